@@ -8406,10 +8406,10 @@ def go_deps():
         patches = [
             "@cockroach//build/patches:io_opentelemetry_go_proto_otlp.patch",
         ],
-        sha256 = "1a91376c923da07bee23439e8430c32736f6330532df85d3bd1ada90305097d7",
-        strip_prefix = "go.opentelemetry.io/proto/otlp@v0.9.0",
+        sha256 = "9587b1de0cdaceb2ba3e8273521760665cd26051812230265fa5658c0a1cf487",
+        strip_prefix = "go.opentelemetry.io/proto/otlp@v0.12.0",
         urls = [
-            "https://storage.googleapis.com/cockroach-godeps/gomod/go.opentelemetry.io/proto/otlp/io_opentelemetry_go_proto_otlp-v0.9.0.zip",
+            "https://storage.googleapis.com/cockroach-godeps/gomod/go.opentelemetry.io/proto/otlp/io_opentelemetry_go_proto_otlp-v0.12.0.zip",
         ],
     )
     go_repository(
@@ -0,0 +1,571 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/cockroachdb/errors"
+)
+
+// compactedBackupSuffix is appended to the name of the subdirectory of a
+// collection that a chain of backups is compacted into.
+const compactedBackupSuffix = "-compacted"
+
+// backupCompactionProgressInterval is the minimum interval between updates of
+// the fraction completed of a backup compaction job.
+const backupCompactionProgressInterval = 10 * time.Second
+
+// backupCompactionResumer implements jobs.Resumer for a job which merges a
+// full backup and its incremental layers into a new full backup.
+//
+// The compacted backup contains the latest revision of every key as of the
+// end time of the last layer which is compacted. It is built by computing the
+// same covering of the backed up spans by the files of the chain that RESTORE
+// uses, and then merging the SSTs of each entry of that covering into the
+// files of the new backup. No data is read from the cluster.
+type backupCompactionResumer struct {
+	job *jobs.Job
+}
+
+var _ jobs.Resumer = &backupCompactionResumer{}
+
+// Resume is part of the jobs.Resumer interface.
+func (r *backupCompactionResumer) Resume(ctx context.Context, execCtx interface{}) error {
+	details := r.job.Details().(jobspb.BackupCompactionDetails)
+	p := execCtx.(sql.JobExecContext)
+	execCfg := p.ExecCfg()
+
+	if details.CompactAfterIncrementals > 0 && details.URI == "" {
+		ok, err := resolveBackupCompaction(ctx, execCfg, p.User(), &details)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			log.Infof(ctx, "fewer than %d incremental backups in the latest chain of %s, not compacting",
+				details.CompactAfterIncrementals, RedactURIForErrorMessage(details.CollectionURI))
+			return nil
+		}
+		// Persist the resolved chain, so that a resumption of the job compacts
+		// the same chain into the same destination.
+		if err := r.job.SetDetails(ctx, nil /* txn */, details); err != nil {
+			return err
+		}
+	}
+
+	store, err := execCfg.DistSQLSrv.ExternalStorageFromURI(ctx, details.URI, p.User())
+	if err != nil {
+		return errors.Wrapf(err, "make storage")
+	}
+	defer store.Close()
+
+	dest, err := execCfg.DistSQLSrv.ExternalStorageFromURI(ctx, details.DestinationURI, p.User())
+	if err != nil {
+		return errors.Wrapf(err, "make storage")
+	}
+	defer dest.Close()
+
+	// The manifest is written last, so if it exists a previous execution of
+	// this job already wrote the whole compacted backup.
+	if f, err := dest.ReadFile(ctx, backupManifestName); err == nil {
+		f.Close()
+		log.Infof(ctx, "compacted backup already written to %s",
+			RedactURIForErrorMessage(details.DestinationURI))
+	} else if !errors.Is(err, cloud.ErrFileDoesNotExist) {
+		return err
+	} else if err := compactBackupChain(ctx, execCfg, r.job, details, store, dest); err != nil {
+		return err
+	}
+
+	return maybeUpdateLatestAfterCompaction(ctx, execCfg, p.User(), details)
+}
+
+// OnFailOrCancel is part of the jobs.Resumer interface.
+func (r *backupCompactionResumer) OnFailOrCancel(context.Context, interface{}) error {
+	return nil
+}
+
+// compactBackupChain writes a full backup to dest which is equivalent to the
+// chain of backups in store.
+func compactBackupChain(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	job *jobs.Job,
+	details jobspb.BackupCompactionDetails,
+	store cloud.ExternalStorage,
+	dest cloud.ExternalStorage,
+) error {
+	enc := details.EncryptionOptions
+
+	incPaths, err := FindPriorBackups(ctx, store, IncludeManifest)
+	if err != nil {
+		return err
+	}
+
+	mem := execCfg.RootMemoryMonitor.MakeBoundAccount()
+	defer mem.Close(ctx)
+
+	manifests := make([]BackupManifest, 0, len(incPaths)+1)
+	full, _, err := ReadBackupManifestFromStore(ctx, &mem, store, enc)
+	if err != nil {
+		return err
+	}
+	manifests = append(manifests, full)
+	for _, incPath := range incPaths {
+		m, _, err := readBackupManifest(ctx, &mem, store, incPath, enc)
+		if err != nil {
+			return err
+		}
+		// The files of the incremental layers are read from store, so make their
+		// paths relative to it.
+		layerDir := strings.TrimPrefix(path.Dir(incPath), "/")
+		for i := range m.Files {
+			m.Files[i].Path = path.Join(layerDir, m.Files[i].Path)
+		}
+		manifests = append(manifests, m)
+	}
+
+	if !details.EndTime.IsEmpty() {
+		n := 0
+		for n < len(manifests) && manifests[n].EndTime.LessEq(details.EndTime) {
+			n++
+		}
+		if n == 0 {
+			return errors.Errorf("full backup ends at %s, after the requested end time %s",
+				manifests[0].EndTime, details.EndTime)
+		}
+		manifests = manifests[:n]
+	}
+
+	for i := range manifests {
+		if len(manifests[i].LocalityKVs) > 0 {
+			return errors.New("compacting locality-aware backups is not supported")
+		}
+	}
+
+	last := manifests[len(manifests)-1]
+	if err := checkCoverage(ctx, last.Spans, manifests); err != nil {
+		return err
+	}
+
+	var fileEnc *roachpb.FileEncryptionOptions
+	if enc != nil {
+		key, err := getEncryptionKey(ctx, enc, execCfg.Settings, store.ExternalIOConf())
+		if err != nil {
+			return err
+		}
+		fileEnc = &roachpb.FileEncryptionOptions{Key: key}
+
+		// Reuse the encryption info of the compacted backup, so that the new
+		// backup can be read with the same passphrase or KMS.
+		encInfo, err := readEncryptionOptions(ctx, store)
+		if err != nil {
+			return err
+		}
+		if err := writeEncryptionInfoIfNotExists(ctx, encInfo, dest); err != nil {
+			return err
+		}
+	}
+
+	pkIDs := make(map[uint64]bool)
+	for i := range last.Descriptors {
+		if t, _, _, _, _ := descpb.FromDescriptor(&last.Descriptors[i]); t != nil {
+			pkIDs[roachpb.BulkOpSummaryID(uint64(t.ID), uint64(t.PrimaryIndex.ID))] = true
+		}
+	}
+
+	sink := &compactionSink{
+		dest:       dest,
+		enc:        fileEnc,
+		instanceID: execCfg.NodeID.SQLInstanceID(),
+		settings:   execCfg.Settings,
+		pkIDs:      pkIDs,
+	}
+	defer sink.close()
+
+	cover := makeSimpleImportSpans(last.Spans, manifests, nil /* backupLocalityMap */, nil /* lowWaterMark */)
+	lastProgress := timeutil.Now()
+	for i, entry := range cover {
+		if err := sink.write(ctx, store, entry); err != nil {
+			return errors.Wrapf(err, "compacting span %s", entry.Span)
+		}
+		if timeutil.Since(lastProgress) > backupCompactionProgressInterval {
+			if err := job.FractionProgressed(
+				ctx, nil /* txn */, jobs.FractionUpdater(float32(i+1)/float32(len(cover))),
+			); err != nil {
+				return err
+			}
+			lastProgress = timeutil.Now()
+		}
+	}
+	if err := sink.flush(ctx); err != nil {
+		return err
+	}
+
+	compacted := last
+	compacted.StartTime = hlc.Timestamp{}
+	compacted.MVCCFilter = MVCCFilter_Latest
+	compacted.RevisionStartTime = hlc.Timestamp{}
+	compacted.IntroducedSpans = nil
+	compacted.DescriptorChanges = nil
+	compacted.Files = sink.files
+	compacted.EntryCounts = RowCount{}
+	for _, f := range sink.files {
+		compacted.EntryCounts.add(f.EntryCounts)
+	}
+	compacted.Dir = dest.Conf()
+	compacted.ID = uuid.MakeV4()
+
+	// Carry over the table statistics of the last layer, if it has any.
+	if len(compacted.StatisticsFilenames) > 0 {
+		lastDir := ""
+		if len(manifests) > 1 {
+			lastDir = strings.TrimPrefix(path.Dir(incPaths[len(manifests)-2]), "/")
+		}
+		if err := copyBackupFile(
+			ctx, store, path.Join(lastDir, backupStatisticsFileName), dest, backupStatisticsFileName,
+		); err != nil {
+			log.Warningf(ctx, "failed to copy table statistics of the compacted backup: %v", err)
+			compacted.StatisticsFilenames = nil
+		}
+	}
+
+	return writeBackupManifest(ctx, execCfg.Settings, dest, backupManifestName, enc, &compacted)
+}
+
+// compactionSink writes the merged contents of the entries of a restore span
+// covering to SSTs, and records the files of the compacted backup.
+type compactionSink struct {
+	dest       cloud.ExternalStorage
+	enc        *roachpb.FileEncryptionOptions
+	instanceID base.SQLInstanceID
+	settings   *cluster.Settings
+	pkIDs      map[uint64]bool
+
+	out     io.WriteCloser
+	sst     storage.SSTWriter
+	outName string
+	outSize int64
+	lastEnd roachpb.Key
+
+	files []BackupManifest_File
+}
+
+// write merges the latest revision of every key in the files of entry, which
+// are read from store, into the current SST, opening a new one if needed. The
+// files of the compacted backup are encrypted with the same key as the files
+// they are read from.
+func (s *compactionSink) write(
+	ctx context.Context, store cloud.ExternalStorage, entry execinfrapb.RestoreSpanEntry,
+) error {
+	// SSTWriter demands writes in order.
+	if s.out != nil && entry.Span.Key.Compare(s.lastEnd) < 0 {
+		if err := s.flush(ctx); err != nil {
+			return err
+		}
+	}
+
+	iters := make([]storage.SimpleMVCCIterator, 0, len(entry.Files))
+	defer func() {
+		for _, iter := range iters {
+			iter.Close()
+		}
+	}()
+	for _, f := range entry.Files {
+		iter, err := storageccl.ExternalSSTReader(ctx, store, f.Path, s.enc)
+		if err != nil {
+			return err
+		}
+		iters = append(iters, iter)
+	}
+	iter := storage.MakeMultiIterator(iters)
+	defer iter.Close()
+
+	var rows storage.RowCounter
+	endKeyMVCC := storage.MVCCKey{Key: entry.Span.EndKey}
+	for iter.SeekGE(storage.MVCCKey{Key: entry.Span.Key}); ; iter.NextKey() {
+		if ok, err := iter.Valid(); err != nil {
+			return err
+		} else if !ok || !iter.UnsafeKey().Less(endKeyMVCC) {
+			break
+		}
+		// The first version of each key is the latest one. If it is a deletion,
+		// the key does not exist as of the end time of the chain.
+		value := iter.UnsafeValue()
+		if len(value) == 0 {
+			continue
+		}
+		if s.out == nil {
+			if err := s.open(ctx); err != nil {
+				return err
+			}
+		}
+		key := iter.UnsafeKey()
+		if err := rows.Count(key.Key); err != nil {
+			return errors.Wrapf(err, "decoding %s", key)
+		}
+		if key.Timestamp.IsEmpty() {
+			if err := s.sst.PutUnversioned(key.Key, value); err != nil {
+				return err
+			}
+		} else {
+			if err := s.sst.PutMVCC(key, value); err != nil {
+				return err
+			}
+		}
+		rows.BulkOpSummary.DataSize += int64(len(key.Key) + len(value))
+	}
+
+	if rows.BulkOpSummary.DataSize == 0 {
+		return nil
+	}
+	s.files = append(s.files, BackupManifest_File{
+		Span:        entry.Span,
+		Path:        s.outName,
+		EntryCounts: countRows(rows.BulkOpSummary, s.pkIDs),
+	})
+	s.outSize += rows.BulkOpSummary.DataSize
+	s.lastEnd = entry.Span.EndKey
+
+	if s.outSize > targetFileSize.Get(&s.settings.SV) {
+		return s.flush(ctx)
+	}
+	return nil
+}
+
+func (s *compactionSink) open(ctx context.Context) error {
+	s.outName = generateUniqueSSTName(s.instanceID)
+	w, err := s.dest.Writer(ctx, s.outName)
+	if err != nil {
+		return err
+	}
+	if s.enc != nil {
+		w, err = storageccl.EncryptingWriter(w, s.enc.Key)
+		if err != nil {
+			return err
+		}
+	}
+	s.out = w
+	s.sst = storage.MakeBackupSSTWriter(s.out)
+	return nil
+}
+
+// flush finishes the current SST, if any.
+func (s *compactionSink) flush(ctx context.Context) error {
+	if s.out == nil {
+		return nil
+	}
+	if err := s.sst.Finish(); err != nil {
+		return err
+	}
+	if err := s.out.Close(); err != nil {
+		return errors.Wrap(err, "writing SST")
+	}
+	s.out = nil
+	s.outSize = 0
+	s.lastEnd = nil
+	return nil
+}
+
+func (s *compactionSink) close() {
+	if s.out != nil {
+		s.sst.Close()
+		_ = s.out.Close()
+		s.out = nil
+	}
+}
+
+// copyBackupFile copies the file at srcName in src to destName in dest.
+func copyBackupFile(
+	ctx context.Context,
+	src cloud.ExternalStorage,
+	srcName string,
+	dest cloud.ExternalStorage,
+	destName string,
+) error {
+	r, err := src.ReadFile(ctx, srcName)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return cloud.WriteFile(ctx, dest, destName, bytes.NewReader(content))
+}
+
+// resolveBackupCompaction resolves the chain of backups compacted by a job
+// started by a backup schedule: the chain of the latest full backup in the
+// collection, up to the last incremental backup it contains. It returns false
+// if that chain does not yet contain CompactAfterIncrementals incremental
+// backups.
+func resolveBackupCompaction(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	user security.SQLUsername,
+	details *jobspb.BackupCompactionDetails,
+) (bool, error) {
+	collectionURI := details.CollectionURI
+	makeCloudStorage := execCfg.DistSQLSrv.ExternalStorageFromURI
+
+	latest, err := readLatestFile(ctx, collectionURI, makeCloudStorage, user)
+	if err != nil {
+		return false, err
+	}
+	subdirURI := func(subdir string) (string, error) {
+		parsed, err := url.Parse(collectionURI)
+		if err != nil {
+			return "", err
+		}
+		parsed.Path = path.Join(parsed.Path, subdir)
+		return parsed.String(), nil
+	}
+	uri, err := subdirURI(latest)
+	if err != nil {
+		return false, err
+	}
+
+	store, err := makeCloudStorage(ctx, uri, user)
+	if err != nil {
+		return false, err
+	}
+	defer store.Close()
+	incPaths, err := FindPriorBackups(ctx, store, IncludeManifest)
+	if err != nil {
+		return false, err
+	}
+	if int64(len(incPaths)) < details.CompactAfterIncrementals {
+		return false, nil
+	}
+
+	// The directory of an incremental backup is named after its end time, to
+	// the precision of DateBasedIncFolderName. Name the compacted backup after
+	// the end time of the last incremental backup found, and only compact the
+	// layers up to that one: later incremental backups of the schedule may
+	// complete while the compaction is running.
+	lastEnd, err := time.Parse(DateBasedIncFolderName, path.Dir(incPaths[len(incPaths)-1]))
+	if err != nil {
+		return false, errors.Wrapf(err, "parsing end time of incremental backup %s", incPaths[len(incPaths)-1])
+	}
+	endTime := hlc.Timestamp{WallTime: lastEnd.Add(10*time.Millisecond).UnixNano() - 1}
+
+	destURI, err := subdirURI(lastEnd.Format(DateBasedIntoFolderName) + compactedBackupSuffix)
+	if err != nil {
+		return false, err
+	}
+	dest, err := makeCloudStorage(ctx, destURI, user)
+	if err != nil {
+		return false, err
+	}
+	defer dest.Close()
+	if err := checkForPreviousBackup(ctx, dest, destURI); err != nil {
+		return false, err
+	}
+
+	kmsEnv := &backupKMSEnv{settings: execCfg.Settings, conf: &execCfg.ExternalIODirConfig}
+	encryption, err := getEncryptionFromBase(ctx, user, makeCloudStorage, uri,
+		details.EncryptionParams, kmsEnv)
+	if err != nil {
+		return false, err
+	}
+
+	details.URI = uri
+	details.EndTime = endTime
+	details.DestinationURI = destURI
+	details.EncryptionOptions = encryption
+	return true, nil
+}
+
+// maybeUpdateLatestAfterCompaction points the LATEST file of the collection at
+// the compacted backup, so that subsequent incremental backups into the
+// collection are taken on top of it. LATEST is only updated if it still points
+// at the chain which was compacted: if a new full backup has been taken in the
+// meantime, it is more recent than the compacted backup.
+func maybeUpdateLatestAfterCompaction(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	user security.SQLUsername,
+	details jobspb.BackupCompactionDetails,
+) error {
+	if details.CollectionURI == "" {
+		return nil
+	}
+	collectionURI, err := url.Parse(details.CollectionURI)
+	if err != nil {
+		return err
+	}
+	subdir := func(uri string) (string, error) {
+		parsed, err := url.Parse(uri)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimPrefix(path.Clean(parsed.Path), path.Clean(collectionURI.Path)), nil
+	}
+	srcSubdir, err := subdir(details.URI)
+	if err != nil {
+		return err
+	}
+	destSubdir, err := subdir(details.DestinationURI)
+	if err != nil {
+		return err
+	}
+
+	latest, err := readLatestFile(ctx, details.CollectionURI,
+		execCfg.DistSQLSrv.ExternalStorageFromURI, user)
+	if err != nil {
+		return err
+	}
+	if path.Clean(latest) != path.Clean(srcSubdir) {
+		log.Infof(ctx, "not pointing LATEST at compacted backup %s: it points at %s",
+			destSubdir, latest)
+		return nil
+	}
+
+	c, err := execCfg.DistSQLSrv.ExternalStorageFromURI(ctx, details.CollectionURI, user)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	return cloud.WriteFile(ctx, c, latestFileName, strings.NewReader(destSubdir))
+}
+
+func init() {
+	jobs.RegisterConstructor(
+		jobspb.TypeBackupCompaction,
+		func(job *jobs.Job, _ *cluster.Settings) jobs.Resumer {
+			return &backupCompactionResumer{
+				job: job,
+			}
+		},
+	)
+}
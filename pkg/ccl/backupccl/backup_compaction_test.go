@@ -0,0 +1,110 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackupCompaction(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const numAccounts = 100
+	_, sqlDB, _, cleanupFn := BackupRestoreTestSetup(t, singleNode, numAccounts, InitManualReplication)
+	defer cleanupFn()
+
+	const collection = LocalFoo + "/collection"
+	sqlDB.Exec(t, `BACKUP DATABASE data INTO $1`, collection)
+	sqlDB.Exec(t, `UPDATE data.bank SET balance = balance + 1 WHERE id % 2 = 0`)
+	sqlDB.Exec(t, `BACKUP DATABASE data INTO LATEST IN $1`, collection)
+	sqlDB.Exec(t, `DELETE FROM data.bank WHERE id % 3 = 0`)
+	sqlDB.Exec(t, `BACKUP DATABASE data INTO LATEST IN $1`, collection)
+	endTime := hlc.Timestamp{WallTime: timeutil.Now().UnixNano()}
+
+	// Changes backed up after the end time of the compaction must not be
+	// included in the compacted backup.
+	expected := sqlDB.QueryStr(t, `SELECT * FROM data.bank ORDER BY id`)
+	sqlDB.Exec(t, `INSERT INTO data.bank VALUES (1000, 1, 'new')`)
+	sqlDB.Exec(t, `BACKUP DATABASE data INTO LATEST IN $1`, collection)
+
+	var subdir string
+	sqlDB.QueryRow(t, `SELECT path FROM [SHOW BACKUPS IN $1]`, collection).Scan(&subdir)
+
+	const compacted = "/compacted"
+	createAndWaitForJob(t, sqlDB, nil /* descriptorIDs */, jobspb.BackupCompactionDetails{
+		URI:            collection + subdir,
+		EndTime:        endTime,
+		DestinationURI: collection + compacted,
+		CollectionURI:  collection,
+	}, jobspb.BackupCompactionProgress{})
+
+	// The compacted backup consists of a single full layer which restores to
+	// the state of the database at the end of the last incremental compacted.
+	require.Equal(t, [][]string{{"full"}}, sqlDB.QueryStr(t,
+		`SELECT DISTINCT backup_type FROM [SHOW BACKUP $1 IN $2]`, compacted, collection))
+	require.Empty(t, sqlDB.QueryStr(t,
+		`SELECT * FROM [SHOW BACKUP $1 IN $2 WITH check_files]`, compacted, collection))
+
+	sqlDB.Exec(t, `RESTORE DATABASE data FROM $1 IN $2 WITH new_db_name = 'restored'`,
+		compacted, collection)
+	require.Equal(t, expected, sqlDB.QueryStr(t, `SELECT * FROM restored.bank ORDER BY id`))
+
+	// LATEST now points at the compacted backup, so that later incremental
+	// backups of the schedule are appended to it.
+	sqlDB.Exec(t, `BACKUP DATABASE data INTO LATEST IN $1`, collection)
+	sqlDB.Exec(t, `RESTORE DATABASE data FROM LATEST IN $1 WITH new_db_name = 'restored_latest'`,
+		collection)
+	sqlDB.CheckQueryResults(t, `SELECT * FROM restored_latest.bank ORDER BY id`,
+		sqlDB.QueryStr(t, `SELECT * FROM data.bank ORDER BY id`))
+}
+
+func TestBackupCompactionResolvesLatestChain(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const numAccounts = 10
+	_, sqlDB, _, cleanupFn := BackupRestoreTestSetup(t, singleNode, numAccounts, InitManualReplication)
+	defer cleanupFn()
+
+	const collection = LocalFoo + "/collection"
+	sqlDB.Exec(t, `BACKUP DATABASE data INTO $1`, collection)
+	sqlDB.Exec(t, `UPDATE data.bank SET balance = balance + 1`)
+	sqlDB.Exec(t, `BACKUP DATABASE data INTO LATEST IN $1`, collection)
+
+	// A job started by a schedule does not compact a chain with fewer
+	// incremental backups than configured.
+	createAndWaitForJob(t, sqlDB, nil /* descriptorIDs */, jobspb.BackupCompactionDetails{
+		CollectionURI:            collection,
+		CompactAfterIncrementals: 2,
+	}, jobspb.BackupCompactionProgress{})
+	require.Len(t, sqlDB.QueryStr(t, `SHOW BACKUPS IN $1`, collection), 1)
+
+	sqlDB.Exec(t, `UPDATE data.bank SET balance = balance + 1`)
+	sqlDB.Exec(t, `BACKUP DATABASE data INTO LATEST IN $1`, collection)
+	createAndWaitForJob(t, sqlDB, nil /* descriptorIDs */, jobspb.BackupCompactionDetails{
+		CollectionURI:            collection,
+		CompactAfterIncrementals: 2,
+	}, jobspb.BackupCompactionProgress{})
+	require.Len(t, sqlDB.QueryStr(t, `SHOW BACKUPS IN $1`, collection), 2)
+	require.Equal(t, [][]string{{"full"}}, sqlDB.QueryStr(t,
+		`SELECT DISTINCT backup_type FROM [SHOW BACKUP LATEST IN $1]`, collection))
+
+	sqlDB.Exec(t, `RESTORE DATABASE data FROM LATEST IN $1 WITH new_db_name = 'restored'`,
+		collection)
+	sqlDB.CheckQueryResults(t, `SELECT * FROM restored.bank ORDER BY id`,
+		sqlDB.QueryStr(t, `SELECT * FROM data.bank ORDER BY id`))
+}
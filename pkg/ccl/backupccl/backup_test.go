@@ -602,6 +602,16 @@ func TestBackupRestorePartitioned(t *testing.T) {
 
 		// Verify that all of the partition manifests are compressed.
 		requireCompressedManifest(t, locations...)
+
+		// The files in every location are checked when all the locations are
+		// given, and the files of the localities without a location are
+		// reported otherwise.
+		require.Empty(t, sqlDB.QueryStr(t,
+			`SELECT * FROM [SHOW BACKUP ($1, $2, $3) WITH check_files]`,
+			backupURIs[0], backupURIs[1], backupURIs[2]))
+		require.Equal(t, [][]string{{"/", "true", "true"}}, sqlDB.QueryStr(t,
+			`SELECT backup_path, file IS NULL, problem LIKE '%in locality dc=dc2 were not checked%'
+FROM [SHOW BACKUP ($1, $2) WITH check_files]`, backupURIs[0], backupURIs[1]))
 	})
 
 	// Test that we're selecting the most specific locality tier for a location.
@@ -361,6 +361,20 @@ func DescriptorsMatchingTargets(
 			}
 			alreadyRequestedSchemas[id] = struct{}{}
 			ret.Descs = append(ret.Descs, r.DescByID[id])
+
+			// The user-defined functions of the schema are stored in their own
+			// descriptors, which are requested along with the schema.
+			sc, err := catalog.AsSchemaDescriptor(schemaDesc)
+			if err != nil {
+				return err
+			}
+			for _, fn := range sc.GetFunctions() {
+				for _, o := range fn.Overloads {
+					if fnDesc, ok := r.DescByID[o.ID]; ok && !fnDesc.Dropped() {
+						ret.Descs = append(ret.Descs, fnDesc)
+					}
+				}
+			}
 		}
 
 		return nil
@@ -12,50 +12,51 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"strings"
+	"strconv"
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
 	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/scheduledjobs"
+	"github.com/cockroachdb/cockroach/pkg/scheduledjobs/schedulebase"
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/sql"
-	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkv"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
-	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descs"
-	"github.com/cockroachdb/cockroach/pkg/sql/catalog/resolver"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
 	"github.com/cockroachdb/cockroach/pkg/sql/protoreflect"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
-	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/errors"
 	"github.com/gogo/protobuf/jsonpb"
 	pbtypes "github.com/gogo/protobuf/types"
-	"github.com/robfig/cron/v3"
 )
 
 const (
-	optFirstRun                = "first_run"
-	optOnExecFailure           = "on_execution_failure"
-	optOnPreviousRunning       = "on_previous_running"
-	optIgnoreExistingBackups   = "ignore_existing_backups"
-	optUpdatesLastBackupMetric = "updates_cluster_last_backup_time_metric"
+	optFirstRun                 = schedulebase.OptFirstRun
+	optOnExecFailure            = schedulebase.OptOnExecFailure
+	optOnPreviousRunning        = schedulebase.OptOnPreviousRunning
+	optIgnoreExistingBackups    = "ignore_existing_backups"
+	optUpdatesLastBackupMetric  = "updates_cluster_last_backup_time_metric"
+	optCompactAfterIncrementals = "compact_after_incrementals"
 )
 
 var scheduledBackupOptionExpectValues = map[string]sql.KVStringOptValidate{
-	optFirstRun:                sql.KVStringOptRequireValue,
-	optOnExecFailure:           sql.KVStringOptRequireValue,
-	optOnPreviousRunning:       sql.KVStringOptRequireValue,
-	optIgnoreExistingBackups:   sql.KVStringOptRequireNoValue,
-	optUpdatesLastBackupMetric: sql.KVStringOptRequireNoValue,
+	optFirstRun:                 sql.KVStringOptRequireValue,
+	optOnExecFailure:            sql.KVStringOptRequireValue,
+	optOnPreviousRunning:        sql.KVStringOptRequireValue,
+	optIgnoreExistingBackups:    sql.KVStringOptRequireNoValue,
+	optUpdatesLastBackupMetric:  sql.KVStringOptRequireNoValue,
+	optCompactAfterIncrementals: sql.KVStringOptRequireValue,
 }
 
 // scheduledBackupGCProtectionEnabled is used to enable and disable the chaining
@@ -90,144 +91,26 @@ type scheduledBackupEval struct {
 	incrementalStorage   func() ([]string, error)
 }
 
-func parseOnError(onError string, details *jobspb.ScheduleDetails) error {
-	switch strings.ToLower(onError) {
-	case "retry":
-		details.OnError = jobspb.ScheduleDetails_RETRY_SOON
-	case "reschedule":
-		details.OnError = jobspb.ScheduleDetails_RETRY_SCHED
-	case "pause":
-		details.OnError = jobspb.ScheduleDetails_PAUSE_SCHED
-	default:
-		return errors.Newf(
-			"%q is not a valid on_execution_error; valid values are [retry|reschedule|pause]",
-			onError)
-	}
-	return nil
-}
-
-func parseWaitBehavior(wait string, details *jobspb.ScheduleDetails) error {
-	switch strings.ToLower(wait) {
-	case "start":
-		details.Wait = jobspb.ScheduleDetails_NO_WAIT
-	case "skip":
-		details.Wait = jobspb.ScheduleDetails_SKIP
-	case "wait":
-		details.Wait = jobspb.ScheduleDetails_WAIT
-	default:
-		return errors.Newf(
-			"%q is not a valid on_previous_running; valid values are [start|skip|wait]",
-			wait)
-	}
-	return nil
-}
-
-func parseOnPreviousRunningOption(
-	onPreviousRunning jobspb.ScheduleDetails_WaitBehavior,
-) (string, error) {
-	var onPreviousRunningOption string
-	switch onPreviousRunning {
-	case jobspb.ScheduleDetails_WAIT:
-		onPreviousRunningOption = "WAIT"
-	case jobspb.ScheduleDetails_NO_WAIT:
-		onPreviousRunningOption = "START"
-	case jobspb.ScheduleDetails_SKIP:
-		onPreviousRunningOption = "SKIP"
-	default:
-		return onPreviousRunningOption, errors.Newf("%s is an invalid onPreviousRunning option", onPreviousRunning.String())
-	}
-	return onPreviousRunningOption, nil
-}
-
-func parseOnErrorOption(onError jobspb.ScheduleDetails_ErrorHandlingBehavior) (string, error) {
-	var onErrorOption string
-	switch onError {
-	case jobspb.ScheduleDetails_RETRY_SCHED:
-		onErrorOption = "RESCHEDULE"
-	case jobspb.ScheduleDetails_RETRY_SOON:
-		onErrorOption = "RETRY"
-	case jobspb.ScheduleDetails_PAUSE_SCHED:
-		onErrorOption = "PAUSE"
-	default:
-		return onErrorOption, errors.Newf("%s is an invalid onError option", onError.String())
-	}
-	return onErrorOption, nil
-}
-
-func makeScheduleDetails(opts map[string]string) (jobspb.ScheduleDetails, error) {
-	var details jobspb.ScheduleDetails
-	if v, ok := opts[optOnExecFailure]; ok {
-		if err := parseOnError(v, &details); err != nil {
-			return details, err
-		}
-	}
-
-	if v, ok := opts[optOnPreviousRunning]; ok {
-		if err := parseWaitBehavior(v, &details); err != nil {
-			return details, err
-		}
-	}
-	return details, nil
-}
-
-func scheduleFirstRun(evalCtx *tree.EvalContext, opts map[string]string) (*time.Time, error) {
-	if v, ok := opts[optFirstRun]; ok {
-		firstRun, _, err := tree.ParseDTimestampTZ(evalCtx, v, time.Microsecond)
-		if err != nil {
-			return nil, err
-		}
-		return &firstRun.Time, nil
-	}
-	return nil, nil
-}
-
-type scheduleRecurrence struct {
-	cron      string
-	frequency time.Duration
-}
+var forceFullBackup *schedulebase.ScheduleRecurrence
 
-// A sentinel value indicating the schedule never recurs.
-var neverRecurs *scheduleRecurrence
-
-func computeScheduleRecurrence(
-	now time.Time, evalFn func() (string, error),
-) (*scheduleRecurrence, error) {
-	if evalFn == nil {
-		return neverRecurs, nil
-	}
-	cronStr, err := evalFn()
-	if err != nil {
-		return nil, err
-	}
-	expr, err := cron.ParseStandard(cronStr)
-	if err != nil {
-		return nil, errors.Newf(
-			`error parsing schedule expression: %q; it must be a valid cron expression`,
-			cronStr)
-	}
-	nextRun := expr.Next(now)
-	frequency := expr.Next(nextRun).Sub(nextRun)
-	return &scheduleRecurrence{cronStr, frequency}, nil
-}
-
-var forceFullBackup *scheduleRecurrence
-
-func pickFullRecurrenceFromIncremental(inc *scheduleRecurrence) *scheduleRecurrence {
-	if inc.frequency <= time.Hour {
+func pickFullRecurrenceFromIncremental(
+	inc *schedulebase.ScheduleRecurrence,
+) *schedulebase.ScheduleRecurrence {
+	if inc.Frequency <= time.Hour {
 		// If incremental is faster than once an hour, take fulls every day,
 		// some time between midnight and 1 am.
-		return &scheduleRecurrence{
-			cron:      "@daily",
-			frequency: 24 * time.Hour,
+		return &schedulebase.ScheduleRecurrence{
+			Cron:      "@daily",
+			Frequency: 24 * time.Hour,
 		}
 	}
 
-	if inc.frequency <= 24*time.Hour {
+	if inc.Frequency <= 24*time.Hour {
 		// If incremental is less than a day, take full weekly;  some day
 		// between 0 and 1 am.
-		return &scheduleRecurrence{
-			cron:      "@weekly",
-			frequency: 7 * 24 * time.Hour,
+		return &schedulebase.ScheduleRecurrence{
+			Cron:      "@weekly",
+			Frequency: 7 * 24 * time.Hour,
 		}
 	}
 
@@ -288,7 +171,7 @@ func doCreateBackupSchedules(
 			return err
 		}
 
-		exists, err := checkScheduleAlreadyExists(ctx, p, scheduleLabel)
+		exists, err := schedulebase.CheckScheduleAlreadyExists(ctx, p, scheduleLabel)
 		if err != nil {
 			return err
 		}
@@ -301,19 +184,14 @@ func doCreateBackupSchedules(
 		}
 	}
 
-	env := scheduledjobs.ProdJobSchedulerEnv
-	if knobs, ok := p.ExecCfg().DistSQLSrv.TestingKnobs.JobsTestingKnobs.(*jobs.TestingKnobs); ok {
-		if knobs.JobSchedulerEnv != nil {
-			env = knobs.JobSchedulerEnv
-		}
-	}
+	env := schedulebase.JobSchedulerEnv(p.ExecCfg())
 
 	// Evaluate incremental and full recurrence.
-	incRecurrence, err := computeScheduleRecurrence(env.Now(), eval.recurrence)
+	incRecurrence, err := schedulebase.ComputeScheduleRecurrence(env.Now(), eval.recurrence)
 	if err != nil {
 		return err
 	}
-	fullRecurrence, err := computeScheduleRecurrence(env.Now(), eval.fullBackupRecurrence)
+	fullRecurrence, err := schedulebase.ComputeScheduleRecurrence(env.Now(), eval.fullBackupRecurrence)
 	if err != nil {
 		return err
 	}
@@ -421,13 +299,33 @@ func doCreateBackupSchedules(
 		}
 	}
 
+	var compactAfterIncrementals int64
+	if v, ok := scheduleOptions[optCompactAfterIncrementals]; ok {
+		if !p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.BackupCompaction) {
+			return pgerror.Newf(pgcode.FeatureNotSupported,
+				"%s requires all nodes to be upgraded to %s",
+				optCompactAfterIncrementals, clusterversion.ByKey(clusterversion.BackupCompaction))
+		}
+		compactAfterIncrementals, err = strconv.ParseInt(v, 10, 64)
+		if err != nil || compactAfterIncrementals <= 0 {
+			return errors.Newf("%s must be a positive integer", optCompactAfterIncrementals)
+		}
+		if incRecurrence == nil {
+			return errors.Newf("%s requires incremental backups", optCompactAfterIncrementals)
+		}
+		if len(destinations) > 1 || eval.incrementalStorage != nil {
+			return errors.Newf("%s is not supported with locality-aware backups "+
+				"or incremental_location", optCompactAfterIncrementals)
+		}
+	}
+
 	evalCtx := &p.ExtendedEvalContext().EvalContext
-	firstRun, err := scheduleFirstRun(evalCtx, scheduleOptions)
+	firstRun, err := schedulebase.ScheduleFirstRun(evalCtx, scheduleOptions)
 	if err != nil {
 		return err
 	}
 
-	details, err := makeScheduleDetails(scheduleOptions)
+	details, err := schedulebase.MakeScheduleDetails(scheduleOptions)
 	if err != nil {
 		return err
 	}
@@ -456,7 +354,8 @@ func doCreateBackupSchedules(
 		}
 		inc, incScheduledBackupArgs, err = makeBackupSchedule(
 			env, p.User(), scheduleLabel, incRecurrence, details, unpauseOnSuccessID,
-			updateMetricOnSuccess, backupNode, chainProtectedTimestampRecords)
+			updateMetricOnSuccess, backupNode, chainProtectedTimestampRecords,
+			compactAfterIncrementals)
 		if err != nil {
 			return err
 		}
@@ -480,7 +379,8 @@ func doCreateBackupSchedules(
 	var fullScheduledBackupArgs *ScheduledBackupExecutionArgs
 	full, fullScheduledBackupArgs, err := makeBackupSchedule(
 		env, p.User(), scheduleLabel, fullRecurrence, details, unpauseOnSuccessID,
-		updateMetricOnSuccess, backupNode, chainProtectedTimestampRecords)
+		updateMetricOnSuccess, backupNode, chainProtectedTimestampRecords,
+		0 /* compactAfterIncrementals */)
 	if err != nil {
 		return err
 	}
@@ -586,12 +486,13 @@ func makeBackupSchedule(
 	env scheduledjobs.JobSchedulerEnv,
 	owner security.SQLUsername,
 	label string,
-	recurrence *scheduleRecurrence,
+	recurrence *schedulebase.ScheduleRecurrence,
 	details jobspb.ScheduleDetails,
 	unpauseOnSuccess int64,
 	updateLastMetricOnSuccess bool,
 	backupNode *tree.Backup,
 	chainProtectedTimestampRecords bool,
+	compactAfterIncrementals int64,
 ) (*jobs.ScheduledJob, *ScheduledBackupExecutionArgs, error) {
 	sj := jobs.NewScheduledJob(env)
 	sj.SetScheduleLabel(label)
@@ -602,6 +503,7 @@ func makeBackupSchedule(
 		UnpauseOnSuccess:               unpauseOnSuccess,
 		UpdatesLastBackupMetric:        updateLastMetricOnSuccess,
 		ChainProtectedTimestampRecords: chainProtectedTimestampRecords,
+		CompactAfterIncrementals:       compactAfterIncrementals,
 	}
 	if backupNode.AppendToLatest {
 		args.BackupType = ScheduledBackupExecutionArgs_INCREMENTAL
@@ -609,7 +511,7 @@ func makeBackupSchedule(
 		args.BackupType = ScheduledBackupExecutionArgs_FULL
 	}
 
-	if err := sj.SetSchedule(recurrence.cron); err != nil {
+	if err := sj.SetSchedule(recurrence.Cron); err != nil {
 		return nil, nil, err
 	}
 
@@ -669,23 +571,6 @@ func emitSchedule(
 	return nil
 }
 
-// checkScheduleAlreadyExists returns true if a schedule with the same label already exists,
-// regardless of backup destination.
-func checkScheduleAlreadyExists(
-	ctx context.Context, p sql.PlanHookState, scheduleLabel string,
-) (bool, error) {
-
-	row, err := p.ExecCfg().InternalExecutor.QueryRowEx(ctx, "check-sched",
-		p.ExtendedEvalContext().Txn, sessiondata.InternalExecutorOverride{User: security.RootUserName()},
-		fmt.Sprintf("SELECT count(schedule_name) FROM %s WHERE schedule_name = '%s'",
-			scheduledjobs.ProdJobSchedulerEnv.ScheduledJobsTableName(), scheduleLabel))
-
-	if err != nil {
-		return false, err
-	}
-	return int64(tree.MustBeDInt(row[0])) != 0, nil
-}
-
 // dryRunBackup executes backup in dry-run mode: we simply execute backup
 // under transaction savepoint, and then rollback to that save point.
 func dryRunBackup(ctx context.Context, p sql.PlanHookState, backupNode *tree.Backup) error {
@@ -708,91 +593,6 @@ func dryRunInvokeBackup(ctx context.Context, p sql.PlanHookState, backupNode *tr
 	return invokeBackup(ctx, backupFn)
 }
 
-func fullyQualifyScheduledBackupTargetTables(
-	ctx context.Context, p sql.PlanHookState, tables tree.TablePatterns,
-) ([]tree.TablePattern, error) {
-	fqTablePatterns := make([]tree.TablePattern, len(tables))
-	for i, target := range tables {
-		tablePattern, err := target.NormalizeTablePattern()
-		if err != nil {
-			return nil, err
-		}
-		switch tp := tablePattern.(type) {
-		case *tree.TableName:
-			if err := sql.DescsTxn(ctx, p.ExecCfg(), func(ctx context.Context, txn *kv.Txn,
-				col *descs.Collection) error {
-				// Resolve the table.
-				un := tp.ToUnresolvedObjectName()
-				found, _, tableDesc, err := resolver.ResolveExisting(ctx, un, p, tree.ObjectLookupFlags{},
-					p.CurrentDatabase(), p.CurrentSearchPath())
-				if err != nil {
-					return err
-				}
-				if !found {
-					return errors.Newf("target table %s could not be resolved", tp.String())
-				}
-
-				// Resolve the database.
-				found, dbDesc, err := col.GetImmutableDatabaseByID(ctx, txn, tableDesc.GetParentID(),
-					tree.DatabaseLookupFlags{Required: true})
-				if err != nil {
-					return err
-				}
-				if !found {
-					return errors.Newf("database of target table %s could not be resolved", tp.String())
-				}
-
-				// Resolve the schema.
-				schemaDesc, err := col.GetImmutableSchemaByID(ctx, txn, tableDesc.GetParentSchemaID(),
-					tree.SchemaLookupFlags{Required: true})
-				if err != nil {
-					return err
-				}
-				tn := tree.NewTableNameWithSchema(
-					tree.Name(dbDesc.GetName()),
-					tree.Name(schemaDesc.GetName()),
-					tree.Name(tableDesc.GetName()),
-				)
-				fqTablePatterns[i] = tn
-				return nil
-			}); err != nil {
-				return nil, err
-			}
-		case *tree.AllTablesSelector:
-			if !tp.ExplicitSchema {
-				tp.ExplicitSchema = true
-				tp.SchemaName = tree.Name(p.CurrentDatabase())
-			} else if tp.ExplicitSchema && !tp.ExplicitCatalog {
-				// The schema field could either be a schema or a database. If we can
-				// successfully resolve the schema, we will add the DATABASE prefix.
-				// Otherwise, no updates are needed since the schema field refers to the
-				// database.
-				var resolvedSchema bool
-				if err := sql.DescsTxn(ctx, p.ExecCfg(), func(ctx context.Context, txn *kv.Txn,
-					col *descs.Collection) error {
-					dbDesc, err := col.GetImmutableDatabaseByName(ctx, txn, p.CurrentDatabase(),
-						tree.DatabaseLookupFlags{Required: true})
-					if err != nil {
-						return err
-					}
-					resolvedSchema, _, err = catalogkv.ResolveSchemaID(ctx, txn, p.ExecCfg().Codec,
-						dbDesc.GetID(), tp.SchemaName.String(), p.ExecCfg().Settings.Version)
-					return err
-				}); err != nil {
-					return nil, err
-				}
-
-				if resolvedSchema {
-					tp.ExplicitCatalog = true
-					tp.CatalogName = tree.Name(p.CurrentDatabase())
-				}
-			}
-			fqTablePatterns[i] = tp
-		}
-	}
-	return fqTablePatterns, nil
-}
-
 // makeScheduleBackupEval prepares helper scheduledBackupEval struct to assist in evaluation
 // of various schedule and backup specific components.
 func makeScheduledBackupEval(
@@ -804,7 +604,7 @@ func makeScheduledBackupEval(
 		// planning. This is because the actual execution of the backup job occurs
 		// in a background, scheduled job session, that does not have the same
 		// resolution configuration as during planning.
-		schedule.Targets.Tables, err = fullyQualifyScheduledBackupTargetTables(ctx, p,
+		schedule.Targets.Tables, err = schedulebase.FullyQualifyTables(ctx, p,
 			schedule.Targets.Tables)
 		if err != nil {
 			return nil, errors.Wrap(err, "qualifying backup target tables")
@@ -904,7 +704,7 @@ var scheduledBackupHeader = colinfo.ResultColumns{
 }
 
 func collectScheduledBackupTelemetry(
-	incRecurrence *scheduleRecurrence,
+	incRecurrence *schedulebase.ScheduleRecurrence,
 	firstRun *time.Time,
 	fullRecurrencePicked bool,
 	details jobspb.ScheduleDetails,
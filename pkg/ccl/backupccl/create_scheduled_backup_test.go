@@ -23,12 +23,15 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobstest"
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/scheduledjobs"
+	"github.com/cockroachdb/cockroach/pkg/scheduledjobs/schedulebase"
 	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/server"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
@@ -598,6 +601,18 @@ func TestSerializesScheduledBackupExecutionArgs(t *testing.T) {
 			query:  `CREATE SCHEDULE FOR BACKUP INTO 'foo' WITH encryption_passphrase=$1 RECURRING '@hourly'`,
 			errMsg: "failed to evaluate backup encryption_passphrase",
 		},
+		{
+			name:   "compact-after-invalid-value",
+			user:   enterpriseUser,
+			query:  `CREATE SCHEDULE FOR BACKUP INTO 'foo' RECURRING '@hourly' WITH SCHEDULE OPTIONS compact_after_incrementals = 'abc'`,
+			errMsg: "compact_after_incrementals must be a positive integer",
+		},
+		{
+			name:   "compact-after-without-incrementals",
+			user:   enterpriseUser,
+			query:  `CREATE SCHEDULE FOR BACKUP INTO 'foo' RECURRING '@hourly' FULL BACKUP ALWAYS WITH SCHEDULE OPTIONS compact_after_incrementals = '3'`,
+			errMsg: "compact_after_incrementals requires incremental backups",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -834,6 +849,34 @@ func TestCreateBackupScheduleRequiresAdminRole(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestCreateBackupScheduleCompactionMixedVersion(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	dir, dirCleanupFn := testutils.TempDir(t)
+	defer dirCleanupFn()
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{
+		ExternalIODir: dir,
+		Knobs: base.TestingKnobs{
+			Server: &server.TestingKnobs{
+				DisableAutomaticVersionUpgrade: 1,
+				BinaryVersionOverride:          clusterversion.ByKey(clusterversion.BackupCompaction - 1),
+			},
+		},
+	})
+	defer s.Stopper().Stop(context.Background())
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	const createSchedule = `CREATE SCHEDULE FOR BACKUP INTO 'nodelocal://0/compact' RECURRING '@hourly'
+WITH SCHEDULE OPTIONS compact_after_incrementals = '3'`
+	sqlDB.ExpectErr(t, `compact_after_incrementals requires all nodes to be upgraded`, createSchedule)
+
+	sqlDB.Exec(t, `SET CLUSTER SETTING version = $1`,
+		clusterversion.ByKey(clusterversion.BackupCompaction).String())
+	sqlDB.Exec(t, createSchedule)
+}
+
 func TestCreateBackupScheduleCollectionOverwrite(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -1132,9 +1175,9 @@ func constructExpectedScheduledBackupNode(
 	require.NoError(t, err)
 	firstRun, err := tree.MakeDTimestampTZ(sj.ScheduledRunTime(), time.Microsecond)
 	require.NoError(t, err)
-	wait, err := parseOnPreviousRunningOption(sj.ScheduleDetails().Wait)
+	wait, err := schedulebase.ParseOnPreviousRunningOption(sj.ScheduleDetails().Wait)
 	require.NoError(t, err)
-	onError, err := parseOnErrorOption(sj.ScheduleDetails().OnError)
+	onError, err := schedulebase.ParseOnErrorOption(sj.ScheduleDetails().OnError)
 	require.NoError(t, err)
 	scheduleOptions := tree.KVOptions{
 		tree.KVOption{
@@ -36,6 +36,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/dbdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descs"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/multiregion"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemadesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
@@ -113,6 +114,7 @@ func WriteDescriptors(
 	schemas []catalog.SchemaDescriptor,
 	tables []catalog.TableDescriptor,
 	types []catalog.TypeDescriptor,
+	functions []catalog.FunctionDescriptor,
 	descCoverage tree.DescriptorCoverage,
 	extra []roachpb.KeyValue,
 ) error {
@@ -228,6 +230,29 @@ func WriteDescriptors(
 			b.CPut(catalogkeys.EncodeNameKey(codec, typ), typ.GetID(), nil)
 		}
 
+		// Write all function descriptors. Functions have no namespace entries;
+		// they are resolved through the function map of their parent schema.
+		for i := range functions {
+			fn := functions[i]
+			updatedPrivileges, err := getRestoringPrivileges(ctx, codec, txn, fn, user, wroteDBs, descCoverage)
+			if err != nil {
+				return err
+			}
+			if updatedPrivileges != nil {
+				if mut, ok := fn.(*funcdesc.Mutable); ok {
+					mut.Privileges = updatedPrivileges
+				} else {
+					log.Fatalf(ctx, "wrong type for function %d, %T, expected Mutable",
+						fn.GetID(), fn)
+				}
+			}
+			if err := descsCol.WriteDescToBatch(
+				ctx, false /* kvTrace */, fn.(catalog.MutableDescriptor), b,
+			); err != nil {
+				return err
+			}
+		}
+
 		for _, kv := range extra {
 			b.InitPut(kv.Key, &kv.Value, false)
 		}
@@ -790,7 +815,7 @@ func spansForAllRestoreTableIndexes(
 		// entire interval. DROPPED tables should never later become PUBLIC.
 		// TODO(pbardea): Consider and test the interaction between revision_history
 		// backups and OFFLINE tables.
-		rawTbl, _, _, _ := descpb.FromDescriptor(rev.Desc)
+		rawTbl, _, _, _, _ := descpb.FromDescriptor(rev.Desc)
 		if rawTbl != nil && !rawTbl.Dropped() {
 			tbl := tabledesc.NewBuilder(rawTbl).BuildImmutableTable()
 			// We only import spans for physical tables.
@@ -844,6 +869,7 @@ func createImportingDescriptors(
 	var writtenTypes []catalog.TypeDescriptor
 	var schemas []*schemadesc.Mutable
 	var types []*typedesc.Mutable
+	var functions []*funcdesc.Mutable
 	// Store the tables as both the concrete mutable structs and the interface
 	// to deal with the lack of slice covariance in go. We want the slice of
 	// mutable descriptors for rewriting but ultimately want to return the
@@ -882,6 +908,13 @@ func createImportingDescriptors(
 		case catalog.TypeDescriptor:
 			mut := typedesc.NewBuilder(desc.TypeDesc()).BuildCreatedMutableType()
 			types = append(types, mut)
+		case catalog.FunctionDescriptor:
+			// Functions are only restored along with their schema, in which case
+			// they have a rewrite.
+			if _, ok := details.DescriptorRewrites[desc.GetID()]; ok {
+				mut := funcdesc.NewBuilder(desc.FuncDesc()).BuildCreatedMutableFunction()
+				functions = append(functions, mut)
+			}
 		}
 	}
 
@@ -978,6 +1011,14 @@ func createImportingDescriptors(
 		return nil, nil, err
 	}
 
+	if err := rewriteFunctionDescs(functions, details.DescriptorRewrites); err != nil {
+		return nil, nil, err
+	}
+	writtenFunctions := make([]catalog.FunctionDescriptor, len(functions))
+	for i, fn := range functions {
+		writtenFunctions[i] = fn
+	}
+
 	// Set the new descriptors' states to offline.
 	for _, desc := range mutableTables {
 		desc.SetOffline("restoring")
@@ -988,6 +1029,9 @@ func createImportingDescriptors(
 	for _, desc := range schemasToWrite {
 		desc.SetOffline("restoring")
 	}
+	for _, desc := range functions {
+		desc.SetOffline("restoring")
+	}
 	for _, desc := range mutableDatabases {
 		desc.SetOffline("restoring")
 	}
@@ -1079,7 +1123,7 @@ func createImportingDescriptors(
 			// Write the new descriptors which are set in the OFFLINE state.
 			if err := WriteDescriptors(
 				ctx, p.ExecCfg().Codec, txn, p.User(), descsCol, databases, writtenSchemas, tables, writtenTypes,
-				details.DescriptorCoverage, nil, /* extra */
+				writtenFunctions, details.DescriptorCoverage, nil, /* extra */
 			); err != nil {
 				return errors.Wrapf(err, "restoring %d TableDescriptors from %d databases", len(tables), len(databases))
 			}
@@ -1236,6 +1280,10 @@ func createImportingDescriptors(
 			for i := range schemasToWrite {
 				details.SchemaDescs[i] = schemasToWrite[i].SchemaDesc()
 			}
+			details.FunctionDescs = make([]*descpb.FunctionDescriptor, len(functions))
+			for i := range functions {
+				details.FunctionDescs[i] = functions[i].FuncDesc()
+			}
 
 			// Update the job once all descs have been prepared for ingestion.
 			err := r.job.SetDetails(ctx, txn, details)
@@ -1818,11 +1866,13 @@ func (r *restoreResumer) publishDescriptors(
 	// Write the new descriptors and flip state over to public so they can be
 	// accessed.
 	allMutDescs := make([]catalog.MutableDescriptor, 0,
-		len(details.TableDescs)+len(details.TypeDescs)+len(details.SchemaDescs)+len(details.DatabaseDescs))
+		len(details.TableDescs)+len(details.TypeDescs)+len(details.SchemaDescs)+
+			len(details.FunctionDescs)+len(details.DatabaseDescs))
 	// Create slices of raw descriptors for the restore job details.
 	newTables := make([]*descpb.TableDescriptor, 0, len(details.TableDescs))
 	newTypes := make([]*descpb.TypeDescriptor, 0, len(details.TypeDescs))
 	newSchemas := make([]*descpb.SchemaDescriptor, 0, len(details.SchemaDescs))
+	newFunctions := make([]*descpb.FunctionDescriptor, 0, len(details.FunctionDescs))
 	newDBs := make([]*descpb.DatabaseDescriptor, 0, len(details.DatabaseDescs))
 	checkVersion := func(read catalog.Descriptor, exp descpb.DescriptorVersion) error {
 		if read.GetVersion() == exp {
@@ -1903,6 +1953,18 @@ func (r *restoreResumer) publishDescriptors(
 		allMutDescs = append(allMutDescs, mutSchema)
 		newSchemas = append(newSchemas, mutSchema.SchemaDesc())
 	}
+	for _, fn := range details.FunctionDescs {
+		mutDesc, err := descsCol.GetMutableDescriptorByID(ctx, fn.ID, txn)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion(mutDesc, fn.Version); err != nil {
+			return err
+		}
+		mutFn := mutDesc.(*funcdesc.Mutable)
+		allMutDescs = append(allMutDescs, mutFn)
+		newFunctions = append(newFunctions, mutFn.FuncDesc())
+	}
 	for _, dbDesc := range details.DatabaseDescs {
 		// Jobs started before 20.2 upgrade finalization don't put databases in
 		// an offline state.
@@ -1949,6 +2011,7 @@ func (r *restoreResumer) publishDescriptors(
 	details.TableDescs = newTables
 	details.TypeDescs = newTypes
 	details.SchemaDescs = newSchemas
+	details.FunctionDescs = newFunctions
 	details.DatabaseDescs = newDBs
 	if err := r.job.SetDetails(ctx, txn, details); err != nil {
 		return errors.Wrap(err,
@@ -2130,6 +2193,29 @@ func (r *restoreResumer) dropDescriptors(
 		descsCol.AddDeletedDescriptor(mutType)
 	}
 
+	// Drop the function descriptors that this restore created. Functions have
+	// no namespace entries and no data, so their descriptors are just removed.
+	for i := range details.FunctionDescs {
+		fnDesc := details.FunctionDescs[i]
+		mutFn, err := descsCol.GetMutableFunctionByID(ctx, txn, fnDesc.ID, tree.ObjectLookupFlags{
+			CommonLookupFlags: tree.CommonLookupFlags{
+				AvoidLeased:    true,
+				IncludeOffline: true,
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		mutFn.SetDropped()
+		if err := descsCol.WriteDescToBatch(ctx, false /* kvTrace */, mutFn, b); err != nil {
+			return errors.Wrap(err, "writing dropping function to batch")
+		}
+		// Remove the system.descriptor entry.
+		b.Del(catalogkeys.MakeDescMetadataKey(codec, fnDesc.ID))
+		descsCol.AddDeletedDescriptor(mutFn)
+	}
+
 	// Queue a GC job.
 	gcDetails := jobspb.SchemaChangeGCDetails{}
 	for _, tableID := range tablesToGC {
@@ -2160,6 +2246,9 @@ func (r *restoreResumer) dropDescriptors(
 	for _, typ := range details.TypeDescs {
 		ignoredChildDescIDs[typ.ID] = struct{}{}
 	}
+	for _, fn := range details.FunctionDescs {
+		ignoredChildDescIDs[fn.ID] = struct{}{}
+	}
 	for _, schema := range details.SchemaDescs {
 		ignoredChildDescIDs[schema.ID] = struct{}{}
 	}
@@ -2406,6 +2495,13 @@ func getRestoringPrivileges(
 		if descCoverage == tree.RequestedDescriptors {
 			updatedPrivileges = descpb.NewBasePrivilegeDescriptor(user)
 		}
+	case catalog.FunctionDescriptor:
+		// If the restore is not a cluster restore we cannot know that the users on
+		// the restoring cluster match the ones that were on the cluster that was
+		// backed up. So we wipe the privileges on the function.
+		if descCoverage == tree.RequestedDescriptors {
+			updatedPrivileges = descpb.NewBaseFunctionPrivilegeDescriptor(user)
+		}
 	case catalog.DatabaseDescriptor:
 		// If the restore is not a cluster restore we cannot know that the users on
 		// the restoring cluster match the ones that were on the cluster that was
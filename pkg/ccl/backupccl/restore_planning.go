@@ -22,6 +22,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
 	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
 	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/featureflag"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
@@ -39,7 +40,9 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/dbdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descs"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/multiregion"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/resolver"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemadesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemaexpr"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/systemschema"
@@ -302,11 +305,13 @@ func allocateDescriptorRewrites(
 	schemasByID map[descpb.ID]*schemadesc.Mutable,
 	tablesByID map[descpb.ID]*tabledesc.Mutable,
 	typesByID map[descpb.ID]*typedesc.Mutable,
+	functionsByID map[descpb.ID]*funcdesc.Mutable,
 	restoreDBs []catalog.DatabaseDescriptor,
 	descriptorCoverage tree.DescriptorCoverage,
 	opts tree.RestoreOptions,
 	intoDB string,
 	newDBName string,
+	asTableName string,
 ) (DescRewriteMap, error) {
 	descriptorRewrites := make(DescRewriteMap)
 
@@ -417,6 +422,13 @@ func allocateDescriptorRewrites(
 		}
 	}
 
+	// Include the function descriptors when calculating the max ID.
+	for _, fn := range functionsByID {
+		if int64(fn.ID) > maxDescIDInBackup {
+			maxDescIDInBackup = int64(fn.ID)
+		}
+	}
+
 	needsNewParentIDs := make(map[string][]descpb.ID)
 	// Increment the DescIDSequenceKey so that it is higher than the max desc ID
 	// in the backup. This generator keeps produced the next descriptor ID.
@@ -705,6 +717,30 @@ func allocateDescriptorRewrites(
 				if err != nil {
 					return err
 				}
+				// When restoring a table under a new name, an enum which collides with
+				// an object that it cannot be remapped to is restored under a new name
+				// as well, rather than failing the restore.
+				var newTypeName string
+				if desc != nil && asTableName != "" && typ.Kind == descpb.TypeDescriptor_ENUM {
+					existingType, isType := desc.(catalog.TypeDescriptor)
+					if !isType || typ.IsCompatibleWith(existingType) != nil {
+						newTypeName = asTableName + "_" + typ.Name
+						desc, err = catalogkv.GetDescriptorCollidingWithObject(
+							ctx,
+							txn,
+							p.ExecCfg().Codec,
+							parentID,
+							getParentSchemaID(typ),
+							newTypeName,
+						)
+						if err != nil {
+							return err
+						}
+						if desc != nil {
+							return sqlerrors.MakeObjectAlreadyExistsError(desc.DescriptorProto(), newTypeName)
+						}
+					}
+				}
 				if desc == nil {
 					// If we didn't find a type with the same name, then mark that we
 					// need to create the type.
@@ -715,17 +751,29 @@ func allocateDescriptorRewrites(
 					}
 
 					// Create a rewrite entry for the type.
-					descriptorRewrites[typ.ID] = &jobspb.RestoreDetails_DescriptorRewrite{ParentID: parentID}
+					descriptorRewrites[typ.ID] = &jobspb.RestoreDetails_DescriptorRewrite{
+						ParentID: parentID,
+						NewName:  newTypeName,
+					}
 
 					// Ensure that there isn't a collision with the array type name.
 					arrTyp := typesByID[typ.ArrayTypeID]
-					typeName := tree.NewUnqualifiedTypeName(arrTyp.GetName())
+					arrayTypeName := arrTyp.GetName()
+					var newArrayTypeName string
+					if newTypeName != "" {
+						newArrayTypeName = "_" + newTypeName
+						arrayTypeName = newArrayTypeName
+					}
+					typeName := tree.NewUnqualifiedTypeName(arrayTypeName)
 					err = catalogkv.CheckObjectCollision(ctx, txn, p.ExecCfg().Codec, parentID, getParentSchemaID(typ), typeName)
 					if err != nil {
 						return errors.Wrapf(err, "name collision for %q's array type", typ.Name)
 					}
 					// Create the rewrite entry for the array type as well.
-					descriptorRewrites[arrTyp.ID] = &jobspb.RestoreDetails_DescriptorRewrite{ParentID: parentID}
+					descriptorRewrites[arrTyp.ID] = &jobspb.RestoreDetails_DescriptorRewrite{
+						ParentID: parentID,
+						NewName:  newArrayTypeName,
+					}
 				} else {
 					// If there was a name collision, we'll try to see if we can remap
 					// this type to the type existing in the cluster.
@@ -861,6 +909,25 @@ func allocateDescriptorRewrites(
 		}
 	}
 
+	// Update remapping information for function descriptors. Functions are
+	// only restored along with their schema: a function whose schema is
+	// remapped to an existing schema in the cluster is not restored.
+	for _, fn := range functionsByID {
+		scRewrite, ok := descriptorRewrites[fn.ParentSchemaID]
+		if !ok || scRewrite.ToExisting {
+			continue
+		}
+		descriptorRewrites[fn.ID] = &jobspb.RestoreDetails_DescriptorRewrite{
+			ParentID: scRewrite.ParentID,
+		}
+		if descriptorCoverage == tree.AllDescriptors {
+			// The function doesn't need to be remapped.
+			descriptorRewrites[fn.ID].ID = fn.ID
+		} else {
+			descriptorsToRemap = append(descriptorsToRemap, fn)
+		}
+	}
+
 	sort.Sort(catalog.Descriptors(descriptorsToRemap))
 
 	// Generate new IDs for the schemas, tables, and types that need to be
@@ -895,6 +962,11 @@ func allocateDescriptorRewrites(
 	for _, typ := range typesByID {
 		rewriteObject(typ)
 	}
+	for _, fn := range functionsByID {
+		if _, ok := descriptorRewrites[fn.ID]; ok {
+			rewriteObject(fn)
+		}
+	}
 
 	return descriptorRewrites, nil
 }
@@ -1126,6 +1198,9 @@ func rewriteTypeDescs(types []*typedesc.Mutable, descriptorRewrites DescRewriteM
 		typ.ID = rewrite.ID
 		typ.ParentSchemaID = rewrite.ParentSchemaID
 		typ.ParentID = rewrite.ParentID
+		if rewrite.NewName != "" {
+			typ.Name = rewrite.NewName
+		}
 		for i := range typ.ReferencingDescriptorIDs {
 			id := typ.ReferencingDescriptorIDs[i]
 			if rw, ok := descriptorRewrites[id]; ok {
@@ -1163,6 +1238,43 @@ func rewriteSchemaDescs(schemas []*schemadesc.Mutable, descriptorRewrites DescRe
 
 		sc.ID = rewrite.ID
 		sc.ParentID = rewrite.ParentID
+
+		// Remap the overloads of the functions in this schema. Overloads whose
+		// function is not being restored are dropped.
+		for name, fn := range sc.Functions {
+			overloads := fn.Overloads[:0]
+			for _, o := range fn.Overloads {
+				if rw, ok := descriptorRewrites[o.ID]; ok {
+					o.ID = rw.ID
+					overloads = append(overloads, o)
+				}
+			}
+			if len(overloads) == 0 {
+				delete(sc.Functions, name)
+				continue
+			}
+			fn.Overloads = overloads
+			sc.Functions[name] = fn
+		}
+	}
+	return nil
+}
+
+// rewriteFunctionDescs rewrites all ID's in the input slice of
+// FunctionDescriptors using the input ID rewrite mapping.
+func rewriteFunctionDescs(fns []*funcdesc.Mutable, descriptorRewrites DescRewriteMap) error {
+	for _, fn := range fns {
+		rewrite, ok := descriptorRewrites[fn.ID]
+		if !ok {
+			return errors.Errorf("missing rewrite for function %d", fn.ID)
+		}
+		// Reset the version and modification time on this new descriptor.
+		fn.Version = 1
+		fn.ModificationTime = hlc.Timestamp{}
+
+		fn.ID = rewrite.ID
+		fn.ParentID = rewrite.ParentID
+		fn.ParentSchemaID = rewrite.ParentSchemaID
 	}
 	return nil
 }
@@ -1197,6 +1309,9 @@ func RewriteTableDescs(
 		table.ID = tableRewrite.ID
 		table.UnexposedParentSchemaID = tableRewrite.ParentSchemaID
 		table.ParentID = tableRewrite.ParentID
+		if tableRewrite.NewName != "" {
+			table.Name = tableRewrite.NewName
+		}
 
 		// Remap type IDs and sequence IDs in all serialized expressions within the TableDescriptor.
 		// TODO (rohany): This needs tests once partial indexes are ready.
@@ -1496,8 +1611,19 @@ func restorePlanHook(
 		}
 	}
 
+	if restoreStmt.AsTableName != nil &&
+		!p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.RestoreTableAs) {
+		return nil, nil, nil, false, pgerror.Newf(pgcode.FeatureNotSupported,
+			"RESTORE TABLE ... AS requires all nodes to be upgraded to %s",
+			clusterversion.ByKey(clusterversion.RestoreTableAs))
+	}
+
 	var intoDBFn func() (string, error)
 	if restoreStmt.Options.IntoDB != nil {
+		if restoreStmt.AsTableName != nil {
+			err = errors.Errorf("cannot use %q option with RESTORE TABLE ... AS", restoreOptIntoDB)
+			return nil, nil, nil, false, err
+		}
 		intoDBFn, err = p.TypeAsString(ctx, restoreStmt.Options.IntoDB, "RESTORE")
 		if err != nil {
 			return nil, nil, nil, false, err
@@ -1890,7 +2016,7 @@ func doRestorePlan(
 	for _, m := range mainBackupManifests {
 		spans := roachpb.Spans(m.Spans)
 		for i := range m.Descriptors {
-			table, _, _, _ := descpb.FromDescriptor(&m.Descriptors[i])
+			table, _, _, _, _ := descpb.FromDescriptor(&m.Descriptors[i])
 			if table == nil {
 				continue
 			}
@@ -1923,6 +2049,14 @@ func doRestorePlan(
 				"use SHOW BACKUP to find correct targets")
 	}
 
+	var renamedTableID descpb.ID
+	if restoreStmt.AsTableName != nil {
+		sqlDescs, renamedTableID, err = addOwnedSequencesToRestore(mainBackupManifests, endTime, sqlDescs)
+		if err != nil {
+			return err
+		}
+	}
+
 	var revalidateIndexes []jobspb.RestoreDetails_RevalidateIndex
 	for _, desc := range sqlDescs {
 		tbl, ok := desc.(catalog.TableDescriptor)
@@ -1977,6 +2111,7 @@ func doRestorePlan(
 	schemasByID := make(map[descpb.ID]*schemadesc.Mutable)
 	tablesByID := make(map[descpb.ID]*tabledesc.Mutable)
 	typesByID := make(map[descpb.ID]*typedesc.Mutable)
+	functionsByID := make(map[descpb.ID]*funcdesc.Mutable)
 
 	for _, desc := range sqlDescs {
 		switch desc := desc.(type) {
@@ -1988,6 +2123,8 @@ func doRestorePlan(
 			tablesByID[desc.ID] = desc
 		case *typedesc.Mutable:
 			typesByID[desc.ID] = desc
+		case *funcdesc.Mutable:
+			functionsByID[desc.ID] = desc
 		}
 	}
 
@@ -2032,6 +2169,18 @@ func doRestorePlan(
 		}
 	}
 
+	var asTableName string
+	var newTableNames map[descpb.ID]string
+	if restoreStmt.AsTableName != nil {
+		asTableName = restoreStmt.AsTableName.Object()
+		intoDB, newTableNames, err = renameTableForRestore(
+			ctx, p, restoreStmt.AsTableName, renamedTableID, tablesByID, schemasByID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
 	descriptorRewrites, err := allocateDescriptorRewrites(
 		ctx,
 		p,
@@ -2039,14 +2188,19 @@ func doRestorePlan(
 		schemasByID,
 		filteredTablesByID,
 		typesByID,
+		functionsByID,
 		restoreDBs,
 		restoreStmt.DescriptorCoverage,
 		restoreStmt.Options,
 		intoDB,
-		newDBName)
+		newDBName,
+		asTableName)
 	if err != nil {
 		return err
 	}
+	for id, name := range newTableNames {
+		descriptorRewrites[id].NewName = name
+	}
 	description, err := restoreJobDescription(p, restoreStmt, from, incFrom, restoreStmt.Options,
 		intoDB,
 		newDBName, kms)
@@ -2072,6 +2226,12 @@ func doRestorePlan(
 	for _, desc := range typesByID {
 		types = append(types, desc)
 	}
+	var functions []*funcdesc.Mutable
+	for i := range functionsByID {
+		if _, ok := descriptorRewrites[i]; ok {
+			functions = append(functions, functionsByID[i])
+		}
+	}
 
 	// We attempt to rewrite ID's in the collected type and table descriptors
 	// to catch errors during this process here, rather than in the job itself.
@@ -2087,6 +2247,9 @@ func doRestorePlan(
 	if err := rewriteTypeDescs(types, descriptorRewrites); err != nil {
 		return err
 	}
+	if err := rewriteFunctionDescs(functions, descriptorRewrites); err != nil {
+		return err
+	}
 	for i := range revalidateIndexes {
 		revalidateIndexes[i].TableID = descriptorRewrites[revalidateIndexes[i].TableID].ID
 	}
@@ -2183,6 +2346,105 @@ func doRestorePlan(
 	return sj.ReportExecutionResults(ctx, resultsCh)
 }
 
+// addOwnedSequencesToRestore adds the sequences owned by the table restored
+// by a RESTORE TABLE ... AS statement to sqlDescs, so that they are restored
+// and renamed along with it. It also returns the ID of the table.
+func addOwnedSequencesToRestore(
+	backupManifests []BackupManifest, asOf hlc.Timestamp, sqlDescs []catalog.Descriptor,
+) ([]catalog.Descriptor, descpb.ID, error) {
+	var table catalog.TableDescriptor
+	for _, desc := range sqlDescs {
+		if tbl, ok := desc.(catalog.TableDescriptor); ok {
+			if table != nil {
+				return nil, descpb.InvalidID, errors.AssertionFailedf(
+					"expected a single table to restore, found %q and %q", table.GetName(), tbl.GetName())
+			}
+			table = tbl
+		}
+	}
+	if table == nil {
+		return nil, descpb.InvalidID, errors.AssertionFailedf("expected a single table to restore")
+	}
+
+	ownedSequenceIDs := make(map[descpb.ID]struct{})
+	for _, col := range table.PublicColumns() {
+		for i := 0; i < col.NumOwnsSequences(); i++ {
+			ownedSequenceIDs[col.GetOwnsSequenceID(i)] = struct{}{}
+		}
+	}
+	if len(ownedSequenceIDs) == 0 {
+		return sqlDescs, table.GetID(), nil
+	}
+	allDescs, _ := loadSQLDescsFromBackupsAtTime(backupManifests, asOf)
+	for _, desc := range allDescs {
+		if _, ok := ownedSequenceIDs[desc.GetID()]; !ok {
+			continue
+		}
+		if desc.GetParentSchemaID() != table.GetParentSchemaID() {
+			return nil, descpb.InvalidID, errors.Errorf(
+				"cannot restore table %q under a new name: it owns sequence %q in a different schema",
+				table.GetName(), desc.GetName())
+		}
+		sqlDescs = append(sqlDescs, desc)
+	}
+	return sqlDescs, table.GetID(), nil
+}
+
+// renameTableForRestore renames the table restored by a RESTORE TABLE ... AS
+// statement to the last part of asTableName, and the sequences that it owns
+// accordingly. The rest of asTableName is resolved in the cluster to find the
+// database into which the table is restored, which is returned along with the
+// new names of the renamed descriptors.
+//
+// The table keeps the schema it has in the backup, so asTableName cannot
+// refer to another schema.
+func renameTableForRestore(
+	ctx context.Context,
+	p sql.PlanHookState,
+	asTableName *tree.UnresolvedObjectName,
+	tableID descpb.ID,
+	tablesByID map[descpb.ID]*tabledesc.Mutable,
+	schemasByID map[descpb.ID]*schemadesc.Mutable,
+) (intoDB string, newNames map[descpb.ID]string, _ error) {
+	table, ok := tablesByID[tableID]
+	if !ok {
+		return "", nil, errors.AssertionFailedf("missing table %d to restore", tableID)
+	}
+	prefix, _, err := resolver.ResolveTargetObject(ctx, p, asTableName)
+	if err != nil {
+		return "", nil, err
+	}
+	schemaName := tree.PublicSchema
+	if sc, ok := schemasByID[table.GetParentSchemaID()]; ok {
+		schemaName = sc.GetName()
+	}
+	if prefix.Schema.GetName() != schemaName {
+		return "", nil, pgerror.Newf(pgcode.InvalidSchemaName,
+			"cannot restore table %q into schema %q: the table is in schema %q in the backup",
+			table.GetName(), prefix.Schema.GetName(), schemaName)
+	}
+
+	oldName, newName := table.GetName(), asTableName.Object()
+	newNames = map[descpb.ID]string{table.GetID(): newName}
+	table.SetName(newName)
+	for _, desc := range tablesByID {
+		if !desc.IsSequence() || !desc.GetSequenceOpts().HasOwner() ||
+			desc.GetSequenceOpts().SequenceOwner.OwnerTableID != tableID {
+			continue
+		}
+		// Owned sequences are usually named after their table, as in t_id_seq.
+		seqName := desc.GetName()
+		if strings.HasPrefix(seqName, oldName+"_") {
+			seqName = newName + "_" + strings.TrimPrefix(seqName, oldName+"_")
+		} else {
+			seqName = newName + "_" + seqName
+		}
+		newNames[desc.GetID()] = seqName
+		desc.SetName(seqName)
+	}
+	return prefix.Database.GetName(), newNames, nil
+}
+
 // renameTargetDatabaseDescriptor updates the name in the target database
 // descriptor to the user specified new_db_name. We update the database
 // descriptor in both sqlDescs that contains all the descriptors being restored,
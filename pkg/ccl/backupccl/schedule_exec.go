@@ -10,13 +10,17 @@ package backupccl
 
 import (
 	"context"
+	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/scheduledjobs"
+	"github.com/cockroachdb/cockroach/pkg/scheduledjobs/schedulebase"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
@@ -105,7 +109,120 @@ func (e *scheduledBackupExecutor) executeBackup(
 	if err != nil {
 		return err
 	}
-	return invokeBackup(ctx, backupFn)
+	if err := invokeBackup(ctx, backupFn); err != nil {
+		return err
+	}
+	return maybeStartBackupCompaction(ctx, hook.(sql.PlanHookState), sj, backupStmt, txn)
+}
+
+// maybeStartBackupCompaction starts a job which compacts the chain of backups
+// an incremental schedule appends to. The job itself checks whether that chain
+// contains the number of incremental backups configured on the schedule, so
+// that no external storage is accessed in the transaction of the scheduler.
+// No job is started while a previous compaction of the same collection is
+// still running. Failures to plan the compaction are logged rather than
+// returned, so that they do not prevent the incremental backup itself from
+// running.
+func maybeStartBackupCompaction(
+	ctx context.Context,
+	p sql.PlanHookState,
+	sj *jobs.ScheduledJob,
+	backupStmt *annotatedBackupStatement,
+	txn *kv.Txn,
+) error {
+	args := &ScheduledBackupExecutionArgs{}
+	if err := pbtypes.UnmarshalAny(sj.ExecutionArgs().Args, args); err != nil {
+		return errors.Wrap(err, "un-marshaling args")
+	}
+	if args.CompactAfterIncrementals <= 0 || !backupStmt.AppendToLatest {
+		return nil
+	}
+	// Nodes running an older binary cannot run BACKUP COMPACTION jobs.
+	if !p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.BackupCompaction) {
+		log.Infof(ctx, "not compacting backups of schedule %d: requires all nodes to be upgraded to %s",
+			sj.ScheduleID(), clusterversion.ByKey(clusterversion.BackupCompaction))
+		return nil
+	}
+
+	record, err := planBackupCompaction(ctx, p, backupStmt, args.CompactAfterIncrementals)
+	if err != nil {
+		log.Warningf(ctx, "failed to plan compaction of backups of schedule %d: %v",
+			sj.ScheduleID(), err)
+		return nil
+	}
+
+	collectionURI := record.Details.(jobspb.BackupCompactionDetails).CollectionURI
+	running, err := jobs.RunningJobExists(ctx, jobspb.InvalidJobID, p.ExecCfg().InternalExecutor, txn,
+		func(payload *jobspb.Payload) bool {
+			if payload.Type() != jobspb.TypeBackupCompaction {
+				return false
+			}
+			return payload.GetBackupCompaction().CollectionURI == collectionURI
+		})
+	if err != nil {
+		return err
+	}
+	if running {
+		log.Infof(ctx, "not compacting backups of schedule %d: a previous compaction is still running",
+			sj.ScheduleID())
+		return nil
+	}
+
+	jobID := p.ExecCfg().JobRegistry.MakeJobID()
+	if _, err := p.ExecCfg().JobRegistry.CreateAdoptableJobWithTxn(ctx, *record, jobID, txn); err != nil {
+		return err
+	}
+	log.Infof(ctx, "started job %d to compact backups of schedule %d", jobID, sj.ScheduleID())
+	return nil
+}
+
+// planBackupCompaction returns the record of a job which compacts the chain of
+// the latest full backup in the collection the incremental backup statement
+// appends to, once that chain contains compactAfter incremental backups.
+func planBackupCompaction(
+	ctx context.Context, p sql.PlanHookState, backupStmt *annotatedBackupStatement, compactAfter int64,
+) (*jobs.Record, error) {
+	if len(backupStmt.To) != 1 {
+		return nil, errors.New("compacting locality-aware backups is not supported")
+	}
+	to, ok := backupStmt.To[0].(*tree.StrVal)
+	if !ok {
+		return nil, errors.Errorf("unexpected %T destination in backup statement", backupStmt.To[0])
+	}
+	collectionURI := to.RawString()
+
+	var encryptionParams jobspb.BackupEncryptionOptions
+	if backupStmt.Options.EncryptionPassphrase != nil {
+		pw, ok := backupStmt.Options.EncryptionPassphrase.(*tree.StrVal)
+		if !ok {
+			return nil, errors.Errorf("unexpected %T passphrase in backup statement",
+				backupStmt.Options.EncryptionPassphrase)
+		}
+		encryptionParams.Mode = jobspb.EncryptionMode_Passphrase
+		encryptionParams.RawPassphrae = pw.RawString()
+	} else if len(backupStmt.Options.EncryptionKMSURI) > 0 {
+		encryptionParams.Mode = jobspb.EncryptionMode_KMS
+		for _, expr := range backupStmt.Options.EncryptionKMSURI {
+			kmsURI, ok := expr.(*tree.StrVal)
+			if !ok {
+				return nil, errors.Errorf("unexpected %T kmsURI in backup statement", expr)
+			}
+			encryptionParams.RawKmsUris = append(encryptionParams.RawKmsUris, kmsURI.RawString())
+		}
+	}
+
+	return &jobs.Record{
+		Description: fmt.Sprintf("compaction of backups in %s",
+			RedactURIForErrorMessage(collectionURI)),
+		Username: p.User(),
+		Details: jobspb.BackupCompactionDetails{
+			CollectionURI:            collectionURI,
+			CompactAfterIncrementals: compactAfter,
+			EncryptionParams:         encryptionParams,
+		},
+		Progress:  jobspb.BackupCompactionProgress{},
+		CreatedBy: backupStmt.CreatedByInfo,
+	}, nil
 }
 
 func invokeBackup(ctx context.Context, backupFn sql.PlanHookRowFn) error {
@@ -251,27 +368,25 @@ func (e *scheduledBackupExecutor) GetCreateScheduleStatement(
 		return "", err
 	}
 
-	wait, err := parseOnPreviousRunningOption(sj.ScheduleDetails().Wait)
+	scheduleOptions, err := schedulebase.MakeScheduleOptions(firstRun, sj.ScheduleDetails())
 	if err != nil {
 		return "", err
 	}
-	onError, err := parseOnErrorOption(sj.ScheduleDetails().OnError)
-	if err != nil {
-		return "", err
+
+	// Compaction is configured on the incremental schedule.
+	compactAfterIncrementals := args.CompactAfterIncrementals
+	if dependentSchedule != nil && !backupNode.AppendToLatest {
+		dependentArgs := &ScheduledBackupExecutionArgs{}
+		if err := pbtypes.UnmarshalAny(dependentSchedule.ExecutionArgs().Args, dependentArgs); err != nil {
+			return "", errors.Wrap(err, "un-marshaling args")
+		}
+		compactAfterIncrementals = dependentArgs.CompactAfterIncrementals
 	}
-	scheduleOptions := tree.KVOptions{
-		tree.KVOption{
-			Key:   optFirstRun,
-			Value: firstRun,
-		},
-		tree.KVOption{
-			Key:   optOnExecFailure,
-			Value: tree.NewDString(onError),
-		},
-		tree.KVOption{
-			Key:   optOnPreviousRunning,
-			Value: tree.NewDString(wait),
-		},
+	if compactAfterIncrementals > 0 {
+		scheduleOptions = append(scheduleOptions, tree.KVOption{
+			Key:   optCompactAfterIncrementals,
+			Value: tree.NewDString(strconv.FormatInt(compactAfterIncrementals, 10)),
+		})
 	}
 
 	var destinations []string
@@ -100,11 +100,39 @@ func (m manifestInfoReader) showBackup(
 	incPaths []string,
 	resultsCh chan<- tree.Datums,
 ) error {
-	var memSize int64
-	defer func() {
-		mem.Shrink(ctx, memSize)
-	}()
+	manifests, memSize, err := readBackupManifestChain(ctx, mem, store, incStore, enc, incPaths)
+	if err != nil {
+		return err
+	}
+	defer mem.Shrink(ctx, memSize)
+
+	datums, err := m.shower.fn(manifests)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range datums {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case resultsCh <- row:
+		}
+	}
+	return nil
+}
 
+// readBackupManifestChain reads the manifest of the base backup in store and
+// of each of the incremental layers at incPaths in incStore. The returned size
+// has been reserved in mem and must be released by the caller.
+func readBackupManifestChain(
+	ctx context.Context,
+	mem *mon.BoundAccount,
+	store cloud.ExternalStorage,
+	incStore cloud.ExternalStorage,
+	enc *jobspb.BackupEncryptionOptions,
+	incPaths []string,
+) ([]BackupManifest, int64, error) {
+	var memSize int64
 	var err error
 	manifests := make([]BackupManifest, len(incPaths)+1)
 	manifests[0], memSize, err = ReadBackupManifestFromStore(ctx, mem, store, enc)
@@ -114,20 +142,21 @@ func (m manifestInfoReader) showBackup(
 			latestFileExists, errLatestFile := checkForLatestFileInCollection(ctx, store)
 
 			if errLatestFile == nil && latestFileExists {
-				return errors.WithHintf(err, "The specified path is the root of a backup collection. "+
+				return nil, 0, errors.WithHintf(err, "The specified path is the root of a backup collection. "+
 					"Use SHOW BACKUPS IN with this path to list all the backup subdirectories in the"+
 					" collection. SHOW BACKUP can be used with any of these subdirectories to inspect a"+
 					" backup.")
 			}
-			return errors.CombineErrors(err, errLatestFile)
+			return nil, 0, errors.CombineErrors(err, errLatestFile)
 		}
-		return err
+		return nil, 0, err
 	}
 
 	for i := range incPaths {
 		m, sz, err := readBackupManifest(ctx, mem, incStore, incPaths[i], enc)
 		if err != nil {
-			return err
+			mem.Shrink(ctx, memSize)
+			return nil, 0, err
 		}
 		memSize += sz
 		// Blank the stats to prevent memory blowup.
@@ -145,22 +174,10 @@ func (m manifestInfoReader) showBackup(
 	// etc.
 	err = maybeUpgradeDescriptorsInBackupManifests(ctx, manifests, true /* skipFKsWithNoMatchingTable */)
 	if err != nil {
-		return err
-	}
-
-	datums, err := m.shower.fn(manifests)
-	if err != nil {
-		return err
-	}
-
-	for _, row := range datums {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case resultsCh <- row:
-		}
+		mem.Shrink(ctx, memSize)
+		return nil, 0, err
 	}
-	return nil
+	return manifests, memSize, nil
 }
 
 // showBackupPlanHook implements PlanHookFn.
@@ -189,6 +206,14 @@ func showBackupPlanHook(
 		}
 	}
 
+	var localityPathsFn func() ([]string, error)
+	if len(backup.LocalityPaths) > 0 {
+		localityPathsFn, err = p.TypeAsStringArray(ctx, backup.LocalityPaths, "SHOW BACKUP")
+		if err != nil {
+			return nil, nil, nil, false, err
+		}
+	}
+
 	expected := map[string]sql.KVStringOptValidate{
 		backupOptEncPassphrase:  sql.KVStringOptRequireValue,
 		backupOptEncKMS:         sql.KVStringOptRequireValue,
@@ -196,6 +221,7 @@ func showBackupPlanHook(
 		backupOptAsJSON:         sql.KVStringOptRequireNoValue,
 		backupOptWithDebugIDs:   sql.KVStringOptRequireNoValue,
 		backupOptIncStorage:     sql.KVStringOptRequireValue,
+		backupOptCheckFiles:     sql.KVStringOptRequireNoValue,
 	}
 	optsFn, err := p.TypeAsStringOpts(ctx, backup.Options, expected)
 	if err != nil {
@@ -223,6 +249,30 @@ func showBackupPlanHook(
 		shower = backupShowerDefault(ctx, p, backup.ShouldIncludeSchemas, opts)
 	}
 	infoReader = manifestInfoReader{shower}
+	_, checkFiles := opts[backupOptCheckFiles]
+	if checkFiles {
+		if backup.Details != tree.BackupDefaultDetails {
+			return nil, nil, nil, false, errors.Newf(
+				"cannot use %q option with SHOW BACKUP RANGES, FILES or as_json", backupOptCheckFiles)
+		}
+		infoReader = backupFileChecker{
+			settings:         p.ExecCfg().Settings,
+			ioConf:           p.ExecCfg().ExternalIODirConfig,
+			makeCloudStorage: p.ExecCfg().DistSQLSrv.ExternalStorageFromURI,
+			user:             p.User(),
+		}
+	}
+	if localityPathsFn != nil {
+		if !checkFiles {
+			return nil, nil, nil, false, errors.Newf(
+				"the locations of a locality-aware backup can only be listed with the %q option",
+				backupOptCheckFiles)
+		}
+		if _, ok := opts[backupOptIncStorage]; ok {
+			return nil, nil, nil, false, errors.Newf(
+				"cannot use %q option with the locations of a locality-aware backup", backupOptIncStorage)
+		}
+	}
 
 	fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
 		// TODO(dan): Move this span into sql.
@@ -244,6 +294,23 @@ func showBackupPlanHook(
 			}
 		}
 
+		var localityURIs map[string]string
+		if localityPathsFn != nil {
+			localityPaths, err := localityPathsFn()
+			if err != nil {
+				return err
+			}
+			dest, localityURIs, err = getURIsByLocalityKV(append([]string{dest}, localityPaths...), "")
+			if err != nil {
+				return err
+			}
+			for _, uri := range localityURIs {
+				if err := checkShowBackupURIPrivileges(ctx, p, uri); err != nil {
+					return err
+				}
+			}
+		}
+
 		if err := checkShowBackupURIPrivileges(ctx, p, dest); err != nil {
 			return err
 		}
@@ -261,6 +328,14 @@ func showBackupPlanHook(
 			}
 			parsed.Path = path.Join(parsed.Path, subdir)
 			dest = parsed.String()
+			for kv, uri := range localityURIs {
+				parsed, err := url.Parse(uri)
+				if err != nil {
+					return err
+				}
+				parsed.Path = path.Join(parsed.Path, subdir)
+				localityURIs[kv] = parsed.String()
+			}
 		}
 
 		store, err := p.ExecCfg().DistSQLSrv.ExternalStorageFromURI(ctx, dest, p.User())
@@ -327,7 +402,12 @@ func showBackupPlanHook(
 		mem := p.ExecCfg().RootMemoryMonitor.MakeBoundAccount()
 		defer mem.Close(ctx)
 
-		return infoReader.showBackup(ctx, &mem, store, incStore, encryption, incPaths, resultsCh)
+		reader := infoReader
+		if checker, ok := reader.(backupFileChecker); ok {
+			checker.localityURIs = localityURIs
+			reader = checker
+		}
+		return reader.showBackup(ctx, &mem, store, incStore, encryption, incPaths, resultsCh)
 	}
 
 	return fn, infoReader.header(), nil, false, nil
@@ -393,7 +473,7 @@ func backupShowerDefault(
 				schemaIDToName := make(map[descpb.ID]string)
 				schemaIDToName[keys.PublicSchemaIDForBackup] = catconstants.PublicSchemaName
 				for i := range manifest.Descriptors {
-					_, db, _, schema := descpb.FromDescriptor(&manifest.Descriptors[i])
+					_, db, _, schema, _ := descpb.FromDescriptor(&manifest.Descriptors[i])
 					if db != nil {
 						if _, ok := dbIDToName[db.ID]; !ok {
 							dbIDToName[db.ID] = db.Name
@@ -700,6 +780,65 @@ var jsonShower = backupShower{
 	},
 }
 
+// readBackupManifestChain reads the manifest of the base backup in store and
+// of each of the incremental layers at incPaths in incStore. The returned size
+// has been reserved in mem and must be released by the caller.
+func readBackupManifestChain(
+	ctx context.Context,
+	mem *mon.BoundAccount,
+	store cloud.ExternalStorage,
+	incStore cloud.ExternalStorage,
+	enc *jobspb.BackupEncryptionOptions,
+	incPaths []string,
+) ([]BackupManifest, int64, error) {
+	var memSize int64
+	var err error
+	manifests := make([]BackupManifest, len(incPaths)+1)
+	manifests[0], memSize, err = ReadBackupManifestFromStore(ctx, mem, store, enc)
+
+	if err != nil {
+		if errors.Is(err, cloud.ErrFileDoesNotExist) {
+			latestFileExists, errLatestFile := checkForLatestFileInCollection(ctx, store)
+
+			if errLatestFile == nil && latestFileExists {
+				return nil, 0, errors.WithHintf(err, "The specified path is the root of a backup collection. "+
+					"Use SHOW BACKUPS IN with this path to list all the backup subdirectories in the"+
+					" collection. SHOW BACKUP can be used with any of these subdirectories to inspect a"+
+					" backup.")
+			}
+			return nil, 0, errors.CombineErrors(err, errLatestFile)
+		}
+		return nil, 0, err
+	}
+
+	for i := range incPaths {
+		m, sz, err := readBackupManifest(ctx, mem, incStore, incPaths[i], enc)
+		if err != nil {
+			mem.Shrink(ctx, memSize)
+			return nil, 0, err
+		}
+		memSize += sz
+		// Blank the stats to prevent memory blowup.
+		m.DeprecatedStatistics = nil
+		manifests[i+1] = m
+	}
+
+	// Ensure that the descriptors in the backup manifests are up to date.
+	//
+	// This is necessary in particular for upgrading descriptors with old-style
+	// foreign keys which are no longer supported.
+	// If we are restoring a backup with old-style foreign keys, skip over the
+	// FKs for which we can't resolve the cross-table references. We can't
+	// display them anyway, because we don't have the referenced table names,
+	// etc.
+	err = maybeUpgradeDescriptorsInBackupManifests(ctx, manifests, true /* skipFKsWithNoMatchingTable */)
+	if err != nil {
+		mem.Shrink(ctx, memSize)
+		return nil, 0, err
+	}
+	return manifests, memSize, nil
+}
+
 // showBackupPlanHook implements PlanHookFn.
 func showBackupsInCollectionPlanHook(
 	ctx context.Context, backup *tree.ShowBackup, p sql.PlanHookState,
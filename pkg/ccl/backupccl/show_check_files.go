@@ -0,0 +1,219 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/errors"
+)
+
+// backupFileChecker implements SHOW BACKUP ... WITH check_files. Rather than
+// describing the contents of the backup, it verifies that every layer in the
+// chain can actually be restored: each SST referenced by a manifest must exist,
+// decrypt with the supplied key, pass the checksums of its blocks and contain
+// as much data as the manifest claims it does, and the layers together must
+// cover their spans without gaps. Every problem found is returned as a row, so
+// an empty result means the backup passed all checks.
+//
+// The SSTs of a locality-aware backup which were written to locality-specific
+// locations are read from localityURIs, keyed by the locality KV of the
+// location.
+type backupFileChecker struct {
+	settings         *cluster.Settings
+	ioConf           base.ExternalIODirConfig
+	makeCloudStorage cloud.ExternalStorageFromURIFactory
+	user             security.SQLUsername
+	localityURIs     map[string]string
+}
+
+var _ backupInfoReader = backupFileChecker{}
+
+func (c backupFileChecker) header() colinfo.ResultColumns {
+	return colinfo.ResultColumns{
+		{Name: "backup_path", Typ: types.String},
+		{Name: "file", Typ: types.String},
+		{Name: "problem", Typ: types.String},
+	}
+}
+
+// showBackup implements the backupInfoReader interface.
+func (c backupFileChecker) showBackup(
+	ctx context.Context,
+	mem *mon.BoundAccount,
+	store cloud.ExternalStorage,
+	incStore cloud.ExternalStorage,
+	enc *jobspb.BackupEncryptionOptions,
+	incPaths []string,
+	resultsCh chan<- tree.Datums,
+) error {
+	manifests, memSize, err := readBackupManifestChain(ctx, mem, store, incStore, enc, incPaths)
+	if err != nil {
+		return err
+	}
+	defer mem.Shrink(ctx, memSize)
+
+	var fileEnc *roachpb.FileEncryptionOptions
+	if enc != nil {
+		key, err := getEncryptionKey(ctx, enc, c.settings, c.ioConf)
+		if err != nil {
+			return err
+		}
+		fileEnc = &roachpb.FileEncryptionOptions{Key: key}
+	}
+
+	emit := func(backupPath string, file tree.Datum, problem string) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case resultsCh <- tree.Datums{tree.NewDString(backupPath), file, tree.NewDString(problem)}:
+			return nil
+		}
+	}
+
+	localityStores := make(map[string]cloud.ExternalStorage, len(c.localityURIs))
+	defer func() {
+		for _, s := range localityStores {
+			s.Close()
+		}
+	}()
+	for kv, uri := range c.localityURIs {
+		s, err := c.makeCloudStorage(ctx, uri, c.user)
+		if err != nil {
+			return errors.Wrapf(err, "make storage for locality %s", kv)
+		}
+		localityStores[kv] = s
+	}
+
+	// The SSTs are identified by their locality and path, since the paths are
+	// only unique within a location.
+	type sstKey struct{ localityKV, path string }
+
+	for i := range manifests {
+		layerStore, layerDir, backupPath := store, "", "/"
+		if i > 0 {
+			layerStore, layerDir = incStore, path.Dir(incPaths[i-1])
+			backupPath = layerDir
+		}
+
+		if err := checkCoverage(ctx, manifests[i].Spans, manifests[:i+1]); err != nil {
+			if err := emit(backupPath, tree.DNull, err.Error()); err != nil {
+				return err
+			}
+		}
+
+		// A single SST may hold the data of several entries in the manifest, so
+		// aggregate the expected size of each SST before reading it.
+		var ssts []sstKey
+		expectedSizes := make(map[sstKey]int64)
+		var missingLocalities []string
+		missingFiles := make(map[string]int)
+		for _, f := range manifests[i].Files {
+			if _, ok := localityStores[f.LocalityKV]; f.LocalityKV != "" && !ok {
+				if missingFiles[f.LocalityKV] == 0 {
+					missingLocalities = append(missingLocalities, f.LocalityKV)
+				}
+				missingFiles[f.LocalityKV]++
+				continue
+			}
+			k := sstKey{localityKV: f.LocalityKV, path: f.Path}
+			if _, ok := expectedSizes[k]; !ok {
+				ssts = append(ssts, k)
+			}
+			expectedSizes[k] += f.EntryCounts.DataSize
+		}
+		for _, kv := range missingLocalities {
+			if err := emit(backupPath, tree.DNull, fmt.Sprintf(
+				"%d files stored in locality %s were not checked: no location was given for it",
+				missingFiles[kv], kv,
+			)); err != nil {
+				return err
+			}
+		}
+
+		for _, sst := range ssts {
+			s := layerStore
+			if sst.localityKV != "" {
+				s = localityStores[sst.localityKV]
+			}
+			problem, err := checkBackupFile(ctx, s, path.Join(layerDir, sst.path), fileEnc, expectedSizes[sst])
+			if err != nil {
+				return err
+			}
+			if problem != "" {
+				if err := emit(backupPath, tree.NewDString(sst.path), problem); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// checkBackupFile reads every key in the SST at basename and describes the
+// first problem found with it, or returns an empty string if there is none.
+// Only errors that prevent the check from running at all, such as a canceled
+// context, are returned as errors.
+func checkBackupFile(
+	ctx context.Context,
+	store cloud.ExternalStorage,
+	basename string,
+	enc *roachpb.FileEncryptionOptions,
+	expectedSize int64,
+) (string, error) {
+	if _, err := store.Size(ctx, basename); err != nil {
+		if errors.Is(err, cloud.ErrFileDoesNotExist) {
+			return "file does not exist", nil
+		}
+		return "", err
+	}
+
+	iter, err := storageccl.ExternalSSTReader(ctx, store, basename, enc)
+	if err != nil {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("cannot open file: %v", err), nil
+	}
+	defer iter.Close()
+
+	// Pebble verifies the checksum of every block as it is loaded, so reading
+	// all the keys is enough to detect corruption of the SST.
+	var size int64
+	for iter.SeekGE(storage.MVCCKey{Key: keys.MinKey}); ; iter.Next() {
+		if ok, err := iter.Valid(); err != nil {
+			if err := ctx.Err(); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("cannot read file: %v", err), nil
+		} else if !ok {
+			break
+		}
+		size += int64(len(iter.UnsafeKey().Key) + len(iter.UnsafeValue()))
+	}
+	if size != expectedSize {
+		return fmt.Sprintf("file contains %d bytes of data, expected %d", size, expectedSize), nil
+	}
+	return "", nil
+}
@@ -690,3 +690,53 @@ func TestShowBackupPathIsCollectionRoot(t *testing.T) {
 	sqlDB.ExpectErr(t, "The specified path is the root of a backup collection.",
 		"SHOW BACKUP $1", LocalFoo)
 }
+
+func TestShowBackupCheckFiles(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const numAccounts = 11
+	_, sqlDB, tempDir, cleanupFn := BackupRestoreTestSetup(t, singleNode, numAccounts, InitManualReplication)
+	defer cleanupFn()
+
+	const full, encrypted = LocalFoo + "/full", LocalFoo + "/encrypted"
+	sqlDB.Exec(t, `BACKUP DATABASE data TO $1`, full)
+	sqlDB.Exec(t, `UPDATE data.bank SET balance = balance + 1`)
+	sqlDB.Exec(t, `BACKUP DATABASE data TO $1`, full)
+	sqlDB.Exec(t, `BACKUP DATABASE data TO $1 WITH encryption_passphrase = 'abc'`, encrypted)
+
+	const checkQuery = `SELECT backup_path, file, problem FROM [SHOW BACKUP $1 WITH check_files] ORDER BY 1, 2`
+	require.Empty(t, sqlDB.QueryStr(t, checkQuery, full))
+	require.Empty(t, sqlDB.QueryStr(t,
+		`SELECT * FROM [SHOW BACKUP $1 WITH check_files, encryption_passphrase = 'abc']`, encrypted))
+
+	sqlDB.ExpectErr(t, `cannot use "check_files" option`,
+		`SHOW BACKUP FILES $1 WITH check_files`, full)
+
+	// Truncate a file in the full backup and remove one from the incremental
+	// layer.
+	dir := filepath.Join(tempDir, "foo", "full")
+	fullFiles, err := filepath.Glob(filepath.Join(dir, "data", "*.sst"))
+	require.NoError(t, err)
+	require.NotEmpty(t, fullFiles)
+	content, err := ioutil.ReadFile(fullFiles[0])
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(fullFiles[0], content[:len(content)/2], 0644))
+
+	incFiles, err := filepath.Glob(filepath.Join(dir, "*", "*", "data", "*.sst"))
+	require.NoError(t, err)
+	require.NotEmpty(t, incFiles)
+	require.NoError(t, os.Remove(incFiles[0]))
+
+	res := sqlDB.QueryStr(t, checkQuery, full)
+	require.Len(t, res, 2)
+	incPath, err := filepath.Rel(dir, filepath.Dir(filepath.Dir(incFiles[0])))
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"/", "data/" + filepath.Base(fullFiles[0]),
+	}, res[0][:2])
+	require.Regexp(t, "cannot open file", res[0][2])
+	require.Equal(t, []string{
+		"/" + incPath, "data/" + filepath.Base(incFiles[0]), "file does not exist",
+	}, res[1])
+}
@@ -282,9 +282,14 @@ func (ca *changeAggregator) Start(ctx context.Context) {
 	if ca.spec.Feed.Opts[changefeedbase.OptFormat] == string(changefeedbase.OptFormatNative) {
 		ca.eventConsumer = newNativeKVConsumer(ca.sink)
 	} else {
-		ca.eventConsumer = newKVEventToRowConsumer(
-			ctx, ca.flowCtx.Cfg, ca.frontier.SpanFrontier(), initialHighWater,
-			ca.sink, ca.encoder, ca.spec.Feed, ca.knobs)
+		ca.eventConsumer, err = newKVEventToRowConsumer(
+			ctx, ca.flowCtx.Cfg, ca.flowCtx.NewEvalCtx(), ca.frontier.SpanFrontier(),
+			initialHighWater, ca.sink, ca.encoder, ca.spec.Feed, ca.knobs)
+		if err != nil {
+			ca.MoveToDraining(err)
+			ca.cancel()
+			return
+		}
 	}
 }
 
@@ -339,7 +344,8 @@ func (ca *changeAggregator) makeKVFeedCfg(
 		ca.spec.Feed.Opts[changefeedbase.OptSchemaChangeEvents])
 	schemaChangePolicy := changefeedbase.SchemaChangePolicy(
 		ca.spec.Feed.Opts[changefeedbase.OptSchemaChangePolicy])
-	_, withDiff := ca.spec.Feed.Opts[changefeedbase.OptDiff]
+	withDiff := changefeedNeedsPrevValues(ca.spec.Feed)
+	_, initialScanOnly := ca.spec.Feed.Opts[changefeedbase.OptInitialScanOnly]
 	cfg := ca.flowCtx.Cfg
 
 	var sf schemafeed.SchemaFeed
@@ -366,6 +372,7 @@ func (ca *changeAggregator) makeKVFeedCfg(
 		InitialHighWater:   initialHighWater,
 		WithDiff:           withDiff,
 		NeedsInitialScan:   needsInitialScan,
+		InitialScanOnly:    initialScanOnly,
 		SchemaChangeEvents: schemaChangeEvents,
 		SchemaChangePolicy: schemaChangePolicy,
 		SchemaFeed:         sf,
@@ -634,6 +641,9 @@ type kvEventToRowConsumer struct {
 	rfCache   *rowFetcherCache
 	details   jobspb.ChangefeedDetails
 	kvFetcher row.SpanKVFetcher
+	// query is the changefeed query applied to each row, or nil if the
+	// changefeed was not created with a query.
+	query *changefeedQuery
 }
 
 var _ kvEventConsumer = &kvEventToRowConsumer{}
@@ -641,13 +651,22 @@ var _ kvEventConsumer = &kvEventToRowConsumer{}
 func newKVEventToRowConsumer(
 	ctx context.Context,
 	cfg *execinfra.ServerConfig,
+	evalCtx *tree.EvalContext,
 	frontier *span.Frontier,
 	cursor hlc.Timestamp,
 	sink Sink,
 	encoder Encoder,
 	details jobspb.ChangefeedDetails,
 	knobs TestingKnobs,
-) kvEventConsumer {
+) (kvEventConsumer, error) {
+	var query *changefeedQuery
+	if details.Select != `` {
+		var err error
+		if query, err = newChangefeedQuery(details.Select, evalCtx); err != nil {
+			return nil, err
+		}
+	}
+
 	rfCache := newRowFetcherCache(
 		ctx,
 		cfg.Codec,
@@ -664,7 +683,8 @@ func newKVEventToRowConsumer(
 		rfCache:  rfCache,
 		details:  details,
 		knobs:    knobs,
-	}
+		query:    query,
+	}, nil
 }
 
 type tableDescriptorTopic struct {
@@ -695,6 +715,19 @@ func (c *kvEventToRowConsumer) ConsumeEvent(ctx context.Context, ev kvevent.Even
 			"or equal to the local frontier %s.", r.updated, c.frontier.Frontier())
 		return nil
 	}
+
+	if c.query != nil {
+		matches, err := c.query.eval(ctx, &r)
+		if err != nil {
+			return err
+		}
+		if !matches {
+			a := ev.DetachAlloc()
+			a.Release(ctx)
+			return nil
+		}
+	}
+
 	var keyCopy, valueCopy []byte
 	encodedKey, err := c.encoder.EncodeKey(ctx, r)
 	if err != nil {
@@ -783,8 +816,7 @@ func (c *kvEventToRowConsumer) eventToRow(
 	}
 
 	// Get prev value, if necessary.
-	_, withDiff := c.details.Opts[changefeedbase.OptDiff]
-	if withDiff {
+	if changefeedNeedsPrevValues(c.details) {
 		prevRF := rf
 		r.prevTableDesc = r.tableDesc
 		if prevSchemaTimestamp != schemaTimestamp {
@@ -1236,6 +1268,13 @@ func (cf *changeFrontier) Next() (rowenc.EncDatumRow, *execinfrapb.ProducerMetad
 		if cf.frontier.schemaChangeBoundaryReached() &&
 			(cf.frontier.boundaryType == jobspb.ResolvedSpan_EXIT ||
 				cf.frontier.boundaryType == jobspb.ResolvedSpan_RESTART) {
+			if _, initialScanOnly := cf.spec.Feed.Opts[changefeedbase.OptInitialScanOnly]; initialScanOnly &&
+				cf.frontier.boundaryType == jobspb.ResolvedSpan_EXIT {
+				// The kvfeed exits after the initial scan, resolving all spans at
+				// the scan time; the changefeed is done.
+				cf.MoveToDraining(nil /* err */)
+				break
+			}
 			err := pgerror.Newf(pgcode.SchemaChangeOccurred,
 				"schema change occurred at %v", cf.frontier.boundaryTime.Next().AsOfSystemTime())
 
@@ -0,0 +1,395 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemaexpr"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/transform"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/errors"
+)
+
+// changefeedQuery evaluates the projection and the filter of a CDC query
+// (CREATE CHANGEFEED ... AS SELECT) against the rows decoded by the change
+// aggregator, so that only the matching rows and the selected columns are
+// sent to the sink.
+type changefeedQuery struct {
+	sel     *tree.SelectClause
+	evalCtx *tree.EvalContext
+	alloc   tree.DatumAlloc
+
+	// compiled caches the query compiled against each version of the target
+	// table seen so far. Rows are decoded using the descriptor version that
+	// was valid at their timestamp, so the query must be recompiled whenever
+	// the schema changes.
+	compiled map[idVersion]*compiledChangefeedQuery
+}
+
+// compiledChangefeedQuery is a changefeedQuery which has been type checked
+// against a specific version of the target table.
+type compiledChangefeedQuery struct {
+	// names are the names of the projected columns.
+	names []string
+	// exprs are the projected expressions, in terms of IndexedVars referring
+	// to the public columns of the table.
+	exprs []tree.TypedExpr
+	// where is the filter, or nil if the query has no WHERE clause.
+	where tree.TypedExpr
+	ivars *changefeedQueryIVarContainer
+}
+
+// projectedRow is the result of evaluating the projection of a changefeed
+// query against a row.
+type projectedRow struct {
+	names  []string
+	datums tree.Datums
+}
+
+// newChangefeedQuery parses the given SELECT clause of a changefeed query.
+func newChangefeedQuery(sel string, evalCtx *tree.EvalContext) (*changefeedQuery, error) {
+	clause, err := parseChangefeedQuery(sel)
+	if err != nil {
+		return nil, err
+	}
+	return &changefeedQuery{
+		sel:      clause,
+		evalCtx:  evalCtx,
+		compiled: make(map[idVersion]*compiledChangefeedQuery),
+	}, nil
+}
+
+// parseChangefeedQuery parses the SELECT clause of a changefeed query and
+// verifies that it only uses the supported subset of SELECT: a projection
+// and a filter over a single table.
+func parseChangefeedQuery(sel string) (*tree.SelectClause, error) {
+	stmt, err := parser.ParseOne(sel)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := stmt.AST.(*tree.Select)
+	if !ok {
+		return nil, errors.AssertionFailedf("expected a SELECT statement, found %s", stmt.AST.StatementTag())
+	}
+	if s.With != nil || s.OrderBy != nil || s.Limit != nil || s.Locking != nil {
+		return nil, pgerror.New(pgcode.FeatureNotSupported,
+			"changefeed queries only support projections and WHERE clauses")
+	}
+	clause, ok := s.Select.(*tree.SelectClause)
+	if !ok {
+		return nil, pgerror.New(pgcode.FeatureNotSupported,
+			"changefeed queries only support projections and WHERE clauses")
+	}
+	if clause.Distinct || clause.DistinctOn != nil || clause.GroupBy != nil ||
+		clause.Having != nil || clause.Window != nil || clause.TableSelect ||
+		clause.From.AsOf.Expr != nil || len(clause.From.Tables) != 1 {
+		return nil, pgerror.New(pgcode.FeatureNotSupported,
+			"changefeed queries only support projections and WHERE clauses")
+	}
+	return clause, nil
+}
+
+// changefeedNeedsPrevValues returns whether the previous values of the changed
+// rows must be fetched along with their new values: either because they are
+// emitted (with the diff option), or because the filter of the changefeed
+// query must be evaluated against them to detect the rows which stop passing
+// it.
+func changefeedNeedsPrevValues(details jobspb.ChangefeedDetails) bool {
+	if _, withDiff := details.Opts[changefeedbase.OptDiff]; withDiff {
+		return true
+	}
+	if details.Select == `` {
+		return false
+	}
+	clause, err := parseChangefeedQuery(details.Select)
+	// The query is validated when the changefeed is created. If it cannot be
+	// parsed anymore, the change aggregator fails to compile it anyway.
+	return err != nil || clause.Where != nil
+}
+
+// tableName returns the name of the table the query selects from, as written
+// in the query.
+func (q *changefeedQuery) tableName() (*tree.TableName, error) {
+	aliased, ok := q.sel.From.Tables[0].(*tree.AliasedTableExpr)
+	if !ok {
+		return nil, errors.AssertionFailedf("unexpected FROM clause %s", tree.AsString(&q.sel.From))
+	}
+	tn, ok := aliased.Expr.(*tree.TableName)
+	if !ok {
+		return nil, errors.AssertionFailedf("unexpected FROM clause %s", tree.AsString(&q.sel.From))
+	}
+	return tn, nil
+}
+
+// compile type checks the query against the given version of the target
+// table, or returns a cached result of doing so.
+func (q *changefeedQuery) compile(
+	ctx context.Context, desc catalog.TableDescriptor,
+) (*compiledChangefeedQuery, error) {
+	idVer := idVersion{id: desc.GetID(), version: desc.GetVersion()}
+	if c, ok := q.compiled[idVer]; ok {
+		return c, nil
+	}
+
+	tn, err := q.tableName()
+	if err != nil {
+		return nil, err
+	}
+	// The columns must match the ones decoded by the row fetchers of the
+	// rowFetcherCache.
+	cols := desc.PublicColumns()
+	ivars := &changefeedQueryIVarContainer{cols: cols}
+	ivarHelper := tree.MakeIndexedVarHelper(ivars, len(cols))
+	source := colinfo.NewSourceInfoForSingleTable(
+		*tn, colinfo.ResultColumnsFromColumns(desc.GetID(), cols),
+	)
+	semaCtx := tree.MakeSemaContext()
+	semaCtx.IVarContainer = ivars
+
+	// Expressions must only depend on the values of the row, which rules out
+	// everything but immutable scalar expressions.
+	const rejectFlags = tree.RejectSpecial | tree.RejectSubqueries |
+		tree.RejectStableOperators | tree.RejectVolatileFunctions
+	var txCtx transform.ExprTransformContext
+	typeCheck := func(expr tree.Expr, desired *types.T, context string) (tree.TypedExpr, error) {
+		var v schemaexpr.NameResolutionVisitor
+		expr, err := schemaexpr.ResolveNamesUsingVisitor(
+			&v, expr, source, ivarHelper, q.evalCtx.SessionData().SearchPath,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkNoVirtualColumnRefs(expr, cols); err != nil {
+			return nil, err
+		}
+		semaCtx.Properties.Require(context, rejectFlags)
+		typedExpr, err := tree.TypeCheck(ctx, expr, &semaCtx, desired)
+		if err != nil {
+			return nil, err
+		}
+		return txCtx.NormalizeExpr(q.evalCtx, typedExpr)
+	}
+
+	c := &compiledChangefeedQuery{ivars: ivars}
+	for _, target := range q.sel.Exprs {
+		if isStar, err := isStarExpr(target.Expr); err != nil {
+			return nil, err
+		} else if isStar {
+			if target.As != "" {
+				return nil, pgerror.New(pgcode.Syntax, "\"*\" cannot be aliased")
+			}
+			for i, col := range cols {
+				if col.IsVirtual() {
+					continue
+				}
+				c.names = append(c.names, col.GetName())
+				c.exprs = append(c.exprs, ivarHelper.IndexedVar(i))
+			}
+			continue
+		}
+		name, err := tree.GetRenderColName(q.evalCtx.SessionData().SearchPath, target)
+		if err != nil {
+			return nil, err
+		}
+		typedExpr, err := typeCheck(target.Expr, types.Any, "CHANGEFEED projection")
+		if err != nil {
+			return nil, err
+		}
+		c.names = append(c.names, name)
+		c.exprs = append(c.exprs, typedExpr)
+	}
+	// The projection is encoded as an object keyed by column name, so the
+	// names must be unique.
+	seen := make(map[string]struct{}, len(c.names))
+	for _, name := range c.names {
+		if _, ok := seen[name]; ok {
+			return nil, pgerror.Newf(pgcode.DuplicateColumn,
+				"column name %q specified more than once in CHANGEFEED query", name)
+		}
+		seen[name] = struct{}{}
+	}
+	if q.sel.Where != nil {
+		if c.where, err = typeCheck(q.sel.Where.Expr, types.Bool, "CHANGEFEED filter"); err != nil {
+			return nil, err
+		}
+	}
+
+	q.compiled[idVer] = c
+	return c, nil
+}
+
+// eval applies the query to the given row. It returns false if the row should
+// not be emitted; otherwise it sets the projection (and, if the previous value
+// of the row passed the filter, the previous projection) of the row.
+//
+// The filter is evaluated against both the new and the previous value of the
+// row, which are always fetched for queries with a filter (see
+// changefeedNeedsPrevValues). A row which passes the filter is emitted. A row
+// which passed the filter before it was deleted, or before it was updated so
+// that it no longer does, is emitted as a deletion so that consumers drop it.
+// Other rows, including the deletions of rows which never passed the filter,
+// are not emitted.
+func (q *changefeedQuery) eval(ctx context.Context, r *encodeRow) (bool, error) {
+	var prevMatches bool
+	if r.prevDatums != nil && !r.prevDeleted {
+		var err error
+		r.prevProjection, prevMatches, err = q.evalRow(ctx, r.prevTableDesc, r.prevDatums)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if r.deleted {
+		// Without a filter, every deletion is emitted, even if the previous
+		// value of the row is not known.
+		return prevMatches || q.sel.Where == nil, nil
+	}
+
+	projection, matches, err := q.evalRow(ctx, r.tableDesc, r.datums)
+	if err != nil {
+		return false, err
+	}
+	if matches {
+		r.projection = projection
+		return true, nil
+	}
+	if !prevMatches {
+		return false, nil
+	}
+	// The row no longer passes the filter.
+	r.deleted = true
+	return true, nil
+}
+
+// evalRow evaluates the filter of the query against a row decoded with the
+// given version of the table and, if the row passes the filter, returns its
+// projection.
+func (q *changefeedQuery) evalRow(
+	ctx context.Context, desc catalog.TableDescriptor, row rowenc.EncDatumRow,
+) (_ *projectedRow, matches bool, _ error) {
+	c, err := q.compile(ctx, desc)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := c.ivars.setRow(row, &q.alloc); err != nil {
+		return nil, false, err
+	}
+	q.evalCtx.PushIVarContainer(c.ivars)
+	defer q.evalCtx.PopIVarContainer()
+	if c.where != nil {
+		d, err := c.where.Eval(q.evalCtx)
+		if err != nil {
+			return nil, false, err
+		}
+		if d != tree.DBoolTrue {
+			return nil, false, nil
+		}
+	}
+	p, err := c.project(q.evalCtx)
+	if err != nil {
+		return nil, false, err
+	}
+	return p, true, nil
+}
+
+// project evaluates the projected expressions against the current row of
+// the IndexedVar container.
+func (c *compiledChangefeedQuery) project(evalCtx *tree.EvalContext) (*projectedRow, error) {
+	p := &projectedRow{names: c.names, datums: make(tree.Datums, len(c.exprs))}
+	for i, expr := range c.exprs {
+		d, err := expr.Eval(evalCtx)
+		if err != nil {
+			return nil, err
+		}
+		p.datums[i] = d
+	}
+	return p, nil
+}
+
+// isStarExpr returns whether the given projected expression is a star (either
+// unqualified or qualified with the table name).
+func isStarExpr(expr tree.Expr) (bool, error) {
+	vn, ok := expr.(tree.VarName)
+	if !ok {
+		return false, nil
+	}
+	v, err := vn.NormalizeVarName()
+	if err != nil {
+		return false, err
+	}
+	switch v.(type) {
+	case tree.UnqualifiedStar, *tree.AllColumnsSelector:
+		return true, nil
+	}
+	return false, nil
+}
+
+// checkNoVirtualColumnRefs returns an error if the expression references a
+// virtual computed column. The values of virtual columns are not decoded by
+// changefeeds.
+func checkNoVirtualColumnRefs(expr tree.Expr, cols []catalog.Column) error {
+	_, err := tree.SimpleVisit(expr, func(expr tree.Expr) (recurse bool, newExpr tree.Expr, err error) {
+		if ivar, ok := expr.(*tree.IndexedVar); ok && cols[ivar.Idx].IsVirtual() {
+			return false, nil, pgerror.Newf(pgcode.FeatureNotSupported,
+				"virtual column %q cannot be referenced in a changefeed query", cols[ivar.Idx].GetName())
+		}
+		return true, expr, nil
+	})
+	return err
+}
+
+// changefeedQueryIVarContainer is a tree.IndexedVarContainer which resolves
+// IndexedVars to the values of the columns of a decoded row.
+type changefeedQueryIVarContainer struct {
+	cols []catalog.Column
+	row  rowenc.EncDatumRow
+}
+
+var _ tree.IndexedVarContainer = &changefeedQueryIVarContainer{}
+
+// setRow sets the current row, decoding all its values.
+func (c *changefeedQueryIVarContainer) setRow(
+	row rowenc.EncDatumRow, alloc *tree.DatumAlloc,
+) error {
+	for i := range row {
+		if err := row[i].EnsureDecoded(c.cols[i].GetType(), alloc); err != nil {
+			return err
+		}
+	}
+	c.row = row
+	return nil
+}
+
+// IndexedVarEval implements the tree.IndexedVarContainer interface.
+func (c *changefeedQueryIVarContainer) IndexedVarEval(
+	idx int, _ *tree.EvalContext,
+) (tree.Datum, error) {
+	return c.row[idx].Datum, nil
+}
+
+// IndexedVarResolvedType implements the tree.IndexedVarContainer interface.
+func (c *changefeedQueryIVarContainer) IndexedVarResolvedType(idx int) *types.T {
+	return c.cols[idx].GetType()
+}
+
+// IndexedVarNodeFormatter implements the tree.IndexedVarContainer interface.
+func (c *changefeedQueryIVarContainer) IndexedVarNodeFormatter(idx int) tree.NodeFormatter {
+	n := tree.Name(c.cols[idx].GetName())
+	return &n
+}
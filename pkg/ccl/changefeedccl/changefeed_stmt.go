@@ -20,6 +20,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
 	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
 	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/docs"
 	"github.com/cockroachdb/cockroach/pkg/featureflag"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
@@ -29,6 +30,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/protectedts"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/protectedts/ptpb"
+	"github.com/cockroachdb/cockroach/pkg/scheduledjobs/schedulebase"
+	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
@@ -45,7 +48,9 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/roleoption"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/compressutil"
 	"github.com/cockroachdb/cockroach/pkg/util/errorutil"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -74,12 +79,30 @@ func init() {
 	)
 }
 
+// annotatedChangefeedStatement is a tree.CreateChangefeed, optionally
+// annotated with the scheduling information.
+type annotatedChangefeedStatement struct {
+	*tree.CreateChangefeed
+	*jobs.CreatedByInfo
+}
+
+func getChangefeedStatement(stmt tree.Statement) *annotatedChangefeedStatement {
+	switch changefeed := stmt.(type) {
+	case *annotatedChangefeedStatement:
+		return changefeed
+	case *tree.CreateChangefeed:
+		return &annotatedChangefeedStatement{CreateChangefeed: changefeed}
+	default:
+		return nil
+	}
+}
+
 // changefeedPlanHook implements sql.PlanHookFn.
 func changefeedPlanHook(
 	ctx context.Context, stmt tree.Statement, p sql.PlanHookState,
 ) (sql.PlanHookRowFn, colinfo.ResultColumns, []sql.PlanNode, bool, error) {
-	changefeedStmt, ok := stmt.(*tree.CreateChangefeed)
-	if !ok {
+	changefeedStmt := getChangefeedStatement(stmt)
+	if changefeedStmt == nil {
 		return nil, nil, nil, false, nil
 	}
 
@@ -177,7 +200,7 @@ func changefeedPlanHook(
 			// Still serialize the experimental_ form for backwards compatibility
 		}
 
-		jobDescription, err := changefeedJobDescription(p, changefeedStmt, sinkURI, opts)
+		jobDescription, err := changefeedJobDescription(p, changefeedStmt.CreateChangefeed, sinkURI, opts)
 		if err != nil {
 			return err
 		}
@@ -262,6 +285,9 @@ func changefeedPlanHook(
 			SinkURI:       sinkURI,
 			StatementTime: statementTime,
 		}
+		if changefeedStmt.Select != nil {
+			details.Select = tree.AsString(&tree.Select{Select: changefeedStmt.Select})
+		}
 		progress := jobspb.Progress{
 			Progress: &jobspb.Progress_HighWater{},
 			Details: &jobspb.Progress_Changefeed{
@@ -317,6 +343,49 @@ func changefeedPlanHook(
 			return err
 		}
 
+		if details.Select != `` {
+			if !p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.ChangefeedQueries) {
+				return pgerror.Newf(pgcode.FeatureNotSupported,
+					"CREATE CHANGEFEED ... AS SELECT requires all nodes to be upgraded to %s",
+					clusterversion.ByKey(clusterversion.ChangefeedQueries))
+			}
+			if err := validateChangefeedQuery(
+				ctx, details, targetDescs, &p.ExtendedEvalContext().EvalContext,
+			); err != nil {
+				return err
+			}
+		}
+
+		if details.Opts[changefeedbase.OptFormat] == string(changefeedbase.OptFormatParquet) &&
+			!isCloudStorageSink(parsedSink) {
+			return errors.Errorf(`%s=%s is only supported with cloud storage sinks`,
+				changefeedbase.OptFormat, changefeedbase.OptFormatParquet)
+		}
+		if codec, ok := details.Opts[changefeedbase.OptCompression]; ok &&
+			!p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.SnappyAndZstdCompression) {
+			switch c, _ := compressutil.CodecFromName(codec); c {
+			case compressutil.Snappy, compressutil.Zstd:
+				return pgerror.Newf(pgcode.FeatureNotSupported,
+					"%s=%s requires all nodes to be upgraded to %s", changefeedbase.OptCompression, codec,
+					clusterversion.ByKey(clusterversion.SnappyAndZstdCompression))
+			}
+		}
+		switch format := changefeedbase.FormatType(details.Opts[changefeedbase.OptFormat]); format {
+		case changefeedbase.OptFormatCSV, changefeedbase.OptFormatParquet:
+			if !p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.ChangefeedFlatFormats) {
+				return pgerror.Newf(pgcode.FeatureNotSupported,
+					"%s=%s requires all nodes to be upgraded to %s", changefeedbase.OptFormat, format,
+					clusterversion.ByKey(clusterversion.ChangefeedFlatFormats))
+			}
+			for _, desc := range targetDescs {
+				if table, isTable := desc.(catalog.TableDescriptor); isTable {
+					if err := validateFlatFormatColumns(table, format); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
 		if isCloudStorageSink(parsedSink) || isWebhookSink(parsedSink) {
 			details.Opts[changefeedbase.OptKeyInValue] = ``
 		}
@@ -434,8 +503,27 @@ func changefeedPlanHook(
 					}
 					return sqlDescIDs
 				}(),
-				Details:  details,
-				Progress: *progress.GetChangefeed(),
+				Details:   details,
+				Progress:  *progress.GetChangefeed(),
+				CreatedBy: changefeedStmt.CreatedByInfo,
+			}
+
+			if changefeedStmt.CreatedByInfo != nil {
+				// Changefeeds started by a schedule are created in the planner's
+				// transaction, which is the transaction of the job scheduler, and
+				// are adopted by the registry once it commits.
+				plannerTxn := p.ExtendedEvalContext().Txn
+				if _, err := p.ExecCfg().JobRegistry.CreateAdoptableJobWithTxn(
+					ctx, jr, jobID, plannerTxn); err != nil {
+					return err
+				}
+				if ptr != nil {
+					if err := p.ExecCfg().ProtectedTimestampProvider.Protect(ctx, plannerTxn, ptr); err != nil {
+						return err
+					}
+				}
+				resultsCh <- tree.Datums{tree.NewDInt(tree.DInt(jobID))}
+				return nil
 			}
 
 			if err := p.ExecCfg().DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
@@ -541,6 +629,33 @@ func validateNonNegativeDuration(optName string, optValue string) error {
 	return nil
 }
 
+// validateChangefeedQuery checks that the query of a changefeed created with
+// CREATE CHANGEFEED ... AS SELECT can be evaluated against its target table
+// and is compatible with the changefeed options.
+func validateChangefeedQuery(
+	ctx context.Context,
+	details jobspb.ChangefeedDetails,
+	targetDescs []catalog.Descriptor,
+	evalCtx *tree.EvalContext,
+) error {
+	if format := details.Opts[changefeedbase.OptFormat]; format != string(changefeedbase.OptFormatJSON) {
+		return errors.Errorf(`CHANGEFEED queries are only supported with %s=%s`,
+			changefeedbase.OptFormat, changefeedbase.OptFormatJSON)
+	}
+	query, err := newChangefeedQuery(details.Select, evalCtx)
+	if err != nil {
+		return err
+	}
+	for _, desc := range targetDescs {
+		if table, isTable := desc.(catalog.TableDescriptor); isTable {
+			if _, err := query.compile(ctx, table); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func validateDetails(details jobspb.ChangefeedDetails) (jobspb.ChangefeedDetails, error) {
 	if details.Opts == nil {
 		// The proto MarshalTo method omits the Opts field if the map is empty.
@@ -598,6 +713,11 @@ func validateDetails(details jobspb.ChangefeedDetails) (jobspb.ChangefeedDetails
 				`cannot specify both %s and %s`, changefeedbase.OptInitialScan,
 				changefeedbase.OptNoInitialScan)
 		}
+		if _, initialScanOnly := details.Opts[changefeedbase.OptInitialScanOnly]; initialScanOnly && noInitialScan {
+			return jobspb.ChangefeedDetails{}, errors.Errorf(
+				`cannot specify both %s and %s`, changefeedbase.OptInitialScanOnly,
+				changefeedbase.OptNoInitialScan)
+		}
 	}
 	{
 		const opt = changefeedbase.OptEnvelope
@@ -606,7 +726,16 @@ func validateDetails(details jobspb.ChangefeedDetails) (jobspb.ChangefeedDetails
 			details.Opts[opt] = string(changefeedbase.OptEnvelopeRow)
 		case changefeedbase.OptEnvelopeKeyOnly:
 			details.Opts[opt] = string(changefeedbase.OptEnvelopeKeyOnly)
-		case ``, changefeedbase.OptEnvelopeWrapped:
+		case ``:
+			// The csv and parquet formats write the columns of a row without an
+			// envelope.
+			switch changefeedbase.FormatType(details.Opts[changefeedbase.OptFormat]) {
+			case changefeedbase.OptFormatCSV, changefeedbase.OptFormatParquet:
+				details.Opts[opt] = string(changefeedbase.OptEnvelopeRow)
+			default:
+				details.Opts[opt] = string(changefeedbase.OptEnvelopeWrapped)
+			}
+		case changefeedbase.OptEnvelopeWrapped:
 			details.Opts[opt] = string(changefeedbase.OptEnvelopeWrapped)
 		default:
 			return jobspb.ChangefeedDetails{}, errors.Errorf(
@@ -618,7 +747,8 @@ func validateDetails(details jobspb.ChangefeedDetails) (jobspb.ChangefeedDetails
 		switch v := changefeedbase.FormatType(details.Opts[opt]); v {
 		case ``, changefeedbase.OptFormatJSON:
 			details.Opts[opt] = string(changefeedbase.OptFormatJSON)
-		case changefeedbase.OptFormatAvro, changefeedbase.DeprecatedOptFormatAvro:
+		case changefeedbase.OptFormatAvro, changefeedbase.DeprecatedOptFormatAvro,
+			changefeedbase.OptFormatCSV, changefeedbase.OptFormatParquet:
 			// No-op.
 		default:
 			return jobspb.ChangefeedDetails{}, errors.Errorf(
@@ -689,7 +819,7 @@ func (b *changefeedResumer) Resume(ctx context.Context, execCtx interface{}) err
 	if err != nil {
 		return b.handleChangefeedError(ctx, err, details, jobExec)
 	}
-	return nil
+	return b.maybeNotifyScheduledJobCompletion(ctx, jobs.StatusSucceeded, execCfg)
 }
 
 func (b *changefeedResumer) handleChangefeedError(
@@ -826,9 +956,53 @@ func (b *changefeedResumer) OnFailOrCancel(ctx context.Context, jobExec interfac
 		telemetry.Count(`changefeed.enterprise.fail`)
 		exec.ExecCfg().JobRegistry.MetricsStruct().Changefeed.(*Metrics).Failures.Inc(1)
 	}
+
+	// This should never return an error unless resolving the schedule that the
+	// job is being run under fails. This could happen if the schedule is dropped
+	// while the job is executing.
+	if err := b.maybeNotifyScheduledJobCompletion(ctx, jobs.StatusFailed, execCfg); err != nil {
+		log.Errorf(ctx, "failed to notify job %d on completion of OnFailOrCancel: %+v",
+			b.job.ID(), err)
+	}
 	return nil
 }
 
+// maybeNotifyScheduledJobCompletion notifies the schedule which created this
+// changefeed, if any, that the changefeed has terminated.
+func (b *changefeedResumer) maybeNotifyScheduledJobCompletion(
+	ctx context.Context, jobStatus jobs.Status, exec *sql.ExecutorConfig,
+) error {
+	env := schedulebase.JobSchedulerEnv(exec)
+	return exec.DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		// We cannot rely on b.job containing created_by_id because on job
+		// resumption the registry does not populate the resumer's CreatedByInfo.
+		datums, err := exec.InternalExecutor.QueryRowEx(
+			ctx,
+			"lookup-schedule-info",
+			txn,
+			sessiondata.InternalExecutorOverride{User: security.NodeUserName()},
+			fmt.Sprintf(
+				"SELECT created_by_id FROM %s WHERE id=$1 AND created_by_type=$2",
+				env.SystemJobsTableName()),
+			b.job.ID(), jobs.CreatedByScheduledJobs)
+		if err != nil {
+			return errors.Wrap(err, "schedule info lookup")
+		}
+		if datums == nil {
+			// Not a scheduled changefeed.
+			return nil
+		}
+
+		scheduleID := int64(tree.MustBeDInt(datums[0]))
+		if err := jobs.NotifyJobTermination(
+			ctx, env, b.job.ID(), jobStatus, b.job.Details(), scheduleID, exec.InternalExecutor, txn); err != nil {
+			return errors.Wrapf(err,
+				"failed to notify schedule %d of completion of job %d", scheduleID, b.job.ID())
+		}
+		return nil
+	})
+}
+
 // Try to clean up a protected timestamp created by the changefeed.
 func (b *changefeedResumer) maybeCleanUpProtectedTimestamp(
 	ctx context.Context, db *kv.DB, pts protectedts.Storage, ptsID uuid.UUID,
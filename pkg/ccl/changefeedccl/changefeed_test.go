@@ -38,6 +38,7 @@ import (
 	_ "github.com/cockroachdb/cockroach/pkg/ccl/partitionccl"
 	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
 	_ "github.com/cockroachdb/cockroach/pkg/cloud/impl" // registers cloud storage providers
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/keys"
@@ -221,6 +222,86 @@ func TestChangefeedDiff(t *testing.T) {
 	t.Run(`pubsub`, pubsubTest(testFn))
 }
 
+func TestChangefeedQuery(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	testFn := func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory) {
+		sqlDB := sqlutils.MakeSQLRunner(db)
+		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY, b STRING, c INT)`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (0, 'shipped', 10), (1, 'pending', 20)`)
+
+		foo := feed(t, f, `CREATE CHANGEFEED WITH diff AS SELECT a, c * 2 AS d FROM foo WHERE b = 'shipped'`)
+		defer closeFeed(t, foo)
+
+		assertPayloads(t, foo, []string{
+			`foo: [0]->{"after": {"a": 0, "d": 20}, "before": null}`,
+		})
+
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (2, 'pending', 30), (3, 'shipped', 40)`)
+		assertPayloads(t, foo, []string{
+			`foo: [3]->{"after": {"a": 3, "d": 80}, "before": null}`,
+		})
+
+		// The previous value of a row which enters the filter is not emitted,
+		// since the row did not pass the filter before.
+		sqlDB.Exec(t, `UPDATE foo SET b = 'shipped' WHERE a = 1`)
+		assertPayloads(t, foo, []string{
+			`foo: [1]->{"after": {"a": 1, "d": 40}, "before": null}`,
+		})
+
+		sqlDB.Exec(t, `UPDATE foo SET c = 50 WHERE a = 1`)
+		assertPayloads(t, foo, []string{
+			`foo: [1]->{"after": {"a": 1, "d": 100}, "before": {"a": 1, "d": 40}}`,
+		})
+
+		// A row which leaves the filter is emitted as a deletion.
+		sqlDB.Exec(t, `UPDATE foo SET b = 'pending' WHERE a = 3`)
+		assertPayloads(t, foo, []string{
+			`foo: [3]->{"after": null, "before": {"a": 3, "d": 80}}`,
+		})
+
+		// Only the deletions of rows which passed the filter are emitted: the
+		// deletions of rows 2 and 3 are not.
+		sqlDB.Exec(t, `DELETE FROM foo WHERE a IN (2, 3)`)
+		sqlDB.Exec(t, `DELETE FROM foo WHERE a = 0`)
+		assertPayloads(t, foo, []string{
+			`foo: [0]->{"after": null, "before": {"a": 0, "d": 20}}`,
+		})
+
+		// The previous values of the rows are used to evaluate the filter even
+		// if they are not emitted.
+		sqlDB.Exec(t, `CREATE TABLE bar (a INT PRIMARY KEY, b STRING)`)
+		sqlDB.Exec(t, `INSERT INTO bar VALUES (0, 'shipped'), (1, 'pending')`)
+		bar := feed(t, f, `CREATE CHANGEFEED AS SELECT a FROM bar WHERE b = 'shipped'`)
+		defer closeFeed(t, bar)
+		assertPayloads(t, bar, []string{
+			`bar: [0]->{"after": {"a": 0}}`,
+		})
+		sqlDB.Exec(t, `UPDATE bar SET b = 'pending' WHERE a = 0`)
+		sqlDB.Exec(t, `DELETE FROM bar WHERE a = 1`)
+		sqlDB.Exec(t, `INSERT INTO bar VALUES (2, 'shipped')`)
+		assertPayloads(t, bar, []string{
+			`bar: [0]->{"after": null}`,
+			`bar: [2]->{"after": {"a": 2}}`,
+		})
+
+		sqlDB.ExpectErr(t, `column "nope" does not exist`,
+			`CREATE CHANGEFEED AS SELECT nope FROM foo`)
+		sqlDB.ExpectErr(t, `volatile functions are not allowed in CHANGEFEED filter`,
+			`CREATE CHANGEFEED AS SELECT a FROM foo WHERE random() < 0.5`)
+		sqlDB.ExpectErr(t, `column name "a" specified more than once in CHANGEFEED query`,
+			`CREATE CHANGEFEED AS SELECT a, c AS a FROM foo`)
+		sqlDB.ExpectErr(t, `column name "b" specified more than once in CHANGEFEED query`,
+			`CREATE CHANGEFEED AS SELECT *, a + 1 AS b FROM foo`)
+	}
+
+	t.Run(`sinkless`, sinklessTest(testFn))
+	t.Run(`enterprise`, enterpriseTest(testFn))
+	t.Run(`kafka`, kafkaTest(testFn))
+	t.Run(`webhook`, webhookTest(testFn))
+}
+
 func TestChangefeedTenants(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -1522,7 +1603,7 @@ func fetchDescVersionModificationTime(
 			if err := value.GetProto(&desc); err != nil {
 				t.Fatal(err)
 			}
-			if tableDesc, _, _, _ := descpb.FromDescriptorWithMVCCTimestamp(&desc, k.Timestamp); tableDesc != nil {
+			if tableDesc, _, _, _, _ := descpb.FromDescriptorWithMVCCTimestamp(&desc, k.Timestamp); tableDesc != nil {
 				if int(tableDesc.Version) == version {
 					return tableDesc.ModificationTime
 				}
@@ -2982,6 +3063,20 @@ func TestChangefeedErrors(t *testing.T) {
 		`EXPERIMENTAL CHANGEFEED FOR foo WITH format=nope`,
 	)
 
+	sqlDB.ExpectErr(
+		t, `format=parquet is only supported with cloud storage sinks`,
+		`EXPERIMENTAL CHANGEFEED FOR foo WITH format=parquet`,
+	)
+	sqlDB.ExpectErr(
+		t, `envelope=wrapped is not supported with format=csv`,
+		`EXPERIMENTAL CHANGEFEED FOR foo WITH format=csv, envelope=wrapped`,
+	)
+	sqlDB.Exec(t, `CREATE TABLE event_type (a INT PRIMARY KEY, __crdb__event_type STRING)`)
+	sqlDB.ExpectErr(
+		t, `table "event_type" has a column named "__crdb__event_type", which is reserved with format=csv`,
+		`EXPERIMENTAL CHANGEFEED FOR event_type WITH format=csv, envelope=row`,
+	)
+
 	sqlDB.ExpectErr(
 		t, `unknown envelope: nope`,
 		`EXPERIMENTAL CHANGEFEED FOR foo WITH envelope=nope`,
@@ -3393,6 +3488,48 @@ func TestChangefeedErrors(t *testing.T) {
 		`kafka://nope`)
 }
 
+// TestChangefeedMixedVersion checks that changefeed features which older nodes
+// cannot run are rejected until the cluster is upgraded.
+func TestChangefeedMixedVersion(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	dir, cleanupDir := testutils.TempDir(t)
+	defer cleanupDir()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{
+		ExternalIODir: dir,
+		Knobs: base.TestingKnobs{
+			Server: &server.TestingKnobs{
+				DisableAutomaticVersionUpgrade: 1,
+				BinaryVersionOverride:          clusterversion.ByKey(clusterversion.ChangefeedQueries - 1),
+			},
+		},
+	})
+	defer s.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `SET CLUSTER SETTING kv.rangefeed.enabled = true`)
+	sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY, b STRING)`)
+
+	sqlDB.ExpectErr(t, `CREATE CHANGEFEED ... AS SELECT requires all nodes to be upgraded`,
+		`CREATE CHANGEFEED INTO 'null://' AS SELECT a FROM foo WHERE b = 'shipped'`)
+
+	sqlDB.Exec(t, `SET CLUSTER SETTING version = $1`,
+		clusterversion.ByKey(clusterversion.ChangefeedQueries).String())
+	sqlDB.Exec(t, `CREATE CHANGEFEED INTO 'null://' AS SELECT a FROM foo WHERE b = 'shipped'`)
+
+	for _, format := range []string{`csv`, `parquet`} {
+		sqlDB.ExpectErr(t, fmt.Sprintf(`format=%s requires all nodes to be upgraded`, format),
+			fmt.Sprintf(`CREATE CHANGEFEED FOR foo INTO 'nodelocal://0/%[1]s' WITH format=%[1]s`, format))
+	}
+
+	sqlDB.Exec(t, `SET CLUSTER SETTING version = $1`,
+		clusterversion.ByKey(clusterversion.ChangefeedFlatFormats).String())
+	for _, format := range []string{`csv`, `parquet`} {
+		sqlDB.Exec(t, fmt.Sprintf(`CREATE CHANGEFEED FOR foo INTO 'nodelocal://0/%[1]s' WITH format=%[1]s`, format))
+	}
+}
+
 func TestChangefeedDescription(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -24,6 +24,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondatapb"
 	"github.com/cockroachdb/cockroach/pkg/util/cache"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding/csv"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/json"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
@@ -54,13 +55,21 @@ type encodeRow struct {
 	// It's valid for interpreting the row at `updated`.
 	tableDesc catalog.TableDescriptor
 	// prevDatums is the old value of a changed table row. The field is set
-	// to nil if the before value for changes was not requested (OptDiff).
+	// to nil if the before value for changes was not requested (OptDiff) and
+	// is not needed to evaluate the filter of the changefeed query.
 	prevDatums rowenc.EncDatumRow
 	// prevDeleted is true if prevDatums is missing or is a deletion.
 	prevDeleted bool
 	// prevTableDesc is a TableDescriptor for the table containing `prevDatums`.
 	// It's valid for interpreting the row at `updated.Prev()`.
 	prevTableDesc catalog.TableDescriptor
+	// projection is the result of evaluating the changefeed query, if any,
+	// against `datums`. If set, it replaces the columns of the table in the
+	// encoded value.
+	projection *projectedRow
+	// prevProjection is the result of evaluating the changefeed query, if
+	// any, against `prevDatums`.
+	prevProjection *projectedRow
 }
 
 // Encoder turns a row into a serialized changefeed key, value, or resolved
@@ -88,6 +97,10 @@ func getEncoder(opts map[string]string, targets jobspb.ChangefeedTargets) (Encod
 		return makeJSONEncoder(opts, targets)
 	case changefeedbase.OptFormatAvro, changefeedbase.DeprecatedOptFormatAvro:
 		return newConfluentAvroEncoder(opts, targets)
+	case changefeedbase.OptFormatCSV:
+		return newCSVEncoder(opts)
+	case changefeedbase.OptFormatParquet:
+		return newParquetEncoder(opts)
 	case changefeedbase.OptFormatNative:
 		return &nativeEncoder{}, nil
 	default:
@@ -199,7 +212,12 @@ func (e *jsonEncoder) EncodeValue(_ context.Context, row encodeRow) ([]byte, err
 	}
 
 	var after map[string]interface{}
-	if !row.deleted {
+	if row.projection != nil {
+		var err error
+		if after, err = encodeProjectionJSON(row.projection); err != nil {
+			return nil, err
+		}
+	} else if !row.deleted {
 		columns := row.tableDesc.PublicColumns()
 		after = make(map[string]interface{})
 		for i, col := range columns {
@@ -223,7 +241,12 @@ func (e *jsonEncoder) EncodeValue(_ context.Context, row encodeRow) ([]byte, err
 	}
 
 	var before map[string]interface{}
-	if row.prevDatums != nil && !row.prevDeleted {
+	if row.prevProjection != nil {
+		var err error
+		if before, err = encodeProjectionJSON(row.prevProjection); err != nil {
+			return nil, err
+		}
+	} else if row.prevDatums != nil && !row.prevDeleted {
 		columns := row.prevTableDesc.PublicColumns()
 		before = make(map[string]interface{})
 		for i, col := range columns {
@@ -303,6 +326,24 @@ func (e *jsonEncoder) EncodeValue(_ context.Context, row encodeRow) ([]byte, err
 	return e.buf.Bytes(), nil
 }
 
+// encodeProjectionJSON returns a JSON object mapping the name of every column
+// of the projection of a changefeed query to its value.
+func encodeProjectionJSON(p *projectedRow) (map[string]interface{}, error) {
+	entries := make(map[string]interface{}, len(p.names))
+	for i, name := range p.names {
+		var err error
+		entries[name], err = tree.AsJSON(
+			p.datums[i],
+			sessiondatapb.DataConversionConfig{},
+			time.UTC,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
 // EncodeResolvedTimestamp implements the Encoder interface.
 func (e *jsonEncoder) EncodeResolvedTimestamp(
 	_ context.Context, _ string, resolved hlc.Timestamp,
@@ -579,6 +620,181 @@ func (e *confluentAvroEncoder) register(
 	return e.schemaRegistry.RegisterSchemaForSubject(ctx, subject, schema.codec.Schema())
 }
 
+// The csv and parquet encoders write the columns of a row without an envelope.
+// Since there is no other way to tell an upsert from a deletion, they add a
+// column with the type of the event after the columns of the table.
+const (
+	flatEventTypeColumn = `__crdb__event_type`
+	flatEventTypeUpsert = `upsert`
+	flatEventTypeDelete = `delete`
+)
+
+// validateFlatFormatOptions returns an error if the options are not
+// compatible with one of the formats which write the columns of a row without
+// an envelope (csv and parquet).
+func validateFlatFormatOptions(opts map[string]string, format changefeedbase.FormatType) error {
+	if changefeedbase.EnvelopeType(opts[changefeedbase.OptEnvelope]) != changefeedbase.OptEnvelopeRow {
+		return errors.Errorf(`%s=%s is not supported with %s=%s`,
+			changefeedbase.OptEnvelope, opts[changefeedbase.OptEnvelope], changefeedbase.OptFormat, format)
+	}
+	for _, opt := range []string{
+		changefeedbase.OptUpdatedTimestamps, changefeedbase.OptMVCCTimestamps,
+		changefeedbase.OptDiff, changefeedbase.OptTopicInValue,
+	} {
+		if _, ok := opts[opt]; ok {
+			return errors.Errorf(`%s is not supported with %s=%s`, opt, changefeedbase.OptFormat, format)
+		}
+	}
+	// OptKeyInValue is ignored: the primary key columns are always part of the
+	// encoded row.
+	return nil
+}
+
+// validateFlatFormatColumns returns an error if the table has a column whose
+// name collides with the column added by the csv and parquet encoders.
+func validateFlatFormatColumns(desc catalog.TableDescriptor, format changefeedbase.FormatType) error {
+	for _, col := range desc.PublicColumns() {
+		if col.GetName() == flatEventTypeColumn {
+			return errors.Errorf(`table %q has a column named %q, which is reserved with %s=%s`,
+				desc.GetName(), flatEventTypeColumn, changefeedbase.OptFormat, format)
+		}
+	}
+	return nil
+}
+
+// flatColumnOrdinals returns the ordinals, in the public columns of the table,
+// of the columns written by the csv and parquet encoders.
+func flatColumnOrdinals(desc catalog.TableDescriptor, virtualColumnVisibility string) []int {
+	columns := desc.PublicColumns()
+	ordinals := make([]int, 0, len(columns))
+	for i, col := range columns {
+		if col.IsVirtual() && virtualColumnVisibility == string(changefeedbase.OptVirtualColumnsOmitted) {
+			continue
+		}
+		ordinals = append(ordinals, i)
+	}
+	return ordinals
+}
+
+// encodeFlatResolvedTimestamp encodes a resolved timestamp payload for the
+// csv and parquet encoders. Resolved timestamps are not rows, so they are
+// encoded in JSON as with the default envelope.
+func encodeFlatResolvedTimestamp(resolved hlc.Timestamp) ([]byte, error) {
+	return gojson.Marshal(map[string]interface{}{
+		`resolved`: tree.TimestampToDecimalDatum(resolved).Decimal.String(),
+	})
+}
+
+// csvEncoder encodes changefeed entries as CSV records. Keys are the primary
+// key columns. Values are every column followed by the type of the event.
+// NULLs are encoded as empty fields.
+type csvEncoder struct {
+	virtualColumnVisibility string
+	// validated is the last table version whose columns were checked by
+	// validateFlatFormatColumns.
+	validated idVersion
+
+	alloc     tree.DatumAlloc
+	fmtCtx    *tree.FmtCtx
+	buf       bytes.Buffer
+	csvWriter *csv.Writer
+	record    []string
+}
+
+var _ Encoder = &csvEncoder{}
+
+func newCSVEncoder(opts map[string]string) (*csvEncoder, error) {
+	if err := validateFlatFormatOptions(opts, changefeedbase.OptFormatCSV); err != nil {
+		return nil, err
+	}
+	e := &csvEncoder{
+		virtualColumnVisibility: opts[changefeedbase.OptVirtualColumns],
+		fmtCtx:                  tree.NewFmtCtx(tree.FmtExport),
+	}
+	e.csvWriter = csv.NewWriter(&e.buf)
+	return e, nil
+}
+
+// EncodeKey implements the Encoder interface.
+func (e *csvEncoder) EncodeKey(_ context.Context, row encodeRow) ([]byte, error) {
+	colIdxByID := catalog.ColumnIDToOrdinalMap(row.tableDesc.PublicColumns())
+	primaryIndex := row.tableDesc.GetPrimaryIndex()
+	e.record = e.record[:0]
+	for i := 0; i < primaryIndex.NumKeyColumns(); i++ {
+		colID := primaryIndex.GetKeyColumnID(i)
+		idx, ok := colIdxByID.Get(colID)
+		if !ok {
+			return nil, errors.Errorf(`unknown column id: %d`, colID)
+		}
+		field, err := e.formatDatum(row, idx)
+		if err != nil {
+			return nil, err
+		}
+		e.record = append(e.record, field)
+	}
+	return e.writeRecord()
+}
+
+// EncodeValue implements the Encoder interface.
+func (e *csvEncoder) EncodeValue(_ context.Context, row encodeRow) ([]byte, error) {
+	// The table may have been altered since the changefeed was created.
+	if idVer := (idVersion{id: row.tableDesc.GetID(), version: row.tableDesc.GetVersion()}); idVer != e.validated {
+		if err := validateFlatFormatColumns(row.tableDesc, changefeedbase.OptFormatCSV); err != nil {
+			return nil, err
+		}
+		e.validated = idVer
+	}
+	e.record = e.record[:0]
+	for _, idx := range flatColumnOrdinals(row.tableDesc, e.virtualColumnVisibility) {
+		field, err := e.formatDatum(row, idx)
+		if err != nil {
+			return nil, err
+		}
+		e.record = append(e.record, field)
+	}
+	if row.deleted {
+		e.record = append(e.record, flatEventTypeDelete)
+	} else {
+		e.record = append(e.record, flatEventTypeUpsert)
+	}
+	return e.writeRecord()
+}
+
+// formatDatum formats the datum with the given ordinal as a CSV field.
+func (e *csvEncoder) formatDatum(row encodeRow, idx int) (string, error) {
+	datum, col := row.datums[idx], row.tableDesc.PublicColumns()[idx]
+	if err := datum.EnsureDecoded(col.GetType(), &e.alloc); err != nil {
+		return "", err
+	}
+	if datum.Datum == tree.DNull {
+		return "", nil
+	}
+	e.fmtCtx.Reset()
+	datum.Datum.Format(e.fmtCtx)
+	return e.fmtCtx.String(), nil
+}
+
+// writeRecord encodes the current record. The trailing newline is omitted,
+// since sinks add their own delimiter between rows.
+func (e *csvEncoder) writeRecord() ([]byte, error) {
+	e.buf.Reset()
+	if err := e.csvWriter.Write(e.record); err != nil {
+		return nil, err
+	}
+	e.csvWriter.Flush()
+	if err := e.csvWriter.Error(); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(e.buf.Bytes(), []byte{'\n'}), nil
+}
+
+// EncodeResolvedTimestamp implements the Encoder interface.
+func (e *csvEncoder) EncodeResolvedTimestamp(
+	_ context.Context, _ string, resolved hlc.Timestamp,
+) ([]byte, error) {
+	return encodeFlatResolvedTimestamp(resolved)
+}
+
 // nativeEncoder only implements EncodeResolvedTimestamp.
 // Unfortunately, the encoder assumes that it operates with encodeRow -- something
 // that's just not the case when emitting raw KVs.
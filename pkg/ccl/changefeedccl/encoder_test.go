@@ -44,7 +44,9 @@ func TestEncoders(t *testing.T) {
 	ts := hlc.Timestamp{WallTime: 1, Logical: 2}
 
 	var opts []map[string]string
-	for _, f := range []string{string(changefeedbase.OptFormatJSON), string(changefeedbase.OptFormatAvro)} {
+	for _, f := range []string{
+		string(changefeedbase.OptFormatJSON), string(changefeedbase.OptFormatAvro), string(changefeedbase.OptFormatCSV),
+	} {
 		for _, e := range []string{
 			string(changefeedbase.OptEnvelopeKeyOnly), string(changefeedbase.OptEnvelopeRow), string(changefeedbase.OptEnvelopeWrapped),
 		} {
@@ -175,6 +177,44 @@ func TestEncoders(t *testing.T) {
 				`"updated":{"string":"1.0000000002"}}`,
 			resolved: `{"resolved":{"string":"1.0000000002"}}`,
 		},
+		`format=csv,envelope=key_only`: {
+			err: `envelope=key_only is not supported with format=csv`,
+		},
+		`format=csv,envelope=key_only,updated`: {
+			err: `envelope=key_only is not supported with format=csv`,
+		},
+		`format=csv,envelope=key_only,diff`: {
+			err: `envelope=key_only is not supported with format=csv`,
+		},
+		`format=csv,envelope=key_only,updated,diff`: {
+			err: `envelope=key_only is not supported with format=csv`,
+		},
+		`format=csv,envelope=row`: {
+			insert:   `1->1,bar,upsert`,
+			delete:   `1->1,bar,delete`,
+			resolved: `{"resolved":"1.0000000002"}`,
+		},
+		`format=csv,envelope=row,updated`: {
+			err: `updated is not supported with format=csv`,
+		},
+		`format=csv,envelope=row,diff`: {
+			err: `diff is not supported with format=csv`,
+		},
+		`format=csv,envelope=row,updated,diff`: {
+			err: `updated is not supported with format=csv`,
+		},
+		`format=csv,envelope=wrapped`: {
+			err: `envelope=wrapped is not supported with format=csv`,
+		},
+		`format=csv,envelope=wrapped,updated`: {
+			err: `envelope=wrapped is not supported with format=csv`,
+		},
+		`format=csv,envelope=wrapped,diff`: {
+			err: `envelope=wrapped is not supported with format=csv`,
+		},
+		`format=csv,envelope=wrapped,updated,diff`: {
+			err: `envelope=wrapped is not supported with format=csv`,
+		},
 	}
 
 	for _, o := range opts {
@@ -191,7 +231,7 @@ func TestEncoders(t *testing.T) {
 			var rowStringFn func([]byte, []byte) string
 			var resolvedStringFn func([]byte) string
 			switch o[changefeedbase.OptFormat] {
-			case string(changefeedbase.OptFormatJSON):
+			case string(changefeedbase.OptFormatJSON), string(changefeedbase.OptFormatCSV):
 				rowStringFn = func(k, v []byte) string { return fmt.Sprintf(`%s->%s`, k, v) }
 				resolvedStringFn = func(r []byte) string { return string(r) }
 			case string(changefeedbase.OptFormatAvro), string(changefeedbase.DeprecatedOptFormatAvro):
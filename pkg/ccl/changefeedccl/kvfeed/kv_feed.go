@@ -59,6 +59,10 @@ type Config struct {
 	// been seen.
 	NeedsInitialScan bool
 
+	// If true, the feed will exit after the initial scan completes, resolving
+	// all of the spans at InitialHighWater with an EXIT boundary.
+	InitialScanOnly bool
+
 	// InitialHighWater is the timestamp after which new events are guaranteed to
 	// be produced.
 	InitialHighWater hlc.Timestamp
@@ -100,6 +104,7 @@ func Run(ctx context.Context, cfg Config) error {
 		cfg.SchemaFeed,
 		sc, pff, bf, cfg.Knobs)
 	f.onBackfillCallback = cfg.OnBackfillCallback
+	f.initialScanOnly = cfg.InitialScanOnly
 
 	g := ctxgroup.WithContext(ctx)
 	g.GoCtx(cfg.SchemaFeed.Run)
@@ -113,15 +118,20 @@ func Run(ctx context.Context, cfg Config) error {
 	// changefeedAggregator to exit even if all values haven't been read out of the
 	// provided buffer.
 	var scErr schemaChangeDetectedError
-	if !errors.As(err, &scErr) {
+	isInitialScanCompleted := errors.Is(err, errInitialScanCompleted)
+	if !isInitialScanCompleted && !errors.As(err, &scErr) {
 		// Regardless of whether we exited KV feed with or without an error, that error
 		// is not a schema change; so, close the writer and return.
 		return errors.CombineErrors(err, f.writer.CloseWithReason(ctx, err))
 	}
 
-	log.Infof(ctx, "stopping kv feed due to schema change at %v", scErr.ts)
+	if isInitialScanCompleted {
+		log.Infof(ctx, "stopping kv feed after initial scan at %v", cfg.InitialHighWater)
+	} else {
+		log.Infof(ctx, "stopping kv feed due to schema change at %v", scErr.ts)
+	}
 
-	// Drain the writer before we close it so that all events emitted prior to schema change
+	// Drain the writer before we close it so that all events emitted prior to the
 	// boundary are consumed by the change aggregator.
 	// Regardless of whether drain succeeds, we must also close the buffer to release
 	// any resources, and to let the consumer (changeAggregator) know that no more writes
@@ -148,11 +158,16 @@ func (e schemaChangeDetectedError) Error() string {
 	return fmt.Sprintf("schema change detected at %v", e.ts)
 }
 
+// errInitialScanCompleted is a sentinel error to indicate to Run() that the
+// feed is stopping because it was only asked to perform an initial scan.
+var errInitialScanCompleted = errors.New("initial scan completed")
+
 type kvFeed struct {
 	spans               []roachpb.Span
 	checkpoint          []roachpb.Span
 	withDiff            bool
 	withInitialBackfill bool
+	initialScanOnly     bool
 	initialHighWater    hlc.Timestamp
 	writer              kvevent.Writer
 	codec               keys.SQLCodec
@@ -213,6 +228,20 @@ func (f *kvFeed) run(ctx context.Context) (err error) {
 			return err
 		}
 
+		if initialScan && f.initialScanOnly {
+			// Resolve all of the spans at the scan time as an EXIT boundary so
+			// that the changefeed completes once the scan has been emitted.
+			for _, sp := range f.spans {
+				if err := f.writer.Add(
+					ctx,
+					kvevent.MakeResolvedEvent(sp, highWater, jobspb.ResolvedSpan_EXIT),
+				); err != nil {
+					return err
+				}
+			}
+			return errInitialScanCompleted
+		}
+
 		highWater, err = f.runUntilTableEvent(ctx, highWater)
 		if err != nil {
 			return err
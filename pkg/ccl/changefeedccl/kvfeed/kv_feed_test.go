@@ -81,6 +81,7 @@ func TestKVFeed(t *testing.T) {
 	type testCase struct {
 		name               string
 		needsInitialScan   bool
+		initialScanOnly    bool
 		withDiff           bool
 		schemaChangeEvents changefeedbase.SchemaChangeEventClass
 		schemaChangePolicy changefeedbase.SchemaChangePolicy
@@ -124,6 +125,7 @@ func TestKVFeed(t *testing.T) {
 			tc.initialHighWater,
 			keys.SystemSQLCodec,
 			tf, sf, rangefeedFactory(ref.run), bufferFactory, TestingKnobs{})
+		f.initialScanOnly = tc.initialScanOnly
 		ctx, cancel := context.WithCancel(context.Background())
 		g := ctxgroup.WithContext(ctx)
 		g.GoCtx(func(ctx context.Context) error {
@@ -148,7 +150,7 @@ func TestKVFeed(t *testing.T) {
 			return nil
 		})
 		// Wait for the feed to fail rather than canceling it.
-		if tc.schemaChangePolicy == changefeedbase.OptSchemaChangePolicyStop {
+		if tc.schemaChangePolicy == changefeedbase.OptSchemaChangePolicyStop || tc.initialScanOnly {
 			testG.Go(func() error {
 				_ = g.Wait()
 				return nil
@@ -189,6 +191,25 @@ func TestKVFeed(t *testing.T) {
 			},
 			expEvents: 1,
 		},
+		{
+			name:               "no events - initial scan only",
+			schemaChangeEvents: changefeedbase.OptSchemaChangeEventClassDefault,
+			schemaChangePolicy: changefeedbase.OptSchemaChangePolicyBackfill,
+			needsInitialScan:   true,
+			initialScanOnly:    true,
+			initialHighWater:   ts(2),
+			spans: []roachpb.Span{
+				tableSpan(42),
+			},
+			events: []roachpb.RangeFeedEvent{
+				kvEvent(42, "a", "b", ts(3)),
+			},
+			expScans: []hlc.Timestamp{
+				ts(2),
+			},
+			expEvents: 1,
+			expErrRE:  "initial scan completed",
+		},
 		{
 			name:               "no events -  full checkpoint",
 			schemaChangeEvents: changefeedbase.OptSchemaChangeEventClassDefault,
@@ -0,0 +1,172 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/ccl/importccl"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/errors"
+)
+
+// parquetEncoder encodes changefeed entries for the cloud storage sink, which
+// writes them to parquet files. Parquet is a columnar format, so a row cannot
+// be encoded on its own: the encoded value of a row is an intermediate
+// encoding of its datums, which the sink decodes and adds to the parquet file
+// of the table version the row belongs to (see parquetFileWriter).
+//
+// The encoded value is the type of the event (a byte which is 1 for deletions
+// and 0 otherwise) followed by the value encoding of the datums of the columns
+// returned by flatColumnOrdinals.
+type parquetEncoder struct {
+	virtualColumnVisibility string
+
+	alloc tree.DatumAlloc
+	buf   []byte
+}
+
+var _ Encoder = &parquetEncoder{}
+
+func newParquetEncoder(opts map[string]string) (*parquetEncoder, error) {
+	if err := validateFlatFormatOptions(opts, changefeedbase.OptFormatParquet); err != nil {
+		return nil, err
+	}
+	if codec, ok := opts[changefeedbase.OptCompression]; ok && codec != `` {
+		return nil, errors.Errorf(`%s is not supported with %s=%s, parquet files are always compressed with snappy`,
+			changefeedbase.OptCompression, changefeedbase.OptFormat, changefeedbase.OptFormatParquet)
+	}
+	return &parquetEncoder{
+		virtualColumnVisibility: opts[changefeedbase.OptVirtualColumns],
+	}, nil
+}
+
+// EncodeKey implements the Encoder interface. The cloud storage sink does not
+// write keys, and the primary key columns are part of the value anyway.
+func (e *parquetEncoder) EncodeKey(context.Context, encodeRow) ([]byte, error) {
+	return nil, nil
+}
+
+// EncodeValue implements the Encoder interface.
+func (e *parquetEncoder) EncodeValue(_ context.Context, row encodeRow) ([]byte, error) {
+	e.buf = e.buf[:0]
+	if row.deleted {
+		e.buf = append(e.buf, 1)
+	} else {
+		e.buf = append(e.buf, 0)
+	}
+	columns := row.tableDesc.PublicColumns()
+	for _, idx := range flatColumnOrdinals(row.tableDesc, e.virtualColumnVisibility) {
+		var err error
+		e.buf, err = row.datums[idx].Encode(columns[idx].GetType(), &e.alloc, descpb.DatumEncoding_VALUE, e.buf)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return e.buf, nil
+}
+
+// EncodeResolvedTimestamp implements the Encoder interface.
+func (e *parquetEncoder) EncodeResolvedTimestamp(
+	_ context.Context, _ string, resolved hlc.Timestamp,
+) ([]byte, error) {
+	return encodeFlatResolvedTimestamp(resolved)
+}
+
+// parquetFileWriter writes the rows encoded by a parquetEncoder to a parquet
+// file. All the rows must belong to the same version of a table.
+type parquetFileWriter struct {
+	typs   []*types.T
+	writer *importccl.ParquetWriter
+
+	alloc tree.DatumAlloc
+	row   tree.Datums
+}
+
+// newParquetFileWriter returns a parquetFileWriter which writes to buf the
+// rows of the given table version. Row groups are flushed once their size
+// reaches maxRowGroupSize.
+func newParquetFileWriter(
+	buf *bytes.Buffer,
+	desc catalog.TableDescriptor,
+	virtualColumnVisibility string,
+	maxRowGroupSize int64,
+) (*parquetFileWriter, error) {
+	// The table may have been altered since the changefeed was created.
+	if err := validateFlatFormatColumns(desc, changefeedbase.OptFormatParquet); err != nil {
+		return nil, err
+	}
+	columns := desc.PublicColumns()
+	ordinals := flatColumnOrdinals(desc, virtualColumnVisibility)
+	names := make([]string, 0, len(ordinals)+1)
+	typs := make([]*types.T, 0, len(ordinals)+1)
+	for _, idx := range ordinals {
+		names = append(names, columns[idx].GetName())
+		typs = append(typs, columns[idx].GetType())
+	}
+	names = append(names, flatEventTypeColumn)
+	typs = append(typs, types.String)
+
+	writer, err := importccl.NewParquetWriter(buf, names, typs, maxRowGroupSize)
+	if err != nil {
+		return nil, err
+	}
+	return &parquetFileWriter{
+		typs:   typs,
+		writer: writer,
+		row:    make(tree.Datums, len(typs)),
+	}, nil
+}
+
+// addRow decodes a row encoded by a parquetEncoder and adds it to the file.
+func (w *parquetFileWriter) addRow(value []byte) error {
+	if len(value) == 0 {
+		return errors.AssertionFailedf("empty parquet row")
+	}
+	eventType := flatEventTypeUpsert
+	if value[0] == 1 {
+		eventType = flatEventTypeDelete
+	}
+	buf := value[1:]
+	last := len(w.typs) - 1
+	for i := 0; i < last; i++ {
+		var ed rowenc.EncDatum
+		var err error
+		ed, buf, err = rowenc.EncDatumFromBuffer(w.typs[i], descpb.DatumEncoding_VALUE, buf)
+		if err != nil {
+			return err
+		}
+		if err := ed.EnsureDecoded(w.typs[i], &w.alloc); err != nil {
+			return err
+		}
+		w.row[i] = ed.Datum
+	}
+	if len(buf) != 0 {
+		return errors.AssertionFailedf("unexpected trailing bytes in parquet row")
+	}
+	w.row[last] = tree.NewDString(eventType)
+	return w.writer.AddRow(w.row)
+}
+
+// size returns an estimate of the size of the parquet file.
+func (w *parquetFileWriter) size() int64 {
+	return w.writer.Size()
+}
+
+// close writes the remaining rows and the footer of the parquet file.
+func (w *parquetFileWriter) close() error {
+	return w.writer.Close()
+}
@@ -0,0 +1,545 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/scheduledjobs"
+	"github.com/cockroachdb/cockroach/pkg/scheduledjobs/schedulebase"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+	"github.com/cockroachdb/errors"
+	pbtypes "github.com/gogo/protobuf/types"
+)
+
+const scheduleChangefeedOp = "CREATE SCHEDULE FOR CHANGEFEED"
+
+// scheduledChangefeedExecutor executes changefeed schedules. Each execution
+// starts a changefeed which performs an initial scan of its targets into a
+// cloud storage sink and then completes.
+type scheduledChangefeedExecutor struct {
+	metrics changefeedScheduleMetrics
+}
+
+type changefeedScheduleMetrics struct {
+	*jobs.ExecutorMetrics
+}
+
+var _ metric.Struct = &changefeedScheduleMetrics{}
+
+// MetricStruct implements metric.Struct interface
+func (m *changefeedScheduleMetrics) MetricStruct() {}
+
+var _ jobs.ScheduledJobExecutor = &scheduledChangefeedExecutor{}
+
+// ExecuteJob implements jobs.ScheduledJobExecutor interface.
+func (e *scheduledChangefeedExecutor) ExecuteJob(
+	ctx context.Context,
+	cfg *scheduledjobs.JobExecutionConfig,
+	env scheduledjobs.JobSchedulerEnv,
+	sj *jobs.ScheduledJob,
+	txn *kv.Txn,
+) error {
+	if err := e.executeChangefeed(ctx, cfg, sj, txn); err != nil {
+		e.metrics.NumFailed.Inc(1)
+		return err
+	}
+	e.metrics.NumStarted.Inc(1)
+	return nil
+}
+
+func (e *scheduledChangefeedExecutor) executeChangefeed(
+	ctx context.Context, cfg *scheduledjobs.JobExecutionConfig, sj *jobs.ScheduledJob, txn *kv.Txn,
+) error {
+	changefeedStmt, err := extractChangefeedStatement(sj)
+	if err != nil {
+		return err
+	}
+
+	// Sanity check: scheduled changefeeds must terminate once their initial
+	// scan completes.
+	if !hasOption(changefeedStmt.Options, changefeedbase.OptInitialScanOnly) {
+		changefeedStmt.Options = append(changefeedStmt.Options,
+			tree.KVOption{Key: changefeedbase.OptInitialScanOnly})
+		log.Warningf(ctx, "force setting %s option for changefeed schedule %d",
+			changefeedbase.OptInitialScanOnly, sj.ScheduleID())
+	}
+
+	log.Infof(ctx, "Starting scheduled changefeed %d: %s",
+		sj.ScheduleID(), tree.AsString(changefeedStmt.CreateChangefeed))
+
+	// Invoke changefeed plan hook.
+	hook, cleanup := cfg.PlanHookMaker("exec-changefeed", txn, sj.Owner())
+	defer cleanup()
+	changefeedFn, err := planChangefeed(ctx, hook.(sql.PlanHookState), changefeedStmt)
+	if err != nil {
+		return err
+	}
+	return invokeChangefeed(ctx, changefeedFn)
+}
+
+func planChangefeed(
+	ctx context.Context, p sql.PlanHookState, changefeedStmt tree.Statement,
+) (sql.PlanHookRowFn, error) {
+	fn, cols, _, _, err := changefeedPlanHook(ctx, changefeedStmt, p)
+	if err != nil {
+		return nil, errors.Wrapf(err, "changefeed eval: %q", tree.AsString(changefeedStmt))
+	}
+	if fn == nil {
+		return nil, errors.Newf("changefeed eval: %q", tree.AsString(changefeedStmt))
+	}
+	if len(cols) != len(utilccl.DetachedJobExecutionResultHeader) {
+		return nil, errors.Newf("unexpected result columns")
+	}
+	return fn, nil
+}
+
+func invokeChangefeed(ctx context.Context, changefeedFn sql.PlanHookRowFn) error {
+	resultCh := make(chan tree.Datums) // No need to close
+	g := ctxgroup.WithContext(ctx)
+
+	g.GoCtx(func(ctx context.Context) error {
+		select {
+		case <-resultCh:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+
+	g.GoCtx(func(ctx context.Context) error {
+		return changefeedFn(ctx, nil, resultCh)
+	})
+
+	return g.Wait()
+}
+
+// NotifyJobTermination implements jobs.ScheduledJobExecutor interface.
+func (e *scheduledChangefeedExecutor) NotifyJobTermination(
+	ctx context.Context,
+	jobID jobspb.JobID,
+	jobStatus jobs.Status,
+	details jobspb.Details,
+	env scheduledjobs.JobSchedulerEnv,
+	schedule *jobs.ScheduledJob,
+	ex sqlutil.InternalExecutor,
+	txn *kv.Txn,
+) error {
+	if jobStatus == jobs.StatusSucceeded {
+		e.metrics.NumSucceeded.Inc(1)
+		log.Infof(ctx, "changefeed job %d scheduled by %d succeeded", jobID, schedule.ScheduleID())
+		return nil
+	}
+
+	e.metrics.NumFailed.Inc(1)
+	err := errors.Errorf(
+		"changefeed job %d scheduled by %d failed with status %s",
+		jobID, schedule.ScheduleID(), jobStatus)
+	log.Errorf(ctx, "changefeed error: %v", err)
+	jobs.DefaultHandleFailedRun(schedule, "changefeed job %d failed with err=%v", jobID, err)
+	return nil
+}
+
+// Metrics implements ScheduledJobExecutor interface
+func (e *scheduledChangefeedExecutor) Metrics() metric.Struct {
+	return &e.metrics
+}
+
+// GetCreateScheduleStatement implements jobs.ScheduledJobExecutor interface.
+func (e *scheduledChangefeedExecutor) GetCreateScheduleStatement(
+	ctx context.Context,
+	env scheduledjobs.JobSchedulerEnv,
+	txn *kv.Txn,
+	sj *jobs.ScheduledJob,
+	ex sqlutil.InternalExecutor,
+) (string, error) {
+	changefeedStmt, err := extractChangefeedStatement(sj)
+	if err != nil {
+		return "", err
+	}
+
+	firstRunTime := sj.ScheduledRunTime()
+	if firstRunTime.IsZero() {
+		firstRunTime = env.Now()
+	}
+	firstRun, err := tree.MakeDTimestampTZ(firstRunTime, time.Microsecond)
+	if err != nil {
+		return "", err
+	}
+
+	scheduleOptions, err := schedulebase.MakeScheduleOptions(firstRun, sj.ScheduleDetails())
+	if err != nil {
+		return "", err
+	}
+
+	redactedChangefeed, err := redactScheduledChangefeed(changefeedStmt.CreateChangefeed)
+	if err != nil {
+		return "", err
+	}
+
+	node := &tree.ScheduledChangefeed{
+		ScheduleLabelSpec: tree.ScheduleLabelSpec{
+			IfNotExists: false, Label: tree.NewDString(sj.ScheduleLabel())},
+		Recurrence:      tree.NewDString(sj.ScheduleExpr()),
+		Changefeed:      redactedChangefeed,
+		ScheduleOptions: scheduleOptions,
+	}
+
+	return tree.AsString(node), nil
+}
+
+// redactScheduledChangefeed returns a copy of the changefeed statement stored
+// in a schedule, suitable for displaying to the user: the sink URI is
+// sanitized and the implicit initial_scan_only option is removed.
+func redactScheduledChangefeed(
+	changefeed *tree.CreateChangefeed,
+) (*tree.CreateChangefeed, error) {
+	sink, ok := changefeed.SinkURI.(*tree.StrVal)
+	if !ok {
+		return nil, errors.Errorf("unexpected %T sink in changefeed statement", changefeed.SinkURI)
+	}
+	sanitizedSink, err := cloud.SanitizeExternalStorageURI(sink.RawString(), nil /* extraParams */)
+	if err != nil {
+		return nil, err
+	}
+
+	redacted := *changefeed
+	redacted.SinkURI = tree.NewStrVal(sanitizedSink)
+	redacted.Options = nil
+	for _, opt := range changefeed.Options {
+		if string(opt.Key) != changefeedbase.OptInitialScanOnly {
+			redacted.Options = append(redacted.Options, opt)
+		}
+	}
+	return &redacted, nil
+}
+
+// extractChangefeedStatement returns the changefeed statement encoded inside
+// the scheduled job.
+func extractChangefeedStatement(sj *jobs.ScheduledJob) (*annotatedChangefeedStatement, error) {
+	args := &jobspb.ScheduledChangefeedExecutionArgs{}
+	if err := pbtypes.UnmarshalAny(sj.ExecutionArgs().Args, args); err != nil {
+		return nil, errors.Wrap(err, "un-marshaling args")
+	}
+
+	node, err := parser.ParseOne(args.ChangefeedStatement)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing changefeed statement")
+	}
+
+	if changefeedStmt, ok := node.AST.(*tree.CreateChangefeed); ok {
+		return &annotatedChangefeedStatement{
+			CreateChangefeed: changefeedStmt,
+			CreatedByInfo: &jobs.CreatedByInfo{
+				Name: jobs.CreatedByScheduledJobs,
+				ID:   sj.ScheduleID(),
+			},
+		}, nil
+	}
+
+	return nil, errors.Newf("unexpect node type %T", node)
+}
+
+func hasOption(opts tree.KVOptions, key string) bool {
+	for _, opt := range opts {
+		if string(opt.Key) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// scheduledChangefeedEval is a helper struct containing the evaluated
+// components of a CREATE SCHEDULE FOR CHANGEFEED statement.
+type scheduledChangefeedEval struct {
+	*tree.ScheduledChangefeed
+
+	scheduleLabel  func() (string, error)
+	recurrence     func() (string, error)
+	sinkURI        func() (string, error)
+	changefeedOpts func() (map[string]string, error)
+	scheduleOpts   func() (map[string]string, error)
+}
+
+// scheduledChangefeedHeader is the header for "CREATE SCHEDULE FOR
+// CHANGEFEED..." statements results.
+var scheduledChangefeedHeader = colinfo.ResultColumns{
+	{Name: "schedule_id", Typ: types.Int},
+	{Name: "label", Typ: types.String},
+	{Name: "status", Typ: types.String},
+	{Name: "first_run", Typ: types.TimestampTZ},
+	{Name: "schedule", Typ: types.String},
+	{Name: "changefeed_stmt", Typ: types.String},
+}
+
+func makeScheduledChangefeedEval(
+	ctx context.Context, p sql.PlanHookState, schedule *tree.ScheduledChangefeed,
+) (*scheduledChangefeedEval, error) {
+	eval := &scheduledChangefeedEval{ScheduledChangefeed: schedule}
+	var err error
+
+	if schedule.ScheduleLabelSpec.Label != nil {
+		eval.scheduleLabel, err = p.TypeAsString(ctx, schedule.ScheduleLabelSpec.Label, scheduleChangefeedOp)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if schedule.Recurrence == nil {
+		// Sanity check: recurrence must be specified.
+		return nil, errors.New("RECURRING clause required")
+	}
+	eval.recurrence, err = p.TypeAsString(ctx, schedule.Recurrence, scheduleChangefeedOp)
+	if err != nil {
+		return nil, err
+	}
+
+	eval.sinkURI, err = p.TypeAsString(ctx, schedule.Changefeed.SinkURI, scheduleChangefeedOp)
+	if err != nil {
+		return nil, err
+	}
+
+	eval.changefeedOpts, err = p.TypeAsStringOpts(
+		ctx, schedule.Changefeed.Options, changefeedbase.ChangefeedOptionExpectValues)
+	if err != nil {
+		return nil, err
+	}
+
+	eval.scheduleOpts, err = p.TypeAsStringOpts(
+		ctx, schedule.ScheduleOptions, schedulebase.CommonScheduleOptionExpectValues)
+	if err != nil {
+		return nil, err
+	}
+	return eval, nil
+}
+
+// doCreateChangefeedSchedule is a plan hook implementation responsible for
+// creating a changefeed schedule.
+func doCreateChangefeedSchedule(
+	ctx context.Context,
+	p sql.PlanHookState,
+	eval *scheduledChangefeedEval,
+	resultsCh chan<- tree.Datums,
+) error {
+	if err := p.RequireAdminRole(ctx, scheduleChangefeedOp); err != nil {
+		return err
+	}
+
+	if err := utilccl.CheckEnterpriseEnabled(
+		p.ExecCfg().Settings, p.ExecCfg().ClusterID(), p.ExecCfg().Organization(),
+		scheduleChangefeedOp,
+	); err != nil {
+		return err
+	}
+
+	if eval.ScheduleLabelSpec.IfNotExists && eval.scheduleLabel != nil {
+		scheduleLabel, err := eval.scheduleLabel()
+		if err != nil {
+			return err
+		}
+
+		exists, err := schedulebase.CheckScheduleAlreadyExists(ctx, p, scheduleLabel)
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			p.BufferClientNotice(ctx,
+				pgnotice.Newf("schedule %q already exists, skipping", scheduleLabel),
+			)
+			return nil
+		}
+	}
+
+	env := schedulebase.JobSchedulerEnv(p.ExecCfg())
+
+	recurrence, err := schedulebase.ComputeScheduleRecurrence(env.Now(), eval.recurrence)
+	if err != nil {
+		return err
+	}
+
+	sinkURI, err := eval.sinkURI()
+	if err != nil {
+		return err
+	}
+	parsedSink, err := url.Parse(sinkURI)
+	if err != nil {
+		return err
+	}
+	if !isCloudStorageSink(parsedSink) {
+		return errors.Errorf("scheduled changefeeds are only supported with cloud storage sinks")
+	}
+
+	changefeedOpts, err := eval.changefeedOpts()
+	if err != nil {
+		return err
+	}
+	for _, opt := range []string{
+		changefeedbase.OptCursor, changefeedbase.OptInitialScan, changefeedbase.OptNoInitialScan,
+	} {
+		if _, ok := changefeedOpts[opt]; ok {
+			return errors.Errorf("%s option is not supported by scheduled changefeeds", opt)
+		}
+	}
+	changefeedOpts[changefeedbase.OptInitialScanOnly] = ""
+
+	// Changefeed targets must be fully qualified when the schedule is created:
+	// the changefeed is started by the job scheduler, whose session does not
+	// have the same name resolution configuration as the current session.
+	targets := eval.Changefeed.Targets
+	targets.Tables, err = schedulebase.FullyQualifyTables(ctx, p, targets.Tables)
+	if err != nil {
+		return errors.Wrap(err, "qualifying changefeed target tables")
+	}
+
+	changefeedNode := &tree.CreateChangefeed{
+		Targets: targets,
+		SinkURI: tree.NewStrVal(sinkURI),
+	}
+	optKeys := make([]string, 0, len(changefeedOpts))
+	for k := range changefeedOpts {
+		optKeys = append(optKeys, k)
+	}
+	sort.Strings(optKeys)
+	for _, k := range optKeys {
+		opt := tree.KVOption{Key: tree.Name(k)}
+		if v := changefeedOpts[k]; v != "" {
+			opt.Value = tree.NewDString(v)
+		}
+		changefeedNode.Options = append(changefeedNode.Options, opt)
+	}
+
+	var scheduleLabel string
+	if eval.scheduleLabel != nil {
+		label, err := eval.scheduleLabel()
+		if err != nil {
+			return err
+		}
+		scheduleLabel = label
+	} else {
+		scheduleLabel = fmt.Sprintf("CHANGEFEED %d", env.Now().Unix())
+	}
+
+	scheduleOptions, err := eval.scheduleOpts()
+	if err != nil {
+		return err
+	}
+
+	evalCtx := &p.ExtendedEvalContext().EvalContext
+	firstRun, err := schedulebase.ScheduleFirstRun(evalCtx, scheduleOptions)
+	if err != nil {
+		return err
+	}
+
+	details, err := schedulebase.MakeScheduleDetails(scheduleOptions)
+	if err != nil {
+		return err
+	}
+
+	sj := jobs.NewScheduledJob(env)
+	sj.SetScheduleLabel(scheduleLabel)
+	sj.SetOwner(p.User())
+	if err := sj.SetSchedule(recurrence.Cron); err != nil {
+		return err
+	}
+	sj.SetScheduleDetails(details)
+	if firstRun != nil {
+		sj.SetNextRun(*firstRun)
+	}
+
+	args := &jobspb.ScheduledChangefeedExecutionArgs{
+		ChangefeedStatement: tree.AsStringWithFlags(
+			changefeedNode, tree.FmtParsable|tree.FmtShowPasswords),
+	}
+	any, err := pbtypes.MarshalAny(args)
+	if err != nil {
+		return err
+	}
+	sj.SetExecutionDetails(
+		tree.ScheduledChangefeedExecutor.InternalName(), jobspb.ExecutionArguments{Args: any},
+	)
+
+	if err := sj.Create(ctx, p.ExecCfg().InternalExecutor, p.ExtendedEvalContext().Txn); err != nil {
+		return err
+	}
+
+	redactedChangefeed, err := redactScheduledChangefeed(changefeedNode)
+	if err != nil {
+		return err
+	}
+	nextRun, err := tree.MakeDTimestampTZ(sj.NextRun(), time.Microsecond)
+	if err != nil {
+		return err
+	}
+	resultsCh <- tree.Datums{
+		tree.NewDInt(tree.DInt(sj.ScheduleID())),
+		tree.NewDString(sj.ScheduleLabel()),
+		tree.NewDString("ACTIVE"),
+		nextRun,
+		tree.NewDString(sj.ScheduleExpr()),
+		tree.NewDString(tree.AsString(redactedChangefeed)),
+	}
+	telemetry.Count("scheduled-changefeed.create.success")
+	return nil
+}
+
+func createChangefeedScheduleHook(
+	ctx context.Context, stmt tree.Statement, p sql.PlanHookState,
+) (sql.PlanHookRowFn, colinfo.ResultColumns, []sql.PlanNode, bool, error) {
+	schedule, ok := stmt.(*tree.ScheduledChangefeed)
+	if !ok {
+		return nil, nil, nil, false, nil
+	}
+
+	eval, err := makeScheduledChangefeedEval(ctx, p, schedule)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
+		if err := doCreateChangefeedSchedule(ctx, p, eval, resultsCh); err != nil {
+			telemetry.Count("scheduled-changefeed.create.failed")
+			return err
+		}
+		return nil
+	}
+	return fn, scheduledChangefeedHeader, nil, false, nil
+}
+
+func init() {
+	sql.AddPlanHook(createChangefeedScheduleHook)
+	jobs.RegisterScheduledJobExecutorFactory(
+		tree.ScheduledChangefeedExecutor.InternalName(),
+		func() (jobs.ScheduledJobExecutor, error) {
+			m := jobs.MakeExecutorMetrics(tree.ScheduledChangefeedExecutor.UserName())
+			return &scheduledChangefeedExecutor{
+				metrics: changefeedScheduleMetrics{ExecutorMetrics: &m},
+			}, nil
+		})
+}
@@ -0,0 +1,288 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobstest"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/scheduledjobs"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactScheduledChangefeed(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	for _, tc := range []struct {
+		stmt     string
+		expected string
+	}{
+		{
+			stmt:     `CREATE CHANGEFEED FOR TABLE d.public.t INTO 'nodelocal://0/feed' WITH initial_scan_only`,
+			expected: `CREATE CHANGEFEED FOR TABLE d.public.t INTO 'nodelocal://0/feed'`,
+		},
+		{
+			stmt:     `CREATE CHANGEFEED FOR TABLE d.public.t INTO 's3://bucket/feed?AWS_SECRET_ACCESS_KEY=secret' WITH format = 'csv', initial_scan_only`,
+			expected: `CREATE CHANGEFEED FOR TABLE d.public.t INTO 's3://bucket/feed?AWS_SECRET_ACCESS_KEY=redacted' WITH format = 'csv'`,
+		},
+	} {
+		t.Run(tc.stmt, func(t *testing.T) {
+			stmt, err := parser.ParseOne(tc.stmt)
+			require.NoError(t, err)
+			redacted, err := redactScheduledChangefeed(stmt.AST.(*tree.CreateChangefeed))
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, tree.AsString(redacted))
+		})
+	}
+}
+
+// scheduleTestHelper starts a server whose job scheduler is driven by the
+// test through jobstest.JobSchedulerTestEnv.
+type scheduleTestHelper struct {
+	iodir            string
+	server           serverutils.TestServerInterface
+	env              *jobstest.JobSchedulerTestEnv
+	cfg              *scheduledjobs.JobExecutionConfig
+	sqlDB            *sqlutils.SQLRunner
+	executeSchedules func() error
+}
+
+func newScheduleTestHelper(t *testing.T) (*scheduleTestHelper, func()) {
+	dir, dirCleanupFn := testutils.TempDir(t)
+
+	th := &scheduleTestHelper{
+		env: jobstest.NewJobSchedulerTestEnv(
+			jobstest.UseSystemTables, timeutil.Now(), tree.ScheduledChangefeedExecutor),
+		iodir: dir,
+	}
+
+	knobs := &jobs.TestingKnobs{
+		JobSchedulerEnv: th.env,
+		TakeOverJobsScheduling: func(fn func(ctx context.Context, maxSchedules int64, txn *kv.Txn) error) {
+			th.executeSchedules = func() error {
+				defer th.server.JobRegistry().(*jobs.Registry).TestingNudgeAdoptionQueue()
+				return th.cfg.DB.Txn(context.Background(), func(ctx context.Context, txn *kv.Txn) error {
+					// maxSchedules = 0 means there's no limit.
+					return fn(ctx, 0 /* maxSchedules */, txn)
+				})
+			}
+		},
+		CaptureJobExecutionConfig: func(config *scheduledjobs.JobExecutionConfig) {
+			th.cfg = config
+		},
+	}
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{
+		ExternalIODir: dir,
+		Knobs:         base.TestingKnobs{JobsTestingKnobs: knobs},
+	})
+	require.NotNil(t, th.cfg)
+	th.sqlDB = sqlutils.MakeSQLRunner(db)
+	th.server = s
+	th.sqlDB.Exec(t, `SET CLUSTER SETTING kv.rangefeed.enabled = true`)
+
+	return th, func() {
+		dirCleanupFn()
+		s.Stopper().Stop(context.Background())
+	}
+}
+
+func (h *scheduleTestHelper) createSchedule(t *testing.T, query string) int64 {
+	t.Helper()
+	var id int64
+	var unusedStr string
+	var unusedTS *time.Time
+	h.sqlDB.QueryRow(t, query).Scan(&id, &unusedStr, &unusedStr, &unusedTS, &unusedStr, &unusedStr)
+	return id
+}
+
+func (h *scheduleTestHelper) loadSchedule(t *testing.T, id int64) *jobs.ScheduledJob {
+	t.Helper()
+	sj, err := jobs.LoadScheduledJob(context.Background(), h.env, id, h.cfg.InternalExecutor, nil)
+	require.NoError(t, err)
+	return sj
+}
+
+// fireSchedule advances the time past the next run of the schedule and runs
+// the job scheduler.
+func (h *scheduleTestHelper) fireSchedule(t *testing.T, id int64) {
+	t.Helper()
+	h.env.SetTime(h.loadSchedule(t, id).NextRun().Add(time.Second))
+	require.NoError(t, h.executeSchedules())
+}
+
+// waitForScheduledJob waits for a job created by the schedule to reach the
+// specified status, and returns its ID.
+func (h *scheduleTestHelper) waitForScheduledJob(
+	t *testing.T, id int64, status jobs.Status,
+) jobspb.JobID {
+	t.Helper()
+	var jobID jobspb.JobID
+	testutils.SucceedsSoon(t, func() error {
+		h.server.JobRegistry().(*jobs.Registry).TestingNudgeAdoptionQueue()
+		return h.sqlDB.DB.QueryRowContext(context.Background(),
+			`SELECT id FROM system.jobs WHERE status = $1 AND created_by_type = $2 AND created_by_id = $3`,
+			status, jobs.CreatedByScheduledJobs, id).Scan(&jobID)
+	})
+	return jobID
+}
+
+// addPausedScheduledJob adds a paused changefeed job, created by the schedule,
+// which is seen as a previous run of the schedule which is still running.
+func (h *scheduleTestHelper) addPausedScheduledJob(t *testing.T, id int64) {
+	t.Helper()
+	registry := h.server.JobRegistry().(*jobs.Registry)
+	require.NoError(t, h.cfg.DB.Txn(context.Background(), func(ctx context.Context, txn *kv.Txn) error {
+		jobID := registry.MakeJobID()
+		if _, err := registry.CreateAdoptableJobWithTxn(ctx, jobs.Record{
+			Description: "previous run",
+			Username:    security.RootUserName(),
+			Details:     jobspb.ChangefeedDetails{},
+			Progress:    jobspb.ChangefeedProgress{},
+			CreatedBy:   &jobs.CreatedByInfo{Name: jobs.CreatedByScheduledJobs, ID: id},
+		}, jobID, txn); err != nil {
+			return err
+		}
+		_, err := h.cfg.InternalExecutor.Exec(ctx, "pause-job", txn,
+			`UPDATE system.jobs SET status = $1 WHERE id = $2`, string(jobs.StatusPaused), jobID)
+		return err
+	}))
+}
+
+func TestScheduledChangefeed(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	th, cleanup := newScheduleTestHelper(t)
+	defer cleanup()
+
+	th.sqlDB.Exec(t, `
+CREATE DATABASE d;
+CREATE TABLE d.t (a INT PRIMARY KEY, b STRING);
+INSERT INTO d.t VALUES (1, 'one'), (2, 'two'), (3, 'three');
+`)
+
+	numScheduledJobs := func(t *testing.T, id int64) int {
+		var n int
+		th.sqlDB.QueryRow(t,
+			`SELECT count(*) FROM system.jobs WHERE created_by_type = $1 AND created_by_id = $2`,
+			jobs.CreatedByScheduledJobs, id).Scan(&n)
+		return n
+	}
+
+	t.Run("execute", func(t *testing.T) {
+		id := th.createSchedule(t,
+			`CREATE SCHEDULE FOR CHANGEFEED d.t INTO 'nodelocal://0/execute' RECURRING '@hourly'`)
+		defer th.sqlDB.Exec(t, `DROP SCHEDULE $1`, id)
+
+		th.fireSchedule(t, id)
+		jobID := th.waitForScheduledJob(t, id, jobs.StatusSucceeded)
+
+		// The changefeed ran as the owner of the schedule, and the schedule
+		// recorded its success.
+		require.Equal(t, [][]string{{"CHANGEFEED", "root"}}, th.sqlDB.QueryStr(t,
+			`SELECT job_type, user_name FROM [SHOW JOBS] WHERE job_id = $1`, jobID))
+		sj := th.loadSchedule(t, id)
+		require.False(t, sj.IsPaused())
+		require.True(t, sj.NextRun().After(th.env.Now()))
+
+		// The initial scan was written to the sink.
+		var rows []string
+		require.NoError(t, filepath.Walk(filepath.Join(th.iodir, "execute"),
+			func(path string, info os.FileInfo, err error) error {
+				if err != nil || info.IsDir() || !strings.HasSuffix(path, ".ndjson") {
+					return err
+				}
+				content, err := ioutil.ReadFile(path)
+				if err != nil {
+					return err
+				}
+				rows = append(rows, strings.Split(strings.TrimSpace(string(content)), "\n")...)
+				return nil
+			}))
+		sort.Strings(rows)
+		require.Equal(t, []string{
+			`{"after": {"a": 1, "b": "one"}, "key": [1]}`,
+			`{"after": {"a": 2, "b": "two"}, "key": [2]}`,
+			`{"after": {"a": 3, "b": "three"}, "key": [3]}`,
+		}, rows)
+	})
+
+	t.Run("on_previous_running", func(t *testing.T) {
+		for _, tc := range []struct {
+			option string
+			// started is set if the schedule starts a changefeed although the
+			// previous one is still running.
+			started bool
+			status  string
+		}{
+			{option: "start", started: true},
+			{option: "skip", status: "rescheduled due to 1 already running"},
+			{option: "wait", status: "delayed due to 1 already running"},
+		} {
+			t.Run(tc.option, func(t *testing.T) {
+				id := th.createSchedule(t, fmt.Sprintf(
+					`CREATE SCHEDULE FOR CHANGEFEED d.t INTO 'nodelocal://0/%s' RECURRING '@hourly'
+WITH SCHEDULE OPTIONS on_previous_running = '%s'`, tc.option, tc.option))
+				defer th.sqlDB.Exec(t, `DROP SCHEDULE $1`, id)
+				th.addPausedScheduledJob(t, id)
+
+				th.fireSchedule(t, id)
+				if tc.started {
+					th.waitForScheduledJob(t, id, jobs.StatusSucceeded)
+					require.Equal(t, 2, numScheduledJobs(t, id))
+					return
+				}
+				require.Equal(t, 1, numScheduledJobs(t, id))
+				sj := th.loadSchedule(t, id)
+				require.Equal(t, tc.status, sj.ScheduleStatus())
+				require.True(t, sj.NextRun().After(th.env.Now()))
+			})
+		}
+	})
+
+	t.Run("on_execution_failure", func(t *testing.T) {
+		th.sqlDB.Exec(t, `CREATE TABLE d.dropped (a INT PRIMARY KEY)`)
+		id := th.createSchedule(t,
+			`CREATE SCHEDULE FOR CHANGEFEED d.dropped INTO 'nodelocal://0/failure' RECURRING '@hourly'
+WITH SCHEDULE OPTIONS on_execution_failure = 'pause'`)
+		defer th.sqlDB.Exec(t, `DROP SCHEDULE $1`, id)
+		th.sqlDB.Exec(t, `DROP TABLE d.dropped`)
+
+		// The changefeed cannot be planned, so no job is started and the
+		// schedule is paused.
+		th.fireSchedule(t, id)
+		require.Equal(t, 0, numScheduledJobs(t, id))
+		sj := th.loadSchedule(t, id)
+		require.True(t, sj.IsPaused())
+		require.Regexp(t, "schedule paused: failed to create job for schedule", sj.ScheduleStatus())
+	})
+}
@@ -10,7 +10,6 @@ package changefeedccl
 
 import (
 	"bytes"
-	"compress/gzip"
 	"context"
 	"crypto/rand"
 	"encoding/hex"
@@ -27,8 +26,10 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/cloud"
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/util/compressutil"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -59,7 +60,10 @@ func cloudStorageFormatTime(ts hlc.Timestamp) string {
 
 type cloudStorageSinkFile struct {
 	cloudStorageSinkKey
-	codec         io.WriteCloser
+	codec io.WriteCloser
+	// parquet is set if the file is a parquet file, in which case rows are
+	// added to it with addParquetRow rather than written with Write.
+	parquet       *parquetFileWriter
 	rawSize       int
 	numMessages   int
 	buf           bytes.Buffer
@@ -79,6 +83,21 @@ func (f *cloudStorageSinkFile) Write(p []byte) (int, error) {
 	return f.buf.Write(p)
 }
 
+// addParquetRow adds a row encoded by a parquetEncoder to the parquet file.
+func (f *cloudStorageSinkFile) addParquetRow(value []byte) error {
+	f.rawSize += len(value)
+	f.numMessages++
+	return f.parquet.addRow(value)
+}
+
+// size returns the size of the file so far.
+func (f *cloudStorageSinkFile) size() int64 {
+	if f.parquet != nil {
+		return f.parquet.size()
+	}
+	return int64(f.buf.Len())
+}
+
 // cloudStorageSink writes changefeed output to files in a cloud storage bucket
 // (S3/GCS/HTTP) maintaining CDC's ordering guarantees (see below) for each
 // row through lexicographical filename ordering.
@@ -172,9 +191,9 @@ func (f *cloudStorageSinkFile) Write(p []byte) (int, error) {
 // by a given `<sink_id>` and <session_id> is a unique identifying string for the job
 // session running the `changeAggregator` that owns this sink.
 //
-// `<ext>` implies the format of the file: currently the only option is
-// `ndjson`, which means a text file conforming to the "Newline Delimited JSON"
-// spec.
+// `<ext>` implies the format of the file: `ndjson`, which means a text file
+// conforming to the "Newline Delimited JSON" spec, `csv` or `parquet`. In
+// parquet files, each row group is at most (roughly) the target file size.
 //
 // This naming convention of data files is carefully chosen in order to preserve
 // the external ordering guarantees of CDC. Naming output files in this fashion
@@ -284,8 +303,11 @@ type cloudStorageSink struct {
 
 	ext          string
 	rowDelimiter []byte
+	parquet      bool
+
+	virtualColumnVisibility string
 
-	compression string
+	compression compressutil.Codec
 
 	es cloud.ExternalStorage
 
@@ -305,8 +327,6 @@ type cloudStorageSink struct {
 	metrics           *sliMetrics
 }
 
-const sinkCompressionGzip = "gzip"
-
 var cloudStorageSinkIDAtomic int64
 
 // Files that are emitted can be partitioned by their earliest event time,
@@ -372,20 +392,31 @@ func makeCloudStorageSink(
 		s.dataFilePartition = s.timestampOracle.inclusiveLowerBoundTS().GoTime().Format(s.partitionFormat)
 	}
 
+	// The formats which write the columns of a row without an envelope (csv and
+	// parquet) include an event type column, so deletions can be told apart from
+	// upserts without the wrapped envelope.
+	envelope := changefeedbase.OptEnvelopeWrapped
 	switch changefeedbase.FormatType(opts[changefeedbase.OptFormat]) {
 	case changefeedbase.OptFormatJSON:
 		// TODO(dan): It seems like these should be on the encoder, but that
 		// would require a bit of refactoring.
 		s.ext = `.ndjson`
 		s.rowDelimiter = []byte{'\n'}
+	case changefeedbase.OptFormatCSV:
+		s.ext = `.csv`
+		s.rowDelimiter = []byte{'\n'}
+		envelope = changefeedbase.OptEnvelopeRow
+	case changefeedbase.OptFormatParquet:
+		s.ext = `.parquet`
+		s.parquet = true
+		s.virtualColumnVisibility = opts[changefeedbase.OptVirtualColumns]
+		envelope = changefeedbase.OptEnvelopeRow
 	default:
 		return nil, errors.Errorf(`this sink is incompatible with %s=%s`,
 			changefeedbase.OptFormat, opts[changefeedbase.OptFormat])
 	}
 
-	switch changefeedbase.EnvelopeType(opts[changefeedbase.OptEnvelope]) {
-	case changefeedbase.OptEnvelopeWrapped:
-	default:
+	if changefeedbase.EnvelopeType(opts[changefeedbase.OptEnvelope]) != envelope {
 		return nil, errors.Errorf(`this sink is incompatible with %s=%s`,
 			changefeedbase.OptEnvelope, opts[changefeedbase.OptEnvelope])
 	}
@@ -395,10 +426,15 @@ func makeCloudStorageSink(
 	}
 
 	if codec, ok := opts[changefeedbase.OptCompression]; ok && codec != "" {
-		if strings.EqualFold(codec, "gzip") {
-			s.compression = sinkCompressionGzip
-			s.ext = s.ext + ".gz"
-		} else {
+		if s.parquet {
+			return nil, errors.Errorf(`%s is incompatible with %s=%s`,
+				changefeedbase.OptCompression, changefeedbase.OptFormat, changefeedbase.OptFormatParquet)
+		}
+		switch c, _ := compressutil.CodecFromName(codec); c {
+		case compressutil.Gzip, compressutil.Snappy, compressutil.Zstd:
+			s.compression = c
+			s.ext = s.ext + c.Extension()
+		default:
 			return nil, errors.Errorf(`unsupported compression codec %q`, codec)
 		}
 	}
@@ -412,26 +448,42 @@ func makeCloudStorageSink(
 
 func (s *cloudStorageSink) getOrCreateFile(
 	topic TopicDescriptor, eventMVCC hlc.Timestamp,
-) *cloudStorageSinkFile {
+) (*cloudStorageSinkFile, error) {
 	key := cloudStorageSinkKey{topic.GetName(), int64(topic.GetVersion())}
 	if item := s.files.Get(key); item != nil {
 		f := item.(*cloudStorageSinkFile)
 		if eventMVCC.Less(f.oldestMVCC) {
 			f.oldestMVCC = eventMVCC
 		}
-		return f
+		return f, nil
 	}
 	f := &cloudStorageSinkFile{
 		cloudStorageSinkKey: key,
 		recordMetrics:       s.metrics.recordEmittedMessages(),
 		oldestMVCC:          eventMVCC,
 	}
-	switch s.compression {
-	case sinkCompressionGzip:
-		f.codec = gzip.NewWriter(&f.buf)
+	if s.compression != compressutil.None {
+		var err error
+		if f.codec, err = compressutil.NewWriter(s.compression, &f.buf); err != nil {
+			return nil, err
+		}
+	}
+	if s.parquet {
+		// The schema of a parquet file is derived from the table the rows
+		// belong to, which is only available if the topic is a table.
+		desc, ok := topic.(catalog.TableDescriptor)
+		if !ok {
+			return nil, errors.AssertionFailedf("unexpected topic %T for parquet file", topic)
+		}
+		var err error
+		if f.parquet, err = newParquetFileWriter(
+			&f.buf, desc, s.virtualColumnVisibility, s.targetMaxFileSize,
+		); err != nil {
+			return nil, err
+		}
 	}
 	s.files.ReplaceOrInsert(f)
-	return f
+	return f, nil
 }
 
 // EmitRow implements the Sink interface.
@@ -446,17 +498,26 @@ func (s *cloudStorageSink) EmitRow(
 		return errors.New(`cannot EmitRow on a closed sink`)
 	}
 
-	file := s.getOrCreateFile(topic, mvcc)
-	file.alloc.Merge(&alloc)
-
-	if _, err := file.Write(value); err != nil {
+	file, err := s.getOrCreateFile(topic, mvcc)
+	if err != nil {
 		return err
 	}
-	if _, err := file.Write(s.rowDelimiter); err != nil {
-		return err
+	file.alloc.Merge(&alloc)
+
+	if file.parquet != nil {
+		if err := file.addParquetRow(value); err != nil {
+			return err
+		}
+	} else {
+		if _, err := file.Write(value); err != nil {
+			return err
+		}
+		if _, err := file.Write(s.rowDelimiter); err != nil {
+			return err
+		}
 	}
 
-	if int64(file.buf.Len()) > s.targetMaxFileSize {
+	if file.size() > s.targetMaxFileSize {
 		if err := s.flushTopicVersions(ctx, file.topic, file.schemaID); err != nil {
 			return err
 		}
@@ -566,6 +627,11 @@ func (s *cloudStorageSink) flushFile(ctx context.Context, file *cloudStorageSink
 			return err
 		}
 	}
+	if file.parquet != nil {
+		if err := file.parquet.close(); err != nil {
+			return err
+		}
+	}
 
 	// We use this monotonically increasing fileID to ensure correct ordering
 	// among files emitted at the same timestamp during the same job session.
@@ -10,7 +10,6 @@ package changefeedccl
 
 import (
 	"bytes"
-	"compress/gzip"
 	"context"
 	"fmt"
 	"io/ioutil"
@@ -34,11 +33,15 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/compressutil"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/span"
+	goparquet "github.com/fraugster/parquet-go"
 	"github.com/stretchr/testify/require"
 )
 
@@ -55,8 +58,8 @@ func TestCloudStorageSink(t *testing.T) {
 	dir, dirCleanupFn := testutils.TempDir(t)
 	defer dirCleanupFn()
 
-	gzipDecompress := func(t *testing.T, compressed []byte) []byte {
-		r, err := gzip.NewReader(bytes.NewReader(compressed))
+	decompress := func(t *testing.T, codec compressutil.Codec, compressed []byte) []byte {
+		r, err := compressutil.NewReader(codec, bytes.NewReader(compressed))
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -122,8 +125,8 @@ func TestCloudStorageSink(t *testing.T) {
 			if err != nil {
 				return err
 			}
-			if strings.HasSuffix(path, ".gz") {
-				file = gzipDecompress(t, file)
+			if codec := compressutil.CodecFromFileName(path); codec != compressutil.None {
+				file = decompress(t, codec, file)
 			}
 			files = append(files, string(file))
 			return nil
@@ -196,6 +199,66 @@ func TestCloudStorageSink(t *testing.T) {
 		require.Equal(t, `{"resolved":"5.0000000000"}`, string(resolvedFile))
 	})
 
+	t.Run(`parquet`, func(t *testing.T) {
+		tableDesc, err := parseTableDesc(`CREATE TABLE t1 (a INT PRIMARY KEY, b STRING)`)
+		require.NoError(t, err)
+		t1 := tableDescriptorTopic{tableDesc}
+		parquetOpts := map[string]string{
+			changefeedbase.OptFormat:     string(changefeedbase.OptFormatParquet),
+			changefeedbase.OptEnvelope:   string(changefeedbase.OptEnvelopeRow),
+			changefeedbase.OptKeyInValue: ``,
+		}
+		pe, err := newParquetEncoder(parquetOpts)
+		require.NoError(t, err)
+
+		testSpan := roachpb.Span{Key: []byte("a"), EndKey: []byte("b")}
+		sf, err := span.MakeFrontier(testSpan)
+		require.NoError(t, err)
+		timestampOracle := &changeAggregatorLowerBoundOracle{sf: sf}
+		sinkDir := `parquet`
+		s, err := makeCloudStorageSink(
+			ctx, sinkURI(sinkDir, unlimitedFileSize), 1, settings,
+			parquetOpts, timestampOracle, externalStorageFromURI, user, nil,
+		)
+		require.NoError(t, err)
+		defer func() { require.NoError(t, s.Close()) }()
+
+		for _, r := range []encodeRow{
+			{
+				datums:    rowenc.EncDatumRow{{Datum: tree.NewDInt(1)}, {Datum: tree.NewDString(`bar`)}},
+				tableDesc: tableDesc,
+			},
+			{
+				datums:    rowenc.EncDatumRow{{Datum: tree.NewDInt(2)}, {Datum: tree.DNull}},
+				deleted:   true,
+				tableDesc: tableDesc,
+			},
+		} {
+			value, err := pe.EncodeValue(ctx, r)
+			require.NoError(t, err)
+			require.NoError(t, s.EmitRow(ctx, t1, noKey, value, ts(1), ts(1), zeroAlloc))
+		}
+		require.NoError(t, s.Flush(ctx))
+
+		files := slurpDir(t, sinkDir)
+		require.Len(t, files, 1)
+		fr, err := goparquet.NewFileReader(strings.NewReader(files[0]))
+		require.NoError(t, err)
+		require.EqualValues(t, 2, fr.NumRows())
+
+		row, err := fr.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, int64(1), row[`a`])
+		require.Equal(t, []byte(`bar`), row[`b`])
+		require.Equal(t, []byte(flatEventTypeUpsert), row[flatEventTypeColumn])
+
+		row, err = fr.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, int64(2), row[`a`])
+		require.Nil(t, row[`b`])
+		require.Equal(t, []byte(flatEventTypeDelete), row[flatEventTypeColumn])
+	})
+
 	forwardFrontier := func(f *span.Frontier, s roachpb.Span, wall int64) bool {
 		forwarded, err := f.Forward(s, ts(wall))
 		require.NoError(t, err)
@@ -209,7 +272,7 @@ func TestCloudStorageSink(t *testing.T) {
 		defer func() {
 			opts[changefeedbase.OptCompression] = before
 		}()
-		for _, compression := range []string{"", "gzip"} {
+		for _, compression := range []string{"", "gzip", "snappy", "zstd"} {
 			opts[changefeedbase.OptCompression] = compression
 			t.Run("compress="+compression, func(t *testing.T) {
 				t1 := makeTopic(`t1`)
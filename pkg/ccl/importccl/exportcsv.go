@@ -10,7 +10,6 @@ package importccl
 
 import (
 	"bytes"
-	"compress/gzip"
 	"context"
 	"fmt"
 	"strings"
@@ -24,6 +23,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/compressutil"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding/csv"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/cockroachdb/errors"
@@ -36,7 +36,8 @@ const exportFilePatternDefault = exportFilePatternPart + ".csv"
 // and csv writer, encapsulating the internals to make
 // exporting oblivious for the consumers.
 type csvExporter struct {
-	compressor *gzip.Writer
+	codec      compressutil.Codec
+	compressor compressutil.Writer
 	buf        *bytes.Buffer
 	csvWriter  *csv.Writer
 }
@@ -91,38 +92,42 @@ func (c *csvExporter) FileName(spec execinfrapb.CSVWriterSpec, part string) stri
 	}
 
 	fileName := strings.Replace(pattern, exportFilePatternPart, part, -1)
-	// TODO: add suffix based on compressor type
-	if c.compressor != nil {
-		fileName += ".gz"
-	}
-	return fileName
+	return fileName + c.codec.Extension()
 }
 
-func newCSVExporter(sp execinfrapb.CSVWriterSpec) *csvExporter {
+func newCSVExporter(sp execinfrapb.CSVWriterSpec) (*csvExporter, error) {
 	buf := bytes.NewBuffer([]byte{})
 	var exporter *csvExporter
+	codec := compressutil.None
 	switch sp.CompressionCodec {
 	case execinfrapb.FileCompression_Gzip:
-		{
-			writer := gzip.NewWriter(buf)
-			exporter = &csvExporter{
-				compressor: writer,
-				buf:        buf,
-				csvWriter:  csv.NewWriter(writer),
-			}
+		codec = compressutil.Gzip
+	case execinfrapb.FileCompression_Snappy:
+		codec = compressutil.Snappy
+	case execinfrapb.FileCompression_Zstd:
+		codec = compressutil.Zstd
+	}
+	if codec != compressutil.None {
+		writer, err := compressutil.NewWriter(codec, buf)
+		if err != nil {
+			return nil, err
 		}
-	default:
-		{
-			exporter = &csvExporter{
-				buf:       buf,
-				csvWriter: csv.NewWriter(buf),
-			}
+		exporter = &csvExporter{
+			codec:      codec,
+			compressor: writer,
+			buf:        buf,
+			csvWriter:  csv.NewWriter(writer),
+		}
+	} else {
+		exporter = &csvExporter{
+			buf:       buf,
+			csvWriter: csv.NewWriter(buf),
 		}
 	}
 	if sp.Options.Comma != 0 {
 		exporter.csvWriter.Comma = sp.Options.Comma
 	}
-	return exporter
+	return exporter, nil
 }
 
 func newCSVWriterProcessor(
@@ -183,7 +188,10 @@ func (sp *csvWriter) Run(ctx context.Context) {
 
 		alloc := &tree.DatumAlloc{}
 
-		writer := newCSVExporter(sp.spec)
+		writer, err := newCSVExporter(sp.spec)
+		if err != nil {
+			return err
+		}
 
 		var nullsAs string
 		if sp.spec.Options.NullEncoding != nil {
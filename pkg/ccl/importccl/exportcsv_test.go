@@ -23,11 +23,13 @@ import (
 	"testing"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/config"
 	"github.com/cockroachdb/cockroach/pkg/config/zonepb"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/server"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
@@ -612,6 +614,40 @@ func TestExportOrderCompressed(t *testing.T) {
 	}
 }
 
+// TestExportCompressionMixedVersion checks that the snappy and zstd codecs are
+// rejected until all nodes can write them.
+func TestExportCompressionMixedVersion(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	dir, cleanupDir := testutils.TempDir(t)
+	defer cleanupDir()
+
+	srv, db, _ := serverutils.StartServer(t, base.TestServerArgs{
+		ExternalIODir: dir,
+		Knobs: base.TestingKnobs{
+			Server: &server.TestingKnobs{
+				DisableAutomaticVersionUpgrade: 1,
+				BinaryVersionOverride:          clusterversion.ByKey(clusterversion.SnappyAndZstdCompression - 1),
+			},
+		},
+	})
+	defer srv.Stopper().Stop(context.Background())
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	sqlDB.Exec(t, `CREATE TABLE foo (i INT PRIMARY KEY)`)
+	sqlDB.Exec(t, `EXPORT INTO CSV 'nodelocal://0/gzip' WITH compression = gzip FROM TABLE foo`)
+	for _, codec := range []string{"snappy", "zstd"} {
+		sqlDB.ExpectErr(t, fmt.Sprintf(`compression codec %s requires all nodes to be upgraded`, codec),
+			fmt.Sprintf(`EXPORT INTO CSV 'nodelocal://0/%[1]s' WITH compression = %[1]s FROM TABLE foo`, codec))
+	}
+
+	sqlDB.Exec(t, `SET CLUSTER SETTING version = $1`,
+		clusterversion.ByKey(clusterversion.SnappyAndZstdCompression).String())
+	for _, codec := range []string{"snappy", "zstd"} {
+		sqlDB.Exec(t, fmt.Sprintf(`EXPORT INTO CSV 'nodelocal://0/%[1]s' WITH compression = %[1]s FROM TABLE foo`, codec))
+	}
+}
+
 func TestExportShow(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
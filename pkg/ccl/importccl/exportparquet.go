@@ -290,16 +290,77 @@ func newParquetSchema(parquetFields []parquetColumn) *parquetschema.SchemaDefini
 }
 
 func buildFileWriter(
-	buf *bytes.Buffer, schema *parquetschema.SchemaDefinition,
+	buf *bytes.Buffer, schema *parquetschema.SchemaDefinition, opts ...goparquet.FileWriterOption,
 ) *goparquet.FileWriter {
-	pw := goparquet.NewFileWriter(buf,
+	opts = append([]goparquet.FileWriterOption{
 		// TODO(MB): allow for user defined compression
 		goparquet.WithCompressionCodec(parquet.CompressionCodec_SNAPPY),
 		goparquet.WithSchemaDefinition(schema),
-	)
+	}, opts...)
+	pw := goparquet.NewFileWriter(buf, opts...)
 	return pw
 }
 
+// ParquetWriter writes rows of datums to a parquet file. Unlike the
+// parquetWriterProcessor, which exports the result of a query, it can be used
+// by callers which produce the rows themselves, such as changefeeds writing to
+// cloud storage.
+type ParquetWriter struct {
+	parquetColumns []parquetColumn
+	parquetWriter  *goparquet.FileWriter
+	parquetRow     map[string]interface{}
+}
+
+// NewParquetWriter returns a ParquetWriter which writes to buf a parquet file
+// with the given (nullable) columns. Row groups are flushed to buf once their
+// estimated size reaches maxRowGroupSize.
+func NewParquetWriter(
+	buf *bytes.Buffer, colNames []string, typs []*types.T, maxRowGroupSize int64,
+) (*ParquetWriter, error) {
+	parquetColumns := make([]parquetColumn, len(typs))
+	for i := range typs {
+		parquetCol, err := newParquetColumn(typs[i], colNames[i], true /* nullable */)
+		if err != nil {
+			return nil, err
+		}
+		parquetColumns[i] = parquetCol
+	}
+	schema := newParquetSchema(parquetColumns)
+	return &ParquetWriter{
+		parquetColumns: parquetColumns,
+		parquetWriter:  buildFileWriter(buf, schema, goparquet.WithMaxRowGroupSize(maxRowGroupSize)),
+		parquetRow:     make(map[string]interface{}, len(typs)),
+	}, nil
+}
+
+// AddRow appends a row to the parquet file. The datums must match the columns
+// the writer was created with.
+func (w *ParquetWriter) AddRow(row tree.Datums) error {
+	for i, d := range row {
+		if d == tree.DNull {
+			w.parquetRow[w.parquetColumns[i].name] = nil
+			continue
+		}
+		native, err := w.parquetColumns[i].encodeFn(d)
+		if err != nil {
+			return err
+		}
+		w.parquetRow[w.parquetColumns[i].name] = native
+	}
+	return w.parquetWriter.AddData(w.parquetRow)
+}
+
+// Size returns an estimate of the size of the parquet file, including the
+// rows of the row group which has not been flushed yet.
+func (w *ParquetWriter) Size() int64 {
+	return w.parquetWriter.CurrentFileSize() + w.parquetWriter.CurrentRowGroupSize()
+}
+
+// Close flushes the last row group and writes the footer of the parquet file.
+func (w *ParquetWriter) Close() error {
+	return w.parquetWriter.Close()
+}
+
 func newParquetWriterProcessor(
 	flowCtx *execinfra.FlowCtx,
 	processorID int32,
@@ -22,6 +22,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
 	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/featureflag"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
@@ -79,9 +80,9 @@ const (
 	pgCopyNull      = "nullif"
 
 	optMaxRowSize = "max_row_size"
+	// Turn on strict validation when importing avro, parquet or ndjson records.
+	optStrictValidation = "strict_validation"
 
-	// Turn on strict validation when importing avro records.
-	avroStrict = "strict_validation"
 	// Default input format is assumed to be OCF (object container file).
 	// This default can be changed by specified either of these options.
 	avroBinRecords  = "data_as_binary_records"
@@ -125,9 +126,9 @@ var importOptionExpectValues = map[string]sql.KVStringOptValidate{
 	importOptionDisableGlobMatch: sql.KVStringOptRequireNoValue,
 	importOptionDetached:         sql.KVStringOptRequireNoValue,
 
-	optMaxRowSize: sql.KVStringOptRequireValue,
+	optMaxRowSize:       sql.KVStringOptRequireValue,
+	optStrictValidation: sql.KVStringOptRequireNoValue,
 
-	avroStrict:             sql.KVStringOptRequireNoValue,
 	avroSchema:             sql.KVStringOptRequireValue,
 	avroSchemaURI:          sql.KVStringOptRequireValue,
 	avroRecordsSeparatedBy: sql.KVStringOptRequireValue,
@@ -163,7 +164,7 @@ var allowedCommonOptions = makeStringSet(
 
 // Format specific allowed options.
 var avroAllowedOptions = makeStringSet(
-	avroStrict, avroBinRecords, avroJSONRecords,
+	optStrictValidation, avroBinRecords, avroJSONRecords,
 	avroRecordsSeparatedBy, avroSchema, avroSchemaURI, optMaxRowSize, csvRowLimit,
 )
 var csvAllowedOptions = makeStringSet(
@@ -177,6 +178,8 @@ var mysqlDumpAllowedOptions = makeStringSet(importOptionSkipFKs, csvRowLimit)
 var pgCopyAllowedOptions = makeStringSet(pgCopyDelimiter, pgCopyNull, optMaxRowSize)
 var pgDumpAllowedOptions = makeStringSet(optMaxRowSize, importOptionSkipFKs, csvRowLimit,
 	pgDumpIgnoreAllUnsupported, pgDumpIgnoreShuntFileDest)
+var parquetAllowedOptions = makeStringSet(optStrictValidation, csvRowLimit)
+var ndjsonAllowedOptions = makeStringSet(optStrictValidation, optMaxRowSize, csvRowLimit)
 
 // DROP is required because the target table needs to be take offline during
 // IMPORT INTO.
@@ -188,6 +191,8 @@ var allowedIntoFormats = map[string]struct{}{
 	"AVRO":      {},
 	"DELIMITED": {},
 	"PGCOPY":    {},
+	"PARQUET":   {},
+	"NDJSON":    {},
 }
 
 // featureImportEnabled is used to enable and disable the IMPORT feature.
@@ -724,6 +729,56 @@ func importPlanHook(
 			if err != nil {
 				return err
 			}
+		case "PARQUET":
+			if err = validateFormatOptions(importStmt.FileFormat, opts, parquetAllowedOptions); err != nil {
+				return err
+			}
+			format.Format = roachpb.IOFileFormat_Parquet
+			_, format.Parquet.StrictMode = opts[optStrictValidation]
+			if _, ok := opts[importOptionSaveRejected]; ok {
+				format.SaveRejected = true
+			}
+			if override, ok := opts[csvRowLimit]; ok {
+				rowLimit, err := strconv.Atoi(override)
+				if err != nil {
+					return pgerror.Wrapf(err, pgcode.Syntax, "invalid numeric %s value", csvRowLimit)
+				}
+				if rowLimit <= 0 {
+					return pgerror.Newf(pgcode.Syntax, "%s must be > 0", csvRowLimit)
+				}
+				format.Parquet.RowLimit = int64(rowLimit)
+			}
+		case "NDJSON":
+			if err = validateFormatOptions(importStmt.FileFormat, opts, ndjsonAllowedOptions); err != nil {
+				return err
+			}
+			format.Format = roachpb.IOFileFormat_NDJSON
+			_, format.Ndjson.StrictMode = opts[optStrictValidation]
+			if _, ok := opts[importOptionSaveRejected]; ok {
+				format.SaveRejected = true
+			}
+			maxRowSize := int32(defaultScanBuffer)
+			if override, ok := opts[optMaxRowSize]; ok {
+				sz, err := humanizeutil.ParseBytes(override)
+				if err != nil {
+					return err
+				}
+				if sz < 1 || sz > math.MaxInt32 {
+					return errors.Errorf("%s out of range: %d", override, sz)
+				}
+				maxRowSize = int32(sz)
+			}
+			format.Ndjson.MaxRowSize = maxRowSize
+			if override, ok := opts[csvRowLimit]; ok {
+				rowLimit, err := strconv.Atoi(override)
+				if err != nil {
+					return pgerror.Wrapf(err, pgcode.Syntax, "invalid numeric %s value", csvRowLimit)
+				}
+				if rowLimit <= 0 {
+					return pgerror.Newf(pgcode.Syntax, "%s must be > 0", csvRowLimit)
+				}
+				format.Ndjson.RowLimit = int64(rowLimit)
+			}
 		default:
 			return unimplemented.Newf("import.format", "unsupported import format: %q", importStmt.FileFormat)
 		}
@@ -766,6 +821,24 @@ func importPlanHook(
 				return unimplemented.Newf("import.compression", "unsupported compression value: %q", override)
 			}
 		}
+		if !p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.SnappyAndZstdCompression) {
+			for _, file := range files {
+				switch c := guessCompressionFromName(file, format.Compression); c {
+				case roachpb.IOFileFormat_Snappy, roachpb.IOFileFormat_Zstd:
+					return pgerror.Newf(pgcode.FeatureNotSupported,
+						"importing %s compressed files requires all nodes to be upgraded to %s",
+						strings.ToLower(c.String()), clusterversion.ByKey(clusterversion.SnappyAndZstdCompression))
+				}
+			}
+		}
+		switch format.Format {
+		case roachpb.IOFileFormat_Parquet, roachpb.IOFileFormat_NDJSON:
+			if !p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.ImportParquetAndNDJSON) {
+				return pgerror.Newf(pgcode.FeatureNotSupported,
+					"IMPORT %s requires all nodes to be upgraded to %s",
+					importStmt.FileFormat, clusterversion.ByKey(clusterversion.ImportParquetAndNDJSON))
+			}
+		}
 
 		var tableDetails []jobspb.ImportDetails_Table
 		var typeDetails []jobspb.ImportDetails_Type
@@ -1000,7 +1073,7 @@ func parseAvroOptions(
 	format.Format = roachpb.IOFileFormat_Avro
 	// Default input format is OCF.
 	format.Avro.Format = roachpb.AvroOptions_OCF
-	_, format.Avro.StrictMode = opts[avroStrict]
+	_, format.Avro.StrictMode = opts[optStrictValidation]
 
 	_, haveBinRecs := opts[avroBinRecords]
 	_, haveJSONRecs := opts[avroJSONRecords]
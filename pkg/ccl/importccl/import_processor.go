@@ -308,6 +308,14 @@ func makeInputConverter(
 		return newAvroInputReader(
 			semaCtx, kvCh, singleTable, spec.Format.Avro, spec.WalltimeNanos,
 			int(spec.ReaderParallelism), evalCtx)
+	case roachpb.IOFileFormat_Parquet:
+		return newParquetInputReader(
+			semaCtx, kvCh, spec.Format.Parquet, spec.WalltimeNanos, int(spec.ReaderParallelism),
+			singleTable, singleTableTargetCols, evalCtx, seqChunkProvider), nil
+	case roachpb.IOFileFormat_NDJSON:
+		return newNDJSONInputReader(
+			semaCtx, kvCh, spec.Format.Ndjson, spec.WalltimeNanos, int(spec.ReaderParallelism),
+			singleTable, singleTableTargetCols, evalCtx, seqChunkProvider), nil
 	default:
 		return nil, errors.Errorf(
 			"Requested IMPORT format (%d) not supported by this node", spec.Format.Format)
@@ -2769,6 +2769,35 @@ func TestExportImportRoundTrip(t *testing.T) {
 			tbl:      "t",
 			expected: `SELECT 'dog' COLLATE en`,
 		},
+		// Compressed files are decompressed based on their extension.
+		{
+			stmts: `EXPORT INTO CSV 'nodelocal://0/%[1]s' WITH compression = 'gzip' FROM SELECT generate_series(1, 100);
+							CREATE TABLE t (x INT);
+							IMPORT INTO t CSV DATA ('nodelocal://0/%[1]s/export*-n*.0.csv.gz')`,
+			tbl:      "t",
+			expected: `SELECT generate_series(1, 100)`,
+		},
+		{
+			stmts: `EXPORT INTO CSV 'nodelocal://0/%[1]s' WITH compression = 'snappy' FROM SELECT generate_series(1, 100);
+							CREATE TABLE t (x INT);
+							IMPORT INTO t CSV DATA ('nodelocal://0/%[1]s/export*-n*.0.csv.sz')`,
+			tbl:      "t",
+			expected: `SELECT generate_series(1, 100)`,
+		},
+		{
+			stmts: `EXPORT INTO CSV 'nodelocal://0/%[1]s' WITH compression = 'zstd' FROM SELECT generate_series(1, 100);
+							CREATE TABLE t (x INT);
+							IMPORT INTO t CSV DATA ('nodelocal://0/%[1]s/export*-n*.0.csv.zst')`,
+			tbl:      "t",
+			expected: `SELECT generate_series(1, 100)`,
+		},
+		{
+			stmts: `EXPORT INTO CSV 'nodelocal://0/%[1]s' WITH compression = 'zstd' FROM SELECT generate_series(1, 100);
+							CREATE TABLE t (x INT);
+							IMPORT INTO t CSV DATA ('nodelocal://0/%[1]s/export*-n*.0.csv.zst') WITH decompress = 'zstd'`,
+			tbl:      "t",
+			expected: `SELECT generate_series(1, 100)`,
+		},
 	}
 
 	for i, test := range tests {
@@ -6388,6 +6417,239 @@ func TestImportAvro(t *testing.T) {
 	})
 }
 
+func TestImportParquet(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	dir, cleanupDir := testutils.TempDir(t)
+	defer cleanupDir()
+
+	srv, db, _ := serverutils.StartServer(t, base.TestServerArgs{ExternalIODir: dir})
+	defer srv.Stopper().Stop(context.Background())
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	sqlDB.Exec(t, `CREATE TABLE src (i INT PRIMARY KEY, x STRING, y FLOAT, b BOOL, a INT[])`)
+	sqlDB.Exec(t, `INSERT INTO src VALUES (1, 'Alice', 1.5, true, ARRAY[1, 2]),
+(2, 'Bob', NULL, false, ARRAY[]), (3, NULL, 3.25, NULL, NULL)`)
+	sqlDB.Exec(t, `EXPORT INTO PARQUET 'nodelocal://0/src' FROM SELECT * FROM src`)
+	const data = `nodelocal://0/src/*.parquet`
+
+	t.Run("import-into", func(t *testing.T) {
+		sqlDB.Exec(t, `CREATE TABLE dst (i INT PRIMARY KEY, x STRING, y FLOAT, b BOOL, a INT[])`)
+		defer sqlDB.Exec(t, `DROP TABLE dst`)
+		sqlDB.Exec(t, `IMPORT INTO dst PARQUET DATA ($1)`, data)
+		sqlDB.CheckQueryResults(t, `SELECT * FROM dst ORDER BY i`,
+			sqlDB.QueryStr(t, `SELECT * FROM src ORDER BY i`))
+	})
+
+	t.Run("columns-mapped-by-name", func(t *testing.T) {
+		sqlDB.Exec(t, `CREATE TABLE dst (x STRING, i INT PRIMARY KEY, z INT DEFAULT 7)`)
+		defer sqlDB.Exec(t, `DROP TABLE dst`)
+		sqlDB.Exec(t, `IMPORT INTO dst (i, x) PARQUET DATA ($1)`, data)
+		sqlDB.CheckQueryResults(t, `SELECT i, x, z FROM dst ORDER BY i`, [][]string{
+			{"1", "Alice", "7"}, {"2", "Bob", "7"}, {"3", "NULL", "7"},
+		})
+	})
+
+	t.Run("converts-to-target-type", func(t *testing.T) {
+		sqlDB.Exec(t, `CREATE TABLE dst (i DECIMAL PRIMARY KEY, y STRING)`)
+		defer sqlDB.Exec(t, `DROP TABLE dst`)
+		sqlDB.Exec(t, `IMPORT INTO dst PARQUET DATA ($1)`, data)
+		sqlDB.CheckQueryResults(t, `SELECT i, y FROM dst ORDER BY i`, [][]string{
+			{"1", "1.5"}, {"2", "NULL"}, {"3", "3.25"},
+		})
+	})
+
+	t.Run("row-limit", func(t *testing.T) {
+		sqlDB.Exec(t, `CREATE TABLE dst (i INT PRIMARY KEY)`)
+		defer sqlDB.Exec(t, `DROP TABLE dst`)
+		sqlDB.Exec(t, `IMPORT INTO dst PARQUET DATA ($1) WITH row_limit = '2'`, data)
+		sqlDB.CheckQueryResults(t, `SELECT count(*) FROM dst`, [][]string{{"2"}})
+	})
+
+	t.Run("strict-validation", func(t *testing.T) {
+		sqlDB.Exec(t, `CREATE TABLE dst (i INT PRIMARY KEY, x STRING)`)
+		defer sqlDB.Exec(t, `DROP TABLE dst`)
+		sqlDB.ExpectErr(t, `could not find column for parquet column y`,
+			`IMPORT INTO dst PARQUET DATA ($1) WITH strict_validation`, data)
+		sqlDB.Exec(t, `IMPORT INTO dst (i, x) PARQUET DATA ($1)`, data)
+		sqlDB.CheckQueryResults(t, `SELECT count(*) FROM dst`, [][]string{{"3"}})
+	})
+
+	t.Run("save-rejected", func(t *testing.T) {
+		sqlDB.Exec(t, `CREATE TABLE dst (i INT PRIMARY KEY, x INT)`)
+		defer sqlDB.Exec(t, `DROP TABLE dst`)
+		sqlDB.ExpectErr(t, `could not parse "Alice" as type int`,
+			`IMPORT INTO dst PARQUET DATA ($1)`, data)
+		sqlDB.Exec(t, `IMPORT INTO dst PARQUET DATA ($1) WITH experimental_save_rejected`, data)
+		sqlDB.CheckQueryResults(t, `SELECT i, x FROM dst`, [][]string{{"3", "NULL"}})
+		rejected, err := filepath.Glob(filepath.Join(dir, "src", "*.parquet.rejected"))
+		require.NoError(t, err)
+		require.Equal(t, 1, len(rejected))
+
+		// The rejected rows are saved as JSON objects, which can be imported as
+		// NDJSON once the target table is fixed.
+		sqlDB.Exec(t, `CREATE TABLE fixed (i INT PRIMARY KEY, x STRING, y FLOAT, b BOOL, a INT[])`)
+		defer sqlDB.Exec(t, `DROP TABLE fixed`)
+		rel, err := filepath.Rel(dir, rejected[0])
+		require.NoError(t, err)
+		sqlDB.Exec(t, `IMPORT INTO fixed NDJSON DATA ($1)`, "nodelocal://0/"+filepath.ToSlash(rel))
+		sqlDB.CheckQueryResults(t, `SELECT * FROM fixed ORDER BY i`,
+			sqlDB.QueryStr(t, `SELECT * FROM src WHERE i < 3 ORDER BY i`))
+	})
+
+	t.Run("logical-types", func(t *testing.T) {
+		// logical-types.parquet has DATE, TIMESTAMP and DECIMAL columns with
+		// each of their physical representations, see testdata/parquet/README.md.
+		fixture, err := ioutil.ReadFile(testutils.TestDataPath(t, "parquet", "logical-types.parquet"))
+		require.NoError(t, err)
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "logical-types.parquet"), fixture, 0644))
+		const logicalTypes = `nodelocal://0/logical-types.parquet`
+
+		sqlDB.Exec(t, `CREATE TABLE dst (
+id INT PRIMARY KEY, d DATE, ts_millis TIMESTAMPTZ, ts_micros TIMESTAMP, ts_int96 TIMESTAMP,
+dec32 DECIMAL, dec64 DECIMAL(10, 3), dec_fixed DECIMAL, dec_binary DECIMAL)`)
+		defer sqlDB.Exec(t, `DROP TABLE dst`)
+		sqlDB.Exec(t, `IMPORT INTO dst PARQUET DATA ($1) WITH strict_validation`, logicalTypes)
+		sqlDB.CheckQueryResults(t, `SELECT
+id, d::STRING, ts_millis::STRING, ts_micros::STRING, ts_int96::STRING,
+dec32::STRING, dec64::STRING, dec_fixed::STRING, dec_binary::STRING
+FROM dst ORDER BY id`, [][]string{
+			{"1", "2022-03-04", "2022-03-04 05:06:07.891+00", "2022-03-04 05:06:07.891234",
+				"2022-03-04 05:06:07.891234", "123.45", "-9876.543", "12.3456", "-0.0000123456"},
+			{"2", "NULL", "NULL", "NULL", "NULL", "NULL", "NULL", "NULL", "NULL"},
+		})
+
+		// Values of other types are converted through their SQL representation.
+		sqlDB.Exec(t, `CREATE TABLE dst_strings (id INT PRIMARY KEY, d STRING, ts_micros DATE, dec32 FLOAT)`)
+		defer sqlDB.Exec(t, `DROP TABLE dst_strings`)
+		sqlDB.Exec(t, `IMPORT INTO dst_strings (id, d, ts_micros, dec32) PARQUET DATA ($1)`, logicalTypes)
+		sqlDB.CheckQueryResults(t, `SELECT id, d, ts_micros::STRING, dec32 FROM dst_strings ORDER BY id`, [][]string{
+			{"1", "2022-03-04", "2022-03-04", "123.45"},
+			{"2", "NULL", "NULL", "NULL"},
+		})
+	})
+
+	t.Run("max-file-size", func(t *testing.T) {
+		sqlDB.Exec(t, `CREATE TABLE dst (i INT PRIMARY KEY, x STRING, y FLOAT, b BOOL, a INT[])`)
+		defer sqlDB.Exec(t, `DROP TABLE dst`)
+		sqlDB.Exec(t, `SET CLUSTER SETTING bulkio.import.parquet.max_file_size = '10B'`)
+		defer sqlDB.Exec(t, `RESET CLUSTER SETTING bulkio.import.parquet.max_file_size`)
+		sqlDB.ExpectErr(t, `parquet file is larger than 10 B, the maximum size which can be imported`,
+			`IMPORT INTO dst PARQUET DATA ($1)`, data)
+	})
+}
+
+// TestImportParquetAndNDJSONMixedVersion checks that the PARQUET and NDJSON
+// formats are rejected until all nodes can read them.
+func TestImportParquetAndNDJSONMixedVersion(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	dir, cleanupDir := testutils.TempDir(t)
+	defer cleanupDir()
+
+	srv, db, _ := serverutils.StartServer(t, base.TestServerArgs{
+		ExternalIODir: dir,
+		Knobs: base.TestingKnobs{
+			Server: &server.TestingKnobs{
+				DisableAutomaticVersionUpgrade: 1,
+				BinaryVersionOverride:          clusterversion.ByKey(clusterversion.ImportParquetAndNDJSON - 1),
+			},
+		},
+	})
+	defer srv.Stopper().Stop(context.Background())
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "t.ndjson"), []byte(`{"i": 1}`+"\n"), 0644))
+	sqlDB.Exec(t, `CREATE TABLE t (i INT PRIMARY KEY)`)
+	sqlDB.ExpectErr(t, `IMPORT NDJSON requires all nodes to be upgraded`,
+		`IMPORT INTO t NDJSON DATA ('nodelocal://0/t.ndjson')`)
+	sqlDB.ExpectErr(t, `IMPORT PARQUET requires all nodes to be upgraded`,
+		`IMPORT INTO t PARQUET DATA ('nodelocal://0/t.parquet')`)
+
+	sqlDB.Exec(t, `SET CLUSTER SETTING version = $1`,
+		clusterversion.ByKey(clusterversion.ImportParquetAndNDJSON).String())
+	sqlDB.Exec(t, `IMPORT INTO t NDJSON DATA ('nodelocal://0/t.ndjson')`)
+	sqlDB.CheckQueryResults(t, `SELECT i FROM t`, [][]string{{"1"}})
+}
+
+func TestImportNDJSON(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	dir, cleanupDir := testutils.TempDir(t)
+	defer cleanupDir()
+
+	srv, db, _ := serverutils.StartServer(t, base.TestServerArgs{ExternalIODir: dir})
+	defer srv.Stopper().Stop(context.Background())
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	const simple = `{"i": 1, "s": "a", "j": {"k": [1, 2]}, "a": [1, 2]}
+
+{"I": 2, "S": "b", "extra": true}
+{"i": 3, "s": null, "j": "str", "a": []}
+`
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "simple.ndjson"), []byte(simple), 0644))
+	const corrupt = `{"i": 1, "s": "a"}
+not json
+{"i": "x"}
+{"i": 2} {"i": 3}
+{"i": 4}
+`
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "corrupt.ndjson"), []byte(corrupt), 0644))
+	const simpleURI = `nodelocal://0/simple.ndjson`
+	const corruptURI = `nodelocal://0/corrupt.ndjson`
+
+	t.Run("import-into", func(t *testing.T) {
+		sqlDB.Exec(t, `CREATE TABLE t (i INT PRIMARY KEY, s STRING, j JSONB, a INT[])`)
+		defer sqlDB.Exec(t, `DROP TABLE t`)
+		sqlDB.Exec(t, `IMPORT INTO t NDJSON DATA ($1)`, simpleURI)
+		sqlDB.CheckQueryResults(t, `SELECT * FROM t ORDER BY i`, [][]string{
+			{"1", "a", `{"k": [1, 2]}`, "{1,2}"},
+			{"2", "b", "NULL", "NULL"},
+			{"3", "NULL", `"str"`, "{}"},
+		})
+	})
+
+	t.Run("target-columns", func(t *testing.T) {
+		sqlDB.Exec(t, `CREATE TABLE t (s STRING, i INT PRIMARY KEY, z INT DEFAULT 7)`)
+		defer sqlDB.Exec(t, `DROP TABLE t`)
+		sqlDB.Exec(t, `IMPORT INTO t (i, s) NDJSON DATA ($1) WITH row_limit = '2'`, simpleURI)
+		sqlDB.CheckQueryResults(t, `SELECT i, s, z FROM t ORDER BY i`, [][]string{
+			{"1", "a", "7"}, {"2", "b", "7"},
+		})
+	})
+
+	t.Run("strict-validation", func(t *testing.T) {
+		sqlDB.Exec(t, `CREATE TABLE t (i INT PRIMARY KEY, s STRING, j JSONB, a INT[])`)
+		defer sqlDB.Exec(t, `DROP TABLE t`)
+		sqlDB.ExpectErr(t, `could not find column for key extra`,
+			`IMPORT INTO t NDJSON DATA ($1) WITH strict_validation`, simpleURI)
+	})
+
+	t.Run("max-row-size", func(t *testing.T) {
+		sqlDB.Exec(t, `CREATE TABLE t (i INT PRIMARY KEY, s STRING, j JSONB, a INT[])`)
+		defer sqlDB.Exec(t, `DROP TABLE t`)
+		sqlDB.ExpectErr(t, `line too long`,
+			`IMPORT INTO t NDJSON DATA ($1) WITH max_row_size = '10B'`, simpleURI)
+	})
+
+	t.Run("save-rejected", func(t *testing.T) {
+		sqlDB.Exec(t, `CREATE TABLE t (i INT PRIMARY KEY, s STRING)`)
+		defer sqlDB.Exec(t, `DROP TABLE t`)
+		sqlDB.ExpectErr(t, `invalid character 'o' in literal null`,
+			`IMPORT INTO t NDJSON DATA ($1)`, corruptURI)
+		sqlDB.Exec(t, `IMPORT INTO t NDJSON DATA ($1) WITH experimental_save_rejected`, corruptURI)
+		sqlDB.CheckQueryResults(t, `SELECT i, s FROM t ORDER BY i`, [][]string{
+			{"1", "a"}, {"4", "NULL"},
+		})
+		rejected, err := ioutil.ReadFile(filepath.Join(dir, "corrupt.ndjson.rejected"))
+		require.NoError(t, err)
+		require.Equal(t, "not json\n{\"i\": \"x\"}\n{\"i\": 2} {\"i\": 3}\n", string(rejected))
+	})
+}
+
 func TestImportMultiRegion(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -6816,6 +7078,8 @@ func TestDisallowsInvalidFormatOptions(t *testing.T) {
 	addOpts(mysqlOutAllowedOptions)
 	addOpts(pgDumpAllowedOptions)
 	addOpts(pgCopyAllowedOptions)
+	addOpts(parquetAllowedOptions)
+	addOpts(ndjsonAllowedOptions)
 
 	// Helper to pick num options from the set of allowed and the set
 	// of all other options.  Returns generated options plus a flag indicating
@@ -6862,6 +7126,8 @@ func TestDisallowsInvalidFormatOptions(t *testing.T) {
 		{"mysqldump", mysqlDumpAllowedOptions},
 		{"pgdump", pgDumpAllowedOptions},
 		{"pgcopy", pgCopyAllowedOptions},
+		{"parquet", parquetAllowedOptions},
+		{"ndjson", ndjsonAllowedOptions},
 	}
 
 	for _, tc := range tests {
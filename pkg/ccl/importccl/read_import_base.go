@@ -10,16 +10,12 @@ package importccl
 
 import (
 	"bytes"
-	"compress/bzip2"
-	"compress/gzip"
 	"context"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"math"
 	"net/url"
 	"runtime"
-	"strings"
 	"sync/atomic"
 	"time"
 
@@ -35,6 +31,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/row"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/compressutil"
 	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
@@ -239,8 +236,7 @@ func readInputFiles(
 			src.Reader = decompressed
 
 			var rejected chan string
-			if (format.Format == roachpb.IOFileFormat_CSV && format.SaveRejected) ||
-				(format.Format == roachpb.IOFileFormat_MysqlOutfile && format.SaveRejected) {
+			if format.SaveRejected && formatSupportsSaveRejected(format.Format) {
 				rejected = make(chan string)
 			}
 			if rejected != nil {
@@ -319,14 +315,18 @@ func rejectedFilename(datafile string) (string, error) {
 func decompressingReader(
 	in io.Reader, name string, hint roachpb.IOFileFormat_Compression,
 ) (io.ReadCloser, error) {
+	codec := compressutil.None
 	switch guessCompressionFromName(name, hint) {
 	case roachpb.IOFileFormat_Gzip:
-		return gzip.NewReader(in)
+		codec = compressutil.Gzip
 	case roachpb.IOFileFormat_Bzip:
-		return ioutil.NopCloser(bzip2.NewReader(in)), nil
-	default:
-		return ioutil.NopCloser(in), nil
+		codec = compressutil.Bzip
+	case roachpb.IOFileFormat_Snappy:
+		codec = compressutil.Snappy
+	case roachpb.IOFileFormat_Zstd:
+		codec = compressutil.Zstd
 	}
+	return compressutil.NewReader(codec, in)
 }
 
 func guessCompressionFromName(
@@ -335,11 +335,15 @@ func guessCompressionFromName(
 	if hint != roachpb.IOFileFormat_Auto {
 		return hint
 	}
-	switch {
-	case strings.HasSuffix(name, ".gz"):
+	switch compressutil.CodecFromFileName(name) {
+	case compressutil.Gzip:
 		return roachpb.IOFileFormat_Gzip
-	case strings.HasSuffix(name, ".bz2") || strings.HasSuffix(name, ".bz"):
+	case compressutil.Bzip:
 		return roachpb.IOFileFormat_Bzip
+	case compressutil.Snappy:
+		return roachpb.IOFileFormat_Snappy
+	case compressutil.Zstd:
+		return roachpb.IOFileFormat_Zstd
 	default:
 		if parsed, err := url.Parse(name); err == nil && parsed.Path != name {
 			return guessCompressionFromName(parsed.Path, hint)
@@ -384,12 +388,44 @@ func formatHasNamedColumns(format roachpb.IOFileFormat_FileFormat) bool {
 	switch format {
 	case roachpb.IOFileFormat_Avro,
 		roachpb.IOFileFormat_Mysqldump,
-		roachpb.IOFileFormat_PgDump:
+		roachpb.IOFileFormat_PgDump,
+		roachpb.IOFileFormat_Parquet,
+		roachpb.IOFileFormat_NDJSON:
 		return true
 	}
 	return false
 }
 
+// formatSupportsSaveRejected returns true if the rows of the input files that
+// fail to parse can be written to a side file instead of failing the import.
+func formatSupportsSaveRejected(format roachpb.IOFileFormat_FileFormat) bool {
+	switch format {
+	case roachpb.IOFileFormat_CSV,
+		roachpb.IOFileFormat_MysqlOutfile,
+		roachpb.IOFileFormat_Parquet,
+		roachpb.IOFileFormat_NDJSON:
+		return true
+	}
+	return false
+}
+
+// targetColumnIdxByName returns a mapping from the names of the columns being
+// imported to their ordinal in the datums of the DatumRowConverter. It is used
+// by the formats whose records name their fields.
+func targetColumnIdxByName(importCtx *parallelImportContext) map[string]int {
+	idxByName := make(map[string]int)
+	if len(importCtx.targetCols) > 0 {
+		for idx, name := range importCtx.targetCols {
+			idxByName[string(name)] = idx
+		}
+		return idxByName
+	}
+	for idx, col := range importCtx.tableDesc.VisibleColumns() {
+		idxByName[col.GetName()] = idx
+	}
+	return idxByName
+}
+
 func isMultiTableFormat(format roachpb.IOFileFormat_FileFormat) bool {
 	switch format {
 	case roachpb.IOFileFormat_Mysqldump,
@@ -0,0 +1,283 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/lexbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/errors"
+)
+
+// ndjsonValueToDatum converts a value decoded from a JSON object (with numbers
+// decoded as json.Number) to a datum of the target type.
+//
+// Any JSON value can be imported into a JSONB column. Otherwise, JSON scalars
+// are parsed as the string representation of the target type, and JSON arrays
+// are only allowed for array columns.
+func ndjsonValueToDatum(
+	v interface{}, targetT *types.T, evalCtx *tree.EvalContext,
+) (tree.Datum, error) {
+	if v == nil {
+		// Let the target table schema verify whether nulls are allowed.
+		return tree.DNull, nil
+	}
+	if targetT.Family() == types.JsonFamily {
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		return tree.ParseDJSON(string(encoded))
+	}
+
+	switch t := v.(type) {
+	case bool:
+		return rowenc.ParseDatumStringAs(targetT, strconv.FormatBool(t), evalCtx)
+	case json.Number:
+		return rowenc.ParseDatumStringAs(targetT, t.String(), evalCtx)
+	case string:
+		return rowenc.ParseDatumStringAs(targetT, t, evalCtx)
+	case []interface{}:
+		if targetT.Family() != types.ArrayFamily {
+			return nil, errors.Errorf("cannot convert JSON array to %s", targetT.SQLString())
+		}
+		arr := tree.NewDArray(targetT.ArrayContents())
+		for _, elt := range t {
+			eltDatum, err := ndjsonValueToDatum(elt, targetT.ArrayContents(), evalCtx)
+			if err == nil {
+				err = arr.Append(eltDatum)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		return arr, nil
+	default:
+		return nil, errors.Errorf("cannot convert JSON value %v to %s", v, targetT.SQLString())
+	}
+}
+
+// ndjsonConsumer implements importRowConsumer interface.
+type ndjsonConsumer struct {
+	fieldNameToIdx map[string]int
+	strict         bool
+}
+
+var _ importRowConsumer = &ndjsonConsumer{}
+
+// parseObject decodes a line of the input, which must contain exactly one
+// JSON object.
+func parseObject(line string) (map[string]interface{}, error) {
+	dec := json.NewDecoder(strings.NewReader(line))
+	dec.UseNumber()
+	var record map[string]interface{}
+	if err := dec.Decode(&record); err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, errors.New("expected a JSON object")
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after JSON object")
+	}
+	return record, nil
+}
+
+// Converts a line of the input to datums as expected by DatumRowConverter.
+func (n *ndjsonConsumer) convertLine(line string, conv *row.DatumRowConverter) error {
+	record, err := parseObject(line)
+	if err != nil {
+		return err
+	}
+
+	for f, v := range record {
+		field := lexbase.NormalizeName(f)
+		idx, ok := n.fieldNameToIdx[field]
+		if !ok {
+			if n.strict {
+				return errors.Errorf("could not find column for key %s", field)
+			}
+			continue
+		}
+
+		datum, err := ndjsonValueToDatum(v, conv.VisibleColTypes[idx], conv.EvalCtx)
+		if err != nil {
+			col := conv.VisibleCols[idx]
+			return errors.Wrapf(err, "encountered error when attempting to parse %q as %s",
+				col.GetName(), col.GetType().SQLString())
+		}
+		conv.Datums[idx] = datum
+	}
+	return nil
+}
+
+// FillDatums implements importRowConsumer interface.
+func (n *ndjsonConsumer) FillDatums(
+	native interface{}, rowNum int64, conv *row.DatumRowConverter,
+) error {
+	line := native.(string)
+	for i := range conv.VisibleCols {
+		conv.Datums[i] = nil
+	}
+	if err := n.convertLine(line, conv); err != nil {
+		return newImportRowError(err, line, rowNum)
+	}
+
+	// Set any nil datums to DNull (in case the object didn't have the key at
+	// all).
+	for i := range conv.VisibleCols {
+		if conv.Datums[i] == nil {
+			if n.strict {
+				return newImportRowError(
+					errors.Errorf("key %s was not set in the JSON object", conv.VisibleCols[i].GetName()),
+					line, rowNum)
+			}
+			conv.Datums[i] = tree.DNull
+		}
+	}
+	return nil
+}
+
+// ndjsonProducer implements importRowProducer interface. Each non-blank line
+// of the input is a row.
+type ndjsonProducer struct {
+	input   *fileReader
+	scanner *bufio.Scanner
+	line    string
+	err     error
+}
+
+var _ importRowProducer = &ndjsonProducer{}
+
+// Scan implements importRowProducer interface.
+func (p *ndjsonProducer) Scan() bool {
+	for p.scanner.Scan() {
+		if len(bytes.TrimSpace(p.scanner.Bytes())) == 0 {
+			continue
+		}
+		p.line = p.scanner.Text()
+		return true
+	}
+	if err := p.scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			err = wrapWithLineTooLongHint(errors.New("line too long"))
+		}
+		p.err = err
+	}
+	return false
+}
+
+// Err implements importRowProducer interface.
+func (p *ndjsonProducer) Err() error {
+	return p.err
+}
+
+// Skip implements importRowProducer interface.
+func (p *ndjsonProducer) Skip() error {
+	return nil
+}
+
+// Row implements importRowProducer interface.
+func (p *ndjsonProducer) Row() (interface{}, error) {
+	return p.line, nil
+}
+
+// Progress implements importRowProducer interface.
+func (p *ndjsonProducer) Progress() float32 {
+	return p.input.ReadFraction()
+}
+
+type ndjsonInputReader struct {
+	importCtx *parallelImportContext
+	opts      roachpb.NDJSONOptions
+}
+
+var _ inputConverter = &ndjsonInputReader{}
+
+func newNDJSONInputReader(
+	semaCtx *tree.SemaContext,
+	kvCh chan row.KVBatch,
+	opts roachpb.NDJSONOptions,
+	walltime int64,
+	parallelism int,
+	tableDesc catalog.TableDescriptor,
+	targetCols tree.NameList,
+	evalCtx *tree.EvalContext,
+	seqChunkProvider *row.SeqChunkProvider,
+) *ndjsonInputReader {
+	return &ndjsonInputReader{
+		importCtx: &parallelImportContext{
+			semaCtx:          semaCtx,
+			walltime:         walltime,
+			numWorkers:       parallelism,
+			evalCtx:          evalCtx,
+			tableDesc:        tableDesc,
+			targetCols:       targetCols,
+			kvCh:             kvCh,
+			seqChunkProvider: seqChunkProvider,
+		},
+		opts: opts,
+	}
+}
+
+func (n *ndjsonInputReader) start(group ctxgroup.Group) {}
+
+func (n *ndjsonInputReader) readFiles(
+	ctx context.Context,
+	dataFiles map[int32]string,
+	resumePos map[int32]int64,
+	format roachpb.IOFileFormat,
+	makeExternalStorage cloud.ExternalStorageFactory,
+	user security.SQLUsername,
+) error {
+	return readInputFiles(ctx, dataFiles, resumePos, format, n.readFile, makeExternalStorage, user)
+}
+
+func (n *ndjsonInputReader) readFile(
+	ctx context.Context, input *fileReader, inputIdx int32, resumePos int64, rejected chan string,
+) error {
+	maxRowSize := int(n.opts.MaxRowSize)
+	if maxRowSize <= 0 {
+		maxRowSize = defaultScanBuffer
+	}
+	s := bufio.NewScanner(input)
+	s.Buffer(nil, maxRowSize)
+
+	producer := &ndjsonProducer{
+		input:   input,
+		scanner: s,
+	}
+	consumer := &ndjsonConsumer{
+		fieldNameToIdx: targetColumnIdxByName(n.importCtx),
+		strict:         n.opts.StrictMode,
+	}
+
+	fileCtx := &importFileContext{
+		source:   inputIdx,
+		skip:     resumePos,
+		rejected: rejected,
+		rowLimit: n.opts.RowLimit,
+	}
+	return runParallelImport(ctx, n.importCtx, fileCtx, producer, consumer)
+}
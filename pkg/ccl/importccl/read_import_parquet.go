@@ -0,0 +1,669 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cockroachdb/apd/v3"
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/lexbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil/pgdate"
+	"github.com/cockroachdb/errors"
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+)
+
+// parquetLogicalType identifies the logical types of parquet columns whose
+// values must be decoded before they can be converted to datums.
+type parquetLogicalType int
+
+const (
+	parquetNoLogicalType parquetLogicalType = iota
+	// parquetDate values are INT32s holding the number of days since the Unix
+	// epoch.
+	parquetDate
+	// parquetTimestamp{Millis,Micros,Nanos} values are INT64s holding the time
+	// since the Unix epoch in the respective unit.
+	parquetTimestampMillis
+	parquetTimestampMicros
+	parquetTimestampNanos
+	// parquetDecimal values are the unscaled value of a decimal, either as an
+	// INT32 or INT64, or as a big-endian two's complement (fixed length) byte
+	// array.
+	parquetDecimal
+)
+
+// parquetColumnType describes how the values of a parquet column are decoded.
+type parquetColumnType struct {
+	logical parquetLogicalType
+	// scale is the scale of a DECIMAL column.
+	scale int32
+	// element is the type of the elements of a LIST column.
+	element *parquetColumnType
+}
+
+// makeParquetColumnType returns the type of the given column, as declared by
+// its logical type or, for files written by older writers, by its converted
+// type.
+func makeParquetColumnType(col *parquetschema.ColumnDefinition) *parquetColumnType {
+	typ := &parquetColumnType{}
+	if lt := col.SchemaElement.LogicalType; lt != nil {
+		switch {
+		case lt.DATE != nil:
+			typ.logical = parquetDate
+		case lt.TIMESTAMP != nil && lt.TIMESTAMP.Unit != nil:
+			switch unit := lt.TIMESTAMP.Unit; {
+			case unit.MILLIS != nil:
+				typ.logical = parquetTimestampMillis
+			case unit.MICROS != nil:
+				typ.logical = parquetTimestampMicros
+			case unit.NANOS != nil:
+				typ.logical = parquetTimestampNanos
+			}
+		case lt.DECIMAL != nil:
+			typ.logical = parquetDecimal
+			typ.scale = lt.DECIMAL.Scale
+		}
+	}
+	if ct := col.SchemaElement.ConvertedType; typ.logical == parquetNoLogicalType && ct != nil {
+		switch *ct {
+		case parquet.ConvertedType_DATE:
+			typ.logical = parquetDate
+		case parquet.ConvertedType_TIMESTAMP_MILLIS:
+			typ.logical = parquetTimestampMillis
+		case parquet.ConvertedType_TIMESTAMP_MICROS:
+			typ.logical = parquetTimestampMicros
+		case parquet.ConvertedType_DECIMAL:
+			typ.logical = parquetDecimal
+			typ.scale = col.SchemaElement.GetScale()
+		}
+	}
+	// The repeated group of a list has a single field, the element.
+	if len(col.Children) == 1 && len(col.Children[0].Children) == 1 {
+		typ.element = makeParquetColumnType(col.Children[0].Children[0])
+	}
+	return typ
+}
+
+// parquetTimeToDatum converts a time decoded from a parquet value to a
+// TIMESTAMP if that is the target type, and to a TIMESTAMPTZ otherwise.
+func parquetTimeToDatum(t time.Time, targetT *types.T) (tree.Datum, error) {
+	switch targetT.Family() {
+	case types.TimestampFamily:
+		d, err := tree.MakeDTimestamp(t, tree.TimeFamilyPrecisionToRoundDuration(targetT.Precision()))
+		if err != nil {
+			return nil, err
+		}
+		return d, nil
+	case types.TimestampTZFamily:
+		d, err := tree.MakeDTimestampTZ(t, tree.TimeFamilyPrecisionToRoundDuration(targetT.Precision()))
+		if err != nil {
+			return nil, err
+		}
+		return d, nil
+	default:
+		d, err := tree.MakeDTimestampTZ(t, time.Microsecond)
+		if err != nil {
+			return nil, err
+		}
+		return d, nil
+	}
+}
+
+// decodeParquetLogicalValue decodes the value of a column with a logical type
+// to a datum of the corresponding SQL type. See parquetTimeToDatum for the
+// type of timestamps.
+func decodeParquetLogicalValue(
+	v interface{}, typ *parquetColumnType, targetT *types.T,
+) (tree.Datum, error) {
+	switch typ.logical {
+	case parquetDate:
+		if days, ok := v.(int32); ok {
+			d, err := pgdate.MakeDateFromUnixEpoch(int64(days))
+			if err != nil {
+				return nil, err
+			}
+			return tree.NewDDate(d), nil
+		}
+	case parquetTimestampMillis, parquetTimestampMicros, parquetTimestampNanos:
+		if ts, ok := v.(int64); ok {
+			var t time.Time
+			switch typ.logical {
+			case parquetTimestampMillis:
+				t = timeutil.Unix(ts/1e3, ts%1e3*int64(time.Millisecond))
+			case parquetTimestampMicros:
+				t = timeutil.Unix(ts/1e6, ts%1e6*int64(time.Microsecond))
+			default:
+				t = timeutil.Unix(0, ts)
+			}
+			return parquetTimeToDatum(t, targetT)
+		}
+	case parquetDecimal:
+		var coeff apd.BigInt
+		switch t := v.(type) {
+		case int32:
+			coeff.SetInt64(int64(t))
+		case int64:
+			coeff.SetInt64(t)
+		case []byte:
+			var unscaled big.Int
+			unscaled.SetBytes(t)
+			if len(t) > 0 && t[0]&0x80 != 0 {
+				// Negative two's complement value.
+				unscaled.Sub(&unscaled, new(big.Int).Lsh(big.NewInt(1), uint(8*len(t))))
+			}
+			coeff.SetMathBigInt(&unscaled)
+		default:
+			return nil, errors.Errorf("unexpected parquet value of type %T for a DECIMAL column", v)
+		}
+		return &tree.DDecimal{Decimal: *apd.NewWithBigInt(&coeff, -typ.scale)}, nil
+	}
+	return nil, errors.Errorf("unexpected parquet value of type %T for a DATE or TIMESTAMP column", v)
+}
+
+// parquetDatumAs converts a datum decoded from a parquet value to the target
+// type. Datums of another type family are converted through their string
+// representation.
+func parquetDatumAs(d tree.Datum, targetT *types.T, evalCtx *tree.EvalContext) (tree.Datum, error) {
+	if d.ResolvedType().Family() == targetT.Family() {
+		return d, nil
+	}
+	return rowenc.ParseDatumStringAs(targetT, tree.AsStringWithFlags(d, tree.FmtBareStrings), evalCtx)
+}
+
+// parquetValueToDatum converts a value as returned by the parquet reader for a
+// column of the given type to a datum of the target type.
+//
+// Values of columns with a DATE, TIMESTAMP or DECIMAL logical type, as well as
+// INT96 timestamps, are decoded to a datum of the corresponding SQL type first.
+// Booleans, integers and floats are converted directly when the target type
+// belongs to the same family, and are otherwise parsed from their string
+// representation. Byte arrays (which is how strings are stored in parquet) are
+// parsed as the string representation of the target type, unless the target
+// is BYTES. Lists, which the reader returns as a map with a repeated "list"
+// group, can only be imported into array columns.
+func parquetValueToDatum(
+	v interface{}, typ *parquetColumnType, targetT *types.T, evalCtx *tree.EvalContext,
+) (tree.Datum, error) {
+	if v != nil && typ.logical != parquetNoLogicalType {
+		d, err := decodeParquetLogicalValue(v, typ, targetT)
+		if err != nil {
+			return nil, err
+		}
+		return parquetDatumAs(d, targetT, evalCtx)
+	}
+
+	switch t := v.(type) {
+	case nil:
+		// Let the target table schema verify whether nulls are allowed.
+		return tree.DNull, nil
+	case bool:
+		if targetT.Family() == types.BoolFamily {
+			return tree.MakeDBool(tree.DBool(t)), nil
+		}
+		return rowenc.ParseDatumStringAs(targetT, strconv.FormatBool(t), evalCtx)
+	case int32:
+		return parquetValueToDatum(int64(t), typ, targetT, evalCtx)
+	case int64:
+		if targetT.Family() == types.IntFamily {
+			return tree.NewDInt(tree.DInt(t)), nil
+		}
+		return rowenc.ParseDatumStringAs(targetT, strconv.FormatInt(t, 10), evalCtx)
+	case float32:
+		if targetT.Family() == types.FloatFamily {
+			return tree.NewDFloat(tree.DFloat(t)), nil
+		}
+		return rowenc.ParseDatumStringAs(targetT, strconv.FormatFloat(float64(t), 'g', -1, 32), evalCtx)
+	case float64:
+		if targetT.Family() == types.FloatFamily {
+			return tree.NewDFloat(tree.DFloat(t)), nil
+		}
+		return rowenc.ParseDatumStringAs(targetT, strconv.FormatFloat(t, 'g', -1, 64), evalCtx)
+	case []byte:
+		if targetT.Family() == types.BytesFamily {
+			return tree.NewDBytes(tree.DBytes(t)), nil
+		}
+		return rowenc.ParseDatumStringAs(targetT, string(t), evalCtx)
+	case [12]byte:
+		// INT96 is the deprecated encoding of timestamps with nanosecond
+		// precision.
+		d, err := parquetTimeToDatum(goparquet.Int96ToTime(t), targetT)
+		if err != nil {
+			return nil, err
+		}
+		return parquetDatumAs(d, targetT, evalCtx)
+	case map[string]interface{}:
+		list, ok := t["list"].([]map[string]interface{})
+		if !ok {
+			return nil, errors.Errorf("cannot convert parquet group to %s", targetT.SQLString())
+		}
+		if targetT.Family() != types.ArrayFamily {
+			return nil, errors.Errorf("cannot convert parquet list to %s", targetT.SQLString())
+		}
+		arr := tree.NewDArray(targetT.ArrayContents())
+		// The reader returns an empty list as a single group without an
+		// element.
+		if len(list) == 1 && len(list[0]) == 0 {
+			return arr, nil
+		}
+		eltTyp := typ.element
+		if eltTyp == nil {
+			eltTyp = &parquetColumnType{}
+		}
+		for _, group := range list {
+			// The repeated group of a list has a single field, the element.
+			var elt interface{}
+			for _, v := range group {
+				elt = v
+			}
+			eltDatum, err := parquetValueToDatum(elt, eltTyp, targetT.ArrayContents(), evalCtx)
+			if err == nil {
+				err = arr.Append(eltDatum)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		return arr, nil
+	default:
+		return nil, errors.Errorf("cannot handle type %T when converting to %s", v, targetT.SQLString())
+	}
+}
+
+// parquetValueToJSON converts a value as returned by the parquet reader for a
+// column of the given type to a value that encoding/json marshals the way the
+// NDJSON import expects the value of a column of the target type: values with
+// a logical type, timestamps and byte arrays are strings holding their SQL
+// representation, and lists are arrays.
+func parquetValueToJSON(v interface{}, typ *parquetColumnType) interface{} {
+	if v != nil && typ.logical != parquetNoLogicalType {
+		d, err := decodeParquetLogicalValue(v, typ, types.TimestampTZ)
+		if err != nil {
+			// Keep the raw value, which is what failed to import.
+			return v
+		}
+		return tree.AsStringWithFlags(d, tree.FmtBareStrings)
+	}
+
+	switch t := v.(type) {
+	case float32:
+		return parquetFloatToJSON(float64(t), 32)
+	case float64:
+		return parquetFloatToJSON(t, 64)
+	case []byte:
+		if utf8.Valid(t) {
+			return string(t)
+		}
+		return tree.AsStringWithFlags(tree.NewDBytes(tree.DBytes(t)), tree.FmtBareStrings)
+	case [12]byte:
+		d, err := tree.MakeDTimestampTZ(goparquet.Int96ToTime(t), time.Microsecond)
+		if err != nil {
+			return v
+		}
+		return tree.AsStringWithFlags(d, tree.FmtBareStrings)
+	case map[string]interface{}:
+		list, ok := t["list"].([]map[string]interface{})
+		if !ok {
+			return v
+		}
+		eltTyp := typ.element
+		if eltTyp == nil {
+			eltTyp = &parquetColumnType{}
+		}
+		arr := make([]interface{}, 0, len(list))
+		if len(list) == 1 && len(list[0]) == 0 {
+			return arr
+		}
+		for _, group := range list {
+			var elt interface{}
+			for _, v := range group {
+				elt = v
+			}
+			arr = append(arr, parquetValueToJSON(elt, eltTyp))
+		}
+		return arr
+	default:
+		return v
+	}
+}
+
+// parquetFloatToJSON returns a float as a JSON number, or as a string if JSON
+// cannot represent it.
+func parquetFloatToJSON(f float64, bitSize int) interface{} {
+	s := strconv.FormatFloat(f, 'g', -1, bitSize)
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return s
+	}
+	return json.Number(s)
+}
+
+// parquetConsumer implements importRowConsumer interface.
+type parquetConsumer struct {
+	fieldNameToIdx map[string]int
+	// columns maps the fields of the parquet rows to the type of their column.
+	columns map[string]*parquetColumnType
+}
+
+var _ importRowConsumer = &parquetConsumer{}
+
+// Converts a parquet row to datums as expected by DatumRowConverter.
+func (p *parquetConsumer) convertRow(
+	record map[string]interface{}, conv *row.DatumRowConverter,
+) error {
+	for f, v := range record {
+		idx, ok := p.fieldNameToIdx[lexbase.NormalizeName(f)]
+		if !ok {
+			// Unknown columns were rejected up front in strict mode.
+			continue
+		}
+		datum, err := parquetValueToDatum(v, p.columns[f], conv.VisibleColTypes[idx], conv.EvalCtx)
+		if err != nil {
+			col := conv.VisibleCols[idx]
+			return errors.Wrapf(err, "encountered error when attempting to parse %q as %s",
+				col.GetName(), col.GetType().SQLString())
+		}
+		conv.Datums[idx] = datum
+	}
+	return nil
+}
+
+// encodeRecord encodes a parquet row as a JSON object, which is how rejected
+// rows are saved so that they can be fixed and imported again as NDJSON.
+func (p *parquetConsumer) encodeRecord(record map[string]interface{}) (string, error) {
+	obj := make(map[string]interface{}, len(record))
+	for f, v := range record {
+		obj[f] = parquetValueToJSON(v, p.columns[f])
+	}
+	encoded, err := json.Marshal(obj)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// FillDatums implements importRowConsumer interface.
+func (p *parquetConsumer) FillDatums(
+	native interface{}, rowNum int64, conv *row.DatumRowConverter,
+) error {
+	record := native.(map[string]interface{})
+	for i := range conv.VisibleCols {
+		conv.Datums[i] = nil
+	}
+	if err := p.convertRow(record, conv); err != nil {
+		encoded, encodeErr := p.encodeRecord(record)
+		if encodeErr != nil {
+			return errors.CombineErrors(err, encodeErr)
+		}
+		return newImportRowError(err, encoded, rowNum)
+	}
+
+	// The parquet reader omits null values from the row, so any column that
+	// wasn't set is null.
+	for i := range conv.VisibleCols {
+		if conv.Datums[i] == nil {
+			conv.Datums[i] = tree.DNull
+		}
+	}
+	return nil
+}
+
+// parquetProducer implements importRowProducer interface.
+type parquetProducer struct {
+	reader   *goparquet.FileReader
+	numRows  int64
+	rowsRead int64
+	row      map[string]interface{}
+	err      error
+}
+
+var _ importRowProducer = &parquetProducer{}
+
+// Scan implements importRowProducer interface.
+func (p *parquetProducer) Scan() bool {
+	p.row, p.err = p.reader.NextRow()
+	if p.err == io.EOF {
+		p.err = nil
+		return false
+	}
+	if p.err != nil {
+		return false
+	}
+	p.rowsRead++
+	return true
+}
+
+// Err implements importRowProducer interface.
+func (p *parquetProducer) Err() error {
+	return p.err
+}
+
+// Skip implements importRowProducer interface.
+func (p *parquetProducer) Skip() error {
+	p.row = nil
+	return nil
+}
+
+// Row implements importRowProducer interface.
+func (p *parquetProducer) Row() (interface{}, error) {
+	res := p.row
+	p.row = nil
+	return res, nil
+}
+
+// Progress implements importRowProducer interface.
+func (p *parquetProducer) Progress() float32 {
+	if p.numRows == 0 {
+		return 0
+	}
+	return float32(p.rowsRead) / float32(p.numRows)
+}
+
+// checkParquetSchema verifies, for strict mode, that the columns of the
+// parquet file and the columns being imported map one-to-one.
+func checkParquetSchema(reader *goparquet.FileReader, fieldNameToIdx map[string]int) error {
+	found := make(map[string]struct{}, len(fieldNameToIdx))
+	for _, col := range reader.GetSchemaDefinition().RootColumn.Children {
+		field := lexbase.NormalizeName(col.SchemaElement.Name)
+		if _, ok := fieldNameToIdx[field]; !ok {
+			return errors.Errorf("could not find column for parquet column %s", field)
+		}
+		found[field] = struct{}{}
+	}
+	for name := range fieldNameToIdx {
+		if _, ok := found[name]; !ok {
+			return errors.Errorf("column %s is not present in the parquet file", name)
+		}
+	}
+	return nil
+}
+
+type parquetInputReader struct {
+	importCtx *parallelImportContext
+	opts      roachpb.ParquetOptions
+}
+
+var _ inputConverter = &parquetInputReader{}
+
+func newParquetInputReader(
+	semaCtx *tree.SemaContext,
+	kvCh chan row.KVBatch,
+	opts roachpb.ParquetOptions,
+	walltime int64,
+	parallelism int,
+	tableDesc catalog.TableDescriptor,
+	targetCols tree.NameList,
+	evalCtx *tree.EvalContext,
+	seqChunkProvider *row.SeqChunkProvider,
+) *parquetInputReader {
+	return &parquetInputReader{
+		importCtx: &parallelImportContext{
+			semaCtx:          semaCtx,
+			walltime:         walltime,
+			numWorkers:       parallelism,
+			evalCtx:          evalCtx,
+			tableDesc:        tableDesc,
+			targetCols:       targetCols,
+			kvCh:             kvCh,
+			seqChunkProvider: seqChunkProvider,
+		},
+		opts: opts,
+	}
+}
+
+func (p *parquetInputReader) start(group ctxgroup.Group) {}
+
+func (p *parquetInputReader) readFiles(
+	ctx context.Context,
+	dataFiles map[int32]string,
+	resumePos map[int32]int64,
+	format roachpb.IOFileFormat,
+	makeExternalStorage cloud.ExternalStorageFactory,
+	user security.SQLUsername,
+) error {
+	return readInputFiles(ctx, dataFiles, resumePos, format, p.readFile, makeExternalStorage, user)
+}
+
+// parquetBufferChunkSize is the minimum amount of free space in the buffer
+// holding a parquet file when reading from the input.
+const parquetBufferChunkSize = 64 << 10
+
+// parquetMaxFileSize is the size of the largest (uncompressed) parquet file
+// which can be imported, since every file is buffered in memory.
+var parquetMaxFileSize = settings.RegisterByteSizeSetting(
+	settings.TenantWritable,
+	"bulkio.import.parquet.max_file_size",
+	"the maximum size of a parquet file that IMPORT can read; each file is held in memory while it is imported",
+	256<<20,
+)
+
+// errParquetFileTooLarge returns the error for a parquet file larger than the
+// given maximum size.
+func errParquetFileTooLarge(maxSize int64) error {
+	return pgerror.Newf(pgcode.ProgramLimitExceeded,
+		"parquet file is larger than %s, the maximum size which can be imported; "+
+			"split it into smaller files or raise the %s cluster setting",
+		humanizeutil.IBytes(maxSize), parquetMaxFileSize.Key())
+}
+
+// bufferParquetFile reads the whole input into memory, which is accounted for
+// on the given account as the buffer grows. The footer of a parquet file holds
+// its metadata, so the reader needs to seek within the file, which the input
+// does not support. Inputs larger than maxSize are rejected.
+func bufferParquetFile(
+	ctx context.Context, input *fileReader, acc *mon.BoundAccount, maxSize int64,
+) ([]byte, error) {
+	// The size of the file is known unless the storage doesn't report it, but
+	// it is only a hint for compressed files.
+	initialSize := input.total
+	if initialSize > maxSize {
+		initialSize = maxSize
+	}
+	initialCap := initialSize + parquetBufferChunkSize
+	if err := acc.Grow(ctx, initialCap); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 0, initialCap)
+	for {
+		if int64(len(buf)) > maxSize {
+			return nil, errParquetFileTooLarge(maxSize)
+		}
+		if cap(buf)-len(buf) < parquetBufferChunkSize {
+			newCap := 2*cap(buf) + parquetBufferChunkSize
+			if int64(newCap) > maxSize+parquetBufferChunkSize {
+				// Leave room to read past the maximum size, so that a file which
+				// is too large is detected.
+				newCap = int(maxSize) + 2*parquetBufferChunkSize
+			}
+			// Both buffers are live while the data is copied.
+			if err := acc.Grow(ctx, int64(newCap)); err != nil {
+				return nil, err
+			}
+			newBuf := make([]byte, len(buf), newCap)
+			copy(newBuf, buf)
+			acc.Shrink(ctx, int64(cap(buf)))
+			buf = newBuf
+		}
+		n, err := input.Read(buf[len(buf):cap(buf)])
+		buf = buf[:len(buf)+n]
+		if err == io.EOF {
+			return buf, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parquetInputReader) readFile(
+	ctx context.Context, input *fileReader, inputIdx int32, resumePos int64, rejected chan string,
+) error {
+	acc := p.importCtx.evalCtx.Mon.MakeBoundAccount()
+	defer acc.Close(ctx)
+	maxSize := parquetMaxFileSize.Get(&p.importCtx.evalCtx.Settings.SV)
+	data, err := bufferParquetFile(ctx, input, &acc, maxSize)
+	if err != nil {
+		return errors.Wrap(err, "buffering parquet file")
+	}
+	reader, err := goparquet.NewFileReader(bytes.NewReader(data))
+	if err != nil {
+		return errors.Wrap(err, "reading parquet file")
+	}
+
+	fieldNameToIdx := targetColumnIdxByName(p.importCtx)
+	if p.opts.StrictMode {
+		if err := checkParquetSchema(reader, fieldNameToIdx); err != nil {
+			return err
+		}
+	}
+	columns := make(map[string]*parquetColumnType)
+	for _, col := range reader.GetSchemaDefinition().RootColumn.Children {
+		columns[col.SchemaElement.Name] = makeParquetColumnType(col)
+	}
+
+	producer := &parquetProducer{
+		reader:  reader,
+		numRows: reader.NumRows(),
+	}
+	consumer := &parquetConsumer{
+		fieldNameToIdx: fieldNameToIdx,
+		columns:        columns,
+	}
+
+	fileCtx := &importFileContext{
+		source:   inputIdx,
+		skip:     resumePos,
+		rejected: rejected,
+		rowLimit: p.opts.RowLimit,
+	}
+	return runParallelImport(ctx, p.importCtx, fileCtx, producer, consumer)
+}
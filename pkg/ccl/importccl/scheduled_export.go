@@ -0,0 +1,448 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/scheduledjobs"
+	"github.com/cockroachdb/cockroach/pkg/scheduledjobs/schedulebase"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+	"github.com/cockroachdb/errors"
+	pbtypes "github.com/gogo/protobuf/types"
+)
+
+const scheduleExportOp = "CREATE SCHEDULE FOR EXPORT"
+
+// scheduledExportExecutor executes export schedules.
+//
+// EXPORT does not have a job of its own, so each execution of an export
+// schedule creates a SCHEDULED EXPORT job which runs the EXPORT statement in
+// its own transaction. The scheduler only creates the job, which lets the
+// on_previous_running schedule option take effect and reports failures to
+// the schedule through NotifyJobTermination.
+type scheduledExportExecutor struct {
+	metrics exportScheduleMetrics
+}
+
+type exportScheduleMetrics struct {
+	*jobs.ExecutorMetrics
+}
+
+var _ metric.Struct = &exportScheduleMetrics{}
+
+// MetricStruct implements metric.Struct interface
+func (m *exportScheduleMetrics) MetricStruct() {}
+
+var _ jobs.ScheduledJobExecutor = &scheduledExportExecutor{}
+
+// ExecuteJob implements jobs.ScheduledJobExecutor interface.
+func (e *scheduledExportExecutor) ExecuteJob(
+	ctx context.Context,
+	cfg *scheduledjobs.JobExecutionConfig,
+	env scheduledjobs.JobSchedulerEnv,
+	sj *jobs.ScheduledJob,
+	txn *kv.Txn,
+) error {
+	if err := e.createExportJob(ctx, cfg, sj, txn); err != nil {
+		e.metrics.NumFailed.Inc(1)
+		return err
+	}
+	e.metrics.NumStarted.Inc(1)
+	return nil
+}
+
+func (e *scheduledExportExecutor) createExportJob(
+	ctx context.Context, cfg *scheduledjobs.JobExecutionConfig, sj *jobs.ScheduledJob, txn *kv.Txn,
+) error {
+	args, exportStmt, err := extractExportStatement(sj)
+	if err != nil {
+		return err
+	}
+
+	p, cleanup := cfg.PlanHookMaker("invoke-scheduled-export", txn, sj.Owner())
+	defer cleanup()
+	registry := p.(sql.PlanHookState).ExecCfg().JobRegistry
+
+	redacted := tree.AsString(redactExport(exportStmt))
+	record := jobs.Record{
+		Description: fmt.Sprintf("scheduled export %d: %s", sj.ScheduleID(), redacted),
+		Statements:  []string{redacted},
+		Username:    sj.Owner(),
+		Details: jobspb.ScheduledExportDetails{
+			Statement: args.ExportStatement,
+			Database:  args.Database,
+		},
+		Progress: jobspb.ScheduledExportProgress{},
+		CreatedBy: &jobs.CreatedByInfo{
+			Name: jobs.CreatedByScheduledJobs,
+			ID:   sj.ScheduleID(),
+		},
+	}
+	jobID := registry.MakeJobID()
+	if _, err := registry.CreateAdoptableJobWithTxn(ctx, record, jobID, txn); err != nil {
+		return errors.Wrapf(err, "scheduled export %d", sj.ScheduleID())
+	}
+	log.Infof(ctx, "scheduled export %d started job %d", sj.ScheduleID(), jobID)
+	return nil
+}
+
+// NotifyJobTermination implements jobs.ScheduledJobExecutor interface.
+func (e *scheduledExportExecutor) NotifyJobTermination(
+	ctx context.Context,
+	jobID jobspb.JobID,
+	jobStatus jobs.Status,
+	details jobspb.Details,
+	env scheduledjobs.JobSchedulerEnv,
+	schedule *jobs.ScheduledJob,
+	ex sqlutil.InternalExecutor,
+	txn *kv.Txn,
+) error {
+	if jobStatus == jobs.StatusSucceeded {
+		e.metrics.NumSucceeded.Inc(1)
+		return nil
+	}
+	e.metrics.NumFailed.Inc(1)
+	err := errors.Errorf(
+		"job %d scheduled by export schedule %d failed with status %s",
+		jobID, schedule.ScheduleID(), jobStatus)
+	log.Errorf(ctx, "export error: %v", err)
+	jobs.DefaultHandleFailedRun(schedule, "export job %d failed with err=%v", jobID, err)
+	return nil
+}
+
+// Metrics implements ScheduledJobExecutor interface
+func (e *scheduledExportExecutor) Metrics() metric.Struct {
+	return &e.metrics
+}
+
+// GetCreateScheduleStatement implements jobs.ScheduledJobExecutor interface.
+func (e *scheduledExportExecutor) GetCreateScheduleStatement(
+	ctx context.Context,
+	env scheduledjobs.JobSchedulerEnv,
+	txn *kv.Txn,
+	sj *jobs.ScheduledJob,
+	ex sqlutil.InternalExecutor,
+) (string, error) {
+	_, exportStmt, err := extractExportStatement(sj)
+	if err != nil {
+		return "", err
+	}
+
+	firstRunTime := sj.ScheduledRunTime()
+	if firstRunTime.IsZero() {
+		firstRunTime = env.Now()
+	}
+	firstRun, err := tree.MakeDTimestampTZ(firstRunTime, time.Microsecond)
+	if err != nil {
+		return "", err
+	}
+
+	scheduleOptions, err := schedulebase.MakeScheduleOptions(firstRun, sj.ScheduleDetails())
+	if err != nil {
+		return "", err
+	}
+
+	node := &tree.ScheduledExport{
+		ScheduleLabelSpec: tree.ScheduleLabelSpec{
+			IfNotExists: false, Label: tree.NewDString(sj.ScheduleLabel())},
+		Recurrence:      tree.NewDString(sj.ScheduleExpr()),
+		Export:          redactExport(exportStmt),
+		ScheduleOptions: scheduleOptions,
+	}
+	return tree.AsString(node), nil
+}
+
+// redactExport returns a copy of the export statement with its destination
+// sanitized. If the destination cannot be sanitized, it is omitted.
+func redactExport(export *tree.Export) *tree.Export {
+	redacted := *export
+	dest, ok := export.File.(*tree.StrVal)
+	if !ok {
+		return &redacted
+	}
+	sanitized, err := cloud.SanitizeExternalStorageURI(dest.RawString(), nil /* extraParams */)
+	if err != nil {
+		sanitized = "<redacted>"
+	}
+	redacted.File = tree.NewStrVal(sanitized)
+	return &redacted
+}
+
+// extractExportStatement returns the execution arguments and the parsed
+// export statement encoded inside the scheduled job.
+func extractExportStatement(
+	sj *jobs.ScheduledJob,
+) (*jobspb.ScheduledExportExecutionArgs, *tree.Export, error) {
+	args := &jobspb.ScheduledExportExecutionArgs{}
+	if err := pbtypes.UnmarshalAny(sj.ExecutionArgs().Args, args); err != nil {
+		return nil, nil, errors.Wrap(err, "un-marshaling args")
+	}
+
+	node, err := parser.ParseOne(args.ExportStatement)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "parsing export statement")
+	}
+
+	if exportStmt, ok := node.AST.(*tree.Export); ok {
+		return args, exportStmt, nil
+	}
+	return nil, nil, errors.Newf("unexpect node type %T", node)
+}
+
+// scheduledExportHeader is the header for "CREATE SCHEDULE FOR EXPORT..."
+// statements results.
+var scheduledExportHeader = colinfo.ResultColumns{
+	{Name: "schedule_id", Typ: types.Int},
+	{Name: "label", Typ: types.String},
+	{Name: "status", Typ: types.String},
+	{Name: "first_run", Typ: types.TimestampTZ},
+	{Name: "schedule", Typ: types.String},
+	{Name: "export_stmt", Typ: types.String},
+}
+
+// doCreateExportSchedule is a plan hook implementation responsible for
+// creating an export schedule.
+func doCreateExportSchedule(
+	ctx context.Context,
+	p sql.PlanHookState,
+	schedule *tree.ScheduledExport,
+	scheduleLabelFn, recurrenceFn, fileFn func() (string, error),
+	scheduleOptsFn func() (map[string]string, error),
+	resultsCh chan<- tree.Datums,
+) error {
+	if err := p.RequireAdminRole(ctx, scheduleExportOp); err != nil {
+		return err
+	}
+
+	if !p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.ScheduledExportJobs) {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"%s requires all nodes to be upgraded to %s",
+			scheduleExportOp, clusterversion.ByKey(clusterversion.ScheduledExportJobs))
+	}
+
+	if err := utilccl.CheckEnterpriseEnabled(
+		p.ExecCfg().Settings, p.ExecCfg().ClusterID(), p.ExecCfg().Organization(),
+		scheduleExportOp,
+	); err != nil {
+		return err
+	}
+
+	if schedule.ScheduleLabelSpec.IfNotExists && scheduleLabelFn != nil {
+		scheduleLabel, err := scheduleLabelFn()
+		if err != nil {
+			return err
+		}
+
+		exists, err := schedulebase.CheckScheduleAlreadyExists(ctx, p, scheduleLabel)
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			p.BufferClientNotice(ctx,
+				pgnotice.Newf("schedule %q already exists, skipping", scheduleLabel),
+			)
+			return nil
+		}
+	}
+
+	env := schedulebase.JobSchedulerEnv(p.ExecCfg())
+
+	recurrence, err := schedulebase.ComputeScheduleRecurrence(env.Now(), recurrenceFn)
+	if err != nil {
+		return err
+	}
+
+	file, err := fileFn()
+	if err != nil {
+		return err
+	}
+	if file == "" {
+		return errors.New("EXPORT destination must not be empty")
+	}
+
+	exportNode := &tree.Export{
+		Query:      schedule.Export.Query,
+		FileFormat: schedule.Export.FileFormat,
+		File:       tree.NewStrVal(file),
+		Options:    schedule.Export.Options,
+	}
+
+	var scheduleLabel string
+	if scheduleLabelFn != nil {
+		label, err := scheduleLabelFn()
+		if err != nil {
+			return err
+		}
+		scheduleLabel = label
+	} else {
+		scheduleLabel = fmt.Sprintf("EXPORT %d", env.Now().Unix())
+	}
+
+	scheduleOptions, err := scheduleOptsFn()
+	if err != nil {
+		return err
+	}
+
+	evalCtx := &p.ExtendedEvalContext().EvalContext
+	firstRun, err := schedulebase.ScheduleFirstRun(evalCtx, scheduleOptions)
+	if err != nil {
+		return err
+	}
+
+	details, err := schedulebase.MakeScheduleDetails(scheduleOptions)
+	if err != nil {
+		return err
+	}
+
+	sj := jobs.NewScheduledJob(env)
+	sj.SetScheduleLabel(scheduleLabel)
+	sj.SetOwner(p.User())
+	if err := sj.SetSchedule(recurrence.Cron); err != nil {
+		return err
+	}
+	sj.SetScheduleDetails(details)
+	if firstRun != nil {
+		sj.SetNextRun(*firstRun)
+	}
+
+	// The export query is executed by the job scheduler in a session whose
+	// current database is the database of the session creating the schedule.
+	args := &jobspb.ScheduledExportExecutionArgs{
+		ExportStatement: tree.AsStringWithFlags(exportNode, tree.FmtParsable|tree.FmtShowPasswords),
+		Database:        p.CurrentDatabase(),
+	}
+	any, err := pbtypes.MarshalAny(args)
+	if err != nil {
+		return err
+	}
+	sj.SetExecutionDetails(
+		tree.ScheduledExportExecutor.InternalName(), jobspb.ExecutionArguments{Args: any},
+	)
+
+	if err := sj.Create(ctx, p.ExecCfg().InternalExecutor, p.ExtendedEvalContext().Txn); err != nil {
+		return err
+	}
+
+	nextRun, err := tree.MakeDTimestampTZ(sj.NextRun(), time.Microsecond)
+	if err != nil {
+		return err
+	}
+	resultsCh <- tree.Datums{
+		tree.NewDInt(tree.DInt(sj.ScheduleID())),
+		tree.NewDString(sj.ScheduleLabel()),
+		tree.NewDString("ACTIVE"),
+		nextRun,
+		tree.NewDString(sj.ScheduleExpr()),
+		tree.NewDString(tree.AsString(redactExport(exportNode))),
+	}
+	telemetry.Count("scheduled-export.create.success")
+	return nil
+}
+
+func createExportScheduleHook(
+	ctx context.Context, stmt tree.Statement, p sql.PlanHookState,
+) (sql.PlanHookRowFn, colinfo.ResultColumns, []sql.PlanNode, bool, error) {
+	schedule, ok := stmt.(*tree.ScheduledExport)
+	if !ok {
+		return nil, nil, nil, false, nil
+	}
+
+	var scheduleLabelFn func() (string, error)
+	var err error
+	if schedule.ScheduleLabelSpec.Label != nil {
+		scheduleLabelFn, err = p.TypeAsString(ctx, schedule.ScheduleLabelSpec.Label, scheduleExportOp)
+		if err != nil {
+			return nil, nil, nil, false, err
+		}
+	}
+
+	if schedule.Recurrence == nil {
+		// Sanity check: recurrence must be specified.
+		return nil, nil, nil, false, errors.New("RECURRING clause required")
+	}
+	recurrenceFn, err := p.TypeAsString(ctx, schedule.Recurrence, scheduleExportOp)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	fileFn, err := p.TypeAsString(ctx, schedule.Export.File, scheduleExportOp)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	scheduleOptsFn, err := p.TypeAsStringOpts(
+		ctx, schedule.ScheduleOptions, schedulebase.CommonScheduleOptionExpectValues)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
+		if err := doCreateExportSchedule(
+			ctx, p, schedule, scheduleLabelFn, recurrenceFn, fileFn, scheduleOptsFn, resultsCh,
+		); err != nil {
+			telemetry.Count("scheduled-export.create.failed")
+			return err
+		}
+		return nil
+	}
+	return fn, scheduledExportHeader, nil, false, nil
+}
+
+func init() {
+	sql.AddPlanHook(createExportScheduleHook)
+	jobs.RegisterConstructor(
+		jobspb.TypeScheduledExport,
+		func(job *jobs.Job, _ *cluster.Settings) jobs.Resumer {
+			details := job.Details().(jobspb.ScheduledExportDetails)
+			// The export is executed at most once. EXPORT names its files after
+			// its query ID, so executing it again after it was interrupted would
+			// leave a second, possibly partial, set of files at the destination.
+			return &schedulebase.StatementJobResumer{
+				Job:       job,
+				OpName:    "scheduled-export",
+				Statement: details.Statement,
+				Database:  details.Database,
+				Started: func(progress jobspb.Progress) bool {
+					return progress.GetScheduledExport().Started
+				},
+				StartedProgress: jobspb.ScheduledExportProgress{Started: true},
+			}
+		},
+	)
+	jobs.RegisterScheduledJobExecutorFactory(
+		tree.ScheduledExportExecutor.InternalName(),
+		func() (jobs.ScheduledJobExecutor, error) {
+			m := jobs.MakeExecutorMetrics(tree.ScheduledExportExecutor.UserName())
+			return &scheduledExportExecutor{
+				metrics: exportScheduleMetrics{ExecutorMetrics: &m},
+			}, nil
+		})
+}
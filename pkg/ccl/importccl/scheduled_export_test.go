@@ -0,0 +1,323 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobstest"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/scheduledjobs"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/server"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// scheduleTestHelper starts a server whose job scheduler is driven by the
+// test through jobstest.JobSchedulerTestEnv.
+type scheduleTestHelper struct {
+	iodir            string
+	server           serverutils.TestServerInterface
+	env              *jobstest.JobSchedulerTestEnv
+	cfg              *scheduledjobs.JobExecutionConfig
+	sqlDB            *sqlutils.SQLRunner
+	executeSchedules func() error
+}
+
+func newScheduleTestHelper(t *testing.T) (*scheduleTestHelper, func()) {
+	dir, dirCleanupFn := testutils.TempDir(t)
+
+	th := &scheduleTestHelper{
+		env: jobstest.NewJobSchedulerTestEnv(
+			jobstest.UseSystemTables, timeutil.Now(), tree.ScheduledExportExecutor),
+		iodir: dir,
+	}
+
+	knobs := &jobs.TestingKnobs{
+		JobSchedulerEnv: th.env,
+		TakeOverJobsScheduling: func(fn func(ctx context.Context, maxSchedules int64, txn *kv.Txn) error) {
+			th.executeSchedules = func() error {
+				defer th.server.JobRegistry().(*jobs.Registry).TestingNudgeAdoptionQueue()
+				return th.cfg.DB.Txn(context.Background(), func(ctx context.Context, txn *kv.Txn) error {
+					// maxSchedules = 0 means there's no limit.
+					return fn(ctx, 0 /* maxSchedules */, txn)
+				})
+			}
+		},
+		CaptureJobExecutionConfig: func(config *scheduledjobs.JobExecutionConfig) {
+			th.cfg = config
+		},
+	}
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{
+		ExternalIODir: dir,
+		Knobs:         base.TestingKnobs{JobsTestingKnobs: knobs},
+	})
+	require.NotNil(t, th.cfg)
+	th.sqlDB = sqlutils.MakeSQLRunner(db)
+	th.server = s
+
+	return th, func() {
+		dirCleanupFn()
+		s.Stopper().Stop(context.Background())
+	}
+}
+
+func (h *scheduleTestHelper) createSchedule(t *testing.T, query string) int64 {
+	t.Helper()
+	var id int64
+	var unusedStr string
+	var unusedTS *time.Time
+	h.sqlDB.QueryRow(t, query).Scan(&id, &unusedStr, &unusedStr, &unusedTS, &unusedStr, &unusedStr)
+	return id
+}
+
+func (h *scheduleTestHelper) loadSchedule(t *testing.T, id int64) *jobs.ScheduledJob {
+	t.Helper()
+	sj, err := jobs.LoadScheduledJob(context.Background(), h.env, id, h.cfg.InternalExecutor, nil)
+	require.NoError(t, err)
+	return sj
+}
+
+// fireSchedule advances the time past the next run of the schedule and runs
+// the job scheduler.
+func (h *scheduleTestHelper) fireSchedule(t *testing.T, id int64) {
+	t.Helper()
+	h.env.SetTime(h.loadSchedule(t, id).NextRun().Add(time.Second))
+	require.NoError(t, h.executeSchedules())
+}
+
+// waitForScheduledJob waits for a job created by the schedule to reach the
+// specified status, and returns its ID.
+func (h *scheduleTestHelper) waitForScheduledJob(
+	t *testing.T, id int64, status jobs.Status,
+) jobspb.JobID {
+	t.Helper()
+	var jobID jobspb.JobID
+	testutils.SucceedsSoon(t, func() error {
+		h.server.JobRegistry().(*jobs.Registry).TestingNudgeAdoptionQueue()
+		return h.sqlDB.DB.QueryRowContext(context.Background(),
+			`SELECT id FROM system.jobs WHERE status = $1 AND created_by_type = $2 AND created_by_id = $3`,
+			status, jobs.CreatedByScheduledJobs, id).Scan(&jobID)
+	})
+	return jobID
+}
+
+// addPausedScheduledJob adds a paused job, created by the schedule, which is
+// seen as a previous run of the schedule which is still running.
+func (h *scheduleTestHelper) addPausedScheduledJob(t *testing.T, id int64) {
+	t.Helper()
+	registry := h.server.JobRegistry().(*jobs.Registry)
+	require.NoError(t, h.cfg.DB.Txn(context.Background(), func(ctx context.Context, txn *kv.Txn) error {
+		jobID := registry.MakeJobID()
+		if _, err := registry.CreateAdoptableJobWithTxn(ctx, jobs.Record{
+			Description: "previous run",
+			Username:    security.RootUserName(),
+			Details:     jobspb.ScheduledExportDetails{Statement: "SELECT 1"},
+			Progress:    jobspb.ScheduledExportProgress{},
+			CreatedBy:   &jobs.CreatedByInfo{Name: jobs.CreatedByScheduledJobs, ID: id},
+		}, jobID, txn); err != nil {
+			return err
+		}
+		_, err := h.cfg.InternalExecutor.Exec(ctx, "pause-job", txn,
+			`UPDATE system.jobs SET status = $1 WHERE id = $2`, string(jobs.StatusPaused), jobID)
+		return err
+	}))
+}
+
+func TestScheduledExport(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	th, cleanup := newScheduleTestHelper(t)
+	defer cleanup()
+
+	th.sqlDB.Exec(t, `
+CREATE DATABASE d;
+CREATE TABLE d.t (a INT PRIMARY KEY, b STRING);
+INSERT INTO d.t VALUES (1, 'one'), (2, 'two'), (3, 'three');
+`)
+
+	numScheduledJobs := func(t *testing.T, id int64) int {
+		var n int
+		th.sqlDB.QueryRow(t,
+			`SELECT count(*) FROM system.jobs WHERE created_by_type = $1 AND created_by_id = $2`,
+			jobs.CreatedByScheduledJobs, id).Scan(&n)
+		return n
+	}
+
+	t.Run("execute", func(t *testing.T) {
+		// The query is resolved in the database of the session which created the
+		// schedule.
+		th.sqlDB.Exec(t, `USE d`)
+		defer th.sqlDB.Exec(t, `USE defaultdb`)
+		id := th.createSchedule(t,
+			`CREATE SCHEDULE FOR EXPORT INTO CSV 'nodelocal://0/execute' FROM (SELECT * FROM t ORDER BY a) RECURRING '@hourly'`)
+		defer th.sqlDB.Exec(t, `DROP SCHEDULE $1`, id)
+
+		th.fireSchedule(t, id)
+		jobID := th.waitForScheduledJob(t, id, jobs.StatusSucceeded)
+
+		// The export ran as the owner of the schedule, and the schedule recorded
+		// its success.
+		require.Equal(t, [][]string{{"SCHEDULED EXPORT", "root"}}, th.sqlDB.QueryStr(t,
+			`SELECT job_type, user_name FROM [SHOW JOBS] WHERE job_id = $1`, jobID))
+		var description string
+		th.sqlDB.QueryRow(t, `SELECT description FROM [SHOW JOBS] WHERE job_id = $1`, jobID).Scan(&description)
+		require.Regexp(t, fmt.Sprintf(
+			`^scheduled export %d: EXPORT INTO CSV 'nodelocal://0/execute' FROM \(?SELECT \* FROM t ORDER BY a`, id),
+			description)
+		sj := th.loadSchedule(t, id)
+		require.False(t, sj.IsPaused())
+		require.True(t, sj.NextRun().After(th.env.Now()))
+
+		content := readFileByGlob(t, filepath.Join(th.iodir, "execute", exportFilePattern))
+		require.Equal(t, "1,one\n2,two\n3,three\n", string(content))
+	})
+
+	t.Run("resume", func(t *testing.T) {
+		// An export job which is adopted before it started exporting executes
+		// the export, while one which is adopted after it started, e.g. after
+		// the node executing it restarted, fails instead of exporting the data a
+		// second time.
+		for _, tc := range []struct {
+			started bool
+			status  jobs.Status
+		}{
+			{started: false, status: jobs.StatusSucceeded},
+			{started: true, status: jobs.StatusFailed},
+		} {
+			t.Run(fmt.Sprintf("started=%t", tc.started), func(t *testing.T) {
+				id := th.createSchedule(t,
+					`CREATE SCHEDULE FOR EXPORT INTO CSV 'nodelocal://0/unused' FROM (SELECT * FROM d.t) RECURRING '@hourly'`)
+				defer th.sqlDB.Exec(t, `DROP SCHEDULE $1`, id)
+
+				dest := fmt.Sprintf("resume-%t", tc.started)
+				registry := th.server.JobRegistry().(*jobs.Registry)
+				require.NoError(t, th.cfg.DB.Txn(context.Background(), func(ctx context.Context, txn *kv.Txn) error {
+					_, err := registry.CreateAdoptableJobWithTxn(ctx, jobs.Record{
+						Description: "interrupted run",
+						Username:    security.RootUserName(),
+						Details: jobspb.ScheduledExportDetails{
+							Statement: fmt.Sprintf(
+								`EXPORT INTO CSV 'nodelocal://0/%s' FROM SELECT * FROM t ORDER BY a`, dest),
+							Database: "d",
+						},
+						Progress:  jobspb.ScheduledExportProgress{Started: tc.started},
+						CreatedBy: &jobs.CreatedByInfo{Name: jobs.CreatedByScheduledJobs, ID: id},
+					}, registry.MakeJobID(), txn)
+					return err
+				}))
+				th.waitForScheduledJob(t, id, tc.status)
+
+				files, err := filepath.Glob(filepath.Join(th.iodir, dest, exportFilePattern))
+				require.NoError(t, err)
+				if tc.started {
+					require.Empty(t, files)
+					return
+				}
+				content := readFileByGlob(t, filepath.Join(th.iodir, dest, exportFilePattern))
+				require.Equal(t, "1,one\n2,two\n3,three\n", string(content))
+			})
+		}
+	})
+
+	t.Run("on_previous_running", func(t *testing.T) {
+		for _, tc := range []struct {
+			option string
+			// started is set if the schedule starts an export although the
+			// previous one is still running.
+			started bool
+			status  string
+		}{
+			{option: "start", started: true},
+			{option: "skip", status: "rescheduled due to 1 already running"},
+			{option: "wait", status: "delayed due to 1 already running"},
+		} {
+			t.Run(tc.option, func(t *testing.T) {
+				id := th.createSchedule(t, fmt.Sprintf(
+					`CREATE SCHEDULE FOR EXPORT INTO CSV 'nodelocal://0/%s' FROM (SELECT * FROM d.t) RECURRING '@hourly'
+WITH SCHEDULE OPTIONS on_previous_running = '%s'`, tc.option, tc.option))
+				defer th.sqlDB.Exec(t, `DROP SCHEDULE $1`, id)
+				th.addPausedScheduledJob(t, id)
+
+				th.fireSchedule(t, id)
+				if tc.started {
+					th.waitForScheduledJob(t, id, jobs.StatusSucceeded)
+					require.Equal(t, 2, numScheduledJobs(t, id))
+					return
+				}
+				require.Equal(t, 1, numScheduledJobs(t, id))
+				sj := th.loadSchedule(t, id)
+				require.Equal(t, tc.status, sj.ScheduleStatus())
+				require.True(t, sj.NextRun().After(th.env.Now()))
+			})
+		}
+	})
+
+	t.Run("on_execution_failure", func(t *testing.T) {
+		th.sqlDB.Exec(t, `CREATE TABLE d.dropped (a INT PRIMARY KEY)`)
+		id := th.createSchedule(t,
+			`CREATE SCHEDULE FOR EXPORT INTO CSV 'nodelocal://0/failure' FROM (SELECT * FROM d.dropped) RECURRING '@hourly'
+WITH SCHEDULE OPTIONS on_execution_failure = 'pause'`)
+		defer th.sqlDB.Exec(t, `DROP SCHEDULE $1`, id)
+		th.sqlDB.Exec(t, `DROP TABLE d.dropped`)
+
+		// The export job is started, and its failure pauses the schedule.
+		th.fireSchedule(t, id)
+		jobID := th.waitForScheduledJob(t, id, jobs.StatusFailed)
+		testutils.SucceedsSoon(t, func() error {
+			if sj := th.loadSchedule(t, id); !sj.IsPaused() {
+				return errors.Newf("schedule %d is not paused: %s", id, sj.ScheduleStatus())
+			}
+			return nil
+		})
+		require.Regexp(t, fmt.Sprintf("schedule paused: export job %d failed", jobID),
+			th.loadSchedule(t, id).ScheduleStatus())
+	})
+}
+
+func TestScheduledExportMixedVersion(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	srv, db, _ := serverutils.StartServer(t, base.TestServerArgs{
+		Knobs: base.TestingKnobs{
+			Server: &server.TestingKnobs{
+				DisableAutomaticVersionUpgrade: 1,
+				BinaryVersionOverride:          clusterversion.ByKey(clusterversion.ScheduledExportJobs - 1),
+			},
+		},
+	})
+	defer srv.Stopper().Stop(context.Background())
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	const createSchedule = `CREATE SCHEDULE FOR EXPORT INTO CSV 'nodelocal://0/t' FROM (SELECT 1) RECURRING '@hourly'`
+	sqlDB.ExpectErr(t, `CREATE SCHEDULE FOR EXPORT requires all nodes to be upgraded`, createSchedule)
+
+	sqlDB.Exec(t, `SET CLUSTER SETTING version = $1`,
+		clusterversion.ByKey(clusterversion.ScheduledExportJobs).String())
+	sqlDB.Exec(t, createSchedule)
+}
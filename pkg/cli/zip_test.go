@@ -76,8 +76,10 @@ table_name NOT IN (
 	'databases',
 	'forward_dependencies',
 	'index_columns',
+	'index_recommendations',
 	'interleaved',
 	'lost_descriptors_with_data',
+	'plan_baselines',
 	'table_columns',
 	'table_row_statistics',
 	'ranges',
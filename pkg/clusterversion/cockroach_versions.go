@@ -248,6 +248,58 @@ const (
 	// that correspond to range descriptor changes resulting from recovery
 	// procedures.
 	UnsafeLossOfQuorumRecoveryRangeLog
+	// UserDefinedFunctions is the version at which schema descriptors can
+	// contain user-defined SQL functions.
+	UserDefinedFunctions
+	// RowLevelTriggers is the version at which table descriptors can contain
+	// row-level triggers, and schema descriptors can contain trigger functions.
+	RowLevelTriggers
+	// DeferrableConstraints is the version at which foreign key and unique
+	// without index constraints can be declared DEFERRABLE.
+	DeferrableConstraints
+	// ReadCommittedIsolation allows transactions to run under the READ COMMITTED
+	// isolation level.
+	ReadCommittedIsolation
+	// ChangefeedQueries is the version at which changefeeds may specify a
+	// projection and filter with CREATE CHANGEFEED ... AS SELECT.
+	ChangefeedQueries
+	// ChangefeedFlatFormats is the version at which changefeeds may emit rows
+	// in the csv and parquet formats.
+	ChangefeedFlatFormats
+	// ImportParquetAndNDJSON is the version at which IMPORT can read PARQUET
+	// and NDJSON files.
+	ImportParquetAndNDJSON
+	// SnappyAndZstdCompression allows IMPORT, EXPORT and changefeeds to use the
+	// snappy and zstd compression codecs.
+	SnappyAndZstdCompression
+	// PublicationsAndReplicationSlots adds the system.publications and
+	// system.replication_slots tables, used by logical replication over pgwire.
+	PublicationsAndReplicationSlots
+	// ScheduledExportJobs is the version at which export schedules run their
+	// EXPORT statement as SCHEDULED EXPORT jobs.
+	ScheduledExportJobs
+	// ScheduledSQL is the version at which schedules can execute SQL statements
+	// as SCHEDULED SQL jobs.
+	ScheduledSQL
+	// RestoreTableAs allows RESTORE TABLE ... AS, whose restore jobs carry the
+	// new names of the restored descriptors.
+	RestoreTableAs
+	// BackupCompaction is the version at which incremental backup schedules can
+	// compact their chain of backups in BACKUP COMPACTION jobs.
+	BackupCompaction
+	// StatementPlanBaselines adds the system.statement_plan_baselines table,
+	// which stores the plan baselines of statement fingerprints.
+	StatementPlanBaselines
+	// MultiColumnHistograms allows histograms to be collected on multi-column
+	// statistics.
+	MultiColumnHistograms
+	// AlterSystemTableStatisticsAddPartialPredicateAndID adds the
+	// partialPredicate and fullStatisticID columns to the
+	// system.table_statistics table, used by partial statistics.
+	AlterSystemTableStatisticsAddPartialPredicateAndID
+	// AutoIndexRecommendationsJob is the version at which the index
+	// recommendations for the workload are refreshed by a singleton job.
+	AutoIndexRecommendationsJob
 
 	// *************************************************
 	// Step (1): Add new versions here.
@@ -380,6 +432,74 @@ var versionsSingleton = keyedVersions{
 		Key:     UnsafeLossOfQuorumRecoveryRangeLog,
 		Version: roachpb.Version{Major: 21, Minor: 2, Internal: 48},
 	},
+	{
+		Key:     UserDefinedFunctions,
+		Version: roachpb.Version{Major: 21, Minor: 2, Internal: 50},
+	},
+	{
+		Key:     RowLevelTriggers,
+		Version: roachpb.Version{Major: 21, Minor: 2, Internal: 52},
+	},
+	{
+		Key:     DeferrableConstraints,
+		Version: roachpb.Version{Major: 21, Minor: 2, Internal: 54},
+	},
+	{
+		Key:     ReadCommittedIsolation,
+		Version: roachpb.Version{Major: 21, Minor: 2, Internal: 56},
+	},
+	{
+		Key:     ChangefeedQueries,
+		Version: roachpb.Version{Major: 21, Minor: 2, Internal: 58},
+	},
+	{
+		Key:     ChangefeedFlatFormats,
+		Version: roachpb.Version{Major: 21, Minor: 2, Internal: 60},
+	},
+	{
+		Key:     ImportParquetAndNDJSON,
+		Version: roachpb.Version{Major: 21, Minor: 2, Internal: 62},
+	},
+	{
+		Key:     SnappyAndZstdCompression,
+		Version: roachpb.Version{Major: 21, Minor: 2, Internal: 64},
+	},
+	{
+		Key:     PublicationsAndReplicationSlots,
+		Version: roachpb.Version{Major: 21, Minor: 2, Internal: 66},
+	},
+	{
+		Key:     ScheduledExportJobs,
+		Version: roachpb.Version{Major: 21, Minor: 2, Internal: 68},
+	},
+	{
+		Key:     ScheduledSQL,
+		Version: roachpb.Version{Major: 21, Minor: 2, Internal: 70},
+	},
+	{
+		Key:     RestoreTableAs,
+		Version: roachpb.Version{Major: 21, Minor: 2, Internal: 72},
+	},
+	{
+		Key:     BackupCompaction,
+		Version: roachpb.Version{Major: 21, Minor: 2, Internal: 74},
+	},
+	{
+		Key:     StatementPlanBaselines,
+		Version: roachpb.Version{Major: 21, Minor: 2, Internal: 76},
+	},
+	{
+		Key:     MultiColumnHistograms,
+		Version: roachpb.Version{Major: 21, Minor: 2, Internal: 78},
+	},
+	{
+		Key:     AlterSystemTableStatisticsAddPartialPredicateAndID,
+		Version: roachpb.Version{Major: 21, Minor: 2, Internal: 80},
+	},
+	{
+		Key:     AutoIndexRecommendationsJob,
+		Version: roachpb.Version{Major: 21, Minor: 2, Internal: 82},
+	},
 
 	// *************************************************
 	// Step (2): Add new versions here.
@@ -21,6 +21,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlliveness"
@@ -407,7 +409,8 @@ func (j *Job) unpaused(ctx context.Context, txn *kv.Txn) error {
 			return nil
 		}
 		if md.Status != StatusPaused {
-			return fmt.Errorf("job with status %s cannot be resumed", md.Status)
+			return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+				"job with status %s cannot be resumed", md.Status)
 		}
 		// We use the absence of error to determine what state we should
 		// resume into.
@@ -451,7 +454,8 @@ func (j *Job) cancelRequested(
 			return nil
 		}
 		if md.Status != StatusPending && md.Status != StatusRunning && md.Status != StatusPaused {
-			return fmt.Errorf("job with status %s cannot be requested to be canceled", md.Status)
+			return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+				"job with status %s cannot be requested to be canceled", md.Status)
 		}
 		if md.Status == StatusPaused && md.Payload.FinalResumeError != nil {
 			decodedErr := errors.DecodeError(ctx, *md.Payload.FinalResumeError)
@@ -502,7 +506,8 @@ func (j *Job) PauseRequested(
 			return nil
 		}
 		if md.Status != StatusPending && md.Status != StatusRunning && md.Status != StatusReverting {
-			return fmt.Errorf("job with status %s cannot be requested to be paused", md.Status)
+			return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+				"job with status %s cannot be requested to be paused", md.Status)
 		}
 		if fn != nil {
 			execCtx, cleanup := j.registry.execCtx("pause request", j.Payload().UsernameProto.Decode())
@@ -47,6 +47,10 @@ var _ Details = MigrationDetails{}
 var _ Details = AutoSpanConfigReconciliationDetails{}
 var _ Details = ImportDetails{}
 var _ Details = StreamReplicationDetails{}
+var _ Details = ScheduledExportDetails{}
+var _ Details = ScheduledSQLDetails{}
+var _ Details = BackupCompactionDetails{}
+var _ Details = AutoIndexRecommendationsDetails{}
 
 // ProgressDetails is a marker interface for job progress details proto structs.
 type ProgressDetails interface{}
@@ -62,6 +66,10 @@ var _ ProgressDetails = NewSchemaChangeProgress{}
 var _ ProgressDetails = MigrationProgress{}
 var _ ProgressDetails = AutoSpanConfigReconciliationDetails{}
 var _ ProgressDetails = StreamReplicationProgress{}
+var _ ProgressDetails = ScheduledExportProgress{}
+var _ ProgressDetails = ScheduledSQLProgress{}
+var _ ProgressDetails = BackupCompactionProgress{}
+var _ ProgressDetails = AutoIndexRecommendationsProgress{}
 
 // Type returns the payload's job type.
 func (p *Payload) Type() Type {
@@ -77,6 +85,17 @@ var _ base.SQLInstanceID
 // running CREATE STATISTICS manually.
 const AutoStatsName = "__auto__"
 
+// AutoPartialStatsName is the name to use for partial statistics created
+// automatically.
+const AutoPartialStatsName = "__auto_partial__"
+
+// ForecastStatsName is the name to use for statistic forecasts.
+const ForecastStatsName = "__forecast__"
+
+// MergedStatsName is the name to use for statistics created by merging a
+// partial statistic into a full statistic.
+const MergedStatsName = "__merged__"
+
 // ImportStatsName is the name to use for statistics created automatically
 // during import.
 const ImportStatsName = "__import__"
@@ -86,6 +105,7 @@ var AutomaticJobTypes = [...]Type{
 	TypeAutoCreateStats,
 	TypeAutoSpanConfigReconciliation,
 	TypeAutoSQLStatsCompaction,
+	TypeAutoIndexRecommendations,
 }
 
 // DetailsType returns the type for a payload detail.
@@ -103,7 +123,7 @@ func DetailsType(d isPayload_Details) Type {
 		return TypeChangefeed
 	case *Payload_CreateStats:
 		createStatsName := d.CreateStats.Name
-		if createStatsName == AutoStatsName {
+		if createStatsName == AutoStatsName || createStatsName == AutoPartialStatsName {
 			return TypeAutoCreateStats
 		}
 		return TypeCreateStats
@@ -123,6 +143,14 @@ func DetailsType(d isPayload_Details) Type {
 		return TypeAutoSQLStatsCompaction
 	case *Payload_StreamReplication:
 		return TypeStreamReplication
+	case *Payload_ScheduledExport:
+		return TypeScheduledExport
+	case *Payload_ScheduledSQL:
+		return TypeScheduledSQL
+	case *Payload_BackupCompaction:
+		return TypeBackupCompaction
+	case *Payload_AutoIndexRecommendations:
+		return TypeAutoIndexRecommendations
 	default:
 		panic(errors.AssertionFailedf("Payload.Type called on a payload with an unknown details type: %T", d))
 	}
@@ -165,6 +193,14 @@ func WrapProgressDetails(details ProgressDetails) interface {
 		return &Progress_AutoSQLStatsCompaction{AutoSQLStatsCompaction: &d}
 	case StreamReplicationProgress:
 		return &Progress_StreamReplication{StreamReplication: &d}
+	case ScheduledExportProgress:
+		return &Progress_ScheduledExport{ScheduledExport: &d}
+	case ScheduledSQLProgress:
+		return &Progress_ScheduledSQL{ScheduledSQL: &d}
+	case BackupCompactionProgress:
+		return &Progress_BackupCompaction{BackupCompaction: &d}
+	case AutoIndexRecommendationsProgress:
+		return &Progress_AutoIndexRecommendations{AutoIndexRecommendations: &d}
 	default:
 		panic(errors.AssertionFailedf("WrapProgressDetails: unknown details type %T", d))
 	}
@@ -202,6 +238,14 @@ func (p *Payload) UnwrapDetails() Details {
 		return *d.AutoSQLStatsCompaction
 	case *Payload_StreamReplication:
 		return *d.StreamReplication
+	case *Payload_ScheduledExport:
+		return *d.ScheduledExport
+	case *Payload_ScheduledSQL:
+		return *d.ScheduledSQL
+	case *Payload_BackupCompaction:
+		return *d.BackupCompaction
+	case *Payload_AutoIndexRecommendations:
+		return *d.AutoIndexRecommendations
 	default:
 		return nil
 	}
@@ -239,6 +283,14 @@ func (p *Progress) UnwrapDetails() ProgressDetails {
 		return *d.AutoSQLStatsCompaction
 	case *Progress_StreamReplication:
 		return *d.StreamReplication
+	case *Progress_ScheduledExport:
+		return *d.ScheduledExport
+	case *Progress_ScheduledSQL:
+		return *d.ScheduledSQL
+	case *Progress_BackupCompaction:
+		return *d.BackupCompaction
+	case *Progress_AutoIndexRecommendations:
+		return *d.AutoIndexRecommendations
 	default:
 		return nil
 	}
@@ -289,6 +341,14 @@ func WrapPayloadDetails(details Details) interface {
 		return &Payload_AutoSQLStatsCompaction{AutoSQLStatsCompaction: &d}
 	case StreamReplicationDetails:
 		return &Payload_StreamReplication{StreamReplication: &d}
+	case ScheduledExportDetails:
+		return &Payload_ScheduledExport{ScheduledExport: &d}
+	case ScheduledSQLDetails:
+		return &Payload_ScheduledSQL{ScheduledSQL: &d}
+	case BackupCompactionDetails:
+		return &Payload_BackupCompaction{BackupCompaction: &d}
+	case AutoIndexRecommendationsDetails:
+		return &Payload_AutoIndexRecommendations{AutoIndexRecommendations: &d}
 	default:
 		panic(errors.AssertionFailedf("jobs.WrapPayloadDetails: unknown details type %T", d))
 	}
@@ -324,7 +384,7 @@ const (
 func (Type) SafeValue() {}
 
 // NumJobTypes is the number of jobs types.
-const NumJobTypes = 16
+const NumJobTypes = 20
 
 // MarshalJSONPB implements jsonpb.JSONPBMarshaller to  redact sensitive sink URI
 // parameters from ChangefeedDetails.
@@ -427,6 +427,14 @@ const (
 	TenantUsageTableID                  = 45
 	SQLInstancesTableID                 = 46
 	SpanConfigurationsTableID           = 47
+	PublicationsTableID                 = 48
+	ReplicationSlotsTableID             = 49
+
+	// StatementPlanBaselinesTableID is the first system table ID outside of the
+	// reserved range. It is only used by clusters bootstrapped with the table:
+	// in clusters created by earlier versions, the ID belongs to a user
+	// descriptor, so the migration creating the table allocates a new ID.
+	StatementPlanBaselinesTableID = 50
 )
 
 // CommentType the type of the schema object on which a comment has been
@@ -448,6 +456,8 @@ const (
 	SchemaCommentType CommentType = 4
 	// ConstraintCommentType comment on a constraint.
 	ConstraintCommentType CommentType = 5
+	// FunctionCommentType comment on a user-defined function.
+	FunctionCommentType CommentType = 6
 )
 
 const (
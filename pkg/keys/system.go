@@ -52,11 +52,21 @@ func MinUserDescriptorID(idChecker SystemIDChecker) uint32 {
 }
 
 // TestingSystemIDChecker returns an implementation of SystemIDChecker suitable
-// for simple unit tests.
+// for simple unit tests. It matches the system IDs of a newly bootstrapped
+// cluster.
 func TestingSystemIDChecker() SystemIDChecker {
-	return &deprecatedSystemIDChecker{}
+	return &testingSystemIDChecker{}
+}
+
+type testingSystemIDChecker struct{}
+
+// IsSystemID implements the SystemIDChecker interface.
+func (t testingSystemIDChecker) IsSystemID(id uint32) bool {
+	return id < minUserDescID || id == StatementPlanBaselinesTableID
 }
 
+var _ SystemIDChecker = (*testingSystemIDChecker)(nil)
+
 // TestingUserDescID is a convenience function which returns a user ID offset
 // from the minimum value allowed in a simple unit test setting.
 func TestingUserDescID(offset uint32) uint32 {
@@ -54,6 +54,14 @@ const (
 	// batches except EndTxn(commit=false) will be rejected.
 	txnError
 
+	// txnRetryableError means that a transaction which takes a new read
+	// snapshot for each statement encountered a retryable error that can be
+	// handled by retrying the current statement, without restarting the
+	// transaction. The transaction moves back to txnPending either when the
+	// client calls PrepareForPartialRetry(), or at the new epoch prepared by the
+	// error when it uses the transaction in any other way.
+	txnRetryableError
+
 	// txnFinalized means that an EndTxn(commit=true) has been executed
 	// successfully, or an EndTxn(commit=false) was sent - regardless of
 	// whether it executed successfully or not. Further batches except
@@ -109,6 +117,11 @@ type TxnCoordSender struct {
 		// clients on Send().
 		storedErr *roachpb.Error
 
+		// storedRetryableErr is set when txnState == txnRetryableError. Its
+		// transaction is the one to use if the transaction is restarted rather
+		// than partially retried.
+		storedRetryableErr *roachpb.TransactionRetryWithProtoRefreshError
+
 		// active is set whenever the transaction has sent any requests. Rolling
 		// back to a savepoint taken before the TxnCoordSender became active resets
 		// the field to false.
@@ -686,6 +699,10 @@ func (tc *TxnCoordSender) maybeRejectClientLocked(
 	switch tc.mu.txnState {
 	case txnPending:
 		// All good.
+	case txnRetryableError:
+		// The client did not partially retry the transaction after the error,
+		// so it continues at the new epoch.
+		tc.restartAfterRetryableErrLocked(ctx)
 	case txnError:
 		return tc.mu.storedErr
 	case txnFinalized:
@@ -769,7 +786,9 @@ func (tc *TxnCoordSender) UpdateStateOnRemoteRetryableErr(
 // the TxnCoordSender's state. Depending on the error, the TxnCoordSender might
 // not be usable afterwards (in case of TransactionAbortedError). The caller is
 // expected to check the ID of the resulting transaction. If the TxnCoordSender
-// can still be used, it will have been prepared for a new epoch.
+// can still be used, it will have been prepared for a new epoch, or, if the
+// current statement can be retried on its own, moved to the txnRetryableError
+// state.
 func (tc *TxnCoordSender) handleRetryableErrLocked(
 	ctx context.Context, pErr *roachpb.Error,
 ) *roachpb.TransactionRetryWithProtoRefreshError {
@@ -830,8 +849,24 @@ func (tc *TxnCoordSender) handleRetryableErrLocked(
 		return retErr
 	}
 
+	if tc.canRetryStatementLocked(pErr) {
+		// Defer the epoch bump, giving the client the chance to retry only the
+		// statement which encountered the error (see PrepareForPartialRetry).
+		// Bumping the epoch would discard the writes of the earlier statements.
+		tc.mu.txnState = txnRetryableError
+		tc.mu.storedRetryableErr = retErr
+		return retErr
+	}
+
+	tc.bumpEpochLocked(ctx, &newTxn)
+	return retErr
+}
+
+// bumpEpochLocked moves the transaction to the given proto, which has been
+// prepared for a new epoch by a retryable error.
+func (tc *TxnCoordSender) bumpEpochLocked(ctx context.Context, newTxn *roachpb.Transaction) {
 	// This is where we get a new epoch.
-	tc.mu.txn.Update(&newTxn)
+	tc.mu.txn.Update(newTxn)
 
 	// Reset state as this is a retryable txn error that is incrementing
 	// the transaction's epoch.
@@ -839,7 +874,65 @@ func (tc *TxnCoordSender) handleRetryableErrLocked(
 	for _, reqInt := range tc.interceptorStack {
 		reqInt.epochBumpedLocked()
 	}
-	return retErr
+}
+
+// canRetryStatementLocked returns whether the given retryable error, which did
+// not abort the transaction, can be handled by retrying the current statement
+// at a new read snapshot. This is only possible for transactions which take a
+// new snapshot for each statement, and only if the error does not invalidate
+// the writes of earlier statements.
+func (tc *TxnCoordSender) canRetryStatementLocked(pErr *roachpb.Error) bool {
+	if !tc.mu.txn.IsoLevel.PerStatementReadSnapshot() || tc.mu.txn.CommitTimestampFixed {
+		return false
+	}
+	if tc.mu.txnState != txnPending {
+		return false
+	}
+	if tErr, ok := pErr.GetDetail().(*roachpb.TransactionRetryError); ok {
+		switch tErr.Reason {
+		case roachpb.RETRY_ASYNC_WRITE_FAILURE, roachpb.RETRY_COMMIT_DEADLINE_EXCEEDED:
+			// A write of an earlier statement was lost, or the transaction can no
+			// longer commit.
+			return false
+		}
+	}
+	return true
+}
+
+// restartAfterRetryableErrLocked moves a transaction in the txnRetryableError
+// state to the new epoch prepared by the error, as if the error had not been
+// eligible for a partial retry.
+func (tc *TxnCoordSender) restartAfterRetryableErrLocked(ctx context.Context) {
+	retryErr := tc.mu.storedRetryableErr
+	tc.mu.txnState = txnPending
+	tc.mu.storedRetryableErr = nil
+	tc.bumpEpochLocked(ctx, &retryErr.Transaction)
+}
+
+// PrepareForPartialRetry is part of the client.TxnSender interface.
+func (tc *TxnCoordSender) PrepareForPartialRetry(ctx context.Context) error {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	if tc.mu.txnState != txnRetryableError {
+		return errors.Errorf("cannot partially retry transaction in state %s", tc.mu.txnState)
+	}
+	retryErr := tc.mu.storedRetryableErr
+	tc.mu.txnState = txnPending
+	tc.mu.storedRetryableErr = nil
+
+	// The retried statement must write above the timestamp prepared by the
+	// error (e.g. above the value that caused a WriteTooOldError), and read from
+	// a snapshot which includes it.
+	tc.mu.txn.WriteTimestamp.Forward(retryErr.Transaction.WriteTimestamp)
+	tc.mu.txn.UpgradePriority(retryErr.Transaction.Priority)
+	readTS := tc.clock.Now()
+	readTS.Forward(tc.mu.txn.WriteTimestamp)
+	tc.mu.txn.BumpReadTimestamp(readTS, tc.clock.MaxOffset().Nanoseconds())
+	tc.interceptorAlloc.txnSpanRefresher.readTimestampSteppedLocked(tc.mu.txn.ReadTimestamp)
+	log.VEventf(ctx, 2, "partially retrying transaction at %s after error: %s",
+		tc.mu.txn.ReadTimestamp, retryErr)
+	return nil
 }
 
 // updateStateLocked updates the transaction state in both the success and error
@@ -1015,6 +1108,24 @@ func (tc *TxnCoordSender) SetUserPriority(pri roachpb.UserPriority) error {
 	return nil
 }
 
+// SetIsoLevel is part of the client.TxnSender interface.
+func (tc *TxnCoordSender) SetIsoLevel(isoLevel enginepb.IsolationLevel) error {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	if tc.mu.active && isoLevel != tc.mu.txn.IsoLevel {
+		return errors.New("cannot change the isolation level of a running transaction")
+	}
+	tc.mu.txn.IsoLevel = isoLevel
+	return nil
+}
+
+// IsoLevel is part of the client.TxnSender interface.
+func (tc *TxnCoordSender) IsoLevel() enginepb.IsolationLevel {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	return tc.mu.txn.IsoLevel
+}
+
 // SetDebugName is part of the client.TxnSender interface.
 func (tc *TxnCoordSender) SetDebugName(name string) {
 	tc.mu.Lock()
@@ -1122,6 +1233,7 @@ func (tc *TxnCoordSender) ManualRestart(
 	// The txn might have entered the txnError state after the epoch was bumped.
 	// Reset the state for the retry.
 	tc.mu.txnState = txnPending
+	tc.mu.storedRetryableErr = nil
 }
 
 // IsSerializablePushAndRefreshNotPossible is part of the client.TxnSender interface.
@@ -1129,6 +1241,10 @@ func (tc *TxnCoordSender) IsSerializablePushAndRefreshNotPossible() bool {
 	tc.mu.Lock()
 	defer tc.mu.Unlock()
 
+	if tc.mu.txn.IsoLevel.ToleratesWriteSkew() {
+		// The transaction can commit at its pushed timestamp.
+		return false
+	}
 	isTxnPushed := tc.mu.txn.WriteTimestamp != tc.mu.txn.ReadTimestamp
 	refreshAttemptNotPossible := tc.interceptorAlloc.txnSpanRefresher.refreshInvalid ||
 		tc.mu.txn.CommitTimestampFixed
@@ -1302,6 +1418,23 @@ func (tc *TxnCoordSender) Step(ctx context.Context) error {
 	return tc.interceptorAlloc.txnSeqNumAllocator.stepLocked(ctx)
 }
 
+// StepReadTimestamp is part of the TxnSender interface.
+func (tc *TxnCoordSender) StepReadTimestamp(ctx context.Context) error {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	if !tc.mu.txn.IsoLevel.PerStatementReadSnapshot() || tc.mu.txn.CommitTimestampFixed {
+		return nil
+	}
+	if err := tc.checkTxnStatusLocked(ctx, kv.OnlyPending); err != nil {
+		return err
+	}
+	now := tc.clock.Now()
+	tc.mu.txn.BumpReadTimestamp(now, tc.clock.MaxOffset().Nanoseconds())
+	tc.interceptorAlloc.txnSpanRefresher.readTimestampSteppedLocked(tc.mu.txn.ReadTimestamp)
+	log.VEventf(ctx, 2, "stepped read timestamp to %s", tc.mu.txn.ReadTimestamp)
+	return nil
+}
+
 // ConfigureStepping is part of the TxnSender interface.
 func (tc *TxnCoordSender) ConfigureStepping(
 	ctx context.Context, mode kv.SteppingMode,
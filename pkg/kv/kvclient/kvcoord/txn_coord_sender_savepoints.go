@@ -69,6 +69,9 @@ func (tc *TxnCoordSender) CreateSavepoint(ctx context.Context) (kv.SavepointToke
 		return nil, err
 	}
 
+	if tc.mu.txnState == txnRetryableError {
+		tc.restartAfterRetryableErrLocked(ctx)
+	}
 	if tc.mu.txnState != txnPending {
 		return nil, ErrSavepointOperationInErrorTxn
 	}
@@ -118,6 +121,11 @@ func (tc *TxnCoordSender) RollbackToSavepoint(ctx context.Context, s kv.Savepoin
 	if tc.mu.txnState == txnError {
 		return unimplemented.New("rollback_error", "cannot rollback to savepoint after error")
 	}
+	if tc.mu.txnState == txnRetryableError {
+		// Savepoints taken in the current epoch are invalidated below, unless
+		// the client partially retried the transaction first.
+		tc.restartAfterRetryableErrLocked(ctx)
+	}
 
 	sp := s.(*savepoint)
 	err := tc.checkSavepointLocked(sp)
@@ -163,6 +171,9 @@ func (tc *TxnCoordSender) ReleaseSavepoint(ctx context.Context, s kv.SavepointTo
 	tc.mu.Lock()
 	defer tc.mu.Unlock()
 
+	if tc.mu.txnState == txnRetryableError {
+		tc.restartAfterRetryableErrLocked(ctx)
+	}
 	if tc.mu.txnState != txnPending {
 		return ErrSavepointOperationInErrorTxn
 	}
@@ -366,6 +366,13 @@ func (tc *txnCommitter) canCommitInParallel(
 		return false
 	}
 
+	// Transactions that tolerate write skew commit at their pushed timestamp
+	// without a refresh, which a failed implicit commit would turn into a
+	// retry error. Keep them on the explicit commit path for now.
+	if ba.Txn.IsoLevel.ToleratesWriteSkew() {
+		return false
+	}
+
 	// If the transaction has a commit trigger, we don't allow it to commit in
 	// parallel with writes. There's no fundamental reason for this restriction,
 	// but for now it's not worth the complication.
@@ -446,9 +446,12 @@ func (sr *txnSpanRefresher) maybeRefreshPreemptivelyLocked(
 	// If true, tryRefreshTxnSpans will trivially succeed.
 	refreshFree := ba.CanForwardReadTimestamp
 
-	// If true, this batch is guaranteed to fail without a refresh.
+	// If true, this batch is guaranteed to fail without a refresh. Transactions
+	// that tolerate write skew can commit above their read timestamp without
+	// refreshing, so the refresh is never inevitable for them.
 	args, hasET := ba.GetArg(roachpb.EndTxn)
-	refreshInevitable := hasET && args.(*roachpb.EndTxnRequest).Commit
+	refreshInevitable := hasET && args.(*roachpb.EndTxnRequest).Commit &&
+		!ba.Txn.IsoLevel.ToleratesWriteSkew()
 
 	// If neither condition is true, defer the refresh.
 	if !refreshFree && !refreshInevitable && !force {
@@ -688,6 +691,17 @@ func (sr *txnSpanRefresher) epochBumpedLocked() {
 	sr.refreshedTimestamp.Reset()
 }
 
+// readTimestampSteppedLocked is called when the transaction establishes a new
+// read snapshot at the given timestamp (see TxnCoordSender.StepReadTimestamp).
+// The reads performed so far were served from earlier snapshots which the
+// transaction no longer needs to be consistent with, so they are dropped from
+// the refresh footprint.
+func (sr *txnSpanRefresher) readTimestampSteppedLocked(readTS hlc.Timestamp) {
+	sr.refreshFootprint.clear()
+	sr.refreshInvalid = false
+	sr.refreshedTimestamp.Forward(readTS)
+}
+
 // createSavepointLocked is part of the txnInterceptor interface.
 func (sr *txnSpanRefresher) createSavepointLocked(ctx context.Context, s *savepoint) {
 	s.refreshSpans = make([]roachpb.Span, len(sr.refreshFootprint.asSlice()))
@@ -18,6 +18,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
@@ -1135,6 +1136,72 @@ func TestTxnSpanRefresherEpochIncrement(t *testing.T) {
 	require.Equal(t, hlc.Timestamp{}, tsr.refreshedTimestamp)
 }
 
+// TestTxnSpanRefresherReadCommitted tests that the txnSpanRefresher drops the
+// reads of previous statements when a READ COMMITTED transaction steps its read
+// timestamp, and that it lets such a transaction commit above its read
+// timestamp without refreshing.
+func TestTxnSpanRefresherReadCommitted(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+	tsr, mockSender := makeMockTxnSpanRefresher()
+
+	txn := makeTxnProto()
+	txn.IsoLevel = enginepb.ReadCommitted
+	keyA, keyB := roachpb.Key("a"), roachpb.Key("b")
+
+	// Perform a read.
+	var ba roachpb.BatchRequest
+	ba.Header = roachpb.Header{Txn: &txn}
+	scanArgs := roachpb.ScanRequest{RequestHeader: roachpb.RequestHeader{Key: keyA, EndKey: keyB}}
+	ba.Add(&scanArgs)
+
+	br, pErr := tsr.SendLocked(ctx, ba)
+	require.Nil(t, pErr)
+	require.NotNil(t, br)
+	require.Equal(t, []roachpb.Span{scanArgs.Span()}, tsr.refreshFootprint.asSlice())
+
+	// Stepping the read timestamp clears the refresh spans.
+	steppedTs := txn.ReadTimestamp.Add(1, 0)
+	txn.BumpReadTimestamp(steppedTs, 0 /* maxOffsetNs */)
+	tsr.readTimestampSteppedLocked(steppedTs)
+	require.True(t, tsr.refreshFootprint.empty())
+	require.False(t, tsr.refreshInvalid)
+	require.Equal(t, steppedTs, tsr.refreshedTimestamp)
+
+	// Perform another read, then push the txn.
+	br, pErr = tsr.SendLocked(ctx, ba)
+	require.Nil(t, pErr)
+	require.NotNil(t, br)
+	require.Equal(t, []roachpb.Span{scanArgs.Span()}, tsr.refreshFootprint.asSlice())
+
+	txn.WriteTimestamp = txn.WriteTimestamp.Add(1, 0)
+	pushedWriteTs := txn.WriteTimestamp
+
+	// The committing EndTxn is issued without a preemptive refresh.
+	ba.Requests = nil
+	ba.Add(&roachpb.EndTxnRequest{Commit: true})
+
+	mockSender.MockSend(func(ba roachpb.BatchRequest) (*roachpb.BatchResponse, *roachpb.Error) {
+		require.Len(t, ba.Requests, 1)
+		require.IsType(t, &roachpb.EndTxnRequest{}, ba.Requests[0].GetInner())
+
+		// The transaction should not be refreshed.
+		require.Equal(t, steppedTs, ba.Txn.ReadTimestamp)
+		require.Equal(t, pushedWriteTs, ba.Txn.WriteTimestamp)
+
+		br := ba.CreateReply()
+		br.Txn = ba.Txn
+		return br, nil
+	})
+
+	br, pErr = tsr.SendLocked(ctx, ba)
+	require.Nil(t, pErr)
+	require.NotNil(t, br)
+	require.Equal(t, int64(0), tsr.refreshSuccess.Count())
+	require.Equal(t, int64(0), tsr.refreshFail.Count())
+}
+
 // TestTxnSpanRefresherSavepoint checks that the span refresher can savepoint
 // its state and restore it.
 func TestTxnSpanRefresherSavepoint(t *testing.T) {
@@ -126,6 +126,13 @@ type TxnSender interface {
 	// SetUserPriority sets the txn's priority.
 	SetUserPriority(roachpb.UserPriority) error
 
+	// SetIsoLevel sets the txn's isolation level. It can only be called before
+	// the txn has performed any operations.
+	SetIsoLevel(enginepb.IsolationLevel) error
+
+	// IsoLevel returns the txn's isolation level.
+	IsoLevel() enginepb.IsolationLevel
+
 	// SetDebugName sets the txn's debug name.
 	SetDebugName(name string)
 
@@ -285,6 +292,25 @@ type TxnSender interface {
 	// The method is idempotent.
 	Step(context.Context) error
 
+	// StepReadTimestamp establishes a new read snapshot for the
+	// transaction at the current time, if the transaction's isolation
+	// level takes a new snapshot for each statement. It is a no-op
+	// otherwise, and for transactions with a fixed commit timestamp.
+	//
+	// Since the new snapshot is not validated against the reads
+	// performed so far, these reads no longer need to be refreshed if
+	// the transaction's timestamp is later pushed.
+	StepReadTimestamp(context.Context) error
+
+	// PrepareForPartialRetry prepares the transaction to retry the current
+	// statement, rather than the whole transaction, after the retryable error
+	// it just returned. The statement is retried at a new read snapshot, and
+	// the client is expected to roll back to a savepoint taken before the
+	// statement. An error is returned if the transaction cannot be partially
+	// retried, for example because the error invalidated the writes of earlier
+	// statements; the transaction then needs to be restarted.
+	PrepareForPartialRetry(context.Context) error
+
 	// ConfigureStepping sets the sequencing point behavior.
 	//
 	// Note that a Sender is initially in the non-stepping mode,
@@ -0,0 +1,41 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package migrations
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/migration"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/systemschema"
+)
+
+const addPartialPredicateAndFullStatisticIDCols = `
+ALTER TABLE system.table_statistics
+  ADD COLUMN IF NOT EXISTS "partialPredicate" STRING NULL,
+  ADD COLUMN IF NOT EXISTS "fullStatisticID" INT8 NULL
+`
+
+// alterSystemTableStatisticsAddPartialPredicateAndID adds the columns used by
+// partial statistics to the system.table_statistics table.
+func alterSystemTableStatisticsAddPartialPredicateAndID(
+	ctx context.Context, cs clusterversion.ClusterVersion, d migration.TenantDeps, _ *jobs.Job,
+) error {
+	op := operation{
+		name:           "add-table-statistics-partial-predicate-and-id-cols",
+		schemaList:     []string{"partialPredicate", "fullStatisticID"},
+		query:          addPartialPredicateAndFullStatisticIDCols,
+		schemaExistsFn: hasColumn,
+	}
+	return migrateTable(ctx, cs, d, op, keys.TableStatisticsTableID, systemschema.TableStatisticsTable)
+}
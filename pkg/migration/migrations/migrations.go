@@ -91,6 +91,24 @@ var migrations = []migration.Migration{
 		toCV(clusterversion.EnsureSpanConfigSubscription),
 		ensureSpanConfigSubscription,
 	),
+	migration.NewTenantMigration(
+		"add the system.publications and system.replication_slots tables",
+		toCV(clusterversion.PublicationsAndReplicationSlots),
+		NoPrecondition,
+		publicationsAndReplicationSlotsMigration,
+	),
+	migration.NewTenantMigration(
+		"add the system.statement_plan_baselines table",
+		toCV(clusterversion.StatementPlanBaselines),
+		NoPrecondition,
+		statementPlanBaselinesMigration,
+	),
+	migration.NewTenantMigration(
+		"add columns partialPredicate and fullStatisticID to table system.table_statistics",
+		toCV(clusterversion.AlterSystemTableStatisticsAddPartialPredicateAndID),
+		NoPrecondition,
+		alterSystemTableStatisticsAddPartialPredicateAndID,
+	),
 }
 
 func init() {
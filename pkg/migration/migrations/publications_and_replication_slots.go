@@ -0,0 +1,40 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package migrations
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/migration"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/systemschema"
+	"github.com/cockroachdb/cockroach/pkg/startupmigrations"
+)
+
+// publicationsAndReplicationSlotsMigration creates the system tables storing
+// the publications and the replication slots of logical replication.
+func publicationsAndReplicationSlotsMigration(
+	ctx context.Context, _ clusterversion.ClusterVersion, d migration.TenantDeps, _ *jobs.Job,
+) error {
+	for _, desc := range []catalog.TableDescriptor{
+		systemschema.PublicationsTable,
+		systemschema.ReplicationSlotsTable,
+	} {
+		if err := startupmigrations.CreateSystemTable(
+			ctx, d.DB, d.Codec, d.Settings, desc,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package migrations
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/migration"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkeys"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkv"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/systemschema"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/startupmigrations"
+)
+
+// statementPlanBaselinesMigration creates the system table storing the plan
+// baselines of statement fingerprints.
+//
+// The ID of the table is outside of the range reserved for system tables, so
+// it is already used by a user descriptor in most clusters created by earlier
+// versions. The table is given a newly allocated ID in that case.
+func statementPlanBaselinesMigration(
+	ctx context.Context, _ clusterversion.ClusterVersion, d migration.TenantDeps, _ *jobs.Job,
+) error {
+	table := systemschema.StatementPlanBaselinesTable
+	existing, err := d.DB.Get(ctx, catalogkeys.MakeDescMetadataKey(d.Codec, table.GetID()))
+	if err != nil {
+		return err
+	}
+	if existing.Exists() {
+		id, err := catalogkv.GenerateUniqueDescID(ctx, d.DB, d.Codec)
+		if err != nil {
+			return err
+		}
+		mut := tabledesc.NewBuilder(table.TableDesc()).BuildCreatedMutableTable()
+		mut.ID = id
+		table = mut.ImmutableCopy().(catalog.TableDescriptor)
+	}
+	// If the table was already created by an earlier attempt, the conditional
+	// writes of CreateSystemTable fail and the table is left untouched.
+	return startupmigrations.CreateSystemTable(ctx, d.DB, d.Codec, d.Settings, table)
+}
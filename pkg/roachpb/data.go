@@ -1138,6 +1138,19 @@ func (t *Transaction) Refresh(timestamp hlc.Timestamp) {
 	t.WriteTooOld = false
 }
 
+// BumpReadTimestamp reconfigures a transaction to read from a new snapshot at
+// the specified timestamp. It is used by transactions whose isolation level
+// establishes a new read snapshot for each statement. Unlike Refresh, the move
+// is not validated against the reads the transaction has already performed, so
+// the uncertainty interval is also reset to start at the new read timestamp:
+// the global uncertainty limit is recomputed and the observed timestamps, which
+// were all collected before the new snapshot, are discarded.
+func (t *Transaction) BumpReadTimestamp(timestamp hlc.Timestamp, maxOffsetNs int64) {
+	t.Refresh(timestamp)
+	t.GlobalUncertaintyLimit.Forward(t.ReadTimestamp.Add(maxOffsetNs, 0))
+	t.ResetObservedTimestamps()
+}
+
 // Update ratchets priority, timestamp and original timestamp values (among
 // others) for the transaction. If t.ID is empty, then the transaction is
 // copied from o.
@@ -1445,6 +1458,7 @@ func PrepareTransactionForRetry(
 		// TODO(andrei): Should we preserve the ObservedTimestamps across the
 		// restart?
 		errTxnPri := txn.Priority
+		errTxnIsoLevel := txn.IsoLevel
 		// Start the new transaction at the current time from the local clock.
 		// The local hlc should have been advanced to at least the error's
 		// timestamp already.
@@ -1461,6 +1475,8 @@ func PrepareTransactionForRetry(
 		)
 		// Use the priority communicated back by the server.
 		txn.Priority = errTxnPri
+		// Preserve the isolation level, which the new transaction inherits.
+		txn.IsoLevel = errTxnIsoLevel
 	case *ReadWithinUncertaintyIntervalError:
 		txn.WriteTimestamp.Forward(readWithinUncertaintyIntervalRetryTimestamp(tErr))
 	case *TransactionPushError:
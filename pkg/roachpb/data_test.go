@@ -477,6 +477,7 @@ var nonZeroTxn = Transaction{
 		Priority:          957356782,
 		Sequence:          123,
 		CoordinatorNodeID: 3,
+		IsoLevel:          enginepb.ReadCommitted,
 	},
 	Name:                   "name",
 	Status:                 COMMITTED,
@@ -519,6 +520,7 @@ func TestTransactionUpdate(t *testing.T) {
 	txn3.Name = "carl"
 	txn3.Priority = 123
 	txn3.CoordinatorNodeID = 3
+	txn3.IsoLevel = enginepb.ReadCommitted
 	txn3.Update(&txn)
 
 	expTxn3 := txn
@@ -534,6 +536,7 @@ func TestTransactionUpdate(t *testing.T) {
 	txn4.Name = "carl"
 	txn4.Priority = 123
 	txn4.CoordinatorNodeID = 3
+	txn4.IsoLevel = enginepb.ReadCommitted
 	txn4.Update(&txn)
 
 	expTxn4 := txn
@@ -734,6 +737,19 @@ func TestTransactionRefresh(t *testing.T) {
 	require.Equal(t, expTxn, txn)
 }
 
+func TestTransactionBumpReadTimestamp(t *testing.T) {
+	txn := nonZeroTxn
+	txn.BumpReadTimestamp(makeTS(45, 1), 10)
+
+	expTxn := nonZeroTxn
+	expTxn.WriteTimestamp = makeTS(45, 1)
+	expTxn.ReadTimestamp = makeTS(45, 1)
+	expTxn.GlobalUncertaintyLimit = makeTS(55, 1)
+	expTxn.ObservedTimestamps = nil
+	expTxn.WriteTooOld = false
+	require.Equal(t, expTxn, txn)
+}
+
 // TestTransactionRecordRoundtrips tests a few properties about Transaction
 // and TransactionRecord protos. Remember that the latter is wire compatible
 // with the former and contains a subset of its protos.
@@ -0,0 +1,126 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package schedulebase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+)
+
+// StatementJobResumer is a jobs.Resumer which executes a single SQL statement
+// on behalf of a schedule whose statement does not have a job of its own.
+//
+// The statement is executed in its own transaction, as the user that created
+// the job, i.e. the owner of the schedule, and at most once: the job records
+// in its progress that it started executing the statement, and fails if it is
+// resumed afterwards, e.g. because it was adopted by another node, as the
+// outcome of the interrupted execution is unknown.
+type StatementJobResumer struct {
+	Job *jobs.Job
+	// OpName identifies the execution of the statement in the internal
+	// executor and in error messages, e.g. "scheduled-sql".
+	OpName string
+	// Statement is the statement executed by the job and Database is the
+	// current database it is executed in.
+	Statement string
+	Database  string
+	// Started returns whether the progress of the job records that it started
+	// executing its statement, and StartedProgress is the progress recording
+	// it.
+	Started         func(jobspb.Progress) bool
+	StartedProgress jobspb.ProgressDetails
+}
+
+var _ jobs.Resumer = &StatementJobResumer{}
+
+// Resume implements the jobs.Resumer interface.
+func (r *StatementJobResumer) Resume(ctx context.Context, execCtx interface{}) error {
+	execCfg := execCtx.(sql.JobExecContext).ExecCfg()
+
+	if r.Started(r.Job.Progress()) {
+		return jobs.MarkAsPermanentJobError(errors.Newf(
+			"%s job %d was interrupted while executing its statement", r.OpName, r.Job.ID()))
+	}
+	if err := r.Job.SetProgress(ctx, nil /* txn */, r.StartedProgress); err != nil {
+		return err
+	}
+
+	rowsAffected, err := execCfg.InternalExecutor.ExecEx(ctx, r.OpName, nil, /* txn */
+		sessiondata.InternalExecutorOverride{
+			User:     r.Job.Payload().UsernameProto.Decode(),
+			Database: r.Database,
+		},
+		r.Statement,
+	)
+	if err != nil {
+		return err
+	}
+	log.Infof(ctx, "%s job %d affected %d rows", r.OpName, r.Job.ID(), rowsAffected)
+
+	return NotifyJobTermination(ctx, execCfg, r.Job, jobs.StatusSucceeded)
+}
+
+// OnFailOrCancel implements the jobs.Resumer interface.
+func (r *StatementJobResumer) OnFailOrCancel(ctx context.Context, execCtx interface{}) error {
+	execCfg := execCtx.(sql.JobExecContext).ExecCfg()
+	// This should never return an error unless resolving the schedule that the
+	// job is being run under fails. This could happen if the schedule is dropped
+	// while the job is executing.
+	if err := NotifyJobTermination(ctx, execCfg, r.Job, jobs.StatusFailed); err != nil {
+		log.Errorf(ctx, "failed to notify job %d on completion of OnFailOrCancel: %+v",
+			r.Job.ID(), err)
+	}
+	return nil
+}
+
+// NotifyJobTermination notifies the schedule which created the job, if any,
+// that the job has terminated with the given status.
+func NotifyJobTermination(
+	ctx context.Context, execCfg *sql.ExecutorConfig, job *jobs.Job, jobStatus jobs.Status,
+) error {
+	env := JobSchedulerEnv(execCfg)
+	return execCfg.DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		datums, err := execCfg.InternalExecutor.QueryRowEx(
+			ctx,
+			"lookup-schedule-info",
+			txn,
+			sessiondata.InternalExecutorOverride{User: security.NodeUserName()},
+			fmt.Sprintf(
+				"SELECT created_by_id FROM %s WHERE id=$1 AND created_by_type=$2",
+				env.SystemJobsTableName()),
+			job.ID(), jobs.CreatedByScheduledJobs)
+		if err != nil {
+			return errors.Wrap(err, "schedule info lookup")
+		}
+		if datums == nil {
+			// Not a scheduled job.
+			return nil
+		}
+
+		scheduleID := int64(tree.MustBeDInt(datums[0]))
+		if err := jobs.NotifyJobTermination(
+			ctx, env, job.ID(), jobStatus, job.Details(), scheduleID, execCfg.InternalExecutor, txn); err != nil {
+			return errors.Wrapf(err,
+				"failed to notify schedule %d of completion of job %d", scheduleID, job.ID())
+		}
+		return nil
+	})
+}
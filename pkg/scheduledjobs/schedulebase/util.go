@@ -0,0 +1,328 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package schedulebase contains the helpers shared by the statements which
+// create scheduled jobs (CREATE SCHEDULE FOR ...).
+package schedulebase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/scheduledjobs"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkv"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descs"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/resolver"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/errors"
+	"github.com/robfig/cron/v3"
+)
+
+// Schedule options shared by all CREATE SCHEDULE statements.
+const (
+	OptFirstRun          = "first_run"
+	OptOnExecFailure     = "on_execution_failure"
+	OptOnPreviousRunning = "on_previous_running"
+)
+
+// CommonScheduleOptionExpectValues is the set of schedule options
+// understood by MakeScheduleDetails and ScheduleFirstRun.
+var CommonScheduleOptionExpectValues = map[string]sql.KVStringOptValidate{
+	OptFirstRun:          sql.KVStringOptRequireValue,
+	OptOnExecFailure:     sql.KVStringOptRequireValue,
+	OptOnPreviousRunning: sql.KVStringOptRequireValue,
+}
+
+// ParseOnError parses the on_execution_failure option value into details.
+func ParseOnError(onError string, details *jobspb.ScheduleDetails) error {
+	switch strings.ToLower(onError) {
+	case "retry":
+		details.OnError = jobspb.ScheduleDetails_RETRY_SOON
+	case "reschedule":
+		details.OnError = jobspb.ScheduleDetails_RETRY_SCHED
+	case "pause":
+		details.OnError = jobspb.ScheduleDetails_PAUSE_SCHED
+	default:
+		return errors.Newf(
+			"%q is not a valid on_execution_error; valid values are [retry|reschedule|pause]",
+			onError)
+	}
+	return nil
+}
+
+// ParseWaitBehavior parses the on_previous_running option value into details.
+func ParseWaitBehavior(wait string, details *jobspb.ScheduleDetails) error {
+	switch strings.ToLower(wait) {
+	case "start":
+		details.Wait = jobspb.ScheduleDetails_NO_WAIT
+	case "skip":
+		details.Wait = jobspb.ScheduleDetails_SKIP
+	case "wait":
+		details.Wait = jobspb.ScheduleDetails_WAIT
+	default:
+		return errors.Newf(
+			"%q is not a valid on_previous_running; valid values are [start|skip|wait]",
+			wait)
+	}
+	return nil
+}
+
+// ParseOnPreviousRunningOption returns the on_previous_running option value
+// corresponding to the specified wait behavior.
+func ParseOnPreviousRunningOption(
+	onPreviousRunning jobspb.ScheduleDetails_WaitBehavior,
+) (string, error) {
+	var onPreviousRunningOption string
+	switch onPreviousRunning {
+	case jobspb.ScheduleDetails_WAIT:
+		onPreviousRunningOption = "WAIT"
+	case jobspb.ScheduleDetails_NO_WAIT:
+		onPreviousRunningOption = "START"
+	case jobspb.ScheduleDetails_SKIP:
+		onPreviousRunningOption = "SKIP"
+	default:
+		return onPreviousRunningOption, errors.Newf("%s is an invalid onPreviousRunning option", onPreviousRunning.String())
+	}
+	return onPreviousRunningOption, nil
+}
+
+// ParseOnErrorOption returns the on_execution_failure option value
+// corresponding to the specified error handling behavior.
+func ParseOnErrorOption(onError jobspb.ScheduleDetails_ErrorHandlingBehavior) (string, error) {
+	var onErrorOption string
+	switch onError {
+	case jobspb.ScheduleDetails_RETRY_SCHED:
+		onErrorOption = "RESCHEDULE"
+	case jobspb.ScheduleDetails_RETRY_SOON:
+		onErrorOption = "RETRY"
+	case jobspb.ScheduleDetails_PAUSE_SCHED:
+		onErrorOption = "PAUSE"
+	default:
+		return onErrorOption, errors.Newf("%s is an invalid onError option", onError.String())
+	}
+	return onErrorOption, nil
+}
+
+// MakeScheduleDetails returns the schedule details described by the
+// on_execution_failure and on_previous_running options.
+func MakeScheduleDetails(opts map[string]string) (jobspb.ScheduleDetails, error) {
+	var details jobspb.ScheduleDetails
+	if v, ok := opts[OptOnExecFailure]; ok {
+		if err := ParseOnError(v, &details); err != nil {
+			return details, err
+		}
+	}
+
+	if v, ok := opts[OptOnPreviousRunning]; ok {
+		if err := ParseWaitBehavior(v, &details); err != nil {
+			return details, err
+		}
+	}
+	return details, nil
+}
+
+// MakeScheduleOptions returns the schedule options which recreate a schedule
+// with the specified first run time and details. It is used when generating
+// the CREATE SCHEDULE statement for an existing schedule.
+func MakeScheduleOptions(
+	firstRun tree.Datum, details jobspb.ScheduleDetails,
+) (tree.KVOptions, error) {
+	wait, err := ParseOnPreviousRunningOption(details.Wait)
+	if err != nil {
+		return nil, err
+	}
+	onError, err := ParseOnErrorOption(details.OnError)
+	if err != nil {
+		return nil, err
+	}
+	return tree.KVOptions{
+		tree.KVOption{
+			Key:   OptFirstRun,
+			Value: firstRun,
+		},
+		tree.KVOption{
+			Key:   OptOnExecFailure,
+			Value: tree.NewDString(onError),
+		},
+		tree.KVOption{
+			Key:   OptOnPreviousRunning,
+			Value: tree.NewDString(wait),
+		},
+	}, nil
+}
+
+// ScheduleFirstRun returns the time specified by the first_run option, if any.
+func ScheduleFirstRun(evalCtx *tree.EvalContext, opts map[string]string) (*time.Time, error) {
+	if v, ok := opts[OptFirstRun]; ok {
+		firstRun, _, err := tree.ParseDTimestampTZ(evalCtx, v, time.Microsecond)
+		if err != nil {
+			return nil, err
+		}
+		return &firstRun.Time, nil
+	}
+	return nil, nil
+}
+
+// ScheduleRecurrence is a parsed RECURRING clause.
+type ScheduleRecurrence struct {
+	Cron      string
+	Frequency time.Duration
+}
+
+// NeverRecurs is a sentinel value indicating the schedule never recurs.
+var NeverRecurs *ScheduleRecurrence
+
+// ComputeScheduleRecurrence evaluates the cron expression returned by evalFn.
+// It returns NeverRecurs if evalFn is nil.
+func ComputeScheduleRecurrence(
+	now time.Time, evalFn func() (string, error),
+) (*ScheduleRecurrence, error) {
+	if evalFn == nil {
+		return NeverRecurs, nil
+	}
+	cronStr, err := evalFn()
+	if err != nil {
+		return nil, err
+	}
+	expr, err := cron.ParseStandard(cronStr)
+	if err != nil {
+		return nil, errors.Newf(
+			`error parsing schedule expression: %q; it must be a valid cron expression`,
+			cronStr)
+	}
+	nextRun := expr.Next(now)
+	frequency := expr.Next(nextRun).Sub(nextRun)
+	return &ScheduleRecurrence{cronStr, frequency}, nil
+}
+
+// CheckScheduleAlreadyExists returns true if a schedule with the same label
+// already exists.
+func CheckScheduleAlreadyExists(
+	ctx context.Context, p sql.PlanHookState, scheduleLabel string,
+) (bool, error) {
+	row, err := p.ExecCfg().InternalExecutor.QueryRowEx(ctx, "check-sched",
+		p.ExtendedEvalContext().Txn, sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+		fmt.Sprintf("SELECT count(schedule_name) FROM %s WHERE schedule_name = $1",
+			scheduledjobs.ProdJobSchedulerEnv.ScheduledJobsTableName()), scheduleLabel)
+
+	if err != nil {
+		return false, err
+	}
+	return int64(tree.MustBeDInt(row[0])) != 0, nil
+}
+
+// FullyQualifyTables fully qualifies the table names and wildcard patterns in
+// tables. Scheduled statements are executed in a background session which
+// does not have the same name resolution configuration as the session that
+// created the schedule, so their target tables must be fully qualified when
+// the schedule is created.
+func FullyQualifyTables(
+	ctx context.Context, p sql.PlanHookState, tables tree.TablePatterns,
+) ([]tree.TablePattern, error) {
+	fqTablePatterns := make([]tree.TablePattern, len(tables))
+	for i, target := range tables {
+		tablePattern, err := target.NormalizeTablePattern()
+		if err != nil {
+			return nil, err
+		}
+		switch tp := tablePattern.(type) {
+		case *tree.TableName:
+			if err := sql.DescsTxn(ctx, p.ExecCfg(), func(ctx context.Context, txn *kv.Txn,
+				col *descs.Collection) error {
+				// Resolve the table.
+				un := tp.ToUnresolvedObjectName()
+				found, _, tableDesc, err := resolver.ResolveExisting(ctx, un, p, tree.ObjectLookupFlags{},
+					p.CurrentDatabase(), p.CurrentSearchPath())
+				if err != nil {
+					return err
+				}
+				if !found {
+					return errors.Newf("target table %s could not be resolved", tp.String())
+				}
+
+				// Resolve the database.
+				found, dbDesc, err := col.GetImmutableDatabaseByID(ctx, txn, tableDesc.GetParentID(),
+					tree.DatabaseLookupFlags{Required: true})
+				if err != nil {
+					return err
+				}
+				if !found {
+					return errors.Newf("database of target table %s could not be resolved", tp.String())
+				}
+
+				// Resolve the schema.
+				schemaDesc, err := col.GetImmutableSchemaByID(ctx, txn, tableDesc.GetParentSchemaID(),
+					tree.SchemaLookupFlags{Required: true})
+				if err != nil {
+					return err
+				}
+				tn := tree.NewTableNameWithSchema(
+					tree.Name(dbDesc.GetName()),
+					tree.Name(schemaDesc.GetName()),
+					tree.Name(tableDesc.GetName()),
+				)
+				fqTablePatterns[i] = tn
+				return nil
+			}); err != nil {
+				return nil, err
+			}
+		case *tree.AllTablesSelector:
+			if !tp.ExplicitSchema {
+				tp.ExplicitSchema = true
+				tp.SchemaName = tree.Name(p.CurrentDatabase())
+			} else if tp.ExplicitSchema && !tp.ExplicitCatalog {
+				// The schema field could either be a schema or a database. If we can
+				// successfully resolve the schema, we will add the DATABASE prefix.
+				// Otherwise, no updates are needed since the schema field refers to the
+				// database.
+				var resolvedSchema bool
+				if err := sql.DescsTxn(ctx, p.ExecCfg(), func(ctx context.Context, txn *kv.Txn,
+					col *descs.Collection) error {
+					dbDesc, err := col.GetImmutableDatabaseByName(ctx, txn, p.CurrentDatabase(),
+						tree.DatabaseLookupFlags{Required: true})
+					if err != nil {
+						return err
+					}
+					resolvedSchema, _, err = catalogkv.ResolveSchemaID(ctx, txn, p.ExecCfg().Codec,
+						dbDesc.GetID(), tp.SchemaName.String(), p.ExecCfg().Settings.Version)
+					return err
+				}); err != nil {
+					return nil, err
+				}
+
+				if resolvedSchema {
+					tp.ExplicitCatalog = true
+					tp.CatalogName = tree.Name(p.CurrentDatabase())
+				}
+			}
+			fqTablePatterns[i] = tp
+		}
+	}
+	return fqTablePatterns, nil
+}
+
+// JobSchedulerEnv returns the JobSchedulerEnv to use when creating
+// schedules, which may be overridden by testing knobs.
+func JobSchedulerEnv(execCfg *sql.ExecutorConfig) scheduledjobs.JobSchedulerEnv {
+	if knobs, ok := execCfg.DistSQLSrv.TestingKnobs.JobsTestingKnobs.(*jobs.TestingKnobs); ok {
+		if knobs.JobSchedulerEnv != nil {
+			return knobs.JobSchedulerEnv
+		}
+	}
+	return scheduledjobs.ProdJobSchedulerEnv
+}
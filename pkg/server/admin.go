@@ -1847,6 +1847,15 @@ func (s *adminServer) Jobs(
 		return nil, err
 	}
 
+	return s.jobsHelper(ctx, req, userName, int(req.Limit), 0)
+}
+
+func (s *adminServer) jobsHelper(
+	ctx context.Context,
+	req *serverpb.JobsRequest,
+	userName security.SQLUsername,
+	limit, offset int,
+) (_ *serverpb.JobsResponse, retErr error) {
 	retryRunningCondition := "status='running' AND next_run > now() AND num_runs > 1"
 	retryRevertingCondition := "status='reverting' AND next_run > now() AND num_runs > 1"
 
@@ -1874,8 +1883,11 @@ func (s *adminServer) Jobs(
 		q.Append(" AND (job_type != $ OR job_type IS NULL)", jobspb.TypeAutoCreateStats.String())
 	}
 	q.Append("ORDER BY created DESC")
-	if req.Limit > 0 {
-		q.Append(" LIMIT $", tree.DInt(req.Limit))
+	if limit > 0 {
+		q.Append(" LIMIT $", tree.DInt(limit))
+		if offset > 0 {
+			q.Append(" OFFSET $", tree.DInt(offset))
+		}
 	}
 	it, err := s.server.sqlServer.internalExecutor.QueryIteratorEx(
 		ctx, "admin-jobs", nil, /* txn */
@@ -1981,6 +1993,12 @@ func (s *adminServer) Job(
 		return nil, err
 	}
 
+	return s.jobHelper(ctx, request, userName)
+}
+
+func (s *adminServer) jobHelper(
+	ctx context.Context, request *serverpb.JobRequest, userName security.SQLUsername,
+) (*serverpb.JobResponse, error) {
 	const query = `
       SELECT job_id, job_type, description, statement, user_name, descriptor_ids, status,
 						 running_status, created, started, finished, modified,
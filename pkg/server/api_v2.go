@@ -42,9 +42,11 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
 	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/roleoption"
 	"github.com/cockroachdb/cockroach/pkg/util/httputil"
 	"github.com/cockroachdb/cockroach/pkg/util/metric"
@@ -140,6 +142,10 @@ func (a *apiV2Server) registerRoutes(innerMux *mux.Router, authMux http.Handler)
 
 		// Directly register other endpoints in the api server.
 		{"sessions/", a.listSessions, true /* requiresAuth */, adminRole, noOption},
+		// Cancellation privileges (CANCELQUERY, admin sessions) are checked by
+		// the status server.
+		{"sessions/{session_id}/cancel/", a.cancelSession, true, regularRole, noOption},
+		{"queries/{query_id}/cancel/", a.cancelQuery, true, regularRole, noOption},
 		{"nodes/", a.listNodes, true, adminRole, noOption},
 		// Any endpoint returning range information requires an admin user. This is because range start/end keys
 		// are sensitive info.
@@ -155,6 +161,16 @@ func (a *apiV2Server) registerRoutes(innerMux *mux.Router, authMux http.Handler)
 		{"databases/{database_name:[\\w.]+}/tables/", a.databaseTables, true, regularRole, noOption},
 		{"databases/{database_name:[\\w.]+}/tables/{table_name:[\\w.]+}/", a.tableDetails, true, regularRole, noOption},
 		{"rules/", a.listRules, false, regularRole, noOption},
+		{"jobs/", a.listJobs, true, regularRole, noOption},
+		{"jobs/{job_id:[0-9]+}/pause/", a.pauseJob, true, regularRole, roleoption.CONTROLJOB},
+		{"jobs/{job_id:[0-9]+}/resume/", a.resumeJob, true, regularRole, roleoption.CONTROLJOB},
+		{"jobs/{job_id:[0-9]+}/cancel/", a.cancelJob, true, regularRole, roleoption.CONTROLJOB},
+		{"schedules/", a.listSchedules, true, adminRole, noOption},
+		{"schedules/{schedule_id:[0-9]+}/pause/", a.pauseSchedule, true, adminRole, noOption},
+		{"schedules/{schedule_id:[0-9]+}/resume/", a.resumeSchedule, true, adminRole, noOption},
+		{"settings/", a.listSettings, true, regularRole, roleoption.MODIFYCLUSTERSETTING},
+		{"settings/{setting_name:[\\w.]+}/", a.setting, true, regularRole, roleoption.MODIFYCLUSTERSETTING},
+		{"index_recommendations/", a.listIndexRecommendations, true, adminRole, noOption},
 	}
 
 	// For all routes requiring authentication, have the outer mux (a.mux)
@@ -168,7 +184,7 @@ func (a *apiV2Server) registerRoutes(innerMux *mux.Router, authMux http.Handler)
 		}
 		if route.requiresAuth {
 			a.mux.Handle(apiV2Path+route.url, authMux)
-			if route.role != regularRole {
+			if route.role != regularRole || route.option != noOption {
 				handler = &roleAuthorizationMux{
 					ie:     a.admin.ie,
 					role:   route.role,
@@ -269,6 +285,128 @@ func (a *apiV2Server) listSessions(w http.ResponseWriter, r *http.Request) {
 	writeJSONResponse(ctx, w, http.StatusOK, response)
 }
 
+// swagger:operation POST /sessions/{session_id}/cancel/ cancelSession
+//
+// Cancel session
+//
+// Cancels a session and all of its queries. Client must be logged-in as the
+// owner of the session, or as a user with the CANCELQUERY role option; only
+// admins can cancel sessions of other admins.
+//
+// ---
+// parameters:
+// - name: session_id
+//   type: string
+//   in: path
+//   description: ID of the session to cancel, as returned by listSessions.
+//   required: true
+// produces:
+// - application/json
+// security:
+// - api_session: []
+// responses:
+//   "200":
+//     description: Cancel session response.
+//     schema:
+//       "$ref": "#/definitions/CancelSessionResponse"
+//   "400":
+//     description: Invalid session ID
+//   "403":
+//     description: User not allowed to cancel the session
+func (a *apiV2Server) cancelSession(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	ctx := r.Context()
+	id, err := sql.StringToClusterWideID(mux.Vars(r)["session_id"])
+	if err != nil {
+		http.Error(w, "invalid session ID", http.StatusBadRequest)
+		return
+	}
+	nodeID := roachpb.NodeID(id.GetNodeID())
+	req := &serverpb.CancelSessionRequest{
+		NodeId:    nodeID.String(),
+		SessionID: id.GetBytes(),
+		Username:  getSQLUsername(ctx).Normalized(),
+	}
+	// Dial the gateway node of the session even if it is the local node, so
+	// that the status server checks the privileges of the logged-in user.
+	outgoingCtx := apiToOutgoingGatewayCtx(ctx, r)
+	client, err := a.status.dialNode(outgoingCtx, nodeID)
+	if err != nil {
+		apiV2Error(ctx, err, w)
+		return
+	}
+	resp, err := client.CancelSession(outgoingCtx, req)
+	if err != nil {
+		apiV2Error(ctx, err, w)
+		return
+	}
+	writeJSONResponse(ctx, w, http.StatusOK, resp)
+}
+
+// swagger:operation POST /queries/{query_id}/cancel/ cancelQuery
+//
+// Cancel query
+//
+// Cancels a running query. Client must be logged-in as the owner of the
+// query, or as a user with the CANCELQUERY role option; only admins can
+// cancel queries of other admins.
+//
+// ---
+// parameters:
+// - name: query_id
+//   type: string
+//   in: path
+//   description: ID of the query to cancel, as returned by listSessions.
+//   required: true
+// produces:
+// - application/json
+// security:
+// - api_session: []
+// responses:
+//   "200":
+//     description: Cancel query response.
+//     schema:
+//       "$ref": "#/definitions/CancelQueryResponse"
+//   "400":
+//     description: Invalid query ID
+//   "403":
+//     description: User not allowed to cancel the query
+func (a *apiV2Server) cancelQuery(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	ctx := r.Context()
+	queryID := mux.Vars(r)["query_id"]
+	id, err := sql.StringToClusterWideID(queryID)
+	if err != nil {
+		http.Error(w, "invalid query ID", http.StatusBadRequest)
+		return
+	}
+	nodeID := roachpb.NodeID(id.GetNodeID())
+	req := &serverpb.CancelQueryRequest{
+		NodeId:   nodeID.String(),
+		QueryID:  queryID,
+		Username: getSQLUsername(ctx).Normalized(),
+	}
+	// See the comment in cancelSession.
+	outgoingCtx := apiToOutgoingGatewayCtx(ctx, r)
+	client, err := a.status.dialNode(outgoingCtx, nodeID)
+	if err != nil {
+		apiV2Error(ctx, err, w)
+		return
+	}
+	resp, err := client.CancelQuery(outgoingCtx, req)
+	if err != nil {
+		apiV2Error(ctx, err, w)
+		return
+	}
+	writeJSONResponse(ctx, w, http.StatusOK, resp)
+}
+
 // swagger:operation GET /health/ health
 //
 // Check node health
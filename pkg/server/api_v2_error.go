@@ -14,7 +14,11 @@ import (
 	"context"
 	"net/http"
 
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -43,3 +47,42 @@ func apiV2InternalError(ctx context.Context, err error, w http.ResponseWriter) {
 	log.ErrorfDepth(ctx, 1, "%s", err)
 	http.Error(w, errAPIInternalErrorString, http.StatusInternalServerError)
 }
+
+// apiV2Error sends err over the http.ResponseWriter for V2 (non-GRPC)
+// endpoints that execute statements or RPCs on behalf of the logged-in user.
+// Errors that are caused by the request, such as missing privileges or
+// invalid arguments, are returned to the client with a corresponding status
+// code; all other errors are handled like apiV2InternalError.
+func apiV2Error(ctx context.Context, err error, w http.ResponseWriter) {
+	code := http.StatusInternalServerError
+	switch {
+	case jobs.HasJobNotFoundError(err):
+		code = http.StatusNotFound
+	case errors.HasType(err, (*jobs.InvalidStatusError)(nil)):
+		code = http.StatusConflict
+	default:
+		switch pgerror.GetPGCode(err) {
+		case pgcode.InsufficientPrivilege:
+			code = http.StatusForbidden
+		case pgcode.InvalidParameterValue, pgcode.Syntax:
+			code = http.StatusBadRequest
+		case pgcode.ObjectNotInPrerequisiteState:
+			code = http.StatusConflict
+		default:
+			switch status.Code(errors.UnwrapAll(err)) {
+			case codes.PermissionDenied:
+				code = http.StatusForbidden
+			case codes.NotFound:
+				code = http.StatusNotFound
+			case codes.InvalidArgument:
+				code = http.StatusBadRequest
+			}
+		}
+	}
+	if code == http.StatusInternalServerError {
+		log.ErrorfDepth(ctx, 1, "%s", err)
+		http.Error(w, errAPIInternalErrorString, code)
+		return
+	}
+	http.Error(w, err.Error(), code)
+}
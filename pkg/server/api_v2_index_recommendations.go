@@ -0,0 +1,136 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/indexadvisor"
+)
+
+// Index recommendation for the workload.
+//
+// swagger:model indexRecommendationResponse
+type indexRecommendationResponse struct {
+	// Type of the recommendation: "create", "replace" or "drop".
+	Type string `json:"type"`
+	// Database of the table of the index.
+	DatabaseName string `json:"database_name"`
+	// Schema of the table of the index.
+	SchemaName string `json:"schema_name"`
+	// Name of the table of the index.
+	TableName string `json:"table_name"`
+	// ID of the table of the index.
+	TableID int64 `json:"table_id"`
+	// Name of the existing index which is replaced or dropped.
+	IndexName string `json:"index_name,omitempty"`
+	// SQL commands needed to follow the recommendation.
+	SQL string `json:"sql"`
+	// Number of statement fingerprints for which the index was recommended.
+	Fingerprints int `json:"fingerprints"`
+	// Number of executions of these statement fingerprints.
+	Executions int64 `json:"executions"`
+	// Total service latency of these executions, in nanoseconds.
+	TotalLatency time.Duration `json:"total_latency"`
+	// Last time the dropped index was read, if it was read since the index
+	// usage statistics were last reset.
+	LastRead *time.Time `json:"last_read,omitempty"`
+}
+
+// Response for listIndexRecommendations.
+//
+// swagger:model indexRecommendationsResponse
+type indexRecommendationsResponse struct {
+	Recommendations []indexRecommendationResponse `json:"recommendations"`
+	// Time at which the recommendations were last refreshed. Not set if they
+	// were not computed yet.
+	Refreshed *time.Time `json:"refreshed,omitempty"`
+
+	// The continuation token, for use in the next paginated call in the `offset`
+	// parameter.
+	Next int `json:"next,omitempty"`
+}
+
+func makeIndexRecommendationResponse(a *indexadvisor.Advice) indexRecommendationResponse {
+	resp := indexRecommendationResponse{
+		Type:         a.Type.String(),
+		DatabaseName: a.Table.DatabaseName,
+		SchemaName:   a.Table.SchemaName,
+		TableName:    a.Table.Name,
+		TableID:      int64(a.Table.ID),
+		IndexName:    a.IndexName,
+		SQL:          a.SQL,
+		Fingerprints: a.Fingerprints,
+		Executions:   a.Executions,
+		TotalLatency: a.TotalLatency,
+	}
+	if !a.LastRead.IsZero() {
+		lastRead := a.LastRead
+		resp.LastRead = &lastRead
+	}
+	return resp
+}
+
+// swagger:operation GET /index_recommendations/ listIndexRecommendations
+//
+// List index recommendations
+//
+// Lists the indexes which are recommended to be created, replaced or
+// dropped for the workload of the cluster, as last computed by the automatic
+// index recommendations job. The indexes to create or replace are ranked by
+// the total latency of the statements they are recommended for. Client must
+// be logged-in as a user with admin privileges.
+//
+// ---
+// parameters:
+// - name: limit
+//   type: integer
+//   in: query
+//   description: Maximum number of results to return in this call.
+//   required: false
+// - name: offset
+//   type: integer
+//   in: query
+//   description: Continuation token for results after a past limited run.
+//   required: false
+// produces:
+// - application/json
+// security:
+// - api_session: []
+// responses:
+//   "200":
+//     description: Index recommendations response
+//     schema:
+//       "$ref": "#/definitions/indexRecommendationsResponse"
+func (a *apiV2Server) listIndexRecommendations(w http.ResponseWriter, r *http.Request) {
+	limit, offset := getSimplePaginationValues(r)
+	ctx := r.Context()
+	ctx = a.admin.server.AnnotateCtx(ctx)
+
+	advice, refreshed, err := a.admin.server.sqlServer.execCfg.IndexAdvisor.Advice(ctx)
+	if err != nil {
+		apiV2InternalError(ctx, err, w)
+		return
+	}
+	var resp indexRecommendationsResponse
+	if !refreshed.IsZero() {
+		resp.Refreshed = &refreshed
+	}
+	var page interface{}
+	page, resp.Next = simplePaginate(advice, limit, offset)
+	resp.Recommendations = []indexRecommendationResponse{}
+	for _, adv := range page.([]indexadvisor.Advice) {
+		adv := adv
+		resp.Recommendations = append(resp.Recommendations, makeIndexRecommendationResponse(&adv))
+	}
+	writeJSONResponse(ctx, w, 200, resp)
+}
@@ -0,0 +1,51 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexRecommendationsV2(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ts, _, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	ctx := context.Background()
+	defer ts.Stopper().Stop(ctx)
+
+	client, err := ts.GetAdminAuthenticatedHTTPClient()
+	require.NoError(t, err)
+	defer client.CloseIdleConnections()
+
+	req, err := http.NewRequest("GET", ts.AdminURL()+apiV2Path+"index_recommendations/", nil)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, 200, resp.StatusCode)
+
+	// The recommendations are only computed in the background, after the
+	// refresh interval, so there are none yet.
+	var ir indexRecommendationsResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&ir))
+	require.Empty(t, ir.Recommendations)
+	require.Nil(t, ir.Refreshed)
+}
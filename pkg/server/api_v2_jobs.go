@@ -0,0 +1,474 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/errors"
+	"github.com/gorilla/mux"
+)
+
+// Response for listJobs.
+//
+// swagger:model jobsResponse
+type jobsResponse struct {
+	serverpb.JobsResponse
+
+	// The continuation token, for use in the next paginated call in the `offset`
+	// parameter.
+	Next int `json:"next,omitempty"`
+}
+
+// swagger:operation GET /jobs/ listJobs
+//
+// List jobs
+//
+// Lists the jobs visible to the logged-in user, most recently created first.
+//
+// ---
+// parameters:
+// - name: status
+//   type: string
+//   in: query
+//   description: Status of jobs to filter for (e.g. "running"). Only one
+//     status can be specified at a time.
+//   required: false
+// - name: type
+//   type: string
+//   in: query
+//   description: Type of jobs to filter for (e.g. "BACKUP"). Only one type
+//     can be specified at a time.
+//   required: false
+// - name: limit
+//   type: integer
+//   in: query
+//   description: Maximum number of results to return in this call.
+//   required: false
+// - name: offset
+//   type: integer
+//   in: query
+//   description: Continuation token for results after a past limited run.
+//   required: false
+// produces:
+// - application/json
+// security:
+// - api_session: []
+// responses:
+//   "200":
+//     description: Jobs response
+//     schema:
+//       "$ref": "#/definitions/jobsResponse"
+//   "400":
+//     description: Invalid job type
+func (a *apiV2Server) listJobs(w http.ResponseWriter, r *http.Request) {
+	limit, offset := getSimplePaginationValues(r)
+	ctx := r.Context()
+	username := getSQLUsername(ctx)
+	ctx = a.admin.server.AnnotateCtx(ctx)
+	queryValues := r.URL.Query()
+
+	req := &serverpb.JobsRequest{Status: queryValues.Get("status")}
+	if typ := queryValues.Get("type"); len(typ) > 0 {
+		t, ok := jobspb.Type_value[typ]
+		if !ok {
+			http.Error(w, "invalid job type", http.StatusBadRequest)
+			return
+		}
+		req.Type = jobspb.Type(t)
+	}
+
+	jobsResp, err := a.admin.jobsHelper(ctx, req, username, limit, offset)
+	if err != nil {
+		apiV2InternalError(ctx, err, w)
+		return
+	}
+	var resp jobsResponse
+	resp.JobsResponse = *jobsResp
+	if limit > 0 && len(resp.Jobs) >= limit {
+		resp.Next = offset + len(resp.Jobs)
+	}
+	writeJSONResponse(ctx, w, 200, resp)
+}
+
+// swagger:operation POST /jobs/{job_id}/pause/ pauseJob
+//
+// Pause a job
+//
+// Requests a job to be paused. Client must be logged-in as a user with admin
+// privileges or the CONTROLJOB role option; only admins can control jobs
+// owned by other admins.
+//
+// ---
+// parameters:
+// - name: job_id
+//   type: integer
+//   in: path
+//   description: ID of the job to pause.
+//   required: true
+// produces:
+// - application/json
+// security:
+// - api_session: []
+// responses:
+//   "200":
+//     description: Job after the pause request
+//     schema:
+//       "$ref": "#/definitions/JobResponse"
+//   "404":
+//     description: Job not found
+//   "409":
+//     description: Job cannot be paused in its current status
+func (a *apiV2Server) pauseJob(w http.ResponseWriter, r *http.Request) {
+	a.controlJob(w, r, "PAUSE JOB $1")
+}
+
+// swagger:operation POST /jobs/{job_id}/resume/ resumeJob
+//
+// Resume a job
+//
+// Resumes a paused job. Client must be logged-in as a user with admin
+// privileges or the CONTROLJOB role option; only admins can control jobs
+// owned by other admins.
+//
+// ---
+// parameters:
+// - name: job_id
+//   type: integer
+//   in: path
+//   description: ID of the job to resume.
+//   required: true
+// produces:
+// - application/json
+// security:
+// - api_session: []
+// responses:
+//   "200":
+//     description: Job after it has been resumed
+//     schema:
+//       "$ref": "#/definitions/JobResponse"
+//   "404":
+//     description: Job not found
+//   "409":
+//     description: Job cannot be resumed in its current status
+func (a *apiV2Server) resumeJob(w http.ResponseWriter, r *http.Request) {
+	a.controlJob(w, r, "RESUME JOB $1")
+}
+
+// swagger:operation POST /jobs/{job_id}/cancel/ cancelJob
+//
+// Cancel a job
+//
+// Requests a job to be canceled. Client must be logged-in as a user with
+// admin privileges or the CONTROLJOB role option; only admins can control
+// jobs owned by other admins.
+//
+// ---
+// parameters:
+// - name: job_id
+//   type: integer
+//   in: path
+//   description: ID of the job to cancel.
+//   required: true
+// produces:
+// - application/json
+// security:
+// - api_session: []
+// responses:
+//   "200":
+//     description: Job after the cancellation request
+//     schema:
+//       "$ref": "#/definitions/JobResponse"
+//   "404":
+//     description: Job not found
+//   "409":
+//     description: Job cannot be canceled in its current status
+func (a *apiV2Server) cancelJob(w http.ResponseWriter, r *http.Request) {
+	a.controlJob(w, r, "CANCEL JOB $1")
+}
+
+// controlJob runs the given job control statement, with the job ID from the
+// request path as its only argument, and responds with the updated job.
+func (a *apiV2Server) controlJob(w http.ResponseWriter, r *http.Request, stmt string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	ctx := r.Context()
+	username := getSQLUsername(ctx)
+	ctx = a.admin.server.AnnotateCtx(ctx)
+	jobID, err := strconv.ParseInt(mux.Vars(r)["job_id"], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid job ID", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := a.admin.server.sqlServer.internalExecutor.ExecEx(
+		ctx, "api-control-job", nil, /* txn */
+		sessiondata.InternalExecutorOverride{User: username},
+		stmt, jobID,
+	); err != nil {
+		apiV2Error(ctx, err, w)
+		return
+	}
+	job, err := a.admin.jobHelper(ctx, &serverpb.JobRequest{JobId: jobID}, username)
+	if err != nil {
+		apiV2InternalError(ctx, err, w)
+		return
+	}
+	writeJSONResponse(ctx, w, 200, job)
+}
+
+// Schedule of recurring jobs.
+//
+// swagger:model scheduleResponse
+type scheduleResponse struct {
+	// ID of the schedule.
+	ID int64 `json:"id"`
+	// Label of the schedule.
+	Label string `json:"label"`
+	// Owner of the schedule.
+	Owner string `json:"owner"`
+	// Executor of the jobs created by the schedule (e.g. "scheduled-backup-executor").
+	ExecutorType string `json:"executor_type"`
+	// Schedule of the job runs, as a crontab expression.
+	Recurrence string `json:"recurrence,omitempty"`
+	// Whether the schedule is paused.
+	Paused bool `json:"paused"`
+	// Time of the next run of the schedule, if not paused.
+	NextRun *time.Time `json:"next_run,omitempty"`
+	// Time at which the schedule was created.
+	Created time.Time `json:"created"`
+}
+
+// Response for listSchedules.
+//
+// swagger:model schedulesResponse
+type schedulesResponse struct {
+	Schedules []scheduleResponse `json:"schedules"`
+
+	// The continuation token, for use in the next paginated call in the `offset`
+	// parameter.
+	Next int `json:"next,omitempty"`
+}
+
+// scheduleColumns are the columns of system.scheduled_jobs scanned by
+// scanRowIntoSchedule. The timestamps are converted to the type expected by
+// resultScanner.
+const scheduleColumns = `schedule_id, schedule_name, owner, executor_type, schedule_expr,
+       next_run::TIMESTAMP, created::TIMESTAMP`
+
+func scanRowIntoSchedule(scanner resultScanner, row tree.Datums, schedule *scheduleResponse) error {
+	var recurrenceOrNil *string
+	if err := scanner.ScanAll(
+		row,
+		&schedule.ID,
+		&schedule.Label,
+		&schedule.Owner,
+		&schedule.ExecutorType,
+		&recurrenceOrNil,
+		&schedule.NextRun,
+		&schedule.Created,
+	); err != nil {
+		return err
+	}
+	if recurrenceOrNil != nil {
+		schedule.Recurrence = *recurrenceOrNil
+	}
+	// Paused schedules have no next run.
+	schedule.Paused = schedule.NextRun == nil
+	return nil
+}
+
+// swagger:operation GET /schedules/ listSchedules
+//
+// List schedules
+//
+// Lists the schedules of recurring jobs on this cluster, in the order in
+// which they were created. Client must be logged-in as a user with admin
+// privileges.
+//
+// ---
+// parameters:
+// - name: limit
+//   type: integer
+//   in: query
+//   description: Maximum number of results to return in this call.
+//   required: false
+// - name: offset
+//   type: integer
+//   in: query
+//   description: Continuation token for results after a past limited run.
+//   required: false
+// produces:
+// - application/json
+// security:
+// - api_session: []
+// responses:
+//   "200":
+//     description: Schedules response
+//     schema:
+//       "$ref": "#/definitions/schedulesResponse"
+func (a *apiV2Server) listSchedules(w http.ResponseWriter, r *http.Request) {
+	limit, offset := getSimplePaginationValues(r)
+	ctx := r.Context()
+	username := getSQLUsername(ctx)
+	ctx = a.admin.server.AnnotateCtx(ctx)
+
+	q := makeSQLQuery()
+	q.Append(`SELECT ` + scheduleColumns + ` FROM system.scheduled_jobs ORDER BY schedule_id`)
+	if limit > 0 {
+		q.Append(" LIMIT $", limit)
+		if offset > 0 {
+			q.Append(" OFFSET $", offset)
+		}
+	}
+	it, err := a.admin.server.sqlServer.internalExecutor.QueryIteratorEx(
+		ctx, "api-schedules", nil, /* txn */
+		sessiondata.InternalExecutorOverride{User: username},
+		q.String(), q.QueryArguments()...,
+	)
+	if err != nil {
+		apiV2InternalError(ctx, err, w)
+		return
+	}
+
+	resp := schedulesResponse{Schedules: []scheduleResponse{}}
+	scanner := makeResultScanner(it.Types())
+	var ok bool
+	for ok, err = it.Next(ctx); ok; ok, err = it.Next(ctx) {
+		var schedule scheduleResponse
+		if err = scanRowIntoSchedule(scanner, it.Cur(), &schedule); err != nil {
+			break
+		}
+		resp.Schedules = append(resp.Schedules, schedule)
+	}
+	err = errors.CombineErrors(err, it.Close())
+	if err != nil {
+		apiV2InternalError(ctx, err, w)
+		return
+	}
+	if limit > 0 && len(resp.Schedules) >= limit {
+		resp.Next = offset + len(resp.Schedules)
+	}
+	writeJSONResponse(ctx, w, 200, resp)
+}
+
+// swagger:operation POST /schedules/{schedule_id}/pause/ pauseSchedule
+//
+// Pause a schedule
+//
+// Pauses a schedule, so that it does not start new jobs until it is
+// resumed. Client must be logged-in as a user with admin privileges.
+//
+// ---
+// parameters:
+// - name: schedule_id
+//   type: integer
+//   in: path
+//   description: ID of the schedule to pause.
+//   required: true
+// produces:
+// - application/json
+// security:
+// - api_session: []
+// responses:
+//   "200":
+//     description: Schedule after it has been paused
+//     schema:
+//       "$ref": "#/definitions/scheduleResponse"
+//   "404":
+//     description: Schedule not found
+func (a *apiV2Server) pauseSchedule(w http.ResponseWriter, r *http.Request) {
+	a.controlSchedule(w, r, "PAUSE SCHEDULE $1")
+}
+
+// swagger:operation POST /schedules/{schedule_id}/resume/ resumeSchedule
+//
+// Resume a schedule
+//
+// Resumes a paused schedule. Client must be logged-in as a user with admin
+// privileges.
+//
+// ---
+// parameters:
+// - name: schedule_id
+//   type: integer
+//   in: path
+//   description: ID of the schedule to resume.
+//   required: true
+// produces:
+// - application/json
+// security:
+// - api_session: []
+// responses:
+//   "200":
+//     description: Schedule after it has been resumed
+//     schema:
+//       "$ref": "#/definitions/scheduleResponse"
+//   "404":
+//     description: Schedule not found
+func (a *apiV2Server) resumeSchedule(w http.ResponseWriter, r *http.Request) {
+	a.controlSchedule(w, r, "RESUME SCHEDULE $1")
+}
+
+// controlSchedule runs the given schedule control statement, with the
+// schedule ID from the request path as its only argument, and responds with
+// the updated schedule.
+func (a *apiV2Server) controlSchedule(w http.ResponseWriter, r *http.Request, stmt string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	ctx := r.Context()
+	username := getSQLUsername(ctx)
+	ctx = a.admin.server.AnnotateCtx(ctx)
+	scheduleID, err := strconv.ParseInt(mux.Vars(r)["schedule_id"], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid schedule ID", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := a.admin.server.sqlServer.internalExecutor.ExecEx(
+		ctx, "api-control-schedule", nil, /* txn */
+		sessiondata.InternalExecutorOverride{User: username},
+		stmt, scheduleID,
+	); err != nil {
+		apiV2Error(ctx, err, w)
+		return
+	}
+	row, cols, err := a.admin.server.sqlServer.internalExecutor.QueryRowExWithCols(
+		ctx, "api-schedule", nil, /* txn */
+		sessiondata.InternalExecutorOverride{User: username},
+		`SELECT `+scheduleColumns+` FROM system.scheduled_jobs WHERE schedule_id = $1`, scheduleID,
+	)
+	if err != nil {
+		apiV2InternalError(ctx, err, w)
+		return
+	}
+	if row == nil {
+		// Controlling a schedule that does not exist is not an error in SQL.
+		http.Error(w, "schedule not found", http.StatusNotFound)
+		return
+	}
+	var schedule scheduleResponse
+	if err := scanRowIntoSchedule(makeResultScanner(cols), row, &schedule); err != nil {
+		apiV2InternalError(ctx, err, w)
+		return
+	}
+	writeJSONResponse(ctx, w, 200, schedule)
+}
@@ -0,0 +1,208 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobsV2(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	// The backfill of the first index blocks until it is unblocked, so that
+	// its job can be paused while it is running.
+	backfillCh := make(chan struct{})
+	var unblockOnce sync.Once
+	unblockBackfill := func() { unblockOnce.Do(func() { close(backfillCh) }) }
+	ts, conn, _ := serverutils.StartServer(t, base.TestServerArgs{
+		Knobs: base.TestingKnobs{
+			JobsTestingKnobs: jobs.NewTestingKnobsWithShortIntervals(),
+			SQLSchemaChanger: &sql.SchemaChangerTestingKnobs{
+				RunBeforeBackfill: func() error {
+					<-backfillCh
+					return nil
+				},
+			},
+		},
+	})
+	ctx := context.Background()
+	defer ts.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(conn)
+	sqlDB.Exec(t, `CREATE TABLE t (k INT PRIMARY KEY, v INT)`)
+	sqlDB.Exec(t, `INSERT INTO t VALUES (1, 1)`)
+
+	client, err := ts.GetAdminAuthenticatedHTTPClient()
+	require.NoError(t, err)
+	defer client.CloseIdleConnections()
+
+	doRequest := func(
+		client http.Client, method, path string, expectedCode int, result interface{},
+	) {
+		req, err := http.NewRequest(method, ts.AdminURL()+apiV2Path+path, nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, expectedCode, resp.StatusCode)
+		if result != nil {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(result))
+		}
+	}
+	// findJob lists the schema change jobs with the given status, and
+	// returns the one which creates the given index, if any.
+	findJob := func(status, index string) *serverpb.JobResponse {
+		var jr jobsResponse
+		doRequest(client, "GET", "jobs/?type=SCHEMA_CHANGE&status="+status, 200, &jr)
+		for i := range jr.Jobs {
+			if strings.HasPrefix(jr.Jobs[i].Description, "CREATE INDEX "+index+" ON") {
+				return &jr.Jobs[i]
+			}
+		}
+		return nil
+	}
+	waitForStatus := func(jobID int64, status string) {
+		sqlDB.CheckQueryResultsRetry(t,
+			fmt.Sprintf(`SELECT status FROM [SHOW JOB %d]`, jobID), [][]string{{status}})
+	}
+
+	t.Run("pause and resume", func(t *testing.T) {
+		defer unblockBackfill()
+		errCh := make(chan error, 1)
+		go func() {
+			_, err := conn.Exec(`CREATE INDEX a ON t (v)`)
+			errCh <- err
+		}()
+		var job *serverpb.JobResponse
+		testutils.SucceedsSoon(t, func() error {
+			if job = findJob("running", "a"); job == nil {
+				return errors.New("the job is not running yet")
+			}
+			return nil
+		})
+
+		var jr serverpb.JobResponse
+		doRequest(client, "POST", fmt.Sprintf("jobs/%d/pause/", job.ID), 200, &jr)
+		require.Equal(t, job.ID, jr.ID)
+		require.Contains(t, []string{"pause-requested", "paused"}, jr.Status)
+		// The job is paused even though its backfill is still blocked.
+		waitForStatus(job.ID, "paused")
+		require.NotNil(t, findJob("paused", "a"))
+		require.Regexp(t, "paused before it completed", <-errCh)
+		unblockBackfill()
+
+		doRequest(client, "POST", fmt.Sprintf("jobs/%d/resume/", job.ID), 200, &jr)
+		require.Equal(t, "running", jr.Status)
+		waitForStatus(job.ID, "succeeded")
+
+		// A succeeded job cannot be paused.
+		doRequest(client, "POST", fmt.Sprintf("jobs/%d/pause/", job.ID), 409, nil)
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		// Pause the job before its backfill, so that it can be canceled once
+		// the statement returns.
+		sqlDB.Exec(t, `SET CLUSTER SETTING jobs.debug.pausepoints = 'indexbackfill.before_flow'`)
+		_, err := conn.Exec(`CREATE INDEX b ON t (v)`)
+		require.Regexp(t, "paused before it completed", err)
+		sqlDB.Exec(t, `RESET CLUSTER SETTING jobs.debug.pausepoints`)
+		job := findJob("paused", "b")
+		require.NotNil(t, job)
+
+		var jr serverpb.JobResponse
+		doRequest(client, "POST", fmt.Sprintf("jobs/%d/cancel/", job.ID), 200, &jr)
+		require.Equal(t, "cancel-requested", jr.Status)
+		waitForStatus(job.ID, "canceled")
+
+		// A canceled job cannot be resumed.
+		doRequest(client, "POST", fmt.Sprintf("jobs/%d/resume/", job.ID), 409, nil)
+	})
+
+	doRequest(client, "GET", "jobs/?type=FOO", 400, nil)
+	doRequest(client, "POST", "jobs/12345/pause/", 404, nil)
+
+	// A non-admin user without CONTROLJOB cannot control jobs.
+	nonAdminClient, err := ts.GetAuthenticatedHTTPClient(false)
+	require.NoError(t, err)
+	doRequest(nonAdminClient, "POST", "jobs/1/pause/", 403, nil)
+}
+
+func TestSchedulesV2(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ts, conn, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	ctx := context.Background()
+	defer ts.Stopper().Stop(ctx)
+
+	var scheduleID int64
+	require.NoError(t, conn.QueryRow(
+		`SELECT schedule_id FROM [CREATE SCHEDULE foo FOR SQL 'SELECT 1' RECURRING '@daily']`,
+	).Scan(&scheduleID))
+
+	client, err := ts.GetAdminAuthenticatedHTTPClient()
+	require.NoError(t, err)
+	defer client.CloseIdleConnections()
+
+	doRequest := func(method, path string, expectedCode int, result interface{}) {
+		req, err := http.NewRequest(method, ts.AdminURL()+apiV2Path+path, nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, expectedCode, resp.StatusCode)
+		if result != nil {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(result))
+		}
+	}
+
+	var sr schedulesResponse
+	doRequest("GET", "schedules/", 200, &sr)
+	var found *scheduleResponse
+	for i := range sr.Schedules {
+		if sr.Schedules[i].ID == scheduleID {
+			found = &sr.Schedules[i]
+		}
+	}
+	require.NotNil(t, found)
+	require.Equal(t, "foo", found.Label)
+	require.Equal(t, "@daily", found.Recurrence)
+	require.False(t, found.Paused)
+
+	var schedule scheduleResponse
+	doRequest("POST", fmt.Sprintf("schedules/%d/pause/", scheduleID), 200, &schedule)
+	require.True(t, schedule.Paused)
+	require.Nil(t, schedule.NextRun)
+	doRequest("POST", fmt.Sprintf("schedules/%d/resume/", scheduleID), 200, &schedule)
+	require.False(t, schedule.Paused)
+	require.NotNil(t, schedule.NextRun)
+
+	doRequest("GET", fmt.Sprintf("schedules/%d/pause/", scheduleID), 405, nil)
+	doRequest("POST", "schedules/12345/pause/", 404, nil)
+}
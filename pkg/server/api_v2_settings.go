@@ -0,0 +1,247 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/lexbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/gorilla/mux"
+)
+
+// Cluster setting.
+//
+// swagger:model settingResponse
+type settingResponse struct {
+	// Name of the setting.
+	Name string `json:"name"`
+	// Type of the setting, e.g. "b" for booleans and "d" for durations.
+	Type string `json:"type"`
+	// Current value of the setting. Sensitive values are redacted unless
+	// the logged-in user has admin privileges.
+	Value string `json:"value"`
+	// Description of the setting.
+	Description string `json:"description"`
+	// Whether the setting is documented.
+	Public bool `json:"public"`
+}
+
+// Response for listSettings.
+//
+// swagger:model settingsResponse
+type settingsResponse struct {
+	Settings []settingResponse `json:"settings"`
+
+	// The continuation token, for use in the next paginated call in the `offset`
+	// parameter.
+	Next int `json:"next,omitempty"`
+}
+
+// settingLookupPurpose returns the purpose with which settings are looked up
+// on behalf of the given user. Only admins can see the unredacted values.
+func (a *apiV2Server) settingLookupPurpose(
+	ctx context.Context, username security.SQLUsername,
+) (settings.LookupPurpose, error) {
+	isAdmin, err := a.admin.hasAdminRole(ctx, username)
+	if err != nil {
+		return settings.LookupForReporting, err
+	}
+	if isAdmin {
+		return settings.LookupForLocalAccess, nil
+	}
+	return settings.LookupForReporting, nil
+}
+
+func (a *apiV2Server) makeSettingResponse(
+	name string, v settings.Setting,
+) settingResponse {
+	return settingResponse{
+		Name: name,
+		Type: v.Typ(),
+		// Note: v.String() redacts the values if the purpose is not "LocalAccess".
+		Value:       v.String(&a.admin.server.st.SV),
+		Description: v.Description(),
+		Public:      v.Visibility() == settings.Public,
+	}
+}
+
+// swagger:operation GET /settings/ listSettings
+//
+// List cluster settings
+//
+// Lists the cluster settings and their current values, sorted by name.
+// Client must be logged-in as a user with admin privileges or the
+// MODIFYCLUSTERSETTING role option.
+//
+// ---
+// parameters:
+// - name: limit
+//   type: integer
+//   in: query
+//   description: Maximum number of results to return in this call.
+//   required: false
+// - name: offset
+//   type: integer
+//   in: query
+//   description: Continuation token for results after a past limited run.
+//   required: false
+// produces:
+// - application/json
+// security:
+// - api_session: []
+// responses:
+//   "200":
+//     description: Settings response
+//     schema:
+//       "$ref": "#/definitions/settingsResponse"
+func (a *apiV2Server) listSettings(w http.ResponseWriter, r *http.Request) {
+	limit, offset := getSimplePaginationValues(r)
+	ctx := r.Context()
+	username := getSQLUsername(ctx)
+	ctx = a.admin.server.AnnotateCtx(ctx)
+
+	lookupPurpose, err := a.settingLookupPurpose(ctx, username)
+	if err != nil {
+		apiV2InternalError(ctx, err, w)
+		return
+	}
+	keys := settings.Keys(settings.ForSystemTenant)
+	var page interface{}
+	var resp settingsResponse
+	page, resp.Next = simplePaginate(keys, limit, offset)
+	resp.Settings = []settingResponse{}
+	for _, k := range page.([]string) {
+		v, ok := settings.Lookup(k, lookupPurpose, settings.ForSystemTenant)
+		if !ok {
+			continue
+		}
+		resp.Settings = append(resp.Settings, a.makeSettingResponse(k, v))
+	}
+	writeJSONResponse(ctx, w, 200, resp)
+}
+
+// Request for updateSetting.
+//
+// swagger:model updateSettingRequest
+type updateSettingRequest struct {
+	// New value of the setting, in the format accepted by SET CLUSTER SETTING.
+	Value string `json:"value"`
+}
+
+// swagger:operation GET /settings/{setting_name}/ settingDetails
+//
+// Get cluster setting
+//
+// Returns the current value of a cluster setting. Client must be logged-in
+// as a user with admin privileges or the MODIFYCLUSTERSETTING role option.
+//
+// ---
+// parameters:
+// - name: setting_name
+//   type: string
+//   in: path
+//   description: Name of the cluster setting.
+//   required: true
+// produces:
+// - application/json
+// security:
+// - api_session: []
+// responses:
+//   "200":
+//     description: Setting response
+//     schema:
+//       "$ref": "#/definitions/settingResponse"
+//   "404":
+//     description: Setting not found
+
+// swagger:operation PUT /settings/{setting_name}/ updateSetting
+//
+// Update cluster setting
+//
+// Updates the value of a cluster setting, like SET CLUSTER SETTING, and
+// returns its new value. Client must be logged-in as a user with admin
+// privileges or the MODIFYCLUSTERSETTING role option; some settings can
+// only be updated by admins.
+//
+// ---
+// parameters:
+// - name: setting_name
+//   type: string
+//   in: path
+//   description: Name of the cluster setting.
+//   required: true
+// - name: request
+//   in: body
+//   required: true
+//   schema:
+//     "$ref": "#/definitions/updateSettingRequest"
+// produces:
+// - application/json
+// security:
+// - api_session: []
+// responses:
+//   "200":
+//     description: Setting response
+//     schema:
+//       "$ref": "#/definitions/settingResponse"
+//   "400":
+//     description: Invalid request
+//   "403":
+//     description: Setting can only be updated by admins
+//   "404":
+//     description: Setting not found
+func (a *apiV2Server) setting(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	username := getSQLUsername(ctx)
+	ctx = a.admin.server.AnnotateCtx(ctx)
+	name := mux.Vars(r)["setting_name"]
+
+	lookupPurpose, err := a.settingLookupPurpose(ctx, username)
+	if err != nil {
+		apiV2InternalError(ctx, err, w)
+		return
+	}
+	v, ok := settings.Lookup(name, lookupPurpose, settings.ForSystemTenant)
+	if !ok {
+		http.Error(w, "setting not found", http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var req updateSettingRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		// The name was validated above, so it does not need quoting.
+		stmt := fmt.Sprintf("SET CLUSTER SETTING %s = %s", name, lexbase.EscapeSQLString(req.Value))
+		if _, err := a.admin.server.sqlServer.internalExecutor.ExecEx(
+			ctx, "api-update-setting", nil, /* txn */
+			sessiondata.InternalExecutorOverride{User: username},
+			stmt,
+		); err != nil {
+			apiV2Error(ctx, err, w)
+			return
+		}
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSONResponse(ctx, w, 200, a.makeSettingResponse(name, v))
+}
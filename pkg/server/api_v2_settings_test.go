@@ -0,0 +1,84 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSettingsV2(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ts, conn, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	ctx := context.Background()
+	defer ts.Stopper().Stop(ctx)
+
+	client, err := ts.GetAdminAuthenticatedHTTPClient()
+	require.NoError(t, err)
+	defer client.CloseIdleConnections()
+
+	doRequest := func(
+		client http.Client, method, path, body string, expectedCode int, result interface{},
+	) {
+		req, err := http.NewRequest(method, ts.AdminURL()+apiV2Path+path, strings.NewReader(body))
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, expectedCode, resp.StatusCode)
+		if result != nil {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(result))
+		}
+	}
+
+	const name = "sql.defaults.distsql"
+	var sr settingResponse
+	doRequest(client, "GET", "settings/"+name+"/", "", 200, &sr)
+	require.Equal(t, name, sr.Name)
+	require.Equal(t, "e", sr.Type)
+	require.Equal(t, "auto", sr.Value)
+
+	doRequest(client, "PUT", "settings/"+name+"/", `{"value": "on"}`, 200, &sr)
+	require.Equal(t, "on", sr.Value)
+	var value string
+	require.NoError(t, conn.QueryRow("SHOW CLUSTER SETTING "+name).Scan(&value))
+	require.Equal(t, "on", value)
+
+	doRequest(client, "PUT", "settings/"+name+"/", `{"value": `, 400, nil)
+	doRequest(client, "GET", "settings/nonexistent.setting/", "", 404, nil)
+
+	var ssr settingsResponse
+	doRequest(client, "GET", "settings/?limit=5", "", 200, &ssr)
+	require.Len(t, ssr.Settings, 5)
+	require.Equal(t, 5, ssr.Next)
+
+	// A non-admin user without MODIFYCLUSTERSETTING cannot access settings.
+	nonAdminClient, err := ts.GetAuthenticatedHTTPClient(false)
+	require.NoError(t, err)
+	doRequest(nonAdminClient, "GET", "settings/"+name+"/", "", 403, nil)
+
+	_, err = conn.Exec(fmt.Sprintf("ALTER USER %s MODIFYCLUSTERSETTING", authenticatedUserNoAdmin))
+	require.NoError(t, err)
+	doRequest(nonAdminClient, "GET", "settings/"+name+"/", "", 200, &sr)
+	require.Equal(t, name, sr.Name)
+}
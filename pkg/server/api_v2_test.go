@@ -23,10 +23,13 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/metric"
+	"github.com/cockroachdb/errors"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v2"
 )
@@ -128,6 +131,92 @@ func TestListSessionsV2(t *testing.T) {
 	require.Contains(t, string(bytesResponse), "not allowed")
 }
 
+func TestCancelQueryAndSessionV2(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ts, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	ctx := context.Background()
+	defer ts.Stopper().Stop(ctx)
+
+	client, err := ts.GetAdminAuthenticatedHTTPClient()
+	require.NoError(t, err)
+	defer client.CloseIdleConnections()
+
+	doRequest := func(method, path string, expectedCode int, result interface{}) {
+		req, err := http.NewRequest(method, ts.AdminURL()+apiV2Path+path, nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, expectedCode, resp.StatusCode)
+		if result != nil {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(result))
+		}
+	}
+	const appName = "api_v2_cancel"
+	findSession := func() *serverpb.Session {
+		var sr listSessionsResponse
+		doRequest("GET", "sessions/", 200, &sr)
+		for i := range sr.Sessions {
+			if sr.Sessions[i].ApplicationName == appName {
+				return &sr.Sessions[i]
+			}
+		}
+		return nil
+	}
+
+	conn, err := db.Conn(ctx)
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+	_, err = conn.ExecContext(ctx, `SET application_name = $1`, appName)
+	require.NoError(t, err)
+
+	t.Run("query", func(t *testing.T) {
+		errCh := make(chan error, 1)
+		go func() {
+			_, err := conn.ExecContext(ctx, `SELECT pg_sleep(1000)`)
+			errCh <- err
+		}()
+		var queryID string
+		testutils.SucceedsSoon(t, func() error {
+			session := findSession()
+			if session == nil || len(session.ActiveQueries) == 0 {
+				return errors.New("the query is not running yet")
+			}
+			queryID = session.ActiveQueries[0].ID
+			return nil
+		})
+
+		var resp serverpb.CancelQueryResponse
+		doRequest("POST", "queries/"+queryID+"/cancel/", 200, &resp)
+		require.True(t, resp.Canceled)
+		require.Regexp(t, "query execution canceled", <-errCh)
+	})
+
+	t.Run("session", func(t *testing.T) {
+		session := findSession()
+		require.NotNil(t, session)
+		sessionID := sql.BytesToClusterWideID(session.ID).String()
+
+		var resp serverpb.CancelSessionResponse
+		doRequest("POST", "sessions/"+sessionID+"/cancel/", 200, &resp)
+		require.True(t, resp.Canceled)
+		testutils.SucceedsSoon(t, func() error {
+			if findSession() != nil {
+				return errors.New("the session is still open")
+			}
+			return nil
+		})
+		_, err := conn.ExecContext(ctx, `SELECT 1`)
+		require.Error(t, err)
+	})
+
+	// Canceling an invalid query or session ID is a client error.
+	doRequest("POST", "queries/foo/cancel/", 400, nil)
+	doRequest("POST", "sessions/foo/cancel/", 400, nil)
+}
+
 func TestHealthV2(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -67,6 +67,9 @@ const (
 
 	graphiteIntervalKey = "external.graphite.interval"
 	maxGraphiteInterval = 15 * time.Minute
+
+	otlpMetricsIntervalKey = "external.otlp.metrics.interval"
+	maxOTLPMetricsInterval = 15 * time.Minute
 )
 
 // Metric names.
@@ -147,6 +150,37 @@ var (
 		10*time.Second,
 		settings.NonNegativeDurationWithMaximum(maxGraphiteInterval),
 	).WithPublic()
+	// otlpMetricsEndpoint is host:port, if any, of the OpenTelemetry
+	// collector receiving metrics.
+	otlpMetricsEndpoint = settings.RegisterStringSetting(
+		settings.TenantWritable,
+		"external.otlp.metrics.endpoint",
+		"if nonempty, push server metrics to the OpenTelemetry collector at the specified host:port "+
+			"using the OTLP/gRPC protocol",
+		"",
+	).WithPublic()
+	// otlpMetricsInterval is how often metrics are pushed to the
+	// OpenTelemetry collector, if enabled.
+	otlpMetricsInterval = settings.RegisterDurationSetting(
+		settings.TenantWritable,
+		otlpMetricsIntervalKey,
+		"the interval at which metrics are pushed to the OpenTelemetry collector (if enabled)",
+		10*time.Second,
+		settings.NonNegativeDurationWithMaximum(maxOTLPMetricsInterval),
+	).WithPublic()
+	// otlpMetricsResourceAttributes describes the node to the
+	// OpenTelemetry collector.
+	otlpMetricsResourceAttributes = settings.RegisterValidatedStringSetting(
+		settings.TenantWritable,
+		"external.otlp.metrics.resource_attributes",
+		"comma-separated list of key=value resource attributes sent along with the metrics "+
+			"pushed to the OpenTelemetry collector",
+		"",
+		func(_ *settings.Values, s string) error {
+			_, err := metric.ParseOTLPResourceAttributes(s)
+			return err
+		},
+	).WithPublic()
 )
 
 type nodeMetrics struct {
@@ -784,6 +818,39 @@ func (n *Node) startGraphiteStatsExporter(st *cluster.Settings) {
 	})
 }
 
+func (n *Node) startOTLPMetricsExporter(st *cluster.Settings) {
+	ctx := logtags.AddTag(n.AnnotateCtx(context.Background()), "otlp metrics exporter", nil)
+	oe := metric.MakeOTLPExporter()
+
+	_ = n.stopper.RunAsyncTask(ctx, "otlp-metrics-exporter", func(ctx context.Context) {
+		var timer timeutil.Timer
+		defer timer.Stop()
+		defer func() {
+			if err := oe.Close(); err != nil {
+				log.Infof(ctx, "error closing connection to OpenTelemetry collector: %s", err)
+			}
+		}()
+		for {
+			timer.Reset(otlpMetricsInterval.Get(&st.SV))
+			select {
+			case <-n.stopper.ShouldQuiesce():
+				return
+			case <-timer.C:
+				timer.Read = true
+				endpoint := otlpMetricsEndpoint.Get(&st.SV)
+				if endpoint == "" {
+					continue
+				}
+				// The setting is validated, so this cannot fail.
+				attrs, _ := metric.ParseOTLPResourceAttributes(otlpMetricsResourceAttributes.Get(&st.SV))
+				if err := n.recorder.ExportToOTLP(ctx, endpoint, attrs, &oe); err != nil {
+					log.Infof(ctx, "error pushing metrics to OpenTelemetry collector: %s", err)
+				}
+			}
+		}
+	})
+}
+
 // startWriteNodeStatus begins periodically persisting status summaries for the
 // node and its stores.
 func (n *Node) startWriteNodeStatus(frequency time.Duration) error {
@@ -81,6 +81,7 @@ import (
 	_ "github.com/cockroachdb/cockroach/pkg/sql/gcjob" // register jobs declared outside of pkg/sql
 	"github.com/cockroachdb/cockroach/pkg/sql/optionalnodeliveness"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire"
+	_ "github.com/cockroachdb/cockroach/pkg/sql/scheduledsql"        // register jobs declared outside of pkg/sql
 	_ "github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scjob" // register jobs declared outside of pkg/sql
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/storage"
@@ -1741,6 +1742,15 @@ func (s *Server) PreStart(ctx context.Context) error {
 		}
 	})
 
+	var otlpMetricsOnce sync.Once
+	otlpMetricsEndpoint.SetOnChange(&s.st.SV, func(context.Context) {
+		if otlpMetricsEndpoint.Get(&s.st.SV) != "" {
+			otlpMetricsOnce.Do(func() {
+				s.node.startOTLPMetricsExporter(s.st)
+			})
+		}
+	})
+
 	// Start the protected timestamp subsystem. Note that this needs to happen
 	// before the modeOperational switch below, as the protected timestamps
 	// subsystem will crash if accessed before being Started (and serving general
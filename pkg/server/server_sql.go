@@ -70,8 +70,10 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
 	"github.com/cockroachdb/cockroach/pkg/sql/flowinfra"
 	"github.com/cockroachdb/cockroach/pkg/sql/gcjob/gcjobnotifier"
+	"github.com/cockroachdb/cockroach/pkg/sql/indexadvisor"
 	"github.com/cockroachdb/cockroach/pkg/sql/optionalnodeliveness"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire"
+	"github.com/cockroachdb/cockroach/pkg/sql/planbaseline"
 	"github.com/cockroachdb/cockroach/pkg/sql/querycache"
 	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scdeps"
 	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scrun"
@@ -137,6 +139,8 @@ type SQLServer struct {
 	// sqlMemMetrics are used to track memory usage of sql sessions.
 	sqlMemMetrics           sql.MemoryMetrics
 	stmtDiagnosticsRegistry *stmtdiagnostics.Registry
+	planBaselineRegistry    *planbaseline.Registry
+	indexAdvisor            *indexadvisor.Advisor
 	// sqlLivenessSessionID will be populated with a non-zero value for non-system
 	// tenants.
 	sqlLivenessSessionID    sqlliveness.SessionID
@@ -835,6 +839,16 @@ func newSQLServer(ctx context.Context, cfg sqlServerArgs) (*SQLServer, error) {
 		cfg.Settings,
 	)
 	execCfg.StmtDiagnosticsRecorder = stmtDiagnosticsRegistry
+	planBaselineRegistry := planbaseline.NewRegistry(cfg.circularInternalExecutor, cfg.Settings)
+	execCfg.PlanBaselines = planBaselineRegistry
+	indexAdvisor := indexadvisor.NewAdvisor(
+		cfg.db,
+		jobRegistry,
+		cfg.circularInternalExecutor,
+		cfg.Settings,
+		sql.NewIndexAdvisorRecommender(execCfg),
+	)
+	execCfg.IndexAdvisor = indexAdvisor
 
 	{
 		// We only need to attach a version upgrade hook if we're the system
@@ -972,6 +986,8 @@ func newSQLServer(ctx context.Context, cfg sqlServerArgs) (*SQLServer, error) {
 		internalMemMetrics:      internalMemMetrics,
 		sqlMemMetrics:           sqlMemMetrics,
 		stmtDiagnosticsRegistry: stmtDiagnosticsRegistry,
+		planBaselineRegistry:    planBaselineRegistry,
+		indexAdvisor:            indexAdvisor,
 		sqlLivenessProvider:     cfg.sqlLivenessProvider,
 		sqlInstanceProvider:     cfg.sqlInstanceProvider,
 		metricsRegistry:         cfg.registry,
@@ -1079,6 +1095,7 @@ func (s *SQLServer) preStart(
 		return err
 	}
 	s.stmtDiagnosticsRegistry.Start(ctx, stopper)
+	s.planBaselineRegistry.Start(ctx, stopper)
 
 	// Before serving SQL requests, we have to make sure the database is
 	// in an acceptable form for this version of the software.
@@ -1129,6 +1146,7 @@ func (s *SQLServer) preStart(
 			return err
 		}
 	}
+	s.indexAdvisor.Start(ctx, stopper)
 
 	var bootstrapVersion roachpb.Version
 	if s.execCfg.Codec.ForSystemTenant() {
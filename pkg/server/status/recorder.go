@@ -296,6 +296,46 @@ func (mr *MetricsRecorder) ExportToGraphite(
 	return graphiteExporter.Push(ctx, endpoint)
 }
 
+// ExportToOTLP sends the current metric values to an OpenTelemetry
+// collector. Unless set in resourceAttrs, the service.instance.id resource
+// attribute is set to the node ID.
+func (mr *MetricsRecorder) ExportToOTLP(
+	ctx context.Context, endpoint string, resourceAttrs map[string]string, oe *metric.OTLPExporter,
+) error {
+	nodeID, ok := mr.scrapeIntoOTLP(oe)
+	if !ok {
+		return nil
+	}
+	if _, ok := resourceAttrs["service.instance.id"]; !ok {
+		attrs := map[string]string{"service.instance.id": nodeID.String()}
+		for k, v := range resourceAttrs {
+			attrs[k] = v
+		}
+		resourceAttrs = attrs
+	}
+	return oe.Push(ctx, endpoint, resourceAttrs)
+}
+
+// scrapeIntoOTLP updates the passed-in OTLPExporter's metrics snapshot. It
+// returns the node ID, or false if it is not known yet.
+func (mr *MetricsRecorder) scrapeIntoOTLP(oe *metric.OTLPExporter) (roachpb.NodeID, bool) {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+	if mr.mu.nodeRegistry == nil {
+		// We haven't yet processed initialization information; do nothing.
+		if log.V(1) {
+			log.Warning(context.TODO(), "MetricsRecorder asked to scrape metrics before NodeID allocation")
+		}
+		return 0, false
+	}
+	includeChildMetrics := childMetricsEnabled.Get(&mr.settings.SV)
+	oe.ScrapeRegistry(mr.mu.nodeRegistry, includeChildMetrics)
+	for _, reg := range mr.mu.storeRegistries {
+		oe.ScrapeRegistry(reg, includeChildMetrics)
+	}
+	return mr.mu.desc.NodeID, true
+}
+
 // GetTimeSeriesData serializes registered metrics for consumption by
 // CockroachDB's time series system.
 func (mr *MetricsRecorder) GetTimeSeriesData() []tspb.TimeSeriesData {
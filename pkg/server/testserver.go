@@ -47,6 +47,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/bootstrap"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/physicalplan"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
@@ -756,9 +757,13 @@ func ExpectedInitialRangeCount(
 	// of the system config ID space (keys.MaxSystemConfigDescID) and the system
 	// table with the maximum ID (maxSystemDescriptorID), even when an ID within
 	// the span does not have an associated descriptor.
+	// The system tables whose IDs are outside of the reserved range, which are
+	// part of the bootstrapped cluster, are not known to idChecker.
+	bootstrappedIDChecker := bootstrap.BootstrappedSystemIDChecker()
 	maxSystemDescriptorID := descriptorIDs[0]
 	for _, descID := range descriptorIDs {
-		if descID > maxSystemDescriptorID && catalog.IsSystemID(idChecker, descID) {
+		if descID > maxSystemDescriptorID &&
+			(catalog.IsSystemID(idChecker, descID) || catalog.IsSystemID(bootstrappedIDChecker, descID)) {
 			maxSystemDescriptorID = descID
 		}
 	}
@@ -384,8 +384,8 @@ func (s *SQLTranslator) findDescendantLeafIDs(
 // type, these are:
 // - Database: IDs of all tables inside the database.
 // - Table: ID of the table itself.
-// - Schema/Type: Nothing, as schemas/types do not carry zone configurations and
-// are not part of the zone configuration hierarchy.
+// - Schema/Type/Function: Nothing, as schemas/types/functions do not carry zone
+// configurations and are not part of the zone configuration hierarchy.
 func (s *SQLTranslator) findDescendantLeafIDsForDescriptor(
 	ctx context.Context, id descpb.ID, txn *kv.Txn, descsCol *descs.Collection,
 ) (descpb.IDs, error) {
@@ -401,9 +401,9 @@ func (s *SQLTranslator) findDescendantLeafIDsForDescriptor(
 	}
 
 	switch desc.DescriptorType() {
-	case catalog.Type, catalog.Schema:
-		// There is nothing to do for {Type, Schema} descriptors as they are not
-		// part of the zone configuration hierarchy.
+	case catalog.Type, catalog.Schema, catalog.Function:
+		// There is nothing to do for {Type, Schema, Function} descriptors as they
+		// are not part of the zone configuration hierarchy.
 		return nil, nil
 	case catalog.Table:
 		// Tables are leaf objects in the zone configuration hierarchy, so simply
@@ -88,6 +88,12 @@ func (p *planner) AlterTableSetSchema(
 		}
 	}
 
+	// Triggers reference their functions by name in the schema of the table.
+	if len(tableDesc.Triggers) > 0 {
+		return nil, pgerror.Newf(pgcode.FeatureNotSupported,
+			"cannot set schema on table %q which has triggers", tableDesc.Name)
+	}
+
 	return &alterTableSetSchemaNode{
 		newSchema: string(n.Schema),
 		prefix:    prefix,
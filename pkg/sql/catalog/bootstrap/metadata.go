@@ -271,6 +271,12 @@ func addSystemDescriptorsToSchema(target *MetadataSchema) {
 	target.AddDescriptor(systemschema.SQLInstancesTable)
 	target.AddDescriptorForSystemTenant(systemschema.SpanConfigurationsTable)
 
+	// Tables introduced in 22.1.
+
+	target.AddDescriptor(systemschema.PublicationsTable)
+	target.AddDescriptor(systemschema.ReplicationSlotsTable)
+	target.AddDescriptor(systemschema.StatementPlanBaselinesTable)
+
 	// Adding a new system table? It should be added here to the metadata schema,
 	// and also created as a migration for older clusters. The includedInBootstrap
 	// field should be set on the migration.
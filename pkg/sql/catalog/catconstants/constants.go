@@ -79,6 +79,9 @@ const (
 	TenantUsageTableName                   SystemTableName = "tenant_usage"
 	SQLInstancesTableName                  SystemTableName = "sql_instances"
 	SpanConfigurationsTableName            SystemTableName = "span_configurations"
+	PublicationsTableName                  SystemTableName = "publications"
+	ReplicationSlotsTableName              SystemTableName = "replication_slots"
+	StatementPlanBaselinesTableName        SystemTableName = "statement_plan_baselines"
 )
 
 // Oid for virtual database and table.
@@ -368,5 +371,7 @@ const (
 	PgExtensionGeographyColumnsTableID
 	PgExtensionGeometryColumnsTableID
 	PgExtensionSpatialRefSysTableID
-	MinVirtualID = PgExtensionSpatialRefSysTableID
+	CrdbInternalPlanBaselinesTableID
+	CrdbInternalIndexRecommendationsTableID
+	MinVirtualID = CrdbInternalIndexRecommendationsTableID
 )
@@ -62,6 +62,9 @@ var (
 		catconstants.TenantUsageTableName,
 		catconstants.SQLInstancesTableName,
 		catconstants.SpanConfigurationsTableName,
+		catconstants.PublicationsTableName,
+		catconstants.ReplicationSlotsTableName,
+		catconstants.StatementPlanBaselinesTableName,
 	}
 
 	systemSuperuserPrivileges = func() map[descpb.NameInfo]privilege.List {
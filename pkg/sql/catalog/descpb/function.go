@@ -0,0 +1,84 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package descpb
+
+import (
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+)
+
+// ArgTypes returns the types of the arguments of the function.
+func (f *FunctionDescriptor) ArgTypes() []*types.T {
+	typs := make([]*types.T, len(f.Args))
+	for i := range f.Args {
+		typs[i] = f.Args[i].Type
+	}
+	return typs
+}
+
+// Signature returns the name of the function followed by its argument types,
+// e.g. "f(INT8, STRING)". Two functions in the same schema must not have the
+// same signature.
+func (f *FunctionDescriptor) Signature() string {
+	var sb strings.Builder
+	sb.WriteString(f.Name)
+	sb.WriteByte('(')
+	for i := range f.Args {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(f.Args[i].Type.SQLString())
+	}
+	sb.WriteByte(')')
+	return sb.String()
+}
+
+// HasArgTypes returns whether the function takes arguments of exactly the
+// given types.
+func (f *FunctionDescriptor) HasArgTypes(typs []*types.T) bool {
+	return identicalTypes(f.ArgTypes(), typs)
+}
+
+// HasArgTypes returns whether the overload takes arguments of exactly the
+// given types.
+func (o *SchemaDescriptor_FunctionOverload) HasArgTypes(typs []*types.T) bool {
+	return identicalTypes(o.ArgTypes, typs)
+}
+
+func identicalTypes(a, b []*types.T) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Identical(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// ToTreeVolatility converts the declared volatility of a function to a
+// tree.Volatility.
+func (v FunctionDescriptor_Volatility) ToTreeVolatility() tree.Volatility {
+	switch v {
+	case FunctionDescriptor_IMMUTABLE:
+		return tree.VolatilityImmutable
+	case FunctionDescriptor_STABLE:
+		return tree.VolatilityStable
+	default:
+		return tree.VolatilityVolatile
+	}
+}
+
+// SafeValue implements the redact.SafeValue interface.
+func (FunctionDescriptor_Volatility) SafeValue() {}
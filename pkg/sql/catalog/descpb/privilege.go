@@ -172,6 +172,15 @@ func NewBaseDatabasePrivilegeDescriptor(owner security.SQLUsername) *PrivilegeDe
 	return p
 }
 
+// NewBaseFunctionPrivilegeDescriptor creates default privileges for a
+// user-defined function. As in Postgres, the public role can execute the
+// function.
+func NewBaseFunctionPrivilegeDescriptor(owner security.SQLUsername) *PrivilegeDescriptor {
+	p := NewBasePrivilegeDescriptor(owner)
+	p.Grant(security.PublicRoleName(), privilege.List{privilege.EXECUTE}, false /* withGrantOption */)
+	return p
+}
+
 // CheckGrantOptions returns false if the user tries to grant a privilege that
 // it does not possess grant options for
 func (p *PrivilegeDescriptor) CheckGrantOptions(
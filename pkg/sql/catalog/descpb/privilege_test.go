@@ -302,6 +302,7 @@ func TestValidPrivilegesForObjects(t *testing.T) {
 		{privilege.Database, privilege.DBPrivileges},
 		{privilege.Schema, privilege.SchemaPrivileges},
 		{privilege.Type, privilege.TypePrivileges},
+		{privilege.Function, privilege.FunctionPrivileges},
 	}
 
 	for _, tc := range testCases {
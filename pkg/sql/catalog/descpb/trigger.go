@@ -0,0 +1,50 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package descpb
+
+import "github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+
+// HasEvent returns whether the trigger fires for the given event.
+func (t *TriggerDescriptor) HasEvent(event TriggerDescriptor_Event) bool {
+	for _, e := range t.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// SafeValue implements the redact.SafeValue interface.
+func (TriggerDescriptor_ActionTime) SafeValue() {}
+
+// SafeValue implements the redact.SafeValue interface.
+func (TriggerDescriptor_Event) SafeValue() {}
+
+// ToTreeActionTime converts the action time of a trigger to a
+// tree.TriggerActionTime.
+func (a TriggerDescriptor_ActionTime) ToTreeActionTime() tree.TriggerActionTime {
+	if a == TriggerDescriptor_AFTER {
+		return tree.TriggerAfter
+	}
+	return tree.TriggerBefore
+}
+
+// ToTreeEvent converts an event of a trigger to a tree.TriggerEvent.
+func (e TriggerDescriptor_Event) ToTreeEvent() tree.TriggerEvent {
+	switch e {
+	case TriggerDescriptor_UPDATE:
+		return tree.TriggerUpdate
+	case TriggerDescriptor_DELETE:
+		return tree.TriggerDelete
+	default:
+		return tree.TriggerInsert
+	}
+}
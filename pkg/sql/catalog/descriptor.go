@@ -44,6 +44,9 @@ const (
 
 	// Schema is for schema descriptors.
 	Schema = "schema"
+
+	// Function is for function descriptors.
+	Function = "function"
 )
 
 // MutationPublicationFilter is used by MakeFirstMutationPublic to filter the
@@ -579,6 +582,9 @@ type TableDescriptor interface {
 	// "inactive" ones queued in the mutations list.
 	AllActiveAndInactiveUniqueWithoutIndexConstraints() []*descpb.UniqueWithoutIndexConstraint
 
+	// GetTriggers returns the row-level triggers defined on the table.
+	GetTriggers() []descpb.TriggerDescriptor
+
 	// ForeachOutboundFK calls f for every outbound foreign key in desc until an
 	// error is returned.
 	ForeachOutboundFK(f func(fk *descpb.ForeignKeyConstraint) error) error
@@ -704,6 +710,26 @@ type TypeDescriptor interface {
 	GetReferencingDescriptorID(refOrdinal int) descpb.ID
 }
 
+// FunctionDescriptor is an interface around the function descriptor types. It
+// describes a single overload of a user-defined function.
+type FunctionDescriptor interface {
+	Descriptor
+
+	// FuncDesc returns the backing protobuf for this function.
+	FuncDesc() *descpb.FunctionDescriptor
+
+	// ArgTypes returns the types of the arguments of the function.
+	ArgTypes() []*types.T
+	// Signature returns the name of the function followed by its argument
+	// types, e.g. "f(INT8, STRING)".
+	Signature() string
+	// GetVolatility returns the declared volatility of the function.
+	GetVolatility() descpb.FunctionDescriptor_Volatility
+	// GetTrigger returns whether the function is a trigger function, which
+	// can only be executed by row-level triggers.
+	GetTrigger() bool
+}
+
 // TypeDescriptorResolver is an interface used during hydration of type
 // metadata in types.T's. It is similar to tree.TypeReferenceResolver, except
 // that it has the power to return TypeDescriptor, rather than only a
@@ -0,0 +1,197 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package funcdesc_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/dbdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemadesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignature(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	fn := funcdesc.NewBuilder(&descpb.FunctionDescriptor{
+		Name: "f",
+		Args: []descpb.FunctionDescriptor_Argument{
+			{Name: "a", Type: types.Int},
+			{Type: types.String},
+		},
+		ReturnType: types.Int,
+	}).BuildImmutableFunction()
+	require.Equal(t, "f(INT8, STRING)", fn.Signature())
+	require.True(t, fn.FuncDesc().HasArgTypes([]*types.T{types.Int, types.String}))
+	require.False(t, fn.FuncDesc().HasArgTypes([]*types.T{types.Int}))
+}
+
+func TestValidateFunctionDesc(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+
+	tests := []struct {
+		err  string
+		desc descpb.FunctionDescriptor
+	}{
+		{ // 0
+			desc: descpb.FunctionDescriptor{
+				ID:             53,
+				ParentID:       51,
+				ParentSchemaID: 52,
+				Name:           "f",
+				ReturnType:     types.Int,
+			},
+		},
+		{ // 1
+			err: `invalid parent schema ID 0`,
+			desc: descpb.FunctionDescriptor{
+				ID:         53,
+				ParentID:   51,
+				Name:       "f",
+				ReturnType: types.Int,
+			},
+		},
+		{ // 2
+			err: `missing return type`,
+			desc: descpb.FunctionDescriptor{
+				ID:             53,
+				ParentID:       51,
+				ParentSchemaID: 52,
+				Name:           "f",
+			},
+		},
+		{ // 3
+			err: `trigger function has a return type or arguments`,
+			desc: descpb.FunctionDescriptor{
+				ID:             53,
+				ParentID:       51,
+				ParentSchemaID: 52,
+				Name:           "f",
+				Args:           []descpb.FunctionDescriptor_Argument{{Type: types.Int}},
+				Trigger:        true,
+			},
+		},
+	}
+
+	for i, test := range tests {
+		test.desc.Privileges = descpb.NewBasePrivilegeDescriptor(security.AdminRoleName())
+		desc := funcdesc.NewBuilder(&test.desc).BuildImmutable()
+		expectedErr := fmt.Sprintf("%s %q (%d): %s", desc.DescriptorType(), desc.GetName(), desc.GetID(), test.err)
+		results := catalog.Validate(ctx, catalog.MakeMapDescGetter(), catalog.NoValidationTelemetry, catalog.ValidationLevelSelfOnly, desc)
+		if err := results.CombinedError(); err == nil {
+			if test.err != "" {
+				t.Errorf("%d: expected \"%s\", but found success: %+v", i, expectedErr, test.desc)
+			}
+		} else if expectedErr != err.Error() {
+			t.Errorf("%d: expected \"%s\", but found \"%s\"", i, expectedErr, err.Error())
+		}
+	}
+}
+
+func TestValidateCrossFunctionReferences(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+
+	tests := []struct {
+		err    string
+		desc   descpb.FunctionDescriptor
+		scDesc descpb.SchemaDescriptor
+	}{
+		{ // 0
+			desc: descpb.FunctionDescriptor{
+				ID:             53,
+				ParentID:       51,
+				ParentSchemaID: 52,
+				Name:           "f",
+				ReturnType:     types.Int,
+			},
+			scDesc: descpb.SchemaDescriptor{
+				ID:       52,
+				ParentID: 51,
+				Name:     "schema1",
+				Functions: map[string]descpb.SchemaDescriptor_Function{
+					"f": {Name: "f", Overloads: []descpb.SchemaDescriptor_FunctionOverload{{ID: 53}}},
+				},
+			},
+		},
+		{ // 1
+			err: `referenced schema ID 500: referenced descriptor not found`,
+			desc: descpb.FunctionDescriptor{
+				ID:             53,
+				ParentID:       51,
+				ParentSchemaID: 500,
+				Name:           "f",
+				ReturnType:     types.Int,
+			},
+			scDesc: descpb.SchemaDescriptor{
+				ID:       52,
+				ParentID: 51,
+				Name:     "schema1",
+			},
+		},
+		{ // 2
+			err: `not present in parent schema [52] functions mapping`,
+			desc: descpb.FunctionDescriptor{
+				ID:             53,
+				ParentID:       51,
+				ParentSchemaID: 52,
+				Name:           "f",
+				ReturnType:     types.Int,
+			},
+			scDesc: descpb.SchemaDescriptor{
+				ID:       52,
+				ParentID: 51,
+				Name:     "schema1",
+				Functions: map[string]descpb.SchemaDescriptor_Function{
+					"f": {Name: "f", Overloads: []descpb.SchemaDescriptor_FunctionOverload{{ID: 54}}},
+				},
+			},
+		},
+	}
+
+	for i, test := range tests {
+		privilege := descpb.NewBasePrivilegeDescriptor(security.AdminRoleName())
+		descs := catalog.MakeMapDescGetter()
+		test.desc.Privileges = privilege
+		desc := funcdesc.NewBuilder(&test.desc).BuildImmutable()
+		descs.Descriptors[test.desc.ID] = desc
+		test.scDesc.Privileges = privilege
+		descs.Descriptors[test.scDesc.ID] = schemadesc.NewBuilder(&test.scDesc).BuildImmutable()
+		dbDesc := descpb.DatabaseDescriptor{
+			ID:         51,
+			Name:       "db",
+			Privileges: privilege,
+			Schemas: map[string]descpb.DatabaseDescriptor_SchemaInfo{
+				"schema1": {ID: 52},
+			},
+		}
+		descs.Descriptors[dbDesc.ID] = dbdesc.NewBuilder(&dbDesc).BuildImmutable()
+		expectedErr := fmt.Sprintf("%s %q (%d): %s", desc.DescriptorType(), desc.GetName(), desc.GetID(), test.err)
+		const validateCrossReferencesOnly = catalog.ValidationLevelCrossReferences &^ (catalog.ValidationLevelCrossReferences >> 1)
+		results := catalog.Validate(ctx, descs, catalog.NoValidationTelemetry, validateCrossReferencesOnly, desc)
+		if err := results.CombinedError(); err == nil {
+			if test.err != "" {
+				t.Errorf("%d: expected \"%s\", but found success: %+v", i, expectedErr, test.desc)
+			}
+		} else if expectedErr != err.Error() {
+			t.Errorf("%d: expected \"%s\", but found \"%s\"", i, expectedErr, err.Error())
+		}
+	}
+}
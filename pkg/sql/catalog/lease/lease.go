@@ -501,7 +501,9 @@ func acquireNodeLease(ctx context.Context, m *Manager, id descpb.ID) (bool, erro
 		if err != nil {
 			return nil, err
 		}
-		if newDescVersionState != nil {
+		// Functions have no namespace entries, and must not shadow the object
+		// with the same name in the name cache.
+		if newDescVersionState != nil && desc.DescriptorType() != catalog.Function {
 			m.names.insert(newDescVersionState)
 		}
 		if toRelease != nil {
@@ -26,6 +26,11 @@ type SchemaDescriptor interface {
 	// GetDefaultPrivilegeDescriptor returns the default privileges for this
 	// database.
 	GetDefaultPrivilegeDescriptor() DefaultPrivilegeDescriptor
+
+	// GetFunctions returns the overloads of the user-defined functions in this
+	// schema, keyed by function name. Only schemas backed by a descriptor can
+	// contain functions.
+	GetFunctions() map[string]descpb.SchemaDescriptor_Function
 }
 
 // ResolvedSchemaKind is an enum that represents what kind of schema
@@ -12,6 +12,7 @@ package schemadesc
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/cockroachdb/cockroach/pkg/keys"
@@ -175,12 +176,48 @@ func (desc *immutable) ValidateSelf(vea catalog.ValidationErrorAccumulator) {
 		// Validate the default privilege descriptor.
 		vea.Report(catprivilege.ValidateDefaultPrivileges(*desc.GetDefaultPrivileges()))
 	}
+
+	// Validate the function signatures.
+	names := make([]string, 0, len(desc.Functions))
+	for name := range desc.Functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fn := desc.Functions[name]
+		if fn.Name != name {
+			vea.Report(errors.AssertionFailedf("function %q is mapped under name %q", fn.Name, name))
+		}
+		vea.Report(catalog.ValidateName(fn.Name, "function"))
+		if len(fn.Overloads) == 0 {
+			vea.Report(errors.AssertionFailedf("function %q has no overloads", fn.Name))
+		}
+		for i := range fn.Overloads {
+			o := &fn.Overloads[i]
+			if o.ID == descpb.InvalidID {
+				vea.Report(errors.AssertionFailedf("invalid ID for overload of function %q", fn.Name))
+			}
+			for j := 0; j < i; j++ {
+				if o.HasArgTypes(fn.Overloads[j].ArgTypes) {
+					vea.Report(errors.AssertionFailedf(
+						"overloads [%d] and [%d] of function %q have the same argument types",
+						fn.Overloads[j].ID, o.ID, fn.Name))
+				}
+			}
+		}
+	}
 }
 
 // GetReferencedDescIDs returns the IDs of all descriptors referenced by
 // this descriptor, including itself.
 func (desc *immutable) GetReferencedDescIDs() (catalog.DescriptorIDSet, error) {
-	return catalog.MakeDescriptorIDSet(desc.GetID(), desc.GetParentID()), nil
+	ret := catalog.MakeDescriptorIDSet(desc.GetID(), desc.GetParentID())
+	for _, fn := range desc.Functions {
+		for i := range fn.Overloads {
+			ret.Add(fn.Overloads[i].ID)
+		}
+	}
+	return ret, nil
 }
 
 // ValidateCrossReferences implements the catalog.Descriptor interface.
@@ -223,6 +260,22 @@ func (desc *immutable) ValidateCrossReferences(
 		vea.Report(errors.AssertionFailedf("not present in parent database [%d] schemas mapping",
 			desc.GetParentID()))
 	}
+
+	// Check that the function overloads reference functions of this schema.
+	for name, fn := range desc.Functions {
+		for i := range fn.Overloads {
+			fnDesc, err := vdg.GetFunctionDescriptor(fn.Overloads[i].ID)
+			if err != nil {
+				vea.Report(err)
+				continue
+			}
+			if fnDesc.GetParentSchemaID() != desc.GetID() || fnDesc.GetName() != name {
+				vea.Report(errors.AssertionFailedf(
+					"function overload [%d] of %q is function %q of schema [%d]",
+					fnDesc.GetID(), name, fnDesc.GetName(), fnDesc.GetParentSchemaID()))
+			}
+		}
+	}
 }
 
 // ValidateTxnCommit implements the catalog.Descriptor interface.
@@ -338,6 +391,40 @@ func (desc *Mutable) SetDefaultPrivilegeDescriptor(
 	desc.DefaultPrivileges = defaultPrivilegeDescriptor
 }
 
+// AddFunction adds an overload to the function of the schema with the given
+// name.
+func (desc *Mutable) AddFunction(name string, overload descpb.SchemaDescriptor_FunctionOverload) {
+	if desc.Functions == nil {
+		desc.Functions = make(map[string]descpb.SchemaDescriptor_Function)
+	}
+	fn := desc.Functions[name]
+	fn.Name = name
+	fn.Overloads = append(fn.Overloads, overload)
+	desc.Functions[name] = fn
+}
+
+// RemoveFunction removes the overload with the given ID from the function of
+// the schema with the given name. The function is removed when it has no more
+// overloads.
+func (desc *Mutable) RemoveFunction(name string, id descpb.ID) {
+	fn, ok := desc.Functions[name]
+	if !ok {
+		return
+	}
+	overloads := fn.Overloads[:0]
+	for _, o := range fn.Overloads {
+		if o.ID != id {
+			overloads = append(overloads, o)
+		}
+	}
+	if len(overloads) == 0 {
+		delete(desc.Functions, name)
+		return
+	}
+	fn.Overloads = overloads
+	desc.Functions[name] = fn
+}
+
 // IsSchemaNameValid returns whether the input name is valid for a user defined
 // schema.
 func IsSchemaNameValid(name string) error {
@@ -112,3 +112,8 @@ func (p synthetic) SchemaDesc() *descpb.SchemaDescriptor {
 func (p synthetic) GetDefaultPrivilegeDescriptor() catalog.DefaultPrivilegeDescriptor {
 	return catprivilege.MakeDefaultPrivileges(catprivilege.MakeDefaultPrivilegeDescriptor(descpb.DefaultPrivilegeDescriptor_SCHEMA))
 }
+
+// GetFunctions returns nil: synthetic schemas cannot contain functions.
+func (p synthetic) GetFunctions() map[string]descpb.SchemaDescriptor_Function {
+	return nil
+}
@@ -52,6 +52,8 @@ func ShouldSplitAtDesc(rawDesc *roachpb.Value) bool {
 		return false
 	case *descpb.Descriptor_Schema:
 		return false
+	case *descpb.Descriptor_Function:
+		return false
 	default:
 		panic(errors.AssertionFailedf("unexpected descriptor type %#v", &desc))
 	}
@@ -241,6 +243,11 @@ CREATE TABLE system.web_sessions (
 	// Design outlined in /docs/RFCS/20170908_sql_optimizer_statistics.md
 	// Note: avgSize is a newer statistic than the RFC above. It contains the
 	// average size of the column group in bytes.
+	//
+	// partialPredicate and fullStatisticID are only set for partial statistics,
+	// which are collected on a subset of the rows of the table. They contain the
+	// predicate which selects that subset, and the ID of the full statistic
+	// that the partial statistic extends.
 	TableStatisticsTableSchema = `
 CREATE TABLE system.table_statistics (
 	"tableID"       INT8       NOT NULL,
@@ -253,8 +260,10 @@ CREATE TABLE system.table_statistics (
 	"nullCount"     INT8       NOT NULL,
 	"avgSize"       INT8       NOT NULL DEFAULT 0,
 	histogram       BYTES,
+	"partialPredicate" STRING,
+	"fullStatisticID"  INT8,
 	CONSTRAINT "primary" PRIMARY KEY ("tableID", "statisticID"),
-	FAMILY ("tableID", "statisticID", name, "columnIDs", "createdAt", "rowCount", "distinctCount", "nullCount", "avgSize", histogram)
+	FAMILY ("tableID", "statisticID", name, "columnIDs", "createdAt", "rowCount", "distinctCount", "nullCount", "avgSize", histogram, "partialPredicate", "fullStatisticID")
 );`
 
 	// locations are used to map a locality specified by a node to geographic
@@ -649,6 +658,57 @@ CREATE TABLE system.span_configurations (
     CONSTRAINT check_bounds CHECK (start_key < end_key),
     FAMILY "primary" (start_key, end_key, config)
 )`
+
+	// PublicationsTableSchema stores the publications created with CREATE
+	// PUBLICATION. A publication is scoped to a database and names the tables
+	// whose changes are streamed to logical replication clients.
+	PublicationsTableSchema = `
+CREATE TABLE system.publications (
+    database_id  INT8 NOT NULL,
+    name         STRING NOT NULL,
+    owner        STRING NOT NULL,
+    all_tables   BOOL NOT NULL,
+    table_ids    INT8[] NOT NULL,
+    CONSTRAINT "primary" PRIMARY KEY (database_id, name),
+    FAMILY "primary" (database_id, name, owner, all_tables, table_ids)
+)`
+
+	// ReplicationSlotsTableSchema stores the logical replication slots created
+	// over replication connections, along with the position up to which the
+	// client confirmed it has flushed the changes it received, and the
+	// protected timestamp record which prevents the garbage collection of the
+	// changes after that position.
+	ReplicationSlotsTableSchema = `
+CREATE TABLE system.replication_slots (
+    name                        STRING NOT NULL,
+    database_id                 INT8 NOT NULL,
+    plugin                      STRING NOT NULL,
+    confirmed_flush_lsn         INT8 NOT NULL,
+    created                     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    protected_timestamp_record  UUID,
+    CONSTRAINT "primary" PRIMARY KEY (name),
+    FAMILY "primary" (name, database_id, plugin, confirmed_flush_lsn, created, protected_timestamp_record)
+)`
+
+	// StatementPlanBaselinesTableSchema stores the plan baselines of statement
+	// fingerprints. A baseline is a set of index and join hints which the
+	// optimizer applies to the statements of its fingerprint while it is
+	// accepted.
+	StatementPlanBaselinesTableSchema = `
+CREATE TABLE system.statement_plan_baselines (
+    id           INT8 NOT NULL DEFAULT unique_rowid(),
+    fingerprint  STRING NOT NULL,
+    database     STRING NOT NULL,
+    query        STRING NOT NULL,
+    hints        JSONB NOT NULL,
+    plan         STRING NOT NULL,
+    cost         FLOAT8 NOT NULL,
+    accepted     BOOL NOT NULL,
+    enforced     BOOL NOT NULL,
+    created      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT "primary" PRIMARY KEY (id),
+    FAMILY "primary" (id, fingerprint, database, query, hints, plan, cost, accepted, enforced, created)
+)`
 )
 
 func pk(name string) descpb.IndexDescriptor {
@@ -1280,10 +1340,12 @@ var (
 				{Name: "nullCount", ID: 8, Type: types.Int},
 				{Name: "avgSize", ID: 9, Type: types.Int, DefaultExpr: &zeroIntString},
 				{Name: "histogram", ID: 10, Type: types.Bytes, Nullable: true},
+				{Name: "partialPredicate", ID: 11, Type: types.String, Nullable: true},
+				{Name: "fullStatisticID", ID: 12, Type: types.Int, Nullable: true},
 			},
 			[]descpb.ColumnFamilyDescriptor{
 				{
-					Name: "fam_0_tableID_statisticID_name_columnIDs_createdAt_rowCount_distinctCount_nullCount_avgSize_histogram",
+					Name: "fam_0_tableID_statisticID_name_columnIDs_createdAt_rowCount_distinctCount_nullCount_avgSize_histogram_partialPredicate_fullStatisticID",
 					ID:   0,
 					ColumnNames: []string{
 						"tableID",
@@ -1296,8 +1358,10 @@ var (
 						"nullCount",
 						"avgSize",
 						"histogram",
+						"partialPredicate",
+						"fullStatisticID",
 					},
-					ColumnIDs: []descpb.ColumnID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+					ColumnIDs: []descpb.ColumnID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12},
 				},
 			},
 			descpb.IndexDescriptor{
@@ -2287,6 +2351,100 @@ var (
 			}}
 		},
 	)
+
+	// PublicationsTable is the descriptor for the publications table.
+	PublicationsTable = registerSystemTable(
+		PublicationsTableSchema,
+		systemTable(
+			catconstants.PublicationsTableName,
+			keys.PublicationsTableID,
+			[]descpb.ColumnDescriptor{
+				{Name: "database_id", ID: 1, Type: types.Int},
+				{Name: "name", ID: 2, Type: types.String},
+				{Name: "owner", ID: 3, Type: types.String},
+				{Name: "all_tables", ID: 4, Type: types.Bool},
+				{Name: "table_ids", ID: 5, Type: types.IntArray},
+			},
+			[]descpb.ColumnFamilyDescriptor{
+				{
+					Name:        "primary",
+					ID:          0,
+					ColumnNames: []string{"database_id", "name", "owner", "all_tables", "table_ids"},
+					ColumnIDs:   []descpb.ColumnID{1, 2, 3, 4, 5},
+				},
+			},
+			descpb.IndexDescriptor{
+				Name:           "primary",
+				ID:             1,
+				Unique:         true,
+				KeyColumnNames: []string{"database_id", "name"},
+				KeyColumnDirections: []descpb.IndexDescriptor_Direction{
+					descpb.IndexDescriptor_ASC, descpb.IndexDescriptor_ASC,
+				},
+				KeyColumnIDs: []descpb.ColumnID{1, 2},
+			},
+		))
+
+	// ReplicationSlotsTable is the descriptor for the replication slots table.
+	ReplicationSlotsTable = registerSystemTable(
+		ReplicationSlotsTableSchema,
+		systemTable(
+			catconstants.ReplicationSlotsTableName,
+			keys.ReplicationSlotsTableID,
+			[]descpb.ColumnDescriptor{
+				{Name: "name", ID: 1, Type: types.String},
+				{Name: "database_id", ID: 2, Type: types.Int},
+				{Name: "plugin", ID: 3, Type: types.String},
+				{Name: "confirmed_flush_lsn", ID: 4, Type: types.Int},
+				{Name: "created", ID: 5, Type: types.TimestampTZ, DefaultExpr: &nowTZString},
+				{Name: "protected_timestamp_record", ID: 6, Type: types.Uuid, Nullable: true},
+			},
+			[]descpb.ColumnFamilyDescriptor{
+				{
+					Name: "primary",
+					ID:   0,
+					ColumnNames: []string{
+						"name", "database_id", "plugin", "confirmed_flush_lsn", "created",
+						"protected_timestamp_record",
+					},
+					ColumnIDs: []descpb.ColumnID{1, 2, 3, 4, 5, 6},
+				},
+			},
+			pk("name"),
+		))
+
+	// StatementPlanBaselinesTable is the descriptor for the statement plan
+	// baselines table.
+	StatementPlanBaselinesTable = registerSystemTable(
+		StatementPlanBaselinesTableSchema,
+		systemTable(
+			catconstants.StatementPlanBaselinesTableName,
+			keys.StatementPlanBaselinesTableID,
+			[]descpb.ColumnDescriptor{
+				{Name: "id", ID: 1, Type: types.Int, DefaultExpr: &uniqueRowIDString},
+				{Name: "fingerprint", ID: 2, Type: types.String},
+				{Name: "database", ID: 3, Type: types.String},
+				{Name: "query", ID: 4, Type: types.String},
+				{Name: "hints", ID: 5, Type: types.Jsonb},
+				{Name: "plan", ID: 6, Type: types.String},
+				{Name: "cost", ID: 7, Type: types.Float},
+				{Name: "accepted", ID: 8, Type: types.Bool},
+				{Name: "enforced", ID: 9, Type: types.Bool},
+				{Name: "created", ID: 10, Type: types.TimestampTZ, DefaultExpr: &nowTZString},
+			},
+			[]descpb.ColumnFamilyDescriptor{
+				{
+					Name: "primary",
+					ID:   0,
+					ColumnNames: []string{
+						"id", "fingerprint", "database", "query", "hints", "plan", "cost",
+						"accepted", "enforced", "created",
+					},
+					ColumnIDs: []descpb.ColumnID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+				},
+			},
+			pk("id"),
+		))
 )
 
 type descRefByName struct {
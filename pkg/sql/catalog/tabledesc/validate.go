@@ -372,6 +372,7 @@ func (desc *wrapper) ValidateSelf(vea catalog.ValidationErrorAccumulator) {
 			desc.validateColumnFamilies(columnIDs),
 			desc.validateCheckConstraints(columnIDs),
 			desc.validateUniqueWithoutIndexConstraints(columnIDs),
+			desc.validateTriggers(),
 			desc.validateTableIndexes(columnNames),
 			desc.validatePartitioning(),
 		}
@@ -756,6 +757,29 @@ func (desc *wrapper) validateUniqueWithoutIndexConstraints(
 	return nil
 }
 
+// validateTriggers validates that row-level triggers are well formed. Checks
+// include validating the trigger names and events.
+func (desc *wrapper) validateTriggers() error {
+	names := make(map[string]struct{}, len(desc.Triggers))
+	for i := range desc.Triggers {
+		t := &desc.Triggers[i]
+		if err := catalog.ValidateName(t.Name, "trigger"); err != nil {
+			return err
+		}
+		if _, ok := names[t.Name]; ok {
+			return errors.Newf("duplicate trigger name: %q", t.Name)
+		}
+		names[t.Name] = struct{}{}
+		if len(t.Events) == 0 {
+			return errors.Newf("trigger %q has no events", t.Name)
+		}
+		if t.FunctionName == "" {
+			return errors.Newf("trigger %q has no function", t.Name)
+		}
+	}
+	return nil
+}
+
 // validateTableIndexes validates that indexes are well formed. Checks include
 // validating the columns involved in the index, verifying the index names and
 // IDs are unique, and the family of the primary key is 0. This does not check
@@ -349,6 +349,9 @@ type ValidationDescGetter interface {
 	// GetTypeDescriptor returns the corresponding TypeDescriptor or an error instead.
 	GetTypeDescriptor(id descpb.ID) (TypeDescriptor, error)
 
+	// GetFunctionDescriptor returns the corresponding FunctionDescriptor or an error instead.
+	GetFunctionDescriptor(id descpb.ID) (FunctionDescriptor, error)
+
 	// Seals this interface.
 	sealed()
 }
@@ -402,6 +405,17 @@ func (vdg *validationDescGetterImpl) GetTypeDescriptor(id descpb.ID) (TypeDescri
 	return descriptor, err
 }
 
+// GetFunctionDescriptor implements the ValidationDescGetter interface.
+func (vdg *validationDescGetterImpl) GetFunctionDescriptor(
+	id descpb.ID,
+) (FunctionDescriptor, error) {
+	desc, found := vdg.Descriptors[id]
+	if !found || desc == nil {
+		return nil, WrapFunctionDescRefErr(id, ErrReferencedDescriptorNotFound)
+	}
+	return AsFunctionDescriptor(desc)
+}
+
 func (vdg *validationDescGetterImpl) addNamespaceEntries(
 	ctx context.Context, descriptors []Descriptor, maybeBatchDescGetter DescGetter,
 ) (err error) {
@@ -535,6 +549,11 @@ func validateNamespace(
 	if desc.GetID() == keys.NamespaceTableID || desc.GetID() == keys.DeprecatedNamespaceTableID {
 		return
 	}
+	// Functions are resolved through the schema they belong to and have no
+	// namespace entry.
+	if desc.DescriptorType() == Function {
+		return
+	}
 
 	id := namespace[descpb.NameInfo{
 		ParentID:       desc.GetParentID(),
@@ -35,6 +35,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/colmem"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/invertedidx"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondatapb"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
@@ -174,10 +175,15 @@ func supportedNatively(spec *execinfrapb.ProcessorSpec) error {
 
 	case spec.Core.JoinReader != nil:
 		if !spec.Core.JoinReader.IsIndexJoin() {
-			return errLookupJoinUnsupported
+			if err := colfetcher.CheckLookupJoinSupported(spec.Core.JoinReader, spec.Input[0].ColumnTypes); err != nil {
+				return errors.Wrap(err, "lookup join reader is unsupported in vectorized")
+			}
 		}
 		return nil
 
+	case spec.Core.InvertedJoiner != nil:
+		return colfetcher.CheckInvertedJoinSupported(spec.Core.InvertedJoiner)
+
 	case spec.Core.Filterer != nil:
 		return nil
 
@@ -234,8 +240,21 @@ func supportedNatively(spec *execinfrapb.ProcessorSpec) error {
 	}
 }
 
+// checkLookupJoinsEnabled returns an error if spec describes a lookup or an
+// inverted join while the vectorized implementations of those joins have been
+// disabled, in which case the row-based processors are wrapped instead.
+func checkLookupJoinsEnabled(flowCtx *execinfra.FlowCtx, spec *execinfrapb.ProcessorSpec) error {
+	isLookupJoin := spec.Core.JoinReader != nil && !spec.Core.JoinReader.IsIndexJoin()
+	if (isLookupJoin || spec.Core.InvertedJoiner != nil) &&
+		!colfetcher.VectorizedLookupJoinsEnabled.Get(&flowCtx.Cfg.Settings.SV) {
+		return errLookupJoinsDisabled
+	}
+	return nil
+}
+
 var (
 	errCoreUnsupportedNatively        = errors.New("unsupported processor core")
+	errLookupJoinsDisabled            = errors.New("vectorized lookup and inverted joins are disabled")
 	errLocalPlanNodeWrap              = errors.New("LocalPlanNode core needs to be wrapped")
 	errMetadataTestSenderWrap         = errors.New("core.MetadataTestSender is not supported")
 	errMetadataTestReceiverWrap       = errors.New("core.MetadataTestReceiver is not supported")
@@ -250,7 +269,6 @@ var (
 	errSampleAggregatorWrap           = errors.New("core.SampleAggregator is not supported (not an execinfra.RowSource)")
 	errExperimentalWrappingProhibited = errors.New("wrapping for non-JoinReader and non-LocalPlanNode cores is prohibited in vectorize=experimental_always")
 	errWrappedCast                    = errors.New("mismatched types in NewColOperator and unsupported casts")
-	errLookupJoinUnsupported          = errors.New("lookup join reader is unsupported in vectorized")
 )
 
 func canWrap(mode sessiondatapb.VectorizeExecMode, spec *execinfrapb.ProcessorSpec) error {
@@ -447,6 +465,85 @@ func (r opResult) createDiskBackedSort(
 	)
 }
 
+// createDiskBackedHashJoiner creates an in-memory hash joiner with the given
+// spec that falls back to the external hash joiner once its memory limit is
+// reached.
+func (r opResult) createDiskBackedHashJoiner(
+	ctx context.Context,
+	flowCtx *execinfra.FlowCtx,
+	args *colexecargs.NewColOperatorArgs,
+	processorID int32,
+	opName string,
+	hjSpec colexecjoin.HashJoinerSpec,
+	leftInput, rightInput colexecop.Operator,
+	factory coldata.ColumnFactory,
+) colexecop.ResettableOperator {
+	hashJoinerMemAccount, hashJoinerMemMonitorName := args.MonitorRegistry.CreateMemAccountForSpillStrategy(
+		ctx, flowCtx, opName, processorID,
+	)
+	hashJoinerUnlimitedAllocator := colmem.NewAllocator(
+		ctx, args.MonitorRegistry.CreateUnlimitedMemAccount(ctx, flowCtx, opName, processorID), factory,
+	)
+	inMemoryHashJoiner := colexecjoin.NewHashJoiner(
+		colmem.NewAllocator(ctx, hashJoinerMemAccount, factory),
+		hashJoinerUnlimitedAllocator, hjSpec, leftInput, rightInput,
+		colexecjoin.HashJoinerInitialNumBuckets,
+	)
+	if args.TestingKnobs.DiskSpillingDisabled {
+		// We will not be creating a disk-backed hash joiner because we're
+		// running a test that explicitly asked for only in-memory hash
+		// joiner.
+		return inMemoryHashJoiner
+	}
+	ehjOpName := "external-" + opName
+	diskAccount := args.MonitorRegistry.CreateDiskAccount(ctx, flowCtx, ehjOpName, processorID)
+	return colexec.NewTwoInputDiskSpiller(
+		leftInput, rightInput, inMemoryHashJoiner.(colexecop.BufferingInMemoryOperator),
+		hashJoinerMemMonitorName,
+		func(inputOne, inputTwo colexecop.Operator) colexecop.Operator {
+			unlimitedAllocator := colmem.NewAllocator(
+				ctx, args.MonitorRegistry.CreateUnlimitedMemAccount(ctx, flowCtx, ehjOpName, processorID), factory,
+			)
+			ehj := colexec.NewExternalHashJoiner(
+				unlimitedAllocator,
+				flowCtx,
+				args,
+				hjSpec,
+				inputOne, inputTwo,
+				r.makeDiskBackedSorterConstructor(ctx, flowCtx, args, ehjOpName, factory),
+				diskAccount,
+			)
+			r.ToClose = append(r.ToClose, ehj.(colexecop.Closer))
+			return ehj
+		},
+		args.TestingKnobs.SpillingCallbackFn,
+	).(colexecop.ResettableOperator)
+}
+
+// makeInputChunkArgs returns the arguments for a spilling queue that is used
+// by the lookup and inverted joiners to buffer a chunk of input rows. Note
+// that the types are populated by the joiners themselves.
+func (r opResult) makeInputChunkArgs(
+	ctx context.Context,
+	flowCtx *execinfra.FlowCtx,
+	args *colexecargs.NewColOperatorArgs,
+	opName string,
+	processorID int32,
+	factory coldata.ColumnFactory,
+) *colexecutils.NewSpillingQueueArgs {
+	// We need to create a separate memory account for the spilling queue
+	// because it looks at how much memory it has already used in order to
+	// decide when to spill to disk.
+	memAccount := args.MonitorRegistry.CreateUnlimitedMemAccount(ctx, flowCtx, opName, processorID)
+	return &colexecutils.NewSpillingQueueArgs{
+		UnlimitedAllocator: colmem.NewAllocator(ctx, memAccount, factory),
+		MemoryLimit:        execinfra.GetWorkMemLimit(flowCtx),
+		DiskQueueCfg:       args.DiskQueueCfg,
+		FDSemaphore:        args.FDSemaphore,
+		DiskAcc:            args.MonitorRegistry.CreateDiskAccount(ctx, flowCtx, opName, processorID),
+	}
+}
+
 // makeDiskBackedSorterConstructor creates a colexec.DiskBackedSorterConstructor
 // that can be used by the hash-based partitioner.
 // NOTE: unless DelegateFDAcquisitions testing knob is set to true, it is up to
@@ -668,7 +765,11 @@ func NewColOperator(
 	core := &spec.Core
 	post := &spec.Post
 
-	if err = supportedNatively(spec); err != nil {
+	err = supportedNatively(spec)
+	if err == nil {
+		err = checkLookupJoinsEnabled(flowCtx, spec)
+	}
+	if err != nil {
 		inputTypes := make([][]*types.T, len(spec.Input))
 		for inputIdx, input := range spec.Input {
 			inputTypes[inputIdx] = make([]*types.T, len(input.ColumnTypes))
@@ -737,9 +838,6 @@ func NewColOperator(
 			if err := checkNumIn(inputs, 1); err != nil {
 				return r, err
 			}
-			if !core.JoinReader.IsIndexJoin() {
-				return r, errors.AssertionFailedf("lookup join reader is unsupported in vectorized")
-			}
 			// We have to create a separate account in order for the cFetcher to
 			// be able to precisely track the size of its output batch. This
 			// memory account is "streaming" in its nature, so we create an
@@ -751,10 +849,9 @@ func NewColOperator(
 				ctx, flowCtx, "kvfetcher" /* opName */, spec.ProcessorID,
 			)
 			var streamerBudgetAcc *mon.BoundAccount
-			// We have an index join, and when the ordering doesn't have to be
-			// maintained, we might use the Streamer API which requires a
-			// separate memory account that is bound to an unlimited memory
-			// monitor.
+			// When the ordering doesn't have to be maintained, we might use the
+			// Streamer API which requires a separate memory account that is
+			// bound to an unlimited memory monitor.
 			if !core.JoinReader.MaintainOrdering {
 				streamerBudgetAcc = args.MonitorRegistry.CreateUnlimitedMemAccount(
 					ctx, flowCtx, "streamer" /* opName */, spec.ProcessorID,
@@ -762,16 +859,96 @@ func NewColOperator(
 			}
 			inputTypes := make([]*types.T, len(spec.Input[0].ColumnTypes))
 			copy(inputTypes, spec.Input[0].ColumnTypes)
-			indexJoinOp, err := colfetcher.NewColIndexJoin(
+			if core.JoinReader.IsIndexJoin() {
+				indexJoinOp, err := colfetcher.NewColIndexJoin(
+					ctx, getStreamingAllocator(ctx, args),
+					colmem.NewAllocator(ctx, cFetcherMemAcc, factory),
+					kvFetcherMemAcc, streamerBudgetAcc, flowCtx, args.ExprHelper,
+					inputs[0].Root, core.JoinReader, post, inputTypes,
+				)
+				if err != nil {
+					return r, err
+				}
+				result.finishScanPlanning(indexJoinOp, indexJoinOp.ResultTypes)
+				break
+			}
+			// Each chunk of input rows is joined with the looked up rows via a
+			// hash joiner that is reset after every chunk.
+			newJoiner := func(
+				probe, build colexecop.Operator, buildTypes []*types.T, buildEqCols []uint32,
+			) colexecop.ResettableOperator {
+				hjSpec := colexecjoin.MakeHashJoinerSpec(
+					core.JoinReader.Type,
+					core.JoinReader.LookupColumns,
+					buildEqCols,
+					inputTypes,
+					buildTypes,
+					core.JoinReader.LookupColumnsAreKey,
+				)
+				return result.createDiskBackedHashJoiner(
+					ctx, flowCtx, args, spec.ProcessorID, "lookup-joiner", hjSpec,
+					probe, build, factory,
+				)
+			}
+			lookupJoinOp, onExpr, err := colfetcher.NewColLookupJoin(
 				ctx, getStreamingAllocator(ctx, args),
 				colmem.NewAllocator(ctx, cFetcherMemAcc, factory),
 				kvFetcherMemAcc, streamerBudgetAcc, flowCtx, args.ExprHelper,
 				inputs[0].Root, core.JoinReader, post, inputTypes,
+				result.makeInputChunkArgs(ctx, flowCtx, args, "lookup-joiner-input", spec.ProcessorID, factory),
+				newJoiner,
 			)
 			if err != nil {
 				return r, err
 			}
-			result.finishScanPlanning(indexJoinOp, indexJoinOp.ResultTypes)
+			result.finishScanPlanning(lookupJoinOp, lookupJoinOp.ResultTypes)
+			if !onExpr.Empty() {
+				if err = result.planAndMaybeWrapFilter(
+					ctx, flowCtx, args, spec.ProcessorID, onExpr, factory,
+				); err != nil {
+					return r, err
+				}
+			}
+
+		case core.InvertedJoiner != nil:
+			if err := checkNumIn(inputs, 1); err != nil {
+				return r, err
+			}
+			opName := "inverted-joiner"
+			cFetcherMemAcc := args.MonitorRegistry.CreateUnlimitedMemAccount(
+				ctx, flowCtx, "cfetcher" /* opName */, spec.ProcessorID,
+			)
+			kvFetcherMemAcc := args.MonitorRegistry.CreateUnlimitedMemAccount(
+				ctx, flowCtx, "kvfetcher" /* opName */, spec.ProcessorID,
+			)
+			// The index rows are buffered in a spilling buffer which looks at
+			// how much memory it has already used in order to decide when to
+			// spill to disk, so it needs a separate unlimited memory account.
+			indexRowsMemAcc := args.MonitorRegistry.CreateUnlimitedMemAccount(
+				ctx, flowCtx, opName+"-index-rows", spec.ProcessorID,
+			)
+			inputTypes := make([]*types.T, len(spec.Input[0].ColumnTypes))
+			copy(inputTypes, spec.Input[0].ColumnTypes)
+			invertedJoinOp, onExpr, err := colfetcher.NewColInvertedJoin(
+				ctx, getStreamingAllocator(ctx, args),
+				colmem.NewAllocator(ctx, cFetcherMemAcc, factory),
+				colmem.NewAllocator(ctx, indexRowsMemAcc, factory),
+				kvFetcherMemAcc, flowCtx, args.ExprHelper,
+				inputs[0].Root, core.InvertedJoiner, post, inputTypes,
+				result.makeInputChunkArgs(ctx, flowCtx, args, opName+"-input", spec.ProcessorID, factory),
+				invertedidx.NewDatumsToInvertedExpr,
+			)
+			if err != nil {
+				return r, err
+			}
+			result.finishScanPlanning(invertedJoinOp, invertedJoinOp.ResultTypes)
+			if !onExpr.Empty() {
+				if err = result.planAndMaybeWrapFilter(
+					ctx, flowCtx, args, spec.ProcessorID, onExpr, factory,
+				); err != nil {
+					return r, err
+				}
+			}
 
 		case core.Filterer != nil:
 			if err := checkNumIn(inputs, 1); err != nil {
@@ -1054,13 +1231,6 @@ func NewColOperator(
 				)
 				result.ToClose = append(result.ToClose, result.Root.(colexecop.Closer))
 			} else {
-				opName := "hash-joiner"
-				hashJoinerMemAccount, hashJoinerMemMonitorName := args.MonitorRegistry.CreateMemAccountForSpillStrategy(
-					ctx, flowCtx, opName, spec.ProcessorID,
-				)
-				hashJoinerUnlimitedAllocator := colmem.NewAllocator(
-					ctx, args.MonitorRegistry.CreateUnlimitedMemAccount(ctx, flowCtx, opName, spec.ProcessorID), factory,
-				)
 				hjSpec := colexecjoin.MakeHashJoinerSpec(
 					core.HashJoiner.Type,
 					core.HashJoiner.LeftEqColumns,
@@ -1069,42 +1239,10 @@ func NewColOperator(
 					rightTypes,
 					core.HashJoiner.RightEqColumnsAreKey,
 				)
-
-				inMemoryHashJoiner := colexecjoin.NewHashJoiner(
-					colmem.NewAllocator(ctx, hashJoinerMemAccount, factory),
-					hashJoinerUnlimitedAllocator, hjSpec, inputs[0].Root, inputs[1].Root,
-					colexecjoin.HashJoinerInitialNumBuckets,
+				result.Root = result.createDiskBackedHashJoiner(
+					ctx, flowCtx, args, spec.ProcessorID, "hash-joiner", hjSpec,
+					inputs[0].Root, inputs[1].Root, factory,
 				)
-				if args.TestingKnobs.DiskSpillingDisabled {
-					// We will not be creating a disk-backed hash joiner because
-					// we're running a test that explicitly asked for only
-					// in-memory hash joiner.
-					result.Root = inMemoryHashJoiner
-				} else {
-					opName := "external-hash-joiner"
-					diskAccount := args.MonitorRegistry.CreateDiskAccount(ctx, flowCtx, opName, spec.ProcessorID)
-					result.Root = colexec.NewTwoInputDiskSpiller(
-						inputs[0].Root, inputs[1].Root, inMemoryHashJoiner.(colexecop.BufferingInMemoryOperator),
-						hashJoinerMemMonitorName,
-						func(inputOne, inputTwo colexecop.Operator) colexecop.Operator {
-							unlimitedAllocator := colmem.NewAllocator(
-								ctx, args.MonitorRegistry.CreateUnlimitedMemAccount(ctx, flowCtx, opName, spec.ProcessorID), factory,
-							)
-							ehj := colexec.NewExternalHashJoiner(
-								unlimitedAllocator,
-								flowCtx,
-								args,
-								hjSpec,
-								inputOne, inputTwo,
-								result.makeDiskBackedSorterConstructor(ctx, flowCtx, args, opName, factory),
-								diskAccount,
-							)
-							result.ToClose = append(result.ToClose, ehj.(colexecop.Closer))
-							return ehj
-						},
-						args.TestingKnobs.SpillingCallbackFn,
-					)
-				}
 			}
 
 			result.ColumnTypes = core.HashJoiner.Type.MakeOutputTypes(leftTypes, rightTypes)
@@ -587,6 +587,9 @@ func (o *mergeJoinBase) Reset(ctx context.Context) {
 	o.proberState.lBatch = nil
 	o.proberState.rBatch = nil
 	o.resetBuilderCrossProductState()
+	// Reset the CloserHelper so that the merge joiner may be closed again
+	// (e.g. it is closed and then reused by the external hash joiner).
+	o.CloserHelper.Reset()
 }
 
 func (o *mergeJoinBase) Init(ctx context.Context) {
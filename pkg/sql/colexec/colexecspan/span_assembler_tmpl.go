@@ -81,6 +81,46 @@ func NewColSpanAssembler(
 	return &spanAssemblerWithColFamily{spanAssemblerBase: *base}
 }
 
+// NewColLookupSpanAssembler returns a ColSpanAssembler operator that is able to
+// generate lookup spans for a lookup join from input batches. Each span covers
+// all the index entries whose key starts with the values of the lookup columns
+// of an input row.
+// - lookupCols contains the ordinals of the input columns that correspond to
+// the leading key columns of the index.
+func NewColLookupSpanAssembler(
+	codec keys.SQLCodec,
+	allocator *colmem.Allocator,
+	table catalog.TableDescriptor,
+	index catalog.Index,
+	inputTypes []*types.T,
+	lookupCols []uint32,
+) ColSpanAssembler {
+	base := spanAssemblerPool.Get().(*spanAssemblerBase)
+	keyPrefix := rowenc.MakeIndexKeyPrefix(codec, table.GetID(), index.GetID())
+	base.scratchKey = append(base.scratchKey[:0], keyPrefix...)
+	base.prefixLength = len(keyPrefix)
+	base.allocator = allocator
+
+	// Add span encoders to encode each lookup column as bytes. Since the lookup
+	// columns might only constrain a prefix of the index key, the spans are
+	// never split into column family spans.
+	for i, colIdx := range lookupCols {
+		asc := index.GetKeyColumnDirection(i) == descpb.IndexDescriptor_ASC
+		base.spanEncoders = append(base.spanEncoders, newSpanEncoder(allocator, inputTypes[colIdx], asc, int(colIdx)))
+	}
+	if cap(base.spanCols) < len(base.spanEncoders) {
+		base.spanCols = make([]*coldata.Bytes, len(base.spanEncoders))
+	} else {
+		base.spanCols = base.spanCols[:len(base.spanEncoders)]
+	}
+
+	// Account for the memory currently in use.
+	base.spansBytes = int64(cap(base.spans)) * spanSize
+	base.allocator.AdjustMemoryUsage(base.spansBytes)
+
+	return &spanAssemblerNoColFamily{spanAssemblerBase: *base}
+}
+
 var spanAssemblerPool = sync.Pool{
 	New: func() interface{} {
 		return &spanAssemblerBase{}
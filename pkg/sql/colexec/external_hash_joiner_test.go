@@ -176,6 +176,81 @@ func TestExternalHashJoinerFallbackToSortMergeJoin(t *testing.T) {
 	require.Equal(t, 0, sem.GetCount())
 }
 
+// TestExternalHashJoinerReset tests that the disk-backed hash joiner can be
+// reused after a Reset once it has spilled to disk and has fallen back to
+// using sort + merge join.
+func TestExternalHashJoinerReset(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	evalCtx := tree.MakeTestingEvalContext(st)
+	defer evalCtx.Stop(ctx)
+	flowCtx := &execinfra.FlowCtx{
+		EvalCtx: &evalCtx,
+		Cfg: &execinfra.ServerConfig{
+			Settings: st,
+			TestingKnobs: execinfra.TestingKnobs{
+				ForceDiskSpill: true,
+			},
+		},
+		DiskMonitor: testDiskMonitor,
+	}
+	sourceTypes := []*types.T{types.Int}
+	batch := testAllocator.NewMemBatchWithMaxCapacity(sourceTypes)
+	// We don't need to set the data since zero values in the columns work.
+	batch.SetLength(coldata.BatchSize())
+	nBatches := 2
+	leftSource := colexectestutils.NewFiniteBatchSource(testAllocator, batch, sourceTypes, nBatches)
+	rightSource := colexectestutils.NewFiniteBatchSource(testAllocator, batch, sourceTypes, nBatches)
+	tc := &joinTestCase{
+		joinType:     descpb.InnerJoin,
+		leftTypes:    sourceTypes,
+		leftOutCols:  []uint32{0},
+		leftEqCols:   []uint32{0},
+		rightTypes:   sourceTypes,
+		rightOutCols: []uint32{0},
+		rightEqCols:  []uint32{0},
+	}
+	tc.init()
+	spec := createSpecForHashJoiner(tc)
+	var numSpills int
+	queueCfg, cleanup := colcontainerutils.NewTestingDiskQueueCfg(t, true /* inMem */)
+	defer cleanup()
+	var monitorRegistry colexecargs.MonitorRegistry
+	defer monitorRegistry.Close(ctx)
+	sem := colexecop.NewTestingSemaphore(externalHJMinPartitions)
+	hj, closers, err := createDiskBackedHashJoiner(
+		ctx, flowCtx, spec, []colexecop.Operator{leftSource, rightSource},
+		func() { numSpills++ }, queueCfg,
+		1,    /* numForcedRepartitions */
+		true, /* delegateFDAcquisitions */
+		sem, &monitorRegistry,
+	)
+	require.NoError(t, err)
+	hj.Init(ctx)
+	expectedTuplesCount := nBatches * nBatches * coldata.BatchSize() * coldata.BatchSize()
+	const numRuns = 3
+	for run := 1; run <= numRuns; run++ {
+		if run > 1 {
+			leftSource.Reset(nBatches)
+			rightSource.Reset(nBatches)
+			hj.(colexecop.Resetter).Reset(ctx)
+		}
+		actualTuplesCount := 0
+		for b := hj.Next(); b.Length() > 0; b = hj.Next() {
+			actualTuplesCount += b.Length()
+		}
+		require.Equal(t, run, numSpills)
+		require.Equal(t, expectedTuplesCount, actualTuplesCount)
+		require.Equal(t, 0, sem.GetCount())
+	}
+	for _, c := range closers {
+		require.NoError(t, c.Close())
+	}
+	require.Equal(t, 0, sem.GetCount())
+}
+
 // newIntColumns returns nCols columns of types.Int with increasing values
 // starting at 0.
 func newIntColumns(nCols int, length int) []coldata.Vec {
@@ -138,6 +138,9 @@ type hashBasedPartitioner struct {
 	partitioners      []*colcontainer.PartitionedDiskQueue
 	partitionedInputs []*partitionerToOperator
 	tupleDistributor  *colexechash.TupleHashDistributor
+	// partitionerCreator creates a new partitioned disk queue for the input
+	// with the given types. It is used to replace the partitioners on Reset.
+	partitionerCreator func([]*types.T) *colcontainer.PartitionedDiskQueue
 	// maxNumberActivePartitions determines the maximum number of active
 	// partitions that the operator is allowed to have. This number is computed
 	// semi-dynamically and will influence the choice of numBuckets value.
@@ -186,6 +189,7 @@ type hashBasedPartitioner struct {
 }
 
 var _ colexecop.ClosableOperator = &hashBasedPartitioner{}
+var _ colexecop.ResettableOperator = &hashBasedPartitioner{}
 
 // hbpPartitionInfo is a helper struct that tracks the memory usage of a
 // partition. Note that if the hash-based partitioner has two inputs, we take
@@ -236,13 +240,16 @@ func newHashBasedPartitioner(
 		// acquiring.
 		partitionedDiskQueueSemaphore = nil
 	}
+	partitionerCreator := func(typs []*types.T) *colcontainer.PartitionedDiskQueue {
+		return colcontainer.NewPartitionedDiskQueue(
+			typs, diskQueueCfg, partitionedDiskQueueSemaphore, colcontainer.PartitionerStrategyDefault, diskAcc,
+		)
+	}
 	numInputs := len(inputs)
 	partitioners := make([]*colcontainer.PartitionedDiskQueue, numInputs)
 	partitionedInputs := make([]*partitionerToOperator, numInputs)
 	for i := range inputs {
-		partitioners[i] = colcontainer.NewPartitionedDiskQueue(
-			inputTypes[i], diskQueueCfg, partitionedDiskQueueSemaphore, colcontainer.PartitionerStrategyDefault, diskAcc,
-		)
+		partitioners[i] = partitionerCreator(inputTypes[i])
 		partitionedInputs[i] = newPartitionerToOperator(
 			unlimitedAllocator, inputTypes[i], partitioners[i],
 		)
@@ -273,6 +280,7 @@ func newHashBasedPartitioner(
 		maxPartitionSizeToProcessUsingMain: maxPartitionSizeToProcessUsingMain,
 		partitioners:                       partitioners,
 		partitionedInputs:                  partitionedInputs,
+		partitionerCreator:                 partitionerCreator,
 		maxNumberActivePartitions:          maxNumberActivePartitions,
 		// In the initial partitioning state we will use all available
 		// partitions fairly among all inputs.
@@ -629,6 +637,42 @@ StateChanged:
 	}
 }
 
+func (op *hashBasedPartitioner) Reset(ctx context.Context) {
+	for i := range op.inputs {
+		if r, ok := op.inputs[i].(colexecop.Resetter); ok {
+			r.Reset(ctx)
+		}
+	}
+	op.state = hbpInitialPartitioning
+	// The partitions might not have been fully processed, so we close the
+	// partitioners in order to remove all of them from disk and replace the
+	// partitioners with fresh ones.
+	for i := range op.inputs {
+		if err := op.partitioners[i].Close(ctx); err != nil {
+			colexecerror.InternalError(err)
+		}
+		op.partitioners[i] = op.partitionerCreator(op.inputTypes[i])
+		op.partitionedInputs[i].partitioner = op.partitioners[i]
+	}
+	if !op.testingKnobs.delegateFDAcquisitions && op.fdState.acquiredFDs > 0 {
+		op.fdState.fdSemaphore.Release(op.fdState.acquiredFDs)
+		op.fdState.acquiredFDs = 0
+	}
+	// Reset the CloserHelper so that the partitioner may be closed again.
+	op.CloserHelper.Reset()
+	for partitionIdx := range op.partitionsToProcessUsingMain {
+		delete(op.partitionsToProcessUsingMain, partitionIdx)
+	}
+	op.partitionsToProcessUsingFallback = op.partitionsToProcessUsingFallback[:0]
+	op.partitionIdxOffset = 0
+	op.numRepartitions = 0
+	op.numBuckets = op.maxNumberActivePartitions / len(op.inputs)
+	op.tupleDistributor.InitHashValue = colexechash.DefaultInitHashValue + 1
+	op.tupleDistributor.ResetNumOutputs(op.numBuckets)
+	// Note that the "main" and the "fallback" operators are reset before
+	// processing each partition, so we don't need to reset them here.
+}
+
 func (op *hashBasedPartitioner) Close() error {
 	if !op.CloserHelper.Close() {
 		return nil
@@ -207,6 +207,117 @@ func populateTableArgsLegacy(
 	return args, neededColumns, resolver.HydrateTypeSlice(ctx, args.typs)
 }
 
+// populateTableArgsForJoin is similar to populateTableArgsLegacy, but it is
+// used by the operators that join their input with the rows fetched from an
+// index. The given post-processing spec and ON expression refer to the input
+// columns followed by tableCols (the latter only when includeTableCols is
+// true), and only the columns of the table that are actually needed are
+// fetched. The column references in post and in the returned ON expression
+// are adjusted accordingly.
+// - requiredCols is a set of ordinals among tableCols that must be fetched
+// regardless of whether post and onExpr refer to them.
+// - neededColumns is a set containing the ordinals among tableCols of all
+// columns that need to be fetched.
+func populateTableArgsForJoin(
+	ctx context.Context,
+	flowCtx *execinfra.FlowCtx,
+	table catalog.TableDescriptor,
+	index catalog.Index,
+	tableCols []catalog.Column,
+	invertedCol catalog.Column,
+	inputTypes []*types.T,
+	includeTableCols bool,
+	requiredCols util.FastIntSet,
+	post *execinfrapb.PostProcessSpec,
+	onExpr execinfrapb.Expression,
+	helper *colexecargs.ExprHelper,
+) (_ *cFetcherTableArgs, neededColumns util.FastIntSet, _ execinfrapb.Expression, _ error) {
+	args := cFetcherTableArgsPool.Get().(*cFetcherTableArgs)
+	numInputCols := len(inputTypes)
+	allTypes := make([]*types.T, 0, numInputCols+len(tableCols))
+	allTypes = append(allTypes, inputTypes...)
+	for _, col := range tableCols {
+		allTypes = append(allTypes, col.GetType())
+	}
+	outputTypes := inputTypes
+	if includeTableCols {
+		outputTypes = allTypes
+	}
+
+	var err error
+	// Make sure that the render expressions and the ON expression are
+	// deserialized right away so that we can find the columns they refer to.
+	for i := range post.RenderExprs {
+		post.RenderExprs[i].LocalExpr, err = helper.ProcessExpr(post.RenderExprs[i], flowCtx.EvalCtx, outputTypes)
+		if err != nil {
+			return args, neededColumns, onExpr, err
+		}
+	}
+	if !onExpr.Empty() {
+		onExpr.LocalExpr, err = helper.ProcessExpr(onExpr, flowCtx.EvalCtx, allTypes)
+		if err != nil {
+			return args, neededColumns, onExpr, err
+		}
+	}
+
+	// Now find the set of the table columns that are needed by the
+	// post-processing spec and the ON expression.
+	neededColumns = requiredCols.Copy()
+	if includeTableCols {
+		neededOutputCols := getNeededColumns(post, len(outputTypes))
+		for i, ok := neededOutputCols.Next(numInputCols); ok; i, ok = neededOutputCols.Next(i + 1) {
+			neededColumns.Add(i - numInputCols)
+		}
+	}
+	if !onExpr.Empty() {
+		visitor := ivarExpressionVisitor{ivarSeen: make([]bool, len(allTypes))}
+		_, _ = tree.WalkExpr(visitor, onExpr.LocalExpr)
+		for i := numInputCols; i < len(allTypes); i++ {
+			if visitor.ivarSeen[i] {
+				neededColumns.Add(i - numInputCols)
+			}
+		}
+	}
+
+	// Prune away the columns that aren't needed.
+	cols := args.cols[:0]
+	idxMap := make([]int, len(allTypes))
+	for i := 0; i < numInputCols; i++ {
+		idxMap[i] = i
+	}
+	for idx, ok := neededColumns.Next(0); ok; idx, ok = neededColumns.Next(idx + 1) {
+		col := tableCols[idx]
+		if invertedCol != nil && col.GetID() == invertedCol.GetID() {
+			col = invertedCol
+		}
+		idxMap[numInputCols+idx] = numInputCols + len(cols)
+		cols = append(cols, col)
+	}
+	if includeTableCols && len(cols) != len(tableCols) {
+		remapPostProcessSpec(post, idxMap, flowCtx.PreserveFlowSpecs)
+	}
+	if !onExpr.Empty() {
+		onExpr.LocalExpr = physicalplan.RemapIVarsInTypedExpr(onExpr.LocalExpr, idxMap)
+	}
+
+	*args = cFetcherTableArgs{
+		desc:             table,
+		index:            index,
+		isSecondaryIndex: !index.Primary(),
+		cols:             cols,
+		typs:             args.typs,
+	}
+	args.populateTypes(cols)
+	for i := range cols {
+		args.ColIdxMap.Set(cols[i].GetID(), i)
+	}
+
+	// Before we can safely use types from the table descriptor, we need to
+	// make sure they are hydrated (see populateTableArgs for more details).
+	resolver := flowCtx.NewTypeResolver(flowCtx.Txn)
+	return args, neededColumns, onExpr, resolver.HydrateTypeSlice(ctx, args.typs)
+}
+
 // getNeededColumns returns the set of needed columns that a processor core must
 // output because these columns are used by the post-processing stage. It is
 // assumed that the render expressions, if any, have already been deserialized.
@@ -0,0 +1,721 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package colfetcher
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/col/coldata"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/colconv"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexec/colexecargs"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexec/colexecutils"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexecerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexecop"
+	"github.com/cockroachdb/cockroach/pkg/sql/colmem"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/inverted"
+	"github.com/cockroachdb/cockroach/pkg/sql/memsize"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/invertedexpr"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc/keyside"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowinfra"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/span"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/errors"
+)
+
+// DatumsToInvertedExprConstructor creates an implementation of
+// invertedexpr.DatumsToInvertedExpr for the given inverted expression that
+// refers to columns of colTypes schema.
+type DatumsToInvertedExprConstructor func(
+	evalCtx *tree.EvalContext, colTypes []*types.T, expr tree.TypedExpr, idx catalog.Index,
+) (invertedexpr.DatumsToInvertedExpr, error)
+
+// ColInvertedJoin operators are used to execute inverted joins. The input is
+// consumed in chunks; for each chunk the input rows are converted to inverted
+// expressions which are used to generate the spans to read from the inverted
+// index. The fetched index rows are de-duplicated, buffered, and evaluated
+// against the inverted expressions via an inverted.BatchedExprEvaluator.
+// Finally, the matches are emitted in the order of the input rows.
+type ColInvertedJoin struct {
+	colexecop.InitHelper
+	colexecop.OneInputNode
+
+	state    invertedJoinState
+	joinType descpb.JoinType
+
+	flowCtx *execinfra.FlowCtx
+	rf      *cFetcher
+
+	// allocator is used for the output batches.
+	allocator *colmem.Allocator
+	output    coldata.Batch
+	// maxOutputBatchMemSize determines the maximum memory footprint of the
+	// output batch.
+	maxOutputBatchMemSize int64
+
+	inputTypes []*types.T
+	// inputConverter and inputRow are used to convert the input rows into the
+	// datum-backed rows that DatumsToInvertedExpr operates on.
+	inputConverter *colconv.VecToDatumConverter
+	inputRow       rowenc.EncDatumRow
+	// inputChunk buffers all input rows of the current chunk.
+	inputChunk *colexecutils.SpillingQueue
+
+	datumsToInvertedExpr invertedexpr.DatumsToInvertedExpr
+	canPreFilter         bool
+	batchedExprEval      inverted.BatchedExprEvaluator
+	spanBuilder          *span.Builder
+	indexSpans           roachpb.Spans
+
+	// invertedColIdx is the ordinal of the inverted column among the fetched
+	// columns.
+	invertedColIdx int
+	// fetchedConverter is used to convert the fetched non-inverted columns into
+	// datums in order to de-duplicate the index rows.
+	fetchedConverter *colconv.VecToDatumConverter
+	storedColIdxs    []int
+	// indexRows stores the de-duplicated index rows for the current chunk. It
+	// is nil if the right columns are not included into the output.
+	indexRows *colexecutils.SpillingBuffer
+	// indexRowsAllocator is the unlimited allocator of indexRows. It is also
+	// used to account for the memory used by indexRowKeys.
+	indexRowsAllocator *colmem.Allocator
+	// indexRowKeys maps the encoding of each index row (without the inverted
+	// column) to its index among all de-duplicated rows of the current chunk.
+	indexRowKeys         map[string]inverted.KeyIndex
+	indexRowKeysMemUsage int64
+	numIndexRows         int
+	scratchKey           []byte
+
+	// joinedRowIdx contains, for each input row of the current chunk, the
+	// indices of the matching index rows.
+	joinedRowIdx [][]inverted.KeyIndex
+	emitState    struct {
+		// inputBatch is the current batch of the input rows from inputChunk,
+		// and inputBatchIdx is the position of the current input row in it.
+		inputBatch    coldata.Batch
+		inputBatchIdx int
+		// inputRowIdx is the position of the current input row within the
+		// chunk.
+		inputRowIdx int
+		// matchIdx is the position of the next match in
+		// joinedRowIdx[inputRowIdx] to be emitted.
+		matchIdx int
+	}
+
+	// tracingSpan is created when the stats should be collected for the query
+	// execution, and it will be finished when closing the operator.
+	tracingSpan *tracing.Span
+	mu          struct {
+		syncutil.Mutex
+		// rowsRead contains the number of total rows this ColInvertedJoin has
+		// fetched so far.
+		rowsRead int64
+	}
+	// ResultTypes is the slice of resulting column types from this operator.
+	ResultTypes []*types.T
+}
+
+var _ colexecop.KVReader = &ColInvertedJoin{}
+var _ execinfra.Releasable = &ColInvertedJoin{}
+var _ colexecop.ClosableOperator = &ColInvertedJoin{}
+
+// Init initializes a ColInvertedJoin.
+func (s *ColInvertedJoin) Init(ctx context.Context) {
+	if !s.InitHelper.Init(ctx) {
+		return
+	}
+	// If tracing is enabled, we need to start a child span so that the only
+	// contention events present in the recording would be because of this
+	// cFetcher. Note that ProcessorSpan method itself will check whether
+	// tracing is enabled.
+	s.Ctx, s.tracingSpan = execinfra.ProcessorSpan(s.Ctx, "colinvertedjoin")
+	s.Input.Init(s.Ctx)
+}
+
+type invertedJoinState uint8
+
+const (
+	invertedJoinReadingInput invertedJoinState = iota
+	invertedJoinScanning
+	invertedJoinEmitting
+	invertedJoinDone
+)
+
+// Next is part of the Operator interface.
+func (s *ColInvertedJoin) Next() coldata.Batch {
+	for {
+		switch s.state {
+		case invertedJoinReadingInput:
+			s.state = s.readInput()
+		case invertedJoinScanning:
+			s.state = s.performScan()
+		case invertedJoinEmitting:
+			if batch := s.emit(); batch.Length() > 0 {
+				return batch
+			}
+			// The current chunk has been fully processed, so we reset the
+			// state and move on to the next chunk.
+			s.resetChunk()
+			s.state = invertedJoinReadingInput
+		case invertedJoinDone:
+			// Eagerly close the inverted joiner. Note that closeInternal() is
+			// idempotent, so it's ok if it'll be closed again.
+			s.closeInternal()
+			return coldata.ZeroBatch
+		}
+	}
+}
+
+// readInput reads the next chunk of input rows, converts them into inverted
+// expressions, and starts the index scan.
+func (s *ColInvertedJoin) readInput() invertedJoinState {
+	var chunkSize int64
+	var rowCount int
+	for chunkSize < inputBatchSizeLimit {
+		batch := s.Input.Next()
+		n := batch.Length()
+		if n == 0 {
+			break
+		}
+		s.inputConverter.ConvertBatchAndDeselect(batch)
+		for i := 0; i < n; i++ {
+			for j, t := range s.inputTypes {
+				s.inputRow[j] = rowenc.DatumToEncDatum(t, s.inputConverter.GetDatumColumn(j)[i])
+			}
+			expr, preFilterState, err := s.datumsToInvertedExpr.Convert(s.Ctx, s.inputRow)
+			if err != nil {
+				colexecerror.InternalError(err)
+			}
+			// Note that expr is nil when one of the input columns was NULL,
+			// and the nil serves as a marker that will result in an empty set
+			// as the evaluation result.
+			s.batchedExprEval.Exprs = append(s.batchedExprEval.Exprs, expr)
+			if s.canPreFilter {
+				s.batchedExprEval.PreFilterState = append(s.batchedExprEval.PreFilterState, preFilterState)
+			}
+		}
+		s.inputChunk.Enqueue(s.Ctx, batch)
+		chunkSize += colmem.GetBatchMemSize(batch)
+		rowCount += n
+	}
+	if rowCount == 0 {
+		// The input has been fully consumed.
+		return invertedJoinDone
+	}
+	s.inputChunk.Enqueue(s.Ctx, coldata.ZeroBatch)
+
+	spans, err := s.batchedExprEval.Init()
+	if err != nil {
+		colexecerror.InternalError(err)
+	}
+	if len(spans) == 0 {
+		// Nothing to scan, so none of the input rows have any matches.
+		s.joinedRowIdx = s.joinedRowIdx[:0]
+		for i := 0; i < rowCount; i++ {
+			s.joinedRowIdx = append(s.joinedRowIdx, nil)
+		}
+		return invertedJoinEmitting
+	}
+	// NB: spans is already sorted, and that sorting is preserved when
+	// generating s.indexSpans. Note that the fetcher takes ownership of the
+	// spans slice, but we can reuse it once the scan is complete.
+	s.indexSpans, err = s.spanBuilder.SpansFromInvertedSpans(spans, nil /* constraint */, s.indexSpans[:0])
+	if err != nil {
+		colexecerror.InternalError(err)
+	}
+	if err = s.rf.StartScan(
+		s.Ctx,
+		s.flowCtx.Txn,
+		s.indexSpans,
+		nil,   /* bsHeader */
+		false, /* limitBatches */
+		rowinfra.NoBytesLimit,
+		rowinfra.NoRowLimit,
+		s.flowCtx.EvalCtx.TestingKnobs.ForceProductionBatchSizes,
+	); err != nil {
+		colexecerror.InternalError(err)
+	}
+	return invertedJoinScanning
+}
+
+// performScan reads the next batch of the index rows and adds the relevant
+// de-duplicated ones to the batched expression evaluator. Once all index rows
+// have been read, the expressions are evaluated.
+func (s *ColInvertedJoin) performScan() invertedJoinState {
+	batch, err := s.rf.NextBatch(s.Ctx)
+	if err != nil {
+		colexecerror.InternalError(err)
+	}
+	if batch.Selection() != nil {
+		colexecerror.InternalError(
+			errors.AssertionFailedf("unexpected selection vector on the batch coming from CFetcher"))
+	}
+	n := batch.Length()
+	if n == 0 {
+		s.joinedRowIdx = s.batchedExprEval.Evaluate()
+		return invertedJoinEmitting
+	}
+	s.mu.Lock()
+	s.mu.rowsRead += int64(n)
+	s.mu.Unlock()
+
+	// NB: the cFetcher outputs the encoded inverted key as the value of the
+	// inverted column.
+	invertedCol := batch.ColVec(s.invertedColIdx).Bytes()
+	s.fetchedConverter.ConvertBatch(batch)
+	for i := 0; i < n; i++ {
+		shouldAdd, err := s.batchedExprEval.PrepareAddIndexRow(invertedCol.Get(i), nil /* encFull */)
+		if err != nil {
+			colexecerror.InternalError(err)
+		}
+		if !shouldAdd {
+			continue
+		}
+		s.scratchKey = s.scratchKey[:0]
+		for _, colIdx := range s.storedColIdxs {
+			s.scratchKey, err = keyside.Encode(
+				s.scratchKey, s.fetchedConverter.GetDatumColumn(colIdx)[i], encoding.Ascending,
+			)
+			if err != nil {
+				colexecerror.InternalError(err)
+			}
+		}
+		keyIndex, ok := s.indexRowKeys[string(s.scratchKey)]
+		if !ok {
+			keyIndex = inverted.KeyIndex(s.numIndexRows)
+			s.numIndexRows++
+			s.indexRowKeys[string(s.scratchKey)] = keyIndex
+			memUsage := int64(len(s.scratchKey)) + memsize.String + memsize.Int + memsize.MapEntryOverhead
+			s.indexRowsAllocator.AdjustMemoryUsage(memUsage)
+			s.indexRowKeysMemUsage += memUsage
+			if s.indexRows != nil {
+				s.indexRows.AppendTuples(s.Ctx, batch, i, i+1)
+			}
+		}
+		if err = s.batchedExprEval.AddIndexRow(keyIndex); err != nil {
+			colexecerror.InternalError(err)
+		}
+	}
+	return invertedJoinScanning
+}
+
+// emit populates the output batch with the results of the join for the
+// current chunk. A zero-length batch is returned once the chunk has been fully
+// processed.
+func (s *ColInvertedJoin) emit() coldata.Batch {
+	s.output, _ = s.allocator.ResetMaybeReallocate(
+		s.ResultTypes, s.output, coldata.BatchSize(), s.maxOutputBatchMemSize,
+	)
+	outputCapacity := s.output.Capacity()
+	numInputCols := len(s.inputTypes)
+	var outIdx int
+	s.allocator.PerformOperation(s.output.ColVecs(), func() {
+		for outIdx < outputCapacity && s.emitState.inputRowIdx < len(s.joinedRowIdx) {
+			if s.emitState.inputBatch == nil || s.emitState.inputBatchIdx == s.emitState.inputBatch.Length() {
+				var err error
+				s.emitState.inputBatch, err = s.inputChunk.Dequeue(s.Ctx)
+				if err != nil {
+					colexecerror.InternalError(err)
+				}
+				s.emitState.inputBatchIdx = 0
+				if s.emitState.inputBatch.Length() == 0 {
+					colexecerror.InternalError(errors.AssertionFailedf("unexpectedly exhausted the input chunk"))
+				}
+			}
+			matches := s.joinedRowIdx[s.emitState.inputRowIdx]
+			switch s.joinType {
+			case descpb.InnerJoin, descpb.LeftOuterJoin:
+				if len(matches) == 0 {
+					if s.joinType == descpb.LeftOuterJoin {
+						s.copyInputRow(outIdx)
+						for i := numInputCols; i < len(s.ResultTypes); i++ {
+							s.output.ColVec(i).Nulls().SetNull(outIdx)
+						}
+						outIdx++
+					}
+					s.advanceInputRow()
+					continue
+				}
+				for s.emitState.matchIdx < len(matches) && outIdx < outputCapacity {
+					s.copyInputRow(outIdx)
+					s.copyIndexRow(outIdx, int(matches[s.emitState.matchIdx]))
+					outIdx++
+					s.emitState.matchIdx++
+				}
+				if s.emitState.matchIdx == len(matches) {
+					s.advanceInputRow()
+				}
+			case descpb.LeftSemiJoin:
+				if len(matches) > 0 {
+					s.copyInputRow(outIdx)
+					outIdx++
+				}
+				s.advanceInputRow()
+			case descpb.LeftAntiJoin:
+				if len(matches) == 0 {
+					s.copyInputRow(outIdx)
+					outIdx++
+				}
+				s.advanceInputRow()
+			default:
+				colexecerror.InternalError(errors.AssertionFailedf("unexpected inverted join type %s", s.joinType))
+			}
+		}
+	})
+	s.output.SetLength(outIdx)
+	return s.output
+}
+
+// copyInputRow copies the current input row into the output batch at position
+// outIdx.
+func (s *ColInvertedJoin) copyInputRow(outIdx int) {
+	rowIdx := s.emitState.inputBatchIdx
+	for i := range s.inputTypes {
+		s.output.ColVec(i).Copy(coldata.SliceArgs{
+			Src:         s.emitState.inputBatch.ColVec(i),
+			DestIdx:     outIdx,
+			SrcStartIdx: rowIdx,
+			SrcEndIdx:   rowIdx + 1,
+		})
+	}
+}
+
+// copyIndexRow copies the buffered index row with the given index into the
+// output batch at position outIdx. The inverted column is always NULL.
+func (s *ColInvertedJoin) copyIndexRow(outIdx int, idx int) {
+	numInputCols := len(s.inputTypes)
+	s.output.ColVec(numInputCols + s.invertedColIdx).Nulls().SetNull(outIdx)
+	for i, colIdx := range s.storedColIdxs {
+		vec, rowIdx, _ := s.indexRows.GetVecWithTuple(s.Ctx, i, idx)
+		s.output.ColVec(numInputCols + colIdx).Copy(coldata.SliceArgs{
+			Src:         vec,
+			DestIdx:     outIdx,
+			SrcStartIdx: rowIdx,
+			SrcEndIdx:   rowIdx + 1,
+		})
+	}
+}
+
+// advanceInputRow moves the emitting state to the next input row.
+func (s *ColInvertedJoin) advanceInputRow() {
+	s.emitState.inputRowIdx++
+	s.emitState.inputBatchIdx++
+	s.emitState.matchIdx = 0
+}
+
+// resetChunk resets the state of the operator so that the next chunk of input
+// rows can be processed.
+func (s *ColInvertedJoin) resetChunk() {
+	s.batchedExprEval.Reset()
+	s.inputChunk.Reset(s.Ctx)
+	if s.indexRows != nil {
+		s.indexRows.Reset(s.Ctx)
+	}
+	for k := range s.indexRowKeys {
+		delete(s.indexRowKeys, k)
+	}
+	s.indexRowsAllocator.ReleaseMemory(s.indexRowKeysMemUsage)
+	s.indexRowKeysMemUsage = 0
+	s.numIndexRows = 0
+	s.joinedRowIdx = nil
+	s.emitState.inputBatch = nil
+	s.emitState.inputBatchIdx = 0
+	s.emitState.inputRowIdx = 0
+	s.emitState.matchIdx = 0
+}
+
+// DrainMeta is part of the colexecop.MetadataSource interface.
+func (s *ColInvertedJoin) DrainMeta() []execinfrapb.ProducerMetadata {
+	var trailingMeta []execinfrapb.ProducerMetadata
+	if tfs := execinfra.GetLeafTxnFinalState(s.Ctx, s.flowCtx.Txn); tfs != nil {
+		trailingMeta = append(trailingMeta, execinfrapb.ProducerMetadata{LeafTxnFinalState: tfs})
+	}
+	meta := execinfrapb.GetProducerMeta()
+	meta.Metrics = execinfrapb.GetMetricsMeta()
+	meta.Metrics.BytesRead = s.GetBytesRead()
+	meta.Metrics.RowsRead = s.GetRowsRead()
+	trailingMeta = append(trailingMeta, *meta)
+	if trace := execinfra.GetTraceData(s.Ctx); trace != nil {
+		trailingMeta = append(trailingMeta, execinfrapb.ProducerMetadata{TraceData: trace})
+	}
+	return trailingMeta
+}
+
+// GetBytesRead is part of the colexecop.KVReader interface.
+func (s *ColInvertedJoin) GetBytesRead() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rf.getBytesRead()
+}
+
+// GetRowsRead is part of the colexecop.KVReader interface.
+func (s *ColInvertedJoin) GetRowsRead() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.mu.rowsRead
+}
+
+// GetCumulativeContentionTime is part of the colexecop.KVReader interface.
+func (s *ColInvertedJoin) GetCumulativeContentionTime() time.Duration {
+	return execinfra.GetCumulativeContentionTime(s.Ctx)
+}
+
+// GetScanStats is part of the colexecop.KVReader interface.
+func (s *ColInvertedJoin) GetScanStats() execinfra.ScanStats {
+	return execinfra.GetScanStats(s.Ctx)
+}
+
+// NewColInvertedJoin creates a new ColInvertedJoin operator.
+// - indexRowsAllocator must be an unlimited allocator that is used for
+// buffering the index rows of each chunk. The buffer spills to disk once
+// memoryLimit is reached.
+// - inputChunkArgs specifies the arguments for the spilling queue that buffers
+// the input rows of each chunk (the types are populated by
+// NewColInvertedJoin).
+//
+// The returned ON expression has its column references adjusted to the output
+// of the operator, and it must be applied on top of the operator by the
+// caller. It is only allowed for inner joins.
+func NewColInvertedJoin(
+	ctx context.Context,
+	allocator *colmem.Allocator,
+	fetcherAllocator *colmem.Allocator,
+	indexRowsAllocator *colmem.Allocator,
+	kvFetcherMemAcc *mon.BoundAccount,
+	flowCtx *execinfra.FlowCtx,
+	helper *colexecargs.ExprHelper,
+	input colexecop.Operator,
+	spec *execinfrapb.InvertedJoinerSpec,
+	post *execinfrapb.PostProcessSpec,
+	inputTypes []*types.T,
+	inputChunkArgs *colexecutils.NewSpillingQueueArgs,
+	newDatumsToInvertedExpr DatumsToInvertedExprConstructor,
+) (*ColInvertedJoin, execinfrapb.Expression, error) {
+	// NB: we hit this with a zero NodeID (but !ok) with multi-tenancy.
+	if nodeID, ok := flowCtx.NodeID.OptionalNodeID(); nodeID == 0 && ok {
+		return nil, execinfrapb.Expression{}, errors.Errorf("attempting to create a ColInvertedJoin with uninitialized NodeID")
+	}
+	if err := CheckInvertedJoinSupported(spec); err != nil {
+		return nil, execinfrapb.Expression{}, errors.NewAssertionErrorWithWrappedErrf(err, "unexpected inverted join spec")
+	}
+
+	table := flowCtx.TableDescriptor(&spec.Table)
+	if int(spec.IndexIdx) >= len(table.ActiveIndexes()) {
+		return nil, execinfrapb.Expression{}, errors.AssertionFailedf("invalid indexIdx %d", spec.IndexIdx)
+	}
+	index := table.ActiveIndexes()[spec.IndexIdx]
+	// Inverted joins are not used for mutations.
+	tableCols := table.PublicColumns()
+
+	// The inverted expression refers to the input columns followed by the
+	// table columns.
+	exprColTypes := make([]*types.T, 0, len(inputTypes)+len(tableCols))
+	exprColTypes = append(exprColTypes, inputTypes...)
+	for _, col := range tableCols {
+		exprColTypes = append(exprColTypes, col.GetType())
+	}
+	invertedExpr, err := helper.ProcessExpr(spec.InvertedExpr, flowCtx.EvalCtx, exprColTypes)
+	if err != nil {
+		return nil, execinfrapb.Expression{}, err
+	}
+	datumsToInvertedExpr, err := newDatumsToInvertedExpr(flowCtx.EvalCtx, exprColTypes, invertedExpr, index)
+	if err != nil {
+		return nil, execinfrapb.Expression{}, err
+	}
+
+	// In general we need all the columns in the index to compute the set
+	// expression (see the comment in newInvertedJoiner for more details).
+	var allIndexCols util.FastIntSet
+	invertedColOrd := -1
+	for _, col := range table.IndexFullColumns(index) {
+		if col == nil {
+			continue
+		}
+		ord := findColumnOrdinal(tableCols, col.GetID())
+		if ord < 0 {
+			return nil, execinfrapb.Expression{}, errors.AssertionFailedf(
+				"column %d not found in table %s", col.GetID(), table.GetName(),
+			)
+		}
+		allIndexCols.Add(ord)
+		if col.GetID() == index.InvertedColumnID() {
+			invertedColOrd = ord
+		}
+	}
+	if invertedColOrd < 0 {
+		return nil, execinfrapb.Expression{}, errors.AssertionFailedf("inverted column not found in index %s", index.GetName())
+	}
+	// The cFetcher outputs the encoded inverted key as the value of the
+	// inverted column, so we override its type.
+	invertedColDesc := *tableCols[invertedColOrd].ColumnDesc()
+	invertedColDesc.Type = types.Bytes
+	invertedCol := tabledesc.FindInvertedColumn(table, &invertedColDesc)
+
+	includeTableCols := spec.Type.ShouldIncludeRightColsInOutput()
+	tableArgs, neededColumns, onExpr, err := populateTableArgsForJoin(
+		ctx, flowCtx, table, index, tableCols, invertedCol, inputTypes,
+		includeTableCols, allIndexCols, post, spec.OnExpr, helper,
+	)
+	if err != nil {
+		return nil, execinfrapb.Expression{}, err
+	}
+	if !neededColumns.SubsetOf(allIndexCols) {
+		return nil, execinfrapb.Expression{}, errors.AssertionFailedf(
+			"index %s does not cover all columns needed by the inverted join", index.GetName(),
+		)
+	}
+	// Find the position of the inverted column among the fetched ones. All
+	// other fetched columns are stored for the output and are used to
+	// de-duplicate the index rows.
+	var invertedColIdx int
+	storedColIdxs := make([]int, 0, neededColumns.Len()-1)
+	var colIdx int
+	for ord, ok := neededColumns.Next(0); ok; ord, ok = neededColumns.Next(ord + 1) {
+		if ord == invertedColOrd {
+			invertedColIdx = colIdx
+		} else {
+			storedColIdxs = append(storedColIdxs, colIdx)
+		}
+		colIdx++
+	}
+
+	memoryLimit := execinfra.GetWorkMemLimit(flowCtx)
+	fetcher := cFetcherPool.Get().(*cFetcher)
+	fetcher.cFetcherArgs = cFetcherArgs{
+		descpb.ScanLockingStrength_FOR_NONE,
+		descpb.ScanLockingWaitPolicy_BLOCK,
+		flowCtx.EvalCtx.SessionData().LockTimeout,
+		memoryLimit,
+		0,     /* estimatedRowCount */
+		false, /* reverse */
+		flowCtx.TraceKV,
+	}
+	if err = fetcher.Init(
+		flowCtx.Codec(), fetcherAllocator, kvFetcherMemAcc, tableArgs,
+	); err != nil {
+		fetcher.Release()
+		return nil, execinfrapb.Expression{}, err
+	}
+
+	spanBuilder := span.MakeBuilder(flowCtx.EvalCtx, flowCtx.Codec(), table, index)
+	spanBuilder.SetNeededColumns(allIndexCols)
+
+	chunkArgs := *inputChunkArgs
+	chunkArgs.Types = inputTypes
+	op := &ColInvertedJoin{
+		OneInputNode:          colexecop.NewOneInputNode(input),
+		joinType:              spec.Type,
+		flowCtx:               flowCtx,
+		rf:                    fetcher,
+		allocator:             allocator,
+		maxOutputBatchMemSize: memoryLimit,
+		inputTypes:            inputTypes,
+		inputConverter:        colconv.NewAllVecToDatumConverter(len(inputTypes)),
+		inputRow:              make(rowenc.EncDatumRow, len(inputTypes)),
+		inputChunk:            colexecutils.NewSpillingQueue(&chunkArgs),
+		datumsToInvertedExpr:  datumsToInvertedExpr,
+		canPreFilter:          datumsToInvertedExpr.CanPreFilter(),
+		spanBuilder:           spanBuilder,
+		invertedColIdx:        invertedColIdx,
+		fetchedConverter: colconv.NewVecToDatumConverter(
+			len(tableArgs.typs), storedColIdxs, true, /* willRelease */
+		),
+		storedColIdxs:      storedColIdxs,
+		indexRowsAllocator: indexRowsAllocator,
+		indexRowKeys:       make(map[string]inverted.KeyIndex),
+		ResultTypes:        spec.Type.MakeOutputTypes(inputTypes, tableArgs.typs),
+	}
+	if op.canPreFilter {
+		op.batchedExprEval.Filterer = datumsToInvertedExpr
+	}
+	if includeTableCols {
+		storedTypes := make([]*types.T, len(storedColIdxs))
+		for i, colIdx := range storedColIdxs {
+			storedTypes[i] = tableArgs.typs[colIdx]
+		}
+		op.indexRows = colexecutils.NewSpillingBuffer(
+			indexRowsAllocator, memoryLimit, inputChunkArgs.DiskQueueCfg,
+			inputChunkArgs.FDSemaphore, storedTypes, inputChunkArgs.DiskAcc,
+			storedColIdxs...,
+		)
+	}
+	return op, onExpr, nil
+}
+
+// CheckInvertedJoinSupported returns an error if the inverted join described
+// by the given spec cannot be executed by the ColInvertedJoin.
+func CheckInvertedJoinSupported(spec *execinfrapb.InvertedJoinerSpec) error {
+	if len(spec.PrefixEqualityColumns) > 0 {
+		return errors.New("inverted joins with prefix equality columns are not supported")
+	}
+	if spec.OutputGroupContinuationForLeftRow {
+		return errors.New("paired inverted joins are not supported")
+	}
+	switch spec.Type {
+	case descpb.InnerJoin, descpb.LeftOuterJoin, descpb.LeftSemiJoin, descpb.LeftAntiJoin:
+	default:
+		return errors.Newf("inverted joins of %s type are not supported", spec.Type)
+	}
+	if !spec.OnExpr.Empty() && spec.Type != descpb.InnerJoin {
+		return errors.New("non-inner inverted joins with ON expressions are not supported")
+	}
+	return nil
+}
+
+// Release implements the execinfra.Releasable interface.
+func (s *ColInvertedJoin) Release() {
+	s.rf.Release()
+	s.inputConverter.Release()
+	s.fetchedConverter.Release()
+	*s = ColInvertedJoin{}
+}
+
+// Close implements the colexecop.Closer interface.
+func (s *ColInvertedJoin) Close() error {
+	s.closeInternal()
+	if s.tracingSpan != nil {
+		s.tracingSpan.Finish()
+		s.tracingSpan = nil
+	}
+	return nil
+}
+
+// closeInternal is a subset of Close() which doesn't finish the operator's
+// span.
+func (s *ColInvertedJoin) closeInternal() {
+	ctx := s.EnsureCtx()
+	s.rf.Close(ctx)
+	if s.inputChunk != nil {
+		if err := s.inputChunk.Close(ctx); err != nil {
+			colexecerror.InternalError(err)
+		}
+	}
+	if s.indexRows != nil {
+		s.indexRows.Close(ctx)
+	}
+	s.output = nil
+	s.joinedRowIdx = nil
+	s.emitState.inputBatch = nil
+}
@@ -0,0 +1,643 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package colfetcher
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/col/coldata"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/kvstreamer"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexec/colexecargs"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexec/colexecspan"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexec/colexecutils"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexecerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexecop"
+	"github.com/cockroachdb/cockroach/pkg/sql/colmem"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowinfra"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/errors"
+)
+
+// LookupJoinerConstructor creates the operator that performs the join of a
+// single chunk of input rows (coming from probe) with the rows looked up for
+// that chunk (coming from build). The build side has buildTypes schema, and
+// buildEqCols are the ordinals of the build side columns that correspond to
+// the lookup columns of the probe side.
+//
+// The returned operator is reset once each chunk is fully processed, so it
+// must support resetting regardless of whether it has spilled to disk.
+type LookupJoinerConstructor func(
+	probe, build colexecop.Operator, buildTypes []*types.T, buildEqCols []uint32,
+) colexecop.ResettableOperator
+
+// ColLookupJoin operators are used to execute lookup joins that don't need to
+// maintain the input ordering. The input is consumed in chunks; for each chunk
+// the lookup spans are constructed and the matching index rows are fetched.
+// The actual joining of the input rows with the fetched rows is delegated to
+// the joiner operator (a hash joiner that can spill to disk) that is reset
+// after each chunk.
+type ColLookupJoin struct {
+	colexecop.InitHelper
+	colexecop.OneInputNode
+
+	state lookupJoinState
+
+	// spanAssembler is used to construct the lookup spans for each input batch.
+	spanAssembler colexecspan.ColSpanAssembler
+
+	// inputChunk buffers all input rows of the current chunk. It serves as the
+	// probe side of the joiner.
+	inputChunk *colexecutils.SpillingQueue
+	// joiner joins the rows from inputChunk with the looked up rows.
+	joiner colexecop.ResettableOperator
+	// probeInput and buildInput are the inputs to joiner.
+	probeInput *lookupJoinChunkOp
+	buildInput *lookupJoinFetchOp
+
+	flowCtx *execinfra.FlowCtx
+	rf      *cFetcher
+
+	// limitBatches and batchBytesLimit determine the batch limits of the scans
+	// performed by rf when the Streamer API is not used.
+	limitBatches    bool
+	batchBytesLimit rowinfra.BytesLimit
+
+	// tracingSpan is created when the stats should be collected for the query
+	// execution, and it will be finished when closing the operator.
+	tracingSpan *tracing.Span
+	mu          struct {
+		syncutil.Mutex
+		// rowsRead contains the number of total rows this ColLookupJoin has
+		// fetched so far.
+		rowsRead int64
+	}
+	// ResultTypes is the slice of resulting column types from this operator.
+	ResultTypes []*types.T
+
+	// usesStreamer indicates whether the ColLookupJoin is using the Streamer
+	// API.
+	usesStreamer bool
+	streamerInfo struct {
+		*kvstreamer.Streamer
+		budgetAcc   *mon.BoundAccount
+		budgetLimit int64
+	}
+}
+
+var _ colexecop.KVReader = &ColLookupJoin{}
+var _ execinfra.Releasable = &ColLookupJoin{}
+var _ colexecop.ClosableOperator = &ColLookupJoin{}
+
+// Init initializes a ColLookupJoin.
+func (s *ColLookupJoin) Init(ctx context.Context) {
+	if !s.InitHelper.Init(ctx) {
+		return
+	}
+	// If tracing is enabled, we need to start a child span so that the only
+	// contention events present in the recording would be because of this
+	// cFetcher. Note that ProcessorSpan method itself will check whether
+	// tracing is enabled.
+	s.Ctx, s.tracingSpan = execinfra.ProcessorSpan(s.Ctx, "collookupjoin")
+	s.Input.Init(s.Ctx)
+	s.joiner.Init(s.Ctx)
+	if s.usesStreamer {
+		s.streamerInfo.Streamer = kvstreamer.NewStreamer(
+			s.flowCtx.Cfg.DistSender,
+			s.flowCtx.Stopper(),
+			s.flowCtx.Txn,
+			s.flowCtx.EvalCtx.Settings,
+			row.GetWaitPolicy(s.rf.lockWaitPolicy),
+			s.streamerInfo.budgetLimit,
+			s.streamerInfo.budgetAcc,
+		)
+		s.streamerInfo.Streamer.Init(
+			kvstreamer.OutOfOrder,
+			kvstreamer.Hints{UniqueRequests: true},
+		)
+	}
+}
+
+type lookupJoinState uint8
+
+const (
+	lookupJoinReadingInput lookupJoinState = iota
+	lookupJoinJoining
+	lookupJoinDone
+)
+
+// Next is part of the Operator interface.
+func (s *ColLookupJoin) Next() coldata.Batch {
+	for {
+		switch s.state {
+		case lookupJoinReadingInput:
+			var chunkSize int64
+			var rowCount int
+			for chunkSize < inputBatchSizeLimit {
+				batch := s.Input.Next()
+				n := batch.Length()
+				if n == 0 {
+					break
+				}
+				s.spanAssembler.ConsumeBatch(batch, 0 /* startIdx */, n)
+				s.inputChunk.Enqueue(s.Ctx, batch)
+				chunkSize += colmem.GetBatchMemSize(batch)
+				rowCount += n
+			}
+			if rowCount == 0 {
+				// The input has been fully consumed.
+				s.state = lookupJoinDone
+				continue
+			}
+			s.inputChunk.Enqueue(s.Ctx, coldata.ZeroBatch)
+
+			// Sort the spans in order to allow lower layers to optimize the
+			// iteration over the data. The joiner doesn't care about the order
+			// in which the looked up rows are fetched. Since multiple input rows
+			// can have the same lookup values, we also have to remove the
+			// duplicate spans so that the same index rows aren't fetched (and
+			// joined) multiple times.
+			spans := s.spanAssembler.GetSpans()
+			sort.Sort(spans)
+			spans = dedupSpans(spans)
+
+			s.rf.setEstimatedRowCount(uint64(rowCount))
+			// Note that the fetcher takes ownership of the spans slice (see the
+			// comment in ColIndexJoin.Next for more details).
+			var err error
+			if s.usesStreamer {
+				err = s.rf.StartScanStreaming(
+					s.Ctx,
+					s.streamerInfo.Streamer,
+					spans,
+					rowinfra.NoRowLimit,
+				)
+			} else {
+				err = s.rf.StartScan(
+					s.Ctx,
+					s.flowCtx.Txn,
+					spans,
+					nil, /* bsHeader */
+					s.limitBatches,
+					s.batchBytesLimit,
+					rowinfra.NoRowLimit,
+					s.flowCtx.EvalCtx.TestingKnobs.ForceProductionBatchSizes,
+				)
+			}
+			if err != nil {
+				colexecerror.InternalError(err)
+			}
+			s.buildInput.done = false
+			s.state = lookupJoinJoining
+		case lookupJoinJoining:
+			batch := s.joiner.Next()
+			if batch.Length() == 0 {
+				// The current chunk has been fully processed, so we reset the
+				// joiner and move on to the next chunk.
+				for !s.buildInput.done {
+					// The joiner is expected to have fully consumed the looked
+					// up rows, but we drain them just in case since the fetcher
+					// must be exhausted before the next scan is started.
+					s.buildInput.Next()
+				}
+				s.joiner.Reset(s.Ctx)
+				s.inputChunk.Reset(s.Ctx)
+				s.state = lookupJoinReadingInput
+				continue
+			}
+			return batch
+		case lookupJoinDone:
+			// Eagerly close the lookup joiner. Note that closeInternal() is
+			// idempotent, so it's ok if it'll be closed again.
+			s.closeInternal()
+			return coldata.ZeroBatch
+		}
+	}
+}
+
+// dedupSpans removes the duplicates from the sorted spans slice in-place.
+func dedupSpans(spans roachpb.Spans) roachpb.Spans {
+	if len(spans) < 2 {
+		return spans
+	}
+	n := 1
+	for i := 1; i < len(spans); i++ {
+		if !spans[i].Equal(spans[n-1]) {
+			spans[n] = spans[i]
+			n++
+		}
+	}
+	for i := n; i < len(spans); i++ {
+		// Lose the references to the keys of the duplicate spans.
+		spans[i] = roachpb.Span{}
+	}
+	return spans[:n]
+}
+
+// lookupJoinChunkOp is the probe side input of the joiner in ColLookupJoin. It
+// returns all input rows of the current chunk.
+//
+// Note that lookupJoinChunkOp intentionally doesn't implement the
+// colexecop.Resetter interface since the chunk is reset by the ColLookupJoin
+// itself.
+type lookupJoinChunkOp struct {
+	colexecop.ZeroInputNode
+	colexecop.NonExplainable
+	j *ColLookupJoin
+}
+
+var _ colexecop.Operator = &lookupJoinChunkOp{}
+
+// Init is part of the Operator interface.
+func (c *lookupJoinChunkOp) Init(context.Context) {}
+
+// Next is part of the Operator interface.
+func (c *lookupJoinChunkOp) Next() coldata.Batch {
+	batch, err := c.j.inputChunk.Dequeue(c.j.Ctx)
+	if err != nil {
+		colexecerror.InternalError(err)
+	}
+	return batch
+}
+
+// lookupJoinFetchOp is the build side input of the joiner in ColLookupJoin. It
+// returns all rows looked up for the current chunk.
+//
+// Note that lookupJoinFetchOp intentionally doesn't implement the
+// colexecop.Resetter interface since the scan is restarted by the
+// ColLookupJoin itself.
+type lookupJoinFetchOp struct {
+	colexecop.ZeroInputNode
+	colexecop.NonExplainable
+	j *ColLookupJoin
+	// done is true once all looked up rows for the current chunk have been
+	// returned.
+	done bool
+}
+
+var _ colexecop.Operator = &lookupJoinFetchOp{}
+
+// Init is part of the Operator interface.
+func (f *lookupJoinFetchOp) Init(context.Context) {}
+
+// Next is part of the Operator interface.
+func (f *lookupJoinFetchOp) Next() coldata.Batch {
+	if f.done {
+		return coldata.ZeroBatch
+	}
+	batch, err := f.j.rf.NextBatch(f.j.Ctx)
+	if err != nil {
+		colexecerror.InternalError(err)
+	}
+	if batch.Selection() != nil {
+		colexecerror.InternalError(
+			errors.AssertionFailedf("unexpected selection vector on the batch coming from CFetcher"))
+	}
+	n := batch.Length()
+	if n == 0 {
+		// NB: the fetcher has just been closed automatically, so it released
+		// all of the resources. We now have to tell the ColSpanAssembler to
+		// account for the spans slice since it still has the references to
+		// it.
+		f.j.spanAssembler.AccountForSpans()
+		f.done = true
+		return coldata.ZeroBatch
+	}
+	f.j.mu.Lock()
+	f.j.mu.rowsRead += int64(n)
+	f.j.mu.Unlock()
+	return batch
+}
+
+// DrainMeta is part of the colexecop.MetadataSource interface.
+func (s *ColLookupJoin) DrainMeta() []execinfrapb.ProducerMetadata {
+	var trailingMeta []execinfrapb.ProducerMetadata
+	if tfs := execinfra.GetLeafTxnFinalState(s.Ctx, s.flowCtx.Txn); tfs != nil {
+		trailingMeta = append(trailingMeta, execinfrapb.ProducerMetadata{LeafTxnFinalState: tfs})
+	}
+	meta := execinfrapb.GetProducerMeta()
+	meta.Metrics = execinfrapb.GetMetricsMeta()
+	meta.Metrics.BytesRead = s.GetBytesRead()
+	meta.Metrics.RowsRead = s.GetRowsRead()
+	trailingMeta = append(trailingMeta, *meta)
+	if trace := execinfra.GetTraceData(s.Ctx); trace != nil {
+		trailingMeta = append(trailingMeta, execinfrapb.ProducerMetadata{TraceData: trace})
+	}
+	return trailingMeta
+}
+
+// GetBytesRead is part of the colexecop.KVReader interface.
+func (s *ColLookupJoin) GetBytesRead() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rf.getBytesRead()
+}
+
+// GetRowsRead is part of the colexecop.KVReader interface.
+func (s *ColLookupJoin) GetRowsRead() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.mu.rowsRead
+}
+
+// GetCumulativeContentionTime is part of the colexecop.KVReader interface.
+func (s *ColLookupJoin) GetCumulativeContentionTime() time.Duration {
+	return execinfra.GetCumulativeContentionTime(s.Ctx)
+}
+
+// GetScanStats is part of the colexecop.KVReader interface.
+func (s *ColLookupJoin) GetScanStats() execinfra.ScanStats {
+	return execinfra.GetScanStats(s.Ctx)
+}
+
+// NewColLookupJoin creates a new ColLookupJoin operator.
+// - inputChunkArgs specifies the arguments for the spilling queue that buffers
+// the input rows of each chunk (the types are populated by NewColLookupJoin).
+// - newJoiner is used to create the operator that joins each chunk of input
+// rows with the looked up rows.
+//
+// The returned ON expression has its column references adjusted to the output
+// of the operator, and it must be applied on top of the operator by the
+// caller. It is only allowed for inner joins.
+func NewColLookupJoin(
+	ctx context.Context,
+	allocator *colmem.Allocator,
+	fetcherAllocator *colmem.Allocator,
+	kvFetcherMemAcc *mon.BoundAccount,
+	streamerBudgetAcc *mon.BoundAccount,
+	flowCtx *execinfra.FlowCtx,
+	helper *colexecargs.ExprHelper,
+	input colexecop.Operator,
+	spec *execinfrapb.JoinReaderSpec,
+	post *execinfrapb.PostProcessSpec,
+	inputTypes []*types.T,
+	inputChunkArgs *colexecutils.NewSpillingQueueArgs,
+	newJoiner LookupJoinerConstructor,
+) (*ColLookupJoin, execinfrapb.Expression, error) {
+	// NB: we hit this with a zero NodeID (but !ok) with multi-tenancy.
+	if nodeID, ok := flowCtx.NodeID.OptionalNodeID(); nodeID == 0 && ok {
+		return nil, execinfrapb.Expression{}, errors.Errorf("attempting to create a ColLookupJoin with uninitialized NodeID")
+	}
+	if err := CheckLookupJoinSupported(spec, inputTypes); err != nil {
+		return nil, execinfrapb.Expression{}, errors.NewAssertionErrorWithWrappedErrf(err, "unexpected lookup join spec")
+	}
+
+	table := flowCtx.TableDescriptor(&spec.Table)
+	index := table.ActiveIndexes()[spec.IndexIdx]
+	tableCols := make([]catalog.Column, 0, len(table.DeletableColumns()))
+	tableCols = append(tableCols, table.DeletableColumns()...)
+	if spec.HasSystemColumns {
+		tableCols = append(tableCols, table.SystemColumns()...)
+	}
+	// The index columns corresponding to the lookup columns must always be
+	// fetched since the joiner needs them to perform the equality check.
+	var lookupKeyCols util.FastIntSet
+	lookupKeyColOrds := make([]int, len(spec.LookupColumns))
+	for i := range spec.LookupColumns {
+		ord := findColumnOrdinal(tableCols, index.GetKeyColumnID(i))
+		if ord < 0 {
+			return nil, execinfrapb.Expression{}, errors.AssertionFailedf(
+				"column %d not found in table %s", index.GetKeyColumnID(i), table.GetName(),
+			)
+		}
+		lookupKeyCols.Add(ord)
+		lookupKeyColOrds[i] = ord
+	}
+	includeTableCols := spec.Type.ShouldIncludeRightColsInOutput()
+	tableArgs, neededColumns, onExpr, err := populateTableArgsForJoin(
+		ctx, flowCtx, table, index, tableCols, nil /* invertedCol */, inputTypes,
+		includeTableCols, lookupKeyCols, post, spec.OnExpr, helper,
+	)
+	if err != nil {
+		return nil, execinfrapb.Expression{}, err
+	}
+	if !index.Primary() {
+		// Make sure that all needed columns are present in the secondary
+		// index.
+		covered := index.CollectKeyColumnIDs()
+		covered.UnionWith(index.CollectKeySuffixColumnIDs())
+		covered.UnionWith(index.CollectSecondaryStoredColumnIDs())
+		for _, col := range tableArgs.cols {
+			if !col.IsSystemColumn() && !covered.Contains(col.GetID()) {
+				return nil, execinfrapb.Expression{}, errors.AssertionFailedf(
+					"index %s does not cover all columns needed by the lookup join", index.GetName(),
+				)
+			}
+		}
+	}
+	// Find the positions of the lookup key columns among the fetched ones.
+	buildEqCols := make([]uint32, len(lookupKeyColOrds))
+	for i, ord := range lookupKeyColOrds {
+		for idx, ok := neededColumns.Next(0); ok && idx < ord; idx, ok = neededColumns.Next(idx + 1) {
+			buildEqCols[i]++
+		}
+	}
+
+	memoryLimit := execinfra.GetWorkMemLimit(flowCtx)
+
+	useStreamer := row.CanUseStreamer(ctx, flowCtx.EvalCtx.Settings)
+	if useStreamer {
+		if maxKeysPerRow, err := tableArgs.desc.KeysPerRow(tableArgs.index.GetID()); err != nil {
+			return nil, execinfrapb.Expression{}, err
+		} else if maxKeysPerRow > 1 {
+			// Same as for the index join, the streamer only supports cases with
+			// a single column family.
+			useStreamer = false
+		} else {
+			if streamerBudgetAcc == nil {
+				return nil, execinfrapb.Expression{}, errors.AssertionFailedf("streamer budget account is nil when the Streamer API is desired")
+			}
+			// Keep the quarter of the memory limit for the output batch of the
+			// cFetcher, and we'll give the remaining three quarters to the
+			// streamer budget below.
+			memoryLimit = int64(math.Ceil(float64(memoryLimit) / 4.0))
+		}
+	}
+
+	fetcher := cFetcherPool.Get().(*cFetcher)
+	fetcher.cFetcherArgs = cFetcherArgs{
+		spec.LockingStrength,
+		spec.LockingWaitPolicy,
+		flowCtx.EvalCtx.SessionData().LockTimeout,
+		memoryLimit,
+		// Note that the estimated row count will be set by the lookup joiner
+		// for each set of spans to read.
+		0,     /* estimatedRowCount */
+		false, /* reverse */
+		flowCtx.TraceKV,
+	}
+	if err = fetcher.Init(
+		flowCtx.Codec(), fetcherAllocator, kvFetcherMemAcc, tableArgs,
+	); err != nil {
+		fetcher.Release()
+		return nil, execinfrapb.Expression{}, err
+	}
+
+	// We choose parallelism when we know that each lookup returns at most one
+	// row. In other cases, we use limits (see the comment in newJoinReader for
+	// more details).
+	limitBatches := !spec.LookupColumnsAreKey
+	if flowCtx.EvalCtx.SessionData().ParallelizeMultiKeyLookupJoinsEnabled {
+		limitBatches = false
+	}
+	batchBytesLimit := rowinfra.NoBytesLimit
+	if limitBatches {
+		batchBytesLimit = rowinfra.BytesLimit(spec.LookupBatchBytesLimit)
+		if batchBytesLimit == 0 {
+			batchBytesLimit = rowinfra.DefaultBatchBytesLimit
+		}
+	}
+
+	chunkArgs := *inputChunkArgs
+	chunkArgs.Types = inputTypes
+	op := &ColLookupJoin{
+		OneInputNode: colexecop.NewOneInputNode(input),
+		spanAssembler: colexecspan.NewColLookupSpanAssembler(
+			flowCtx.Codec(), allocator, table, index, inputTypes, spec.LookupColumns,
+		),
+		inputChunk:      colexecutils.NewSpillingQueue(&chunkArgs),
+		flowCtx:         flowCtx,
+		rf:              fetcher,
+		limitBatches:    limitBatches,
+		batchBytesLimit: batchBytesLimit,
+		ResultTypes:     spec.Type.MakeOutputTypes(inputTypes, tableArgs.typs),
+		usesStreamer:    useStreamer,
+	}
+	op.probeInput = &lookupJoinChunkOp{j: op}
+	op.buildInput = &lookupJoinFetchOp{j: op, done: true}
+	op.joiner = newJoiner(op.probeInput, op.buildInput, tableArgs.typs, buildEqCols)
+	if useStreamer {
+		op.streamerInfo.budgetLimit = 3 * memoryLimit
+		op.streamerInfo.budgetAcc = streamerBudgetAcc
+	}
+	return op, onExpr, nil
+}
+
+// VectorizedLookupJoinsEnabled determines whether lookup and inverted joins
+// can be executed natively by the vectorized engine. When disabled, the
+// row-based processors are wrapped into the vectorized flow instead.
+var VectorizedLookupJoinsEnabled = settings.RegisterBoolSetting(
+	settings.TenantWritable,
+	"sql.distsql.vectorize_lookup_joins.enabled",
+	"set to false to execute lookup and inverted joins via the row-based "+
+		"processors in the vectorized engine",
+	true,
+)
+
+// CheckLookupJoinSupported returns an error if the lookup join described by
+// the given spec (with the input of inputTypes schema) cannot be executed by
+// the ColLookupJoin.
+func CheckLookupJoinSupported(spec *execinfrapb.JoinReaderSpec, inputTypes []*types.T) error {
+	if !spec.LookupExpr.Empty() || !spec.RemoteLookupExpr.Empty() {
+		return errors.New("lookup joins with lookup expressions are not supported")
+	}
+	if len(spec.LookupColumns) == 0 {
+		return errors.New("lookup joins without lookup columns are not supported")
+	}
+	if spec.MaintainOrdering {
+		// The joiner doesn't guarantee any output ordering.
+		return errors.New("lookup joins that maintain ordering are not supported")
+	}
+	if spec.OutputGroupContinuationForLeftRow || spec.LeftJoinWithPairedJoiner {
+		return errors.New("paired lookup joins are not supported")
+	}
+	switch spec.Type {
+	case descpb.InnerJoin, descpb.LeftOuterJoin, descpb.LeftSemiJoin, descpb.LeftAntiJoin:
+	default:
+		return errors.Newf("lookup joins of %s type are not supported", spec.Type)
+	}
+	if !spec.OnExpr.Empty() && spec.Type != descpb.InnerJoin {
+		return errors.New("non-inner lookup joins with ON expressions are not supported")
+	}
+	// The joiner compares the lookup columns against the corresponding index
+	// columns, so their types must be identical.
+	table := tabledesc.NewUnsafeImmutable(&spec.Table)
+	if int(spec.IndexIdx) >= len(table.ActiveIndexes()) {
+		return errors.Newf("invalid indexIdx %d", spec.IndexIdx)
+	}
+	index := table.ActiveIndexes()[spec.IndexIdx]
+	if len(spec.LookupColumns) > index.NumKeyColumns() {
+		return errors.Newf(
+			"%d lookup columns specified, expecting at most %d", len(spec.LookupColumns), index.NumKeyColumns(),
+		)
+	}
+	for i, colIdx := range spec.LookupColumns {
+		col, err := table.FindColumnWithID(index.GetKeyColumnID(i))
+		if err != nil {
+			return err
+		}
+		if int(colIdx) >= len(inputTypes) || !inputTypes[colIdx].Identical(col.GetType()) {
+			return errors.New("lookup joins with mismatched lookup column types are not supported")
+		}
+	}
+	return nil
+}
+
+// findColumnOrdinal returns the ordinal of the column with the given ID in
+// cols, or -1 if there is no such column.
+func findColumnOrdinal(cols []catalog.Column, id descpb.ColumnID) int {
+	for i, col := range cols {
+		if col.GetID() == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// Release implements the execinfra.Releasable interface.
+func (s *ColLookupJoin) Release() {
+	s.rf.Release()
+	s.spanAssembler.Release()
+	*s = ColLookupJoin{}
+}
+
+// Close implements the colexecop.Closer interface.
+func (s *ColLookupJoin) Close() error {
+	s.closeInternal()
+	if s.tracingSpan != nil {
+		s.tracingSpan.Finish()
+		s.tracingSpan = nil
+	}
+	return nil
+}
+
+// closeInternal is a subset of Close() which doesn't finish the operator's
+// span.
+func (s *ColLookupJoin) closeInternal() {
+	ctx := s.EnsureCtx()
+	s.rf.Close(ctx)
+	if s.spanAssembler != nil {
+		// spanAssembler can be nil if Release() has already been called.
+		s.spanAssembler.Close()
+	}
+	if s.inputChunk != nil {
+		if err := s.inputChunk.Close(ctx); err != nil {
+			colexecerror.InternalError(err)
+		}
+	}
+	if s.streamerInfo.Streamer != nil {
+		s.streamerInfo.Streamer.Close()
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scexec"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+)
+
+type commentOnFunctionNode struct {
+	n         *tree.CommentOnFunction
+	fnDesc    catalog.FunctionDescriptor
+	commenter scexec.CommentUpdater
+}
+
+// CommentOnFunction add comment on a user-defined function.
+// Privileges: ownership of the function or the admin role.
+//   notes: postgres requires ownership of the function.
+func (p *planner) CommentOnFunction(
+	ctx context.Context, n *tree.CommentOnFunction,
+) (planNode, error) {
+	if err := checkUserDefinedFunctionsSupported(ctx, p); err != nil {
+		return nil, err
+	}
+	if err := checkSchemaChangeEnabled(
+		ctx,
+		p.ExecCfg(),
+		"COMMENT ON FUNCTION",
+	); err != nil {
+		return nil, err
+	}
+
+	fnDesc, err := p.resolveMutableFunction(ctx, &n.Function)
+	if err != nil {
+		return nil, err
+	}
+	if fnDesc == nil {
+		return nil, pgerror.Newf(pgcode.UndefinedFunction,
+			"function %s does not exist", tree.AsString(&n.Function))
+	}
+	if err := p.checkFunctionOwnership(ctx, fnDesc); err != nil {
+		return nil, err
+	}
+
+	return &commentOnFunctionNode{
+		n:      n,
+		fnDesc: fnDesc,
+		commenter: p.execCfg.CommentUpdaterFactory.NewCommentUpdater(
+			ctx,
+			p.txn,
+			p.SessionData(),
+		),
+	}, nil
+}
+
+func (n *commentOnFunctionNode) startExec(params runParams) error {
+	if n.n.Comment != nil {
+		err := n.commenter.UpsertDescriptorComment(
+			int64(n.fnDesc.GetID()), 0, keys.FunctionCommentType, *n.n.Comment)
+		if err != nil {
+			return err
+		}
+	} else {
+		err := n.commenter.DeleteDescriptorComment(
+			int64(n.fnDesc.GetID()), 0, keys.FunctionCommentType)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (n *commentOnFunctionNode) Next(runParams) (bool, error) { return false, nil }
+func (n *commentOnFunctionNode) Values() tree.Datums          { return tree.Datums{} }
+func (n *commentOnFunctionNode) Close(context.Context)        {}
+
+func (p *planner) removeFunctionComment(ctx context.Context, fnID descpb.ID) error {
+	_, err := p.ExtendedEvalContext().ExecCfg.InternalExecutor.ExecEx(
+		ctx,
+		"delete-function-comment",
+		p.txn,
+		sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+		"DELETE FROM system.comments WHERE type=$1 AND object_id=$2 AND sub_id=0",
+		keys.FunctionCommentType,
+		fnID)
+
+	return err
+}
@@ -21,6 +21,7 @@ import (
 	"time"
 	"unicode/utf8"
 
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/kv"
@@ -48,6 +49,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlstats/persistedsqlstats"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlstats/sslocal"
 	"github.com/cockroachdb/cockroach/pkg/sql/stmtdiagnostics"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/buildutil"
 	"github.com/cockroachdb/cockroach/pkg/util/envutil"
@@ -851,6 +853,7 @@ func (s *Server) newConnExecutor(
 		portals:   make(map[string]PreparedPortal),
 	}
 	ex.extraTxnState.prepStmtsNamespaceMemAcc = ex.sessionMon.MakeBoundAccount()
+	ex.extraTxnState.deferredChecks.memAcc = ex.sessionMon.MakeBoundAccount()
 	ex.extraTxnState.descCollection = s.cfg.CollectionFactory.MakeCollection(
 		descs.NewTemporarySchemaProvider(sdMutIterator.sds),
 	)
@@ -925,6 +928,7 @@ func (s *Server) newConnExecutorWithTxn(
 		txn.ReadTimestamp().GoTime(),
 		nil, /* historicalTimestamp */
 		roachpb.UnspecifiedUserPriority,
+		enginepb.Serializable, /* isoLevel */
 		tree.ReadWrite,
 		txn,
 		ex.transitionCtx)
@@ -1053,6 +1057,7 @@ func (ex *connExecutor) close(ctx context.Context, closeType closeType) {
 			ctx, &ex.extraTxnState.prepStmtsNamespaceMemAcc,
 		)
 		ex.extraTxnState.prepStmtsNamespaceMemAcc.Close(ctx)
+		ex.extraTxnState.deferredChecks.memAcc.Close(ctx)
 	}
 
 	if ex.sessionTracing.Enabled() {
@@ -1159,6 +1164,11 @@ type connExecutor struct {
 		// that staged them commits.
 		jobs jobsCollection
 
+		// deferredChecks accumulates the checks of deferrable constraints that
+		// were deferred by statements in the transaction, along with the mode set
+		// by SET CONSTRAINTS. The checks are run before the transaction commits.
+		deferredChecks deferredCheckCollection
+
 		// schemaChangeJobRecords is a map of descriptor IDs to job Records.
 		// Used in createOrUpdateSchemaChangeJob so we can check if a job has been
 		// queued up for the given ID. The cache remains valid only for the current
@@ -1519,6 +1529,8 @@ func (ns *prepStmtNamespace) resetTo(
 // commits, rolls back or restarts.
 func (ex *connExecutor) resetExtraTxnState(ctx context.Context, ev txnEvent) error {
 	ex.extraTxnState.jobs = nil
+	ex.extraTxnState.deferredChecks.mode = tree.UnspecifiedConstraintsMode
+	ex.extraTxnState.deferredChecks.reset(ctx)
 	ex.extraTxnState.hasAdminRoleCache = HasAdminRoleCache{}
 	ex.extraTxnState.schemaChangerState = SchemaChangerState{
 		mode: ex.sessionData().NewSchemaChangerMode,
@@ -1892,6 +1904,13 @@ func (ex *connExecutor) execCmd(ctx context.Context) error {
 		if err != nil {
 			return err
 		}
+	case StartReplication:
+		res = ex.clientComm.CreateCopyInResult(pos)
+		var err error
+		ev, payload, err = ex.execStartReplication(ctx, tcmd)
+		if err != nil {
+			return err
+		}
 	case DrainRequest:
 		// We received a drain request. We terminate immediately if we're not in a
 		// transaction. If we are in a transaction, we'll finish as soon as a Sync
@@ -2109,6 +2128,8 @@ func (ex *connExecutor) updateTxnRewindPosMaybe(
 				canAdvance = true
 			case CopyIn:
 				// Can't advance.
+			case StartReplication:
+				// Can't advance.
 			case DrainRequest:
 				canAdvance = true
 			case Flush:
@@ -2279,6 +2300,46 @@ func (ex *connExecutor) execCopyIn(
 	return nil, nil, nil
 }
 
+// execStartReplication streams the changes of the replication slot named in
+// the START_REPLICATION command to the client. Like execCopyIn, it takes
+// control of the network connection until the client ends the stream.
+func (ex *connExecutor) execStartReplication(
+	ctx context.Context, cmd StartReplication,
+) (_ fsm.Event, retPayload fsm.EventPayload, retErr error) {
+	ex.incrementStartedStmtCounter(cmd.Stmt)
+	defer func() {
+		if retErr == nil && !payloadHasError(retPayload) {
+			ex.incrementExecutedStmtCounter(cmd.Stmt)
+		}
+	}()
+
+	// When we're done, unblock the network connection.
+	defer cmd.Done.Done()
+
+	if _, isNoTxn := ex.machine.CurState().(stateNoTxn); !isNoTxn {
+		ev := eventNonRetriableErr{IsCommit: fsm.False}
+		payload := eventNonRetriableErrPayload{err: pgerror.New(pgcode.ActiveSQLTransaction,
+			"START_REPLICATION cannot run inside a transaction block")}
+		return ev, payload, nil
+	}
+
+	feed, err := newReplicationFeed(ctx, ex.server.cfg, ex.sessionData(), cmd.Stmt)
+	if err != nil {
+		ev := eventNonRetriableErr{IsCommit: fsm.False}
+		payload := eventNonRetriableErrPayload{err: err}
+		return ev, payload, nil
+	}
+	defer feed.Close(ctx)
+	sd := ex.sessionData()
+	if err := cmd.Conn.ServeReplication(ctx, feed, sd.DataConversionConfig, sd.GetLocation()); err != nil {
+		// The error was reported to the client, and the connection closed, by
+		// ServeReplication. Returning it ends the session without reporting it
+		// a second time.
+		return nil, nil, err
+	}
+	return nil, nil, nil
+}
+
 // stmtHasNoData returns true if describing a result of the input statement
 // type should return NoData.
 func stmtHasNoData(stmt tree.Statement) bool {
@@ -2419,9 +2480,10 @@ func (ex *connExecutor) setTransactionModes(
 			return err
 		}
 	}
-	if modes.Isolation != tree.UnspecifiedIsolation && modes.Isolation != tree.SerializableIsolation {
-		return errors.AssertionFailedf(
-			"unknown isolation level: %s", errors.Safe(modes.Isolation))
+	if modes.Isolation != tree.UnspecifiedIsolation {
+		if err := ex.state.setIsolationLevel(ex.txnIsoLevelToKV(modes.Isolation)); err != nil {
+			return pgerror.WithCandidateCode(err, pgcode.ActiveSQLTransaction)
+		}
 	}
 	rwMode := modes.ReadWriteMode
 	if modes.AsOf.Expr != nil && asOfTs.IsEmpty() {
@@ -2463,6 +2525,35 @@ func (ex *connExecutor) txnPriorityWithSessionDefault(mode tree.UserPriority) ro
 	return txnPriorityToProto(mode)
 }
 
+// txnIsoLevelToKV converts the given SQL isolation level into the isolation
+// level used by the KV transaction. READ COMMITTED is upgraded to SERIALIZABLE
+// unless it has been enabled through the cluster setting and all nodes
+// understand it.
+func (ex *connExecutor) txnIsoLevelToKV(level tree.IsolationLevel) enginepb.IsolationLevel {
+	switch level {
+	case tree.UnspecifiedIsolation, tree.SerializableIsolation:
+		return enginepb.Serializable
+	case tree.ReadCommittedIsolation:
+		if allowReadCommittedIsolation.Get(&ex.server.cfg.Settings.SV) &&
+			ex.server.cfg.Settings.Version.IsActive(ex.Ctx(), clusterversion.ReadCommittedIsolation) {
+			return enginepb.ReadCommitted
+		}
+		return enginepb.Serializable
+	default:
+		log.Fatalf(context.Background(), "unknown isolation level: %s", level)
+	}
+	return enginepb.Serializable
+}
+
+func (ex *connExecutor) txnIsoLevelWithSessionDefault(
+	level tree.IsolationLevel,
+) enginepb.IsolationLevel {
+	if level == tree.UnspecifiedIsolation {
+		level = tree.IsolationLevel(ex.sessionData().DefaultTxnIsolationLevel)
+	}
+	return ex.txnIsoLevelToKV(level)
+}
+
 func (ex *connExecutor) readWriteModeWithSessionDefault(
 	mode tree.ReadWriteMode,
 ) tree.ReadWriteMode {
@@ -2520,6 +2611,7 @@ func (ex *connExecutor) initEvalCtx(ctx context.Context, evalCtx *extendedEvalCo
 		Descs:                  &ex.extraTxnState.descCollection,
 		TxnModesSetter:         ex,
 		Jobs:                   &ex.extraTxnState.jobs,
+		DeferredChecks:         &ex.extraTxnState.deferredChecks,
 		SchemaChangeJobRecords: ex.extraTxnState.schemaChangeJobRecords,
 		statsProvider:          ex.server.sqlStats,
 		indexUsageStats:        ex.indexUsageStats,
@@ -2538,6 +2630,7 @@ func (ex *connExecutor) resetEvalCtx(evalCtx *extendedEvalContext, txn *kv.Txn,
 	evalCtx.TxnState = ex.getTransactionState()
 	evalCtx.TxnReadOnly = ex.state.readOnly
 	evalCtx.TxnImplicit = ex.implicitTxn()
+	evalCtx.TxnConstraintsMode = ex.extraTxnState.deferredChecks.mode
 	evalCtx.StmtTimestamp = stmtTS
 	evalCtx.TxnTimestamp = ex.state.sqlTimestamp
 	evalCtx.Placeholders = nil
@@ -702,6 +702,17 @@ func (ex *connExecutor) execStmtInOpenState(
 		return makeErrEvent(err)
 	}
 
+	// Under isolation levels that take a new read snapshot for each statement
+	// (i.e. READ COMMITTED), advance the transaction's read timestamp so that
+	// the statement observes all the writes committed before it started. This
+	// is skipped for the internal executor, whose statements run on behalf of
+	// (and must observe the same snapshot as) an outer statement.
+	if ex.executorType != executorTypeInternal {
+		if err := ex.state.mu.txn.StepReadTimestamp(ctx); err != nil {
+			return makeErrEvent(err)
+		}
+	}
+
 	if err := p.semaCtx.Placeholders.Assign(pinfo, stmt.NumPlaceholders); err != nil {
 		return makeErrEvent(err)
 	}
@@ -719,7 +730,7 @@ func (ex *connExecutor) execStmtInOpenState(
 		ctx, stmtThresholdSpan = createRootOrChildSpan(ctx, "trace-stmt-threshold", ex.transitionCtx.tracer, tracing.WithRecording(tracing.RecordingVerbose))
 	}
 
-	if err := ex.dispatchToExecutionEngine(ctx, p, res); err != nil {
+	if err := ex.dispatchReadCommittedStmtToExecutionEngine(ctx, p, res); err != nil {
 		stmtThresholdSpan.Finish()
 		return nil, nil, err
 	}
@@ -883,6 +894,13 @@ func (ex *connExecutor) reportSessionDataChanges(fn func() error) error {
 func (ex *connExecutor) commitSQLTransactionInternal(
 	ctx context.Context, ast tree.Statement,
 ) error {
+	// Validate any constraints whose checks were deferred until commit. This
+	// happens before anything else so that a failing check aborts the
+	// transaction before jobs are created.
+	if err := ex.planner.runDeferredChecks(ctx); err != nil {
+		return err
+	}
+
 	if err := ex.createJobs(ctx); err != nil {
 		return err
 	}
@@ -950,6 +968,103 @@ func (ex *connExecutor) rollbackSQLTransaction(
 	return eventTxnFinishAborted{}, nil
 }
 
+// dispatchReadCommittedStmtToExecutionEngine executes the statement like
+// dispatchToExecutionEngine. In transactions which take a new read snapshot for
+// each statement (i.e. READ COMMITTED), a statement that encounters a
+// retryable error (such as a WriteTooOldError, or a failure to refresh its
+// reads) is rolled back and retried at a new snapshot, without restarting the
+// transaction. This is only done while none of the results of the statement
+// have been sent to the client: the results buffered so far are then discarded
+// before the statement is retried. Other errors are handled by restarting the
+// whole transaction.
+//
+// DDL statements are never retried on their own: like ROLLBACK TO SAVEPOINT
+// (see rollbackToSavepoint), rolling back the KV writes of a schema change
+// would leave the modified descriptors and the queued schema change jobs of
+// the transaction behind. The transaction is restarted instead.
+func (ex *connExecutor) dispatchReadCommittedStmtToExecutionEngine(
+	ctx context.Context, p *planner, res RestrictedCommandResult,
+) error {
+	txn := ex.state.mu.txn
+	if ex.executorType == executorTypeInternal || !txn.IsoLevel().PerStatementReadSnapshot() {
+		return ex.dispatchToExecutionEngine(ctx, p, res)
+	}
+	// The position of the statement is used to discard its buffered results. It
+	// is read before executing the statement since a portal which is suspended
+	// advances the StmtBuf while it runs.
+	_, pos, err := ex.stmtBuf.CurCmd()
+	if err != nil {
+		return err
+	}
+	maxRetries := readCommittedStmtRetries.Get(&ex.server.cfg.Settings.SV)
+	if tree.CanModifySchema(p.stmt.AST) {
+		return ex.dispatchToExecutionEngine(ctx, p, res)
+	}
+	for attempt := int64(0); ; attempt++ {
+		savepoint, err := txn.CreateSavepoint(ctx)
+		if err != nil {
+			return err
+		}
+		numDDL := ex.extraTxnState.numDDL
+		if err := ex.dispatchToExecutionEngine(ctx, p, res); err != nil {
+			return err
+		}
+		var retryErr *roachpb.TransactionRetryWithProtoRefreshError
+		if !errors.As(res.Err(), &retryErr) || retryErr.PrevTxnAborted() || attempt >= maxRetries {
+			return nil
+		}
+		// The plan of a statement which is not DDL itself may still contain
+		// DDL, for example in a statement source.
+		if ex.extraTxnState.numDDL != numDDL {
+			log.VEventf(ctx, 2, "cannot retry statement: it executed DDL")
+			return nil
+		}
+		// If results of the statement were already sent to the client, or the
+		// transaction cannot be partially retried, the error is left in res so
+		// that the transaction is restarted.
+		if !ex.discardStmtResults(ctx, pos, res) {
+			log.VEventf(ctx, 2, "cannot retry statement: results were sent to the client")
+			return nil
+		}
+		if err := txn.PrepareForPartialRetry(ctx); err != nil {
+			log.VEventf(ctx, 2, "cannot retry statement: %v", err)
+			return nil
+		}
+		if err := txn.RollbackToSavepoint(ctx, savepoint); err != nil {
+			log.VEventf(ctx, 2, "cannot retry statement: %v", err)
+			return nil
+		}
+		if err := txn.Step(ctx); err != nil {
+			return err
+		}
+		log.VEventf(ctx, 2, "retrying statement after error: %v", retryErr)
+		res.SetError(nil)
+	}
+}
+
+// discardStmtResults discards the results buffered for the statement at pos,
+// so that it can be executed again. Like the rewinding of a transaction (see
+// getRewindTxnCapability), this is only possible if none of the results of
+// the statement have been delivered to the client yet; false is returned
+// otherwise.
+func (ex *connExecutor) discardStmtResults(
+	ctx context.Context, pos CmdPos, res RestrictedCommandResult,
+) bool {
+	if ex.executorType == executorTypeInternal {
+		// The internal executor streams the rows of a statement to its caller
+		// as they are produced, so they can never be discarded.
+		return false
+	}
+	cl := ex.clientComm.LockCommunication()
+	defer cl.Close()
+	if cl.ClientPos() >= pos {
+		return false
+	}
+	cl.RTrim(ctx, pos)
+	res.ResetRows()
+	return true
+}
+
 // dispatchToExecutionEngine executes the statement, writes the result to res
 // and returns an event for the connection's state machine.
 //
@@ -1512,6 +1627,7 @@ func (ex *connExecutor) execStmtInNoTxnState(
 		return eventStartExplicitTxn,
 			makeEventTxnStartPayload(
 				ex.txnPriorityWithSessionDefault(s.Modes.UserPriority),
+				ex.txnIsoLevelWithSessionDefault(s.Modes.Isolation),
 				mode,
 				sqlTs,
 				historicalTs,
@@ -1532,6 +1648,7 @@ func (ex *connExecutor) execStmtInNoTxnState(
 		return eventStartImplicitTxn,
 			makeEventTxnStartPayload(
 				ex.txnPriorityWithSessionDefault(tree.UnspecifiedUserPriority),
+				ex.txnIsoLevelWithSessionDefault(tree.UnspecifiedIsolation),
 				mode,
 				sqlTs,
 				historicalTs,
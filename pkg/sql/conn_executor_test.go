@@ -11,6 +11,7 @@
 package sql_test
 
 import (
+	"bytes"
 	"context"
 	gosql "database/sql"
 	"database/sql/driver"
@@ -41,6 +42,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlliveness"
 	"github.com/cockroachdb/cockroach/pkg/sql/tests"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/pgtest"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
@@ -1402,6 +1404,247 @@ func TestInjectRetryErrors(t *testing.T) {
 	})
 }
 
+// TestReadCommittedStatementRetry checks that a statement of a READ COMMITTED
+// transaction which encounters a retryable error is retried on its own, without
+// restarting the transaction and discarding the writes of earlier statements.
+func TestReadCommittedStatementRetry(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	filter := newDynamicRequestFilter()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{
+		Knobs: base.TestingKnobs{
+			Store: &kvserver.StoreTestingKnobs{
+				TestingRequestFilter: filter.filter,
+			},
+		},
+	})
+	defer s.Stopper().Stop(ctx)
+
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `SET CLUSTER SETTING sql.txn.read_committed_isolation.enabled = true`)
+	sqlDB.Exec(t, `CREATE TABLE t (k INT PRIMARY KEY, v INT)`)
+	sqlDB.Exec(t, `INSERT INTO t VALUES (1, 1), (2, 2)`)
+	var tableID uint32
+	sqlDB.QueryRow(t, `SELECT 't'::regclass::oid`).Scan(&tableID)
+	tablePrefix := keys.SystemSQLCodec.TablePrefix(tableID)
+
+	// Inject retryable errors which cannot be handled by refreshing the reads
+	// of the statement into the writes of a READ COMMITTED transaction.
+	const numInjected = 3
+	var injected int64
+	filter.setFilter(func(_ context.Context, ba roachpb.BatchRequest) *roachpb.Error {
+		if ba.Txn == nil || ba.Txn.IsoLevel != enginepb.ReadCommitted {
+			return nil
+		}
+		for _, ru := range ba.Requests {
+			put, ok := ru.GetInner().(*roachpb.PutRequest)
+			if ok && bytes.HasPrefix(put.Key, tablePrefix) && atomic.LoadInt64(&injected) < numInjected {
+				atomic.AddInt64(&injected, 1)
+				return roachpb.NewErrorWithTxn(
+					roachpb.NewTransactionRetryError(roachpb.RETRY_REASON_UNKNOWN, "injected"), ba.Txn,
+				)
+			}
+		}
+		return nil
+	})
+	defer filter.setFilter(nil)
+
+	// The statement is retried until it succeeds, so the client does not see
+	// the errors, and the transaction keeps the write of its first statement.
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	_, err = tx.Exec(`SET TRANSACTION ISOLATION LEVEL READ COMMITTED`)
+	require.NoError(t, err)
+	_, err = tx.Exec(`SELECT * FROM t`)
+	require.NoError(t, err)
+	_, err = tx.Exec(`UPDATE t SET v = 10 WHERE k = 1`)
+	require.NoError(t, err)
+	_, err = tx.Exec(`UPDATE t SET v = 20 WHERE k = 2`)
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+
+	require.Equal(t, int64(numInjected), atomic.LoadInt64(&injected))
+	sqlDB.CheckQueryResults(t, `SELECT * FROM t ORDER BY k`, [][]string{{"1", "10"}, {"2", "20"}})
+}
+
+// TestReadCommittedRowsStatementRetry checks that statements of a READ
+// COMMITTED transaction which return rows, such as SELECT or mutations with a
+// RETURNING clause, are retried on their own after a retryable error, as long
+// as none of their rows have been sent to the client.
+func TestReadCommittedRowsStatementRetry(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	filter := newDynamicRequestFilter()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{
+		Knobs: base.TestingKnobs{
+			Store: &kvserver.StoreTestingKnobs{
+				TestingRequestFilter: filter.filter,
+			},
+		},
+	})
+	defer s.Stopper().Stop(ctx)
+
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `SET CLUSTER SETTING sql.txn.read_committed_isolation.enabled = true`)
+	sqlDB.Exec(t, `CREATE TABLE t (k INT PRIMARY KEY, v INT)`)
+	sqlDB.Exec(t, `INSERT INTO t VALUES (1, 1), (2, 2)`)
+	var tableID uint32
+	sqlDB.QueryRow(t, `SELECT 't'::regclass::oid`).Scan(&tableID)
+	tablePrefix := keys.SystemSQLCodec.TablePrefix(tableID)
+
+	// queryInTxn runs a query in a READ COMMITTED transaction which first writes
+	// to t, and returns the rows of the query. Retryable errors which cannot be
+	// handled by refreshing the reads of the query are injected into its
+	// requests on t for which isTarget returns true.
+	const numInjected = 3
+	var injected int64
+	queryInTxn := func(query string, isTarget func(roachpb.Request) bool) [][]string {
+		defer filter.setFilter(nil)
+		tx, err := db.Begin()
+		require.NoError(t, err)
+		defer func() { _ = tx.Rollback() }()
+		_, err = tx.Exec(`SET TRANSACTION ISOLATION LEVEL READ COMMITTED`)
+		require.NoError(t, err)
+		_, err = tx.Exec(`INSERT INTO t VALUES (3, 3)`)
+		require.NoError(t, err)
+
+		atomic.StoreInt64(&injected, 0)
+		filter.setFilter(func(_ context.Context, ba roachpb.BatchRequest) *roachpb.Error {
+			if ba.Txn == nil || ba.Txn.IsoLevel != enginepb.ReadCommitted {
+				return nil
+			}
+			for _, ru := range ba.Requests {
+				req := ru.GetInner()
+				if isTarget(req) && bytes.HasPrefix(req.Header().Key, tablePrefix) &&
+					atomic.LoadInt64(&injected) < numInjected {
+					atomic.AddInt64(&injected, 1)
+					return roachpb.NewErrorWithTxn(
+						roachpb.NewTransactionRetryError(roachpb.RETRY_REASON_UNKNOWN, "injected"), ba.Txn,
+					)
+				}
+			}
+			return nil
+		})
+		rows, err := tx.Query(query)
+		require.NoError(t, err)
+		res, err := sqlutils.RowsToStrMatrix(rows)
+		require.NoError(t, err)
+		require.NoError(t, tx.Commit())
+		return res
+	}
+
+	t.Run("select", func(t *testing.T) {
+		defer sqlDB.Exec(t, `DELETE FROM t WHERE k = 3`)
+		// The rows read by the failed attempts are not returned to the client
+		// along with the ones of the attempt which succeeds.
+		res := queryInTxn(`SELECT k, v FROM t ORDER BY k`, func(req roachpb.Request) bool {
+			_, ok := req.(*roachpb.ScanRequest)
+			return ok
+		})
+		require.Equal(t, int64(numInjected), atomic.LoadInt64(&injected))
+		require.Equal(t, [][]string{{"1", "1"}, {"2", "2"}, {"3", "3"}}, res)
+	})
+
+	t.Run("returning", func(t *testing.T) {
+		defer sqlDB.Exec(t, `DELETE FROM t WHERE k = 3`)
+		res := queryInTxn(`UPDATE t SET v = v * 10 WHERE k IN (1, 2) RETURNING k, v`,
+			func(req roachpb.Request) bool {
+				_, ok := req.(*roachpb.PutRequest)
+				return ok
+			})
+		require.Equal(t, int64(numInjected), atomic.LoadInt64(&injected))
+		require.ElementsMatch(t, [][]string{{"1", "10"}, {"2", "20"}}, res)
+		sqlDB.CheckQueryResults(t, `SELECT * FROM t ORDER BY k`,
+			[][]string{{"1", "10"}, {"2", "20"}, {"3", "3"}})
+	})
+}
+
+// TestReadCommittedDDLStatementNotRetried checks that a DDL statement of a READ
+// COMMITTED transaction which encounters a retryable error is not retried on
+// its own, since rolling back its writes would not undo the descriptor changes
+// and schema change jobs of the transaction. The whole transaction is
+// restarted instead.
+func TestReadCommittedDDLStatementNotRetried(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	filter := newDynamicRequestFilter()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{
+		Knobs: base.TestingKnobs{
+			Store: &kvserver.StoreTestingKnobs{
+				TestingRequestFilter: filter.filter,
+			},
+		},
+	})
+	defer s.Stopper().Stop(ctx)
+
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `SET CLUSTER SETTING sql.txn.read_committed_isolation.enabled = true`)
+	sqlDB.Exec(t, `CREATE TABLE t (k INT PRIMARY KEY, v INT)`)
+	var tableID uint32
+	sqlDB.QueryRow(t, `SELECT 't'::regclass::oid`).Scan(&tableID)
+	descKey := keys.SystemSQLCodec.DescMetadataKey(tableID)
+
+	// Inject a retryable error into the write of the descriptor of t by a READ
+	// COMMITTED transaction.
+	var injected int64
+	filter.setFilter(func(_ context.Context, ba roachpb.BatchRequest) *roachpb.Error {
+		if ba.Txn == nil || ba.Txn.IsoLevel != enginepb.ReadCommitted {
+			return nil
+		}
+		for _, ru := range ba.Requests {
+			put, ok := ru.GetInner().(*roachpb.PutRequest)
+			if ok && put.Key.Equal(descKey) && atomic.CompareAndSwapInt64(&injected, 0, 1) {
+				return roachpb.NewErrorWithTxn(
+					roachpb.NewTransactionRetryError(roachpb.RETRY_REASON_UNKNOWN, "injected"), ba.Txn,
+				)
+			}
+		}
+		return nil
+	})
+	defer filter.setFilter(nil)
+
+	runTxn := func() error {
+		tx, err := db.Begin()
+		require.NoError(t, err)
+		defer func() { _ = tx.Rollback() }()
+		_, err = tx.Exec(`SET TRANSACTION ISOLATION LEVEL READ COMMITTED`)
+		require.NoError(t, err)
+		_, err = tx.Exec(`INSERT INTO t VALUES (1, 1)`)
+		require.NoError(t, err)
+		if _, err := tx.Exec(`ALTER TABLE t ADD COLUMN w INT`); err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
+
+	// The results of the earlier statements were sent to the client, so the
+	// restart of the transaction surfaces as a retry error.
+	err := runTxn()
+	require.Equal(t, int64(1), atomic.LoadInt64(&injected))
+	var pqErr *pq.Error
+	require.True(t, errors.As(err, &pqErr), "expected a pq error, got %v", err)
+	require.Equal(t, pgcode.SerializationFailure.String(), string(pqErr.Code))
+	sqlDB.CheckQueryResults(t, `SELECT count(*) FROM t`, [][]string{{"0"}})
+	sqlDB.CheckQueryResults(t,
+		`SELECT column_name FROM [SHOW COLUMNS FROM t] ORDER BY column_name`,
+		[][]string{{"k"}, {"v"}},
+	)
+	sqlDB.CheckQueryResults(t,
+		`SELECT count(*) FROM [SHOW JOBS] WHERE description LIKE 'ALTER TABLE%ADD COLUMN w%'`,
+		[][]string{{"0"}},
+	)
+
+	// The transaction succeeds when it is retried by the client.
+	require.NoError(t, runTxn())
+	sqlDB.CheckQueryResults(t, `SELECT k, v, w FROM t`, [][]string{{"1", "1", "NULL"}})
+}
+
 // dynamicRequestFilter exposes a filter method which is a
 // kvserverbase.ReplicaRequestFilter but can be set dynamically.
 type dynamicRequestFilter struct {
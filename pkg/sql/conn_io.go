@@ -332,6 +332,27 @@ func (CopyIn) String() string {
 
 var _ Command = CopyIn{}
 
+// StartReplication is the command for the execution of the START_REPLICATION
+// command of the streaming replication protocol.
+type StartReplication struct {
+	Stmt *tree.StartReplication
+	// Conn is the network connection. Execution of the command takes control of
+	// the connection until the client ends the replication stream.
+	Conn ReplicationConn
+	// Done is decremented once execution finishes, signaling that control of
+	// the connection is being handed back to the network routine.
+	Done *sync.WaitGroup
+}
+
+// command implements the Command interface.
+func (StartReplication) command() string { return "start replication" }
+
+func (StartReplication) String() string {
+	return "StartReplication"
+}
+
+var _ Command = StartReplication{}
+
 // DrainRequest represents a notice that the server is draining and command
 // processing should stop soon.
 //
@@ -766,6 +787,11 @@ type RestrictedCommandResult interface {
 	// sum of all n passed into IncrementRowsAffected.
 	RowsAffected() int
 
+	// ResetRows forgets the rows added to the result and the rows affected so
+	// far, so that the statement can be executed again. The results buffered
+	// for the statement must have been discarded with ClientLock.RTrim.
+	ResetRows()
+
 	// DisableBuffering can be called during execution to ensure that
 	// the results accumulated so far, and all subsequent rows added
 	// to this CommandResult, will be flushed immediately to the client.
@@ -999,6 +1025,16 @@ func (r *streamingCommandResult) RowsAffected() int {
 	return r.rowsAffected
 }
 
+// ResetRows is part of the RestrictedCommandResult interface.
+//
+// The rows are handed to the ieResultWriter as they are produced, so they
+// can't be taken back. Statements run by the internal executor are never
+// executed again (see dispatchReadCommittedStmtToExecutionEngine and
+// discardStmtResults).
+func (r *streamingCommandResult) ResetRows() {
+	panic(errors.AssertionFailedf("the rows of an internal executor statement cannot be reset"))
+}
+
 // Close is part of the CommandResultClose interface.
 func (r *streamingCommandResult) Close(context.Context, TransactionStatusIndicator) {
 	if r.closeCallback != nil {
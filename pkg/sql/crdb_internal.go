@@ -23,6 +23,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/build"
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/config/zonepb"
 	"github.com/cockroachdb/cockroach/pkg/gossip"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
@@ -117,6 +118,7 @@ var crdbInternal = virtualSchema{
 		catconstants.CrdbInternalGossipLivenessTableID:            crdbInternalGossipLivenessTable,
 		catconstants.CrdbInternalGossipNetworkTableID:             crdbInternalGossipNetworkTable,
 		catconstants.CrdbInternalIndexColumnsTableID:              crdbInternalIndexColumnsTable,
+		catconstants.CrdbInternalIndexRecommendationsTableID:      crdbInternalIndexRecommendationsTable,
 		catconstants.CrdbInternalIndexUsageStatisticsTableID:      crdbInternalIndexUsageStatistics,
 		catconstants.CrdbInternalInflightTraceSpanTableID:         crdbInternalInflightTraceSpanTable,
 		catconstants.CrdbInternalJobsTableID:                      crdbInternalJobsTable,
@@ -132,6 +134,7 @@ var crdbInternal = virtualSchema{
 		catconstants.CrdbInternalNodeStmtStatsTableID:             crdbInternalNodeStmtStatsTable,
 		catconstants.CrdbInternalNodeTxnStatsTableID:              crdbInternalNodeTxnStatsTable,
 		catconstants.CrdbInternalPartitionsTableID:                crdbInternalPartitionsTable,
+		catconstants.CrdbInternalPlanBaselinesTableID:             crdbInternalPlanBaselinesTable,
 		catconstants.CrdbInternalPredefinedCommentsTableID:        crdbInternalPredefinedCommentsTable,
 		catconstants.CrdbInternalRangesNoLeasesTableID:            crdbInternalRangesNoLeasesTable,
 		catconstants.CrdbInternalRangesViewID:                     crdbInternalRangesView,
@@ -4342,6 +4345,118 @@ CREATE TABLE crdb_internal.predefined_comments (
 	},
 }
 
+var crdbInternalPlanBaselinesTable = virtualSchemaTable{
+	comment: `plan baselines of statement fingerprints (KV scan)`,
+	schema: `
+CREATE TABLE crdb_internal.plan_baselines (
+  id          INT NOT NULL,
+  fingerprint STRING NOT NULL,
+  database    STRING NOT NULL,
+  query       STRING NOT NULL,
+  hints       JSONB NOT NULL,
+  plan        STRING NOT NULL,
+  cost        FLOAT NOT NULL,
+  accepted    BOOL NOT NULL,
+  enforced    BOOL NOT NULL,
+  created     TIMESTAMPTZ NOT NULL
+)`,
+	populate: func(ctx context.Context, p *planner, _ catalog.DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		if err := p.RequireAdminRole(ctx, "read crdb_internal.plan_baselines"); err != nil {
+			return err
+		}
+		if !p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.StatementPlanBaselines) {
+			return nil
+		}
+		rows, err := p.ExtendedEvalContext().ExecCfg.InternalExecutor.QueryBufferedEx(
+			ctx, "crdb-internal-plan-baselines-table", p.txn,
+			sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+			`SELECT id, fingerprint, database, query, hints, plan, cost, accepted, enforced, created
+         FROM system.statement_plan_baselines
+        ORDER BY id`)
+		if err != nil {
+			return err
+		}
+		for _, r := range rows {
+			if err := addRow(r...); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+var crdbInternalIndexRecommendationsTable = virtualSchemaTable{
+	comment: `index advice consolidated for the workload by the automatic index recommendations job`,
+	schema: `
+CREATE TABLE crdb_internal.index_recommendations (
+  type          STRING NOT NULL,
+  database_name STRING NOT NULL,
+  schema_name   STRING NOT NULL,
+  table_name    STRING NOT NULL,
+  table_id      INT NOT NULL,
+  index_name    STRING,
+  sql           STRING NOT NULL,
+  fingerprints  INT NOT NULL,
+  executions    INT NOT NULL,
+  total_latency INTERVAL NOT NULL,
+  last_read     TIMESTAMPTZ,
+  refreshed     TIMESTAMPTZ NOT NULL
+)`,
+	populate: func(ctx context.Context, p *planner, _ catalog.DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		if err := p.RequireAdminRole(ctx, "read crdb_internal.index_recommendations"); err != nil {
+			return err
+		}
+		advisor := p.ExecCfg().IndexAdvisor
+		if advisor == nil {
+			return nil
+		}
+		advice, refreshed, err := advisor.Advice(ctx)
+		if err != nil {
+			return err
+		}
+		if len(advice) == 0 {
+			return nil
+		}
+		refreshedDatum, err := tree.MakeDTimestampTZ(refreshed, time.Microsecond)
+		if err != nil {
+			return err
+		}
+		for i := range advice {
+			a := &advice[i]
+			indexName := tree.DNull
+			if a.IndexName != "" {
+				indexName = tree.NewDString(a.IndexName)
+			}
+			lastRead := tree.DNull
+			if !a.LastRead.IsZero() {
+				if lastRead, err = tree.MakeDTimestampTZ(a.LastRead, time.Microsecond); err != nil {
+					return err
+				}
+			}
+			if err := addRow(
+				tree.NewDString(a.Type.String()),
+				tree.NewDString(a.Table.DatabaseName),
+				tree.NewDString(a.Table.SchemaName),
+				tree.NewDString(a.Table.Name),
+				tree.NewDInt(tree.DInt(a.Table.ID)),
+				indexName,
+				tree.NewDString(a.SQL),
+				tree.NewDInt(tree.DInt(a.Fingerprints)),
+				tree.NewDInt(tree.DInt(a.Executions)),
+				tree.NewDInterval(
+					duration.MakeDuration(a.TotalLatency.Nanoseconds(), 0, 0),
+					types.DefaultIntervalTypeMetadata,
+				),
+				lastRead,
+				refreshedDatum,
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
 type marshaledJobMetadata struct {
 	status                      *tree.DString
 	payloadBytes, progressBytes *tree.DBytes
@@ -0,0 +1,744 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkv"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemadesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/optbuilder"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/xform"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+)
+
+// checkUserDefinedFunctionsSupported returns an error if the cluster version
+// does not allow schema descriptors to contain functions yet.
+func checkUserDefinedFunctionsSupported(ctx context.Context, p *planner) error {
+	if !p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.UserDefinedFunctions) {
+		return pgerror.New(pgcode.FeatureNotSupported,
+			"user-defined functions are not supported until version upgrade is finalized")
+	}
+	return nil
+}
+
+type createFunctionNode struct {
+	n      *tree.CreateFunction
+	scDesc *schemadesc.Mutable
+	fn     descpb.FunctionDescriptor
+	// existing is the function replaced by CREATE OR REPLACE FUNCTION, if any.
+	existing *funcdesc.Mutable
+}
+
+// CreateFunction creates a user-defined SQL function in a user-defined schema,
+// or in a public schema backed by a descriptor. The function is stored in its
+// own descriptor, and the schema descriptor maps its name to the IDs of its
+// overloads. Functions returning type trigger can only be executed by
+// triggers; see CreateTrigger.
+// Privileges: CREATE on the schema. Replacing a function also requires
+// ownership of the function.
+//   notes: postgres also supports functions in other languages, functions
+//          returning sets and OUT arguments.
+func (p *planner) CreateFunction(ctx context.Context, n *tree.CreateFunction) (planNode, error) {
+	if err := checkUserDefinedFunctionsSupported(ctx, p); err != nil {
+		return nil, err
+	}
+	if err := checkSchemaChangeEnabled(ctx, p.ExecCfg(), "CREATE FUNCTION"); err != nil {
+		return nil, err
+	}
+
+	fn := descpb.FunctionDescriptor{
+		Name:              n.Name.Object(),
+		CalledOnNullInput: true,
+		Volatility:        descpb.FunctionDescriptor_VOLATILE,
+	}
+	if err := validateFunctionOptions(n.Options, &fn); err != nil {
+		return nil, err
+	}
+	if _, ok := tree.FunDefs[fn.Name]; ok {
+		return nil, pgerror.Newf(pgcode.DuplicateFunction,
+			"function %s already exists as a built-in function", fn.Name)
+	}
+
+	scDesc, err := p.getSchemaForCreateFunction(ctx, n.Name)
+	if err != nil {
+		return nil, err
+	}
+	fn.ParentID = scDesc.GetParentID()
+	fn.ParentSchemaID = scDesc.GetID()
+
+	fn.Trigger = isTriggerReturnType(n.ReturnType)
+	if fn.Trigger && len(n.Args) > 0 {
+		return nil, pgerror.New(pgcode.InvalidFunctionDefinition,
+			"trigger functions cannot have declared arguments")
+	}
+
+	fn.Args = make([]descpb.FunctionDescriptor_Argument, len(n.Args))
+	seenArgs := make(map[string]struct{}, len(n.Args))
+	for i := range n.Args {
+		arg := &n.Args[i]
+		typ, err := p.resolveFunctionType(ctx, arg.Type)
+		if err != nil {
+			return nil, err
+		}
+		if arg.Name != "" {
+			if _, ok := seenArgs[string(arg.Name)]; ok {
+				return nil, pgerror.Newf(pgcode.InvalidFunctionDefinition,
+					"parameter name %q used more than once", arg.Name)
+			}
+			seenArgs[string(arg.Name)] = struct{}{}
+		}
+		fn.Args[i] = descpb.FunctionDescriptor_Argument{Name: string(arg.Name), Type: typ}
+	}
+	if !fn.Trigger {
+		if fn.ReturnType, err = p.resolveFunctionType(ctx, n.ReturnType); err != nil {
+			return nil, err
+		}
+	}
+
+	var existing *funcdesc.Mutable
+	if id, ok := findFunctionOverload(scDesc, fn.Name, fn.ArgTypes()); ok {
+		if existing, err = p.Descriptors().GetMutableFunctionByID(
+			ctx, p.txn, id, tree.ObjectLookupFlagsWithRequired(),
+		); err != nil {
+			return nil, err
+		}
+		if !n.Replace {
+			return nil, pgerror.Newf(pgcode.DuplicateFunction,
+				"function %s already exists with same argument types", existing.Signature())
+		}
+		if err := p.checkFunctionOwnership(ctx, existing); err != nil {
+			return nil, err
+		}
+		if existing.Trigger != fn.Trigger ||
+			(!fn.Trigger && !existing.ReturnType.Identical(fn.ReturnType)) {
+			return nil, pgerror.Newf(pgcode.InvalidFunctionDefinition,
+				"cannot change return type of existing function")
+		}
+		for i := range existing.Args {
+			if existing.Args[i].Name != "" && existing.Args[i].Name != fn.Args[i].Name {
+				return nil, pgerror.Newf(pgcode.InvalidFunctionDefinition,
+					"cannot change name of input parameter %q", existing.Args[i].Name)
+			}
+		}
+		fn.ID = existing.GetID()
+	}
+
+	// Build a call of the function to validate its body. The body of a
+	// trigger function references the rows of the table of the trigger, so
+	// it can only be built when the trigger fires.
+	if fn.Trigger {
+		if err := validateTriggerFunctionBody(&fn); err != nil {
+			return nil, err
+		}
+	} else if err := p.validateFunctionBody(ctx, &fn); err != nil {
+		return nil, err
+	}
+	return &createFunctionNode{n: n, scDesc: scDesc, fn: fn, existing: existing}, nil
+}
+
+func (n *createFunctionNode) startExec(params runParams) error {
+	jobDesc := tree.AsStringWithFQNames(n.n, params.Ann())
+	if fnDesc := n.existing; fnDesc != nil {
+		fnDesc.Args = n.fn.Args
+		fnDesc.ReturnType = n.fn.ReturnType
+		fnDesc.Volatility = n.fn.Volatility
+		fnDesc.CalledOnNullInput = n.fn.CalledOnNullInput
+		fnDesc.Body = n.fn.Body
+		return params.p.writeFuncSchemaChange(params.ctx, fnDesc, jobDesc)
+	}
+
+	id, err := catalogkv.GenerateUniqueDescID(params.ctx, params.ExecCfg().DB, params.ExecCfg().Codec)
+	if err != nil {
+		return err
+	}
+	fn := n.fn
+	fn.ID = id
+	fn.Version = 1
+	fn.Privileges = descpb.NewBaseFunctionPrivilegeDescriptor(params.p.User())
+	fnDesc := funcdesc.NewBuilder(&fn).BuildCreatedMutableFunction()
+	n.scDesc.AddFunction(fn.Name, descpb.SchemaDescriptor_FunctionOverload{
+		ID:       id,
+		ArgTypes: fn.ArgTypes(),
+	})
+	if err := params.p.writeFuncSchemaChange(params.ctx, fnDesc, jobDesc); err != nil {
+		return err
+	}
+	return params.p.writeSchemaDescChange(params.ctx, n.scDesc, jobDesc)
+}
+
+func (n *createFunctionNode) Next(runParams) (bool, error) { return false, nil }
+func (n *createFunctionNode) Values() tree.Datums          { return tree.Datums{} }
+func (n *createFunctionNode) Close(context.Context)        {}
+
+// writeFuncSchemaChange writes the function descriptor and queues a schema
+// change job for it, which waits for the leases on the previous version of
+// the function to be released, and deletes the descriptor of a dropped
+// function.
+func (p *planner) writeFuncSchemaChange(
+	ctx context.Context, desc *funcdesc.Mutable, jobDesc string,
+) error {
+	record, recordExists := p.extendedEvalCtx.SchemaChangeJobRecords[desc.ID]
+	if recordExists {
+		record.AppendDescription(jobDesc)
+		log.Infof(ctx, "job %d: updated job's specification for change on function %d", record.JobID, desc.ID)
+	} else {
+		jobRecord := jobs.Record{
+			JobID:         p.extendedEvalCtx.ExecCfg.JobRegistry.MakeJobID(),
+			Description:   jobDesc,
+			Username:      p.User(),
+			DescriptorIDs: descpb.IDs{desc.ID},
+			Details: jobspb.SchemaChangeDetails{
+				DescID:        desc.ID,
+				FormatVersion: jobspb.DatabaseJobFormatVersion,
+			},
+			Progress:      jobspb.SchemaChangeProgress{},
+			NonCancelable: true,
+		}
+		p.extendedEvalCtx.SchemaChangeJobRecords[desc.ID] = &jobRecord
+		log.Infof(ctx, "queued new schema change job %d for function %d", jobRecord.JobID, desc.ID)
+	}
+
+	b := p.txn.NewBatch()
+	if err := p.Descriptors().WriteDescToBatch(
+		ctx, p.extendedEvalCtx.Tracing.KVTracingEnabled(), desc, b,
+	); err != nil {
+		return err
+	}
+	return p.txn.Run(ctx, b)
+}
+
+// validateFunctionOptions checks the options of a CREATE FUNCTION statement
+// and sets the corresponding fields of the function.
+func validateFunctionOptions(opts tree.FunctionOptions, fn *descpb.FunctionDescriptor) error {
+	var seenNullInput, seenVolatility, seenLanguage, seenBody bool
+	seen := func(b *bool) error {
+		if *b {
+			return pgerror.New(pgcode.Syntax, "conflicting or redundant options")
+		}
+		*b = true
+		return nil
+	}
+	for _, opt := range opts {
+		switch t := opt.(type) {
+		case tree.FunctionNullInputBehavior:
+			if err := seen(&seenNullInput); err != nil {
+				return err
+			}
+			fn.CalledOnNullInput = t == tree.FunctionCalledOnNullInput
+		case tree.FunctionVolatility:
+			if err := seen(&seenVolatility); err != nil {
+				return err
+			}
+			switch t.Volatility {
+			case tree.VolatilityImmutable:
+				fn.Volatility = descpb.FunctionDescriptor_IMMUTABLE
+			case tree.VolatilityStable:
+				fn.Volatility = descpb.FunctionDescriptor_STABLE
+			default:
+				fn.Volatility = descpb.FunctionDescriptor_VOLATILE
+			}
+		case tree.FunctionLanguage:
+			if err := seen(&seenLanguage); err != nil {
+				return err
+			}
+			if t != tree.FunctionLangSQL {
+				return pgerror.Newf(pgcode.UndefinedObject,
+					"language %q does not exist", string(t))
+			}
+		case tree.FunctionBodyStr:
+			if err := seen(&seenBody); err != nil {
+				return err
+			}
+			fn.Body = string(t)
+		default:
+			return errors.AssertionFailedf("unknown function option %T", t)
+		}
+	}
+	if !seenLanguage {
+		return pgerror.New(pgcode.InvalidFunctionDefinition, "no language specified")
+	}
+	if !seenBody {
+		return pgerror.New(pgcode.InvalidFunctionDefinition, "no function body specified")
+	}
+	return nil
+}
+
+// getSchemaForCreateFunction returns the mutable descriptor of the schema
+// in which the function with the given name is created. Only schemas backed
+// by a descriptor can contain functions.
+func (p *planner) getSchemaForCreateFunction(
+	ctx context.Context, name *tree.UnresolvedObjectName,
+) (*schemadesc.Mutable, error) {
+	db, _, prefix, err := p.ResolveTargetObject(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if db.GetID() == keys.SystemDatabaseID {
+		return nil, pgerror.New(pgcode.InsufficientPrivilege,
+			"cannot create functions in the system database")
+	}
+	sc, err := p.getNonTemporarySchemaForCreate(ctx, db, prefix.Schema())
+	if err != nil {
+		return nil, err
+	}
+	scDesc, ok := sc.(*schemadesc.Mutable)
+	if !ok || sc.SchemaKind() != catalog.SchemaUserDefined {
+		return nil, pgerror.Newf(pgcode.FeatureNotSupported,
+			"cannot create functions in schema %q of database %q, which has no descriptor",
+			sc.GetName(), db.GetName())
+	}
+	if err := p.canCreateOnSchema(
+		ctx, scDesc.GetID(), db.GetID(), p.User(), checkPublicSchema,
+	); err != nil {
+		return nil, err
+	}
+	return scDesc, nil
+}
+
+// resolveFunctionType resolves the type of an argument or of the result of a
+// function. User-defined types are not supported, since functions do not
+// record dependencies on them.
+func (p *planner) resolveFunctionType(
+	ctx context.Context, ref tree.ResolvableTypeReference,
+) (*types.T, error) {
+	typ, err := tree.ResolveType(ctx, ref, p.semaCtx.GetTypeResolver())
+	if err != nil {
+		return nil, err
+	}
+	if typ.UserDefined() {
+		return nil, pgerror.Newf(pgcode.FeatureNotSupported,
+			"user-defined type %s cannot be used in functions", typ.SQLString())
+	}
+	if typ.IsAmbiguous() || typ.Family() == types.VoidFamily {
+		return nil, pgerror.Newf(pgcode.InvalidFunctionDefinition,
+			"SQL functions cannot have arguments or results of type %s", typ.SQLString())
+	}
+	return typ, nil
+}
+
+// isTriggerReturnType returns true if the given return type of a CREATE
+// FUNCTION statement is the trigger pseudo-type.
+func isTriggerReturnType(ref tree.ResolvableTypeReference) bool {
+	name, ok := ref.(*tree.UnresolvedObjectName)
+	return ok && name.NumParts == 1 && name.Parts[0] == "trigger"
+}
+
+// validateTriggerFunctionBody checks that the body of a trigger function is a
+// single SELECT or INSERT statement. BEFORE triggers require a SELECT body and
+// AFTER triggers require an INSERT body; this is checked by CreateTrigger.
+func validateTriggerFunctionBody(fn *descpb.FunctionDescriptor) error {
+	stmt, err := parser.ParseOne(fn.Body)
+	if err != nil {
+		return pgerror.Wrap(err, pgcode.InvalidFunctionDefinition, "invalid function body")
+	}
+	switch stmt.AST.(type) {
+	case *tree.Select, *tree.Insert:
+		return nil
+	}
+	return pgerror.Newf(pgcode.FeatureNotSupported,
+		"only SELECT and INSERT statements are supported in trigger function bodies, found %s",
+		stmt.AST.StatementTag())
+}
+
+// findFunctionOverload returns the ID of the overload of the function of the
+// schema with the given name and argument types, and whether there is one.
+func findFunctionOverload(
+	scDesc catalog.SchemaDescriptor, name string, argTypes []*types.T,
+) (descpb.ID, bool) {
+	fn := scDesc.GetFunctions()[name]
+	for i := range fn.Overloads {
+		if fn.Overloads[i].HasArgTypes(argTypes) {
+			return fn.Overloads[i].ID, true
+		}
+	}
+	return descpb.InvalidID, false
+}
+
+// checkFunctionOwnership returns an error if the current user is neither the
+// owner of the function, a member of the owner role nor an admin.
+func (p *planner) checkFunctionOwnership(ctx context.Context, fn catalog.FunctionDescriptor) error {
+	hasAdmin, err := p.HasAdminRole(ctx)
+	if err != nil || hasAdmin {
+		return err
+	}
+	isOwner, err := p.HasOwnership(ctx, fn)
+	if err != nil {
+		return err
+	}
+	if !isOwner {
+		return pgerror.Newf(pgcode.InsufficientPrivilege,
+			"must be owner of function %s", fn.GetName())
+	}
+	return nil
+}
+
+// validateFunctionBody checks that the body of the function is a single
+// SELECT statement, and that a call of the function can be built with
+// arguments of the declared types. In particular, this checks that the body
+// returns a single column whose type can be assigned to the return type.
+func (p *planner) validateFunctionBody(ctx context.Context, fn *descpb.FunctionDescriptor) error {
+	stmt, err := parser.ParseOne(fn.Body)
+	if err != nil {
+		return pgerror.Wrap(err, pgcode.InvalidFunctionDefinition, "invalid function body")
+	}
+	if _, ok := stmt.AST.(*tree.Select); !ok {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"only SELECT statements are supported in function bodies, found %s",
+			stmt.AST.StatementTag())
+	}
+
+	argTypes := fn.ArgTypes()
+	args := make(tree.Exprs, len(argTypes))
+	for i := range args {
+		args[i] = &tree.Placeholder{Idx: tree.PlaceholderIdx(i)}
+	}
+	def := makeUDFFunctionDefinition(
+		[]catalog.FunctionDescriptor{funcdesc.NewBuilder(fn).BuildImmutableFunction()},
+	)
+	call := &tree.FuncExpr{
+		Func:  tree.ResolvableFunctionReference{FunctionReference: def},
+		Exprs: args,
+	}
+	sel := &tree.Select{Select: &tree.SelectClause{
+		Exprs: tree.SelectExprs{{Expr: call}},
+	}}
+
+	semaCtx := p.semaCtx
+	if err := semaCtx.Placeholders.Init(len(argTypes), argTypes); err != nil {
+		return err
+	}
+	var oc optCatalog
+	oc.init(p)
+	oc.reset()
+	var o xform.Optimizer
+	o.Init(p.EvalContext(), &oc)
+	bld := optbuilder.New(ctx, &semaCtx, p.EvalContext(), &oc, o.Factory(), sel)
+	bld.KeepPlaceholders = true
+	return bld.Build()
+}
+
+// makeUDFFunctionDefinition returns the definition of the given overloads of
+// a function, which must have the same name.
+func makeUDFFunctionDefinition(fns []catalog.FunctionDescriptor) *tree.FunctionDefinition {
+	overloads := make([]tree.Overload, len(fns))
+	for i := range fns {
+		fn := fns[i].FuncDesc()
+		argTypes := make(tree.ArgTypes, len(fn.Args))
+		argNames := make([]string, len(fn.Args))
+		for j := range fn.Args {
+			argTypes[j].Name = fn.Args[j].Name
+			argTypes[j].Typ = fn.Args[j].Type
+			argNames[j] = fn.Args[j].Name
+		}
+		retType := fn.ReturnType
+		if fn.Trigger {
+			retType = types.Void
+		}
+		overloads[i] = tree.Overload{
+			Types:      argTypes,
+			ReturnType: tree.FixedReturnType(retType),
+			Volatility: fn.Volatility.ToTreeVolatility(),
+			UDF: &tree.UDFDefinition{
+				Body:              fn.Body,
+				ArgNames:          argNames,
+				CalledOnNullInput: fn.CalledOnNullInput,
+				Trigger:           fn.Trigger,
+				FunctionID:        uint32(fn.ID),
+				FunctionVersion:   uint64(fn.Version),
+			},
+		}
+	}
+	return tree.NewUDFFunctionDefinition(fns[0].GetName(), overloads)
+}
+
+// resolveUDF resolves the name of a user-defined function. Unqualified names
+// are resolved using the search path; the definition contains all the
+// overloads of the function in the first schema in which a function with that
+// name exists. It returns nil if there is no such function.
+func (p *planner) resolveUDF(
+	ctx context.Context, name *tree.UnresolvedName,
+) (*tree.FunctionDefinition, error) {
+	if name.Star || name.NumParts > 3 {
+		return nil, nil
+	}
+	fnName := name.Parts[0]
+	dbName := p.CurrentDatabase()
+	if name.NumParts == 3 {
+		dbName = name.Parts[2]
+	}
+	if dbName == "" {
+		return nil, nil
+	}
+	var scNames []string
+	if name.NumParts >= 2 {
+		scNames = []string{name.Parts[1]}
+	} else {
+		iter := p.CurrentSearchPath().IterWithoutImplicitPGSchemas()
+		for scName, ok := iter.Next(); ok; scName, ok = iter.Next() {
+			scNames = append(scNames, scName)
+		}
+	}
+	for _, scName := range scNames {
+		found, prefix, err := p.LookupSchema(ctx, dbName, scName)
+		if err != nil {
+			return nil, err
+		}
+		if !found || prefix.Schema.SchemaKind() != catalog.SchemaUserDefined {
+			continue
+		}
+		fn, ok := prefix.Schema.GetFunctions()[fnName]
+		if !ok {
+			continue
+		}
+		if err := p.CheckPrivilege(ctx, prefix.Schema, privilege.USAGE); err != nil {
+			return nil, err
+		}
+		fns := make([]catalog.FunctionDescriptor, len(fn.Overloads))
+		for i := range fn.Overloads {
+			if fns[i], err = p.Descriptors().GetImmutableFunctionByID(
+				ctx, p.txn, fn.Overloads[i].ID, tree.ObjectLookupFlagsWithRequired(),
+			); err != nil {
+				return nil, err
+			}
+		}
+		return makeUDFFunctionDefinition(fns), nil
+	}
+	return nil, nil
+}
+
+type dropFunctionNode struct {
+	n *tree.DropFunction
+	// toDrop are the functions to drop.
+	toDrop []*funcdesc.Mutable
+	// scDescs are the schemas of toDrop, in the order in which they were
+	// resolved.
+	scDescs []*schemadesc.Mutable
+	// triggerTables are the tables with triggers executing dropped trigger
+	// functions, and triggerFns maps their IDs to the names of those
+	// functions. The triggers are dropped with the functions when CASCADE is
+	// specified.
+	triggerTables []*tabledesc.Mutable
+	triggerFns    map[descpb.ID][]string
+}
+
+// DropFunction drops user-defined functions.
+// Privileges: ownership of the functions or the admin role.
+//   notes: postgres requires ownership of the functions.
+func (p *planner) DropFunction(ctx context.Context, n *tree.DropFunction) (planNode, error) {
+	if err := checkUserDefinedFunctionsSupported(ctx, p); err != nil {
+		return nil, err
+	}
+	if err := checkSchemaChangeEnabled(ctx, p.ExecCfg(), "DROP FUNCTION"); err != nil {
+		return nil, err
+	}
+
+	node := &dropFunctionNode{
+		n:          n,
+		triggerFns: make(map[descpb.ID][]string),
+	}
+	scDescsByID := make(map[descpb.ID]*schemadesc.Mutable)
+	seen := make(map[descpb.ID]struct{})
+	for i := range n.Functions {
+		fnObj := &n.Functions[i]
+		fn, scDesc, err := p.resolveFunctionToDrop(ctx, fnObj, scDescsByID)
+		if err != nil {
+			return nil, err
+		}
+		if fn == nil {
+			if n.IfExists {
+				continue
+			}
+			return nil, pgerror.Newf(pgcode.UndefinedFunction,
+				"function %s does not exist", tree.AsString(fnObj))
+		}
+		if _, ok := seen[fn.GetID()]; ok {
+			continue
+		}
+		seen[fn.GetID()] = struct{}{}
+		if err := p.checkFunctionOwnership(ctx, fn); err != nil {
+			return nil, err
+		}
+		if fn.Trigger {
+			tables, err := p.tablesWithTriggerFunction(ctx, scDesc, fn.Name)
+			if err != nil {
+				return nil, err
+			}
+			if len(tables) > 0 && n.DropBehavior != tree.DropCascade {
+				return nil, pgerror.Newf(pgcode.DependentObjectsStillExist,
+					"cannot drop function %s because other objects depend on it", fn.Signature())
+			}
+			for _, tableDesc := range tables {
+				if _, ok := node.triggerFns[tableDesc.GetID()]; !ok {
+					node.triggerTables = append(node.triggerTables, tableDesc)
+				}
+				node.triggerFns[tableDesc.GetID()] = append(node.triggerFns[tableDesc.GetID()], fn.Name)
+			}
+		}
+		node.toDrop = append(node.toDrop, fn)
+	}
+	for _, scDesc := range scDescsByID {
+		node.scDescs = append(node.scDescs, scDesc)
+	}
+	sort.Slice(node.scDescs, func(i, j int) bool {
+		return node.scDescs[i].GetID() < node.scDescs[j].GetID()
+	})
+	return node, nil
+}
+
+// resolveFunctionToDrop returns the mutable descriptor of the function
+// identified by the given function object of a DROP FUNCTION statement and
+// the mutable descriptor of its schema, or nil if there is no such function.
+// The schema descriptors which were already resolved are reused from
+// scDescsByID.
+func (p *planner) resolveFunctionToDrop(
+	ctx context.Context, fnObj *tree.FuncObj, scDescsByID map[descpb.ID]*schemadesc.Mutable,
+) (*funcdesc.Mutable, *schemadesc.Mutable, error) {
+	fnDesc, err := p.resolveMutableFunction(ctx, fnObj)
+	if err != nil || fnDesc == nil {
+		return nil, nil, err
+	}
+	scID := fnDesc.GetParentSchemaID()
+	scDesc, ok := scDescsByID[scID]
+	if !ok {
+		mut, err := p.Descriptors().GetMutableDescriptorByID(ctx, scID, p.txn)
+		if err != nil {
+			return nil, nil, err
+		}
+		if scDesc, ok = mut.(*schemadesc.Mutable); !ok {
+			return nil, nil, errors.AssertionFailedf("descriptor %d is not a schema", scID)
+		}
+		scDescsByID[scID] = scDesc
+	}
+	return fnDesc, scDesc, nil
+}
+
+// resolveMutableFunction returns the mutable descriptor of the function
+// identified by the given function object, or nil if there is no such
+// function. The argument types can be omitted if the function has a single
+// overload.
+func (p *planner) resolveMutableFunction(
+	ctx context.Context, fnObj *tree.FuncObj,
+) (*funcdesc.Mutable, error) {
+	var argTypes []*types.T
+	if fnObj.Args != nil {
+		argTypes = make([]*types.T, len(fnObj.Args))
+		for i := range fnObj.Args {
+			typ, err := tree.ResolveType(ctx, fnObj.Args[i].Type, p.semaCtx.GetTypeResolver())
+			if err != nil {
+				return nil, err
+			}
+			argTypes[i] = typ
+		}
+	}
+
+	un := fnObj.Name.ToUnresolvedName()
+	def, err := p.resolveUDF(ctx, un)
+	if err != nil || def == nil {
+		return nil, err
+	}
+	var id descpb.ID
+	for _, o := range def.Definition {
+		o := o.(*tree.Overload)
+		if argTypes != nil {
+			if identicalArgTypes(o.Types.(tree.ArgTypes), argTypes) {
+				id = descpb.ID(o.UDF.FunctionID)
+				break
+			}
+			continue
+		}
+		if id != descpb.InvalidID {
+			return nil, pgerror.Newf(pgcode.AmbiguousFunction,
+				"function name %q is not unique", un.Parts[0])
+		}
+		id = descpb.ID(o.UDF.FunctionID)
+	}
+	if id == descpb.InvalidID {
+		return nil, nil
+	}
+	return p.Descriptors().GetMutableFunctionByID(
+		ctx, p.txn, id, tree.ObjectLookupFlagsWithRequired(),
+	)
+}
+
+// identicalArgTypes returns whether the arguments have exactly the given
+// types.
+func identicalArgTypes(args tree.ArgTypes, typs []*types.T) bool {
+	if len(args) != len(typs) {
+		return false
+	}
+	for i := range args {
+		if !args[i].Typ.Identical(typs[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (n *dropFunctionNode) startExec(params runParams) error {
+	jobDesc := tree.AsStringWithFQNames(n.n, params.Ann())
+	scDescsByID := make(map[descpb.ID]*schemadesc.Mutable, len(n.scDescs))
+	for _, scDesc := range n.scDescs {
+		scDescsByID[scDesc.GetID()] = scDesc
+	}
+	for _, fnDesc := range n.toDrop {
+		scDescsByID[fnDesc.GetParentSchemaID()].RemoveFunction(fnDesc.GetName(), fnDesc.GetID())
+		fnDesc.SetDropped()
+		if err := params.p.writeFuncSchemaChange(params.ctx, fnDesc, jobDesc); err != nil {
+			return err
+		}
+		if err := params.p.removeFunctionComment(params.ctx, fnDesc.GetID()); err != nil {
+			return err
+		}
+	}
+	for _, scDesc := range n.scDescs {
+		if err := params.p.writeSchemaDescChange(params.ctx, scDesc, jobDesc); err != nil {
+			return err
+		}
+	}
+	for _, tableDesc := range n.triggerTables {
+		fnNames := n.triggerFns[tableDesc.GetID()]
+		dropTriggers(tableDesc, func(t *descpb.TriggerDescriptor) bool {
+			for _, name := range fnNames {
+				if t.FunctionName == name {
+					return true
+				}
+			}
+			return false
+		})
+		if err := params.p.writeSchemaChange(
+			params.ctx, tableDesc, descpb.InvalidMutationID, jobDesc,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *dropFunctionNode) Next(runParams) (bool, error) { return false, nil }
+func (n *dropFunctionNode) Values() tree.Datums          { return tree.Datums{} }
+func (n *dropFunctionNode) Close(context.Context)        {}
@@ -14,6 +14,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/featureflag"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
@@ -281,6 +282,39 @@ func (n *createStatsNode) makeJobRecord(ctx context.Context) (*jobs.Record, erro
 		}
 	}
 
+	// Partial statistics are only collected on the columns that can be scanned
+	// from the extremes of an index.
+	if n.Options.UsingExtremes {
+		if !n.p.ExecCfg().Settings.Version.IsActive(
+			ctx, clusterversion.AlterSystemTableStatisticsAddPartialPredicateAndID,
+		) {
+			return nil, pgerror.New(pgcode.FeatureNotSupported,
+				"partial statistics are not supported until the cluster version is upgraded")
+		}
+		// Read the stored statistics rather than the cached ones, which may not
+		// include a full statistic that was just collected.
+		tableStats, err := n.p.ExecCfg().TableStatsCache.GetStoredTableStats(ctx, tableDesc)
+		if err != nil {
+			return nil, err
+		}
+		if colStats, err = makePartialColStats(
+			tableDesc, tableStats, colStats, len(n.ColumnNames) > 0,
+		); err != nil {
+			return nil, err
+		}
+	}
+
+	// Make histograms for multi-column stats, if enabled. Nodes running older
+	// versions only sample the first column of each stat, so wait until all
+	// nodes can sample every column.
+	if !n.Options.UsingExtremes &&
+		stats.MultiColumnHistogramClusterMode.Get(&n.p.ExecCfg().Settings.SV) &&
+		n.p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.MultiColumnHistograms) {
+		if err := addMultiColumnHistograms(tableDesc, colStats); err != nil {
+			return nil, err
+		}
+	}
+
 	// Evaluate the AS OF time, if any.
 	var asOfTimestamp *hlc.Timestamp
 	if n.Options.AsOf.Expr != nil {
@@ -298,6 +332,8 @@ func (n *createStatsNode) makeJobRecord(ctx context.Context) (*jobs.Record, erro
 	if n.Name == jobspb.AutoStatsName {
 		// Use a user-friendly description for automatic statistics.
 		description = fmt.Sprintf("Table statistics refresh for %s", fqTableName)
+	} else if n.Name == jobspb.AutoPartialStatsName {
+		description = fmt.Sprintf("Table partial statistics refresh for %s", fqTableName)
 	} else {
 		// This must be a user query, so use the statement (for consistency with
 		// other jobs triggered by statements).
@@ -316,6 +352,7 @@ func (n *createStatsNode) makeJobRecord(ctx context.Context) (*jobs.Record, erro
 			Statement:       eventLogStatement,
 			AsOf:            asOfTimestamp,
 			MaxFractionIdle: n.Options.Throttling,
+			UsingExtremes:   n.Options.UsingExtremes,
 		},
 		Progress: jobspb.CreateStatsProgress{},
 	}, nil
@@ -536,6 +573,137 @@ func createStatsDefaultColumns(
 	return colStats, nil
 }
 
+// addMultiColumnHistograms requests histograms for the multi-column statistics
+// in colStats. Histograms on multiple columns are built on the key encodings of
+// the column values, so they are only requested if all of the column types can
+// be key-encoded.
+func addMultiColumnHistograms(
+	desc catalog.TableDescriptor, colStats []jobspb.CreateStatsDetails_ColStat,
+) error {
+	for i := range colStats {
+		colStat := &colStats[i]
+		if len(colStat.ColumnIDs) < 2 || colStat.Inverted || colStat.HasHistogram {
+			continue
+		}
+		indexable := true
+		for _, colID := range colStat.ColumnIDs {
+			col, err := desc.FindColumnWithID(colID)
+			if err != nil {
+				return err
+			}
+			if !colinfo.ColumnTypeIsIndexable(col.GetType()) {
+				indexable = false
+				break
+			}
+		}
+		if indexable {
+			colStat.HasHistogram = true
+			colStat.HistogramMaxBuckets = defaultHistogramBuckets
+		}
+	}
+	return nil
+}
+
+// makePartialColStats returns the column statistics requests for partial
+// statistics from the given requests for full statistics. Partial statistics
+// can only be collected on a single column that is the first key column of a
+// forward, non-partial index, and that has a full statistic with a histogram
+// that the partial statistic extends. If the columns were requested
+// explicitly, an error is returned if partial statistics cannot be collected
+// on them; otherwise, the columns on which they cannot be collected are
+// skipped.
+func makePartialColStats(
+	desc catalog.TableDescriptor,
+	tableStats []*stats.TableStatistic,
+	colStats []jobspb.CreateStatsDetails_ColStat,
+	explicit bool,
+) ([]jobspb.CreateStatsDetails_ColStat, error) {
+	if explicit && len(colStats) > 0 && len(colStats[0].ColumnIDs) != 1 {
+		return nil, pgerror.New(pgcode.FeatureNotSupported,
+			"partial statistics can only be created on a single column")
+	}
+	var res []jobspb.CreateStatsDetails_ColStat
+	for _, colStat := range colStats {
+		if len(colStat.ColumnIDs) != 1 || colStat.Inverted {
+			continue
+		}
+		colID := colStat.ColumnIDs[0]
+		col, err := desc.FindColumnWithID(colID)
+		if err != nil {
+			return nil, err
+		}
+		if colinfo.ColumnTypeIsInvertedIndexable(col.GetType()) {
+			// The histograms on these columns are built on the inverted index
+			// keys rather than the column values.
+			if explicit {
+				return nil, pgerror.Newf(pgcode.FeatureNotSupported,
+					"cannot create partial statistics on column %q of type %s",
+					col.GetName(), col.GetType().SQLString())
+			}
+			continue
+		}
+		if partialStatsIndex(desc, colID) == nil {
+			if explicit {
+				return nil, pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+					"column %q must be the first key column of a forward, non-partial index "+
+						"to create partial statistics", col.GetName())
+			}
+			continue
+		}
+		if mostRecentFullStatWithHistogram(tableStats, colID) == nil {
+			if explicit {
+				return nil, pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+					"column %q does not have a full statistic with a histogram to extend",
+					col.GetName())
+			}
+			continue
+		}
+		res = append(res, jobspb.CreateStatsDetails_ColStat{
+			ColumnIDs:           colStat.ColumnIDs,
+			HasHistogram:        true,
+			HistogramMaxBuckets: defaultHistogramBuckets,
+		})
+	}
+	if len(res) == 0 {
+		return nil, pgerror.New(pgcode.ObjectNotInPrerequisiteState,
+			"no columns are eligible for partial statistics")
+	}
+	return res, nil
+}
+
+// partialStatsIndex returns a forward, non-partial index of the table whose
+// first key column is the given column, or nil if there is none. Partial
+// statistics on the column are collected by scanning the extremes of the
+// index.
+func partialStatsIndex(desc catalog.TableDescriptor, colID descpb.ColumnID) catalog.Index {
+	for _, idx := range desc.ActiveIndexes() {
+		if idx.GetType() == descpb.IndexDescriptor_FORWARD && !idx.IsPartial() &&
+			idx.NumKeyColumns() > 0 && idx.GetKeyColumnID(0) == colID {
+			return idx
+		}
+	}
+	return nil
+}
+
+// mostRecentFullStatWithHistogram returns the most recent full statistic on
+// the given column if it has a histogram, or nil otherwise. tableStats must be
+// ordered by CreatedAt, newest to oldest.
+func mostRecentFullStatWithHistogram(
+	tableStats []*stats.TableStatistic, colID descpb.ColumnID,
+) *stats.TableStatistic {
+	for _, stat := range tableStats {
+		if len(stat.ColumnIDs) != 1 || stat.ColumnIDs[0] != colID ||
+			stat.IsPartial() || stat.IsMerged() || stat.IsForecast() {
+			continue
+		}
+		if stat.HistogramData == nil || len(stat.HistogramData.Buckets) == 0 {
+			return nil
+		}
+		return stat
+	}
+	return nil
+}
+
 // makeColStatKey constructs a unique key representing cols that can be used
 // as the key in a map.
 func makeColStatKey(cols []descpb.ColumnID) string {
@@ -559,7 +727,7 @@ var _ jobs.Resumer = &createStatsResumer{}
 func (r *createStatsResumer) Resume(ctx context.Context, execCtx interface{}) error {
 	p := execCtx.(JobExecContext)
 	details := r.job.Details().(jobspb.CreateStatsDetails)
-	if details.Name == jobspb.AutoStatsName {
+	if details.Name == jobspb.AutoStatsName || details.Name == jobspb.AutoPartialStatsName {
 		// We want to make sure that an automatic CREATE STATISTICS job only runs if
 		// there are no other CREATE STATISTICS jobs running, automatic or manual.
 		if err := checkRunningJobs(ctx, r.job, p); err != nil {
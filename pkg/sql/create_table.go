@@ -18,6 +18,7 @@ import (
 	"strings"
 
 	"github.com/cockroachdb/cockroach/pkg/build"
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/docs"
 	"github.com/cockroachdb/cockroach/pkg/geo/geoindex"
 	"github.com/cockroachdb/cockroach/pkg/kv"
@@ -600,6 +601,21 @@ func qualifyFKColErrorWithDB(
 }
 
 // TableState is the state of the referencing table ResolveFK() or
+// checkDeferrableConstraintSupported returns an error if the constraint is
+// DEFERRABLE and not all nodes are running a version that checks deferrable
+// constraints at the end of the transaction.
+func checkDeferrableConstraintSupported(
+	ctx context.Context, evalCtx *tree.EvalContext, deferrable tree.ConstraintDeferrability,
+) error {
+	if deferrable == tree.ConstraintNotDeferrable ||
+		evalCtx.Settings.Version.IsActive(ctx, clusterversion.DeferrableConstraints) {
+		return nil
+	}
+	return pgerror.Newf(pgcode.FeatureNotSupported,
+		"DEFERRABLE constraints require all nodes to be upgraded to %s",
+		clusterversion.ByKey(clusterversion.DeferrableConstraints))
+}
+
 // ResolveUniqueWithoutIndexConstraint() is called on.
 type TableState int
 
@@ -637,6 +653,7 @@ func addUniqueWithoutIndexColumnTableDef(
 		string(d.Unique.ConstraintName),
 		[]string{string(d.Name)},
 		"", /* predicate */
+		tree.ConstraintNotDeferrable,
 		ts,
 		validationBehavior,
 	); err != nil {
@@ -674,6 +691,9 @@ func addUniqueWithoutIndexTableDef(
 			"partitioned unique constraints without an index are not supported",
 		)
 	}
+	if err := checkDeferrableConstraintSupported(ctx, evalCtx, d.Deferrable); err != nil {
+		return err
+	}
 
 	// If there is a predicate, validate it.
 	var predicate string
@@ -693,7 +713,7 @@ func addUniqueWithoutIndexTableDef(
 		colNames[i] = string(d.Columns[i].Column)
 	}
 	if err := ResolveUniqueWithoutIndexConstraint(
-		ctx, desc, string(d.Name), colNames, predicate, ts, validationBehavior,
+		ctx, desc, string(d.Name), colNames, predicate, d.Deferrable, ts, validationBehavior,
 	); err != nil {
 		return err
 	}
@@ -713,6 +733,7 @@ func ResolveUniqueWithoutIndexConstraint(
 	constraintName string,
 	colNames []string,
 	predicate string,
+	deferrable tree.ConstraintDeferrability,
 	ts TableState,
 	validationBehavior tree.ValidationBehavior,
 ) error {
@@ -766,11 +787,13 @@ func ResolveUniqueWithoutIndexConstraint(
 	}
 
 	uc := descpb.UniqueWithoutIndexConstraint{
-		Name:      constraintName,
-		TableID:   tbl.ID,
-		ColumnIDs: columnIDs,
-		Predicate: predicate,
-		Validity:  validity,
+		Name:              constraintName,
+		TableID:           tbl.ID,
+		ColumnIDs:         columnIDs,
+		Predicate:         predicate,
+		Validity:          validity,
+		Deferrable:        deferrable != tree.ConstraintNotDeferrable,
+		InitiallyDeferred: deferrable == tree.ConstraintInitiallyDeferred,
 	}
 
 	if ts == NewTable {
@@ -820,6 +843,9 @@ func ResolveFK(
 	validationBehavior tree.ValidationBehavior,
 	evalCtx *tree.EvalContext,
 ) error {
+	if err := checkDeferrableConstraintSupported(ctx, evalCtx, d.Deferrable); err != nil {
+		return err
+	}
 	var originColSet catalog.TableColSet
 	originCols := make([]catalog.Column, len(d.FromCols))
 	for i, fromCol := range d.FromCols {
@@ -1025,6 +1051,8 @@ func ResolveFK(
 		OnDelete:            descpb.ForeignKeyReferenceActionValue[d.Actions.Delete],
 		OnUpdate:            descpb.ForeignKeyReferenceActionValue[d.Actions.Update],
 		Match:               descpb.CompositeKeyMatchMethodValue[d.Match],
+		Deferrable:          d.Deferrable != tree.ConstraintNotDeferrable,
+		InitiallyDeferred:   d.Deferrable == tree.ConstraintInitiallyDeferred,
 	}
 
 	if ts == NewTable {
@@ -0,0 +1,280 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/resolver"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/errors"
+)
+
+// checkRowLevelTriggersSupported returns an error if the cluster version does
+// not allow table descriptors to contain triggers yet.
+func checkRowLevelTriggersSupported(ctx context.Context, p *planner) error {
+	if !p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.RowLevelTriggers) {
+		return pgerror.New(pgcode.FeatureNotSupported,
+			"triggers are not supported until version upgrade is finalized")
+	}
+	return nil
+}
+
+type createTriggerNode struct {
+	n         *tree.CreateTrigger
+	tableDesc *tabledesc.Mutable
+	trigger   descpb.TriggerDescriptor
+}
+
+// CreateTrigger creates a row-level trigger on a table. The trigger is stored
+// in the table descriptor, and executes a trigger function of the schema of
+// the table for each row inserted, updated or deleted by a statement.
+// Privileges: CREATE on the table.
+//   notes: postgres requires the TRIGGER privilege on the table, and also
+//          supports statement-level triggers, WHEN conditions, column lists of
+//          UPDATE triggers, TRUNCATE triggers and triggers on views.
+func (p *planner) CreateTrigger(ctx context.Context, n *tree.CreateTrigger) (planNode, error) {
+	if err := checkRowLevelTriggersSupported(ctx, p); err != nil {
+		return nil, err
+	}
+	if err := checkSchemaChangeEnabled(ctx, p.ExecCfg(), "CREATE TRIGGER"); err != nil {
+		return nil, err
+	}
+
+	tn := n.Table.ToTableName()
+	prefix, tableDesc, err := p.ResolveMutableTableDescriptor(
+		ctx, &tn, true /* required */, tree.ResolveRequireTableDesc,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.CheckPrivilege(ctx, tableDesc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+	for i := range tableDesc.Triggers {
+		if tableDesc.Triggers[i].Name == string(n.Name) {
+			return nil, pgerror.Newf(pgcode.DuplicateObject,
+				"trigger %q for relation %q already exists", n.Name, tableDesc.Name)
+		}
+	}
+
+	trigger := descpb.TriggerDescriptor{
+		Name:         string(n.Name),
+		ActionTime:   descpb.TriggerDescriptor_BEFORE,
+		FunctionName: n.FuncName.Object(),
+	}
+	if n.ActionTime == tree.TriggerAfter {
+		trigger.ActionTime = descpb.TriggerDescriptor_AFTER
+	}
+	for _, e := range n.Events {
+		var event descpb.TriggerDescriptor_Event
+		switch e {
+		case tree.TriggerInsert:
+			event = descpb.TriggerDescriptor_INSERT
+		case tree.TriggerUpdate:
+			event = descpb.TriggerDescriptor_UPDATE
+		case tree.TriggerDelete:
+			event = descpb.TriggerDescriptor_DELETE
+		default:
+			return nil, errors.AssertionFailedf("unknown trigger event %d", e)
+		}
+		if trigger.HasEvent(event) {
+			return nil, pgerror.New(pgcode.Syntax, "duplicate trigger events specified")
+		}
+		trigger.Events = append(trigger.Events, event)
+	}
+
+	if err := p.checkTriggerFunction(ctx, prefix, tableDesc, n.FuncName, &trigger); err != nil {
+		return nil, err
+	}
+	return &createTriggerNode{n: n, tableDesc: tableDesc, trigger: trigger}, nil
+}
+
+// checkTriggerFunction checks that the function of the trigger is a trigger
+// function of the schema of the table, whose body is a SELECT statement for a
+// BEFORE trigger or an INSERT statement for an AFTER trigger.
+func (p *planner) checkTriggerFunction(
+	ctx context.Context,
+	prefix catalog.ResolvedObjectPrefix,
+	tableDesc catalog.TableDescriptor,
+	fnName *tree.UnresolvedObjectName,
+	trigger *descpb.TriggerDescriptor,
+) error {
+	scDesc := prefix.Schema
+	if (fnName.HasExplicitSchema() && fnName.Schema() != scDesc.GetName()) ||
+		(fnName.HasExplicitCatalog() && fnName.Catalog() != prefix.Database.GetName()) ||
+		scDesc.SchemaKind() != catalog.SchemaUserDefined {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"trigger function %s must be defined in the schema of table %q",
+			fnName, tableDesc.GetName())
+	}
+	id, ok := findFunctionOverload(scDesc, trigger.FunctionName, nil /* argTypes */)
+	if !ok {
+		return pgerror.Newf(pgcode.UndefinedFunction,
+			"function %s() does not exist", trigger.FunctionName)
+	}
+	fn, err := p.Descriptors().GetImmutableFunctionByID(
+		ctx, p.txn, id, tree.ObjectLookupFlagsWithRequired(),
+	)
+	if err != nil {
+		return err
+	}
+	if !fn.GetTrigger() {
+		return pgerror.Newf(pgcode.InvalidObjectDefinition,
+			"function %s must return type trigger", fn.GetName())
+	}
+	if err := p.CheckPrivilege(ctx, fn, privilege.EXECUTE); err != nil {
+		return err
+	}
+
+	stmt, err := parser.ParseOne(fn.FuncDesc().Body)
+	if err != nil {
+		return err
+	}
+	switch stmt.AST.(type) {
+	case *tree.Select:
+		if trigger.ActionTime == descpb.TriggerDescriptor_AFTER {
+			return pgerror.Newf(pgcode.FeatureNotSupported,
+				"AFTER triggers require a trigger function with an INSERT body, found SELECT in %s",
+				fn.GetName())
+		}
+	case *tree.Insert:
+		if trigger.ActionTime == descpb.TriggerDescriptor_BEFORE {
+			return pgerror.Newf(pgcode.FeatureNotSupported,
+				"BEFORE triggers require a trigger function with a SELECT body, found INSERT in %s",
+				fn.GetName())
+		}
+	}
+	return nil
+}
+
+func (n *createTriggerNode) startExec(params runParams) error {
+	n.tableDesc.Triggers = append(n.tableDesc.Triggers, n.trigger)
+	return params.p.writeSchemaChange(
+		params.ctx, n.tableDesc, descpb.InvalidMutationID,
+		tree.AsStringWithFQNames(n.n, params.Ann()),
+	)
+}
+
+func (n *createTriggerNode) Next(runParams) (bool, error) { return false, nil }
+func (n *createTriggerNode) Values() tree.Datums          { return tree.Datums{} }
+func (n *createTriggerNode) Close(context.Context)        {}
+
+type dropTriggerNode struct {
+	n         *tree.DropTrigger
+	tableDesc *tabledesc.Mutable
+}
+
+// DropTrigger drops a trigger of a table.
+// Privileges: CREATE on the table.
+//   notes: postgres requires ownership of the table.
+func (p *planner) DropTrigger(ctx context.Context, n *tree.DropTrigger) (planNode, error) {
+	if err := checkRowLevelTriggersSupported(ctx, p); err != nil {
+		return nil, err
+	}
+	if err := checkSchemaChangeEnabled(ctx, p.ExecCfg(), "DROP TRIGGER"); err != nil {
+		return nil, err
+	}
+
+	tn := n.Table.ToTableName()
+	_, tableDesc, err := p.ResolveMutableTableDescriptor(
+		ctx, &tn, !n.IfExists, tree.ResolveRequireTableDesc,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if tableDesc == nil {
+		return newZeroNode(nil /* columns */), nil
+	}
+	if err := p.CheckPrivilege(ctx, tableDesc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+	for i := range tableDesc.Triggers {
+		if tableDesc.Triggers[i].Name == string(n.Name) {
+			return &dropTriggerNode{n: n, tableDesc: tableDesc}, nil
+		}
+	}
+	if n.IfExists {
+		return newZeroNode(nil /* columns */), nil
+	}
+	return nil, pgerror.Newf(pgcode.UndefinedObject,
+		"trigger %q for table %q does not exist", n.Name, tableDesc.Name)
+}
+
+func (n *dropTriggerNode) startExec(params runParams) error {
+	dropTriggers(n.tableDesc, func(t *descpb.TriggerDescriptor) bool {
+		return t.Name == string(n.n.Name)
+	})
+	return params.p.writeSchemaChange(
+		params.ctx, n.tableDesc, descpb.InvalidMutationID,
+		tree.AsStringWithFQNames(n.n, params.Ann()),
+	)
+}
+
+func (n *dropTriggerNode) Next(runParams) (bool, error) { return false, nil }
+func (n *dropTriggerNode) Values() tree.Datums          { return tree.Datums{} }
+func (n *dropTriggerNode) Close(context.Context)        {}
+
+// dropTriggers removes the triggers of the table for which drop returns true.
+func dropTriggers(tableDesc *tabledesc.Mutable, drop func(*descpb.TriggerDescriptor) bool) {
+	triggers := tableDesc.Triggers[:0]
+	for i := range tableDesc.Triggers {
+		if !drop(&tableDesc.Triggers[i]) {
+			triggers = append(triggers, tableDesc.Triggers[i])
+		}
+	}
+	tableDesc.Triggers = triggers
+}
+
+// tablesWithTriggerFunction returns the tables of the given schema which have
+// triggers executing the trigger function with the given name.
+func (p *planner) tablesWithTriggerFunction(
+	ctx context.Context, scDesc catalog.SchemaDescriptor, fnName string,
+) ([]*tabledesc.Mutable, error) {
+	_, dbDesc, err := p.Descriptors().GetImmutableDatabaseByID(
+		ctx, p.txn, scDesc.GetParentID(), tree.DatabaseLookupFlags{Required: true},
+	)
+	if err != nil {
+		return nil, err
+	}
+	_, ids, err := resolver.GetObjectNamesAndIDs(
+		ctx, p.txn, p, p.ExecCfg().Codec, dbDesc, scDesc.GetName(), true, /* explicitPrefix */
+	)
+	if err != nil {
+		return nil, err
+	}
+	var tables []*tabledesc.Mutable
+	for _, id := range ids {
+		desc, err := p.Descriptors().GetMutableDescriptorByID(ctx, id, p.txn)
+		if err != nil {
+			return nil, err
+		}
+		tableDesc, ok := desc.(*tabledesc.Mutable)
+		if !ok {
+			continue
+		}
+		for i := range tableDesc.Triggers {
+			if tableDesc.Triggers[i].FunctionName == fnName {
+				tables = append(tables, tableDesc)
+				break
+			}
+		}
+	}
+	return tables, nil
+}
@@ -232,6 +232,7 @@ func (n *createViewNode) startExec(params runParams) error {
 			desc.IsMaterializedView = true
 			desc.State = descpb.DescriptorState_ADD
 			desc.CreateAsOfTime = params.p.Txn().ReadTimestamp()
+			desc.MaterializedViewAsOfTime = desc.CreateAsOfTime
 			if err := desc.AllocateIDs(params.ctx); err != nil {
 				return err
 			}
@@ -0,0 +1,483 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/exec"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/errors"
+)
+
+// deferredCheckBatchSize is the maximum number of queued keys which are
+// validated by a single query.
+const deferredCheckBatchSize = 256
+
+// deferredConstraint identifies a constraint whose checks have been deferred.
+// Constraints are identified by their columns rather than by their name, so
+// that renaming a constraint later in the transaction does not lose its
+// queued checks.
+type deferredConstraint struct {
+	kind exec.DeferredCheckKind
+	// tableID is the table on which the constraint is defined. For foreign
+	// keys this is always the origin (referencing) table.
+	tableID descpb.ID
+	// columnIDs are the unique columns, or the origin columns of a foreign
+	// key. The queued keys contain values for these columns, in this order.
+	columnIDs descpb.ColumnIDs
+	// referencedTableID and referencedColumnIDs are only set for foreign keys.
+	referencedTableID   descpb.ID
+	referencedColumnIDs descpb.ColumnIDs
+	// predicate is the predicate of a partial unique constraint.
+	predicate string
+	// name is the name of the constraint when the check was queued. It is only
+	// used in error messages.
+	name string
+}
+
+func makeDeferredConstraint(check *exec.DeferredCheck) deferredConstraint {
+	c := deferredConstraint{
+		kind:      check.Kind,
+		tableID:   descpb.ID(check.Table.ID()),
+		columnIDs: make(descpb.ColumnIDs, len(check.Columns)),
+		predicate: check.Predicate,
+		name:      check.Constraint,
+	}
+	for i, ord := range check.Columns {
+		c.columnIDs[i] = descpb.ColumnID(check.Table.Column(int(ord)).ColID())
+	}
+	if check.ReferencedTable != nil {
+		c.referencedTableID = descpb.ID(check.ReferencedTable.ID())
+		c.referencedColumnIDs = make(descpb.ColumnIDs, len(check.ReferencedColumns))
+		for i, ord := range check.ReferencedColumns {
+			c.referencedColumnIDs[i] = descpb.ColumnID(check.ReferencedTable.Column(int(ord)).ColID())
+		}
+	}
+	return c
+}
+
+// equals returns true if both values identify the same constraint.
+func (c *deferredConstraint) equals(other *deferredConstraint) bool {
+	return c.kind == other.kind &&
+		c.tableID == other.tableID &&
+		c.columnIDs.Equals(other.columnIDs) &&
+		c.referencedTableID == other.referencedTableID &&
+		c.referencedColumnIDs.Equals(other.referencedColumnIDs) &&
+		c.predicate == other.predicate
+}
+
+// deferredCheckKeys holds the keys which must be validated against a
+// constraint at the end of the transaction.
+type deferredCheckKeys struct {
+	constraint deferredConstraint
+	keys       []tree.Datums
+	// seen is used to deduplicate keys, which can be queued by several
+	// statements.
+	seen map[string]struct{}
+}
+
+// deferredCheckCollection tracks the constraint checks that have been deferred
+// until the end of the transaction, along with the mode set by SET
+// CONSTRAINTS. It is part of the connExecutor's extraTxnState.
+type deferredCheckCollection struct {
+	mode   tree.ConstraintsMode
+	checks []*deferredCheckKeys
+	// memAcc accounts for the queued keys. It is bound to the session monitor,
+	// so a transaction fails once its queued keys exceed the session budget.
+	memAcc mon.BoundAccount
+}
+
+// add queues a key to be validated against the given constraint.
+func (c *deferredCheckCollection) add(
+	ctx context.Context, constraint *deferredConstraint, key tree.Datums,
+) error {
+	var keys *deferredCheckKeys
+	for _, k := range c.checks {
+		if k.constraint.equals(constraint) {
+			keys = k
+			break
+		}
+	}
+	if keys == nil {
+		keys = &deferredCheckKeys{constraint: *constraint, seen: make(map[string]struct{})}
+		c.checks = append(c.checks, keys)
+	}
+	encoded := tree.AsStringWithFlags(&key, tree.FmtSerializable)
+	if _, ok := keys.seen[encoded]; ok {
+		return nil
+	}
+	// The key is stored both encoded, in seen, and as datums.
+	size := int64(len(encoded))
+	for _, d := range key {
+		size += int64(d.Size())
+	}
+	if err := c.memAcc.Grow(ctx, size); err != nil {
+		return err
+	}
+	keys.seen[encoded] = struct{}{}
+	keys.keys = append(keys.keys, key)
+	return nil
+}
+
+// reset clears the queued checks and releases their memory.
+func (c *deferredCheckCollection) reset(ctx context.Context) {
+	c.checks = nil
+	c.memAcc.Clear(ctx)
+}
+
+// deferredCheckNode queues the keys of a constraint check to be validated at
+// the end of the transaction (or when SET CONSTRAINTS ALL IMMEDIATE is
+// executed). It is planned by the optimizer in place of an errorIfRowsNode
+// when the constraint is deferrable and its check is deferred.
+//
+// The input is the check query planned by the optimizer, which returns the
+// rows violating the constraint at the time the statement runs. Only these
+// keys can still violate the constraint at commit: any later statement which
+// causes another violation queues its own keys.
+type deferredCheckNode struct {
+	plan       planNode
+	constraint deferredConstraint
+	// keyCols are the columns of the input which contain the key values.
+	keyCols []exec.NodeColumnOrdinal
+
+	nexted bool
+}
+
+func (n *deferredCheckNode) startExec(params runParams) error {
+	return nil
+}
+
+func (n *deferredCheckNode) Next(params runParams) (bool, error) {
+	if n.nexted {
+		return false, nil
+	}
+	n.nexted = true
+
+	for {
+		ok, err := n.plan.Next(params)
+		if err != nil || !ok {
+			return false, err
+		}
+		row := n.plan.Values()
+		key := make(tree.Datums, len(n.keyCols))
+		for i, ord := range n.keyCols {
+			key[i] = row[ord]
+		}
+		if err := params.extendedEvalCtx.DeferredChecks.add(params.ctx, &n.constraint, key); err != nil {
+			return false, err
+		}
+	}
+}
+
+func (n *deferredCheckNode) Values() tree.Datums {
+	return nil
+}
+
+func (n *deferredCheckNode) Close(ctx context.Context) {
+	n.plan.Close(ctx)
+}
+
+// runDeferredChecks validates the keys queued for the constraints whose checks
+// were deferred in the current transaction, and clears the queue.
+func (p *planner) runDeferredChecks(ctx context.Context) error {
+	checks := p.extendedEvalCtx.DeferredChecks
+	if len(checks.checks) == 0 {
+		return nil
+	}
+	defer checks.reset(ctx)
+
+	flags := tree.ObjectLookupFlags{CommonLookupFlags: tree.CommonLookupFlags{
+		AvoidLeased:    true,
+		IncludeDropped: true,
+	}}
+	for _, check := range checks.checks {
+		table, err := p.Descriptors().GetImmutableTableByID(ctx, p.txn, check.constraint.tableID, flags)
+		if err != nil {
+			return err
+		}
+		if table.Dropped() {
+			// The table was dropped later in the transaction, so there is
+			// nothing left to validate.
+			continue
+		}
+		for start := 0; start < len(check.keys); start += deferredCheckBatchSize {
+			end := start + deferredCheckBatchSize
+			if end > len(check.keys) {
+				end = len(check.keys)
+			}
+			if check.constraint.kind == exec.DeferredUniqueCheck {
+				err = p.runDeferredUniqueCheck(ctx, table, &check.constraint, check.keys[start:end])
+			} else {
+				err = p.runDeferredFKCheck(ctx, table, &check.constraint, check.keys[start:end])
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// errDeferredConstraintDropped is returned when a constraint was dropped
+// while some of its checks were still queued. Like Postgres, we don't allow
+// pending checks to be discarded this way.
+func errDeferredConstraintDropped(
+	table catalog.TableDescriptor, constraint *deferredConstraint,
+) error {
+	return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+		"constraint %q on table %q was dropped while it had pending deferred checks",
+		constraint.name, table.GetName(),
+	)
+}
+
+// deferredKeysClause returns a VALUES clause listing the given keys as
+// placeholders cast to the types of the given columns, along with the
+// arguments for the placeholders.
+func deferredKeysClause(
+	cols []catalog.Column, keys []tree.Datums,
+) (values string, keyCols []string, args []interface{}) {
+	keyCols = make([]string, len(cols))
+	for i := range cols {
+		keyCols[i] = fmt.Sprintf("k%d", i+1)
+	}
+	var buf strings.Builder
+	args = make([]interface{}, 0, len(keys)*len(cols))
+	for i, key := range keys {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteByte('(')
+		for j, d := range key {
+			if j > 0 {
+				buf.WriteString(", ")
+			}
+			args = append(args, d)
+			fmt.Fprintf(&buf, "$%d::%s", len(args), cols[j].GetType().SQLString())
+		}
+		buf.WriteByte(')')
+	}
+	return buf.String(), keyCols, args
+}
+
+// columnsForIDs returns the public columns with the given IDs.
+func columnsForIDs(table catalog.TableDescriptor, ids descpb.ColumnIDs) ([]catalog.Column, error) {
+	cols := make([]catalog.Column, len(ids))
+	for i, id := range ids {
+		col, err := tabledesc.FindPublicColumnWithID(table, id)
+		if err != nil {
+			return nil, err
+		}
+		cols[i] = col
+	}
+	return cols, nil
+}
+
+// runDeferredFKCheck validates the given keys against the foreign key
+// identified by constraint. A key violates the constraint if some row of the
+// origin table still refers to it and there is no matching row in the
+// referenced table.
+func (p *planner) runDeferredFKCheck(
+	ctx context.Context,
+	srcTable catalog.TableDescriptor,
+	constraint *deferredConstraint,
+	keys []tree.Datums,
+) error {
+	var fk *descpb.ForeignKeyConstraint
+	if err := srcTable.ForeachOutboundFK(func(c *descpb.ForeignKeyConstraint) error {
+		if c.OriginColumnIDs.Equals(constraint.columnIDs) &&
+			c.ReferencedTableID == constraint.referencedTableID &&
+			c.ReferencedColumnIDs.Equals(constraint.referencedColumnIDs) {
+			fk = c
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	if fk == nil {
+		return errDeferredConstraintDropped(srcTable, constraint)
+	}
+	targetTable, err := p.Descriptors().GetImmutableTableByID(
+		ctx, p.txn, fk.ReferencedTableID, tree.ObjectLookupFlags{
+			CommonLookupFlags: tree.CommonLookupFlags{AvoidLeased: true},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	srcCols, err := columnsForIDs(srcTable, fk.OriginColumnIDs)
+	if err != nil {
+		return err
+	}
+	targetCols, err := columnsForIDs(targetTable, fk.ReferencedColumnIDs)
+	if err != nil {
+		return err
+	}
+
+	values, keyCols, args := deferredKeysClause(srcCols, keys)
+	// For MATCH FULL, a key that mixes NULL and non-NULL values is a violation
+	// as long as it is referenced, while a key which is entirely NULL never
+	// is. With MATCH SIMPLE, keys containing NULLs never refer to a row.
+	matchFull := fk.Match == descpb.ForeignKeyReference_FULL
+	srcOp := "="
+	if matchFull {
+		srcOp = "IS NOT DISTINCT FROM"
+	}
+	srcWhere := make([]string, len(keyCols))
+	targetWhere := make([]string, len(keyCols))
+	notAllNull := make([]string, len(keyCols))
+	for i := range keyCols {
+		srcWhere[i] = fmt.Sprintf("src.%s %s k.%s", tree.NameString(srcCols[i].GetName()), srcOp, keyCols[i])
+		targetWhere[i] = fmt.Sprintf("target.%s = k.%s", tree.NameString(targetCols[i].GetName()), keyCols[i])
+		notAllNull[i] = fmt.Sprintf("k.%s IS NOT NULL", keyCols[i])
+	}
+	query := fmt.Sprintf(
+		`SELECT %[1]s FROM (VALUES %[2]s) AS k(%[1]s)
+		 WHERE (%[3]s)
+		   AND EXISTS (SELECT 1 FROM [%[4]d AS src] WHERE %[5]s)
+		   AND NOT EXISTS (SELECT 1 FROM [%[6]d AS target] WHERE %[7]s)
+		 LIMIT 1`,
+		strings.Join(keyCols, ", "),        // 1
+		values,                             // 2
+		strings.Join(notAllNull, " OR "),   // 3
+		srcTable.GetID(),                   // 4
+		strings.Join(srcWhere, " AND "),    // 5
+		targetTable.GetID(),                // 6
+		strings.Join(targetWhere, " AND "), // 7
+	)
+	log.VEventf(ctx, 2, "running deferred FK check %q on %d keys", fk.Name, len(keys))
+	row, err := p.ExecCfg().InternalExecutor.QueryRowEx(ctx, "deferred fk check", p.txn,
+		sessiondata.NodeUserSessionDataOverride, query, args...)
+	if err != nil || row == nil {
+		return err
+	}
+
+	colNames := make([]string, len(srcCols))
+	valuesStr := make([]string, len(row))
+	hasNull := false
+	for i := range row {
+		colNames[i] = srcCols[i].GetName()
+		valuesStr[i] = row[i].String()
+		hasNull = hasNull || row[i] == tree.DNull
+	}
+	if matchFull && hasNull {
+		return pgerror.WithConstraintName(pgerror.Newf(pgcode.ForeignKeyViolation,
+			"foreign key violation: MATCH FULL does not allow mixing of null and nonnull values %s for %s",
+			formatValues(colNames, row), fk.Name,
+		), fk.Name)
+	}
+	if constraint.kind == exec.DeferredFKInboundCheck {
+		err = pgerror.Newf(pgcode.ForeignKeyViolation,
+			"update or delete on table %q violates foreign key constraint %q on table %q",
+			targetTable.GetName(), fk.Name, srcTable.GetName(),
+		)
+		err = errors.WithDetail(err, fmt.Sprintf(
+			"Key (%s)=(%s) is still referenced from table %q.",
+			strings.Join(colNames, ","), strings.Join(valuesStr, ","), srcTable.GetName(),
+		))
+	} else {
+		err = pgerror.Newf(pgcode.ForeignKeyViolation,
+			"insert or update on table %q violates foreign key constraint %q",
+			srcTable.GetName(), fk.Name,
+		)
+		err = errors.WithDetail(err, fmt.Sprintf(
+			"Key (%s)=(%s) is not present in table %q.",
+			strings.Join(colNames, ","), strings.Join(valuesStr, ","), targetTable.GetName(),
+		))
+	}
+	return pgerror.WithConstraintName(err, fk.Name)
+}
+
+// runDeferredUniqueCheck validates the given keys against the unique without
+// index constraint identified by constraint. A key violates the constraint if
+// more than one row of the table has it.
+func (p *planner) runDeferredUniqueCheck(
+	ctx context.Context,
+	table catalog.TableDescriptor,
+	constraint *deferredConstraint,
+	keys []tree.Datums,
+) error {
+	// The predicate of a partial constraint refers to columns by name, so it
+	// changes if one of them is renamed. It is only used to tell apart
+	// constraints on the same columns.
+	var uc *descpb.UniqueWithoutIndexConstraint
+	uniqueConstraints := table.GetUniqueWithoutIndexConstraints()
+	for i := range uniqueConstraints {
+		c := &uniqueConstraints[i]
+		if !c.ColumnIDs.PermutationOf(constraint.columnIDs) {
+			continue
+		}
+		if uc == nil || c.Predicate == constraint.predicate {
+			uc = c
+		}
+	}
+	if uc == nil {
+		return errDeferredConstraintDropped(table, constraint)
+	}
+	cols, err := columnsForIDs(table, constraint.columnIDs)
+	if err != nil {
+		return err
+	}
+
+	values, keyCols, args := deferredKeysClause(cols, keys)
+	where := make([]string, 0, len(keyCols)+1)
+	for i := range keyCols {
+		where = append(where, fmt.Sprintf("tbl.%s = k.%s", tree.NameString(cols[i].GetName()), keyCols[i]))
+	}
+	if uc.Predicate != "" {
+		// Unqualified column names in the predicate resolve to tbl, the
+		// innermost scope.
+		where = append(where, fmt.Sprintf("(%s)", uc.Predicate))
+	}
+	query := fmt.Sprintf(
+		`SELECT %[1]s FROM (VALUES %[2]s) AS k(%[1]s)
+		 WHERE (SELECT count(*) FROM [%[3]d AS tbl] WHERE %[4]s) > 1
+		 LIMIT 1`,
+		strings.Join(keyCols, ", "),  // 1
+		values,                       // 2
+		table.GetID(),                // 3
+		strings.Join(where, " AND "), // 4
+	)
+	log.VEventf(ctx, 2, "running deferred unique check %q on %d keys", uc.Name, len(keys))
+	row, err := p.ExecCfg().InternalExecutor.QueryRowEx(ctx, "deferred unique check", p.txn,
+		sessiondata.NodeUserSessionDataOverride, query, args...)
+	if err != nil || row == nil {
+		return err
+	}
+	colNames := make([]string, len(cols))
+	valuesStr := make([]string, len(row))
+	for i := range row {
+		colNames[i] = cols[i].GetName()
+		valuesStr[i] = row[i].String()
+	}
+	return errors.WithDetail(
+		pgerror.WithConstraintName(
+			pgerror.Newf(
+				pgcode.UniqueViolation,
+				"duplicate key value violates unique constraint %q", uc.Name,
+			),
+			uc.Name,
+		),
+		fmt.Sprintf(
+			"Key (%s)=(%s) already exists.", strings.Join(colNames, ","), strings.Join(valuesStr, ","),
+		),
+	)
+}
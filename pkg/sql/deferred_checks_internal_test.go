@@ -0,0 +1,77 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/exec"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDeferredCheckCollectionMemoryAccounting verifies that the keys queued
+// for deferred checks are accounted for, and that queueing fails once the
+// budget is exhausted.
+func TestDeferredCheckCollectionMemoryAccounting(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	monitor := mon.NewMonitorWithLimit(
+		"test-monitor",
+		mon.MemoryResource,
+		10000,         /* limit */
+		nil,           /* curCount */
+		nil,           /* maxHist */
+		1,             /* increment */
+		math.MaxInt64, /* noteworthy */
+		st,
+	)
+	monitor.Start(ctx, nil /* pool */, mon.MakeStandaloneBudget(10000))
+	defer monitor.Stop(ctx)
+
+	c := deferredCheckCollection{memAcc: monitor.MakeBoundAccount()}
+	constraint := deferredConstraint{
+		kind:      exec.DeferredUniqueCheck,
+		tableID:   52,
+		columnIDs: descpb.ColumnIDs{1},
+	}
+	key := tree.Datums{tree.NewDString("a")}
+	require.NoError(t, c.add(ctx, &constraint, key))
+	used := c.memAcc.Used()
+	require.Greater(t, used, int64(0))
+
+	// Duplicate keys are only queued once.
+	require.NoError(t, c.add(ctx, &constraint, key))
+	require.Equal(t, used, c.memAcc.Used())
+
+	var err error
+	for i := 0; err == nil; i++ {
+		err = c.add(ctx, &constraint, tree.Datums{tree.NewDInt(tree.DInt(i))})
+	}
+	require.Equal(t, pgcode.OutOfMemory, pgerror.GetPGCode(err))
+
+	c.reset(ctx)
+	require.Empty(t, c.checks)
+	require.Equal(t, int64(0), c.memAcc.Used())
+	c.memAcc.Close(ctx)
+}
@@ -0,0 +1,63 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/server"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// TestDeferrableConstraintsMixedVersion checks that DEFERRABLE constraints
+// cannot be created until all nodes defer their checks.
+func TestDeferrableConstraintsMixedVersion(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{
+		Knobs: base.TestingKnobs{
+			Server: &server.TestingKnobs{
+				DisableAutomaticVersionUpgrade: 1,
+				BinaryVersionOverride:          clusterversion.ByKey(clusterversion.DeferrableConstraints - 1),
+			},
+		},
+	})
+	defer s.Stopper().Stop(context.Background())
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	sqlDB.Exec(t, `CREATE TABLE parent (p INT PRIMARY KEY)`)
+	sqlDB.Exec(t, `CREATE TABLE child (c INT PRIMARY KEY, p INT)`)
+	sqlDB.Exec(t, `SET experimental_enable_unique_without_index_constraints = true`)
+
+	const (
+		createChild = `CREATE TABLE child2 (c INT PRIMARY KEY, p INT REFERENCES parent (p) DEFERRABLE)`
+		addFK       = `ALTER TABLE child ADD CONSTRAINT fk FOREIGN KEY (p) REFERENCES parent (p) DEFERRABLE INITIALLY DEFERRED`
+		addUnique   = `ALTER TABLE child ADD CONSTRAINT uniq UNIQUE WITHOUT INDEX (p) DEFERRABLE`
+	)
+	for _, stmt := range []string{createChild, addFK, addUnique} {
+		sqlDB.ExpectErr(t, `DEFERRABLE constraints require all nodes to be upgraded`, stmt)
+	}
+	// Constraints which are not deferrable are not affected.
+	sqlDB.Exec(t, `CREATE TABLE child3 (c INT PRIMARY KEY, p INT REFERENCES parent (p))`)
+
+	sqlDB.Exec(t, `SET CLUSTER SETTING version = $1`,
+		clusterversion.ByKey(clusterversion.DeferrableConstraints).String())
+	for _, stmt := range []string{createChild, addFK, addUnique} {
+		sqlDB.Exec(t, stmt)
+	}
+}
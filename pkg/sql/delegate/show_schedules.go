@@ -55,6 +55,21 @@ WHERE status='%s' AND created_by_type='%s' AND created_by_id=schedule_id
 			"executor_type = '%s'", tree.ScheduledBackupExecutor.InternalName()))
 		columnExprs = append(columnExprs, fmt.Sprintf(
 			"%s->>'backup_statement' AS command", commandColumn))
+	case tree.ScheduledChangefeedExecutor:
+		whereExprs = append(whereExprs, fmt.Sprintf(
+			"executor_type = '%s'", tree.ScheduledChangefeedExecutor.InternalName()))
+		columnExprs = append(columnExprs, fmt.Sprintf(
+			"%s->>'changefeedStatement' AS command", commandColumn))
+	case tree.ScheduledExportExecutor:
+		whereExprs = append(whereExprs, fmt.Sprintf(
+			"executor_type = '%s'", tree.ScheduledExportExecutor.InternalName()))
+		columnExprs = append(columnExprs, fmt.Sprintf(
+			"%s->>'exportStatement' AS command", commandColumn))
+	case tree.ScheduledSQLExecutor:
+		whereExprs = append(whereExprs, fmt.Sprintf(
+			"executor_type = '%s'", tree.ScheduledSQLExecutor.InternalName()))
+		columnExprs = append(columnExprs, fmt.Sprintf(
+			"%s->>'statement' AS command", commandColumn))
 	case tree.ScheduledSQLStatsCompactionExecutor:
 		whereExprs = append(whereExprs, fmt.Sprintf(
 			"executor_type = '%s'", tree.ScheduledSQLStatsCompactionExecutor.InternalName()))
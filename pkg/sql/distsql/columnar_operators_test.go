@@ -18,9 +18,12 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/col/coldata"
 	"github.com/cockroachdb/cockroach/pkg/col/typeconv"
+	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkv"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/colexec/colexecagg"
 	"github.com/cockroachdb/cockroach/pkg/sql/colexec/colexectestutils"
@@ -30,6 +33,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/randutil"
@@ -999,6 +1004,255 @@ func TestMergeJoinerAgainstProcessor(t *testing.T) {
 	}
 }
 
+func TestLookupJoinerAgainstProcessor(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	s, db, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+
+	// The table contains all pairs (a, b) of integers in [0, maxNum), and c
+	// takes all values in [0, maxNum) too, so the lookup of any non-NULL value
+	// finds at least one row.
+	const maxNum = 5
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `CREATE DATABASE test`)
+	sqlDB.Exec(t, `CREATE TABLE test.t (a INT, b INT, c INT, PRIMARY KEY (a, b), INDEX c_idx (c))`)
+	sqlDB.Exec(t, `INSERT INTO test.t SELECT i // $1, i % $1, (i // $1 + i % $1) % $1 FROM generate_series(0, $1 * $1 - 1) AS g(i)`, maxNum)
+	td := catalogkv.TestingGetTableDescriptor(kvDB, keys.SystemSQLCodec, "test", "t")
+	tableTypes := []*types.T{types.Int, types.Int, types.Int}
+
+	type indexInfo struct {
+		idx uint32
+		// numKeyCols is the maximum number of lookup columns.
+		numKeyCols int
+		// isUnique is true if the index is unique when all of its key
+		// columns are used for the lookup.
+		isUnique bool
+	}
+	indexes := []indexInfo{
+		{idx: 0, numKeyCols: 2, isUnique: true},
+		{idx: 1, numKeyCols: 1},
+	}
+	joinTypes := []descpb.JoinType{
+		descpb.InnerJoin,
+		descpb.LeftOuterJoin,
+		descpb.LeftSemiJoin,
+		descpb.LeftAntiJoin,
+	}
+
+	rng, seed := randutil.NewTestRand()
+	nRuns := 3
+	nRows := 10
+	maxCols := 3
+	intTyps := make([]*types.T, maxCols)
+	for i := range intTyps {
+		intTyps[i] = types.Int
+	}
+
+	for _, spillForced := range []bool{false, true} {
+		for run := 0; run < nRuns; run++ {
+			for _, joinType := range joinTypes {
+				for _, index := range indexes {
+					for nCols := 1; nCols <= maxCols; nCols++ {
+						nLookupCols := 1 + rng.Intn(index.numKeyCols)
+						if nLookupCols > nCols {
+							nLookupCols = nCols
+						}
+						inputTypes := intTyps[:nCols]
+						// When the spilling is forced, we don't generate NULLs
+						// so that the lookups are guaranteed to find some
+						// rows, and the hash joiner has to spill.
+						nullProb := nullProbability
+						if spillForced {
+							nullProb = 0
+						}
+						rows := randgen.MakeRandIntRowsInRange(rng, nRows, nCols, maxNum, nullProb)
+						lookupCols := generateEqualityColumns(rng, nCols, nLookupCols)
+
+						var onExpr execinfrapb.Expression
+						if joinType == descpb.InnerJoin && rng.Float64() < 0.5 {
+							// ON expressions are only supported natively for
+							// inner joins.
+							onExpr.Expr = fmt.Sprintf(
+								"@%d < @%d", rng.Intn(nCols)+1, nCols+rng.Intn(len(tableTypes))+1,
+							)
+						}
+						outputTypes := joinType.MakeOutputTypes(inputTypes, tableTypes)
+						outputColumns := make([]uint32, len(outputTypes))
+						for i := range outputColumns {
+							outputColumns[i] = uint32(i)
+						}
+						jrSpec := &execinfrapb.JoinReaderSpec{
+							Table:               *td.TableDesc(),
+							IndexIdx:            index.idx,
+							LookupColumns:       lookupCols,
+							LookupColumnsAreKey: index.isUnique && nLookupCols == index.numKeyCols,
+							OnExpr:              onExpr,
+							Type:                joinType,
+						}
+						pspec := &execinfrapb.ProcessorSpec{
+							Input: []execinfrapb.InputSyncSpec{{ColumnTypes: inputTypes}},
+							Core:  execinfrapb.ProcessorCoreUnion{JoinReader: jrSpec},
+							Post: execinfrapb.PostProcessSpec{
+								Projection:    true,
+								OutputColumns: outputColumns,
+							},
+							ResultTypes: outputTypes,
+						}
+						args := verifyColOperatorArgs{
+							anyOrder:       true,
+							inputTypes:     [][]*types.T{inputTypes},
+							inputs:         []rowenc.EncDatumRows{rows},
+							pspec:          pspec,
+							forceDiskSpill: spillForced,
+							rng:            rng,
+							s:              s,
+						}
+						if err := verifyColOperator(t, args); err != nil {
+							fmt.Printf("--- spillForced = %t join type = %s onExpr = %q"+
+								" seed = %d run = %d ---\n",
+								spillForced, joinType.String(), onExpr.Expr, seed, run)
+							fmt.Printf("--- indexIdx = %d lookupCols = %v ---\n", index.idx, lookupCols)
+							prettyPrintTypes(inputTypes, "input_table" /* tableName */)
+							prettyPrintInput(rows, inputTypes, "input_table" /* tableName */)
+							t.Fatal(err)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestInvertedJoinerAgainstProcessor(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	s, db, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+
+	// Each array in the table has two elements in [0, maxNum). There are also
+	// rows with NULL and empty arrays.
+	const maxNum = 5
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `CREATE DATABASE test`)
+	sqlDB.Exec(t, `CREATE TABLE test.t (k INT PRIMARY KEY, a INT[], INVERTED INDEX a_idx (a))`)
+	sqlDB.Exec(t, `INSERT INTO test.t SELECT i, ARRAY[i % $1, i // $1 % $1] FROM generate_series(0, 2 * $1 * $1 - 1) AS g(i)`, maxNum)
+	sqlDB.Exec(t, `INSERT INTO test.t VALUES (-1, NULL), (-2, ARRAY[])`)
+	td := catalogkv.TestingGetTableDescriptor(kvDB, keys.SystemSQLCodec, "test", "t")
+	const invertedIndexIdx = 1
+	tableTypes := []*types.T{types.Int, types.IntArray}
+
+	joinTypes := []descpb.JoinType{
+		descpb.InnerJoin,
+		descpb.LeftOuterJoin,
+		descpb.LeftSemiJoin,
+		descpb.LeftAntiJoin,
+	}
+
+	rng, seed := randutil.NewTestRand()
+	nRuns := 3
+	nRows := 10
+	maxCols := 3
+	makeRows := func(nCols int) rowenc.EncDatumRows {
+		rows := make(rowenc.EncDatumRows, nRows)
+		for i := range rows {
+			rows[i] = make(rowenc.EncDatumRow, nCols)
+			var d tree.Datum = tree.DNull
+			if rng.Float64() >= nullProbability {
+				arr := tree.NewDArray(types.Int)
+				for n := rng.Intn(3); n > 0; n-- {
+					require.NoError(t, arr.Append(tree.NewDInt(tree.DInt(rng.Intn(maxNum)))))
+				}
+				d = arr
+			}
+			rows[i][0] = rowenc.DatumToEncDatum(types.IntArray, d)
+			for j := 1; j < nCols; j++ {
+				rows[i][j] = randgen.IntEncDatum(rng.Intn(2 * maxNum * maxNum))
+			}
+		}
+		return rows
+	}
+
+	for _, spillForced := range []bool{false, true} {
+		for run := 0; run < nRuns; run++ {
+			for _, joinType := range joinTypes {
+				for nCols := 1; nCols <= maxCols; nCols++ {
+					// The first input column is the array that is looked up in
+					// the inverted index; the remaining columns are integers.
+					inputTypes := make([]*types.T, nCols)
+					inputTypes[0] = types.IntArray
+					for i := 1; i < nCols; i++ {
+						inputTypes[i] = types.Int
+					}
+					rows := makeRows(nCols)
+
+					// In both expressions, @(nCols+1) refers to the primary key
+					// of the table and @(nCols+2) to the inverted column.
+					invertedExpr := execinfrapb.Expression{Expr: fmt.Sprintf("@%d @> @1", nCols+2)}
+					var onExpr execinfrapb.Expression
+					if joinType == descpb.InnerJoin && rng.Float64() < 0.5 {
+						// ON expressions are only supported natively for inner
+						// joins.
+						if nCols > 1 {
+							onExpr.Expr = fmt.Sprintf("@%d < @%d", rng.Intn(nCols-1)+2, nCols+1)
+						} else {
+							onExpr.Expr = fmt.Sprintf("@%d > %d", nCols+1, rng.Intn(2*maxNum*maxNum))
+						}
+					}
+					// The inverted column cannot be output by the joiner, so
+					// only the primary key is included from the table.
+					outputTypes := joinType.MakeOutputTypes(inputTypes, tableTypes[:1])
+					outputColumns := make([]uint32, len(outputTypes))
+					for i := range outputColumns {
+						outputColumns[i] = uint32(i)
+					}
+					ijSpec := &execinfrapb.InvertedJoinerSpec{
+						Table:        *td.TableDesc(),
+						IndexIdx:     invertedIndexIdx,
+						InvertedExpr: invertedExpr,
+						OnExpr:       onExpr,
+						Type:         joinType,
+					}
+					pspec := &execinfrapb.ProcessorSpec{
+						Input: []execinfrapb.InputSyncSpec{{ColumnTypes: inputTypes}},
+						Core:  execinfrapb.ProcessorCoreUnion{InvertedJoiner: ijSpec},
+						Post: execinfrapb.PostProcessSpec{
+							Projection:    true,
+							OutputColumns: outputColumns,
+						},
+						ResultTypes: outputTypes,
+					}
+					args := verifyColOperatorArgs{
+						anyOrder:       true,
+						inputTypes:     [][]*types.T{inputTypes},
+						inputs:         []rowenc.EncDatumRows{rows},
+						pspec:          pspec,
+						forceDiskSpill: spillForced,
+						// The inverted joiner buffers the input rows and the
+						// index rows in a spilling queue and a spilling buffer
+						// which don't report when they spill.
+						forcedDiskSpillMightNotOccur: true,
+						rng:                          rng,
+						s:                            s,
+					}
+					if err := verifyColOperator(t, args); err != nil {
+						fmt.Printf("--- spillForced = %t join type = %s onExpr = %q"+
+							" seed = %d run = %d ---\n",
+							spillForced, joinType.String(), onExpr.Expr, seed, run)
+						prettyPrintTypes(inputTypes, "input_table" /* tableName */)
+						prettyPrintInput(rows, inputTypes, "input_table" /* tableName */)
+						t.Fatal(err)
+					}
+				}
+			}
+		}
+	}
+}
+
 // generateColumnOrdering produces a random ordering of nOrderingCols columns
 // on a table with nCols columns, so nOrderingCols must be not greater than
 // nCols.
@@ -22,6 +22,9 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/col/coldata"
 	"github.com/cockroachdb/cockroach/pkg/col/coldataext"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/kvcoord"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/colcontainer"
 	"github.com/cockroachdb/cockroach/pkg/sql/colexec"
@@ -37,6 +40,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/util/randutil"
 	"github.com/cockroachdb/errors"
 )
@@ -67,6 +71,9 @@ type verifyColOperatorArgs struct {
 	numForcedRepartitions int
 	// rng (if set) will be used to randomize batch size.
 	rng *rand.Rand
+	// s (if set) is the test server used by the processors that read from KV
+	// (like lookup and inverted joiners).
+	s serverutils.TestServerInterface
 }
 
 // verifyColOperator passes inputs through both the processor defined by pspec
@@ -86,6 +93,14 @@ func verifyColOperator(t *testing.T, args verifyColOperatorArgs) error {
 
 	ctx := context.Background()
 	st := cluster.MakeTestingClusterSettings()
+	if args.s != nil && args.forceDiskSpill {
+		// The memory limit used when the spilling is forced leaves no budget
+		// for the Streamer, so KV is read without it.
+		useStreamer, _ := settings.Lookup(
+			"sql.distsql.use_streamer.enabled", settings.LookupForLocalAccess, settings.ForSystemTenant,
+		)
+		useStreamer.(*settings.BoolSetting).Override(ctx, &st.SV, false)
+	}
 	tempEngine, tempFS, err := storage.NewTempEngine(ctx, base.DefaultTestTempStorageConfig(st), base.DefaultTestStoreSpec)
 	if err != nil {
 		return err
@@ -105,6 +120,17 @@ func verifyColOperator(t *testing.T, args verifyColOperatorArgs) error {
 		DiskMonitor: diskMonitor,
 	}
 	flowCtx.Cfg.TestingKnobs.ForceDiskSpill = args.forceDiskSpill
+	// The processor and the columnar operator are read from in an interleaved
+	// fashion, so if they read from KV, each one of them gets its own txn.
+	procFlowCtx, colOpFlowCtx := flowCtx, flowCtx
+	if args.s != nil {
+		flowCtx.Cfg.Stopper = args.s.Stopper()
+		flowCtx.Cfg.DistSender = args.s.DistSenderI().(*kvcoord.DistSender)
+		procFlowCtxCopy, colOpFlowCtxCopy := *flowCtx, *flowCtx
+		procFlowCtxCopy.Txn = kv.NewTxn(ctx, args.s.DB(), args.s.NodeID())
+		colOpFlowCtxCopy.Txn = kv.NewTxn(ctx, args.s.DB(), args.s.NodeID())
+		procFlowCtx, colOpFlowCtx = &procFlowCtxCopy, &colOpFlowCtxCopy
+	}
 	var monitorRegistry colexecargs.MonitorRegistry
 	defer monitorRegistry.Close(ctx)
 
@@ -116,7 +142,7 @@ func verifyColOperator(t *testing.T, args verifyColOperatorArgs) error {
 	}
 
 	proc, err := rowexec.NewProcessor(
-		ctx, flowCtx, 0, &args.pspec.Core, &args.pspec.Post,
+		ctx, procFlowCtx, 0, &args.pspec.Core, &args.pspec.Post,
 		inputsProc, []execinfra.RowReceiver{nil}, nil,
 	)
 	if err != nil {
@@ -132,7 +158,7 @@ func verifyColOperator(t *testing.T, args verifyColOperatorArgs) error {
 	testAllocator := colmem.NewAllocator(ctx, &acc, coldataext.NewExtendedColumnFactory(&evalCtx))
 	columnarizers := make([]colexecop.Operator, len(args.inputs))
 	for i, input := range inputsColOp {
-		columnarizers[i] = colexec.NewBufferingColumnarizer(testAllocator, flowCtx, int32(i)+1, input)
+		columnarizers[i] = colexec.NewBufferingColumnarizer(testAllocator, colOpFlowCtx, int32(i)+1, input)
 	}
 
 	constructorArgs := &colexecargs.NewColOperatorArgs{
@@ -156,13 +182,13 @@ func verifyColOperator(t *testing.T, args verifyColOperatorArgs) error {
 		constructorArgs.TestingKnobs.SpillingCallbackFn = func() { spilled = true }
 	}
 	constructorArgs.TestingKnobs.NumForcedRepartitions = args.numForcedRepartitions
-	result, err := colbuilder.NewColOperator(ctx, flowCtx, constructorArgs)
+	result, err := colbuilder.NewColOperator(ctx, colOpFlowCtx, constructorArgs)
 	if err != nil {
 		return err
 	}
 
 	outColOp := colexec.NewMaterializer(
-		flowCtx,
+		colOpFlowCtx,
 		int32(len(args.inputs))+2,
 		result.OpWithMetaInfo,
 		args.pspec.ResultTypes,
@@ -12,16 +12,21 @@ package sql
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/span"
 	"github.com/cockroachdb/cockroach/pkg/sql/stats"
@@ -86,13 +91,23 @@ func (dsp *DistSQLPlanner) createStatsPlan(
 	for i, c := range scan.cols {
 		colIdxMap.Set(c.GetID(), i)
 	}
-	sb := span.MakeBuilder(planCtx.EvalContext(), planCtx.ExtendedEvalCtx.Codec, desc, scan.index)
-	defer sb.Release()
-	scan.spans, err = sb.UnconstrainedSpans()
-	if err != nil {
-		return nil, err
+	var partialPredicate string
+	var fullStatisticID uint64
+	if details.UsingExtremes {
+		if partialPredicate, fullStatisticID, err = initPartialStatsScan(
+			planCtx, &scan, reqStats,
+		); err != nil {
+			return nil, err
+		}
+	} else {
+		sb := span.MakeBuilder(planCtx.EvalContext(), planCtx.ExtendedEvalCtx.Codec, desc, scan.index)
+		defer sb.Release()
+		scan.spans, err = sb.UnconstrainedSpans()
+		if err != nil {
+			return nil, err
+		}
+		scan.isFull = true
 	}
-	scan.isFull = true
 
 	p, err := dsp.createTableReaders(planCtx, &scan)
 	if err != nil {
@@ -133,8 +148,8 @@ func (dsp *DistSQLPlanner) createStatsPlan(
 			// currently have a way of using more than one or deciding which one
 			// is better.
 			//
-			// We do not generate multi-column stats with histograms, so there
-			// is no need to find an index for multi-column stats here.
+			// We do not generate multi-column inverted stats with histograms, so
+			// there is no need to find an index for multi-column stats here.
 			//
 			// TODO(mjibson): allow multiple inverted indexes on the same column
 			// (i.e., with different configurations). See #50655.
@@ -210,7 +225,7 @@ func (dsp *DistSQLPlanner) createStatsPlan(
 	}
 
 	var rowsExpected uint64
-	if len(tableStats) > 0 {
+	if len(tableStats) > 0 && !details.UsingExtremes {
 		overhead := stats.AutomaticStatisticsFractionStaleRows.Get(&dsp.st.SV)
 		// Convert to a signed integer first to make the linter happy.
 		rowsExpected = uint64(int64(
@@ -230,6 +245,8 @@ func (dsp *DistSQLPlanner) createStatsPlan(
 		TableID:          desc.GetID(),
 		JobID:            jobID,
 		RowsExpected:     rowsExpected,
+		PartialPredicate: partialPredicate,
+		FullStatisticID:  fullStatisticID,
 	}
 	// Plan the SampleAggregator on the gateway, unless we have a single Sampler.
 	node := dsp.gatewayNodeID
@@ -247,9 +264,103 @@ func (dsp *DistSQLPlanner) createStatsPlan(
 	return p, nil
 }
 
+// initPartialStatsScan sets up the scan to only read the rows in which the
+// column of the requested partial statistic is outside the bounds of the
+// histogram of the most recent full statistic on the column, from an index on
+// the column. It returns the predicate that describes the scanned rows and the
+// ID of the full statistic.
+func initPartialStatsScan(
+	planCtx *PlanningCtx, scan *scanNode, reqStats []requestedStat,
+) (predicate string, fullStatisticID uint64, _ error) {
+	if len(reqStats) != 1 || len(reqStats[0].columns) != 1 || reqStats[0].inverted {
+		return "", 0, errors.AssertionFailedf("partial statistics must be requested on a single column")
+	}
+	desc := scan.desc
+	colID := reqStats[0].columns[0]
+	col, err := desc.FindColumnWithID(colID)
+	if err != nil {
+		return "", 0, err
+	}
+	idx := partialStatsIndex(desc, colID)
+	if idx == nil {
+		return "", 0, pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+			"column %q must be the first key column of a forward, non-partial index "+
+				"to create partial statistics", col.GetName())
+	}
+	tableStats, err := planCtx.ExtendedEvalCtx.ExecCfg.TableStatsCache.GetStoredTableStats(planCtx.ctx, desc)
+	if err != nil {
+		return "", 0, err
+	}
+	fullStat := mostRecentFullStatWithHistogram(tableStats, colID)
+	if fullStat == nil {
+		return "", 0, pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+			"column %q does not have a full statistic with a histogram to extend", col.GetName())
+	}
+
+	// The bounds are the lowest and highest upper bounds of the histogram,
+	// ignoring the NULL bucket.
+	var lo, hi tree.Datum
+	for _, b := range fullStat.Histogram {
+		if b.UpperBound == tree.DNull {
+			continue
+		}
+		if lo == nil {
+			lo = b.UpperBound
+		}
+		hi = b.UpperBound
+	}
+	if lo == nil {
+		return "", 0, errors.AssertionFailedf("histogram of statistic %d has no buckets", fullStat.StatisticID)
+	}
+
+	sb := span.MakeBuilder(planCtx.EvalContext(), planCtx.ExtendedEvalCtx.Codec, desc, idx)
+	defer sb.Release()
+	var nullSpan, loSpan, hiSpan roachpb.Span
+	for _, v := range []struct {
+		datum tree.Datum
+		span  *roachpb.Span
+	}{
+		{datum: tree.DNull, span: &nullSpan},
+		{datum: lo, span: &loSpan},
+		{datum: hi, span: &hiSpan},
+	} {
+		if *v.span, _, err = sb.SpanFromEncDatums(
+			rowenc.EncDatumRow{rowenc.EncDatum{Datum: v.datum}}, 1, /* prefixLen */
+		); err != nil {
+			return "", 0, err
+		}
+	}
+	idxSpan := desc.IndexSpan(planCtx.ExtendedEvalCtx.Codec, idx.GetID())
+	if idx.GetKeyColumnDirection(0) == descpb.IndexDescriptor_ASC {
+		// NULLs sort first in an ascending index.
+		scan.spans = roachpb.Spans{
+			{Key: nullSpan.EndKey, EndKey: loSpan.Key},
+			{Key: hiSpan.EndKey, EndKey: idxSpan.EndKey},
+		}
+	} else {
+		// NULLs sort last in a descending index.
+		scan.spans = roachpb.Spans{
+			{Key: idxSpan.Key, EndKey: hiSpan.Key},
+			{Key: loSpan.EndKey, EndKey: nullSpan.Key},
+		}
+	}
+	scan.index = idx
+	scan.isFull = false
+
+	colName := tree.Name(col.GetName())
+	predicate = fmt.Sprintf("(%s < %s) OR (%s > %s)",
+		colName.String(), tree.AsString(lo), colName.String(), tree.AsString(hi))
+	return predicate, fullStat.StatisticID, nil
+}
+
 func (dsp *DistSQLPlanner) createPlanForCreateStats(
 	planCtx *PlanningCtx, jobID jobspb.JobID, details jobspb.CreateStatsDetails,
 ) (*PhysicalPlan, error) {
+	if details.UsingExtremes && len(details.ColumnStats) > 1 {
+		// Partial statistics on each column are collected by a separate flow
+		// (see planAndRunCreateStats), so only the first column is planned here.
+		details.ColumnStats = details.ColumnStats[:1]
+	}
 	reqStats := make([]requestedStat, len(details.ColumnStats))
 	histogramCollectionEnabled := stats.HistogramClusterMode.Get(&dsp.st.SV)
 	for i := 0; i < len(reqStats); i++ {
@@ -282,7 +393,38 @@ func (dsp *DistSQLPlanner) planAndRunCreateStats(
 	ctx = logtags.AddTag(ctx, "create-stats-distsql", nil)
 
 	details := job.Details().(jobspb.CreateStatsDetails)
-	physPlan, err := dsp.createPlanForCreateStats(planCtx, job.ID(), details)
+	if !details.UsingExtremes {
+		return dsp.planAndRunCreateStatsFlow(ctx, evalCtx, planCtx, txn, job.ID(), details, resultWriter)
+	}
+	// Partial statistics scan a different index for each column, so the
+	// statistics on each column are collected by a separate flow.
+	for i := range details.ColumnStats {
+		if i > 0 {
+			planCtx = dsp.NewPlanningCtx(ctx, evalCtx, nil /* planner */, txn, !planCtx.isLocal)
+		}
+		colDetails := details
+		colDetails.ColumnStats = details.ColumnStats[i : i+1]
+		if err := dsp.planAndRunCreateStatsFlow(
+			ctx, evalCtx, planCtx, txn, job.ID(), colDetails, resultWriter,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// planAndRunCreateStatsFlow plans and runs a single flow that collects the
+// statistics requested in details.
+func (dsp *DistSQLPlanner) planAndRunCreateStatsFlow(
+	ctx context.Context,
+	evalCtx *extendedEvalContext,
+	planCtx *PlanningCtx,
+	txn *kv.Txn,
+	jobID jobspb.JobID,
+	details jobspb.CreateStatsDetails,
+	resultWriter *RowResultWriter,
+) error {
+	physPlan, err := dsp.createPlanForCreateStats(planCtx, jobID, details)
 	if err != nil {
 		return err
 	}
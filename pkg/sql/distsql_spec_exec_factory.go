@@ -1034,6 +1034,12 @@ func (e *distSQLSpecExecFactory) ConstructErrorIfRows(
 	return nil, unimplemented.NewWithIssue(47473, "experimental opt-driven distsql planning: error if rows")
 }
 
+func (e *distSQLSpecExecFactory) ConstructDeferredCheck(
+	input exec.Node, check *exec.DeferredCheck,
+) (exec.Node, error) {
+	return nil, unimplemented.NewWithIssue(47473, "experimental opt-driven distsql planning: deferred check")
+}
+
 func (e *distSQLSpecExecFactory) ConstructOpaque(metadata opt.OpaqueMetadata) (exec.Node, error) {
 	plan, err := constructOpaque(metadata)
 	if err != nil {
@@ -17,6 +17,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/dbdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/resolver"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
@@ -36,6 +37,7 @@ type dropCascadeState struct {
 	toDeleteByID            map[descpb.ID]*toDelete
 	allTableObjectsToDelete []*tabledesc.Mutable
 	typesToDelete           []*typedesc.Mutable
+	functionsToDelete       []*funcdesc.Mutable
 
 	droppedNames []string
 }
@@ -66,6 +68,17 @@ func (d *dropCascadeState) collectObjectsInSchema(
 	for i := range names {
 		d.objectNamesToDelete = append(d.objectNamesToDelete, &names[i])
 	}
+	for _, fn := range schema.GetFunctions() {
+		for _, o := range fn.Overloads {
+			fnDesc, err := p.Descriptors().GetMutableFunctionByID(
+				ctx, p.txn, o.ID, tree.ObjectLookupFlagsWithRequired(),
+			)
+			if err != nil {
+				return err
+			}
+			d.functionsToDelete = append(d.functionsToDelete, fnDesc)
+		}
+	}
 	d.schemasToDelete = append(d.schemasToDelete, schemaWithDbDesc{schema: schema, dbDesc: db})
 	return nil
 }
@@ -219,6 +232,20 @@ func (d *dropCascadeState) dropAllCollectedObjects(ctx context.Context, p *plann
 		}
 	}
 
+	// Now delete all of the functions. They are dropped with their schema, so
+	// the schema descriptors are not updated.
+	for _, fnDesc := range d.functionsToDelete {
+		fnDesc.SetDropped()
+		if err := p.writeFuncSchemaChange(
+			ctx, fnDesc, "dropping function "+fnDesc.Signature(),
+		); err != nil {
+			return err
+		}
+		if err := p.removeFunctionComment(ctx, fnDesc.GetID()); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
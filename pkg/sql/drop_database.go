@@ -84,6 +84,9 @@ func (p *planner) DropDatabase(ctx context.Context, n *tree.DropDatabase) (planN
 
 	d := newDropCascadeState()
 
+	// Functions are stored in schema descriptors, including the one of the
+	// public schema, so they are not collected as objects to delete.
+	hasFunctions := false
 	for _, schema := range schemas {
 		res, err := p.Descriptors().GetSchemaByName(
 			ctx, p.txn, dbDesc, schema, tree.SchemaLookupFlags{
@@ -97,9 +100,10 @@ func (p *planner) DropDatabase(ctx context.Context, n *tree.DropDatabase) (planN
 		if err := d.collectObjectsInSchema(ctx, p, dbDesc, res); err != nil {
 			return nil, err
 		}
+		hasFunctions = hasFunctions || len(res.GetFunctions()) > 0
 	}
 
-	if len(d.objectNamesToDelete) > 0 {
+	if len(d.objectNamesToDelete) > 0 || hasFunctions {
 		switch n.DropBehavior {
 		case tree.DropRestrict:
 			return nil, pgerror.Newf(pgcode.DependentObjectsStillExist,
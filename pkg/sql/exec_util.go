@@ -63,6 +63,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
 	"github.com/cockroachdb/cockroach/pkg/sql/gcjob/gcjobnotifier"
+	"github.com/cockroachdb/cockroach/pkg/sql/indexadvisor"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
 	"github.com/cockroachdb/cockroach/pkg/sql/optionalnodeliveness"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
@@ -70,6 +71,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
 	"github.com/cockroachdb/cockroach/pkg/sql/physicalplan"
+	"github.com/cockroachdb/cockroach/pkg/sql/planbaseline"
 	"github.com/cockroachdb/cockroach/pkg/sql/querycache"
 	"github.com/cockroachdb/cockroach/pkg/sql/row"
 	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
@@ -393,6 +395,27 @@ var implicitSelectForUpdateClusterMode = settings.RegisterBoolSetting(
 	true,
 ).WithPublic()
 
+// allowReadCommittedIsolation controls whether transactions may run under
+// the READ COMMITTED isolation level. When disabled, transactions requesting
+// READ COMMITTED are transparently upgraded to SERIALIZABLE.
+var allowReadCommittedIsolation = settings.RegisterBoolSetting(
+	settings.TenantWritable,
+	"sql.txn.read_committed_isolation.enabled",
+	"set to true to allow transactions to use the READ COMMITTED isolation level; if false, READ COMMITTED transactions are upgraded to SERIALIZABLE",
+	false,
+).WithPublic()
+
+// readCommittedStmtRetries is the number of times a statement of a READ
+// COMMITTED transaction is retried at a new read snapshot after a retryable
+// error, before the error is handled by restarting the whole transaction.
+var readCommittedStmtRetries = settings.RegisterIntSetting(
+	settings.TenantWritable,
+	"sql.txn.read_committed_isolation.max_statement_retries",
+	"maximum number of times a statement of a READ COMMITTED transaction is retried after a retryable error",
+	10,
+	settings.NonNegativeInt,
+)
+
 var insertFastPathClusterMode = settings.RegisterBoolSetting(
 	settings.TenantWritable,
 	"sql.defaults.insert_fast_path.enabled",
@@ -1185,6 +1208,13 @@ type ExecutorConfig struct {
 	// StmtDiagnosticsRecorder deals with recording statement diagnostics.
 	StmtDiagnosticsRecorder *stmtdiagnostics.Registry
 
+	// PlanBaselines caches the accepted plan baselines of statement
+	// fingerprints.
+	PlanBaselines *planbaseline.Registry
+
+	// IndexAdvisor consolidates the index recommendations for the workload.
+	IndexAdvisor *indexadvisor.Advisor
+
 	ExternalIODirConfig base.ExternalIODirConfig
 
 	GCJobNotifier *gcjobnotifier.Notifier
@@ -1834,6 +1864,10 @@ type SessionArgs struct {
 	// client.
 	RemoteAddr            net.Addr
 	ConnResultsBufferSize int64
+	// Replication is set if the connection was established in logical
+	// replication mode, in which the commands of the streaming replication
+	// protocol are accepted.
+	Replication bool
 }
 
 // SessionRegistry stores a set of all sessions on this node.
@@ -2772,6 +2806,10 @@ func (m *sessionDataMutator) SetDefaultTransactionPriority(val tree.UserPriority
 	m.data.DefaultTxnPriority = int64(val)
 }
 
+func (m *sessionDataMutator) SetDefaultTransactionIsolationLevel(val tree.IsolationLevel) {
+	m.data.DefaultTxnIsolationLevel = int64(val)
+}
+
 func (m *sessionDataMutator) SetDefaultTransactionReadOnly(val bool) {
 	m.data.DefaultTxnReadOnly = val
 }
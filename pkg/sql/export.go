@@ -17,6 +17,7 @@ import (
 	"strings"
 
 	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/featureflag"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings"
@@ -26,6 +27,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/compressutil"
 	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/errors"
 )
@@ -87,7 +89,6 @@ var exportOptionExpectValues = map[string]KVStringOptValidate{
 const exportChunkSizeDefault = int64(32 << 20) // 32 MB
 const exportChunkRowsDefault = 100000
 const exportFilePatternPart = "%part%"
-const exportCompressionCodec = "gzip"
 const csvSuffix = "csv"
 const parquetSuffix = "parquet"
 
@@ -221,12 +222,23 @@ func (ef *execFactory) ConstructExport(
 	// of positive result
 	var codec execinfrapb.FileCompression
 	if name, ok := optVals[exportOptionCompression]; ok && len(name) != 0 {
-		if strings.EqualFold(name, exportCompressionCodec) {
+		switch c, _ := compressutil.CodecFromName(name); c {
+		case compressutil.Gzip:
 			codec = execinfrapb.FileCompression_Gzip
-		} else {
+		case compressutil.Snappy:
+			codec = execinfrapb.FileCompression_Snappy
+		case compressutil.Zstd:
+			codec = execinfrapb.FileCompression_Zstd
+		default:
 			return nil, pgerror.Newf(pgcode.InvalidParameterValue,
 				"unsupported compression codec %s", name)
 		}
+		if codec != execinfrapb.FileCompression_Gzip &&
+			!ef.planner.ExecCfg().Settings.Version.IsActive(ef.planner.EvalContext().Context, clusterversion.SnappyAndZstdCompression) {
+			return nil, pgerror.Newf(pgcode.FeatureNotSupported,
+				"compression codec %s requires all nodes to be upgraded to %s",
+				name, clusterversion.ByKey(clusterversion.SnappyAndZstdCompression))
+		}
 	}
 
 	exportID := ef.planner.stmt.QueryID.String()
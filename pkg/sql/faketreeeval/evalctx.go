@@ -238,6 +238,35 @@ func (*DummyEvalPlanner) DecodeGist(gist string) ([]string, error) {
 	return nil, errors.WithStack(errEvalPlanner)
 }
 
+// CapturePlanBaseline is part of the EvalPlanner interface.
+func (*DummyEvalPlanner) CapturePlanBaseline(
+	ctx context.Context, query string, enforce bool,
+) (int64, error) {
+	return 0, errors.WithStack(errEvalPlanner)
+}
+
+// PinPlanBaseline is part of the EvalPlanner interface.
+func (*DummyEvalPlanner) PinPlanBaseline(
+	ctx context.Context, query string, enforce bool,
+) (int64, error) {
+	return 0, errors.WithStack(errEvalPlanner)
+}
+
+// EvolvePlanBaseline is part of the EvalPlanner interface.
+func (*DummyEvalPlanner) EvolvePlanBaseline(ctx context.Context, id int64) (int64, bool, error) {
+	return 0, false, errors.WithStack(errEvalPlanner)
+}
+
+// AcceptPlanBaseline is part of the EvalPlanner interface.
+func (*DummyEvalPlanner) AcceptPlanBaseline(ctx context.Context, id int64) (bool, error) {
+	return false, errors.WithStack(errEvalPlanner)
+}
+
+// DropPlanBaseline is part of the EvalPlanner interface.
+func (*DummyEvalPlanner) DropPlanBaseline(ctx context.Context, id int64) (bool, error) {
+	return false, errors.WithStack(errEvalPlanner)
+}
+
 // CreateSessionRevivalToken is part of the EvalPlanner interface.
 func (*DummyEvalPlanner) CreateSessionRevivalToken() (*tree.DBytes, error) {
 	return nil, errors.WithStack(errEvalPlanner)
@@ -0,0 +1,300 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/idxusage"
+	"github.com/cockroachdb/cockroach/pkg/sql/indexadvisor"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/indexrec"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/optbuilder"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondatapb"
+	"github.com/cockroachdb/errors"
+)
+
+// indexAdvisorRecommender implements the indexadvisor.Recommender interface.
+type indexAdvisorRecommender struct {
+	execCfg *ExecutorConfig
+}
+
+var _ indexadvisor.Recommender = &indexAdvisorRecommender{}
+
+// NewIndexAdvisorRecommender returns the indexadvisor.Recommender which plans
+// statement fingerprints with the optimizer and reads the index usage
+// statistics of the cluster.
+func NewIndexAdvisorRecommender(execCfg *ExecutorConfig) indexadvisor.Recommender {
+	return &indexAdvisorRecommender{execCfg: execCfg}
+}
+
+// RecommendIndexes is part of the indexadvisor.Recommender interface.
+func (r *indexAdvisorRecommender) RecommendIndexes(
+	ctx context.Context, fingerprint, database string,
+) (recs []indexadvisor.IndexRecommendation, _ error) {
+	stmt, err := parser.ParseOne(fingerprint)
+	if err != nil {
+		return nil, err
+	}
+	switch stmt.AST.(type) {
+	case *tree.ParenSelect, *tree.Select, *tree.SelectClause, *tree.UnionClause,
+		*tree.Insert, *tree.Update, *tree.Delete:
+	default:
+		return nil, nil
+	}
+	if stmt.AST, stmt.NumPlaceholders, err = replaceHiddenConstants(stmt.AST); err != nil {
+		return nil, err
+	}
+
+	err = r.execCfg.DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		recs = recs[:0]
+		ip, cleanup := NewInternalPlanner(
+			"index-advisor",
+			txn,
+			security.NodeUserName(),
+			&MemoryMetrics{},
+			r.execCfg,
+			sessiondatapb.SessionData{},
+		)
+		defer cleanup()
+		localPlanner := ip.(*planner)
+		// The statement must resolve names like it does in the sessions which
+		// executed it.
+		localPlanner.SessionData().Database = database
+
+		indexRecs, err := localPlanner.indexRecommendationsForStatement(ctx, stmt)
+		if err != nil {
+			return err
+		}
+		for i := range indexRecs {
+			rec := &indexRecs[i]
+			desc, err := localPlanner.Descriptors().GetImmutableTableByID(
+				ctx, txn, descpb.ID(rec.TableID), tree.ObjectLookupFlagsWithRequired(),
+			)
+			if err != nil {
+				return err
+			}
+			tn, err := localPlanner.getQualifiedTableName(ctx, desc)
+			if err != nil {
+				return err
+			}
+			// Qualify the names in the SQL commands, since the advice is not
+			// specific to a database.
+			rec.Create.Table = *tn
+			var indexName string
+			if rec.Drop != nil {
+				for _, idx := range rec.Drop.IndexList {
+					idx.Table = *tn
+					indexName = string(idx.Index)
+				}
+			}
+			recs = append(recs, indexadvisor.IndexRecommendation{
+				Type:      rec.Type,
+				Table:     indexAdvisorTable(desc.GetID(), tn),
+				IndexName: indexName,
+				SQL:       rec.SQL(),
+			})
+		}
+		return nil
+	})
+	return recs, err
+}
+
+// UnusedIndexes is part of the indexadvisor.Recommender interface.
+func (r *indexAdvisorRecommender) UnusedIndexes(
+	ctx context.Context, unusedSince time.Time,
+) (unused []indexadvisor.UnusedIndex, _ error) {
+	resp, err := r.execCfg.SQLStatusServer.IndexUsageStatistics(
+		ctx, &serverpb.IndexUsageStatisticsRequest{},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if resp.LastReset.After(unusedSince) {
+		// The index usage statistics do not cover the whole period yet.
+		return nil, nil
+	}
+	indexStats := idxusage.NewLocalIndexUsageStatsFromExistingStats(&idxusage.Config{}, resp.Statistics)
+
+	err = r.execCfg.DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		unused = unused[:0]
+		ip, cleanup := NewInternalPlanner(
+			"index-advisor-unused-indexes",
+			txn,
+			security.NodeUserName(),
+			&MemoryMetrics{},
+			r.execCfg,
+			sessiondatapb.SessionData{},
+		)
+		defer cleanup()
+		localPlanner := ip.(*planner)
+		return forEachTableDesc(ctx, localPlanner, nil /* dbContext */, hideVirtual,
+			func(db catalog.DatabaseDescriptor, scName string, table catalog.TableDescriptor) error {
+				if db.GetID() == keys.SystemDatabaseID || !table.IsTable() {
+					return nil
+				}
+				// The indexes of tables which were changed recently may have been
+				// created too recently to have been read.
+				if table.GetModificationTime().GoTime().After(unusedSince) {
+					return nil
+				}
+				tn := tree.MakeTableNameWithSchema(
+					tree.Name(db.GetName()), tree.Name(scName), tree.Name(table.GetName()),
+				)
+				for _, idx := range table.PublicNonPrimaryIndexes() {
+					// Unique indexes enforce constraints, even if they are not read.
+					if idx.IsUnique() {
+						continue
+					}
+					stats := indexStats.Get(roachpb.TableID(table.GetID()), roachpb.IndexID(idx.GetID()))
+					if stats.TotalReadCount > 0 && stats.LastRead.After(unusedSince) {
+						continue
+					}
+					dropCmd := tree.DropIndex{
+						IndexList: tree.TableIndexNames{{
+							Table: tn,
+							Index: tree.UnrestrictedName(idx.GetName()),
+						}},
+					}
+					unused = append(unused, indexadvisor.UnusedIndex{
+						Table:     indexAdvisorTable(table.GetID(), &tn),
+						IndexName: idx.GetName(),
+						SQL:       dropCmd.String() + ";",
+						LastRead:  stats.LastRead,
+					})
+				}
+				return nil
+			})
+	})
+	return unused, err
+}
+
+func indexAdvisorTable(id descpb.ID, tn *tree.TableName) indexadvisor.Table {
+	return indexadvisor.Table{
+		ID:           id,
+		DatabaseName: tn.Catalog(),
+		SchemaName:   tn.Schema(),
+		Name:         tn.Table(),
+	}
+}
+
+// indexAdvisorResumer implements the jobs.Resumer interface for the automatic
+// index recommendations job, which refreshes the index advice with the
+// indexadvisor.Advisor of the node which adopted it.
+type indexAdvisorResumer struct {
+	job *jobs.Job
+}
+
+var _ jobs.Resumer = (*indexAdvisorResumer)(nil)
+
+// Resume implements the jobs.Resumer interface.
+func (r *indexAdvisorResumer) Resume(ctx context.Context, execCtx interface{}) error {
+	advisor := execCtx.(JobExecContext).ExecCfg().IndexAdvisor
+	if advisor == nil {
+		return errors.AssertionFailedf("index advisor is not configured")
+	}
+	return advisor.Run(ctx, r.job)
+}
+
+// OnFailOrCancel implements the jobs.Resumer interface.
+func (r *indexAdvisorResumer) OnFailOrCancel(context.Context, interface{}) error {
+	return nil
+}
+
+func init() {
+	jobs.RegisterConstructor(jobspb.TypeAutoIndexRecommendations,
+		func(job *jobs.Job, _ *cluster.Settings) jobs.Resumer {
+			return &indexAdvisorResumer{job: job}
+		})
+}
+
+// indexRecommendationsForStatement returns the index recommendations for the
+// given statement, which may contain placeholders. Since the recommendations
+// depend on the columns which are constrained by the statement rather than on
+// the values of the constants, the placeholders are assigned the default
+// values of their types.
+func (p *planner) indexRecommendationsForStatement(
+	ctx context.Context, stmt parser.Statement,
+) ([]indexrec.Recommendation, error) {
+	if err := p.semaCtx.Placeholders.Init(stmt.NumPlaceholders, nil /* typeHints */); err != nil {
+		return nil, err
+	}
+	p.stmt = makeStatement(stmt, ClusterWideID{} /* queryID */)
+	opc := &p.optPlanningCtx
+	opc.init(p)
+	opc.catalog.reset()
+	opc.optimizer.Init(p.EvalContext(), &opc.catalog)
+
+	f := opc.optimizer.Factory()
+	bld := optbuilder.New(ctx, &p.semaCtx, p.EvalContext(), &opc.catalog, f, stmt.AST)
+	bld.KeepPlaceholders = true
+	if err := bld.Build(); err != nil {
+		return nil, err
+	}
+	if f.Memo().HasPlaceholders() {
+		placeholders := &p.semaCtx.Placeholders
+		placeholders.Values = make(tree.QueryArguments, len(placeholders.Types))
+		for i, typ := range placeholders.Types {
+			if typ == nil {
+				return nil, errors.AssertionFailedf("could not determine the type of placeholder %d", i+1)
+			}
+			d, err := tree.NewDefaultDatum(p.EvalContext(), typ)
+			if err != nil {
+				return nil, err
+			}
+			placeholders.Values[i] = d
+		}
+		prepared := opc.optimizer.DetachMemo()
+		f.FoldingControl().AllowStableFolds()
+		if err := f.AssignPlaceholders(prepared); err != nil {
+			return nil, err
+		}
+	}
+	return opc.makeQueryIndexRecommendation()
+}
+
+// replaceHiddenConstants replaces the constants which were hidden in a
+// statement fingerprint (formatted with tree.FmtHideConstants) with
+// placeholders, so that the fingerprint can be planned. It returns the new
+// statement and its number of placeholders.
+func replaceHiddenConstants(stmt tree.Statement) (tree.Statement, int, error) {
+	var numPlaceholders int
+	stmt, err := tree.SimpleStmtVisit(stmt, func(expr tree.Expr) (bool, tree.Expr, error) {
+		switch t := expr.(type) {
+		case *tree.Placeholder:
+			if n := int(t.Idx) + 1; n > numPlaceholders {
+				numPlaceholders = n
+			}
+		case *tree.UnresolvedName:
+			if t.NumParts != 1 || (t.Parts[0] != "_" && !strings.HasPrefix(t.Parts[0], "__more")) {
+				break
+			}
+			p := &tree.Placeholder{Idx: tree.PlaceholderIdx(numPlaceholders)}
+			numPlaceholders++
+			return false, p, nil
+		}
+		return true, expr, nil
+	})
+	return stmt, numPlaceholders, err
+}
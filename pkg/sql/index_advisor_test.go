@@ -0,0 +1,64 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlstats/persistedsqlstats"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+func TestIndexAdvisor(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	sqlDB.Exec(t, `CREATE DATABASE d`)
+	sqlDB.Exec(t, `USE d`)
+	sqlDB.Exec(t, `CREATE TABLE t (k INT PRIMARY KEY, a INT, b INT, c STRING, INDEX b_idx (b))`)
+	for i := 0; i < 3; i++ {
+		sqlDB.Exec(t, `SELECT k FROM t WHERE a = $1`, i)
+	}
+	sqlDB.Exec(t, `SELECT k, c FROM t WHERE b > 1 AND b < 5`)
+	// Statements which cannot use indexes are ignored.
+	sqlDB.Exec(t, `SHOW TABLES`)
+
+	s.SQLServer().(*sql.Server).GetSQLStatsProvider().(*persistedsqlstats.PersistedSQLStats).Flush(ctx)
+
+	// The advice is refreshed by a single automatic job, which persists it.
+	sqlDB.CheckQueryResultsRetry(t, `
+SELECT count(*) FROM [SHOW AUTOMATIC JOBS] WHERE job_type = 'AUTO INDEX RECOMMENDATIONS'`,
+		[][]string{{"1"}},
+	)
+	sqlDB.Exec(t, `SET CLUSTER SETTING sql.index_recommendations.workload.interval = '10ms'`)
+
+	sqlDB.CheckQueryResultsRetry(t, `
+SELECT type, database_name, schema_name, table_name, index_name, sql, fingerprints
+  FROM crdb_internal.index_recommendations
+ ORDER BY sql`,
+		[][]string{
+			{"create", "d", "public", "t", "NULL", "CREATE INDEX ON d.public.t (a);", "1"},
+			{"replace", "d", "public", "t", "b_idx",
+				"CREATE INDEX ON d.public.t (b) STORING (c); DROP INDEX d.public.t@b_idx;", "1"},
+		},
+	)
+}
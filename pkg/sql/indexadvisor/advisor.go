@@ -0,0 +1,602 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package indexadvisor periodically consolidates index recommendations for the
+// workload of the cluster.
+//
+// The statement fingerprints which take up the most execution time according
+// to the persisted SQL statistics are planned with hypothetical indexes, and
+// the indexes recommended for them are weighed by the number of executions and
+// the total latency of these fingerprints. The advice also includes dropping
+// the secondary indexes which have not been read for a while, according to the
+// index usage statistics.
+package indexadvisor
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catconstants"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/indexrec"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+var refreshInterval = settings.RegisterDurationSetting(
+	settings.TenantWritable,
+	"sql.index_recommendations.workload.interval",
+	"interval at which index recommendations are consolidated for the workload, set to zero to disable",
+	time.Hour,
+	settings.NonNegativeDuration,
+).WithPublic()
+
+// checkJobInterval controls how often the Advisor checks if the automatic
+// index recommendations job exists, in order to start it if not.
+var checkJobInterval = settings.RegisterDurationSetting(
+	settings.TenantWritable,
+	"sql.index_recommendations.workload.check_job_interval",
+	"the frequency at which to check if the index recommendations job exists (and to start it if not)",
+	10*time.Minute,
+	settings.NonNegativeDuration,
+)
+
+var topStatements = settings.RegisterIntSetting(
+	settings.TenantWritable,
+	"sql.index_recommendations.workload.top_statements",
+	"number of statement fingerprints with the highest total execution time for which indexes are recommended",
+	100,
+	settings.PositiveInt,
+)
+
+var lookback = settings.RegisterDurationSetting(
+	settings.TenantWritable,
+	"sql.index_recommendations.workload.lookback",
+	"period of the persisted statement statistics which is considered for index recommendations",
+	7*24*time.Hour,
+	settings.PositiveDuration,
+)
+
+var unusedIndexThreshold = settings.RegisterDurationSetting(
+	settings.TenantWritable,
+	"sql.index_recommendations.workload.unused_index_threshold",
+	"duration without reads after which a secondary index is recommended to be dropped",
+	7*24*time.Hour,
+	settings.PositiveDuration,
+)
+
+// Table identifies the table of an index.
+type Table struct {
+	ID           descpb.ID
+	DatabaseName string
+	SchemaName   string
+	Name         string
+}
+
+// IndexRecommendation is an index recommendation for a statement.
+type IndexRecommendation struct {
+	Type  indexrec.RecommendationType
+	Table Table
+	// IndexName is the name of the existing index which is replaced by the
+	// recommended index, if any.
+	IndexName string
+	// SQL contains the SQL commands needed to follow the recommendation, with
+	// fully qualified names.
+	SQL string
+}
+
+// UnusedIndex is a secondary index which has not been read recently.
+type UnusedIndex struct {
+	Table     Table
+	IndexName string
+	// SQL is the SQL command which drops the index, with fully qualified names.
+	SQL string
+	// LastRead is the last time the index was read, if it was ever read since
+	// the index usage statistics were reset.
+	LastRead time.Time
+}
+
+// Recommender plans statements and inspects the indexes on behalf of the
+// Advisor.
+type Recommender interface {
+	// RecommendIndexes returns the index recommendations for the given
+	// statement fingerprint, planned in the given database. It returns no
+	// recommendations for statements which index recommendations do not apply
+	// to.
+	RecommendIndexes(ctx context.Context, fingerprint, database string) ([]IndexRecommendation, error)
+	// UnusedIndexes returns the secondary indexes which have not been read
+	// since the given time. Indexes which are needed to enforce constraints
+	// are never returned.
+	UnusedIndexes(ctx context.Context, unusedSince time.Time) ([]UnusedIndex, error)
+}
+
+// AdviceType is the type of a piece of Advice.
+type AdviceType int
+
+const (
+	// CreateIndex advises to create a new index.
+	CreateIndex AdviceType = iota
+	// ReplaceIndex advises to replace an existing index with an index which
+	// stores more columns.
+	ReplaceIndex
+	// DropIndex advises to drop an unused index.
+	DropIndex
+)
+
+// String implements the fmt.Stringer interface.
+func (t AdviceType) String() string {
+	switch t {
+	case CreateIndex:
+		return "create"
+	case ReplaceIndex:
+		return "replace"
+	case DropIndex:
+		return "drop"
+	default:
+		return "unknown"
+	}
+}
+
+// Advice is a consolidated index recommendation for the workload.
+type Advice struct {
+	Type  AdviceType
+	Table Table
+	// IndexName is the name of the existing index which is replaced or
+	// dropped. It is empty for CreateIndex.
+	IndexName string
+	// SQL contains the SQL commands needed to follow the advice.
+	SQL string
+	// Fingerprints is the number of statement fingerprints for which the index
+	// was recommended.
+	Fingerprints int
+	// Executions is the number of executions of these statement fingerprints
+	// during the lookback period.
+	Executions int64
+	// TotalLatency is the total service latency of these executions. It is
+	// used to rank the advice.
+	TotalLatency time.Duration
+	// LastRead is the last time the dropped index was read, if any. It is
+	// only set for DropIndex.
+	LastRead time.Time
+}
+
+// Advisor refreshes the index advice for the workload on behalf of the
+// automatic index recommendations job, which persists it in its progress so
+// that it can be shown in crdb_internal.index_recommendations and through the
+// HTTP API of every node. The Advisor of every node ensures that the job
+// exists.
+type Advisor struct {
+	db          *kv.DB
+	jr          *jobs.Registry
+	ie          sqlutil.InternalExecutor
+	st          *cluster.Settings
+	recommender Recommender
+
+	// started is the time at which the Advisor was started, before which no
+	// index usage statistics were collected on this node.
+	started time.Time
+}
+
+// NewAdvisor constructs a new Advisor.
+func NewAdvisor(
+	db *kv.DB,
+	jr *jobs.Registry,
+	ie sqlutil.InternalExecutor,
+	st *cluster.Settings,
+	recommender Recommender,
+) *Advisor {
+	return &Advisor{db: db, jr: jr, ie: ie, st: st, recommender: recommender}
+}
+
+// Start creates a background task that starts the automatic index
+// recommendations job. It also periodically ensures that the job exists,
+// recreating it if it doesn't.
+func (a *Advisor) Start(ctx context.Context, stopper *stop.Stopper) {
+	a.started = timeutil.Now()
+	ctx, _ = stopper.WithCancelOnQuiesce(ctx)
+	// NB: The only error that should occur here would be if the server were
+	// shutting down so let's swallow it.
+	_ = stopper.RunAsyncTask(ctx, "index-advisor-job-check", a.checkJobLoop)
+}
+
+func (a *Advisor) checkJobLoop(ctx context.Context) {
+	jobCheckCh := make(chan struct{}, 1)
+	triggerJobCheck := func() {
+		select {
+		case jobCheckCh <- struct{}{}:
+		default:
+		}
+	}
+	// The job is checked when it may have to be created: when the refreshes are
+	// enabled and once the cluster is upgraded.
+	refreshInterval.SetOnChange(&a.st.SV, func(ctx context.Context) {
+		triggerJobCheck()
+	})
+	checkJobInterval.SetOnChange(&a.st.SV, func(ctx context.Context) {
+		triggerJobCheck()
+	})
+	a.st.Version.SetOnChange(func(_ context.Context, _ clusterversion.ClusterVersion) {
+		triggerJobCheck()
+	})
+
+	checkJob := func() {
+		if refreshInterval.Get(&a.st.SV) == 0 ||
+			!a.st.Version.IsActive(ctx, clusterversion.AutoIndexRecommendationsJob) {
+			return
+		}
+		started, err := a.createAndStartJobIfNoneExists(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Errorf(ctx, "error starting auto index recommendations job: %v", err)
+		}
+		if started {
+			log.Infof(ctx, "started auto index recommendations job")
+		}
+	}
+
+	timer := timeutil.NewTimer()
+	defer timer.Stop()
+
+	triggerJobCheck()
+	for {
+		timer.Reset(checkJobInterval.Get(&a.st.SV))
+		select {
+		case <-jobCheckCh:
+			checkJob()
+		case <-timer.C:
+			timer.Read = true
+			checkJob()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// createAndStartJobIfNoneExists creates the automatic index recommendations
+// job iff it hasn't been created already and notifies the jobs registry to
+// adopt it. Returns a boolean indicating if the job was created.
+func (a *Advisor) createAndStartJobIfNoneExists(ctx context.Context) (bool, error) {
+	record := jobs.Record{
+		JobID:         a.jr.MakeJobID(),
+		Description:   "consolidating index recommendations for the workload",
+		Username:      security.RootUserName(),
+		Details:       jobspb.AutoIndexRecommendationsDetails{},
+		Progress:      jobspb.AutoIndexRecommendationsProgress{},
+		NonCancelable: true,
+	}
+
+	var job *jobs.Job
+	if err := a.db.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		exists, err := jobs.RunningJobExists(ctx, jobspb.InvalidJobID, a.ie, txn,
+			func(payload *jobspb.Payload) bool {
+				return payload.Type() == jobspb.TypeAutoIndexRecommendations
+			},
+		)
+		if err != nil {
+			return err
+		}
+		if exists {
+			job = nil
+			return nil
+		}
+		job, err = a.jr.CreateJobWithTxn(ctx, record, record.JobID, txn)
+		return err
+	}); err != nil {
+		return false, err
+	}
+
+	if job == nil {
+		return false, nil
+	}
+	a.jr.NotifyToResume(ctx, job.ID())
+	return true, nil
+}
+
+// Run refreshes the index advice periodically and persists it in the progress
+// of the given automatic index recommendations job. It only returns once ctx
+// is canceled.
+func (a *Advisor) Run(ctx context.Context, job *jobs.Job) error {
+	// The advice is refreshed an interval after the last refresh, which may
+	// have been done before the job was adopted by this node.
+	lastRefresh := timeutil.Now()
+	progress := job.Progress()
+	if p := progress.GetAutoIndexRecommendations(); p != nil && !p.Refreshed.IsZero() {
+		lastRefresh = p.Refreshed
+	}
+
+	var timer timeutil.Timer
+	defer timer.Stop()
+	intervalChanged := make(chan struct{}, 1)
+	refreshInterval.SetOnChange(&a.st.SV, func(ctx context.Context) {
+		select {
+		case intervalChanged <- struct{}{}:
+		default:
+		}
+	})
+	for {
+		if interval := refreshInterval.Get(&a.st.SV); interval > 0 {
+			timer.Reset(timeutil.Until(lastRefresh.Add(interval)))
+		} else {
+			timer.Stop()
+		}
+		select {
+		case <-intervalChanged:
+			continue
+		case <-timer.C:
+			timer.Read = true
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		// Errors are not returned, which would fail the job until it is
+		// recreated. The advice is refreshed again after the next interval.
+		lastRefresh = timeutil.Now()
+		advice, err := a.refresh(ctx)
+		if err == nil {
+			err = job.SetProgress(ctx, nil /* txn */, jobspb.AutoIndexRecommendationsProgress{
+				Advice:    adviceToProto(advice),
+				Refreshed: timeutil.Now(),
+			})
+		}
+		if err != nil && ctx.Err() == nil {
+			log.Warningf(ctx, "error refreshing index recommendations: %v", err)
+		}
+	}
+}
+
+// Advice returns the index advice of the last refresh, ordered by decreasing
+// relevance, along with the time of that refresh. The advice is read from the
+// progress of the automatic index recommendations job. The time is zero if the
+// advice was never refreshed.
+func (a *Advisor) Advice(ctx context.Context) (_ []Advice, _ time.Time, retErr error) {
+	it, err := a.ie.QueryIteratorEx(
+		ctx, "index-advisor-advice", nil, /* txn */
+		sessiondata.InternalExecutorOverride{User: security.NodeUserName()},
+		`SELECT payload, progress FROM system.jobs WHERE status IN `+
+			jobs.NonTerminalStatusTupleString+` ORDER BY created DESC`,
+	)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	defer func() { retErr = errors.CombineErrors(retErr, it.Close()) }()
+
+	var ok bool
+	for ok, err = it.Next(ctx); ok; ok, err = it.Next(ctx) {
+		row := it.Cur()
+		payload, err := jobs.UnmarshalPayload(row[0])
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		if payload.Type() != jobspb.TypeAutoIndexRecommendations {
+			continue
+		}
+		progress, err := jobs.UnmarshalProgress(row[1])
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		p := progress.GetAutoIndexRecommendations()
+		if p == nil {
+			return nil, time.Time{}, nil
+		}
+		return adviceFromProto(p.Advice), p.Refreshed, nil
+	}
+	return nil, time.Time{}, err
+}
+
+func adviceToProto(advice []Advice) []jobspb.AutoIndexRecommendationsProgress_IndexAdvice {
+	res := make([]jobspb.AutoIndexRecommendationsProgress_IndexAdvice, len(advice))
+	for i := range advice {
+		a := &advice[i]
+		res[i] = jobspb.AutoIndexRecommendationsProgress_IndexAdvice{
+			Type:         int32(a.Type),
+			TableID:      a.Table.ID,
+			DatabaseName: a.Table.DatabaseName,
+			SchemaName:   a.Table.SchemaName,
+			TableName:    a.Table.Name,
+			IndexName:    a.IndexName,
+			SQL:          a.SQL,
+			Fingerprints: int64(a.Fingerprints),
+			Executions:   a.Executions,
+			TotalLatency: a.TotalLatency,
+			LastRead:     a.LastRead,
+		}
+	}
+	return res
+}
+
+func adviceFromProto(advice []jobspb.AutoIndexRecommendationsProgress_IndexAdvice) []Advice {
+	res := make([]Advice, len(advice))
+	for i := range advice {
+		a := &advice[i]
+		res[i] = Advice{
+			Type: AdviceType(a.Type),
+			Table: Table{
+				ID:           a.TableID,
+				DatabaseName: a.DatabaseName,
+				SchemaName:   a.SchemaName,
+				Name:         a.TableName,
+			},
+			IndexName:    a.IndexName,
+			SQL:          a.SQL,
+			Fingerprints: int(a.Fingerprints),
+			Executions:   a.Executions,
+			TotalLatency: a.TotalLatency,
+			LastRead:     a.LastRead,
+		}
+	}
+	return res
+}
+
+// workloadStatement is a statement fingerprint of the workload, along with
+// its statistics during the lookback period.
+type workloadStatement struct {
+	query        string
+	database     string
+	executions   int64
+	totalLatency time.Duration
+}
+
+// refresh recomputes the index advice.
+func (a *Advisor) refresh(ctx context.Context) ([]Advice, error) {
+	stmts, err := a.topStatements(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	type adviceKey struct {
+		typ AdviceType
+		sql string
+	}
+	adviceByKey := make(map[adviceKey]*Advice)
+	// replaced contains the indexes that are replaced by some advice, keyed by
+	// table and index name. These are not advised to be dropped.
+	type indexKey struct {
+		tableID   descpb.ID
+		indexName string
+	}
+	replaced := make(map[indexKey]struct{})
+	for _, stmt := range stmts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		recs, err := a.recommender.RecommendIndexes(ctx, stmt.query, stmt.database)
+		if err != nil {
+			// Fingerprints may not be plannable anymore, for example when they
+			// refer to tables which were dropped since. They are skipped.
+			log.VEventf(ctx, 2, "cannot recommend indexes for %q: %v", stmt.query, err)
+			continue
+		}
+		for i := range recs {
+			rec := &recs[i]
+			typ := CreateIndex
+			if rec.Type == indexrec.TypeReplaceIndex {
+				typ = ReplaceIndex
+				replaced[indexKey{tableID: rec.Table.ID, indexName: rec.IndexName}] = struct{}{}
+			}
+			key := adviceKey{typ: typ, sql: rec.SQL}
+			adv, ok := adviceByKey[key]
+			if !ok {
+				adv = &Advice{
+					Type:      typ,
+					Table:     rec.Table,
+					IndexName: rec.IndexName,
+					SQL:       rec.SQL,
+				}
+				adviceByKey[key] = adv
+			}
+			adv.Fingerprints++
+			adv.Executions += stmt.executions
+			adv.TotalLatency += stmt.totalLatency
+		}
+	}
+
+	advice := make([]Advice, 0, len(adviceByKey))
+	for _, adv := range adviceByKey {
+		advice = append(advice, *adv)
+	}
+	sort.Slice(advice, func(i, j int) bool {
+		if advice[i].TotalLatency != advice[j].TotalLatency {
+			return advice[i].TotalLatency > advice[j].TotalLatency
+		}
+		return advice[i].SQL < advice[j].SQL
+	})
+
+	// Only advise to drop unused indexes once the index usage statistics have
+	// been collected for long enough on this node.
+	unusedSince := timeutil.Now().Add(-unusedIndexThreshold.Get(&a.st.SV))
+	if !a.started.IsZero() && a.started.Before(unusedSince) {
+		unused, err := a.recommender.UnusedIndexes(ctx, unusedSince)
+		if err != nil {
+			return nil, err
+		}
+		for _, idx := range unused {
+			if _, ok := replaced[indexKey{tableID: idx.Table.ID, indexName: idx.IndexName}]; ok {
+				continue
+			}
+			advice = append(advice, Advice{
+				Type:      DropIndex,
+				Table:     idx.Table,
+				IndexName: idx.IndexName,
+				SQL:       idx.SQL,
+				LastRead:  idx.LastRead,
+			})
+		}
+	}
+	return advice, nil
+}
+
+// topStatements returns the DML statement fingerprints of user applications
+// with the highest total service latency during the lookback period.
+func (a *Advisor) topStatements(ctx context.Context) (_ []workloadStatement, retErr error) {
+	it, err := a.ie.QueryIteratorEx(
+		ctx, "index-advisor-top-statements", nil, /* txn */
+		sessiondata.InternalExecutorOverride{User: security.NodeUserName()},
+		`SELECT query, database, executions, total_latency
+   FROM (
+         SELECT metadata->>'query' AS query,
+                metadata->>'db' AS database,
+                sum((statistics->'statistics'->>'cnt')::INT8)::INT8 AS executions,
+                sum(
+                  (statistics->'statistics'->>'cnt')::FLOAT8 *
+                  (statistics->'statistics'->'svcLat'->>'mean')::FLOAT8
+                ) AS total_latency
+           FROM system.statement_statistics
+          WHERE aggregated_ts >= $1
+            AND metadata->>'stmtTyp' = $2
+            AND app_name NOT LIKE $3
+       GROUP BY query, database
+        )
+ORDER BY total_latency DESC
+   LIMIT $4`,
+		timeutil.Now().Add(-lookback.Get(&a.st.SV)),
+		tree.TypeDML.String(),
+		catconstants.InternalAppNamePrefix+"%",
+		topStatements.Get(&a.st.SV),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { retErr = errors.CombineErrors(retErr, it.Close()) }()
+
+	var stmts []workloadStatement
+	var ok bool
+	for ok, err = it.Next(ctx); ok; ok, err = it.Next(ctx) {
+		row := it.Cur()
+		if row[0] == tree.DNull || row[1] == tree.DNull {
+			continue
+		}
+		stmt := workloadStatement{
+			query:    string(tree.MustBeDString(row[0])),
+			database: string(tree.MustBeDString(row[1])),
+		}
+		if row[2] != tree.DNull {
+			stmt.executions = int64(tree.MustBeDInt(row[2]))
+		}
+		if row[3] != tree.DNull {
+			stmt.totalLatency = time.Duration(float64(tree.MustBeDFloat(row[3])) * float64(time.Second))
+		}
+		stmts = append(stmts, stmt)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return stmts, nil
+}
@@ -8,14 +8,13 @@
 // by the Apache License, Version 2.0, included in the file
 // licenses/APL.txt.
 
-package rowexec
+package inverted
 
 import (
 	"bytes"
 	"sort"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
-	"github.com/cockroachdb/cockroach/pkg/sql/inverted"
 	"github.com/cockroachdb/errors"
 )
 
@@ -24,7 +23,7 @@ import (
 // of an inverted index, which consists of an inverted column followed by the
 // primary key of the table. The set expressions involve union and
 // intersection over operands. The operands are sets of primary keys contained
-// in the corresponding span. Callers should use batchedInvertedExprEvaluator.
+// in the corresponding span. Callers should use BatchedExprEvaluator.
 // This evaluator does not do the actual scan -- it is fed the set elements as
 // the inverted index is scanned, and routes a set element to all the sets to
 // which it belongs (since spans can be overlapping). Once the scan is
@@ -101,16 +100,16 @@ func intersectSetContainers(a, b setContainer) setContainer {
 
 // setExpression follows the structure of SpanExpression.
 type setExpression struct {
-	op inverted.SetOperator
+	op SetOperator
 	// The index in invertedExprEvaluator.sets
 	unionSetIndex int
 	left          *setExpression
 	right         *setExpression
 }
 
-type invertedSpan = inverted.SpanExpressionProto_Span
-type invertedSpans = inverted.SpanExpressionProtoSpans
-type spanExpression = inverted.SpanExpressionProto_Node
+type invertedSpan = SpanExpressionProto_Span
+type invertedSpans = SpanExpressionProtoSpans
+type spanExpression = SpanExpressionProto_Node
 
 // The spans in a SpanExpression.FactoredUnionSpans and the corresponding index
 // in invertedExprEvaluator.sets. Only populated when FactoredUnionsSpans is
@@ -121,7 +120,7 @@ type spansAndSetIndex struct {
 }
 
 // invertedExprEvaluator evaluates a single expression. It should not be directly
-// used -- see batchedInvertedExprEvaluator.
+// used -- see BatchedExprEvaluator.
 type invertedExprEvaluator struct {
 	setExpr *setExpression
 	// These are initially populated by calls to addIndexRow() as
@@ -205,9 +204,9 @@ func (ev *invertedExprEvaluator) evaluateSetExpr(sx *setExpression) setContainer
 	}
 	var childrenSet setContainer
 	switch sx.op {
-	case inverted.SetUnion:
+	case SetUnion:
 		childrenSet = unionSetContainers(left, right)
-	case inverted.SetIntersection:
+	case SetIntersection:
 		childrenSet = intersectSetContainers(left, right)
 	}
 	return unionSetContainers(ev.sets[sx.unionSetIndex], childrenSet)
@@ -215,9 +214,9 @@ func (ev *invertedExprEvaluator) evaluateSetExpr(sx *setExpression) setContainer
 
 // Supporting struct for invertedSpanRoutingInfo.
 type exprAndSetIndex struct {
-	// An index into batchedInvertedExprEvaluator.exprEvals.
+	// An index into BatchedExprEvaluator.exprEvals.
 	exprIndex int
-	// An index into batchedInvertedExprEvaluator.exprEvals[exprIndex].sets.
+	// An index into BatchedExprEvaluator.exprEvals[exprIndex].sets.
 	setIndex int
 }
 
@@ -257,38 +256,38 @@ func (s invertedSpanRoutingInfosByEndKey) Less(i, j int) bool {
 	return bytes.Compare(s[i].span.End, s[j].span.End) < 0
 }
 
-// preFilterer is the single method from DatumsToInvertedExpr that is relevant here.
-type preFilterer interface {
-	PreFilter(enc inverted.EncVal, preFilters []interface{}, result []bool) (bool, error)
+// PreFilterer is the single method from DatumsToInvertedExpr that is relevant here.
+type PreFilterer interface {
+	PreFilter(enc EncVal, preFilters []interface{}, result []bool) (bool, error)
 }
 
-// batchedInvertedExprEvaluator is for evaluating one or more expressions. The
-// batched evaluator can be reused by calling reset(). In the build phase,
-// append expressions directly to exprs. A nil expression is permitted, and is
-// just a placeholder that will result in a nil []KeyIndex in evaluate().
-// init() must be called before calls to {prepare}addIndexRow() -- it builds the
+// BatchedExprEvaluator is for evaluating one or more expressions. The
+// batched evaluator can be reused by calling Reset(). In the build phase,
+// append expressions directly to Exprs. A nil expression is permitted, and is
+// just a placeholder that will result in a nil []KeyIndex in Evaluate().
+// Init() must be called before calls to {Prepare}AddIndexRow() -- it builds the
 // fragmentedSpans used for routing the added rows.
-type batchedInvertedExprEvaluator struct {
-	filterer preFilterer
-	exprs    []*inverted.SpanExpressionProto
+type BatchedExprEvaluator struct {
+	Filterer PreFilterer
+	Exprs    []*SpanExpressionProto
 
 	// The pre-filtering state for each expression. When pre-filtering, this
-	// is the same length as exprs.
-	preFilterState []interface{}
+	// is the same length as Exprs.
+	PreFilterState []interface{}
 	// The parameters and result of pre-filtering for an inverted row are
 	// kept in this temporary state.
 	tempPreFilters      []interface{}
 	tempPreFilterResult []bool
 
-	// The evaluators for all the exprs.
+	// The evaluators for all the Exprs.
 	exprEvals []*invertedExprEvaluator
 	// The keys that constrain the non-inverted prefix columns, if the index is
 	// a multi-column inverted index. For multi-column inverted indexes, these
 	// keys are in one-to-one correspondence with exprEvals.
-	nonInvertedPrefixes []roachpb.Key
+	NonInvertedPrefixes []roachpb.Key
 	// Spans here are in sorted order and non-overlapping.
 	fragmentedSpans []invertedSpanRoutingInfo
-	// The routing index computed by prepareAddIndexRow.
+	// The routing index computed by PrepareAddIndexRow.
 	routingIndex int
 
 	// Temporary state used during initialization.
@@ -328,8 +327,8 @@ type batchedInvertedExprEvaluator struct {
 //    c-e-f            f-g
 //    c-e-f            f-i
 //    c-e
-func (b *batchedInvertedExprEvaluator) fragmentPendingSpans(
-	pendingSpans []invertedSpanRoutingInfo, fragmentUntil inverted.EncVal,
+func (b *BatchedExprEvaluator) fragmentPendingSpans(
+	pendingSpans []invertedSpanRoutingInfo, fragmentUntil EncVal,
 ) []invertedSpanRoutingInfo {
 	// The start keys are the same, so this only sorts in increasing order of
 	// end keys. Assign slice to a field on the receiver before sorting to avoid
@@ -344,9 +343,9 @@ func (b *batchedInvertedExprEvaluator) fragmentPendingSpans(
 		// the next fragment is constructed.
 		var removeSize int
 		// The end of the next fragment.
-		var end inverted.EncVal
+		var end EncVal
 		// The start of the fragment after the next fragment.
-		var nextStart inverted.EncVal
+		var nextStart EncVal
 		if fragmentUntil != nil && bytes.Compare(fragmentUntil, pendingSpans[0].span.End) < 0 {
 			// Can't completely remove any spans from pendingSpans, but a prefix
 			// of these spans will be removed
@@ -398,7 +397,7 @@ func (b *batchedInvertedExprEvaluator) fragmentPendingSpans(
 	return pendingSpans
 }
 
-func (b *batchedInvertedExprEvaluator) pendingLenWithSameEnd(
+func (b *BatchedExprEvaluator) pendingLenWithSameEnd(
 	pendingSpans []invertedSpanRoutingInfo,
 ) int {
 	length := 1
@@ -411,27 +410,27 @@ func (b *batchedInvertedExprEvaluator) pendingLenWithSameEnd(
 	return length
 }
 
-// init fragments the spans for later routing of rows and returns spans
+// Init fragments the spans for later routing of rows and returns spans
 // representing a union of all the spans (for executing the scan). The
-// returned slice is only valid until the next call to reset.
-func (b *batchedInvertedExprEvaluator) init() (invertedSpans, error) {
-	if len(b.nonInvertedPrefixes) > 0 && len(b.nonInvertedPrefixes) != len(b.exprs) {
+// returned slice is only valid until the next call to Reset.
+func (b *BatchedExprEvaluator) Init() (SpanExpressionProtoSpans, error) {
+	if len(b.NonInvertedPrefixes) > 0 && len(b.NonInvertedPrefixes) != len(b.Exprs) {
 		return nil, errors.AssertionFailedf("length of non-empty nonInvertedPrefixes must equal length of exprs")
 	}
-	if cap(b.exprEvals) < len(b.exprs) {
-		b.exprEvals = make([]*invertedExprEvaluator, len(b.exprs))
+	if cap(b.exprEvals) < len(b.Exprs) {
+		b.exprEvals = make([]*invertedExprEvaluator, len(b.Exprs))
 	} else {
-		b.exprEvals = b.exprEvals[:len(b.exprs)]
+		b.exprEvals = b.exprEvals[:len(b.Exprs)]
 	}
 	// Initial spans fetched from all expressions.
-	for i, expr := range b.exprs {
+	for i, expr := range b.Exprs {
 		if expr == nil {
 			b.exprEvals[i] = nil
 			continue
 		}
 		var prefixKey roachpb.Key
-		if len(b.nonInvertedPrefixes) > 0 {
-			prefixKey = b.nonInvertedPrefixes[i]
+		if len(b.NonInvertedPrefixes) > 0 {
+			prefixKey = b.NonInvertedPrefixes[i]
 		}
 		b.exprEvals[i] = newInvertedExprEvaluator(&expr.Node)
 		exprSpans := b.exprEvals[i].getSpansAndSetIndex()
@@ -500,16 +499,14 @@ func (b *batchedInvertedExprEvaluator) init() (invertedSpans, error) {
 	return b.coveringSpans, nil
 }
 
-// prepareAddIndexRow must be called prior to addIndexRow to do any
-// pre-filtering. The return value indicates whether addIndexRow should be
+// PrepareAddIndexRow must be called prior to AddIndexRow to do any
+// pre-filtering. The return value indicates whether AddIndexRow should be
 // called. encFull should include the entire index key, including non-inverted
 // prefix columns. It should be nil if the index is not a multi-column inverted
 // index.
 // TODO(sumeer): if this will be called in non-decreasing order of enc,
 // use that to optimize the binary search.
-func (b *batchedInvertedExprEvaluator) prepareAddIndexRow(
-	enc inverted.EncVal, encFull inverted.EncVal,
-) (bool, error) {
+func (b *BatchedExprEvaluator) PrepareAddIndexRow(enc EncVal, encFull EncVal) (bool, error) {
 	routingEnc := enc
 	if encFull != nil {
 		routingEnc = encFull
@@ -536,11 +533,11 @@ func (b *batchedInvertedExprEvaluator) prepareAddIndexRow(
 	return b.prefilter(enc)
 }
 
-// prefilter applies b.filterer, if it exists, returning true if addIndexRow
+// prefilter applies b.Filterer, if it exists, returning true if AddIndexRow
 // should be called for the row corresponding to the encoded value.
-// prepareAddIndexRow or prepareAddMultiColumnIndexRow must be called first.
-func (b *batchedInvertedExprEvaluator) prefilter(enc inverted.EncVal) (bool, error) {
-	if b.filterer != nil {
+// PrepareAddIndexRow must be called first.
+func (b *BatchedExprEvaluator) prefilter(enc EncVal) (bool, error) {
+	if b.Filterer != nil {
 		exprIndexList := b.fragmentedSpans[b.routingIndex].exprIndexList
 		if len(exprIndexList) > cap(b.tempPreFilters) {
 			b.tempPreFilters = make([]interface{}, len(exprIndexList))
@@ -550,17 +547,17 @@ func (b *batchedInvertedExprEvaluator) prefilter(enc inverted.EncVal) (bool, err
 			b.tempPreFilterResult = b.tempPreFilterResult[:len(exprIndexList)]
 		}
 		for j := range exprIndexList {
-			b.tempPreFilters[j] = b.preFilterState[exprIndexList[j]]
+			b.tempPreFilters[j] = b.PreFilterState[exprIndexList[j]]
 		}
-		return b.filterer.PreFilter(enc, b.tempPreFilters, b.tempPreFilterResult)
+		return b.Filterer.PreFilter(enc, b.tempPreFilters, b.tempPreFilterResult)
 	}
 	return true, nil
 }
 
-// addIndexRow must be called iff prepareAddIndexRow returned true.
-func (b *batchedInvertedExprEvaluator) addIndexRow(keyIndex KeyIndex) error {
+// AddIndexRow must be called iff PrepareAddIndexRow returned true.
+func (b *BatchedExprEvaluator) AddIndexRow(keyIndex KeyIndex) error {
 	i := b.routingIndex
-	if b.filterer != nil {
+	if b.Filterer != nil {
 		exprIndexes := b.fragmentedSpans[i].exprIndexList
 		exprSetIndexes := b.fragmentedSpans[i].exprAndSetIndexList
 		if len(exprIndexes) != len(b.tempPreFilterResult) {
@@ -588,8 +585,10 @@ func (b *batchedInvertedExprEvaluator) addIndexRow(keyIndex KeyIndex) error {
 	return nil
 }
 
-func (b *batchedInvertedExprEvaluator) evaluate() [][]KeyIndex {
-	result := make([][]KeyIndex, len(b.exprs))
+// Evaluate evaluates all the expressions, returning the set of key indexes
+// that satisfy each expression.
+func (b *BatchedExprEvaluator) Evaluate() [][]KeyIndex {
+	result := make([][]KeyIndex, len(b.Exprs))
 	for i := range b.exprEvals {
 		if b.exprEvals[i] == nil {
 			continue
@@ -599,14 +598,16 @@ func (b *batchedInvertedExprEvaluator) evaluate() [][]KeyIndex {
 	return result
 }
 
-func (b *batchedInvertedExprEvaluator) reset() {
-	b.exprs = b.exprs[:0]
-	b.preFilterState = b.preFilterState[:0]
+// Reset resets the evaluator so that it can be reused for a new batch of
+// expressions.
+func (b *BatchedExprEvaluator) Reset() {
+	b.Exprs = b.Exprs[:0]
+	b.PreFilterState = b.PreFilterState[:0]
 	b.exprEvals = b.exprEvals[:0]
 	b.fragmentedSpans = b.fragmentedSpans[:0]
 	b.routingSpans = b.routingSpans[:0]
 	b.coveringSpans = b.coveringSpans[:0]
-	b.nonInvertedPrefixes = b.nonInvertedPrefixes[:0]
+	b.NonInvertedPrefixes = b.NonInvertedPrefixes[:0]
 }
 
 // prefixInvertedSpan returns a new invertedSpan with prefix prepended to the
@@ -8,7 +8,7 @@
 // by the Apache License, Version 2.0, included in the file
 // licenses/APL.txt.
 
-package rowexec
+package inverted
 
 import (
 	"fmt"
@@ -16,7 +16,6 @@ import (
 	"strings"
 	"testing"
 
-	"github.com/cockroachdb/cockroach/pkg/sql/inverted"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/stretchr/testify/require"
 )
@@ -136,37 +135,37 @@ type keyAndIndex struct {
 	index int
 }
 
-// Tests both invertedExprEvaluator and batchedInvertedExprEvaluator.
+// Tests both invertedExprEvaluator and BatchedExprEvaluator.
 func TestInvertedExpressionEvaluator(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
 	leaf1 := &spanExpression{
 		FactoredUnionSpans: []invertedSpan{{Start: []byte("a"), End: []byte("d")}},
-		Operator:           inverted.None,
+		Operator:           None,
 	}
 	leaf2 := &spanExpression{
 		FactoredUnionSpans: []invertedSpan{{Start: []byte("e"), End: []byte("h")}},
-		Operator:           inverted.None,
+		Operator:           None,
 	}
 	l1Andl2 := &spanExpression{
 		FactoredUnionSpans: []invertedSpan{
 			{Start: []byte("i"), End: []byte("j")}, {Start: []byte("k"), End: []byte("n")}},
-		Operator: inverted.SetIntersection,
+		Operator: SetIntersection,
 		Left:     leaf1,
 		Right:    leaf2,
 	}
 	leaf3 := &spanExpression{
 		FactoredUnionSpans: []invertedSpan{{Start: []byte("d"), End: []byte("f")}},
-		Operator:           inverted.None,
+		Operator:           None,
 	}
 	leaf4 := &spanExpression{
 		FactoredUnionSpans: []invertedSpan{{Start: []byte("a"), End: []byte("c")}},
-		Operator:           inverted.None,
+		Operator:           None,
 	}
 	l3Andl4 := &spanExpression{
 		FactoredUnionSpans: []invertedSpan{
 			{Start: []byte("g"), End: []byte("m")}},
-		Operator: inverted.SetIntersection,
+		Operator: SetIntersection,
 		Left:     leaf3,
 		Right:    leaf4,
 	}
@@ -174,13 +173,13 @@ func TestInvertedExpressionEvaluator(t *testing.T) {
 	// up to expr, by the factoring code in the invertedexpr package. But the
 	// evaluator does not care, and keeping them separate exercises more code.
 	exprUnion := &spanExpression{
-		Operator: inverted.SetUnion,
+		Operator: SetUnion,
 		Left:     l1Andl2,
 		Right:    l3Andl4,
 	}
 
 	exprIntersection := &spanExpression{
-		Operator: inverted.SetIntersection,
+		Operator: SetIntersection,
 		Left:     l1Andl2,
 		Right:    l3Andl4,
 	}
@@ -191,7 +190,7 @@ func TestInvertedExpressionEvaluator(t *testing.T) {
 
 	// Test the getSpansAndSetIndex() method on the invertedExprEvaluator
 	// directly. The rest of the methods we will only exercise through
-	// batchedInvertedExprEvaluator.
+	// BatchedExprEvaluator.
 	evalUnion := newInvertedExprEvaluator(exprUnion)
 	// Indexes are being assigned using a pre-order traversal.
 	require.Equal(t, expectedSpansAndSetIndex,
@@ -201,15 +200,15 @@ func TestInvertedExpressionEvaluator(t *testing.T) {
 	require.Equal(t, expectedSpansAndSetIndex,
 		spansIndexToString(evalIntersection.getSpansAndSetIndex()))
 
-	// The batchedInvertedExprEvaluators will construct their own
+	// The BatchedExprEvaluators will construct their own
 	// invertedExprEvaluators.
-	protoUnion := inverted.SpanExpressionProto{Node: *exprUnion}
-	batchEvalUnion := &batchedInvertedExprEvaluator{
-		exprs: []*inverted.SpanExpressionProto{&protoUnion, nil},
+	protoUnion := SpanExpressionProto{Node: *exprUnion}
+	batchEvalUnion := &BatchedExprEvaluator{
+		Exprs: []*SpanExpressionProto{&protoUnion, nil},
 	}
-	protoIntersection := inverted.SpanExpressionProto{Node: *exprIntersection}
-	batchEvalIntersection := &batchedInvertedExprEvaluator{
-		exprs: []*inverted.SpanExpressionProto{&protoIntersection, nil},
+	protoIntersection := SpanExpressionProto{Node: *exprIntersection}
+	batchEvalIntersection := &BatchedExprEvaluator{
+		Exprs: []*SpanExpressionProto{&protoIntersection, nil},
 	}
 	expectedSpans := "[a, n) "
 	expectedFragmentedSpans :=
@@ -225,13 +224,13 @@ func TestInvertedExpressionEvaluator(t *testing.T) {
 			"span: [k, m)  indexes (expr, set): (0, 4) (0, 1) (expr): 0 \n" +
 			"span: [m, n)  indexes (expr, set): (0, 1) (expr): 0 \n"
 
-	invertedSpans, err := batchEvalUnion.init()
+	invertedSpans, err := batchEvalUnion.Init()
 	require.NoError(t, err)
 	require.Equal(t, expectedSpans, spansToString(invertedSpans))
 	require.Equal(t, expectedFragmentedSpans,
 		fragmentedSpansToString(batchEvalUnion.fragmentedSpans))
 
-	invertedSpans, err = batchEvalIntersection.init()
+	invertedSpans, err = batchEvalIntersection.Init()
 	require.NoError(t, err)
 	require.Equal(t, expectedSpans, spansToString(invertedSpans))
 	require.Equal(t, expectedFragmentedSpans,
@@ -257,45 +256,45 @@ func TestInvertedExpressionEvaluator(t *testing.T) {
 		indexRows[i], indexRows[j] = indexRows[j], indexRows[i]
 	})
 	for _, elem := range indexRows {
-		add, err := batchEvalUnion.prepareAddIndexRow(inverted.EncVal(elem.key), nil /* encFull */)
+		add, err := batchEvalUnion.PrepareAddIndexRow(EncVal(elem.key), nil /* encFull */)
 		require.NoError(t, err)
 		require.Equal(t, true, add)
-		err = batchEvalUnion.addIndexRow(elem.index)
+		err = batchEvalUnion.AddIndexRow(elem.index)
 		require.NoError(t, err)
-		add, err = batchEvalIntersection.prepareAddIndexRow(inverted.EncVal(elem.key), nil /* encFull */)
+		add, err = batchEvalIntersection.PrepareAddIndexRow(EncVal(elem.key), nil /* encFull */)
 		require.NoError(t, err)
 		require.Equal(t, true, add)
-		err = batchEvalIntersection.addIndexRow(elem.index)
+		err = batchEvalIntersection.AddIndexRow(elem.index)
 		require.NoError(t, err)
 	}
-	require.Equal(t, expectedUnion, keyIndexesToString(batchEvalUnion.evaluate()))
-	require.Equal(t, expectedIntersection, keyIndexesToString(batchEvalIntersection.evaluate()))
+	require.Equal(t, expectedUnion, keyIndexesToString(batchEvalUnion.Evaluate()))
+	require.Equal(t, expectedIntersection, keyIndexesToString(batchEvalIntersection.Evaluate()))
 
 	// Now do both exprUnion and exprIntersection in a single batch.
 	batchBoth := batchEvalUnion
-	batchBoth.reset()
-	batchBoth.exprs = append(batchBoth.exprs, &protoUnion, &protoIntersection)
-	_, err = batchBoth.init()
+	batchBoth.Reset()
+	batchBoth.Exprs = append(batchBoth.Exprs, &protoUnion, &protoIntersection)
+	_, err = batchBoth.Init()
 	if err != nil {
 		t.Fatal(err)
 	}
 	for _, elem := range indexRows {
-		add, err := batchBoth.prepareAddIndexRow(inverted.EncVal(elem.key), nil /* encFull */)
+		add, err := batchBoth.PrepareAddIndexRow(EncVal(elem.key), nil /* encFull */)
 		require.NoError(t, err)
 		require.Equal(t, true, add)
-		err = batchBoth.addIndexRow(elem.index)
+		err = batchBoth.AddIndexRow(elem.index)
 		require.NoError(t, err)
 	}
 	require.Equal(t, "0: 0 3 4 5 6 7 8 \n1: 0 4 6 8 \n",
-		keyIndexesToString(batchBoth.evaluate()))
+		keyIndexesToString(batchBoth.Evaluate()))
 
 	// Reset and evaluate nil expressions.
-	batchBoth.reset()
-	batchBoth.exprs = append(batchBoth.exprs, nil, nil)
-	invertedSpans, err = batchBoth.init()
+	batchBoth.Reset()
+	batchBoth.Exprs = append(batchBoth.Exprs, nil, nil)
+	invertedSpans, err = batchBoth.Init()
 	require.NoError(t, err)
 	require.Equal(t, 0, len(invertedSpans))
-	require.Equal(t, "0: \n1: \n", keyIndexesToString(batchBoth.evaluate()))
+	require.Equal(t, "0: \n1: \n", keyIndexesToString(batchBoth.Evaluate()))
 }
 
 // Test fragmentation for routing when multiple expressions in the batch have
@@ -303,30 +302,30 @@ func TestInvertedExpressionEvaluator(t *testing.T) {
 func TestFragmentedSpans(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
-	expr1 := inverted.SpanExpressionProto{
+	expr1 := SpanExpressionProto{
 		Node: spanExpression{
 			FactoredUnionSpans: []invertedSpan{{Start: []byte("a"), End: []byte("g")}},
-			Operator:           inverted.None,
+			Operator:           None,
 		},
 	}
-	expr2 := inverted.SpanExpressionProto{
+	expr2 := SpanExpressionProto{
 		Node: spanExpression{
 			FactoredUnionSpans: []invertedSpan{{Start: []byte("d"), End: []byte("j")}},
-			Operator:           inverted.None,
+			Operator:           None,
 		},
 	}
-	expr3 := inverted.SpanExpressionProto{
+	expr3 := SpanExpressionProto{
 		Node: spanExpression{
 			FactoredUnionSpans: []invertedSpan{
 				{Start: []byte("e"), End: []byte("f")}, {Start: []byte("i"), End: []byte("l")},
 				{Start: []byte("o"), End: []byte("p")}},
-			Operator: inverted.None,
+			Operator: None,
 		},
 	}
-	batchEval := &batchedInvertedExprEvaluator{
-		exprs: []*inverted.SpanExpressionProto{&expr1, &expr2, &expr3},
+	batchEval := &BatchedExprEvaluator{
+		Exprs: []*SpanExpressionProto{&expr1, &expr2, &expr3},
 	}
-	invertedSpans, err := batchEval.init()
+	invertedSpans, err := batchEval.Init()
 	require.NoError(t, err)
 	require.Equal(t, "[a, l) [o, p) ", spansToString(invertedSpans))
 	require.Equal(t,
@@ -348,7 +347,7 @@ type testPreFilterer struct {
 }
 
 func (t *testPreFilterer) PreFilter(
-	enc inverted.EncVal, preFilters []interface{}, result []bool,
+	enc EncVal, preFilters []interface{}, result []bool,
 ) (bool, error) {
 	require.Equal(t.t, t.expectedPreFilters, preFilters)
 	rv := false
@@ -366,38 +365,38 @@ func TestInvertedExpressionEvaluatorPreFilter(t *testing.T) {
 	// in a span.
 	leaf1 := &spanExpression{
 		FactoredUnionSpans: []invertedSpan{{Start: []byte("a"), End: []byte("d")}},
-		Operator:           inverted.None,
+		Operator:           None,
 	}
 	leaf2 := &spanExpression{
 		FactoredUnionSpans: []invertedSpan{{Start: []byte("e"), End: []byte("h")}},
-		Operator:           inverted.None,
+		Operator:           None,
 	}
 	expr1 := &spanExpression{
-		Operator: inverted.SetIntersection,
+		Operator: SetIntersection,
 		Left: &spanExpression{
-			Operator: inverted.SetIntersection,
+			Operator: SetIntersection,
 			Left:     leaf1,
 			Right:    leaf2,
 		},
 		Right: leaf1,
 	}
-	expr1Proto := inverted.SpanExpressionProto{Node: *expr1}
+	expr1Proto := SpanExpressionProto{Node: *expr1}
 	expr2 := &spanExpression{
-		Operator: inverted.SetIntersection,
+		Operator: SetIntersection,
 		Left: &spanExpression{
-			Operator: inverted.SetIntersection,
+			Operator: SetIntersection,
 			Left:     leaf2,
 			Right:    leaf1,
 		},
 		Right: leaf2,
 	}
-	expr2Proto := inverted.SpanExpressionProto{Node: *expr2}
+	expr2Proto := SpanExpressionProto{Node: *expr2}
 	preFilters := []interface{}{"pf1", "pf2"}
-	batchEval := &batchedInvertedExprEvaluator{
-		exprs:          []*inverted.SpanExpressionProto{&expr1Proto, &expr2Proto},
-		preFilterState: preFilters,
+	batchEval := &BatchedExprEvaluator{
+		Exprs:          []*SpanExpressionProto{&expr1Proto, &expr2Proto},
+		PreFilterState: preFilters,
 	}
-	invertedSpans, err := batchEval.init()
+	invertedSpans, err := batchEval.Init()
 	require.NoError(t, err)
 	require.Equal(t, "[a, d) [e, h) ", spansToString(invertedSpans))
 	require.Equal(t,
@@ -406,11 +405,11 @@ func TestInvertedExpressionEvaluatorPreFilter(t *testing.T) {
 		fragmentedSpansToString(batchEval.fragmentedSpans))
 	feedIndexRows := func(indexRows []keyAndIndex, expectedAdd bool) {
 		for _, elem := range indexRows {
-			add, err := batchEval.prepareAddIndexRow(inverted.EncVal(elem.key), nil /* encFull */)
+			add, err := batchEval.PrepareAddIndexRow(EncVal(elem.key), nil /* encFull */)
 			require.NoError(t, err)
 			require.Equal(t, expectedAdd, add)
 			if add {
-				err = batchEval.addIndexRow(elem.index)
+				err = batchEval.AddIndexRow(elem.index)
 			}
 			require.NoError(t, err)
 		}
@@ -420,7 +419,7 @@ func TestInvertedExpressionEvaluatorPreFilter(t *testing.T) {
 		t:                  t,
 		expectedPreFilters: preFilters,
 	}
-	batchEval.filterer = &filterer
+	batchEval.Filterer = &filterer
 	// Neither row is pre-filtered, so 0 will appear in output.
 	filterer.result = []bool{true, true}
 	feedIndexRows([]keyAndIndex{{"a", 0}, {"e", 0}}, true)
@@ -445,7 +444,7 @@ func TestInvertedExpressionEvaluatorPreFilter(t *testing.T) {
 	filterer.result = []bool{false, false}
 	feedIndexRows([]keyAndIndex{{"a", 3}, {"e", 3}}, false)
 
-	require.Equal(t, "0: 0 1 \n1: 0 2 \n", keyIndexesToString(batchEval.evaluate()))
+	require.Equal(t, "0: 0 1 \n1: 0 2 \n", keyIndexesToString(batchEval.Evaluate()))
 }
 
 // TODO(sumeer): randomized inputs for union, intersection and expression evaluation.
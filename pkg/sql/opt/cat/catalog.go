@@ -126,6 +126,17 @@ type Catalog interface {
 		ctx context.Context, name *tree.UnresolvedObjectName,
 	) (*types.T, error)
 
+	// ResolveFunction locates the user-defined function with the given name.
+	// If the name is not qualified by a schema, the schemas of the search path
+	// are tried in order. The returned definition contains all the overloads
+	// of the function in the first schema where it was found. If no such
+	// function exists, then ResolveFunction returns nil.
+	//
+	// Builtin functions are not resolved by ResolveFunction.
+	ResolveFunction(
+		ctx context.Context, name *tree.UnresolvedName,
+	) (*tree.FunctionDefinition, error)
+
 	// CheckPrivilege verifies that the current user has the given privilege on
 	// the given catalog object. If not, then CheckPrivilege returns an error.
 	CheckPrivilege(ctx context.Context, o Object, priv privilege.Kind) error
@@ -134,6 +145,11 @@ type Catalog interface {
 	// the given catalog object. If not, then CheckAnyPrivilege returns an error.
 	CheckAnyPrivilege(ctx context.Context, o Object) error
 
+	// CheckFunctionPrivilege verifies that the current user has the given
+	// privilege on the user-defined function with the given descriptor ID. If
+	// not, then CheckFunctionPrivilege returns an error.
+	CheckFunctionPrivilege(ctx context.Context, id StableID, priv privilege.Kind) error
+
 	// HasAdminRole checks that the current user has admin privileges. If yes,
 	// returns true. Returns an error if query on the `system.users` table failed
 	HasAdminRole(ctx context.Context) (bool, error)
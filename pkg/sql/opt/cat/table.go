@@ -134,6 +134,15 @@ type Table interface {
 	// i < UniqueCount.
 	Unique(i UniqueOrdinal) UniqueConstraint
 
+	// TriggerCount returns the number of row-level triggers defined on this
+	// table.
+	TriggerCount() int
+
+	// Trigger returns the ith trigger defined on this table, where
+	// i < TriggerCount. Triggers are ordered by name, which is the order in
+	// which triggers with the same action time fire.
+	Trigger(i int) Trigger
+
 	// Zone returns a table's zone.
 	Zone() Zone
 }
@@ -150,6 +159,32 @@ type CheckConstraint struct {
 	Validated  bool
 }
 
+// Trigger is a row-level trigger on a table, which executes a trigger function
+// for each row that is inserted, updated or deleted by a statement. For
+// example, this trigger executes the function check_row before each row is
+// inserted into table a:
+//
+//   CREATE TRIGGER tr BEFORE INSERT ON a FOR EACH ROW EXECUTE FUNCTION check_row()
+//
+type Trigger struct {
+	Name       string
+	ActionTime tree.TriggerActionTime
+	Events     []tree.TriggerEvent
+	// FunctionName is the name of the trigger function, which is defined in
+	// the schema of the table.
+	FunctionName string
+}
+
+// HasEvent returns whether the trigger fires for the given event.
+func (t *Trigger) HasEvent(event tree.TriggerEvent) bool {
+	for _, e := range t.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
 // TableStatistic is an interface to a table statistic. Each statistic is
 // associated with a set of columns.
 type TableStatistic interface {
@@ -249,6 +284,17 @@ type ForeignKeyConstraint interface {
 	// UpdateReferenceAction returns the action to be performed if the foreign key
 	// constraint would be violated by an update.
 	UpdateReferenceAction() tree.ReferenceAction
+
+	// Deferrable is true if checking of the constraint can be deferred until
+	// the end of the transaction (see SET CONSTRAINTS). The existing data is
+	// not guaranteed to satisfy a deferrable constraint while a transaction
+	// that modified it is in progress.
+	Deferrable() bool
+
+	// InitiallyDeferred is true if checking of the constraint is deferred until
+	// the end of the transaction unless the transaction requested otherwise with
+	// SET CONSTRAINTS. It implies Deferrable.
+	InitiallyDeferred() bool
 }
 
 // UniqueConstraint represents a uniqueness constraint. UniqueConstraints may
@@ -287,6 +333,15 @@ type UniqueConstraint interface {
 	// cannot make any assumptions about the data. An unvalidated constraint still
 	// needs to be enforced on new mutations.
 	Validated() bool
+
+	// Deferrable is true if checking of the constraint can be deferred until
+	// the end of the transaction. Only constraints without an index can be
+	// deferrable. See ForeignKeyConstraint.Deferrable.
+	Deferrable() bool
+
+	// InitiallyDeferred is true if checking of the constraint is deferred by
+	// default. See ForeignKeyConstraint.InitiallyDeferred.
+	InitiallyDeferred() bool
 }
 
 // UniqueOrdinal identifies a unique constraint (in the context of a Table).
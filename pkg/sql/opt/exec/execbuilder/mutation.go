@@ -123,6 +123,10 @@ func (b *Builder) buildInsert(ins *memo.InsertExpr) (execPlan, error) {
 		return execPlan{}, err
 	}
 
+	if err := b.buildFKCascades(ins.WithID, ins.FKCascades); err != nil {
+		return execPlan{}, err
+	}
+
 	return ep, nil
 }
 
@@ -156,6 +160,12 @@ func (b *Builder) tryBuildFastPathInsert(ins *memo.InsertExpr) (_ execPlan, ok b
 		return execPlan{}, false, nil
 	}
 
+	// We cannot use the fast path if there are cascades (the queries of AFTER
+	// triggers), since they read the buffered input.
+	if len(ins.FKCascades) > 0 {
+		return execPlan{}, false, nil
+	}
+
 	md := b.mem.Metadata()
 	tab := md.Table(ins.Table)
 
@@ -169,6 +179,10 @@ func (b *Builder) tryBuildFastPathInsert(ins *memo.InsertExpr) (_ execPlan, ok b
 			return execPlan{}, false, nil
 		}
 		fk := tab.OutboundForeignKey(c.FKOrdinal)
+		if b.deferCheck(fk.Deferrable(), fk.InitiallyDeferred()) {
+			// The check must be queued until the end of the transaction.
+			return execPlan{}, false, nil
+		}
 		lookupJoin, isLookupJoin := c.Check.(*memo.LookupJoinExpr)
 		if !isLookupJoin || lookupJoin.JoinType != opt.AntiJoinOp {
 			// Not a lookup anti-join.
@@ -682,6 +696,10 @@ func mutationOutputColMap(mutation memo.RelExpr) opt.ColMap {
 // The checks consist of queries that will only return rows if a constraint is
 // violated. Those queries are each wrapped in an ErrorIfRows operator, which
 // will throw an appropriate error in case the inner query returns any rows.
+//
+// Checks of deferrable constraints which are deferred in the current
+// transaction are instead wrapped in DeferredCheck operators, which queue the
+// keys of the violating rows to be checked again when the transaction commits.
 func (b *Builder) buildUniqueChecks(checks memo.UniqueChecksExpr) error {
 	md := b.mem.Metadata()
 	for i := range checks {
@@ -691,6 +709,38 @@ func (b *Builder) buildUniqueChecks(checks memo.UniqueChecksExpr) error {
 		if err != nil {
 			return err
 		}
+		tab := md.Table(c.Table)
+		uc := tab.Unique(c.CheckOrdinal)
+		if b.deferCheck(uc.Deferrable(), uc.InitiallyDeferred()) {
+			// The key columns are in the order of the table columns (see
+			// mutationBuilder.buildUniqueChecks).
+			var ords util.FastIntSet
+			for j, n := 0, uc.ColumnCount(); j < n; j++ {
+				ords.Add(uc.ColumnOrdinal(tab, j))
+			}
+			check := &exec.DeferredCheck{
+				Kind:       exec.DeferredUniqueCheck,
+				Table:      tab,
+				Constraint: uc.Name(),
+				Columns:    make([]exec.TableColumnOrdinal, 0, ords.Len()),
+				KeyCols:    make([]exec.NodeColumnOrdinal, len(c.KeyCols)),
+			}
+			ords.ForEach(func(ord int) {
+				check.Columns = append(check.Columns, exec.TableColumnOrdinal(ord))
+			})
+			if pred, isPartial := uc.Predicate(); isPartial {
+				check.Predicate = pred
+			}
+			for j, col := range c.KeyCols {
+				check.KeyCols[j] = query.getNodeColumnOrdinal(col)
+			}
+			node, err := b.factory.ConstructDeferredCheck(query.root, check)
+			if err != nil {
+				return err
+			}
+			b.checks = append(b.checks, node)
+			continue
+		}
 		// Wrap the query in an error node.
 		mkErr := func(row tree.Datums) error {
 			keyVals := make(tree.Datums, len(c.KeyCols))
@@ -717,6 +767,44 @@ func (b *Builder) buildFKChecks(checks memo.FKChecksExpr) error {
 		if err != nil {
 			return err
 		}
+		origin := md.Table(c.OriginTable)
+		referenced := md.Table(c.ReferencedTable)
+		var fk cat.ForeignKeyConstraint
+		kind := exec.DeferredFKOutboundCheck
+		if c.FKOutbound {
+			fk = origin.OutboundForeignKey(c.FKOrdinal)
+		} else {
+			fk = referenced.InboundForeignKey(c.FKOrdinal)
+			kind = exec.DeferredFKInboundCheck
+		}
+		if b.deferCheck(fk.Deferrable(), fk.InitiallyDeferred()) {
+			// Deferred checks are always queued against the origin table, where
+			// the constraint is defined. The key columns are in the order of the
+			// FK columns, for both outbound and inbound checks.
+			numCols := fk.ColumnCount()
+			check := &exec.DeferredCheck{
+				Kind:              kind,
+				Table:             origin,
+				Constraint:        fk.Name(),
+				Columns:           make([]exec.TableColumnOrdinal, numCols),
+				ReferencedTable:   referenced,
+				ReferencedColumns: make([]exec.TableColumnOrdinal, numCols),
+				KeyCols:           make([]exec.NodeColumnOrdinal, len(c.KeyCols)),
+			}
+			for j := 0; j < numCols; j++ {
+				check.Columns[j] = exec.TableColumnOrdinal(fk.OriginColumnOrdinal(origin, j))
+				check.ReferencedColumns[j] = exec.TableColumnOrdinal(fk.ReferencedColumnOrdinal(referenced, j))
+			}
+			for j, col := range c.KeyCols {
+				check.KeyCols[j] = query.getNodeColumnOrdinal(col)
+			}
+			node, err := b.factory.ConstructDeferredCheck(query.root, check)
+			if err != nil {
+				return err
+			}
+			b.checks = append(b.checks, node)
+			continue
+		}
 		// Wrap the query in an error node.
 		mkErr := func(row tree.Datums) error {
 			keyVals := make(tree.Datums, len(c.KeyCols))
@@ -734,6 +822,24 @@ func (b *Builder) buildFKChecks(checks memo.FKChecksExpr) error {
 	return nil
 }
 
+// deferCheck returns true if the check of a constraint with the given
+// properties should be deferred until the end of the transaction. Checks are
+// never deferred in implicit transactions, since the end of the transaction is
+// the end of the statement anyway.
+func (b *Builder) deferCheck(deferrable, initiallyDeferred bool) bool {
+	if !deferrable || b.evalCtx == nil || b.evalCtx.TxnImplicit {
+		return false
+	}
+	switch b.evalCtx.TxnConstraintsMode {
+	case tree.ConstraintsDeferred:
+		return true
+	case tree.ConstraintsImmediate:
+		return false
+	default:
+		return initiallyDeferred
+	}
+}
+
 // mkUniqueCheckErr generates a user-friendly error describing a uniqueness
 // violation. The keyVals are the values that correspond to the
 // cat.UniqueConstraint columns.
@@ -286,6 +286,7 @@ var nodeNames = [...]string{
 	createTableOp:          "create table",
 	createTableAsOp:        "create table as",
 	createViewOp:           "create view",
+	deferredCheckOp:        "deferred check",
 	deleteOp:               "delete",
 	deleteRangeOp:          "delete range",
 	distinctOp:             "distinct",
@@ -845,6 +846,14 @@ func (e *emitter) emitNodeAttributes(n *Node) error {
 			ob.Attrf("deduplicate", "")
 		}
 
+	case deferredCheckOp:
+		a := n.args.(*deferredCheckArgs)
+		// The check is not available when the plan was decoded from a gist.
+		if a.Check != nil {
+			ob.Attrf("table", "%s", a.Check.Table.Name())
+			ob.Attr("constraint", a.Check.Constraint)
+		}
+
 	case alterRangeRelocateOp:
 		a := n.args.(*alterRangeRelocateArgs)
 		ob.Attr("replicas", a.subjectReplicas)
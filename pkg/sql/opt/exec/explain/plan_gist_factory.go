@@ -195,7 +195,7 @@ func DecodePlanGistToPlan(s string, cat cat.Catalog) (plan *Plan, retErr error)
 			break
 		}
 		switch op {
-		case errorIfRowsOp:
+		case errorIfRowsOp, deferredCheckOp:
 			plan.Checks = append(plan.Checks, f.popChild())
 		}
 	}
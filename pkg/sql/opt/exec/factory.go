@@ -268,6 +268,54 @@ type InsertFastPathFKCheck struct {
 // relevant row.
 type MkErrFn func(tree.Datums) error
 
+// DeferredCheckKind identifies the constraint enforced by a DeferredCheck
+// operator.
+type DeferredCheckKind uint8
+
+const (
+	// DeferredUniqueCheck checks a UNIQUE WITHOUT INDEX constraint.
+	DeferredUniqueCheck DeferredCheckKind = iota
+
+	// DeferredFKOutboundCheck checks that rows inserted or updated in the origin
+	// table of a foreign key have a matching row in the referenced table.
+	DeferredFKOutboundCheck
+
+	// DeferredFKInboundCheck checks that rows removed from the referenced table
+	// of a foreign key don't orphan rows in the origin table.
+	DeferredFKInboundCheck
+)
+
+// DeferredCheck contains information about a constraint check which is
+// deferred until the end of the transaction (see ConstructDeferredCheck).
+type DeferredCheck struct {
+	Kind DeferredCheckKind
+
+	// Table is the table on which the constraint is defined; for foreign keys,
+	// this is the origin table.
+	Table cat.Table
+
+	// Constraint is the name of the constraint, as of the time the statement
+	// was planned. It is only used in error messages; the constraint is
+	// identified by its columns, which are stable across renames.
+	Constraint string
+
+	// Columns are the ordinals of the constraint columns in Table: the unique
+	// columns, or the origin columns of a foreign key. The order matches
+	// KeyCols.
+	Columns []TableColumnOrdinal
+
+	// ReferencedTable and ReferencedColumns identify the referenced side of a
+	// foreign key. They are unset for unique checks.
+	ReferencedTable   cat.Table
+	ReferencedColumns []TableColumnOrdinal
+
+	// Predicate is the predicate of a partial unique constraint, or empty.
+	Predicate string
+
+	// KeyCols are the input columns which contain the key values to be checked.
+	KeyCols []NodeColumnOrdinal
+}
+
 // ExplainFactory is an extension of Factory used when constructing a plan that
 // can be explained. It allows annotation of nodes with extra information.
 type ExplainFactory interface {
@@ -13,7 +13,6 @@ package indexrec
 import (
 	"fmt"
 	"sort"
-	"strings"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
@@ -140,9 +139,9 @@ func (irs *IndexRecommendationSet) addIndexToRecommendationSet(
 	}
 }
 
-// Output returns a string slice of index recommendation output that will be
-// displayed below the statement plan in EXPLAIN.
-func (irs *IndexRecommendationSet) Output() []string {
+// Recommendations returns the index recommendations of the set, ordered by
+// the names of their tables.
+func (irs *IndexRecommendationSet) Recommendations() []Recommendation {
 	indexRecCount := 0
 	for t := range irs.indexRecs {
 		indexRecCount += len(irs.indexRecs[t])
@@ -151,10 +150,6 @@ func (irs *IndexRecommendationSet) Output() []string {
 		return nil
 	}
 
-	outputRowCount := 2*len(irs.indexRecs) + 1
-	output := make([]string, 0, outputRowCount)
-	output = append(output, fmt.Sprintf("index recommendations: %d", indexRecCount))
-
 	sortedTables := make([]cat.Table, 0, len(irs.indexRecs))
 	for t := range irs.indexRecs {
 		sortedTables = append(sortedTables, t)
@@ -163,22 +158,82 @@ func (irs *IndexRecommendationSet) Output() []string {
 		return sortedTables[i].Name() < sortedTables[j].Name()
 	})
 
-	indexRecOrd := 1
+	recs := make([]Recommendation, 0, indexRecCount)
 	for _, t := range sortedTables {
-		indexes := irs.indexRecs[t]
-		for _, indexRec := range indexes {
-			recTypeStr := "index creation"
-			if indexRec.existingIndex != nil {
-				recTypeStr = "index replacement"
-			}
-			indexCols := indexRec.indexCols()
-			storing := indexRec.storingColumns()
+		for _, indexRec := range irs.indexRecs[t] {
+			recs = append(recs, indexRec.recommendation())
+		}
+	}
+	return recs
+}
 
-			indexRecType := fmt.Sprintf("%d. type: %s", indexRecOrd, recTypeStr)
-			indexRecSQL := indexRec.indexRecommendationString(indexCols, storing)
-			output = append(output, indexRecType, indexRecSQL)
-			indexRecOrd++
+// Output returns a string slice of index recommendation output that will be
+// displayed below the statement plan in EXPLAIN.
+func (irs *IndexRecommendationSet) Output() []string {
+	return FormatRecommendations(irs.Recommendations())
+}
+
+// RecommendationType is the type of an index recommendation.
+type RecommendationType int
+
+const (
+	// TypeCreateIndex is the type of recommendations to create a new index.
+	TypeCreateIndex RecommendationType = iota
+	// TypeReplaceIndex is the type of recommendations to replace an existing
+	// index with one that stores more columns.
+	TypeReplaceIndex
+)
+
+// String implements the fmt.Stringer interface.
+func (t RecommendationType) String() string {
+	switch t {
+	case TypeCreateIndex:
+		return "index creation"
+	case TypeReplaceIndex:
+		return "index replacement"
+	default:
+		return fmt.Sprintf("RecommendationType(%d)", int(t))
+	}
+}
+
+// Recommendation is a single index recommendation, along with the SQL
+// commands needed to follow it.
+type Recommendation struct {
+	Type RecommendationType
+	// TableID is the ID of the table the index is recommended for.
+	TableID cat.StableID
+	// Create is the CREATE INDEX statement of the recommended index.
+	Create tree.CreateIndex
+	// Drop is the DROP INDEX statement of the existing index replaced by the
+	// recommended index. It is only set for TypeReplaceIndex.
+	Drop *tree.DropIndex
+}
+
+// SQL returns the SQL command(s) needed to follow the recommendation.
+func (r *Recommendation) SQL() string {
+	if r.Drop == nil {
+		return r.Create.String() + ";"
+	}
+	return r.Create.String() + "; " + r.Drop.String() + ";"
+}
+
+// FormatRecommendations returns a string slice of index recommendation output
+// that will be displayed below the statement plan in EXPLAIN.
+func FormatRecommendations(recs []Recommendation) []string {
+	if len(recs) == 0 {
+		return nil
+	}
+	output := make([]string, 0, 2*len(recs)+1)
+	output = append(output, fmt.Sprintf("index recommendations: %d", len(recs)))
+	for i := range recs {
+		sqlPrefix := "   SQL command: "
+		if recs[i].Drop != nil {
+			sqlPrefix = "   SQL commands: "
 		}
+		output = append(output,
+			fmt.Sprintf("%d. type: %s", i+1, recs[i].Type),
+			sqlPrefix+recs[i].SQL(),
+		)
 	}
 	return output
 }
@@ -248,7 +303,7 @@ func (ir *indexRecommendation) addStoredColOrds(scannedColOrds util.FastIntSet)
 }
 
 // indexCols returns the explicit key columns of the index, used in
-// indexRecommendationString.
+// recommendation.
 func (ir *indexRecommendation) indexCols() []tree.IndexElem {
 	indexCols := make([]tree.IndexElem, len(ir.index.cols))
 
@@ -272,7 +327,7 @@ func (ir *indexRecommendation) indexCols() []tree.IndexElem {
 }
 
 // storingColumns returns the stored columns of an index recommendation, used in
-// indexRecommendationString.
+// recommendation.
 func (ir *indexRecommendation) storingColumns() []tree.Name {
 	storingLen := ir.newStoredColOrds.Len()
 	if storingLen == 0 {
@@ -286,39 +341,35 @@ func (ir *indexRecommendation) storingColumns() []tree.Name {
 	return storingCols
 }
 
-// indexRecommendationString returns the string output for an index
-// recommendation, containing the SQL command(s) needed to follow this
-// recommendation.
-func (ir *indexRecommendation) indexRecommendationString(
-	indexCols []tree.IndexElem, storing []tree.Name,
-) string {
-	var sb strings.Builder
+// recommendation returns the Recommendation, containing the SQL command(s)
+// needed to follow this index recommendation.
+func (ir *indexRecommendation) recommendation() Recommendation {
 	tableName := tree.NewUnqualifiedTableName(ir.index.tab.Name())
+	rec := Recommendation{
+		Type:    TypeCreateIndex,
+		TableID: ir.index.tab.ID(),
+	}
 
-	var dropCmd tree.DropIndex
 	unique := false
 	if ir.existingIndex != nil {
-		sb.WriteString("   SQL commands: ")
-		indexName := tree.UnrestrictedName(ir.existingIndex.Name())
-		dropCmd.IndexList = []*tree.TableIndexName{{Table: *tableName, Index: indexName}}
+		rec.Type = TypeReplaceIndex
+		rec.Drop = &tree.DropIndex{
+			IndexList: []*tree.TableIndexName{{
+				Table: *tableName,
+				Index: tree.UnrestrictedName(ir.existingIndex.Name()),
+			}},
+		}
 
 		// Maintain uniqueness if the existing index is unique.
 		unique = ir.existingIndex.IsUnique()
-	} else {
-		sb.WriteString("   SQL command: ")
 	}
 
-	createCmd := tree.CreateIndex{
+	rec.Create = tree.CreateIndex{
 		Table:    *tableName,
-		Columns:  indexCols,
-		Storing:  storing,
+		Columns:  ir.indexCols(),
+		Storing:  ir.storingColumns(),
 		Unique:   unique,
 		Inverted: ir.index.IsInverted(),
 	}
-	sb.WriteString(createCmd.String() + ";")
-	if len(dropCmd.IndexList) > 0 {
-		sb.WriteString(" " + dropCmd.String() + ";")
-	}
-
-	return sb.String()
+	return rec
 }
@@ -976,7 +976,8 @@ type FKCascades []FKCascade
 // FKCascade stores metadata necessary for building a cascading query.
 // Cascading queries are built as needed, after the original query is executed.
 type FKCascade struct {
-	// FKName is the name of the FK constraint.
+	// FKName is the name of the FK constraint, or the name of the trigger for
+	// the cascades which execute AFTER triggers.
 	FKName string
 
 	// Builder is an object that can be used as the "optbuilder" for the cascading
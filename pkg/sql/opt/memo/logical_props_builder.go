@@ -1809,6 +1809,12 @@ func MakeTableFuncDep(md *opt.Metadata, tabID opt.TableID) *props.FuncDepSet {
 				continue
 			}
 
+			if unique.Deferrable() {
+				// A deferrable constraint can be violated until the end of the
+				// transaction that modified the table, so we cannot use it as a key.
+				continue
+			}
+
 			if _, isPartial := unique.Predicate(); isPartial {
 				// Partial constraints cannot be considered while building functional
 				// dependency keys for the table because their keys are only unique
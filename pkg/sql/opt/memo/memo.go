@@ -152,6 +152,11 @@ type Memo struct {
 	largeFullScanRows       float64
 	nullOrderedLast         bool
 
+	// planHintsKey identifies the plan hints applied when the memo was built,
+	// such as the hints of a plan baseline, or is empty if none were applied.
+	// The memo may only be reused for statements planned with the same hints.
+	planHintsKey string
+
 	// curRank is the highest currently in-use scalar expression rank.
 	curRank opt.ScalarRank
 
@@ -327,6 +332,18 @@ func (m *Memo) IsStale(
 	return false, nil
 }
 
+// PlanHintsKey returns the key identifying the plan hints applied when the memo
+// was built, or the empty string if none were applied.
+func (m *Memo) PlanHintsKey() string {
+	return m.planHintsKey
+}
+
+// SetPlanHintsKey records the key identifying the plan hints applied when the
+// memo was built. See PlanHintsKey.
+func (m *Memo) SetPlanHintsKey(key string) {
+	m.planHintsKey = key
+}
+
 // InternPhysicalProps adds the given physical props to the memo if they haven't
 // yet been added. If the same props was added previously, then return a pointer
 // to the previously added props. This allows interned physical props to be
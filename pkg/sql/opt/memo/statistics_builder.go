@@ -586,6 +586,16 @@ func (sb *statisticsBuilder) makeTableStatistics(tabID opt.TableID) *props.Stati
 							}
 						}
 					}
+				} else if cols.Len() > 1 && stat.Histogram() != nil &&
+					sb.evalCtx.SessionData().OptimizerUseHistograms {
+					// The histogram is built on the key encodings of the values of the
+					// columns, in the order of the columns in the statistic.
+					histCols := make(opt.ColList, stat.ColumnCount())
+					for i := range histCols {
+						histCols[i] = tabID.ColumnID(stat.ColumnOrdinal(i))
+					}
+					colStat.MultiColHistogram = &props.MultiColHistogram{}
+					colStat.MultiColHistogram.Init(histCols, stat.Histogram())
 				}
 
 				// Fetch the colStat again since it may now have a different address due
@@ -806,10 +816,23 @@ func (sb *statisticsBuilder) constrainScan(
 	}
 	sb.updateNullCountsFromNotNullCols(notNullCols, s)
 
+	// Calculate selectivity from multi-column histograms
+	// --------------------------------------------------
+	multiColHistSelectivity := props.OneSelectivity
+	var multiColHistCols opt.ColSet
+	if constraint != nil && pred == nil && scan.InvertedConstraint == nil {
+		multiColHistSelectivity, multiColHistCols =
+			sb.selectivityFromMultiColHistogramsForConstraint(scan.Table, constraint)
+	}
+
 	// Calculate row count and selectivity
 	// -----------------------------------
-	histSelectivity, selectivityUpperBound := sb.selectivityFromHistograms(histCols, scan, s)
+	histSelectivity, selectivityUpperBound := sb.selectivityFromHistograms(
+		histCols.Difference(multiColHistCols), scan, s,
+	)
 	s.ApplySelectivity(histSelectivity)
+	s.ApplySelectivity(multiColHistSelectivity)
+	selectivityUpperBound = props.MinSelectivity(selectivityUpperBound, multiColHistSelectivity)
 	s.ApplySelectivity(sb.selectivityFromUnappliedConjuncts(numUnappliedConjuncts))
 	s.ApplySelectivity(sb.selectivityFromNullsRemoved(scan, notNullCols, constrainedCols))
 
@@ -818,12 +841,28 @@ func (sb *statisticsBuilder) constrainScan(
 	// selectivity to increase, so apply a limit to ensure it does not exceed the
 	// upper bound based on the histograms.
 	s.ApplySelectivityRatio(
-		sb.selectivityFromMultiColDistinctCounts(constrainedCols, scan, s),
-		sb.selectivityFromSingleColDistinctCounts(histCols, scan, s),
+		sb.selectivityFromMultiColDistinctCounts(constrainedCols.Difference(multiColHistCols), scan, s),
+		sb.selectivityFromSingleColDistinctCounts(histCols.Difference(multiColHistCols), scan, s),
 	)
 	s.LimitSelectivity(selectivityUpperBound)
 }
 
+// selectivityFromMultiColHistogramsForConstraint is a wrapper around
+// selectivityFromMultiColHistograms for the columns that are fixed to constant
+// values by the given index constraint.
+func (sb *statisticsBuilder) selectivityFromMultiColHistogramsForConstraint(
+	tabID opt.TableID, c *constraint.Constraint,
+) (selectivity props.Selectivity, histCols opt.ColSet) {
+	cs := constraint.SingleConstraint(c)
+	return sb.selectivityFromMultiColHistograms(
+		tabID,
+		cs.ExtractConstCols(sb.evalCtx),
+		func(col opt.ColumnID) tree.Datum {
+			return cs.ExtractValueForConstCol(sb.evalCtx, col)
+		},
+	)
+}
+
 func (sb *statisticsBuilder) colStatScan(colSet opt.ColSet, scan *ScanExpr) *props.ColumnStatistic {
 	relProps := scan.Relational()
 	s := &relProps.Stats
@@ -2905,10 +2944,32 @@ func (sb *statisticsBuilder) filterRelExpr(
 	// ---------------------------------------------
 	sb.updateNullCountsFromNotNullCols(notNullCols, s)
 
+	// Calculate selectivity from multi-column histograms
+	// --------------------------------------------------
+	// Multi-column histograms describe the rows of a table, so they are only
+	// used for filters directly on top of an unfiltered scan.
+	multiColHistSelectivity := props.OneSelectivity
+	var multiColHistCols opt.ColSet
+	if sel, ok := e.(*SelectExpr); ok {
+		if scan, ok := sel.Input.(*ScanExpr); ok && scan.IsUnfiltered(sb.md) {
+			multiColHistSelectivity, multiColHistCols = sb.selectivityFromMultiColHistograms(
+				scan.Table,
+				ExtractConstColumns(filters, sb.evalCtx),
+				func(col opt.ColumnID) tree.Datum {
+					return ExtractValueForConstColumn(filters, sb.evalCtx, col)
+				},
+			)
+		}
+	}
+
 	// Calculate row count and selectivity
 	// -----------------------------------
-	histSelectivity, selectivityUpperBound := sb.selectivityFromHistograms(histCols, e, s)
+	histSelectivity, selectivityUpperBound := sb.selectivityFromHistograms(
+		histCols.Difference(multiColHistCols), e, s,
+	)
 	s.ApplySelectivity(histSelectivity)
+	s.ApplySelectivity(multiColHistSelectivity)
+	selectivityUpperBound = props.MinSelectivity(selectivityUpperBound, multiColHistSelectivity)
 	s.ApplySelectivity(sb.selectivityFromEquivalencies(equivReps, &relProps.FuncDeps, e, s))
 	s.ApplySelectivity(sb.selectivityFromUnappliedConjuncts(numUnappliedConjuncts))
 	s.ApplySelectivity(sb.selectivityFromNullsRemoved(e, notNullCols, constrainedCols))
@@ -2918,8 +2979,8 @@ func (sb *statisticsBuilder) filterRelExpr(
 	// selectivity to increase, so apply a limit to ensure it does not exceed the
 	// upper bound based on the histograms.
 	s.ApplySelectivityRatio(
-		sb.selectivityFromMultiColDistinctCounts(constrainedCols, e, s),
-		sb.selectivityFromSingleColDistinctCounts(histCols, e, s),
+		sb.selectivityFromMultiColDistinctCounts(constrainedCols.Difference(multiColHistCols), e, s),
+		sb.selectivityFromSingleColDistinctCounts(histCols.Difference(multiColHistCols), e, s),
 	)
 	s.LimitSelectivity(selectivityUpperBound)
 
@@ -3835,6 +3896,54 @@ func (sb *statisticsBuilder) selectivityFromHistograms(
 	return selectivity, selectivityUpperBound
 }
 
+// selectivityFromMultiColHistograms calculates the selectivity of a filter
+// that fixes columns of a table to constant values, using a histogram on
+// multiple columns of the table. The histogram with the most columns that are
+// all in constCols is used, and constValue must return the constant value of
+// each of those columns. Unlike the single-column distinct counts, the
+// histogram captures the correlation between the values of the columns.
+//
+// The columns of the histogram are returned as histCols, so that the caller
+// does not account for the filters on those columns again. If no histogram can
+// be used, the selectivity is one and histCols is empty.
+func (sb *statisticsBuilder) selectivityFromMultiColHistograms(
+	tabID opt.TableID, constCols opt.ColSet, constValue func(col opt.ColumnID) tree.Datum,
+) (selectivity props.Selectivity, histCols opt.ColSet) {
+	selectivity = props.OneSelectivity
+	if !sb.evalCtx.SessionData().OptimizerUseMultiColStats ||
+		!sb.evalCtx.SessionData().OptimizerUseHistograms || constCols.Len() < 2 {
+		return selectivity, opt.ColSet{}
+	}
+
+	tableStats := sb.makeTableStatistics(tabID)
+	var hist *props.MultiColHistogram
+	for i, n := 0, tableStats.ColStats.Count(); i < n; i++ {
+		colStat := tableStats.ColStats.Get(i)
+		if colStat.MultiColHistogram == nil || !colStat.Cols.SubsetOf(constCols) {
+			continue
+		}
+		if hist == nil || colStat.Cols.Len() > len(hist.Columns()) {
+			hist = colStat.MultiColHistogram
+		}
+	}
+	if hist == nil {
+		return selectivity, opt.ColSet{}
+	}
+
+	cols := hist.Columns()
+	vals := make(tree.Datums, len(cols))
+	for i, col := range cols {
+		if vals[i] = constValue(col); vals[i] == nil {
+			return selectivity, opt.ColSet{}
+		}
+	}
+	count, ok := hist.EqValuesCount(vals)
+	if !ok {
+		return selectivity, opt.ColSet{}
+	}
+	return props.MakeSelectivityFromFraction(count, tableStats.RowCount), cols.ToSet()
+}
+
 // selectivityFromNullsRemoved calculates the selectivity from null-rejecting
 // filters that were not already accounted for in selectivityFromMultiColDistinctCounts
 // or selectivityFromHistograms. The columns for filters already accounted for
@@ -111,6 +111,14 @@ type Metadata struct {
 	// needed for EXPLAIN (opt, env).
 	views []cat.View
 
+	// udfs stores the user-defined functions inlined in the query, along with
+	// the names used to resolve them.
+	udfs []mdUDF
+
+	// udfCalls stores the descriptor IDs of the user-defined functions called
+	// by the query, on which the EXECUTE privilege is required.
+	udfCalls []cat.StableID
+
 	// currUniqueID is the highest UniqueID that has been assigned.
 	currUniqueID UniqueID
 
@@ -131,6 +139,12 @@ type mdDep struct {
 	privileges privilegeBitmap
 }
 
+// mdUDF is a user-defined function on which the query depends.
+type mdUDF struct {
+	name tree.UnresolvedName
+	def  *tree.FunctionDefinition
+}
+
 // MDDepName stores either the unresolved DataSourceName or the StableID from
 // the query that was used to resolve a data source.
 type MDDepName struct {
@@ -181,6 +195,13 @@ func (md *Metadata) Init() {
 		views[i] = nil
 	}
 
+	udfs := md.udfs
+	for i := range udfs {
+		udfs[i] = mdUDF{}
+	}
+
+	udfCalls := md.udfCalls
+
 	// This initialization pattern ensures that fields are not unwittingly
 	// reused. Field reuse must be explicit.
 	*md = Metadata{}
@@ -190,6 +211,8 @@ func (md *Metadata) Init() {
 	md.sequences = sequences[:0]
 	md.deps = deps[:0]
 	md.views = views[:0]
+	md.udfs = udfs[:0]
+	md.udfCalls = udfCalls[:0]
 }
 
 // CopyFrom initializes the metadata with a copy of the provided metadata.
@@ -203,7 +226,8 @@ func (md *Metadata) Init() {
 func (md *Metadata) CopyFrom(from *Metadata, copyScalarFn func(Expr) Expr) {
 	if len(md.schemas) != 0 || len(md.cols) != 0 || len(md.tables) != 0 ||
 		len(md.sequences) != 0 || len(md.deps) != 0 || len(md.views) != 0 ||
-		len(md.userDefinedTypes) != 0 || len(md.userDefinedTypesSlice) != 0 {
+		len(md.userDefinedTypes) != 0 || len(md.userDefinedTypesSlice) != 0 ||
+		len(md.udfs) != 0 || len(md.udfCalls) != 0 {
 		panic(errors.AssertionFailedf("CopyFrom requires empty destination"))
 	}
 	md.schemas = append(md.schemas, from.schemas...)
@@ -233,6 +257,8 @@ func (md *Metadata) CopyFrom(from *Metadata, copyScalarFn func(Expr) Expr) {
 	md.sequences = append(md.sequences, from.sequences...)
 	md.deps = append(md.deps, from.deps...)
 	md.views = append(md.views, from.views...)
+	md.udfs = append(md.udfs, from.udfs...)
+	md.udfCalls = append(md.udfCalls, from.udfCalls...)
 	md.currUniqueID = from.currUniqueID
 
 	// We cannot copy the bound expressions; they must be rebuilt in the new memo.
@@ -332,9 +358,72 @@ func (md *Metadata) CheckDependencies(
 			return false, nil
 		}
 	}
+	// Check that all of the user-defined functions still resolve to the same
+	// versions of the same overloads.
+	for i := range md.udfs {
+		toCheck, err := catalog.ResolveFunction(ctx, &md.udfs[i].name)
+		if err != nil {
+			return false, err
+		}
+		if toCheck == nil || !sameUDFVersion(toCheck, md.udfs[i].def) {
+			return false, nil
+		}
+	}
+	// Check that the user still has privileges to call the user-defined
+	// functions.
+	for _, id := range md.udfCalls {
+		if err := catalog.CheckFunctionPrivilege(ctx, id, privilege.EXECUTE); err != nil {
+			return false, err
+		}
+	}
 	return true, nil
 }
 
+// sameUDFVersion returns whether two definitions of a user-defined function
+// have the same overloads, resolved from the same versions of their function
+// descriptors.
+func sameUDFVersion(a, b *tree.FunctionDefinition) bool {
+	if len(a.Definition) != len(b.Definition) {
+		return false
+	}
+	for i := range a.Definition {
+		udfA, udfB := a.Definition[i].(*tree.Overload).UDF, b.Definition[i].(*tree.Overload).UDF
+		if udfA.FunctionID != udfB.FunctionID || udfA.FunctionVersion != udfB.FunctionVersion {
+			return false
+		}
+	}
+	return true
+}
+
+// AddUserDefinedFunction tracks a user-defined function that is inlined in
+// the query, as well as the name used to resolve it. If the Memo using this
+// metadata is cached, then a call to CheckDependencies can detect if the name
+// resolves to a different function now, or if the function was replaced or
+// dropped.
+func (md *Metadata) AddUserDefinedFunction(
+	name *tree.UnresolvedName, def *tree.FunctionDefinition,
+) {
+	for i := range md.udfs {
+		if md.udfs[i].name.String() == name.String() && sameUDFVersion(md.udfs[i].def, def) {
+			return
+		}
+	}
+	md.udfs = append(md.udfs, mdUDF{name: *name, def: def})
+}
+
+// AddUserDefinedFunctionCall tracks a call to the user-defined function with
+// the given descriptor ID. If the Memo using this metadata is cached, then a
+// call to CheckDependencies can detect if the user no longer has the EXECUTE
+// privilege on the function.
+func (md *Metadata) AddUserDefinedFunctionCall(id cat.StableID) {
+	for _, call := range md.udfCalls {
+		if call == id {
+			return
+		}
+	}
+	md.udfCalls = append(md.udfCalls, id)
+}
+
 // AddSchema indexes a new reference to a schema used by the query.
 func (md *Metadata) AddSchema(sch cat.Schema) SchemaID {
 	md.schemas = append(md.schemas, sch)
@@ -87,6 +87,12 @@ func (c *CustomFuncs) neededMutationCols(
 			withUses := memo.WithUses(fkChecks[i].Check)
 			cols.UnionWith(withUses[private.WithID].UsedCols)
 		}
+		// Cascades read their old and new values from the buffered input. For
+		// AFTER triggers, these are not necessarily fetched or updated columns.
+		for i := range private.FKCascades {
+			cols.UnionWith(private.FKCascades[i].OldValues.ToSet())
+			cols.UnionWith(private.FKCascades[i].NewValues.ToSet())
+		}
 	}
 
 	return cols
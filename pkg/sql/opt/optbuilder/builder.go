@@ -68,6 +68,14 @@ type Builder struct {
 	// This is used when re-preparing invalidated queries.
 	KeepPlaceholders bool
 
+	// PlanHints is a control knob: if set, the index and join hints of the
+	// statement are replaced by the given hints.
+	PlanHints *PlanHints
+
+	// RecordPlanHintTargets is a control knob: if set, PlanHintTargets is
+	// populated while the statement is built.
+	RecordPlanHintTargets bool
+
 	// -- Results --
 	//
 	// These fields are set during the building process and can be used after
@@ -82,6 +90,10 @@ type Builder struct {
 	// statements.
 	DisableMemoReuse bool
 
+	// PlanHintTargets describes the tables and joins of the statement which can
+	// be hinted by PlanHints. It is only set if RecordPlanHintTargets is set.
+	PlanHintTargets PlanHintTargets
+
 	factory *norm.Factory
 	stmt    tree.Statement
 
@@ -118,6 +130,10 @@ type Builder struct {
 	// are disabled and certain statements (like mutations) are disallowed.
 	insideViewDef bool
 
+	// inliningUDFs contains the descriptor IDs of the user-defined functions
+	// whose bodies are currently being built, to detect recursive functions.
+	inliningUDFs []uint32
+
 	// If set, we are collecting view dependencies in viewDeps. This can only
 	// happen inside view definitions.
 	//
@@ -144,6 +160,18 @@ type Builder struct {
 	// (without ON CONFLICT) or false otherwise. All mutated tables will have an
 	// entry in the map.
 	areAllTableMutationsSimpleInserts map[cat.StableID]bool
+
+	// numHintedTables and numHintedJoins are the number of table data sources
+	// and joins which have been built so far; see PlanHints.
+	numHintedTables int
+	numHintedJoins  int
+
+	// buildingView is true while the query of a view referenced by the
+	// statement is being built. The tables and joins of the view are not
+	// hinted, since the view can be redefined independently of the statement.
+	// For the same reason, neither are those of the bodies of user-defined
+	// functions (see inliningUDFs).
+	buildingView bool
 }
 
 // New creates a new Builder structure initialized with the given
@@ -45,6 +45,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
 	"github.com/cockroachdb/errors"
 )
 
@@ -100,6 +101,32 @@ type groupby struct {
 	// It is used to ensure that the builder does not throw a grouping error
 	// prematurely.
 	buildingGroupingCols bool
+
+	// groupingSets contains the grouping sets of a GROUP BY clause that uses
+	// GROUPING SETS, ROLLUP or CUBE. Each set contains the grouping columns
+	// that are included in it. It is nil if there is a single grouping set, in
+	// which case a single GroupBy operator is built.
+	groupingSets []opt.ColSet
+
+	// outCols maps the grouping columns in aggInScope to the columns in
+	// aggOutScope which hold their values, if they are different. This is the
+	// case if there are multiple grouping sets, since the grouping columns
+	// which are not part of a set are NULL in the rows produced for that set.
+	outCols opt.ColMap
+
+	// groupingFuncs contains information about GROUPING function calls
+	// encountered when there are multiple grouping sets.
+	groupingFuncs []groupingFuncInfo
+}
+
+// groupingFuncInfo stores information about a GROUPING function call.
+type groupingFuncInfo struct {
+	// args contains the grouping columns corresponding to the arguments of the
+	// function.
+	args opt.ColList
+
+	// col is the output column of the function.
+	col *scopeColumn
 }
 
 // groupByStrSet is a set of stringified GROUP BY expressions that map to the
@@ -149,6 +176,15 @@ func (g *groupby) aggregateResultCols() []scopeColumn {
 	return g.aggOutScope.cols[:len(g.aggs)]
 }
 
+// outputCol returns the column in aggOutScope which holds the values of the
+// given grouping column (see outCols).
+func (g *groupby) outputCol(col *scopeColumn) *scopeColumn {
+	if outCol, ok := g.outCols.Get(int(col.id)); ok {
+		return g.aggOutScope.getColumn(opt.ColumnID(outCol))
+	}
+	return col
+}
+
 // hasAggregates returns true if the enclosing scope has aggregate functions.
 func (g *groupby) hasAggregates() bool {
 	return len(g.aggs) > 0
@@ -322,6 +358,244 @@ func (b *Builder) constructGroupBy(
 	return b.factory.ConstructGroupBy(input, aggs, &private)
 }
 
+// constructGroupingSets constructs the aggregation for a GROUP BY clause with
+// multiple grouping sets. The pre-projection (aggInScope) is bound to a With
+// expression so that it is computed only once, and an aggregation is built for
+// each grouping set on top of a WithScan of it. The grouping columns that are
+// not part of a grouping set are NULL in the rows produced for that set. The
+// results for all the grouping sets are combined with UnionAll operators.
+//
+// The final UnionAll produces the columns in aggOutScope. The grouping columns
+// have different IDs in aggOutScope than in the pre-projection (see
+// groupby.outCols), so that they are not produced by both the pre-projection
+// and the UnionAll.
+//
+// For example:
+//
+//   SELECT a, b, count(*) FROM t GROUP BY ROLLUP (a, b)
+//
+//   with &1
+//    ├── pre-projection: a, b
+//    └── union-all
+//         ├── union-all
+//         │    ├── group-by (a, b): count(*)
+//         │    │    └── with-scan &1
+//         │    └── project (b: NULL)
+//         │         └── group-by (a): count(*)
+//         │              └── with-scan &1
+//         └── project (a: NULL, b: NULL)
+//              └── scalar-group-by: count(*)
+//                   └── with-scan &1
+//
+// GROUPING function calls are projected as constants in the aggregation for
+// each grouping set.
+func (b *Builder) constructGroupingSets(
+	g *groupby, aggCols []scopeColumn, ordering opt.Ordering,
+) memo.RelExpr {
+	md := b.factory.Metadata()
+	groupingCols := g.groupingCols()
+
+	binding := g.aggInScope.expr
+	bindingCols := binding.Relational().OutputCols.ToList()
+	withID := b.factory.Memo().NextWithID()
+	md.AddWithBinding(withID, binding)
+
+	// The output columns of the union are the aggregates, the grouping columns
+	// and the GROUPING function columns in aggOutScope. The grouping columns are
+	// identified by their IDs in the pre-projection in outCols.
+	var outCols opt.ColList
+	var outColSet opt.ColSet
+	addOutCol := func(col opt.ColumnID) {
+		if !outColSet.Contains(col) {
+			outCols = append(outCols, col)
+			outColSet.Add(col)
+		}
+	}
+	for i := range aggCols {
+		addOutCol(aggCols[i].id)
+	}
+	for i := range groupingCols {
+		addOutCol(groupingCols[i].id)
+	}
+	for i := range g.groupingFuncs {
+		addOutCol(g.groupingFuncs[i].col.id)
+	}
+
+	newCol := func(col opt.ColumnID) opt.ColumnID {
+		meta := md.ColumnMeta(col)
+		return md.AddColumn(meta.Alias, meta.Type)
+	}
+
+	var union memo.RelExpr
+	var unionCols opt.ColList
+	for i, set := range g.groupingSets {
+		// Scan the pre-projection, with new column IDs.
+		var scanColMap opt.ColMap
+		scanCols := make(opt.ColList, len(bindingCols))
+		for j, col := range bindingCols {
+			scanCols[j] = newCol(col)
+			scanColMap.Set(int(col), int(scanCols[j]))
+		}
+		input := b.factory.ConstructWithScan(&memo.WithScanPrivate{
+			With:    withID,
+			InCols:  bindingCols,
+			OutCols: scanCols,
+			ID:      md.NextUniqueID(),
+		})
+
+		// branchCols maps each output column of the union to the corresponding
+		// column of this branch.
+		var branchCols opt.ColMap
+
+		// Build the aggregations, remapped to the new columns.
+		branchAggCols := make([]scopeColumn, len(aggCols))
+		for j := range aggCols {
+			branchAggCols[j] = aggCols[j]
+			if col, ok := branchCols.Get(int(aggCols[j].id)); ok {
+				branchAggCols[j].id = opt.ColumnID(col)
+				continue
+			}
+			branchAggCols[j].id = newCol(aggCols[j].id)
+			branchAggCols[j].scalar = b.factory.CustomFuncs().RemapCols(aggCols[j].scalar, scanColMap)
+			branchCols.Set(int(aggCols[j].id), int(branchAggCols[j].id))
+		}
+
+		// Grouping columns in the set are passed through, while the others are
+		// projected as NULL.
+		var branchGroupingCols opt.ColSet
+		var projections memo.ProjectionsExpr
+		for j := range groupingCols {
+			groupingCol := groupingCols[j].id
+			if _, ok := branchCols.Get(int(groupingCol)); ok {
+				continue
+			}
+			if set.Contains(groupingCol) {
+				col, _ := scanColMap.Get(int(groupingCol))
+				branchGroupingCols.Add(opt.ColumnID(col))
+				branchCols.Set(int(groupingCol), col)
+			} else {
+				col := newCol(groupingCol)
+				null := b.factory.ConstructNull(groupingCols[j].typ)
+				projections = append(projections, b.factory.ConstructProjectionsItem(null, col))
+				branchCols.Set(int(groupingCol), int(col))
+			}
+		}
+
+		// The result of a GROUPING function call has a bit set for each argument
+		// that is not in the grouping set, where the last argument corresponds to
+		// the least significant bit.
+		for j := range g.groupingFuncs {
+			fn := &g.groupingFuncs[j]
+			var mask int
+			for _, arg := range fn.args {
+				mask <<= 1
+				if !set.Contains(arg) {
+					mask |= 1
+				}
+			}
+			col := newCol(fn.col.id)
+			val := b.factory.ConstructConstVal(tree.NewDInt(tree.DInt(mask)), types.Int)
+			projections = append(projections, b.factory.ConstructProjectionsItem(val, col))
+			branchCols.Set(int(fn.col.id), int(col))
+		}
+
+		var branchOrdering opt.Ordering
+		for _, ordCol := range ordering {
+			col, ok := scanColMap.Get(int(ordCol.ID()))
+			if !ok {
+				break
+			}
+			branchOrdering = append(branchOrdering, opt.MakeOrderingColumn(opt.ColumnID(col), ordCol.Descending()))
+		}
+
+		branch := b.constructGroupBy(input, branchGroupingCols, branchAggCols, branchOrdering)
+		if len(projections) > 0 {
+			branch = b.factory.ConstructProject(branch, projections, branch.Relational().OutputCols)
+		}
+
+		branchOutCols := make(opt.ColList, len(outCols))
+		for j, col := range outCols {
+			branchCol, _ := branchCols.Get(int(col))
+			branchOutCols[j] = opt.ColumnID(branchCol)
+		}
+
+		if union == nil {
+			union, unionCols = branch, branchOutCols
+			continue
+		}
+
+		// The last UnionAll produces the output columns in aggOutScope; the
+		// others produce new columns.
+		unionOutCols := make(opt.ColList, len(outCols))
+		for j, col := range outCols {
+			if i < len(g.groupingSets)-1 {
+				unionOutCols[j] = newCol(col)
+			} else if outCol, ok := g.outCols.Get(int(col)); ok {
+				unionOutCols[j] = opt.ColumnID(outCol)
+			} else {
+				unionOutCols[j] = col
+			}
+		}
+		union = b.factory.ConstructUnionAll(union, branch, &memo.SetPrivate{
+			LeftCols:  unionCols,
+			RightCols: branchOutCols,
+			OutCols:   unionOutCols,
+		})
+		unionCols = unionOutCols
+	}
+
+	return b.factory.ConstructWith(binding, union, &memo.WithPrivate{ID: withID})
+}
+
+// buildGroupingFunc builds a GROUPING function call. Each of its arguments
+// must match a GROUP BY expression. If there are multiple grouping sets, the
+// result of the function is computed by the aggregation for each grouping set
+// (see constructGroupingSets). Otherwise, all the arguments are always part of
+// the grouping set, so the result is 0.
+func (b *Builder) buildGroupingFunc(
+	t *tree.GroupingExpr, inScope, outScope *scope, outCol *scopeColumn, colRefs *opt.ColSet,
+) opt.ScalarExpr {
+	g := inScope.groupby
+	if !inScope.inGroupingContext() || inScope.inAgg || g.buildingGroupingCols {
+		panic(errGroupingFuncArgs)
+	}
+	if len(t.Exprs) > 31 {
+		panic(pgerror.New(pgcode.TooManyArguments, "GROUPING must have fewer than 32 arguments"))
+	}
+
+	args := make(opt.ColList, len(t.Exprs))
+	for i, e := range t.Exprs {
+		col, ok := g.groupStrs[symbolicExprStr(e)]
+		if !ok {
+			panic(errGroupingFuncArgs)
+		}
+		args[i] = col.id
+	}
+
+	if g.groupingSets == nil {
+		out := b.factory.ConstructConstVal(tree.NewDInt(0), types.Int)
+		return b.finishBuildScalar(t, out, inScope, outScope, outCol)
+	}
+
+	// Reuse the column of an identical GROUPING call, if there is one.
+	var col *scopeColumn
+	for i := range g.groupingFuncs {
+		if g.groupingFuncs[i].args.Equals(args) {
+			col = g.groupingFuncs[i].col
+			break
+		}
+	}
+	if col == nil {
+		col = b.synthesizeColumn(g.aggOutScope, scopeColName("grouping"), types.Int, t, nil /* scalar */)
+		g.groupingFuncs = append(g.groupingFuncs, groupingFuncInfo{args: args, col: col})
+	}
+	return b.finishBuildScalarRef(col, g.aggOutScope, outScope, outCol, colRefs)
+}
+
+var errGroupingFuncArgs = pgerror.New(pgcode.Grouping,
+	"arguments to GROUPING must be grouping expressions of the associated query level",
+)
+
 // buildGroupingColumns builds the grouping columns and adds them to the
 // groupby scopes that will be used to build the aggregation expression.
 // Returns the slice of grouping columns.
@@ -334,8 +608,19 @@ func (b *Builder) buildGroupingColumns(sel *tree.SelectClause, projectionsScope,
 	// The "from" columns are visible to any grouping expressions.
 	b.buildGroupingList(sel.GroupBy, sel.Exprs, projectionsScope, fromScope)
 
-	// Copy the grouping columns to the aggOutScope.
-	g.aggOutScope.appendColumns(g.groupingCols())
+	// Copy the grouping columns to the aggOutScope. If there are multiple
+	// grouping sets, the aggregation produces the grouping columns as new
+	// columns (see constructGroupingSets).
+	if g.groupingSets == nil {
+		g.aggOutScope.appendColumns(g.groupingCols())
+		return
+	}
+	groupingCols := g.groupingCols()
+	for i := range groupingCols {
+		col := &groupingCols[i]
+		outCol := b.synthesizeColumn(g.aggOutScope, col.name, col.typ, col.expr, nil /* scalar */)
+		g.outCols.Set(int(col.id), int(outCol.id))
+	}
 }
 
 // buildAggregation builds the aggregation operators and constructs the
@@ -354,6 +639,11 @@ func (b *Builder) buildAggregation(having opt.ScalarExpr, fromScope *scope) (out
 	// If there are any aggregates that are ordering sensitive, build the
 	// aggregations as window functions over each group.
 	if g.hasNonCommutativeAggregates() {
+		if g.groupingSets != nil {
+			panic(unimplemented.NewWithIssue(46280,
+				"ordering-sensitive aggregates with ORDER BY are not supported with grouping sets",
+			))
+		}
 		return b.buildAggregationAsWindow(groupingColSet, having, fromScope)
 	}
 
@@ -430,12 +720,16 @@ func (b *Builder) buildAggregation(having opt.ScalarExpr, fromScope *scope) (out
 	// aggregate arguments, as well as any additional order by columns.
 	b.constructProjectForScope(fromScope, g.aggInScope)
 
-	g.aggOutScope.expr = b.constructGroupBy(
-		g.aggInScope.expr,
-		groupingColSet,
-		aggCols,
-		g.aggInScope.ordering,
-	)
+	if g.groupingSets != nil {
+		g.aggOutScope.expr = b.constructGroupingSets(g, aggCols, g.aggInScope.ordering)
+	} else {
+		g.aggOutScope.expr = b.constructGroupBy(
+			g.aggInScope.expr,
+			groupingColSet,
+			aggCols,
+			g.aggInScope.ordering,
+		)
+	}
 
 	// Wrap with having filter if it exists.
 	if having != nil {
@@ -481,7 +775,9 @@ func (b *Builder) buildHaving(having tree.TypedExpr, fromScope *scope) opt.Scala
 
 // buildGroupingList builds a set of memo groups that represent a list of
 // GROUP BY expressions, adding the group-by expressions as columns to
-// aggInScope and populating groupStrs.
+// aggInScope and populating groupStrs. If the list contains GROUPING SETS,
+// ROLLUP or CUBE elements, it also populates groupingSets with the cross
+// product of the grouping sets of each element.
 //
 // groupBy   The given GROUP BY expressions.
 // selects   The select expressions are needed in case one of the GROUP BY
@@ -507,15 +803,120 @@ func (b *Builder) buildGroupingList(
 	// used in an aggregate function`. The builder cannot know whether there is
 	// a grouping error until the grouping columns are fully built.
 	g.buildingGroupingCols = true
+	sets := []opt.ColSet{{}}
 	for _, e := range groupBy {
-		b.buildGrouping(e, selects, projectionsScope, fromScope, g.aggInScope)
+		var elemSets []opt.ColSet
+		if gs, ok := e.(*tree.GroupingSets); ok {
+			elemSets = b.buildGroupingSets(gs, selects, projectionsScope, fromScope)
+		} else {
+			elemSets = []opt.ColSet{b.buildGrouping(e, selects, projectionsScope, fromScope, g.aggInScope)}
+		}
+		if len(sets)*len(elemSets) > maxGroupingSets {
+			panic(errTooManyGroupingSets)
+		}
+		product := make([]opt.ColSet, 0, len(sets)*len(elemSets))
+		for _, set := range sets {
+			for _, elemSet := range elemSets {
+				product = append(product, set.Union(elemSet))
+			}
+		}
+		sets = product
 	}
 	g.buildingGroupingCols = false
+
+	// A single grouping set contains all the grouping columns, so it is
+	// equivalent to a plain GROUP BY.
+	if len(sets) > 1 {
+		g.groupingSets = sets
+	}
+}
+
+// maxGroupingSets is the maximum number of grouping sets that a GROUP BY
+// clause can expand to.
+const maxGroupingSets = 4096
+
+// maxCubeElements is the maximum number of elements in a CUBE; larger CUBEs
+// would produce more than maxGroupingSets grouping sets.
+const maxCubeElements = 12
+
+var errTooManyGroupingSets = pgerror.Newf(pgcode.StatementTooComplex,
+	"too many grouping sets present (maximum %d)", maxGroupingSets,
+)
+
+// buildGroupingSets builds the expressions in a GROUPING SETS, ROLLUP or CUBE
+// element of a GROUP BY clause (see buildGrouping), and returns the grouping
+// sets that the element expands to. For example:
+//
+//   ROLLUP (a, b, c)          => (a, b, c), (a, b), (a), ()
+//   CUBE (a, b)               => (a, b), (a), (b), ()
+//   GROUPING SETS (a, (b, c)) => (a), (b, c)
+//
+func (b *Builder) buildGroupingSets(
+	gs *tree.GroupingSets, selects tree.SelectExprs, projectionsScope, fromScope *scope,
+) []opt.ColSet {
+	g := fromScope.groupby
+	if gs.Type == tree.GroupingSetsExplicit {
+		var sets []opt.ColSet
+		for _, e := range gs.Exprs {
+			if nested, ok := e.(*tree.GroupingSets); ok {
+				sets = append(sets, b.buildGroupingSets(nested, selects, projectionsScope, fromScope)...)
+			} else {
+				sets = append(sets, b.buildGrouping(e, selects, projectionsScope, fromScope, g.aggInScope))
+			}
+			if len(sets) > maxGroupingSets {
+				panic(errTooManyGroupingSets)
+			}
+		}
+		return sets
+	}
+
+	// Each element of a ROLLUP or CUBE is either an expression or a
+	// parenthesized list of expressions which is treated as a unit.
+	elems := make([]opt.ColSet, len(gs.Exprs))
+	for i, e := range gs.Exprs {
+		elems[i] = b.buildGrouping(e, selects, projectionsScope, fromScope, g.aggInScope)
+	}
+
+	var sets []opt.ColSet
+	switch gs.Type {
+	case tree.GroupingSetsRollup:
+		// ROLLUP includes every prefix of its elements, from longest to shortest.
+		// The last set is the empty set.
+		sets = make([]opt.ColSet, len(elems)+1)
+		var prefix opt.ColSet
+		for i := range elems {
+			prefix = prefix.Union(elems[i])
+			sets[len(elems)-1-i] = prefix
+		}
+
+	case tree.GroupingSetsCube:
+		// CUBE includes every subset of its elements.
+		if len(elems) > maxCubeElements {
+			panic(pgerror.Newf(pgcode.ProgramLimitExceeded,
+				"CUBE is limited to %d elements", maxCubeElements,
+			))
+		}
+		sets = make([]opt.ColSet, 0, 1<<len(elems))
+		for mask := (1 << len(elems)) - 1; mask >= 0; mask-- {
+			var set opt.ColSet
+			for i := range elems {
+				if mask&(1<<(len(elems)-1-i)) != 0 {
+					set.UnionWith(elems[i])
+				}
+			}
+			sets = append(sets, set)
+		}
+
+	default:
+		panic(errors.AssertionFailedf("unhandled grouping sets type %d", gs.Type))
+	}
+	return sets
 }
 
 // buildGrouping builds a set of memo groups that represent a GROUP BY
 // expression. The expression (or expressions, if we have a star) is added to
-// groupStrs and to the aggInScope.
+// groupStrs and to the aggInScope. Returns the set of grouping columns that
+// correspond to the expression.
 //
 //
 // groupBy          The given GROUP BY expression.
@@ -529,7 +930,7 @@ func (b *Builder) buildGroupingList(
 //                  as the aggregate function arguments.
 func (b *Builder) buildGrouping(
 	groupBy tree.Expr, selects tree.SelectExprs, projectionsScope, fromScope, aggInScope *scope,
-) {
+) (cols opt.ColSet) {
 	// Unwrap parenthesized expressions like "((a))" to "a".
 	groupBy = tree.StripParens(groupBy)
 	alias := ""
@@ -627,7 +1028,8 @@ func (b *Builder) buildGrouping(
 		// If a grouping column has already been added, don't add it again.
 		// GROUP BY a, a is semantically equivalent to GROUP BY a.
 		exprStr := symbolicExprStr(e)
-		if _, ok := fromScope.groupby.groupStrs[exprStr]; ok {
+		if col, ok := fromScope.groupby.groupStrs[exprStr]; ok {
+			cols.Add(col.id)
 			continue
 		}
 
@@ -637,7 +1039,9 @@ func (b *Builder) buildGrouping(
 		col := aggInScope.addColumn(scopeColName(tree.Name(alias)), e)
 		b.buildScalar(e, fromScope, aggInScope, col, nil)
 		fromScope.groupby.groupStrs[exprStr] = col
+		cols.Add(col.id)
 	}
+	return cols
 }
 
 // buildAggArg builds a scalar expression which is used as an input in some form
@@ -903,6 +1307,10 @@ func newGroupingError(name tree.Name) error {
 // table. In that case, we can allow col as an "implicit" grouping column, even
 // if it is not specified in the query.
 func (b *Builder) allowImplicitGroupingColumn(colID opt.ColumnID, g *groupby) bool {
+	if g.groupingSets != nil {
+		// The column would be NULL for grouping sets which don't include the PK.
+		return false
+	}
 	md := b.factory.Metadata()
 	colMeta := md.ColumnMeta(colID)
 	if colMeta.Table == 0 {
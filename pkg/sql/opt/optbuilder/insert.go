@@ -350,6 +350,8 @@ func (b *Builder) buildInsert(ins *tree.Insert, inScope *scope) (outScope *scope
 //      values specified for them.
 //   4. Each update value is the same as the corresponding insert value.
 //   5. There are no inbound foreign keys containing non-key columns.
+//   6. There are no triggers, which need to know whether each row was
+//      inserted or updated.
 //
 // TODO(andyk): The fast path is currently only enabled when the UPSERT alias
 // is explicitly selected by the user. It's possible to fast path some queries
@@ -357,7 +359,7 @@ func (b *Builder) buildInsert(ins *tree.Insert, inScope *scope) (outScope *scope
 // of edge cases (that caused real correctness bugs #13437 #13962). As a result,
 // this support was removed and needs to re-enabled. See #14482.
 func (mb *mutationBuilder) needExistingRows() bool {
-	if mb.tab.DeletableIndexCount() > 1 {
+	if mb.tab.DeletableIndexCount() > 1 || mb.tab.TriggerCount() > 0 {
 		return true
 	}
 
@@ -655,6 +657,10 @@ func (mb *mutationBuilder) addSynthesizedColsForInsert() {
 	// Add assignment casts for default column values.
 	mb.addAssignmentCasts(mb.insertColIDs)
 
+	// Build the BEFORE INSERT triggers, which can change the values of the
+	// non-computed columns.
+	mb.buildBeforeTriggers(tree.TriggerInsert)
+
 	// Now add all computed columns.
 	mb.addSynthesizedComputedCols(mb.insertColIDs, false /* restrict */)
 
@@ -679,6 +685,8 @@ func (mb *mutationBuilder) buildInsert(returning tree.ReturningExprs) {
 
 	mb.buildFKChecksForInsert()
 
+	mb.buildAfterTriggers(tree.TriggerInsert)
+
 	private := mb.makeMutationPrivate(returning != nil)
 	mb.outScope.expr = mb.b.factory.ConstructInsert(
 		mb.outScope.expr, mb.uniqueChecks, mb.fkChecks, private,
@@ -902,6 +910,11 @@ func (mb *mutationBuilder) buildUpsert(returning tree.ReturningExprs) {
 
 	mb.buildFKChecksForUpsert()
 
+	// The AFTER INSERT triggers fire for the inserted rows and the AFTER UPDATE
+	// triggers fire for the updated rows.
+	mb.buildAfterTriggers(tree.TriggerInsert)
+	mb.buildAfterTriggers(tree.TriggerUpdate)
+
 	private := mb.makeMutationPrivate(returning != nil)
 	mb.outScope.expr = mb.b.factory.ConstructUpsert(
 		mb.outScope.expr, mb.uniqueChecks, mb.fkChecks, private,
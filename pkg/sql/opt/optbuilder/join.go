@@ -33,6 +33,7 @@ import (
 func (b *Builder) buildJoin(
 	join *tree.JoinTableExpr, locking lockingSpec, inScope *scope,
 ) (outScope *scope) {
+	hintOrd, leftStart := b.startHintJoin(), b.numHintedTables
 	leftScope := b.buildDataSource(join.Left, nil /* indexFlags */, locking, inScope)
 
 	inScopeRight := inScope
@@ -45,14 +46,16 @@ func (b *Builder) buildJoin(
 		inScopeRight.context = exprKindLateralJoin
 	}
 
+	rightStart := b.numHintedTables
 	rightScope := b.buildDataSource(join.Right, nil /* indexFlags */, locking, inScopeRight)
 
 	// Check that the same table name is not used on both sides.
 	b.validateJoinTableNames(leftScope, rightScope)
 
 	joinType := descpb.JoinTypeFromAstString(join.JoinType)
+	hint := b.hintJoin(hintOrd, leftStart, rightStart, join.Hint)
 	var flags memo.JoinFlags
-	switch join.Hint {
+	switch hint {
 	case "":
 	case tree.AstHash:
 		telemetry.Inc(sqltelemetry.HashJoinHintUseCounter)
@@ -86,7 +89,7 @@ func (b *Builder) buildJoin(
 
 	default:
 		panic(pgerror.Newf(
-			pgcode.FeatureNotSupported, "join hint %s not supported", join.Hint,
+			pgcode.FeatureNotSupported, "join hint %s not supported", hint,
 		))
 	}
 
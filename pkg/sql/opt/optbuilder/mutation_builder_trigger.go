@@ -0,0 +1,496 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package optbuilder
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/props"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/errors"
+)
+
+// This file contains methods that build the row-level triggers of the table
+// being mutated. The body of a trigger function references the row that is
+// inserted, updated or deleted through the "new" and "old" data sources, which
+// have one column for each visible, non-computed column of the table. "old" is
+// NULL for inserted rows, and "new" is NULL for deleted rows.
+//
+// -- BEFORE triggers --
+//
+// The body of the trigger function of a BEFORE trigger is a SELECT statement,
+// which is joined with each row of the mutation input:
+//
+//   SELECT * FROM input, LATERAL (body LIMIT 1)
+//
+// Rows for which the body returns no rows are skipped. For inserts and updates,
+// the columns returned by the body replace the new values of the row. BEFORE
+// triggers are built after default values have been added and before computed
+// columns are added, as in Postgres.
+//
+// In an upsert, the BEFORE INSERT triggers fire for all input rows, before
+// conflicts are detected. The BEFORE UPDATE triggers only fire for the rows
+// that conflict with an existing row, so the body is left-joined instead:
+//
+//   SELECT * FROM input
+//   LEFT JOIN LATERAL (SELECT *, true AS trigger_row FROM (body LIMIT 1))
+//   ON canary IS NOT NULL
+//   WHERE canary IS NULL OR trigger_row IS NOT NULL
+//
+// -- AFTER triggers --
+//
+// The body of the trigger function of an AFTER trigger is an INSERT statement,
+// which is executed for each mutated row. AFTER triggers are built like FK
+// cascades: the mutation input is buffered, and the insert is built by
+// afterTriggerBuilder and executed after the mutation, with the buffered rows
+// as the source of the "new" and "old" columns:
+//
+//   INSERT INTO audit SELECT body_values FROM buffer, LATERAL (VALUES ...)
+//
+// In an upsert, the AFTER INSERT triggers fire for the inserted rows and the
+// AFTER UPDATE triggers fire for the updated rows, which are told apart by the
+// canary column of the upsert.
+//
+
+// buildBeforeTriggers builds the BEFORE triggers of the mutated table that
+// fire for the given event, in the order of their names.
+func (mb *mutationBuilder) buildBeforeTriggers(event tree.TriggerEvent) {
+	for i, n := 0, mb.tab.TriggerCount(); i < n; i++ {
+		trigger := mb.tab.Trigger(i)
+		if trigger.ActionTime == tree.TriggerBefore && trigger.HasEvent(event) {
+			mb.buildBeforeTrigger(&trigger, event)
+		}
+	}
+}
+
+// buildBeforeTrigger joins the body of the trigger function of the given
+// BEFORE trigger with the mutation input. See the comment at the top of the
+// file.
+func (mb *mutationBuilder) buildBeforeTrigger(trigger *cat.Trigger, event tree.TriggerEvent) {
+	udf := mb.resolveTriggerFunction(trigger)
+	sel, ok := parseTriggerFunctionBody(trigger, udf).(*tree.Select)
+	if !ok {
+		panic(pgerror.Newf(pgcode.FeatureNotSupported,
+			"BEFORE triggers require a trigger function with a SELECT body, found INSERT in %s",
+			trigger.FunctionName))
+	}
+
+	ords := triggerColumnOrdinals(mb.tab)
+	oldCols, newCols := mb.triggerCols(ords, event)
+	triggerScope := mb.b.buildTriggerScope(mb.tab, ords, mb.outScope.expr, oldCols, newCols)
+
+	var desiredTypes []*types.T
+	if event != tree.TriggerDelete {
+		desiredTypes = make([]*types.T, len(ords))
+		for i, ord := range ords {
+			desiredTypes[i] = mb.tab.Column(ord).DatumType()
+		}
+	}
+
+	// The references of the body to the trigger columns must not be recorded as
+	// outer columns of an enclosing subquery.
+	defer func(enclosing *subquery) { mb.b.subquery = enclosing }(mb.b.subquery)
+	mb.b.subquery = nil
+	bodyScope := mb.b.buildStmt(sel, desiredTypes, triggerScope)
+	bodyScope.removeHiddenCols()
+
+	// Only the first row of the body is used.
+	f := mb.b.factory
+	bodyExpr := f.ConstructLimit(
+		bodyScope.expr,
+		f.ConstructConst(tree.NewDInt(1), types.Int),
+		bodyScope.makeOrderingChoice(),
+	)
+	if event == tree.TriggerUpdate && mb.canaryColID != 0 {
+		mb.outScope.expr = mb.buildUpsertBeforeUpdateTrigger(triggerScope.expr, bodyScope, bodyExpr)
+	} else {
+		mb.outScope.expr = f.ConstructInnerJoinApply(
+			triggerScope.expr, bodyExpr, memo.TrueFilter, memo.EmptyJoinPrivate,
+		)
+	}
+	if event == tree.TriggerDelete {
+		return
+	}
+
+	if len(bodyScope.cols) != len(ords) {
+		panic(errors.WithDetailf(pgerror.New(pgcode.DatatypeMismatch,
+			"returned row structure does not match the structure of the triggering table"),
+			"Number of returned columns (%d) does not match expected column count (%d).",
+			len(bodyScope.cols), len(ords)))
+	}
+
+	// Replace the new values of the row with the columns returned by the body.
+	colIDs := mb.insertColIDs
+	if event == tree.TriggerUpdate {
+		colIDs = mb.updateColIDs
+	}
+	for i, ord := range ords {
+		col := bodyScope.cols[i]
+		col.name = scopeColName(mb.tab.Column(ord).ColName())
+		col.table = mb.alias
+		mb.outScope.cols = append(mb.outScope.cols, col)
+		colIDs[ord] = col.id
+
+		if tabColID := mb.tabID.ColumnID(ord); !mb.targetColSet.Contains(tabColID) {
+			mb.targetColList = append(mb.targetColList, tabColID)
+			mb.targetColSet.Add(tabColID)
+		}
+	}
+	mb.addAssignmentCasts(colIDs)
+
+	// Make the names of the table columns refer to the new values, so that
+	// computed columns are computed from them.
+	mb.disambiguateColumns()
+}
+
+// buildUpsertBeforeUpdateTrigger left-joins the body of the trigger function of
+// a BEFORE UPDATE trigger with the input of an upsert, so that the trigger only
+// fires for the rows that conflict with an existing row. Conflicting rows for
+// which the body returns no rows are skipped. See the comment at the top of the
+// file.
+func (mb *mutationBuilder) buildUpsertBeforeUpdateTrigger(
+	input memo.RelExpr, bodyScope *scope, bodyExpr memo.RelExpr,
+) memo.RelExpr {
+	f := mb.b.factory
+	triggerRowColID := mb.md.AddColumn("trigger_row", types.Bool)
+	bodyExpr = f.ConstructProject(
+		bodyExpr,
+		memo.ProjectionsExpr{f.ConstructProjectionsItem(memo.TrueSingleton, triggerRowColID)},
+		bodyScope.colSet(),
+	)
+	canary := f.ConstructVariable(mb.canaryColID)
+	join := f.ConstructLeftJoinApply(
+		input,
+		bodyExpr,
+		memo.FiltersExpr{f.ConstructFiltersItem(f.ConstructIsNot(canary, memo.NullSingleton))},
+		memo.EmptyJoinPrivate,
+	)
+	return f.ConstructSelect(join, memo.FiltersExpr{f.ConstructFiltersItem(f.ConstructOr(
+		f.ConstructIs(canary, memo.NullSingleton),
+		f.ConstructIsNot(f.ConstructVariable(triggerRowColID), memo.NullSingleton),
+	))})
+}
+
+// buildAfterTriggers adds an FK cascade that executes the AFTER triggers of the
+// mutated table that fire for the given event, in the order of their names.
+// It must be called after the FK checks and cascades of the mutation have been
+// built. See the comment at the top of the file.
+func (mb *mutationBuilder) buildAfterTriggers(event tree.TriggerEvent) {
+	for i, n := 0, mb.tab.TriggerCount(); i < n; i++ {
+		trigger := mb.tab.Trigger(i)
+		if trigger.ActionTime != tree.TriggerAfter || !trigger.HasEvent(event) {
+			continue
+		}
+		udf := mb.resolveTriggerFunction(&trigger)
+		if _, ok := parseTriggerFunctionBody(&trigger, udf).(*tree.Insert); !ok {
+			panic(pgerror.Newf(pgcode.FeatureNotSupported,
+				"AFTER triggers require a trigger function with an INSERT body, found SELECT in %s",
+				trigger.FunctionName))
+		}
+
+		ords := triggerColumnOrdinals(mb.tab)
+		oldCols, newCols := mb.triggerCols(ords, event)
+		upsert := mb.canaryColID != 0
+		if upsert {
+			// The canary column is passed to the cascade as the last old value.
+			oldCols = append(oldCols, mb.canaryColID)
+		}
+		mb.ensureWithID()
+		mb.cascades = append(mb.cascades, memo.FKCascade{
+			FKName: trigger.Name,
+			Builder: &afterTriggerBuilder{
+				mutatedTable: mb.tab,
+				trigger:      trigger,
+				body:         udf.Body,
+				event:        event,
+				upsert:       upsert,
+			},
+			WithID:    mb.withID,
+			OldValues: oldCols,
+			NewValues: newCols,
+		})
+	}
+}
+
+// resolveTriggerFunction resolves the trigger function of the given trigger,
+// which is defined in the schema of the mutated table. The function is added
+// to the metadata, so that the query is planned again if the function is
+// replaced.
+func (mb *mutationBuilder) resolveTriggerFunction(trigger *cat.Trigger) *tree.UDFDefinition {
+	tn, err := mb.b.catalog.FullyQualifiedName(mb.b.ctx, mb.tab)
+	if err != nil {
+		panic(err)
+	}
+	name := tree.NewUnresolvedName(tn.Catalog(), tn.Schema(), trigger.FunctionName)
+	def, err := mb.b.catalog.ResolveFunction(mb.b.ctx, name)
+	if err != nil {
+		panic(err)
+	}
+	if def != nil {
+		for _, impl := range def.Definition {
+			if o := impl.(*tree.Overload); o.UDF != nil && o.UDF.Trigger {
+				mb.md.AddUserDefinedFunction(name, def)
+				return o.UDF
+			}
+		}
+	}
+	panic(pgerror.Newf(pgcode.UndefinedFunction,
+		"trigger function %s() of trigger %q does not exist", trigger.FunctionName, trigger.Name))
+}
+
+// parseTriggerFunctionBody parses the body of the trigger function of the
+// given trigger.
+func parseTriggerFunctionBody(trigger *cat.Trigger, udf *tree.UDFDefinition) tree.Statement {
+	stmt, err := parser.ParseOne(udf.Body)
+	if err != nil {
+		panic(pgerror.Wrapf(err, pgcode.Syntax,
+			"failed to parse the body of function %s", trigger.FunctionName))
+	}
+	return stmt.AST
+}
+
+// triggerColumnOrdinals returns the ordinals of the columns of the table that
+// are referenced by trigger functions through "new" and "old": the visible,
+// non-computed columns of the table.
+func triggerColumnOrdinals(tab cat.Table) []int {
+	var ords []int
+	for i, n := 0, tab.ColumnCount(); i < n; i++ {
+		col := tab.Column(i)
+		if col.Kind() == cat.Ordinary && col.Visibility() == cat.Visible && !col.IsComputed() {
+			ords = append(ords, i)
+		}
+	}
+	return ords
+}
+
+// triggerCols returns the input columns that contain the old and new values of
+// the given table columns for the given event. oldCols is nil for inserts and
+// newCols is nil for deletes.
+func (mb *mutationBuilder) triggerCols(
+	ords []int, event tree.TriggerEvent,
+) (oldCols, newCols opt.ColList) {
+	if event != tree.TriggerInsert {
+		oldCols = make(opt.ColList, len(ords))
+		for i, ord := range ords {
+			oldCols[i] = mb.fetchColIDs[ord]
+		}
+	}
+	switch event {
+	case tree.TriggerInsert:
+		newCols = make(opt.ColList, len(ords))
+		for i, ord := range ords {
+			newCols[i] = mb.insertColIDs[ord]
+		}
+	case tree.TriggerUpdate:
+		newCols = make(opt.ColList, len(ords))
+		for i, ord := range ords {
+			if newCols[i] = mb.updateColIDs[ord]; newCols[i] == 0 {
+				newCols[i] = mb.fetchColIDs[ord]
+			}
+		}
+	}
+	return oldCols, newCols
+}
+
+// buildTriggerScope returns a scope with the "new" and "old" columns of the
+// given table columns, which are built from the given old and new value
+// columns of the input. If oldCols or newCols is nil, the corresponding
+// columns are NULL. The expression of the scope is the input, with all of its
+// columns.
+func (b *Builder) buildTriggerScope(
+	tab cat.Table, ords []int, input memo.RelExpr, oldCols, newCols opt.ColList,
+) *scope {
+	f := b.factory
+	md := f.Metadata()
+	triggerScope := b.allocScope()
+	var projections memo.ProjectionsExpr
+	addCols := func(tabName string, cols opt.ColList) {
+		for i, ord := range ords {
+			tabCol := tab.Column(ord)
+			col := scopeColumn{
+				name:  scopeColName(tabCol.ColName()),
+				table: tree.MakeUnqualifiedTableName(tree.Name(tabName)),
+				typ:   tabCol.DatumType(),
+			}
+			if cols != nil {
+				col.id = cols[i]
+			} else {
+				col.id = md.AddColumn(fmt.Sprintf("%s_%s", tabCol.ColName(), tabName), col.typ)
+				projections = append(projections, f.ConstructProjectionsItem(f.ConstructNull(col.typ), col.id))
+			}
+			triggerScope.cols = append(triggerScope.cols, col)
+		}
+	}
+	addCols("new", newCols)
+	addCols("old", oldCols)
+
+	triggerScope.expr = input
+	if len(projections) > 0 {
+		triggerScope.expr = f.ConstructProject(input, projections, input.Relational().OutputCols)
+	}
+	return triggerScope
+}
+
+// afterTriggerBuilder is a memo.CascadeBuilder implementation for an AFTER
+// trigger. It builds the INSERT statement of the body of the trigger function
+// for each row in the buffered mutation input. See the comment at the top of
+// the file.
+type afterTriggerBuilder struct {
+	mutatedTable cat.Table
+	trigger      cat.Trigger
+	// body is the body of the trigger function, which is an INSERT statement.
+	// It is parsed again every time the cascade is built, since building the
+	// statement can modify its AST.
+	body string
+	// event is the event for which the trigger fires.
+	event tree.TriggerEvent
+	// upsert is true if the trigger fires for the rows of an upsert. The last
+	// old value is then the canary column of the upsert, which is null for the
+	// inserted rows and not null for the updated rows; only the rows for event
+	// are passed to the trigger function.
+	upsert bool
+}
+
+var _ memo.CascadeBuilder = &afterTriggerBuilder{}
+
+// Build is part of the memo.CascadeBuilder interface.
+func (tb *afterTriggerBuilder) Build(
+	ctx context.Context,
+	semaCtx *tree.SemaContext,
+	evalCtx *tree.EvalContext,
+	catalog cat.Catalog,
+	factoryI interface{},
+	binding opt.WithID,
+	bindingProps *props.Relational,
+	oldValues, newValues opt.ColList,
+) (_ memo.RelExpr, err error) {
+	return buildCascadeHelper(ctx, semaCtx, evalCtx, catalog, factoryI, func(b *Builder) memo.RelExpr {
+		ins, ok := parseTriggerFunctionBody(
+			&tb.trigger, &tree.UDFDefinition{Body: tb.body},
+		).(*tree.Insert)
+		if !ok {
+			panic(errors.AssertionFailedf("expected INSERT statement"))
+		}
+
+		f := b.factory
+		md := f.Metadata()
+		ords := triggerColumnOrdinals(tb.mutatedTable)
+		inCols := append(oldValues[:len(oldValues):len(oldValues)], newValues...)
+		outCols := make(opt.ColList, len(inCols))
+		for i := range inCols {
+			colMeta := md.ColumnMeta(inCols[i])
+			outCols[i] = md.AddColumn(colMeta.Alias, colMeta.Type)
+		}
+		md.AddWithBinding(binding, f.ConstructFakeRel(&memo.FakeRelPrivate{
+			Props: bindingProps,
+		}))
+		var input memo.RelExpr = f.ConstructWithScan(&memo.WithScanPrivate{
+			With:    binding,
+			InCols:  inCols,
+			OutCols: outCols,
+			ID:      md.NextUniqueID(),
+		})
+
+		numOld := len(oldValues)
+		if tb.upsert {
+			numOld--
+			canary := f.ConstructVariable(outCols[numOld])
+			var filter opt.ScalarExpr
+			if tb.event == tree.TriggerInsert {
+				filter = f.ConstructIs(canary, memo.NullSingleton)
+			} else {
+				filter = f.ConstructIsNot(canary, memo.NullSingleton)
+			}
+			input = f.ConstructSelect(input, memo.FiltersExpr{f.ConstructFiltersItem(filter)})
+		}
+
+		var oldCols, newCols opt.ColList
+		if numOld > 0 {
+			oldCols = outCols[:numOld]
+		}
+		if len(newValues) > 0 {
+			newCols = outCols[len(oldValues):]
+		}
+		triggerScope := b.buildTriggerScope(tb.mutatedTable, ords, input, oldCols, newCols)
+		return b.buildAfterTriggerInsert(&tb.trigger, ins, triggerScope)
+	})
+}
+
+// buildAfterTriggerInsert builds the INSERT statement of the body of the
+// trigger function of an AFTER trigger. The input rows of the statement are
+// built for each row of the trigger scope. Only simple INSERT statements
+// without subqueries are supported, since the statement is executed as an FK
+// cascade.
+func (b *Builder) buildAfterTriggerInsert(
+	trigger *cat.Trigger, ins *tree.Insert, triggerScope *scope,
+) memo.RelExpr {
+	if ins.With != nil || ins.OnConflict != nil || resultsNeeded(ins.Returning) {
+		panic(unimplemented.Newf("after trigger insert",
+			"only INSERT statements without WITH, ON CONFLICT or RETURNING clauses are "+
+				"supported in the bodies of AFTER trigger functions, found %s in %s",
+			ins, trigger.FunctionName))
+	}
+	if _, err := tree.SimpleStmtVisit(ins, func(e tree.Expr) (bool, tree.Expr, error) {
+		if _, ok := e.(*tree.Subquery); ok {
+			return false, e, unimplemented.Newf("after trigger subquery",
+				"subqueries are not supported in the bodies of AFTER trigger functions, found %s in %s",
+				e, trigger.FunctionName)
+		}
+		return true, e, nil
+	}); err != nil {
+		panic(err)
+	}
+
+	tab, _, alias, refColumns := b.resolveTableForMutation(ins.Table, privilege.INSERT)
+	if refColumns != nil {
+		if len(ins.Columns) != 0 {
+			panic(pgerror.Newf(pgcode.Syntax,
+				"cannot specify both a list of column IDs and a list of column names"))
+		}
+		ins.Columns = make(tree.NameList, len(refColumns))
+		for i, ord := range resolveNumericColumnRefs(tab, refColumns) {
+			ins.Columns[i] = tab.Column(ord).ColName()
+		}
+	}
+	b.checkMultipleMutations(tab, true /* simpleInsert */)
+
+	var mb mutationBuilder
+	mb.init(b, "insert", tab, alias)
+	if len(ins.Columns) != 0 {
+		mb.addTargetNamedColsForInsert(ins.Columns)
+	} else if values := mb.extractValuesInput(ins.Rows); values != nil {
+		mb.addTargetTableColsForInsert(len(values.Rows[0]))
+	}
+	if !ins.DefaultValues() {
+		mb.buildInputForInsert(triggerScope, mb.replaceDefaultExprs(ins.Rows))
+	} else {
+		mb.buildInputForInsert(triggerScope, nil /* rows */)
+	}
+
+	// The input rows reference the trigger columns as outer columns; build them
+	// for each row of the trigger scope.
+	mb.outScope.expr = b.factory.ConstructInnerJoinApply(
+		triggerScope.expr, mb.outScope.expr, memo.TrueFilter, memo.EmptyJoinPrivate,
+	)
+	mb.addSynthesizedColsForInsert()
+	mb.buildInsert(nil /* returning */)
+	return mb.outScope.expr
+}
@@ -0,0 +1,177 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package optbuilder
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// PlanHints is a set of index and join hints which the builder applies to a
+// statement in place of the hints written in the statement. It is used to
+// enforce the plan baseline of a statement fingerprint.
+//
+// Tables and joins are identified by the order in which the builder resolves
+// them, which only depends on the structure of the statement. The same hints
+// therefore apply to every statement with the same fingerprint. The tables and
+// joins of the views referenced by the statement are not part of this order,
+// and a table hint is ignored if it was recorded for a different table, e.g.
+// because a table name now resolves to another table.
+type PlanHints struct {
+	// Tables contains the index hint of each table data source of the
+	// statement, or nil for the tables which are not hinted.
+	Tables []*TableHint `json:"tables,omitempty"`
+
+	// Joins contains the join hint (HASH, MERGE, LOOKUP or INVERTED) of each
+	// join of the statement, or "" for the joins which are not hinted.
+	Joins []string `json:"joins,omitempty"`
+}
+
+// TableHint is the subset of tree.IndexFlags which can be part of PlanHints.
+type TableHint struct {
+	// TableID is the ID of the hinted table, or 0 if the hint applies to any
+	// table.
+	TableID      cat.StableID          `json:"table_id,omitempty"`
+	Index        tree.UnrestrictedName `json:"index,omitempty"`
+	IndexID      tree.IndexID          `json:"index_id,omitempty"`
+	Direction    tree.Direction        `json:"direction,omitempty"`
+	NoIndexJoin  bool                  `json:"no_index_join,omitempty"`
+	NoZigzagJoin bool                  `json:"no_zigzag_join,omitempty"`
+	NoFullScan   bool                  `json:"no_full_scan,omitempty"`
+}
+
+// MakeTableHint returns the TableHint equivalent to the given index flags, or
+// nil if the flags are nil. Flags which do not select a plan, such as
+// IGNORE_FOREIGN_KEYS, and zigzag join hints result in an error.
+func MakeTableHint(flags *tree.IndexFlags) (*TableHint, error) {
+	if flags == nil {
+		return nil, nil
+	}
+	if flags.IgnoreForeignKeys || flags.IgnoreUniqueWithoutIndexKeys || flags.ForceZigzag ||
+		len(flags.ZigzagIndexes) > 0 || len(flags.ZigzagIndexIDs) > 0 {
+		return nil, pgerror.Newf(pgcode.FeatureNotSupported,
+			"index hint %s cannot be part of a plan baseline", tree.AsString(flags))
+	}
+	return &TableHint{
+		Index:        flags.Index,
+		IndexID:      flags.IndexID,
+		Direction:    flags.Direction,
+		NoIndexJoin:  flags.NoIndexJoin,
+		NoZigzagJoin: flags.NoZigzagJoin,
+		NoFullScan:   flags.NoFullScan,
+	}, nil
+}
+
+// indexFlags returns the index flags which replace the given flags of the
+// statement. Flags which are not part of the hint are preserved.
+func (h *TableHint) indexFlags(orig *tree.IndexFlags) *tree.IndexFlags {
+	flags := &tree.IndexFlags{
+		Index:        h.Index,
+		IndexID:      h.IndexID,
+		Direction:    h.Direction,
+		NoIndexJoin:  h.NoIndexJoin,
+		NoZigzagJoin: h.NoZigzagJoin,
+		NoFullScan:   h.NoFullScan,
+	}
+	if orig != nil {
+		flags.IgnoreForeignKeys = orig.IgnoreForeignKeys
+		flags.IgnoreUniqueWithoutIndexKeys = orig.IgnoreUniqueWithoutIndexKeys
+	}
+	return flags
+}
+
+// PlanHintTargets describes the tables and joins of a statement to which
+// PlanHints apply, in the order used by PlanHints.
+type PlanHintTargets struct {
+	Tables []PlanHintTable
+	Joins  []PlanHintJoin
+}
+
+// PlanHintTable describes a table data source of a statement.
+type PlanHintTable struct {
+	// ID identifies the table in the metadata of the memo.
+	ID opt.TableID
+	// TableID is the ID of the table in the catalog.
+	TableID cat.StableID
+	// Flags are the index flags written in the statement, if any.
+	Flags *tree.IndexFlags
+}
+
+// PlanHintJoin describes a join of a statement. The tables of the left input
+// of the join are the tables [LeftStart, RightStart) of PlanHintTargets, and
+// the tables of its right input are the tables [RightStart, RightEnd).
+type PlanHintJoin struct {
+	LeftStart, RightStart, RightEnd int
+	// Hint is the join hint written in the statement, if any.
+	Hint string
+}
+
+// hintTable returns the index flags to use for the next table data source of
+// the statement, given the flags written in the statement.
+func (b *Builder) hintTable(tabMeta *opt.TableMeta, indexFlags *tree.IndexFlags) *tree.IndexFlags {
+	if b.buildingView || len(b.inliningUDFs) > 0 {
+		return indexFlags
+	}
+	ord := b.numHintedTables
+	b.numHintedTables++
+	if b.RecordPlanHintTargets {
+		b.PlanHintTargets.Tables = append(b.PlanHintTargets.Tables, PlanHintTable{
+			ID:      tabMeta.MetaID,
+			TableID: tabMeta.Table.ID(),
+			Flags:   indexFlags,
+		})
+	}
+	if b.PlanHints == nil || ord >= len(b.PlanHints.Tables) {
+		return indexFlags
+	}
+	if h := b.PlanHints.Tables[ord]; h != nil && (h.TableID == 0 || h.TableID == tabMeta.Table.ID()) {
+		return h.indexFlags(indexFlags)
+	}
+	return indexFlags
+}
+
+// startHintJoin reserves the ordinal of the next join of the statement, before
+// its inputs are built.
+func (b *Builder) startHintJoin() (ord int) {
+	if b.buildingView || len(b.inliningUDFs) > 0 {
+		return -1
+	}
+	ord = b.numHintedJoins
+	b.numHintedJoins++
+	if b.RecordPlanHintTargets {
+		b.PlanHintTargets.Joins = append(b.PlanHintTargets.Joins, PlanHintJoin{})
+	}
+	return ord
+}
+
+// hintJoin returns the join hint to use for the join with the given ordinal,
+// given the hint written in the statement. leftStart and rightStart are the
+// number of tables resolved before each input of the join was built.
+func (b *Builder) hintJoin(ord, leftStart, rightStart int, hint string) string {
+	if ord < 0 {
+		return hint
+	}
+	if b.RecordPlanHintTargets {
+		b.PlanHintTargets.Joins[ord] = PlanHintJoin{
+			LeftStart:  leftStart,
+			RightStart: rightStart,
+			RightEnd:   b.numHintedTables,
+			Hint:       hint,
+		}
+	}
+	if b.PlanHints != nil && ord < len(b.PlanHints.Joins) && b.PlanHints.Joins[ord] != "" {
+		return b.PlanHints.Joins[ord]
+	}
+	return hint
+}
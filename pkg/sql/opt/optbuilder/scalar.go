@@ -67,7 +67,8 @@ func (b *Builder) buildScalar(
 			// with a new column ID if they are not contained in the input scope, so
 			// passing in aggOutScope ensures we don't create new column IDs when not
 			// necessary.
-			return b.finishBuildScalarRef(col, inScope.groupby.aggOutScope, outScope, outCol, colRefs)
+			g := inScope.groupby
+			return b.finishBuildScalarRef(g.outputCol(col), g.aggOutScope, outScope, outCol, colRefs)
 		}
 	}
 
@@ -84,7 +85,9 @@ func (b *Builder) buildScalar(
 			// grouping on the entire PK of that table.
 			g := inScope.groupby
 			if !inScope.isOuterColumn(t.id) {
-				if !b.allowImplicitGroupingColumn(t.id, g) {
+				// Columns are not added implicitly to multiple grouping sets, since
+				// they would be NULL in the rows of the sets without the PK.
+				if g.groupingSets != nil || !b.allowImplicitGroupingColumn(t.id, g) {
 					panic(newGroupingError(t.name.ReferenceName()))
 				}
 				// We add a new grouping column; these show up both in aggInScope and
@@ -116,6 +119,9 @@ func (b *Builder) buildScalar(
 	case *windowInfo:
 		return b.finishBuildScalarRef(t.col, inScope, outScope, outCol, colRefs)
 
+	case *tree.GroupingExpr:
+		return b.buildGroupingFunc(t, inScope, outScope, outCol, colRefs)
+
 	case *tree.AndExpr:
 		left := b.buildScalar(tree.ReType(t.TypedLeft(), types.Bool), inScope, nil, nil, colRefs)
 		right := b.buildScalar(tree.ReType(t.TypedRight(), types.Bool), inScope, nil, nil, colRefs)
@@ -165,6 +171,7 @@ func (b *Builder) buildScalar(
 			RequestedCol: inCol,
 		}
 		out = b.factory.ConstructArrayFlatten(s.node, &subqueryPrivate)
+		out = b.remapSubqueryGroupingCols(out, inGroupingContext, inScope)
 
 	case *tree.IndirectionExpr:
 		expr := b.buildScalar(t.Expr.(tree.TypedExpr), inScope, nil, nil, colRefs)
@@ -278,6 +285,7 @@ func (b *Builder) buildScalar(
 			// Perform correctness checks on the outer cols, update colRefs and
 			// b.subquery.outerCols.
 			b.checkSubqueryOuterCols(sub.outerCols, inGroupingContext, inScope, colRefs)
+			out = b.remapSubqueryGroupingCols(out, inGroupingContext, inScope)
 		} else if b.hasSubOperator(t) {
 			// Cases where the RHS is a multi-row subquery were handled above, so this
 			// only handles explicit tuples and arrays.
@@ -402,6 +410,7 @@ func (b *Builder) buildScalar(
 		// Perform correctness checks on the outer cols, update colRefs and
 		// b.subquery.outerCols.
 		b.checkSubqueryOuterCols(t.outerCols, inGroupingContext, inScope, colRefs)
+		out = b.remapSubqueryGroupingCols(out, inGroupingContext, inScope)
 
 	case *tree.Tuple:
 		els := make(memo.ScalarListExpr, len(t.Exprs))
@@ -502,6 +511,11 @@ func (b *Builder) buildFunction(
 		panic(errors.AssertionFailedf("window function should have been replaced"))
 	}
 
+	if f.ResolvedOverload().UDF != nil {
+		out = b.buildUDF(f, def, inScope, colRefs)
+		return b.finishBuildScalar(f, out, inScope, outScope, outCol)
+	}
+
 	args := make(memo.ScalarListExpr, len(f.Exprs))
 	for i, pexpr := range f.Exprs {
 		args[i] = b.buildScalar(pexpr.(tree.TypedExpr), inScope, nil, nil, colRefs)
@@ -623,6 +637,17 @@ func (b *Builder) checkSubqueryOuterCols(
 		b.subquery.outerCols.UnionWith(subqueryOuterCols.Difference(inScopeCols))
 	}
 
+	// The subquery may refer to grouping columns by their IDs in the
+	// pre-projection of the aggregation (see remapSubqueryGroupingCols).
+	var groupedCols opt.ColSet
+	if inGroupingContext {
+		g := inScope.groupby
+		groupedCols = g.aggOutScope.colSet()
+		g.outCols.ForEach(func(from, _ int) {
+			groupedCols.Add(opt.ColumnID(from))
+		})
+	}
+
 	// Check 1 (see function comment).
 	if b.semaCtx.Properties.IsSet(tree.RejectAggregates) && inScope.groupby != nil {
 		aggCols := inScope.groupby.aggregateResultCols()
@@ -636,9 +661,8 @@ func (b *Builder) checkSubqueryOuterCols(
 	// Check 2 (see function comment).
 	if inGroupingContext {
 		subqueryOuterCols.IntersectionWith(inScopeCols)
-		if !subqueryOuterCols.Empty() &&
-			!subqueryOuterCols.SubsetOf(inScope.groupby.aggOutScope.colSet()) {
-			subqueryOuterCols.DifferenceWith(inScope.groupby.aggOutScope.colSet())
+		if !subqueryOuterCols.Empty() && !subqueryOuterCols.SubsetOf(groupedCols) {
+			subqueryOuterCols.DifferenceWith(groupedCols)
 			colID, _ := subqueryOuterCols.Next(0)
 			col := inScope.getColumn(colID)
 			name := col.name.ReferenceName()
@@ -650,6 +674,20 @@ func (b *Builder) checkSubqueryOuterCols(
 	}
 }
 
+// remapSubqueryGroupingCols replaces the references of a subquery built in a
+// grouping context to grouping columns which have different IDs in the output
+// of the aggregation (see groupby.outCols). The subquery refers to the columns
+// of the FROM clause, which are the grouping columns passed through by the
+// pre-projection of the aggregation.
+func (b *Builder) remapSubqueryGroupingCols(
+	out opt.ScalarExpr, inGroupingContext bool, inScope *scope,
+) opt.ScalarExpr {
+	if !inGroupingContext || inScope.groupby.outCols.Empty() {
+		return out
+	}
+	return b.factory.CustomFuncs().RemapCols(out, inScope.groupby.outCols)
+}
+
 func (b *Builder) constructComparison(
 	cmp *tree.ComparisonExpr, left, right opt.ScalarExpr,
 ) opt.ScalarExpr {
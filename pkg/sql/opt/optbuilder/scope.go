@@ -1013,7 +1013,15 @@ func (s *scope) VisitPre(expr tree.Expr) (recurse bool, newExpr tree.Expr) {
 	case *tree.FuncExpr:
 		def, err := t.Func.Resolve(s.builder.semaCtx.SearchPath)
 		if err != nil {
-			panic(err)
+			udfCall, udfDef := s.builder.resolveUDF(t, err)
+			if udfCall == nil {
+				panic(err)
+			}
+			if t.WindowDef != nil || t.Filter != nil || t.Type != 0 || len(t.OrderBy) > 0 {
+				panic(pgerror.Newf(pgcode.WrongObjectType,
+					"%s is not an aggregate or window function", udfDef.Name))
+			}
+			return true, udfCall
 		}
 
 		if isGenerator(def) && s.replaceSRFs {
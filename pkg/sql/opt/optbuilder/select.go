@@ -112,6 +112,7 @@ func (b *Builder) buildDataSource(
 		switch t := ds.(type) {
 		case cat.Table:
 			tabMeta := b.addTable(t, &resName)
+			indexFlags = b.hintTable(tabMeta, indexFlags)
 			return b.buildScan(
 				tabMeta,
 				tableOrdinals(t, columnKinds{
@@ -280,6 +281,10 @@ func (b *Builder) buildView(
 		b.skipSelectPrivilegeChecks = true
 		defer func() { b.skipSelectPrivilegeChecks = false }()
 	}
+	if !b.buildingView {
+		b.buildingView = true
+		defer func() { b.buildingView = false }()
+	}
 	trackDeps := b.trackViewDeps
 	if trackDeps {
 		// We are only interested in the direct dependency on this view descriptor.
@@ -0,0 +1,354 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package optbuilder
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/errors"
+)
+
+// resolveUDF resolves the name of the given function call, which is not the
+// name of a builtin function, to a user-defined function. It returns a copy of
+// the call which references the function definition, or nil if there is no
+// such function. The AST is not modified, since it may be cached and reused
+// after the function is replaced or dropped.
+func (b *Builder) resolveUDF(
+	f *tree.FuncExpr, resolveErr error,
+) (*tree.FuncExpr, *tree.FunctionDefinition) {
+	name, ok := f.Func.FunctionReference.(*tree.UnresolvedName)
+	if !ok || pgerror.GetPGCode(resolveErr) != pgcode.UndefinedFunction {
+		return nil, nil
+	}
+	def, err := b.catalog.ResolveFunction(b.ctx, name)
+	if err != nil {
+		panic(err)
+	}
+	if def == nil {
+		return nil, nil
+	}
+	if b.trackViewDeps {
+		panic(unimplemented.Newf("udf in view",
+			"user-defined function %s cannot be used in a view", def.Name))
+	}
+	b.factory.Metadata().AddUserDefinedFunction(name, def)
+
+	udfCall := *f
+	udfCall.Func = tree.ResolvableFunctionReference{FunctionReference: def}
+	udfCall.Exprs = append(tree.Exprs(nil), f.Exprs...)
+	return &udfCall, def
+}
+
+// buildUDF builds the body of a user-defined SQL function in place of the
+// given call to it. Bodies that consist of a single expression over the
+// arguments are inlined into the calling expression. Other bodies are built as
+// a subquery that is correlated with the arguments, and returns the first row
+// of the body, or NULL if there is none.
+//
+// See Builder.buildStmt for a description of the remaining input and return
+// values.
+func (b *Builder) buildUDF(
+	f *tree.FuncExpr, def *tree.FunctionDefinition, inScope *scope, colRefs *opt.ColSet,
+) opt.ScalarExpr {
+	o := f.ResolvedOverload()
+	if o.UDF.Trigger {
+		panic(pgerror.New(pgcode.FeatureNotSupported,
+			"trigger functions can only be called as triggers"))
+	}
+	fnID := cat.StableID(o.UDF.FunctionID)
+	if err := b.catalog.CheckFunctionPrivilege(b.ctx, fnID, privilege.EXECUTE); err != nil {
+		panic(err)
+	}
+	b.factory.Metadata().AddUserDefinedFunctionCall(fnID)
+	// The function is resolved again for every call, so the overloads are
+	// compared by the ID of their descriptor.
+	key := o.UDF.FunctionID
+	for _, inlining := range b.inliningUDFs {
+		if inlining == key {
+			panic(unimplemented.Newf("recursive udf",
+				"recursive user-defined function %s is not supported", def.Name))
+		}
+	}
+	b.inliningUDFs = append(b.inliningUDFs, key)
+	defer func() { b.inliningUDFs = b.inliningUDFs[:len(b.inliningUDFs)-1] }()
+
+	stmt, err := parser.ParseOne(o.UDF.Body)
+	if err != nil {
+		panic(pgerror.Wrapf(err, pgcode.Syntax,
+			"failed to parse the body of function %s", def.Name))
+	}
+	sel, ok := stmt.AST.(*tree.Select)
+	if !ok {
+		panic(errors.AssertionFailedf("expected SELECT statement"))
+	}
+
+	udf := &udfCall{
+		name:    def.Name,
+		def:     o.UDF,
+		params:  o.Types.(tree.ArgTypes),
+		retType: o.FixedReturnType(),
+		args:    make([]tree.TypedExpr, len(f.Exprs)),
+	}
+	for i := range f.Exprs {
+		udf.args[i] = f.Exprs[i].(tree.TypedExpr)
+	}
+
+	if expr := udf.simpleBody(sel, b.semaCtx.SearchPath); expr != nil {
+		return b.buildSimpleUDF(udf, expr, inScope, colRefs)
+	}
+	return b.buildUDFSubquery(udf, sel, inScope, colRefs)
+}
+
+// udfCall describes a call to a user-defined function that is being inlined.
+type udfCall struct {
+	name    string
+	def     *tree.UDFDefinition
+	params  tree.ArgTypes
+	retType *types.T
+	args    []tree.TypedExpr
+}
+
+// argOrdinal returns the ordinal of the argument referenced by the given name,
+// or -1 if the name does not reference an argument.
+func (u *udfCall) argOrdinal(name *tree.UnresolvedName) int {
+	if name.NumParts != 1 || name.Star {
+		return -1
+	}
+	for i, argName := range u.def.ArgNames {
+		if argName != "" && argName == name.Parts[0] {
+			return i
+		}
+	}
+	return -1
+}
+
+// placeholderArg returns the ordinal of the argument referenced by the given
+// placeholder.
+func (u *udfCall) placeholderArg(p *tree.Placeholder) int {
+	if int(p.Idx) >= len(u.args) {
+		panic(pgerror.Newf(pgcode.UndefinedParameter,
+			"there is no parameter $%d in function %s", p.Idx+1, u.name))
+	}
+	return int(p.Idx)
+}
+
+// simpleBody returns the expression of the body with references to the
+// arguments replaced by the arguments, if the body is a single expression that
+// can be inlined in the calling expression. This is the case if the body has
+// no FROM clause or other clauses, only references the arguments, and does not
+// contain subqueries or aggregate, window or generator functions. Since an
+// argument can be referenced several times, the arguments themselves must be
+// columns, placeholders or constants. Otherwise, simpleBody returns nil.
+func (u *udfCall) simpleBody(sel *tree.Select, searchPath sessiondata.SearchPath) tree.Expr {
+	if sel.With != nil || sel.OrderBy != nil || sel.Limit != nil || sel.Locking != nil {
+		return nil
+	}
+	clause, ok := sel.Select.(*tree.SelectClause)
+	if !ok || clause.Distinct || clause.DistinctOn != nil || len(clause.From.Tables) > 0 ||
+		clause.Where != nil || clause.GroupBy != nil || clause.Having != nil ||
+		clause.Window != nil || clause.TableSelect || len(clause.Exprs) != 1 {
+		return nil
+	}
+	for _, arg := range u.args {
+		switch arg.(type) {
+		case *scopeColumn, *tree.Placeholder, tree.Datum:
+		default:
+			return nil
+		}
+	}
+
+	simple := true
+	expr, err := tree.SimpleVisit(clause.Exprs[0].Expr, func(e tree.Expr) (bool, tree.Expr, error) {
+		if !simple {
+			return false, e, nil
+		}
+		switch t := e.(type) {
+		case *tree.UnresolvedName:
+			if i := u.argOrdinal(t); i >= 0 {
+				return false, u.castArg(i), nil
+			}
+			simple = false
+		case *tree.Placeholder:
+			return false, u.castArg(u.placeholderArg(t)), nil
+		case *tree.Subquery:
+			simple = false
+		case *tree.FuncExpr:
+			// User-defined functions do not resolve here; they are scalar
+			// functions.
+			def, err := t.Func.Resolve(searchPath)
+			if err == nil && def.Class != tree.NormalClass {
+				simple = false
+			}
+		}
+		return simple, e, nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	if !simple {
+		return nil
+	}
+	return expr
+}
+
+// castArg returns the argument with the given ordinal, cast to the type of
+// the corresponding parameter if necessary.
+func (u *udfCall) castArg(i int) tree.TypedExpr {
+	arg, typ := u.args[i], u.params[i].Typ
+	if arg.ResolvedType().Identical(typ) {
+		return arg
+	}
+	return tree.NewTypedCastExpr(arg, typ)
+}
+
+// buildSimpleUDF builds the expression returned by udfCall.simpleBody in the
+// calling expression. Strict functions return NULL if any argument is NULL.
+func (b *Builder) buildSimpleUDF(
+	udf *udfCall, expr tree.Expr, inScope *scope, colRefs *opt.ColSet,
+) opt.ScalarExpr {
+	texpr := inScope.resolveType(expr, udf.retType)
+	out := b.buildScalar(texpr, inScope, nil, nil, colRefs)
+	out = b.castUDFResult(udf, out)
+	if udf.def.CalledOnNullInput || len(udf.args) == 0 {
+		return out
+	}
+	var anyNull opt.ScalarExpr
+	for _, arg := range udf.args {
+		isNull := b.factory.ConstructIs(
+			b.buildScalar(arg, inScope, nil, nil, colRefs), memo.NullSingleton,
+		)
+		if anyNull == nil {
+			anyNull = isNull
+		} else {
+			anyNull = b.factory.ConstructOr(anyNull, isNull)
+		}
+	}
+	whens := memo.ScalarListExpr{
+		b.factory.ConstructWhen(anyNull, b.factory.ConstructNull(udf.retType)),
+	}
+	return b.factory.ConstructCase(memo.TrueSingleton, whens, out)
+}
+
+// buildUDFSubquery builds the body of a user-defined function as a subquery.
+// The arguments are projected as the columns of a single row, which is
+// joined with the body so that the body can reference them as outer columns:
+//
+//   (SELECT body.col::ret FROM (SELECT arg1, arg2) AS args, LATERAL (body) LIMIT 1)
+//
+// Strict functions filter out the row of arguments if any argument is NULL,
+// so that the subquery returns NULL.
+func (b *Builder) buildUDFSubquery(
+	udf *udfCall, sel *tree.Select, inScope *scope, colRefs *opt.ColSet,
+) opt.ScalarExpr {
+	// The arguments are built in the calling scope, which also updates the
+	// outer columns of the enclosing subquery, if any.
+	argScope := b.allocScope()
+	for i := range udf.args {
+		arg := b.buildScalar(udf.args[i], inScope, nil, nil, colRefs)
+		if typ := udf.params[i].Typ; !arg.DataType().Identical(typ) {
+			arg = b.factory.ConstructCast(arg, typ)
+		}
+		name := scopeColName(tree.Name(udf.def.ArgNames[i]))
+		if udf.def.ArgNames[i] == "" {
+			name = name.WithMetadataName(tree.PlaceholderIdx(i).String())
+		}
+		b.synthesizeColumn(argScope, name, udf.params[i].Typ, nil /* expr */, arg)
+	}
+	argScope.expr = b.constructProject(
+		b.factory.ConstructValues(memo.ScalarListWithEmptyTuple, &memo.ValuesPrivate{
+			Cols: opt.ColList{},
+			ID:   b.factory.Metadata().NextUniqueID(),
+		}),
+		argScope.cols,
+	)
+	if !udf.def.CalledOnNullInput && len(argScope.cols) > 0 {
+		filters := make(memo.FiltersExpr, len(argScope.cols))
+		for i := range argScope.cols {
+			filters[i] = b.factory.ConstructFiltersItem(b.factory.ConstructIsNot(
+				b.factory.ConstructVariable(argScope.cols[i].id), memo.NullSingleton,
+			))
+		}
+		argScope.expr = b.factory.ConstructSelect(argScope.expr, filters)
+	}
+
+	// Replace the placeholders of the body with references to the argument
+	// columns.
+	body, err := tree.SimpleStmtVisit(sel, func(e tree.Expr) (bool, tree.Expr, error) {
+		if p, ok := e.(*tree.Placeholder); ok {
+			return false, &argScope.cols[udf.placeholderArg(p)], nil
+		}
+		return true, e, nil
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	// The body cannot reference the columns of the calling query, and its
+	// references to the arguments must not be recorded as outer columns of the
+	// enclosing subquery.
+	defer func(enclosing *subquery) { b.subquery = enclosing }(b.subquery)
+	b.subquery = nil
+	bodyScope := b.buildStmt(body, []*types.T{udf.retType}, argScope)
+	bodyScope.removeHiddenCols()
+	if len(bodyScope.cols) != 1 {
+		panic(errors.WithDetail(pgerror.Newf(pgcode.InvalidFunctionDefinition,
+			"return type mismatch in function %s declared to return %s", udf.name, udf.retType),
+			"Final statement must return exactly one column."))
+	}
+
+	// Only the first row of the body is returned.
+	bodyExpr := b.factory.ConstructLimit(
+		bodyScope.expr,
+		b.factory.ConstructConst(tree.NewDInt(1), types.Int),
+		bodyScope.makeOrderingChoice(),
+	)
+	input := b.factory.ConstructInnerJoinApply(
+		argScope.expr, bodyExpr, memo.TrueFilter, memo.EmptyJoinPrivate,
+	)
+	outScope := argScope.push()
+	result := b.castUDFResult(udf, b.factory.ConstructVariable(bodyScope.cols[0].id))
+	outCol := b.synthesizeColumn(
+		outScope, scopeColName(tree.Name(udf.name)), udf.retType, nil /* expr */, result,
+	)
+	input = b.constructProject(input, []scopeColumn{*outCol})
+
+	return b.factory.ConstructSubquery(input, &memo.SubqueryPrivate{
+		OriginalExpr: &tree.Subquery{Select: &tree.ParenSelect{Select: sel}},
+	})
+}
+
+// castUDFResult casts the result of the body of a user-defined function to
+// the return type of the function, if necessary. Only assignment casts are
+// allowed, as in Postgres.
+func (b *Builder) castUDFResult(udf *udfCall, result opt.ScalarExpr) opt.ScalarExpr {
+	typ := result.DataType()
+	if typ.Identical(udf.retType) {
+		return result
+	}
+	if typ.Family() == types.UnknownFamily {
+		return b.factory.ConstructNull(udf.retType)
+	}
+	if !tree.ValidCast(typ, udf.retType, tree.CastContextAssignment) {
+		panic(errors.WithDetailf(pgerror.Newf(pgcode.InvalidFunctionDefinition,
+			"return type mismatch in function %s declared to return %s", udf.name, udf.retType),
+			"Actual return type is %s.", typ))
+	}
+	return b.factory.ConstructAssignmentCast(result, udf.retType)
+}
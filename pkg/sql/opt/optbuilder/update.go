@@ -313,6 +313,10 @@ func (mb *mutationBuilder) addSynthesizedColsForUpdate() {
 	// Add assignment casts for default column values.
 	mb.addAssignmentCasts(mb.updateColIDs)
 
+	// Build the BEFORE UPDATE triggers, which can change the values of the
+	// non-computed columns.
+	mb.buildBeforeTriggers(tree.TriggerUpdate)
+
 	// Disambiguate names so that references in the computed expression refer to
 	// the correct columns.
 	mb.disambiguateColumns()
@@ -346,6 +350,8 @@ func (mb *mutationBuilder) buildUpdate(returning tree.ReturningExprs) {
 
 	mb.buildFKChecksForUpdate()
 
+	mb.buildAfterTriggers(tree.TriggerUpdate)
+
 	private := mb.makeMutationPrivate(returning != nil)
 	for _, col := range mb.extraAccessibleCols {
 		if col.id != 0 {
@@ -0,0 +1,111 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package props
+
+import (
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc/keyside"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+)
+
+// MultiColHistogram captures the distribution of values for a list of columns
+// within a table. The upper bounds of the buckets are the concatenated
+// ascending key encodings of the column values, in the order of the columns,
+// so the buckets are ordered like the tuples of values. Rows in which any of
+// the columns is NULL are not included in the histogram.
+//
+// Unlike Histogram, a MultiColHistogram cannot be filtered; it is only used to
+// estimate the number of rows in which all of its columns are equal to
+// constant values.
+// MultiColHistograms are immutable.
+type MultiColHistogram struct {
+	cols    opt.ColList
+	buckets []cat.HistogramBucket
+}
+
+// Init initializes the histogram with data from the catalog. The upper bounds
+// of the buckets must be DBytes key encodings of the values of cols.
+func (h *MultiColHistogram) Init(cols opt.ColList, buckets []cat.HistogramBucket) {
+	// This initialization pattern ensures that fields are not unwittingly
+	// reused. Field reuse must be explicit.
+	*h = MultiColHistogram{
+		cols:    cols,
+		buckets: buckets,
+	}
+}
+
+// Columns returns the columns of the histogram, in the order in which their
+// values are encoded in the bucket upper bounds.
+func (h *MultiColHistogram) Columns() opt.ColList {
+	return h.cols
+}
+
+// BucketCount returns the number of buckets in the histogram.
+func (h *MultiColHistogram) BucketCount() int {
+	return len(h.buckets)
+}
+
+// ValuesCount returns the total number of values in the histogram.
+func (h *MultiColHistogram) ValuesCount() float64 {
+	var count float64
+	for i := range h.buckets {
+		count += h.buckets[i].NumRange
+		count += h.buckets[i].NumEq
+	}
+	return count
+}
+
+// EqValuesCount returns the estimated number of rows in which the columns of
+// the histogram are equal to the given values. vals must contain one value for
+// each column, in the order of Columns. The second return value is false if
+// the estimate cannot be made from the histogram, e.g. because one of the
+// values is NULL.
+func (h *MultiColHistogram) EqValuesCount(vals tree.Datums) (float64, bool) {
+	if len(vals) != len(h.cols) {
+		return 0, false
+	}
+	var key []byte
+	for _, val := range vals {
+		if val == tree.DNull {
+			return 0, false
+		}
+		var err error
+		if key, err = keyside.Encode(key, val, encoding.Ascending); err != nil {
+			return 0, false
+		}
+	}
+	k := string(key)
+
+	// Find the first bucket with an upper bound greater than or equal to the
+	// key.
+	i := sort.Search(len(h.buckets), func(i int) bool {
+		upperBound, ok := h.buckets[i].UpperBound.(*tree.DBytes)
+		return ok && string(*upperBound) >= k
+	})
+	if i == len(h.buckets) {
+		// The key is greater than all the values in the histogram.
+		return 0, true
+	}
+	b := &h.buckets[i]
+	if string(*b.UpperBound.(*tree.DBytes)) == k {
+		return b.NumEq, true
+	}
+	if b.DistinctRange < 1 {
+		return 0, true
+	}
+	// Assume that the values in the range of the bucket are uniformly
+	// distributed.
+	return b.NumRange / b.DistinctRange, true
+}
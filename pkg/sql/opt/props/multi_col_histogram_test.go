@@ -0,0 +1,83 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package props
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc/keyside"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+)
+
+func TestMultiColHistogramEqValuesCount(t *testing.T) {
+	makeKey := func(a int, b string) tree.Datum {
+		key, err := keyside.Encode(nil, tree.NewDInt(tree.DInt(a)), encoding.Ascending)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if key, err = keyside.Encode(key, tree.NewDString(b), encoding.Ascending); err != nil {
+			t.Fatal(err)
+		}
+		return tree.NewDBytes(tree.DBytes(key))
+	}
+
+	//   0  1  3  3   4  5   0  0   40  35
+	// <--- (1, 'a') --- (1, 'c') --- (2, 'a') --- (3, 'x')
+	h := &MultiColHistogram{}
+	h.Init(opt.ColList{1, 2}, []cat.HistogramBucket{
+		{NumRange: 0, DistinctRange: 0, NumEq: 1, UpperBound: makeKey(1, "a")},
+		{NumRange: 3, DistinctRange: 3, NumEq: 5, UpperBound: makeKey(1, "c")},
+		{NumRange: 0, DistinctRange: 0, NumEq: 4, UpperBound: makeKey(2, "a")},
+		{NumRange: 40, DistinctRange: 10, NumEq: 35, UpperBound: makeKey(3, "x")},
+	})
+
+	if count := h.ValuesCount(); count != 88 {
+		t.Fatalf("expected 88 values, got %v", count)
+	}
+
+	testData := []struct {
+		a        int
+		b        string
+		expected float64
+	}{
+		{a: 0, b: "z", expected: 0},
+		{a: 1, b: "a", expected: 1},
+		{a: 1, b: "b", expected: 1},
+		{a: 1, b: "c", expected: 5},
+		{a: 2, b: "a", expected: 4},
+		{a: 2, b: "b", expected: 4},
+		{a: 3, b: "x", expected: 35},
+		{a: 3, b: "y", expected: 0},
+		{a: 4, b: "a", expected: 0},
+	}
+
+	for i, tc := range testData {
+		count, ok := h.EqValuesCount(tree.Datums{
+			tree.NewDInt(tree.DInt(tc.a)), tree.NewDString(tc.b),
+		})
+		if !ok {
+			t.Fatalf("test case %d: expected an estimate", i)
+		}
+		if count != tc.expected {
+			t.Fatalf("test case %d: expected %v but found %v", i, tc.expected, count)
+		}
+	}
+
+	if _, ok := h.EqValuesCount(tree.Datums{tree.NewDInt(1), tree.DNull}); ok {
+		t.Fatalf("expected no estimate for a NULL value")
+	}
+	if _, ok := h.EqValuesCount(tree.Datums{tree.NewDInt(1)}); ok {
+		t.Fatalf("expected no estimate for a prefix of the columns")
+	}
+}
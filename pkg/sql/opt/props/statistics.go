@@ -220,6 +220,11 @@ type ColumnStatistic struct {
 	// the approximate distribution of values for that column, represented
 	// by a slice of histogram buckets.
 	Histogram *Histogram
+
+	// MultiColHistogram is only used when the size of Cols is greater than one,
+	// and only in the statistics of a table. It contains the approximate
+	// distribution of the tuples of values of the columns.
+	MultiColHistogram *MultiColHistogram
 }
 
 // ApplySelectivity updates the distinct count, null count, and histogram
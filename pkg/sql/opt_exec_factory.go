@@ -1869,6 +1869,17 @@ func (ef *execFactory) ConstructErrorIfRows(
 	}, nil
 }
 
+// ConstructDeferredCheck is part of the exec.Factory interface.
+func (ef *execFactory) ConstructDeferredCheck(
+	input exec.Node, check *exec.DeferredCheck,
+) (exec.Node, error) {
+	return &deferredCheckNode{
+		plan:       input.(planNode),
+		constraint: makeDeferredConstraint(check),
+		keyCols:    check.KeyCols,
+	}, nil
+}
+
 // ConstructOpaque is part of the exec.Factory interface.
 func (ef *execFactory) ConstructOpaque(metadata opt.OpaqueMetadata) (exec.Node, error) {
 	return constructOpaque(metadata)
@@ -651,6 +651,9 @@ func (u *sqlSymUnion) transactionModes() tree.TransactionModes {
 func (u *sqlSymUnion) compositeKeyMatchMethod() tree.CompositeKeyMatchMethod {
   return u.val.(tree.CompositeKeyMatchMethod)
 }
+func (u *sqlSymUnion) constraintDeferrability() tree.ConstraintDeferrability {
+  return u.val.(tree.ConstraintDeferrability)
+}
 func (u *sqlSymUnion) referenceAction() tree.ReferenceAction {
     return u.val.(tree.ReferenceAction)
 }
@@ -660,6 +663,33 @@ func (u *sqlSymUnion) referenceActions() tree.ReferenceActions {
 func (u *sqlSymUnion) createStatsOptions() *tree.CreateStatsOptions {
     return u.val.(*tree.CreateStatsOptions)
 }
+func (u *sqlSymUnion) funcArg() tree.FuncArg {
+    return u.val.(tree.FuncArg)
+}
+func (u *sqlSymUnion) funcArgs() tree.FuncArgs {
+    return u.val.(tree.FuncArgs)
+}
+func (u *sqlSymUnion) functionOption() tree.FunctionOption {
+    return u.val.(tree.FunctionOption)
+}
+func (u *sqlSymUnion) functionOptions() tree.FunctionOptions {
+    return u.val.(tree.FunctionOptions)
+}
+func (u *sqlSymUnion) triggerActionTime() tree.TriggerActionTime {
+    return u.val.(tree.TriggerActionTime)
+}
+func (u *sqlSymUnion) triggerEvent() tree.TriggerEvent {
+    return u.val.(tree.TriggerEvent)
+}
+func (u *sqlSymUnion) triggerEvents() tree.TriggerEvents {
+    return u.val.(tree.TriggerEvents)
+}
+func (u *sqlSymUnion) funcObj() tree.FuncObj {
+    return u.val.(tree.FuncObj)
+}
+func (u *sqlSymUnion) funcObjs() tree.FuncObjs {
+    return u.val.(tree.FuncObjs)
+}
 func (u *sqlSymUnion) scrubOptions() tree.ScrubOptions {
     return u.val.(tree.ScrubOptions)
 }
@@ -764,7 +794,7 @@ func (u *sqlSymUnion) setVar() *tree.SetVar {
 %token <str> BUCKET_COUNT
 %token <str> BOOLEAN BOTH BOX2D BUNDLE BY
 
-%token <str> CACHE CANCEL CANCELQUERY CASCADE CASE CAST CBRT CHANGEFEED CHAR
+%token <str> CACHE CALLED CANCEL CANCELQUERY CASCADE CASE CAST CBRT CHANGEFEED CHAR
 %token <str> CHARACTER CHARACTERISTICS CHECK CLOSE
 %token <str> CLUSTER COALESCE COLLATE COLLATION COLUMN COLUMNS COMMENT COMMENTS COMMIT
 %token <str> COMMITTED COMPACT COMPLETE CONCAT CONCURRENTLY CONFIGURATION CONFIGURATIONS CONFIGURE
@@ -778,15 +808,15 @@ func (u *sqlSymUnion) setVar() *tree.SetVar {
 %token <str> DEALLOCATE DECLARE DEFERRABLE DEFERRED DELETE DELIMITER DESC DESTINATION DETACHED
 %token <str> DISCARD DISTINCT DO DOMAIN DOUBLE DROP
 
-%token <str> ELSE ENCODING ENCRYPTED ENCRYPTION_PASSPHRASE END ENUM ENUMS ESCAPE EXCEPT EXCLUDE EXCLUDING
+%token <str> EACH ELSE ENCODING ENCRYPTED ENCRYPTION_PASSPHRASE END ENUM ENUMS ESCAPE EXCEPT EXCLUDE EXCLUDING
 %token <str> EXISTS EXECUTE EXECUTION EXPERIMENTAL
 %token <str> EXPERIMENTAL_FINGERPRINTS EXPERIMENTAL_REPLICA
 %token <str> EXPERIMENTAL_AUDIT EXPERIMENTAL_RELOCATE
-%token <str> EXPIRATION EXPLAIN EXPORT EXTENSION EXTRACT EXTRACT_DURATION
+%token <str> EXPIRATION EXPLAIN EXPORT EXTENSION EXTRACT EXTRACT_DURATION EXTREMES
 
 %token <str> FAILURE FALSE FAMILY FETCH FETCHVAL FETCHTEXT FETCHVAL_PATH FETCHTEXT_PATH
 %token <str> FILES FILTER
-%token <str> FIRST FLOAT FLOAT4 FLOAT8 FLOORDIV FOLLOWING FOR FORCE FORCE_INDEX FORCE_ZIGZAG FOREIGN FROM FULL FUNCTION FUNCTIONS
+%token <str> FIRST FLOAT FLOAT4 FLOAT8 FLOORDIV FOLLOWING FOR FORCE FORCE_INDEX FORCE_ZIGZAG FORECAST FOREIGN FROM FULL FUNCTION FUNCTIONS
 
 %token <str> GENERATED GEOGRAPHY GEOMETRY GEOMETRYM GEOMETRYZ GEOMETRYZM
 %token <str> GEOMETRYCOLLECTION GEOMETRYCOLLECTIONM GEOMETRYCOLLECTIONZ GEOMETRYCOLLECTIONZM
@@ -795,11 +825,11 @@ func (u *sqlSymUnion) setVar() *tree.SetVar {
 %token <str> HAVING HASH HIGH HISTOGRAM HOUR
 
 %token <str> IDENTITY
-%token <str> IF IFERROR IFNULL IGNORE_FOREIGN_KEYS ILIKE IMMEDIATE IMPORT IN INCLUDE
+%token <str> IF IFERROR IFNULL IGNORE_FOREIGN_KEYS ILIKE IMMEDIATE IMMUTABLE IMPORT IN INCLUDE
 %token <str> INCLUDING INCREMENT INCREMENTAL INCREMENTAL_STORAGE
 %token <str> INET INET_CONTAINED_BY_OR_EQUALS
 %token <str> INET_CONTAINS_OR_EQUALS INDEX INDEXES INHERITS INJECT INITIALLY
-%token <str> INNER INSERT INT INTEGER
+%token <str> INNER INPUT INSERT INT INTEGER
 %token <str> INTERSECT INTERVAL INTO INTO_DB INVERTED IS ISERROR ISNULL ISOLATION
 
 %token <str> JOB JOBS JOIN JSON JSONB JSON_SOME_EXISTS JSON_ALL_EXISTS
@@ -827,14 +857,14 @@ func (u *sqlSymUnion) setVar() *tree.SetVar {
 %token <str> PARENT PARTIAL PARTITION PARTITIONS PASSWORD PAUSE PAUSED PHYSICAL PLACEMENT PLACING
 %token <str> PLAN PLANS POINT POINTM POINTZ POINTZM POLYGON POLYGONM POLYGONZ POLYGONZM
 %token <str> POSITION PRECEDING PRECISION PREPARE PRESERVE PRIMARY PRIORITY PRIVILEGES
-%token <str> PROCEDURAL PUBLIC PUBLICATION
+%token <str> PROCEDURAL PROCEDURE PUBLIC PUBLICATION
 
 %token <str> QUERIES QUERY
 
 %token <str> RANGE RANGES READ REAL REASON REASSIGN RECURSIVE RECURRING REF REFERENCES REFRESH
 %token <str> REGCLASS REGION REGIONAL REGIONS REGNAMESPACE REGPROC REGPROCEDURE REGROLE REGTYPE REINDEX
 %token <str> RELOCATE REMOVE_PATH RENAME REPEATABLE REPLACE REPLICATION
-%token <str> RELEASE RESET RESTORE RESTRICT RESTRICTED RESUME RETURNING RETRY REVISION_HISTORY
+%token <str> RELEASE RESET RESTORE RESTRICT RESTRICTED RESUME RETURNING RETURNS RETRY REVISION_HISTORY
 %token <str> REVOKE RIGHT ROLE ROLES ROLLBACK ROLLUP ROUTINES ROW ROWS RSHIFT RULE RUNNING
 
 %token <str> SAVEPOINT SCANS SCATTER SCHEDULE SCHEDULES SCHEMA SCHEMAS SCRUB SEARCH SECOND SELECT SEQUENCE SEQUENCES
@@ -843,7 +873,7 @@ func (u *sqlSymUnion) setVar() *tree.SetVar {
 %token <str> SKIP_MISSING_SEQUENCES SKIP_MISSING_SEQUENCE_OWNERS SKIP_MISSING_VIEWS SMALLINT SMALLSERIAL SNAPSHOT SOME SPLIT SQL
 %token <str> SQLLOGIN
 
-%token <str> START STATISTICS STATUS STDIN STREAM STRICT STRING STORAGE STORE STORED STORING SUBSTRING
+%token <str> STABLE START STATISTICS STATUS STDIN STREAM STRICT STRING STORAGE STORE STORED STORING SUBSTRING
 %token <str> SURVIVE SURVIVAL SYMMETRIC SYNTAX SYSTEM SQRT SUBSCRIPTION STATEMENTS
 
 %token <str> TABLE TABLES TABLESPACE TEMP TEMPLATE TEMPORARY TENANT TESTING_RELOCATE TEXT THEN
@@ -855,7 +885,7 @@ func (u *sqlSymUnion) setVar() *tree.SetVar {
 %token <str> UNBOUNDED UNCOMMITTED UNION UNIQUE UNKNOWN UNLOGGED UNSPLIT
 %token <str> UPDATE UPSERT UNTIL USE USER USERS USING UUID
 
-%token <str> VALID VALIDATE VALUE VALUES VARBIT VARCHAR VARIADIC VIEW VARYING VIEWACTIVITY VIEWACTIVITYREDACTED VIRTUAL VISIBLE VOTERS
+%token <str> VALID VALIDATE VALUE VALUES VARBIT VARCHAR VARIADIC VIEW VARYING VIEWACTIVITY VIEWACTIVITYREDACTED VIRTUAL VISIBLE VOLATILE VOTERS
 
 %token <str> WHEN WHERE WINDOW WITH WITHIN WITHOUT WORK WRITE
 
@@ -988,9 +1018,22 @@ func (u *sqlSymUnion) setVar() *tree.SetVar {
 %type <tree.Statement> create_index_stmt
 %type <tree.Statement> create_role_stmt
 %type <tree.Statement> create_schedule_for_backup_stmt
+%type <tree.Statement> create_schedule_for_changefeed_stmt
+%type <tree.Statement> create_schedule_for_export_stmt
+%type <tree.Statement> create_schedule_for_sql_stmt
 %type <tree.Statement> create_schema_stmt
 %type <tree.Statement> create_table_stmt
 %type <tree.Statement> create_table_as_stmt
+%type <tree.Statement> create_publication_stmt
+%type <tree.Statement> create_function_stmt
+%type <tree.FuncArg> func_arg
+%type <tree.FuncArgs> func_arg_list opt_func_arg_list
+%type <tree.FunctionOption> create_func_opt_item
+%type <tree.FunctionOptions> opt_create_func_opt_list create_func_opt_list
+%type <tree.Statement> create_trigger_stmt
+%type <tree.TriggerActionTime> trigger_action_time
+%type <tree.TriggerEvent> trigger_event
+%type <tree.TriggerEvents> trigger_event_list
 %type <tree.Statement> create_view_stmt
 %type <tree.Statement> create_sequence_stmt
 
@@ -1010,6 +1053,11 @@ func (u *sqlSymUnion) setVar() *tree.SetVar {
 %type <tree.Statement> drop_role_stmt
 %type <tree.Statement> drop_schema_stmt
 %type <tree.Statement> drop_table_stmt
+%type <tree.Statement> drop_publication_stmt
+%type <tree.Statement> drop_function_stmt
+%type <tree.FuncObj> func_obj
+%type <tree.FuncObjs> func_obj_list
+%type <tree.Statement> drop_trigger_stmt
 %type <tree.Statement> drop_type_stmt
 %type <tree.Statement> drop_view_stmt
 %type <tree.Statement> drop_sequence_stmt
@@ -1049,6 +1097,7 @@ func (u *sqlSymUnion) setVar() *tree.SetVar {
 %type <tree.Statement> set_session_stmt
 %type <tree.Statement> set_csetting_stmt
 %type <tree.Statement> set_transaction_stmt
+%type <tree.Statement> set_constraints_stmt
 %type <tree.Statement> set_exprs_internal
 %type <tree.Statement> generic_set
 %type <tree.Statement> set_rest_more
@@ -1352,6 +1401,7 @@ func (u *sqlSymUnion) setVar() *tree.SetVar {
 %type <tree.NamedColumnQualification> col_qualification create_as_col_qualification
 %type <tree.ColumnQualification> col_qualification_elem create_as_col_qualification_elem
 %type <tree.CompositeKeyMatchMethod> key_match
+%type <tree.ConstraintDeferrability> opt_deferrable
 %type <tree.ReferenceActions> reference_actions
 %type <tree.ReferenceAction> reference_action reference_on_delete reference_on_update
 
@@ -2885,6 +2935,117 @@ create_schedule_for_backup_stmt:
   }
  | CREATE SCHEDULE error  // SHOW HELP: CREATE SCHEDULE FOR BACKUP
 
+// %Help: CREATE SCHEDULE FOR CHANGEFEED - export table data periodically
+// %Category: CCL
+// %Text:
+// CREATE SCHEDULE [IF NOT EXISTS]
+// [<description>]
+// FOR CHANGEFEED <targets> INTO <sink>
+// [WITH <option>[=<value>] [, ...]]
+// RECURRING [crontab|NEVER]
+// [WITH SCHEDULE OPTIONS <schedule_option>[= <value>] [, ...] ]
+//
+// Each execution of the schedule runs a changefeed which performs an initial
+// scan of the targets, writes it to the sink and then completes. Only cloud
+// storage sinks are supported.
+//
+// Targets:
+//   TABLE <pattern> [, ...]: comma separated list of tables to export.
+//
+// WITH <options>:
+//   Options specific to CREATE CHANGEFEED: See CREATE CHANGEFEED options
+//
+// RECURRING <crontab>:
+//   Schedule specified as a string in crontab format. All times in UTC.
+//
+// SCHEDULE OPTIONS:
+//   See CREATE SCHEDULE FOR BACKUP for the supported schedule options.
+//
+// %SeeAlso: CREATE CHANGEFEED, CREATE SCHEDULE FOR BACKUP
+create_schedule_for_changefeed_stmt:
+  CREATE SCHEDULE /*$3=*/schedule_label_spec FOR CHANGEFEED /*$6=*/changefeed_targets
+  INTO /*$8=*/string_or_placeholder /*$9=*/opt_with_options
+  /*$10=*/cron_expr /*$11=*/opt_with_schedule_options
+  {
+    $$.val = &tree.ScheduledChangefeed{
+      ScheduleLabelSpec: *($3.scheduleLabelSpec()),
+      Recurrence:        $10.expr(),
+      Changefeed:        &tree.CreateChangefeed{
+        Targets: $6.targetList(),
+        SinkURI: $8.expr(),
+        Options: $9.kvOptions(),
+      },
+      ScheduleOptions: $11.kvOptions(),
+    }
+  }
+| CREATE SCHEDULE schedule_label_spec FOR CHANGEFEED error  // SHOW HELP: CREATE SCHEDULE FOR CHANGEFEED
+
+// %Help: CREATE SCHEDULE FOR EXPORT - export query results periodically
+// %Category: CCL
+// %Text:
+// CREATE SCHEDULE [IF NOT EXISTS]
+// [<description>]
+// FOR EXPORT INTO <format> <datafile> [WITH <option> [= value] [,...]] FROM (<query>)
+// RECURRING [crontab|NEVER]
+// [WITH SCHEDULE OPTIONS <schedule_option>[= <value>] [, ...] ]
+//
+// Each execution of the schedule runs the EXPORT statement as the owner of
+// the schedule. The query must be enclosed in parentheses.
+//
+// RECURRING <crontab>:
+//   Schedule specified as a string in crontab format. All times in UTC.
+//
+// SCHEDULE OPTIONS:
+//   See CREATE SCHEDULE FOR BACKUP for the supported schedule options.
+//
+// %SeeAlso: EXPORT, CREATE SCHEDULE FOR BACKUP
+create_schedule_for_export_stmt:
+  CREATE SCHEDULE /*$3=*/schedule_label_spec FOR EXPORT INTO /*$7=*/import_format
+  /*$8=*/string_or_placeholder /*$9=*/opt_with_options FROM /*$11=*/select_with_parens
+  /*$12=*/cron_expr /*$13=*/opt_with_schedule_options
+  {
+    $$.val = &tree.ScheduledExport{
+      ScheduleLabelSpec: *($3.scheduleLabelSpec()),
+      Recurrence:        $12.expr(),
+      Export:            &tree.Export{Query: &tree.Select{Select: $11.selectStmt()}, FileFormat: $7, File: $8.expr(), Options: $9.kvOptions()},
+      ScheduleOptions:   $13.kvOptions(),
+    }
+  }
+| CREATE SCHEDULE schedule_label_spec FOR EXPORT error  // SHOW HELP: CREATE SCHEDULE FOR EXPORT
+
+// %Help: CREATE SCHEDULE FOR SQL - execute a SQL statement periodically
+// %Category: Misc
+// %Text:
+// CREATE SCHEDULE [IF NOT EXISTS]
+// [<description>]
+// FOR SQL <statement>
+// RECURRING [crontab|NEVER]
+// [WITH SCHEDULE OPTIONS <schedule_option>[= <value>] [, ...] ]
+//
+// Each execution of the schedule runs the statement, given as a string, as
+// the owner of the schedule in the current database. Executions are recorded
+// as jobs, which can be inspected with SHOW JOBS FOR SCHEDULE.
+//
+// RECURRING <crontab>:
+//   Schedule specified as a string in crontab format. All times in UTC.
+//
+// SCHEDULE OPTIONS:
+//   See CREATE SCHEDULE FOR BACKUP for the supported schedule options.
+//
+// %SeeAlso: SHOW SCHEDULES, SHOW JOBS, CREATE SCHEDULE FOR BACKUP
+create_schedule_for_sql_stmt:
+  CREATE SCHEDULE /*$3=*/schedule_label_spec FOR SQL /*$6=*/string_or_placeholder
+  /*$7=*/cron_expr /*$8=*/opt_with_schedule_options
+  {
+    $$.val = &tree.ScheduledSQL{
+      ScheduleLabelSpec: *($3.scheduleLabelSpec()),
+      Recurrence:        $7.expr(),
+      Statement:         $6.expr(),
+      ScheduleOptions:   $8.kvOptions(),
+    }
+  }
+| CREATE SCHEDULE schedule_label_spec FOR SQL error  // SHOW HELP: CREATE SCHEDULE FOR SQL
+
 // sconst_or_placeholder matches a simple string, or a placeholder.
 sconst_or_placeholder:
   SCONST
@@ -2958,6 +3119,9 @@ opt_with_schedule_options:
 // RESTORE <targets...> FROM <location...>
 //         [ AS OF SYSTEM TIME <expr> ]
 //         [ WITH <option> [= <value>] [, ...] ]
+// RESTORE TABLE <tablename> AS <newtablename> FROM <location...>
+//         [ AS OF SYSTEM TIME <expr> ]
+//         [ WITH <option> [= <value>] [, ...] ]
 //
 // Targets:
 //    TABLE <pattern> [, ...]
@@ -3018,6 +3182,27 @@ restore_stmt:
       Options: *($8.restoreOptions()),
     }
   }
+| RESTORE TABLE table_name AS table_name FROM list_of_string_or_placeholder_opt_list opt_as_of_clause opt_with_restore_options
+  {
+    $$.val = &tree.Restore{
+      Targets: tree.TargetList{Tables: tree.TablePatterns{$3.unresolvedObjectName().ToUnresolvedName()}},
+      AsTableName: $5.unresolvedObjectName(),
+      From: $7.listOfStringOrPlaceholderOptList(),
+      AsOf: $8.asOfClause(),
+      Options: *($9.restoreOptions()),
+    }
+  }
+| RESTORE TABLE table_name AS table_name FROM string_or_placeholder IN list_of_string_or_placeholder_opt_list opt_as_of_clause opt_with_restore_options
+  {
+    $$.val = &tree.Restore{
+      Targets: tree.TargetList{Tables: tree.TablePatterns{$3.unresolvedObjectName().ToUnresolvedName()}},
+      AsTableName: $5.unresolvedObjectName(),
+      Subdir: $7.expr(),
+      From: $9.listOfStringOrPlaceholderOptList(),
+      AsOf: $10.asOfClause(),
+      Options: *($11.restoreOptions()),
+    }
+  }
 | RESTORE targets FROM REPLICATION STREAM FROM string_or_placeholder_opt_list opt_as_of_clause
   {
    $$.val = &tree.StreamIngestion{
@@ -3530,7 +3715,10 @@ comment_stmt:
     $$.val = &tree.CommentOnConstraint{Constraint:tree.Name($4), Table: $6.unresolvedObjectName(), Comment: $8.strPtr()}
   }
 | COMMENT ON EXTENSION error { return unimplemented(sqllex, "comment on extension") }
-| COMMENT ON FUNCTION error { return unimplementedWithIssueDetail(sqllex, 17511, "comment on function") }
+| COMMENT ON FUNCTION func_obj IS comment_text
+  {
+    $$.val = &tree.CommentOnFunction{Function: $4.funcObj(), Comment: $6.strPtr()}
+  }
 
 comment_text:
   SCONST
@@ -3549,12 +3737,15 @@ comment_text:
 // %Text:
 // CREATE DATABASE, CREATE TABLE, CREATE INDEX, CREATE TABLE AS,
 // CREATE USER, CREATE VIEW, CREATE SEQUENCE, CREATE STATISTICS,
-// CREATE ROLE, CREATE TYPE, CREATE EXTENSION
+// CREATE ROLE, CREATE TYPE, CREATE EXTENSION, CREATE PUBLICATION
 create_stmt:
   create_role_stmt     // EXTEND WITH HELP: CREATE ROLE
 | create_ddl_stmt      // help texts in sub-rule
 | create_stats_stmt    // EXTEND WITH HELP: CREATE STATISTICS
 | create_schedule_for_backup_stmt   // EXTEND WITH HELP: CREATE SCHEDULE FOR BACKUP
+| create_schedule_for_changefeed_stmt   // EXTEND WITH HELP: CREATE SCHEDULE FOR CHANGEFEED
+| create_schedule_for_export_stmt   // EXTEND WITH HELP: CREATE SCHEDULE FOR EXPORT
+| create_schedule_for_sql_stmt   // EXTEND WITH HELP: CREATE SCHEDULE FOR SQL
 | create_changefeed_stmt
 | create_replication_stream_stmt
 | create_extension_stmt  // EXTEND WITH HELP: CREATE EXTENSION
@@ -3575,6 +3766,211 @@ create_extension_stmt:
 | CREATE EXTENSION IF NOT EXISTS name WITH error { return unimplemented(sqllex, "create extension if not exists with") }
 | CREATE EXTENSION error // SHOW HELP: CREATE EXTENSION
 
+// %Help: CREATE PUBLICATION - define a new publication
+// %Category: DDL
+// %Text:
+// CREATE PUBLICATION <name> [FOR TABLE <tablename> [, ...] | FOR ALL TABLES]
+//
+// The changes to the tables of a publication can be streamed with the
+// logical replication protocol.
+// %SeeAlso: DROP PUBLICATION, WEBDOCS/create-publication.html
+create_publication_stmt:
+  CREATE PUBLICATION name
+  {
+    $$.val = &tree.CreatePublication{Name: tree.Name($3)}
+  }
+| CREATE PUBLICATION name FOR TABLE table_name_list
+  {
+    $$.val = &tree.CreatePublication{Name: tree.Name($3), Tables: $6.tableNames()}
+  }
+| CREATE PUBLICATION name FOR ALL TABLES
+  {
+    $$.val = &tree.CreatePublication{Name: tree.Name($3), AllTables: true}
+  }
+| CREATE PUBLICATION error // SHOW HELP: CREATE PUBLICATION
+
+// %Help: CREATE FUNCTION - define a new function
+// %Category: DDL
+// %Text:
+// CREATE [OR REPLACE] FUNCTION <name> ( [ [<argname>] <argtype> [, ...] ] )
+//   RETURNS <rettype>
+//   LANGUAGE SQL
+//   [ IMMUTABLE | STABLE | VOLATILE ]
+//   [ CALLED ON NULL INPUT | RETURNS NULL ON NULL INPUT | STRICT ]
+//   AS '<select statement>'
+//
+// The body of the function can reference its arguments by name or as $1, $2,
+// etc. The function returns the first column of the first row of the body.
+// %SeeAlso: DROP FUNCTION, WEBDOCS/create-function.html
+create_function_stmt:
+  CREATE FUNCTION db_object_name '(' opt_func_arg_list ')' RETURNS typename opt_create_func_opt_list
+  {
+    $$.val = &tree.CreateFunction{
+      Name: $3.unresolvedObjectName(),
+      Args: $5.funcArgs(),
+      ReturnType: $8.typeReference(),
+      Options: $9.functionOptions(),
+    }
+  }
+| CREATE OR REPLACE FUNCTION db_object_name '(' opt_func_arg_list ')' RETURNS typename opt_create_func_opt_list
+  {
+    $$.val = &tree.CreateFunction{
+      Name: $5.unresolvedObjectName(),
+      Replace: true,
+      Args: $7.funcArgs(),
+      ReturnType: $10.typeReference(),
+      Options: $11.functionOptions(),
+    }
+  }
+| CREATE FUNCTION error // SHOW HELP: CREATE FUNCTION
+| CREATE OR REPLACE FUNCTION error // SHOW HELP: CREATE FUNCTION
+
+opt_func_arg_list:
+  func_arg_list
+| /* EMPTY */
+  {
+    $$.val = tree.FuncArgs{}
+  }
+
+func_arg_list:
+  func_arg
+  {
+    $$.val = tree.FuncArgs{$1.funcArg()}
+  }
+| func_arg_list ',' func_arg
+  {
+    $$.val = append($1.funcArgs(), $3.funcArg())
+  }
+
+func_arg:
+  type_function_name typename
+  {
+    $$.val = tree.FuncArg{Name: tree.Name($1), Type: $2.typeReference()}
+  }
+| typename
+  {
+    $$.val = tree.FuncArg{Type: $1.typeReference()}
+  }
+
+opt_create_func_opt_list:
+  create_func_opt_list
+| /* EMPTY */
+  {
+    $$.val = tree.FunctionOptions(nil)
+  }
+
+create_func_opt_list:
+  create_func_opt_item
+  {
+    $$.val = tree.FunctionOptions{$1.functionOption()}
+  }
+| create_func_opt_list create_func_opt_item
+  {
+    $$.val = append($1.functionOptions(), $2.functionOption())
+  }
+
+create_func_opt_item:
+  LANGUAGE non_reserved_word_or_sconst
+  {
+    $$.val = tree.FunctionLanguage(strings.ToLower($2))
+  }
+| IMMUTABLE
+  {
+    $$.val = tree.FunctionVolatility{Volatility: tree.VolatilityImmutable}
+  }
+| STABLE
+  {
+    $$.val = tree.FunctionVolatility{Volatility: tree.VolatilityStable}
+  }
+| VOLATILE
+  {
+    $$.val = tree.FunctionVolatility{Volatility: tree.VolatilityVolatile}
+  }
+| CALLED ON NULL INPUT
+  {
+    $$.val = tree.FunctionCalledOnNullInput
+  }
+| RETURNS NULL ON NULL INPUT
+  {
+    $$.val = tree.FunctionReturnsNullOnNullInput
+  }
+| STRICT
+  {
+    $$.val = tree.FunctionStrict
+  }
+| AS SCONST
+  {
+    $$.val = tree.FunctionBodyStr($2)
+  }
+
+// %Help: CREATE TRIGGER - define a new row-level trigger
+// %Category: DDL
+// %Text:
+// CREATE TRIGGER <name> { BEFORE | AFTER } { INSERT | UPDATE | DELETE } [ OR ... ]
+//   ON <tablename> FOR EACH ROW EXECUTE { FUNCTION | PROCEDURE } <funcname> ()
+//
+// The trigger function must have no arguments, return type trigger, and be
+// defined in the schema of the table.
+// %SeeAlso: DROP TRIGGER, CREATE FUNCTION, WEBDOCS/create-trigger.html
+create_trigger_stmt:
+  CREATE TRIGGER name trigger_action_time trigger_event_list ON table_name FOR EACH ROW EXECUTE function_or_procedure db_object_name '(' ')'
+  {
+    $$.val = &tree.CreateTrigger{
+      Name: tree.Name($3),
+      ActionTime: $4.triggerActionTime(),
+      Events: $5.triggerEvents(),
+      Table: $7.unresolvedObjectName(),
+      FuncName: $13.unresolvedObjectName(),
+    }
+  }
+| CREATE TRIGGER error // SHOW HELP: CREATE TRIGGER
+
+trigger_action_time:
+  BEFORE
+  {
+    $$.val = tree.TriggerBefore
+  }
+| AFTER
+  {
+    $$.val = tree.TriggerAfter
+  }
+
+trigger_event_list:
+  trigger_event
+  {
+    $$.val = tree.TriggerEvents{$1.triggerEvent()}
+  }
+| trigger_event_list OR trigger_event
+  {
+    $$.val = append($1.triggerEvents(), $3.triggerEvent())
+  }
+
+trigger_event:
+  INSERT
+  {
+    $$.val = tree.TriggerInsert
+  }
+| UPDATE
+  {
+    $$.val = tree.TriggerUpdate
+  }
+| DELETE
+  {
+    $$.val = tree.TriggerDelete
+  }
+| UPDATE OF error
+  {
+    return unimplementedWithIssueDetail(sqllex, 28296, "update of trigger")
+  }
+| TRUNCATE error
+  {
+    return unimplementedWithIssueDetail(sqllex, 28296, "truncate trigger")
+  }
+
+function_or_procedure:
+  FUNCTION {}
+| PROCEDURE {}
+
 create_unsupported:
   CREATE ACCESS METHOD error { return unimplemented(sqllex, "create access method") }
 | CREATE AGGREGATE error { return unimplemented(sqllex, "create aggregate") }
@@ -3584,17 +3980,13 @@ create_unsupported:
 | CREATE DEFAULT CONVERSION error { return unimplemented(sqllex, "create def conv") }
 | CREATE FOREIGN TABLE error { return unimplemented(sqllex, "create foreign table") }
 | CREATE FOREIGN DATA error { return unimplemented(sqllex, "create fdw") }
-| CREATE FUNCTION error { return unimplementedWithIssueDetail(sqllex, 17511, "create function") }
-| CREATE OR REPLACE FUNCTION error { return unimplementedWithIssueDetail(sqllex, 17511, "create function") }
 | CREATE opt_or_replace opt_trusted opt_procedural LANGUAGE name error { return unimplementedWithIssueDetail(sqllex, 17511, "create language " + $6) }
 | CREATE OPERATOR error { return unimplementedWithIssue(sqllex, 65017) }
-| CREATE PUBLICATION error { return unimplemented(sqllex, "create publication") }
 | CREATE opt_or_replace RULE error { return unimplemented(sqllex, "create rule") }
 | CREATE SERVER error { return unimplemented(sqllex, "create server") }
 | CREATE SUBSCRIPTION error { return unimplemented(sqllex, "create subscription") }
 | CREATE TABLESPACE error { return unimplementedWithIssueDetail(sqllex, 54113, "create tablespace") }
 | CREATE TEXT error { return unimplementedWithIssueDetail(sqllex, 7821, "create text") }
-| CREATE TRIGGER error { return unimplementedWithIssueDetail(sqllex, 28296, "create trigger") }
 
 opt_or_replace:
   OR REPLACE {}
@@ -3619,15 +4011,12 @@ drop_unsupported:
 | DROP EXTENSION name error { return unimplemented(sqllex, "drop extension " + $3) }
 | DROP FOREIGN TABLE error { return unimplemented(sqllex, "drop foreign table") }
 | DROP FOREIGN DATA error { return unimplemented(sqllex, "drop fdw") }
-| DROP FUNCTION error { return unimplementedWithIssueDetail(sqllex, 17511, "drop function") }
 | DROP opt_procedural LANGUAGE name error { return unimplementedWithIssueDetail(sqllex, 17511, "drop language " + $4) }
 | DROP OPERATOR error { return unimplemented(sqllex, "drop operator") }
-| DROP PUBLICATION error { return unimplemented(sqllex, "drop publication") }
 | DROP RULE error { return unimplemented(sqllex, "drop rule") }
 | DROP SERVER error { return unimplemented(sqllex, "drop server") }
 | DROP SUBSCRIPTION error { return unimplemented(sqllex, "drop subscription") }
 | DROP TEXT error { return unimplementedWithIssueDetail(sqllex, 7821, "drop text") }
-| DROP TRIGGER error { return unimplementedWithIssueDetail(sqllex, 28296, "drop") }
 
 create_ddl_stmt:
   create_database_stmt // EXTEND WITH HELP: CREATE DATABASE
@@ -3640,13 +4029,16 @@ create_ddl_stmt:
 | create_type_stmt     // EXTEND WITH HELP: CREATE TYPE
 | create_view_stmt     // EXTEND WITH HELP: CREATE VIEW
 | create_sequence_stmt // EXTEND WITH HELP: CREATE SEQUENCE
+| create_publication_stmt // EXTEND WITH HELP: CREATE PUBLICATION
+| create_function_stmt // EXTEND WITH HELP: CREATE FUNCTION
+| create_trigger_stmt  // EXTEND WITH HELP: CREATE TRIGGER
 
 // %Help: CREATE STATISTICS - create a new table statistic
 // %Category: Misc
 // %Text:
 // CREATE STATISTICS <statisticname>
 //   [ON <colname> [, ...]]
-//   FROM <tablename> [AS OF SYSTEM TIME <expr>]
+//   FROM <tablename> [USING EXTREMES] [AS OF SYSTEM TIME <expr>]
 create_stats_stmt:
   CREATE STATISTICS statistics_name opt_stats_columns FROM create_stats_target opt_create_stats_options
   {
@@ -3696,6 +4088,19 @@ opt_create_stats_options:
       AsOf: $1.asOfClause(),
     }
   }
+| USING EXTREMES
+  {
+    $$.val = &tree.CreateStatsOptions{
+      UsingExtremes: true,
+    }
+  }
+| USING EXTREMES as_of_clause
+  {
+    $$.val = &tree.CreateStatsOptions{
+      UsingExtremes: true,
+      AsOf: $3.asOfClause(),
+    }
+  }
 | /* EMPTY */
   {
     $$.val = &tree.CreateStatsOptions{}
@@ -3735,6 +4140,13 @@ create_stats_option:
       AsOf: $1.asOfClause(),
     }
   }
+| USING EXTREMES
+  {
+    /* SKIP DOC */
+    $$.val = &tree.CreateStatsOptions{
+      UsingExtremes: true,
+    }
+  }
 
 // %Help: CREATE CHANGEFEED  - create change data capture
 // %Category: CCL
@@ -3742,6 +4154,9 @@ create_stats_option:
 // CREATE CHANGEFEED
 // FOR <targets> [INTO sink] [WITH <options>]
 //
+// CREATE CHANGEFEED [INTO sink] [WITH <options>]
+// AS SELECT <targets> FROM <table> [WHERE <expr>]
+//
 // Sink: Data caputre stream stream destination.  Enterprise only.
 create_changefeed_stmt:
   CREATE CHANGEFEED FOR changefeed_targets opt_changefeed_sink opt_with_options
@@ -3752,6 +4167,20 @@ create_changefeed_stmt:
       Options: $6.kvOptions(),
     }
   }
+| CREATE CHANGEFEED opt_changefeed_sink opt_with_options AS SELECT target_list FROM table_name opt_where_clause
+  {
+    name := $9.unresolvedObjectName().ToTableName()
+    $$.val = &tree.CreateChangefeed{
+      Targets: tree.TargetList{Tables: tree.TablePatterns{$9.unresolvedObjectName().ToUnresolvedName()}},
+      SinkURI: $3.expr(),
+      Options: $4.kvOptions(),
+      Select: &tree.SelectClause{
+        Exprs: $7.selExprs(),
+        From: tree.From{Tables: tree.TableExprs{&tree.AliasedTableExpr{Expr: &name}}},
+        Where: tree.NewWhere(tree.AstWhere, $10.expr()),
+      },
+    }
+  }
 | EXPERIMENTAL CHANGEFEED FOR changefeed_targets opt_with_options
   {
     /* SKIP DOC */
@@ -3896,7 +4325,7 @@ discard_stmt:
 // %Category: Group
 // %Text:
 // DROP DATABASE, DROP INDEX, DROP TABLE, DROP VIEW, DROP SEQUENCE,
-// DROP USER, DROP ROLE, DROP TYPE
+// DROP USER, DROP ROLE, DROP TYPE, DROP PUBLICATION
 drop_stmt:
   drop_ddl_stmt      // help texts in sub-rule
 | drop_role_stmt     // EXTEND WITH HELP: DROP ROLE
@@ -3912,6 +4341,9 @@ drop_ddl_stmt:
 | drop_sequence_stmt // EXTEND WITH HELP: DROP SEQUENCE
 | drop_schema_stmt   // EXTEND WITH HELP: DROP SCHEMA
 | drop_type_stmt     // EXTEND WITH HELP: DROP TYPE
+| drop_publication_stmt // EXTEND WITH HELP: DROP PUBLICATION
+| drop_function_stmt // EXTEND WITH HELP: DROP FUNCTION
+| drop_trigger_stmt  // EXTEND WITH HELP: DROP TRIGGER
 
 // %Help: DROP VIEW - remove a view
 // %Category: DDL
@@ -4046,6 +4478,97 @@ drop_type_stmt:
   }
 | DROP TYPE error // SHOW HELP: DROP TYPE
 
+// %Help: DROP PUBLICATION - remove a publication
+// %Category: DDL
+// %Text: DROP PUBLICATION [IF EXISTS] <name> [, ...] [CASCADE | RESTRICT]
+// %SeeAlso: CREATE PUBLICATION, WEBDOCS/drop-publication.html
+drop_publication_stmt:
+  DROP PUBLICATION name_list opt_drop_behavior
+  {
+    $$.val = &tree.DropPublication{
+      Names: $3.nameList(),
+      IfExists: false,
+      DropBehavior: $4.dropBehavior(),
+    }
+  }
+| DROP PUBLICATION IF EXISTS name_list opt_drop_behavior
+  {
+    $$.val = &tree.DropPublication{
+      Names: $5.nameList(),
+      IfExists: true,
+      DropBehavior: $6.dropBehavior(),
+    }
+  }
+| DROP PUBLICATION error // SHOW HELP: DROP PUBLICATION
+
+// %Help: DROP FUNCTION - remove a function
+// %Category: DDL
+// %Text: DROP FUNCTION [IF EXISTS] <name> [ ( [ [<argname>] <argtype> [, ...] ] ) ] [, ...] [CASCADE | RESTRICT]
+// %SeeAlso: CREATE FUNCTION, WEBDOCS/drop-function.html
+drop_function_stmt:
+  DROP FUNCTION func_obj_list opt_drop_behavior
+  {
+    $$.val = &tree.DropFunction{
+      Functions: $3.funcObjs(),
+      IfExists: false,
+      DropBehavior: $4.dropBehavior(),
+    }
+  }
+| DROP FUNCTION IF EXISTS func_obj_list opt_drop_behavior
+  {
+    $$.val = &tree.DropFunction{
+      Functions: $5.funcObjs(),
+      IfExists: true,
+      DropBehavior: $6.dropBehavior(),
+    }
+  }
+| DROP FUNCTION error // SHOW HELP: DROP FUNCTION
+
+// %Help: DROP TRIGGER - remove a trigger
+// %Category: DDL
+// %Text: DROP TRIGGER [IF EXISTS] <name> ON <tablename> [CASCADE | RESTRICT]
+// %SeeAlso: CREATE TRIGGER, WEBDOCS/drop-trigger.html
+drop_trigger_stmt:
+  DROP TRIGGER name ON table_name opt_drop_behavior
+  {
+    $$.val = &tree.DropTrigger{
+      Name: tree.Name($3),
+      Table: $5.unresolvedObjectName(),
+      IfExists: false,
+      DropBehavior: $6.dropBehavior(),
+    }
+  }
+| DROP TRIGGER IF EXISTS name ON table_name opt_drop_behavior
+  {
+    $$.val = &tree.DropTrigger{
+      Name: tree.Name($5),
+      Table: $7.unresolvedObjectName(),
+      IfExists: true,
+      DropBehavior: $8.dropBehavior(),
+    }
+  }
+| DROP TRIGGER error // SHOW HELP: DROP TRIGGER
+
+func_obj_list:
+  func_obj
+  {
+    $$.val = tree.FuncObjs{$1.funcObj()}
+  }
+| func_obj_list ',' func_obj
+  {
+    $$.val = append($1.funcObjs(), $3.funcObj())
+  }
+
+func_obj:
+  db_object_name
+  {
+    $$.val = tree.FuncObj{Name: $1.unresolvedObjectName()}
+  }
+| db_object_name '(' opt_func_arg_list ')'
+  {
+    $$.val = tree.FuncObj{Name: $1.unresolvedObjectName(), Args: $3.funcArgs()}
+  }
+
 target_types:
   type_name_list
   {
@@ -4365,7 +4888,7 @@ deallocate_stmt:
 //   GRANT <roles...> TO <grantees...> [WITH ADMIN OPTION]
 //
 // Privileges:
-//   CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE, USAGE
+//   CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE, USAGE, EXECUTE
 //
 // Targets:
 //   DATABASE <databasename> [, ...]
@@ -4373,6 +4896,7 @@ deallocate_stmt:
 //   TYPE <typename> [, <typename>]...
 //   SCHEMA [<databasename> .]<schemaname> [, [<databasename> .]<schemaname>]...
 //   ALL TABLES IN SCHEMA schema_name [, ...]
+//   FUNCTION <funcname> [ ( [<argtype> [, ...]] ) ] [, ...]
 //
 // %SeeAlso: REVOKE, WEBDOCS/grant.html
 grant_stmt:
@@ -4419,6 +4943,17 @@ grant_stmt:
       WithGrantOption: $11.bool(),
     }
   }
+| GRANT privileges ON FUNCTION func_obj_list TO role_spec_list opt_with_grant_option
+  {
+    $$.val = &tree.Grant{
+      Privileges: $2.privilegeList(),
+      Targets: tree.TargetList{
+        Functions: $5.funcObjs(),
+      },
+      Grantees: $7.roleSpecList(),
+      WithGrantOption: $8.bool(),
+    }
+  }
 | GRANT privileges ON SEQUENCE error
   {
     return unimplemented(sqllex, "grant privileges on sequence")
@@ -4434,7 +4969,7 @@ grant_stmt:
 //   REVOKE [ADMIN OPTION FOR] <roles...> FROM <grantees...>
 //
 // Privileges:
-//   CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE, USAGE
+//   CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE, USAGE, EXECUTE
 //
 // Targets:
 //   DATABASE <databasename> [, <databasename>]...
@@ -4442,6 +4977,7 @@ grant_stmt:
 //   TYPE <typename> [, <typename>]...
 //   SCHEMA [<databasename> .]<schemaname> [, [<databasename> .]<schemaname]...
 //   ALL TABLES IN SCHEMA schema_name [, ...]
+//   FUNCTION <funcname> [ ( [<argtype> [, ...]] ) ] [, ...]
 //
 // %SeeAlso: GRANT, WEBDOCS/revoke.html
 revoke_stmt:
@@ -4515,6 +5051,28 @@ revoke_stmt:
       GrantOptionFor: true,
     }
   }
+| REVOKE privileges ON FUNCTION func_obj_list FROM role_spec_list
+  {
+    $$.val = &tree.Revoke{
+      Privileges: $2.privilegeList(),
+      Targets: tree.TargetList{
+        Functions: $5.funcObjs(),
+      },
+      Grantees: $7.roleSpecList(),
+      GrantOptionFor: false,
+    }
+  }
+| REVOKE GRANT OPTION FOR privileges ON FUNCTION func_obj_list FROM role_spec_list
+  {
+    $$.val = &tree.Revoke{
+      Privileges: $5.privilegeList(),
+      Targets: tree.TargetList{
+        Functions: $8.funcObjs(),
+      },
+      Grantees: $10.roleSpecList(),
+      GrantOptionFor: true,
+    }
+  }
 | REVOKE privileges ON SEQUENCE error
   {
     return unimplemented(sqllex, "revoke privileges on sequence")
@@ -4604,7 +5162,7 @@ use_stmt:
 nonpreparable_set_stmt:
   set_transaction_stmt // EXTEND WITH HELP: SET TRANSACTION
 | set_exprs_internal   { /* SKIP DOC */ }
-| SET CONSTRAINTS error { return unimplemented(sqllex, "set constraints") }
+| set_constraints_stmt // EXTEND WITH HELP: SET CONSTRAINTS
 
 // SET SESSION / SET LOCAL / SET CLUSTER SETTING
 preparable_set_stmt:
@@ -4744,7 +5302,7 @@ set_exprs_internal:
 // %Text:
 // SET [SESSION] <var> { TO | = } <values...>
 // SET [SESSION] TIME ZONE <tz>
-// SET [SESSION] CHARACTERISTICS AS TRANSACTION ISOLATION LEVEL { SNAPSHOT | SERIALIZABLE }
+// SET [SESSION] CHARACTERISTICS AS TRANSACTION ISOLATION LEVEL { READ COMMITTED | SNAPSHOT | SERIALIZABLE }
 // SET [SESSION] TRACING { TO | = } { on | off | cluster | kv | results } [,...]
 //
 // %SeeAlso: SHOW SESSION, RESET, DISCARD, SHOW, SET CLUSTER SETTING, SET TRANSACTION, SET LOCAL
@@ -4789,7 +5347,7 @@ set_local_stmt:
 // SET [SESSION] TRANSACTION <txnparameters...>
 //
 // Transaction parameters:
-//    ISOLATION LEVEL { SNAPSHOT | SERIALIZABLE }
+//    ISOLATION LEVEL { READ COMMITTED | SNAPSHOT | SERIALIZABLE }
 //    PRIORITY { LOW | NORMAL | HIGH }
 //    AS OF SYSTEM TIME <expr>
 //    [NOT] DEFERRABLE
@@ -4808,6 +5366,28 @@ set_transaction_stmt:
   }
 | SET SESSION TRANSACTION error // SHOW HELP: SET TRANSACTION
 
+// %Help: SET CONSTRAINTS - set the constraint checking mode
+// %Category: Txn
+// %Text:
+// SET CONSTRAINTS ALL { DEFERRED | IMMEDIATE }
+//
+// DEFERRED postpones the checking of DEFERRABLE foreign key and unique
+// constraints until the transaction commits. IMMEDIATE checks them at the end
+// of each statement, and also checks the constraints deferred so far.
+//
+// %SeeAlso: SET TRANSACTION, CREATE TABLE
+set_constraints_stmt:
+  SET CONSTRAINTS ALL DEFERRED
+  {
+    $$.val = &tree.SetConstraints{Mode: tree.ConstraintsDeferred}
+  }
+| SET CONSTRAINTS ALL IMMEDIATE
+  {
+    $$.val = &tree.SetConstraints{Mode: tree.ConstraintsImmediate}
+  }
+| SET CONSTRAINTS name_list error { return unimplementedWithIssueDetail(sqllex, 31632, "set constraints name") }
+| SET CONSTRAINTS error // SHOW HELP: SET CONSTRAINTS
+
 generic_set:
   var_name to_or_eq var_list
   {
@@ -4937,11 +5517,11 @@ var_list:
 iso_level:
   READ UNCOMMITTED
   {
-    $$.val = tree.SerializableIsolation
+    $$.val = tree.ReadCommittedIsolation
   }
 | READ COMMITTED
   {
-    $$.val = tree.SerializableIsolation
+    $$.val = tree.ReadCommittedIsolation
   }
 | SNAPSHOT
   {
@@ -5129,19 +5709,27 @@ session_var_parts:
 
 // %Help: SHOW STATISTICS - display table statistics (experimental)
 // %Category: Experimental
-// %Text: SHOW STATISTICS [USING JSON] FOR TABLE <table_name>
+// %Text:
+// SHOW STATISTICS [USING JSON] FOR TABLE <table_name>
+// SHOW STATISTICS FOR TABLE <table_name> WITH FORECAST
 //
 // Returns the available statistics for a table.
 // The statistics can include a histogram ID, which can
 // be used with SHOW HISTOGRAM.
 // If USING JSON is specified, the statistics and histograms
 // are encoded in JSON format.
+// If WITH FORECAST is specified, the statistics forecasted
+// from the history of collected statistics are also returned.
 // %SeeAlso: SHOW HISTOGRAM
 show_stats_stmt:
   SHOW STATISTICS FOR TABLE table_name
   {
     $$.val = &tree.ShowTableStats{Table: $5.unresolvedObjectName()}
   }
+| SHOW STATISTICS FOR TABLE table_name WITH FORECAST
+  {
+    $$.val = &tree.ShowTableStats{Table: $5.unresolvedObjectName(), WithForecast: true}
+  }
 | SHOW STATISTICS USING JSON FOR TABLE table_name
   {
     /* SKIP DOC */
@@ -5170,7 +5758,10 @@ show_histogram_stmt:
 
 // %Help: SHOW BACKUP - list backup contents
 // %Category: CCL
-// %Text: SHOW BACKUP [SCHEMAS|FILES|RANGES] <location>
+// %Text:
+// SHOW BACKUP [SCHEMAS|FILES|RANGES] <location>
+// SHOW BACKUP ( <location> [, ...] ) WITH check_files
+// SHOW BACKUP <subdirectory> IN ( <collection> [, ...] ) WITH check_files
 // %SeeAlso: WEBDOCS/show-backup.html
 show_backup_stmt:
   SHOW BACKUPS IN string_or_placeholder
@@ -5196,6 +5787,25 @@ show_backup_stmt:
       Options: $6.kvOptions(),
     }
   }
+| SHOW BACKUP '(' string_or_placeholder_list ')' opt_with_options
+  {
+    $$.val = &tree.ShowBackup{
+      Details:       tree.BackupDefaultDetails,
+      Path:          $4.exprs()[0],
+      LocalityPaths: $4.exprs()[1:],
+      Options:       $6.kvOptions(),
+    }
+  }
+| SHOW BACKUP string_or_placeholder IN '(' string_or_placeholder_list ')' opt_with_options
+  {
+    $$.val = &tree.ShowBackup{
+      Details:       tree.BackupDefaultDetails,
+      Path:          $3.expr(),
+      InCollection:  $6.exprs()[0],
+      LocalityPaths: $6.exprs()[1:],
+      Options:       $8.kvOptions(),
+    }
+  }
 | SHOW BACKUP SCHEMAS string_or_placeholder opt_with_options
   {
     $$.val = &tree.ShowBackup{
@@ -5528,7 +6138,7 @@ show_jobs_stmt:
 // %Help: SHOW SCHEDULES - list periodic schedules
 // %Category: Misc
 // %Text:
-// SHOW [RUNNING | PAUSED] SCHEDULES [FOR BACKUP]
+// SHOW [RUNNING | PAUSED] SCHEDULES [FOR {BACKUP | CHANGEFEED | EXPORT | SQL | SQL STATISTICS}]
 // SHOW SCHEDULE <schedule_id>
 // %SeeAlso: PAUSE SCHEDULES, RESUME SCHEDULES, DROP SCHEDULES
 show_schedules_stmt:
@@ -5580,6 +6190,18 @@ opt_schedule_executor_type:
   {
     $$.val = tree.ScheduledSQLStatsCompactionExecutor
   }
+| FOR CHANGEFEED
+  {
+    $$.val = tree.ScheduledChangefeedExecutor
+  }
+| FOR EXPORT
+  {
+    $$.val = tree.ScheduledExportExecutor
+  }
+| FOR SQL
+  {
+    $$.val = tree.ScheduledSQLExecutor
+  }
 
 // %Help: SHOW TRACE - display an execution trace
 // %Category: Misc
@@ -6970,7 +7592,7 @@ col_qualification_elem:
   {
     $$.val = &tree.ColumnOnUpdate{Expr: $3.expr()}
   }
-| REFERENCES table_name opt_name_parens key_match reference_actions
+| REFERENCES table_name opt_name_parens key_match reference_actions opt_deferrable
   {
     name := $2.unresolvedObjectName().ToTableName()
     $$.val = &tree.ColumnFKConstraint{
@@ -6978,6 +7600,7 @@ col_qualification_elem:
       Col: tree.Name($3),
       Actions: $5.referenceActions(),
       Match: $4.compositeKeyMatchMethod(),
+      Deferrable: $6.constraintDeferrability(),
     }
   }
 | generated_as '(' a_expr ')' STORED
@@ -7100,6 +7723,10 @@ table_constraint:
 constraint_elem:
   CHECK '(' a_expr ')' opt_deferrable
   {
+    if $5.constraintDeferrability() != tree.ConstraintNotDeferrable {
+      sqllex.Error("CHECK constraints cannot be marked DEFERRABLE")
+      return 1
+    }
     $$.val = &tree.CheckConstraintTableDef{
       Expr: $3.expr(),
     }
@@ -7107,6 +7734,12 @@ constraint_elem:
 | UNIQUE opt_without_index '(' index_params ')'
     opt_storing opt_partition_by_index opt_deferrable opt_where_clause
   {
+    // Unique indexes are enforced by the KV layer as rows are written, so only
+    // constraints without an index can be checked at the end of the
+    // transaction.
+    if !$2.bool() && $8.constraintDeferrability() != tree.ConstraintNotDeferrable {
+      return unimplementedWithIssueDetail(sqllex, 31632, "deferrable unique index")
+    }
     $$.val = &tree.UniqueConstraintTableDef{
       WithoutIndex: $2.bool(),
       IndexTableDef: tree.IndexTableDef{
@@ -7115,6 +7748,7 @@ constraint_elem:
         PartitionByIndex: $7.partitionByIndex(),
         Predicate: $9.expr(),
       },
+      Deferrable: $8.constraintDeferrability(),
     }
   }
 | PRIMARY KEY '(' index_params ')' opt_hash_sharded
@@ -7137,6 +7771,7 @@ constraint_elem:
       ToCols: $8.nameList(),
       Match: $9.compositeKeyMatchMethod(),
       Actions: $10.referenceActions(),
+      Deferrable: $11.constraintDeferrability(),
     }
   }
 | EXCLUDE USING error
@@ -7247,12 +7882,30 @@ create_as_col_qualification_elem:
   }
 
 opt_deferrable:
-  /* EMPTY */ { /* no error */ }
-| DEFERRABLE { return unimplementedWithIssueDetail(sqllex, 31632, "deferrable") }
-| DEFERRABLE INITIALLY DEFERRED { return unimplementedWithIssueDetail(sqllex, 31632, "def initially deferred") }
-| DEFERRABLE INITIALLY IMMEDIATE { return unimplementedWithIssueDetail(sqllex, 31632, "def initially immediate") }
-| INITIALLY DEFERRED { return unimplementedWithIssueDetail(sqllex, 31632, "initially deferred") }
-| INITIALLY IMMEDIATE { return unimplementedWithIssueDetail(sqllex, 31632, "initially immediate") }
+  /* EMPTY */
+  {
+    $$.val = tree.ConstraintNotDeferrable
+  }
+| DEFERRABLE
+  {
+    $$.val = tree.ConstraintInitiallyImmediate
+  }
+| DEFERRABLE INITIALLY DEFERRED
+  {
+    $$.val = tree.ConstraintInitiallyDeferred
+  }
+| DEFERRABLE INITIALLY IMMEDIATE
+  {
+    $$.val = tree.ConstraintInitiallyImmediate
+  }
+| INITIALLY DEFERRED
+  {
+    $$.val = tree.ConstraintInitiallyDeferred
+  }
+| INITIALLY IMMEDIATE
+  {
+    $$.val = tree.ConstraintNotDeferrable
+  }
 
 storing:
   COVERING
@@ -8623,7 +9276,7 @@ transaction_stmt:
 // START TRANSACTION [ <txnparameter> [[,] ...] ]
 //
 // Transaction parameters:
-//    ISOLATION LEVEL { SNAPSHOT | SERIALIZABLE }
+//    ISOLATION LEVEL { READ COMMITTED | SNAPSHOT | SERIALIZABLE }
 //    PRIORITY { LOW | NORMAL | HIGH }
 //
 // %SeeAlso: COMMIT, ROLLBACK, WEBDOCS/begin-transaction.html
@@ -9416,13 +10069,21 @@ simple_select:
 //        { <expr> [[AS] <name>] | [ [<dbname>.] <tablename>. ] * } [, ...]
 //        [ FROM <source> ]
 //        [ WHERE <expr> ]
-//        [ GROUP BY <expr> [ , ... ] ]
+//        [ GROUP BY <grouping_element> [ , ... ] ]
 //        [ HAVING <expr> ]
 //        [ WINDOW <name> AS ( <definition> ) ]
 //        [ { UNION | INTERSECT | EXCEPT } [ ALL | DISTINCT ] <selectclause> ]
 //        [ ORDER BY <expr> [ ASC | DESC ] [, ...] ]
 //        [ LIMIT { <expr> | ALL } ]
 //        [ OFFSET <expr> [ ROW | ROWS ] ]
+//
+// Grouping elements:
+//    <expr>
+//    ( [ <expr> [ , ... ] ] )
+//    ROLLUP ( <expr> [ , ... ] )
+//    CUBE ( <expr> [ , ... ] )
+//    GROUPING SETS ( <grouping_element> [ , ... ] )
+//
 // %SeeAlso: WEBDOCS/select-clause.html
 simple_select_clause:
   SELECT opt_all_clause target_list
@@ -9840,9 +10501,18 @@ group_by_list:
 // rather than reducing the conflicting unreserved_keyword rule.
 group_by_item:
   a_expr { $$.val = $1.expr() }
-| ROLLUP '(' error { return unimplementedWithIssueDetail(sqllex, 46280, "rollup") }
-| CUBE '(' error { return unimplementedWithIssueDetail(sqllex, 46280, "cube") }
-| GROUPING SETS error { return unimplementedWithIssueDetail(sqllex, 46280, "grouping sets") }
+| ROLLUP '(' expr_list ')'
+  {
+    $$.val = &tree.GroupingSets{Type: tree.GroupingSetsRollup, Exprs: $3.exprs()}
+  }
+| CUBE '(' expr_list ')'
+  {
+    $$.val = &tree.GroupingSets{Type: tree.GroupingSetsCube, Exprs: $3.exprs()}
+  }
+| GROUPING SETS '(' group_by_list ')'
+  {
+    $$.val = &tree.GroupingSets{Type: tree.GroupingSetsExplicit, Exprs: $4.exprs()}
+  }
 
 having_clause:
   HAVING a_expr
@@ -11761,7 +12431,10 @@ d_expr:
   {
     $$.val = $2.expr()
   }
-| GROUPING '(' expr_list ')' { return unimplemented(sqllex, "d_expr grouping") }
+| GROUPING '(' expr_list ')'
+  {
+    $$.val = &tree.GroupingExpr{Exprs: $3.exprs()}
+  }
 
 func_application:
   func_name '(' ')'
@@ -13321,6 +13994,7 @@ unreserved_keyword:
 | BUNDLE
 | BY
 | CACHE
+| CALLED
 | CANCEL
 | CANCELQUERY
 | CASCADE
@@ -13371,6 +14045,7 @@ unreserved_keyword:
 | DOMAIN
 | DOUBLE
 | DROP
+| EACH
 | ENCODING
 | ENCRYPTED
 | ENCRYPTION_PASSPHRASE
@@ -13390,6 +14065,7 @@ unreserved_keyword:
 | EXPLAIN
 | EXPORT
 | EXTENSION
+| EXTREMES
 | FAILURE
 | FILES
 | FILTER
@@ -13398,6 +14074,7 @@ unreserved_keyword:
 | FORCE
 | FORCE_INDEX
 | FORCE_ZIGZAG
+| FORECAST
 | FUNCTION
 | FUNCTIONS
 | GENERATED
@@ -13418,6 +14095,7 @@ unreserved_keyword:
 | HOUR
 | IDENTITY
 | IMMEDIATE
+| IMMUTABLE
 | IMPORT
 | INCLUDE
 | INCLUDING
@@ -13427,6 +14105,7 @@ unreserved_keyword:
 | INDEXES
 | INHERITS
 | INJECT
+| INPUT
 | INSERT
 | INTO_DB
 | INVERTED
@@ -13537,6 +14216,7 @@ unreserved_keyword:
 | PRESERVE
 | PRIORITY
 | PRIVILEGES
+| PROCEDURE
 | PUBLIC
 | PUBLICATION
 | QUERIES
@@ -13566,6 +14246,7 @@ unreserved_keyword:
 | RESTRICTED
 | RESUME
 | RETRY
+| RETURNS
 | REVISION_HISTORY
 | REVOKE
 | ROLE
@@ -13610,6 +14291,7 @@ unreserved_keyword:
 | SPLIT
 | SQL
 | SQLLOGIN
+| STABLE
 | START
 | STATEMENTS
 | STATISTICS
@@ -13661,6 +14343,7 @@ unreserved_keyword:
 | VIEWACTIVITY
 | VIEWACTIVITYREDACTED
 | VISIBLE
+| VOLATILE
 | VOTERS
 | WITHIN
 | WITHOUT
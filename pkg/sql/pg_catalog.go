@@ -17,10 +17,12 @@ import (
 	"fmt"
 	"hash"
 	"hash/fnv"
+	"sort"
 	"strings"
 	"time"
 	"unicode"
 
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
@@ -44,6 +46,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/vtable"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/lsn"
 	"github.com/cockroachdb/errors"
 	"github.com/lib/pq/oid"
 	"golang.org/x/text/collate"
@@ -78,7 +81,7 @@ type RewriteEvTypes string
 
 const evTypeSelect RewriteEvTypes = "1"
 
-//PGShDependType is an enumeration that lists pg_shdepend deptype column values
+// PGShDependType is an enumeration that lists pg_shdepend deptype column values
 type PGShDependType string
 
 const (
@@ -1538,6 +1541,9 @@ https://www.postgresql.org/docs/9.5/catalog-pg-description.html`,
 				objID = tree.NewDOid(tree.MustBeDInt(objID))
 				objSubID = tree.DZero
 				classOid = tree.NewDOid(catconstants.PgCatalogConstraintTableID)
+			case keys.FunctionCommentType:
+				objID = makeOidHasher().UserDefinedFunctionOid(descpb.ID(tree.MustBeDInt(objID)))
+				classOid = tree.NewDOid(catconstants.PgCatalogProcTableID)
 			case keys.IndexCommentType:
 				objID = makeOidHasher().IndexOid(
 					descpb.ID(tree.MustBeDInt(objID)),
@@ -2261,11 +2267,127 @@ https://www.postgresql.org/docs/9.5/catalog-pg-proc.html`,
 						}
 					}
 				}
-				return nil
+				return forEachSchema(ctx, p, db, func(sc catalog.SchemaDescriptor) error {
+					return addUserDefinedFunctionRows(ctx, p, h, db, sc, addRow)
+				})
 			})
 	},
 }
 
+// addUserDefinedFunctionRows adds the rows of pg_proc for the user-defined
+// functions of the given schema.
+func addUserDefinedFunctionRows(
+	ctx context.Context,
+	p *planner,
+	h oidHasher,
+	db catalog.DatabaseDescriptor,
+	sc catalog.SchemaDescriptor,
+	addRow func(...tree.Datum) error,
+) error {
+	fnDescs, err := getUserDefinedFunctions(ctx, p, sc)
+	if err != nil {
+		return err
+	}
+	if len(fnDescs) == 0 {
+		return nil
+	}
+	nspOid := h.NamespaceOid(db.GetID(), sc.GetName())
+	for _, fnDesc := range fnDescs {
+		fn := fnDesc.FuncDesc()
+		dArgTypes := tree.NewDArray(types.Oid)
+		hasArgNames := false
+		for _, arg := range fn.Args {
+			if err := dArgTypes.Append(tree.NewDOid(tree.DInt(arg.Type.Oid()))); err != nil {
+				return err
+			}
+			hasArgNames = hasArgNames || arg.Name != ""
+		}
+		argNames := tree.DNull
+		if hasArgNames {
+			dArgNames := tree.NewDArray(types.String)
+			for _, arg := range fn.Args {
+				if err := dArgNames.Append(tree.NewDString(arg.Name)); err != nil {
+					return err
+				}
+			}
+			argNames = dArgNames
+		}
+		provolatile, proleakproof := fn.Volatility.ToTreeVolatility().ToPostgres()
+		retType := oid.T_trigger
+		if !fn.Trigger {
+			retType = fn.ReturnType.Oid()
+		}
+
+		if err := addRow(
+			h.UserDefinedFunctionOid(fn.ID), // oid
+			tree.NewDName(fn.Name),          // proname
+			nspOid,                          // pronamespace
+			h.UserOid(fnDesc.GetPrivileges().Owner()), // proowner
+			oidZero,                                  // prolang
+			tree.DNull,                               // procost
+			tree.DNull,                               // prorows
+			oidZero,                                  // provariadic
+			tree.DNull,                               // protransform
+			tree.DBoolFalse,                          // proisagg
+			tree.DBoolFalse,                          // proiswindow
+			tree.DBoolFalse,                          // prosecdef
+			tree.MakeDBool(tree.DBool(proleakproof)), // proleakproof
+			tree.MakeDBool(tree.DBool(!fn.CalledOnNullInput)), // proisstrict
+			tree.DBoolFalse,                         // proretset
+			tree.NewDString(provolatile),            // provolatile
+			tree.DNull,                              // proparallel
+			tree.NewDInt(tree.DInt(len(fn.Args))),   // pronargs
+			tree.NewDInt(tree.DInt(0)),              // pronargdefaults
+			tree.NewDOid(tree.DInt(retType)),        // prorettype
+			tree.NewDOidVectorFromDArray(dArgTypes), // proargtypes
+			tree.DNull,                              // proallargtypes
+			tree.DNull,                              // proargmodes
+			argNames,                                // proargnames
+			tree.DNull,                              // proargdefaults
+			tree.DNull,                              // protrftypes
+			tree.NewDString(fn.Body),                // prosrc
+			tree.DNull,                              // probin
+			tree.DNull,                              // proconfig
+			tree.DNull,                              // proacl
+			tree.DNull,                              // prokind
+			tree.DNull,                              // prosupport
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getUserDefinedFunctions returns the descriptors of the user-defined
+// functions of the given schema, ordered by name and ID.
+func getUserDefinedFunctions(
+	ctx context.Context, p *planner, sc catalog.SchemaDescriptor,
+) ([]catalog.FunctionDescriptor, error) {
+	var ids []descpb.ID
+	for _, fn := range sc.GetFunctions() {
+		for _, o := range fn.Overloads {
+			ids = append(ids, o.ID)
+		}
+	}
+	fnDescs := make([]catalog.FunctionDescriptor, len(ids))
+	for i, id := range ids {
+		fnDesc, err := p.Descriptors().GetImmutableFunctionByID(
+			ctx, p.txn, id, tree.ObjectLookupFlagsWithRequired(),
+		)
+		if err != nil {
+			return nil, err
+		}
+		fnDescs[i] = fnDesc
+	}
+	sort.Slice(fnDescs, func(i, j int) bool {
+		if fnDescs[i].GetName() != fnDescs[j].GetName() {
+			return fnDescs[i].GetName() < fnDescs[j].GetName()
+		}
+		return fnDescs[i].GetID() < fnDescs[j].GetID()
+	})
+	return fnDescs, nil
+}
+
 var pgCatalogRangeTable = virtualSchemaTable{
 	comment: `range types (empty - feature does not exist)
 https://www.postgresql.org/docs/9.5/catalog-pg-range.html`,
@@ -3161,12 +3283,38 @@ var pgCatalogTimezoneNamesTable = virtualSchemaTable{
 }
 
 var pgCatalogPublicationTablesTable = virtualSchemaTable{
-	comment: "pg_publication_tables was created for compatibility and is currently unimplemented",
-	schema:  vtable.PgCatalogPublicationTables,
-	populate: func(ctx context.Context, p *planner, _ catalog.DatabaseDescriptor, addRow func(...tree.Datum) error) error {
-		return nil
+	comment: `publications and the tables they contain
+https://www.postgresql.org/docs/13/view-pg-publication-tables.html`,
+	schema: vtable.PgCatalogPublicationTables,
+	populate: func(ctx context.Context, p *planner, dbContext catalog.DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		pubs, err := getPublications(ctx, p, dbContext)
+		if err != nil || len(pubs) == 0 {
+			return err
+		}
+		return forEachTableDesc(ctx, p, dbContext, hideVirtual, /* virtual tables cannot be published */
+			func(db catalog.DatabaseDescriptor, scName string, table catalog.TableDescriptor) error {
+				if !table.IsPhysicalTable() || table.IsSequence() || table.IsTemporary() {
+					return nil
+				}
+				for i := range pubs {
+					pub := &pubs[i]
+					if pub.dbID != db.GetID() {
+						continue
+					}
+					if _, ok := pub.tableIDs[table.GetID()]; !ok && !pub.allTables {
+						continue
+					}
+					if err := addRow(
+						tree.NewDName(pub.name),        // pubname
+						tree.NewDName(scName),          // schemaname
+						tree.NewDName(table.GetName()), // tablename
+					); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
 	},
-	unimplemented: true,
 }
 
 var pgCatalogUserMappingsTable = virtualSchemaTable{
@@ -3241,12 +3389,82 @@ https://www.postgresql.org/docs/13/view-pg-shadow.html`,
 }
 
 var pgCatalogPublicationTable = virtualSchemaTable{
-	comment: "pg_publication was created for compatibility and is currently unimplemented",
-	schema:  vtable.PgCatalogPublication,
-	populate: func(ctx context.Context, p *planner, _ catalog.DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+	comment: `publications created in the database
+https://www.postgresql.org/docs/13/catalog-pg-publication.html`,
+	schema: vtable.PgCatalogPublication,
+	populate: func(ctx context.Context, p *planner, dbContext catalog.DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		pubs, err := getPublications(ctx, p, dbContext)
+		if err != nil {
+			return err
+		}
+		h := makeOidHasher()
+		for _, pub := range pubs {
+			if err := addRow(
+				tree.DBoolTrue,                            // pubupdate
+				h.PublicationOid(pub.dbID, pub.name),      // oid
+				tree.MakeDBool(tree.DBool(pub.allTables)), // puballtables
+				tree.DBoolTrue,                            // pubdelete
+				tree.DBoolTrue,                            // pubinsert
+				tree.NewDName(pub.name),                   // pubname
+				h.UserOid(pub.owner),                      // pubowner
+				tree.DBoolFalse,                           // pubtruncate
+				tree.DBoolFalse,                           // pubviaroot
+			); err != nil {
+				return err
+			}
+		}
 		return nil
 	},
-	unimplemented: true,
+}
+
+// publicationInfo describes a row of system.publications.
+type publicationInfo struct {
+	dbID      descpb.ID
+	name      string
+	owner     security.SQLUsername
+	allTables bool
+	tableIDs  map[descpb.ID]struct{}
+}
+
+// getPublications returns the publications of the given database, or of all
+// databases if dbContext is nil.
+func getPublications(
+	ctx context.Context, p *planner, dbContext catalog.DatabaseDescriptor,
+) ([]publicationInfo, error) {
+	if !p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.PublicationsAndReplicationSlots) {
+		return nil, nil
+	}
+	rows, err := p.extendedEvalCtx.ExecCfg.InternalExecutor.QueryBufferedEx(
+		ctx,
+		"select-publications",
+		p.EvalContext().Txn,
+		sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+		`SELECT database_id, name, owner, all_tables, table_ids FROM system.public.publications`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	pubs := make([]publicationInfo, 0, len(rows))
+	for _, row := range rows {
+		dbID := descpb.ID(tree.MustBeDInt(row[0]))
+		if dbContext != nil && dbContext.GetID() != dbID {
+			continue
+		}
+		pub := publicationInfo{
+			dbID:      dbID,
+			name:      string(tree.MustBeDString(row[1])),
+			owner:     security.MakeSQLUsernameFromPreNormalizedString(string(tree.MustBeDString(row[2]))),
+			allTables: bool(tree.MustBeDBool(row[3])),
+			tableIDs:  make(map[descpb.ID]struct{}),
+		}
+		if row[4] != tree.DNull {
+			for _, id := range tree.MustBeDArray(row[4]).Array {
+				pub.tableIDs[descpb.ID(tree.MustBeDInt(id))] = struct{}{}
+			}
+		}
+		pubs = append(pubs, pub)
+	}
+	return pubs, nil
 }
 
 var pgCatalogGroupTable = virtualSchemaTable{
@@ -3430,12 +3648,61 @@ var pgCatalogLargeobjectTable = virtualSchemaTable{
 }
 
 var pgCatalogReplicationSlotsTable = virtualSchemaTable{
-	comment: "pg_replication_slots was created for compatibility and is currently unimplemented",
-	schema:  vtable.PgCatalogReplicationSlots,
+	comment: `replication slots that exist on the cluster
+https://www.postgresql.org/docs/13/view-pg-replication-slots.html`,
+	schema: vtable.PgCatalogReplicationSlots,
 	populate: func(ctx context.Context, p *planner, _ catalog.DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		if !p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.PublicationsAndReplicationSlots) {
+			return nil
+		}
+		rows, err := p.extendedEvalCtx.ExecCfg.InternalExecutor.QueryBufferedEx(
+			ctx,
+			"select-replication-slots",
+			p.EvalContext().Txn,
+			sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+			`SELECT name, database_id, plugin, confirmed_flush_lsn FROM system.public.replication_slots`,
+		)
+		if err != nil || len(rows) == 0 {
+			return err
+		}
+		dbNames := make(map[descpb.ID]string)
+		if err := forEachDatabaseDesc(ctx, p, nil /* dbContext */, false, /* requiresPrivileges */
+			func(db catalog.DatabaseDescriptor) error {
+				dbNames[db.GetID()] = db.GetName()
+				return nil
+			}); err != nil {
+			return err
+		}
+		for _, row := range rows {
+			dbID := descpb.ID(tree.MustBeDInt(row[1]))
+			database := tree.DNull
+			if name, ok := dbNames[dbID]; ok {
+				database = tree.NewDName(name)
+			}
+			slotName := tree.NewDName(string(tree.MustBeDString(row[0])))
+			plugin := tree.NewDName(string(tree.MustBeDString(row[2])))
+			confirmedLSN := tree.NewDString(lsn.LSN(tree.MustBeDInt(row[3])).String())
+			if err := addRow(
+				tree.DNull,                    // safe_wal_size
+				tree.NewDString("reserved"),   // wal_status
+				plugin,                        // plugin
+				confirmedLSN,                  // restart_lsn
+				tree.DNull,                    // xmin
+				confirmedLSN,                  // confirmed_flush_lsn
+				database,                      // database
+				tree.NewDOid(tree.DInt(dbID)), // datoid
+				tree.DBoolFalse,               // active
+				tree.DNull,                    // catalog_xmin
+				slotName,                      // slot_name
+				tree.DNull,                    // active_pid
+				tree.NewDString("logical"),    // slot_type
+				tree.DBoolFalse,               // temporary
+			); err != nil {
+				return err
+			}
+		}
 		return nil
 	},
-	unimplemented: true,
 }
 
 var pgCatalogInitPrivsTable = virtualSchemaTable{
@@ -4106,16 +4373,15 @@ func init() {
 // are 32 bits and that they are stable across accesses.
 //
 // The type has a few layers of methods:
-// - write<go_type> methods write concrete types to the underlying running hash.
-// - write<db_object> methods account for single database objects like TableDescriptors
-//   or IndexDescriptors in the running hash. These methods aim to write information
-//   that would uniquely fingerprint the object to the hash using the first layer of
-//   methods.
-// - <DB_Object>Oid methods use the second layer of methods to construct a unique
-//   object identifier for the provided database object. This object identifier will
-//   be returned as a *tree.DInt, and the running hash will be reset. These are the
-//   only methods that are part of the oidHasher's external facing interface.
-//
+//   - write<go_type> methods write concrete types to the underlying running hash.
+//   - write<db_object> methods account for single database objects like TableDescriptors
+//     or IndexDescriptors in the running hash. These methods aim to write information
+//     that would uniquely fingerprint the object to the hash using the first layer of
+//     methods.
+//   - <DB_Object>Oid methods use the second layer of methods to construct a unique
+//     object identifier for the provided database object. This object identifier will
+//     be returned as a *tree.DInt, and the running hash will be reset. These are the
+//     only methods that are part of the oidHasher's external facing interface.
 type oidHasher struct {
 	h hash.Hash32
 }
@@ -4176,6 +4442,8 @@ const (
 	enumEntryTypeTag
 	rewriteTypeTag
 	dbSchemaRoleTypeTag
+	publicationTypeTag
+	userDefinedFunctionTypeTag
 )
 
 func (h oidHasher) writeTypeTag(tag oidTypeTag) {
@@ -4317,6 +4585,19 @@ func (h oidHasher) UserOid(username security.SQLUsername) *tree.DOid {
 	return h.getOid()
 }
 
+func (h oidHasher) PublicationOid(dbID descpb.ID, name string) *tree.DOid {
+	h.writeTypeTag(publicationTypeTag)
+	h.writeDB(dbID)
+	h.writeStr(name)
+	return h.getOid()
+}
+
+func (h oidHasher) UserDefinedFunctionOid(fnID descpb.ID) *tree.DOid {
+	h.writeTypeTag(userDefinedFunctionTypeTag)
+	h.writeUInt32(uint32(fnID))
+	return h.getOid()
+}
+
 func (h oidHasher) CollationOid(collation string) *tree.DOid {
 	h.writeTypeTag(collationTypeTag)
 	h.writeStr(collation)
@@ -4372,7 +4653,7 @@ func stringOid(s string) *tree.DOid {
 	return h.getOid()
 }
 
-//MakeConstraintOidBuilder constructs an OID builder.
+// MakeConstraintOidBuilder constructs an OID builder.
 func MakeConstraintOidBuilder() commenter.ConstraintOidBuilder {
 	return makeOidHasher()
 }
@@ -315,6 +315,12 @@ func (r *commandResult) RowsAffected() int {
 	return r.rowsAffected
 }
 
+// ResetRows is part of the sql.RestrictedCommandResult interface.
+func (r *commandResult) ResetRows() {
+	r.assertNotReleased()
+	r.rowsAffected = 0
+}
+
 // ResetStmtType is part of the sql.RestrictedCommandResult interface.
 func (r *commandResult) ResetStmtType(stmt tree.Statement) {
 	r.assertNotReleased()
@@ -449,6 +455,12 @@ func (r *limitedCommandResult) AddRow(ctx context.Context, row tree.Datums) erro
 	return nil
 }
 
+// ResetRows is part of the sql.RestrictedCommandResult interface.
+func (r *limitedCommandResult) ResetRows() {
+	r.commandResult.ResetRows()
+	r.seenTuples = 0
+}
+
 // SupportsAddBatch is part of the sql.RestrictedCommandResult interface.
 // TODO(yuzefovich): implement limiting behavior for AddBatch.
 func (r *limitedCommandResult) SupportsAddBatch() bool {
@@ -767,6 +767,18 @@ func (c *conn) handleSimpleQuery(
 	}
 
 	startParse := timeutil.Now()
+	if c.sessionArgs.Replication {
+		// Connections in replication mode also accept the commands of the
+		// streaming replication protocol, which the SQL parser doesn't know
+		// about.
+		stmt, ok, err := parseReplicationCommand(query)
+		if err != nil {
+			return c.stmtBuf.Push(ctx, sql.SendError{Err: err})
+		}
+		if ok {
+			return c.handleReplicationCommand(ctx, query, stmt, timeReceived, startParse)
+		}
+	}
 	stmts, err := c.parser.ParseWithInt(query, unqualifiedIntSize)
 	if err != nil {
 		return c.stmtBuf.Push(ctx, sql.SendError{Err: err})
@@ -823,6 +835,34 @@ func (c *conn) handleSimpleQuery(
 	return nil
 }
 
+// handleReplicationCommand executes a command of the streaming replication
+// protocol. Like COPY, START_REPLICATION takes control of the connection, so
+// this network routine blocks until the replication stream ends.
+//
+// An error is returned iff the statement buffer has been closed. In that case,
+// the connection should be considered toast.
+func (c *conn) handleReplicationCommand(
+	ctx context.Context, query string, stmt tree.Statement, timeReceived, startParse time.Time,
+) error {
+	if sr, ok := stmt.(*tree.StartReplication); ok {
+		done := sync.WaitGroup{}
+		done.Add(1)
+		if err := c.stmtBuf.Push(ctx, sql.StartReplication{Conn: c, Stmt: sr, Done: &done}); err != nil {
+			return err
+		}
+		done.Wait()
+		return nil
+	}
+	return c.stmtBuf.Push(
+		ctx,
+		sql.ExecStmt{
+			Statement:    parser.Statement{AST: stmt, SQL: query},
+			TimeReceived: timeReceived,
+			ParseStart:   startParse,
+			ParseEnd:     timeutil.Now(),
+		})
+}
+
 // An error is returned iff the statement buffer has been closed. In that case,
 // the connection should be considered toast.
 func (c *conn) handleParse(
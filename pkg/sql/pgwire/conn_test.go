@@ -1508,6 +1508,22 @@ func TestParseClientProvidedSessionParameters(t *testing.T) {
 				require.Equal(t, "ISO,YMD", args.SessionDefaults["datestyle"])
 			},
 		},
+		{
+			desc:  "logical replication mode",
+			query: "user=root&replication=database",
+			assert: func(t *testing.T, args sql.SessionArgs, err error) {
+				require.NoError(t, err)
+				require.True(t, args.Replication)
+			},
+		},
+		{
+			desc:  "physical replication mode is not supported",
+			query: "user=root&replication=true",
+			assert: func(t *testing.T, args sql.SessionArgs, err error) {
+				require.Error(t, err)
+				require.Regexp(t, "physical replication is not supported", err)
+			},
+		},
 	}
 
 	baseURL := fmt.Sprintf("postgres://%s/system?sslmode=disable", serverAddr)
@@ -14,6 +14,9 @@ func _() {
 	_ = x[ServerMsgCommandComplete-67]
 	_ = x[ServerMsgCloseComplete-51]
 	_ = x[ServerMsgCopyInResponse-71]
+	_ = x[ServerMsgCopyBothResponse-87]
+	_ = x[ServerMsgCopyData-100]
+	_ = x[ServerMsgCopyDone-99]
 	_ = x[ServerMsgDataRow-68]
 	_ = x[ServerMsgEmptyQuery-73]
 	_ = x[ServerMsgErrorResponse-69]
@@ -27,53 +30,34 @@ func _() {
 	_ = x[ServerMsgRowDescription-84]
 }
 
-const (
-	_ServerMessageType_name_0 = "ServerMsgParseCompleteServerMsgBindCompleteServerMsgCloseComplete"
-	_ServerMessageType_name_1 = "ServerMsgCommandCompleteServerMsgDataRowServerMsgErrorResponse"
-	_ServerMessageType_name_2 = "ServerMsgCopyInResponse"
-	_ServerMessageType_name_3 = "ServerMsgEmptyQuery"
-	_ServerMessageType_name_4 = "ServerMsgBackendKeyData"
-	_ServerMessageType_name_5 = "ServerMsgNoticeResponse"
-	_ServerMessageType_name_6 = "ServerMsgAuthServerMsgParameterStatusServerMsgRowDescription"
-	_ServerMessageType_name_7 = "ServerMsgReady"
-	_ServerMessageType_name_8 = "ServerMsgNoData"
-	_ServerMessageType_name_9 = "ServerMsgPortalSuspendedServerMsgParameterDescription"
-)
+const _ServerMessageType_name = "ServerMsgParseCompleteServerMsgBindCompleteServerMsgCloseCompleteServerMsgCommandCompleteServerMsgDataRowServerMsgErrorResponseServerMsgCopyInResponseServerMsgEmptyQueryServerMsgBackendKeyDataServerMsgNoticeResponseServerMsgAuthServerMsgParameterStatusServerMsgRowDescriptionServerMsgCopyBothResponseServerMsgReadyServerMsgCopyDoneServerMsgCopyDataServerMsgNoDataServerMsgPortalSuspendedServerMsgParameterDescription"
 
-var (
-	_ServerMessageType_index_0 = [...]uint8{0, 22, 43, 65}
-	_ServerMessageType_index_1 = [...]uint8{0, 24, 40, 62}
-	_ServerMessageType_index_6 = [...]uint8{0, 13, 37, 60}
-	_ServerMessageType_index_9 = [...]uint8{0, 24, 53}
-)
+var _ServerMessageType_map = map[ServerMessageType]string{
+	49:  _ServerMessageType_name[0:22],
+	50:  _ServerMessageType_name[22:43],
+	51:  _ServerMessageType_name[43:65],
+	67:  _ServerMessageType_name[65:89],
+	68:  _ServerMessageType_name[89:105],
+	69:  _ServerMessageType_name[105:127],
+	71:  _ServerMessageType_name[127:150],
+	73:  _ServerMessageType_name[150:169],
+	75:  _ServerMessageType_name[169:192],
+	78:  _ServerMessageType_name[192:215],
+	82:  _ServerMessageType_name[215:228],
+	83:  _ServerMessageType_name[228:252],
+	84:  _ServerMessageType_name[252:275],
+	87:  _ServerMessageType_name[275:300],
+	90:  _ServerMessageType_name[300:314],
+	99:  _ServerMessageType_name[314:331],
+	100: _ServerMessageType_name[331:348],
+	110: _ServerMessageType_name[348:363],
+	115: _ServerMessageType_name[363:387],
+	116: _ServerMessageType_name[387:416],
+}
 
 func (i ServerMessageType) String() string {
-	switch {
-	case 49 <= i && i <= 51:
-		i -= 49
-		return _ServerMessageType_name_0[_ServerMessageType_index_0[i]:_ServerMessageType_index_0[i+1]]
-	case 67 <= i && i <= 69:
-		i -= 67
-		return _ServerMessageType_name_1[_ServerMessageType_index_1[i]:_ServerMessageType_index_1[i+1]]
-	case i == 71:
-		return _ServerMessageType_name_2
-	case i == 73:
-		return _ServerMessageType_name_3
-	case i == 75:
-		return _ServerMessageType_name_4
-	case i == 78:
-		return _ServerMessageType_name_5
-	case 82 <= i && i <= 84:
-		i -= 82
-		return _ServerMessageType_name_6[_ServerMessageType_index_6[i]:_ServerMessageType_index_6[i+1]]
-	case i == 90:
-		return _ServerMessageType_name_7
-	case i == 110:
-		return _ServerMessageType_name_8
-	case 115 <= i && i <= 116:
-		i -= 115
-		return _ServerMessageType_name_9[_ServerMessageType_index_9[i]:_ServerMessageType_index_9[i+1]]
-	default:
-		return "ServerMessageType(" + strconv.FormatInt(int64(i), 10) + ")"
+	if str, ok := _ServerMessageType_map[i]; ok {
+		return str
 	}
+	return "ServerMessageType(" + strconv.FormatInt(int64(i), 10) + ")"
 }
@@ -0,0 +1,426 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package pgwire
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirebase"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondatapb"
+	"github.com/cockroachdb/cockroach/pkg/util/lsn"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// replicationKeepaliveInterval is the interval at which keepalive messages are
+// sent to replication clients when there are no changes to stream.
+var replicationKeepaliveInterval = 10 * time.Second
+
+// The messages of the streaming replication protocol are sent in CopyData
+// messages, and start with a byte identifying their type.
+const (
+	replicationMsgXLogData            = 'w'
+	replicationMsgKeepalive           = 'k'
+	replicationMsgStandbyStatusUpdate = 'r'
+	replicationMsgHotStandbyFeedback  = 'h'
+)
+
+// replicationClientMsg is a message received from the client during a
+// replication stream.
+type replicationClientMsg struct {
+	// flushed is the position up to which the client has durably stored the
+	// changes, as reported by a standby status update.
+	flushed lsn.LSN
+	// replyRequested is set if the client asked for a keepalive message.
+	replyRequested bool
+	// done is set if the client ended the stream with CopyDone.
+	done bool
+	err  error
+}
+
+// ServeReplication is part of the sql.ReplicationConn interface.
+//
+// The transactions produced by the feed are sent in the format of the
+// pgoutput logical decoding plugin, until the client ends the stream with a
+// CopyDone message.
+func (c *conn) ServeReplication(
+	ctx context.Context,
+	feed *sql.ReplicationFeed,
+	conv sessiondatapb.DataConversionConfig,
+	sessionLoc *time.Location,
+) (retErr error) {
+	// Start the copy-both sub-protocol.
+	c.msgBuilder.initMsg(pgwirebase.ServerMsgCopyBothResponse)
+	c.msgBuilder.writeByte(byte(pgwirebase.FormatText))
+	c.msgBuilder.putInt16(0)
+	if err := c.msgBuilder.finishMsg(c.conn); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer func() {
+		if retErr != nil {
+			// The client may still be sending messages of the copy-both
+			// sub-protocol, which can't be told apart from regular messages once
+			// control of the connection is handed back. Like postgres, report the
+			// error and end the connection, which also unblocks the reader below.
+			_ = writeErr(ctx, c.sv, retErr, &c.msgBuilder, c.conn)
+			_ = c.conn.Close()
+		}
+		cancel()
+		wg.Wait()
+	}()
+
+	msgs := make(chan replicationClientMsg)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		c.readReplicationMessages(ctx, msgs)
+	}()
+
+	type txnOrErr struct {
+		txn sql.ReplicationTxn
+		err error
+	}
+	txns := make(chan txnOrErr)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			txn, err := feed.Next(ctx)
+			select {
+			case txns <- txnOrErr{txn: txn, err: err}:
+			case <-ctx.Done():
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	enc := pgoutputEncoder{
+		conv:       conv,
+		sessionLoc: sessionLoc,
+		relations:  make(map[descpb.ID]descpb.DescriptorVersion),
+	}
+	sent := feed.StartLSN()
+	sendKeepalive := func(replyRequested bool) error {
+		walEnd := sent
+		if resolved := feed.ResolvedLSN(); resolved > walEnd {
+			walEnd = resolved
+		}
+		return writeKeepalive(&c.msgBuilder, c.conn, walEnd, timeutil.Now(), replyRequested)
+	}
+
+	ticker := time.NewTicker(replicationKeepaliveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case t := <-txns:
+			if t.err != nil {
+				return t.err
+			}
+			if err := enc.encodeTxn(ctx, &c.msgBuilder, c.conn, t.txn, timeutil.Now()); err != nil {
+				return err
+			}
+			sent = t.txn.LSN.CommitLSN()
+		case m := <-msgs:
+			if m.err != nil {
+				return m.err
+			}
+			if m.done {
+				c.msgBuilder.initMsg(pgwirebase.ServerMsgCopyDone)
+				if err := c.msgBuilder.finishMsg(c.conn); err != nil {
+					return err
+				}
+				return c.SendCommandComplete([]byte("START_STREAMING"))
+			}
+			if err := feed.ConfirmFlush(ctx, m.flushed); err != nil {
+				return err
+			}
+			if m.replyRequested {
+				if err := sendKeepalive(false /* replyRequested */); err != nil {
+					return err
+				}
+			}
+		case <-ticker.C:
+			if err := sendKeepalive(true /* replyRequested */); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// readReplicationMessages reads the messages sent by the client during a
+// replication stream, until it receives CopyDone or an error occurs.
+func (c *conn) readReplicationMessages(ctx context.Context, msgs chan<- replicationClientMsg) {
+	send := func(m replicationClientMsg) bool {
+		select {
+		case msgs <- m:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	readBuf := pgwirebase.MakeReadBuffer(pgwirebase.ReadBufferOptionWithClusterSettings(c.sv))
+	for {
+		typ, _, err := readBuf.ReadTypedMsg(c.Rd())
+		if err != nil {
+			send(replicationClientMsg{err: err})
+			return
+		}
+		var m replicationClientMsg
+		switch typ {
+		case pgwirebase.ClientMsgCopyData:
+			m, err = parseReplicationClientMsg(&readBuf)
+			if err != nil {
+				send(replicationClientMsg{err: err})
+				return
+			}
+		case pgwirebase.ClientMsgCopyDone:
+			send(replicationClientMsg{done: true})
+			return
+		case pgwirebase.ClientMsgCopyFail:
+			msg, _ := readBuf.GetString()
+			send(replicationClientMsg{
+				err: pgwirebase.NewProtocolViolationErrorf("replication stream failed: %s", msg),
+			})
+			return
+		case pgwirebase.ClientMsgFlush, pgwirebase.ClientMsgSync:
+			continue
+		default:
+			send(replicationClientMsg{err: pgwirebase.NewUnrecognizedMsgTypeErr(typ)})
+			return
+		}
+		if !send(m) {
+			return
+		}
+	}
+}
+
+// parseReplicationClientMsg parses the content of a CopyData message sent by
+// the client during a replication stream.
+func parseReplicationClientMsg(buf *pgwirebase.ReadBuffer) (replicationClientMsg, error) {
+	typ, err := buf.GetBytes(1)
+	if err != nil {
+		return replicationClientMsg{}, err
+	}
+	switch typ[0] {
+	case replicationMsgStandbyStatusUpdate:
+		// The positions written, flushed and applied by the client, followed by
+		// the time of the client and whether it requests a reply.
+		if _, err := buf.GetUint64(); err != nil {
+			return replicationClientMsg{}, err
+		}
+		flushed, err := buf.GetUint64()
+		if err != nil {
+			return replicationClientMsg{}, err
+		}
+		if _, err := buf.GetBytes(16); err != nil {
+			return replicationClientMsg{}, err
+		}
+		reply, err := buf.GetBytes(1)
+		if err != nil {
+			return replicationClientMsg{}, err
+		}
+		return replicationClientMsg{flushed: lsn.LSN(flushed), replyRequested: reply[0] == 1}, nil
+	case replicationMsgHotStandbyFeedback:
+		// Only meaningful for physical replication.
+		return replicationClientMsg{}, nil
+	default:
+		return replicationClientMsg{}, pgwirebase.NewProtocolViolationErrorf(
+			"unexpected replication message type %q", typ[0])
+	}
+}
+
+// writeKeepalive writes a primary keepalive message, which reports the current
+// end of the stream.
+func writeKeepalive(
+	b *writeBuffer, w io.Writer, walEnd lsn.LSN, now time.Time, replyRequested bool,
+) error {
+	b.initMsg(pgwirebase.ServerMsgCopyData)
+	b.writeByte(replicationMsgKeepalive)
+	b.putInt64(int64(walEnd))
+	b.putInt64(timeToPgBinary(now, nil))
+	if replyRequested {
+		b.writeByte(1)
+	} else {
+		b.writeByte(0)
+	}
+	return b.finishMsg(w)
+}
+
+// pgoutputEncoder encodes transactions in the format of version 1 of the
+// pgoutput logical decoding plugin, see:
+// https://www.postgresql.org/docs/current/protocol-logicalrep-message-formats.html
+//
+// Table IDs are used as relation OIDs, and the changes of a transaction are
+// reported with the LSN of the transaction. Virtual computed columns are not
+// replicated, like generated columns in postgres.
+type pgoutputEncoder struct {
+	conv       sessiondatapb.DataConversionConfig
+	sessionLoc *time.Location
+	// relations maps the IDs of the tables that were described to the client
+	// with a Relation message to the version of the table that was described.
+	relations map[descpb.ID]descpb.DescriptorVersion
+	// xid is the identifier of the last transaction.
+	xid uint32
+}
+
+// encodeTxn writes the messages of a transaction: Begin, the changes, preceded
+// by Relation messages for the tables that weren't described yet, and
+// Commit.
+func (e *pgoutputEncoder) encodeTxn(
+	ctx context.Context, b *writeBuffer, w io.Writer, txn sql.ReplicationTxn, now time.Time,
+) error {
+	e.xid++
+	commitTime := timeToPgBinary(txn.CommitTime, nil)
+	commitLSN := txn.LSN.CommitLSN()
+
+	e.initXLogData(b, txn.LSN, now)
+	b.writeByte('B')
+	b.putInt64(int64(commitLSN)) // final LSN of the transaction
+	b.putInt64(commitTime)
+	b.putInt32(int32(e.xid))
+	if err := b.finishMsg(w); err != nil {
+		return err
+	}
+
+	for i := range txn.Changes {
+		change := &txn.Changes[i]
+		table := change.Table
+		if v, ok := e.relations[table.GetID()]; !ok || v != table.GetVersion() {
+			e.initXLogData(b, txn.LSN, now)
+			e.writeRelation(b, change.SchemaName, table)
+			if err := b.finishMsg(w); err != nil {
+				return err
+			}
+			e.relations[table.GetID()] = table.GetVersion()
+		}
+
+		e.initXLogData(b, txn.LSN, now)
+		switch change.Kind {
+		case sql.ReplicationInsert:
+			b.writeByte('I')
+			b.putInt32(int32(table.GetID()))
+			b.writeByte('N')
+			e.writeTuple(ctx, b, table, change.Row)
+		case sql.ReplicationUpdate:
+			b.writeByte('U')
+			b.putInt32(int32(table.GetID()))
+			if change.PrevRow != nil {
+				b.writeByte('O')
+				e.writeTuple(ctx, b, table, change.PrevRow)
+			}
+			b.writeByte('N')
+			e.writeTuple(ctx, b, table, change.Row)
+		case sql.ReplicationDelete:
+			b.writeByte('D')
+			b.putInt32(int32(table.GetID()))
+			if change.PrevRow != nil {
+				b.writeByte('O')
+				e.writeTuple(ctx, b, table, change.PrevRow)
+			} else {
+				b.writeByte('K')
+				e.writeTuple(ctx, b, table, change.Row)
+			}
+		}
+		if err := b.finishMsg(w); err != nil {
+			return err
+		}
+	}
+
+	e.initXLogData(b, commitLSN, now)
+	b.writeByte('C')
+	b.writeByte(0)               // flags
+	b.putInt64(int64(commitLSN)) // LSN of the commit
+	b.putInt64(int64(commitLSN)) // end LSN of the transaction
+	b.putInt64(commitTime)
+	return b.finishMsg(w)
+}
+
+// initXLogData starts a CopyData message containing an XLogData message, in
+// which the pgoutput messages are sent.
+func (e *pgoutputEncoder) initXLogData(b *writeBuffer, l lsn.LSN, now time.Time) {
+	b.initMsg(pgwirebase.ServerMsgCopyData)
+	b.writeByte(replicationMsgXLogData)
+	b.putInt64(int64(l)) // start of the data
+	b.putInt64(int64(l)) // end of the stream
+	b.putInt64(timeToPgBinary(now, nil))
+}
+
+// writeRelation writes a Relation message describing the replicated columns
+// of the table. The primary key is used as the replica identity.
+func (e *pgoutputEncoder) writeRelation(
+	b *writeBuffer, schemaName string, table catalog.TableDescriptor,
+) {
+	keyCols := table.GetPrimaryIndex().CollectKeyColumnIDs()
+	b.writeByte('R')
+	b.putInt32(int32(table.GetID()))
+	b.writeTerminatedString(schemaName)
+	b.writeTerminatedString(table.GetName())
+	b.writeByte('d') // replica identity
+	cols := table.PublicColumns()
+	b.putInt16(int16(numReplicatedColumns(cols)))
+	for _, col := range cols {
+		if col.IsVirtual() {
+			continue
+		}
+		if keyCols.Contains(col.GetID()) {
+			b.writeByte(1)
+		} else {
+			b.writeByte(0)
+		}
+		b.writeTerminatedString(col.GetName())
+		b.putInt32(int32(col.GetType().Oid()))
+		b.putInt32(col.GetType().TypeModifier())
+	}
+}
+
+// writeTuple writes the values of the replicated columns of a row, in the
+// text format.
+func (e *pgoutputEncoder) writeTuple(
+	ctx context.Context, b *writeBuffer, table catalog.TableDescriptor, row tree.Datums,
+) {
+	cols := table.PublicColumns()
+	b.putInt16(int16(numReplicatedColumns(cols)))
+	for i, col := range cols {
+		if col.IsVirtual() {
+			continue
+		}
+		if row[i] == tree.DNull {
+			b.writeByte('n')
+			continue
+		}
+		b.writeByte('t')
+		b.writeTextDatum(ctx, row[i], e.conv, e.sessionLoc, col.GetType())
+	}
+}
+
+func numReplicatedColumns(cols []catalog.Column) int {
+	n := 0
+	for _, col := range cols {
+		if !col.IsVirtual() {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,309 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package pgwire
+
+import (
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/cockroach/pkg/util/lsn"
+)
+
+// The commands of the streaming replication protocol are accepted on
+// connections established with the replication=database parameter. Their
+// grammar is separate from the SQL grammar, see:
+// https://www.postgresql.org/docs/current/protocol-replication.html
+
+type replicationTokenKind int
+
+const (
+	replicationTokenWord replicationTokenKind = iota
+	replicationTokenIdent
+	replicationTokenString
+	replicationTokenPunct
+)
+
+type replicationToken struct {
+	kind replicationTokenKind
+	val  string
+}
+
+// replicationLexer splits a replication command into tokens. Unquoted words
+// are returned as is, and quoted identifiers and strings are unescaped.
+type replicationLexer struct {
+	in   string
+	toks []replicationToken
+}
+
+func (l *replicationLexer) lex() error {
+	for i := 0; i < len(l.in); {
+		c := l.in[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(' || c == ')' || c == ',' || c == ';':
+			l.toks = append(l.toks, replicationToken{kind: replicationTokenPunct, val: string(c)})
+			i++
+		case c == '"' || c == '\'':
+			var sb strings.Builder
+			j := i + 1
+			for {
+				if j >= len(l.in) {
+					return pgerror.Newf(pgcode.Syntax, "unterminated quoted string at or near %q", l.in[i:])
+				}
+				if l.in[j] == c {
+					if j+1 < len(l.in) && l.in[j+1] == c {
+						sb.WriteByte(c)
+						j += 2
+						continue
+					}
+					break
+				}
+				sb.WriteByte(l.in[j])
+				j++
+			}
+			kind := replicationTokenString
+			if c == '"' {
+				kind = replicationTokenIdent
+			}
+			l.toks = append(l.toks, replicationToken{kind: kind, val: sb.String()})
+			i = j + 1
+		default:
+			j := i
+			for j < len(l.in) && !strings.ContainsRune(" \t\n\r(),;\"'", rune(l.in[j])) {
+				j++
+			}
+			l.toks = append(l.toks, replicationToken{kind: replicationTokenWord, val: l.in[i:j]})
+			i = j
+		}
+	}
+	// A trailing semicolon is allowed.
+	if n := len(l.toks); n > 0 && l.toks[n-1] == (replicationToken{kind: replicationTokenPunct, val: ";"}) {
+		l.toks = l.toks[:n-1]
+	}
+	return nil
+}
+
+// replicationParser parses the tokens of a replication command.
+type replicationParser struct {
+	toks []replicationToken
+}
+
+func (p *replicationParser) errorf(format string, args ...interface{}) error {
+	return pgerror.Newf(pgcode.Syntax, format, args...)
+}
+
+func (p *replicationParser) peek() (replicationToken, bool) {
+	if len(p.toks) == 0 {
+		return replicationToken{}, false
+	}
+	return p.toks[0], true
+}
+
+func (p *replicationParser) next() (replicationToken, bool) {
+	t, ok := p.peek()
+	if ok {
+		p.toks = p.toks[1:]
+	}
+	return t, ok
+}
+
+// keyword consumes the next token if it is the given keyword.
+func (p *replicationParser) keyword(kw string) bool {
+	t, ok := p.peek()
+	if ok && t.kind == replicationTokenWord && strings.EqualFold(t.val, kw) {
+		p.toks = p.toks[1:]
+		return true
+	}
+	return false
+}
+
+func (p *replicationParser) expectKeyword(kw string) error {
+	if !p.keyword(kw) {
+		return p.unexpected()
+	}
+	return nil
+}
+
+func (p *replicationParser) expectPunct(punct string) error {
+	t, ok := p.next()
+	if !ok || t.kind != replicationTokenPunct || t.val != punct {
+		return p.errorf("syntax error: expected %q", punct)
+	}
+	return nil
+}
+
+func (p *replicationParser) unexpected() error {
+	t, ok := p.peek()
+	if !ok {
+		return p.errorf("syntax error at end of input")
+	}
+	return p.errorf("syntax error at or near %q", t.val)
+}
+
+// name parses an identifier. Unquoted identifiers are folded to lower case.
+func (p *replicationParser) name() (tree.Name, error) {
+	t, ok := p.next()
+	if !ok {
+		return "", p.errorf("syntax error at end of input")
+	}
+	switch t.kind {
+	case replicationTokenWord:
+		return tree.Name(strings.ToLower(t.val)), nil
+	case replicationTokenIdent:
+		return tree.Name(t.val), nil
+	default:
+		return "", p.errorf("syntax error at or near %q", t.val)
+	}
+}
+
+func (p *replicationParser) done() error {
+	if len(p.toks) > 0 {
+		return p.unexpected()
+	}
+	return nil
+}
+
+// parseReplicationCommand parses a command of the streaming replication
+// protocol. It returns false if the query is not a replication command, in
+// which case it should be parsed as SQL.
+func parseReplicationCommand(query string) (tree.Statement, bool, error) {
+	l := replicationLexer{in: query}
+	if err := l.lex(); err != nil {
+		// Let the SQL parser report the error.
+		return nil, false, nil //nolint:returnerrcheck
+	}
+	p := replicationParser{toks: l.toks}
+	first, ok := p.next()
+	if !ok || first.kind != replicationTokenWord {
+		return nil, false, nil
+	}
+	var stmt tree.Statement
+	var err error
+	switch strings.ToUpper(first.val) {
+	case "IDENTIFY_SYSTEM":
+		stmt, err = &tree.IdentifySystem{}, p.done()
+	case "CREATE_REPLICATION_SLOT":
+		stmt, err = p.parseCreateReplicationSlot()
+	case "DROP_REPLICATION_SLOT":
+		stmt, err = p.parseDropReplicationSlot()
+	case "START_REPLICATION":
+		stmt, err = p.parseStartReplication()
+	case "TIMELINE_HISTORY", "BASE_BACKUP", "READ_REPLICATION_SLOT":
+		err = unimplemented.Newf("replication."+strings.ToLower(first.val),
+			"replication command %s is not supported", strings.ToUpper(first.val))
+	default:
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, true, err
+	}
+	return stmt, true, nil
+}
+
+// parseCreateReplicationSlot parses:
+//   CREATE_REPLICATION_SLOT slot_name [ TEMPORARY ] { PHYSICAL | LOGICAL output_plugin } [ options ]
+// The options only control the export of a snapshot, which isn't supported,
+// and are ignored.
+func (p *replicationParser) parseCreateReplicationSlot() (tree.Statement, error) {
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if p.keyword("TEMPORARY") {
+		return nil, unimplemented.New("replication.temporary_slot",
+			"temporary replication slots are not supported")
+	}
+	if p.keyword("PHYSICAL") {
+		return nil, unimplemented.New("replication.physical",
+			"physical replication is not supported")
+	}
+	if err := p.expectKeyword("LOGICAL"); err != nil {
+		return nil, err
+	}
+	plugin, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	return &tree.CreateReplicationSlot{Name: name, Plugin: plugin}, nil
+}
+
+// parseDropReplicationSlot parses:
+//   DROP_REPLICATION_SLOT slot_name [ WAIT ]
+func (p *replicationParser) parseDropReplicationSlot() (tree.Statement, error) {
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	wait := p.keyword("WAIT")
+	if err := p.done(); err != nil {
+		return nil, err
+	}
+	return &tree.DropReplicationSlot{Name: name, Wait: wait}, nil
+}
+
+// parseStartReplication parses:
+//   START_REPLICATION SLOT slot_name LOGICAL XXX/XXX [ ( option_name [ option_value ] [, ...] ) ]
+func (p *replicationParser) parseStartReplication() (tree.Statement, error) {
+	if err := p.expectKeyword("SLOT"); err != nil {
+		return nil, err
+	}
+	slot, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if !p.keyword("LOGICAL") {
+		if p.keyword("PHYSICAL") {
+			return nil, unimplemented.New("replication.physical",
+				"physical replication is not supported")
+		}
+		return nil, p.unexpected()
+	}
+	t, ok := p.next()
+	if !ok || t.kind != replicationTokenWord {
+		return nil, p.errorf("syntax error: expected LSN")
+	}
+	startLSN, err := lsn.Parse(t.val)
+	if err != nil {
+		return nil, pgerror.WithCandidateCode(err, pgcode.Syntax)
+	}
+	stmt := &tree.StartReplication{Slot: slot, StartLSN: startLSN}
+	if t, ok := p.peek(); ok && t.val == "(" && t.kind == replicationTokenPunct {
+		p.toks = p.toks[1:]
+		for {
+			key, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			opt := tree.ReplicationOption{Key: key}
+			if t, ok := p.peek(); ok && t.kind == replicationTokenString {
+				opt.Value = t.val
+				p.toks = p.toks[1:]
+			}
+			stmt.Options = append(stmt.Options, opt)
+			if t, ok := p.peek(); ok && t.kind == replicationTokenPunct && t.val == "," {
+				p.toks = p.toks[1:]
+				continue
+			}
+			if err := p.expectPunct(")"); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	if err := p.done(); err != nil {
+		return nil, err
+	}
+	return stmt, nil
+}
@@ -0,0 +1,125 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package pgwire
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReplicationCommand(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	testCases := []struct {
+		query string
+		// isReplication is false if the query should be handed to the SQL parser.
+		isReplication bool
+		expected      string
+		expectedErr   string
+	}{
+		{query: `SELECT 1`},
+		{query: `CREATE PUBLICATION p FOR ALL TABLES`},
+		{query: `SELECT 'unterminated`},
+
+		{query: `IDENTIFY_SYSTEM`, isReplication: true, expected: `IDENTIFY_SYSTEM`},
+		{query: `identify_system;`, isReplication: true, expected: `IDENTIFY_SYSTEM`},
+		{query: `IDENTIFY_SYSTEM foo`, isReplication: true, expectedErr: `syntax error at or near "foo"`},
+
+		{
+			query:         `CREATE_REPLICATION_SLOT s LOGICAL pgoutput`,
+			isReplication: true,
+			expected:      `CREATE_REPLICATION_SLOT s LOGICAL pgoutput`,
+		},
+		{
+			query:         `CREATE_REPLICATION_SLOT "S" LOGICAL pgoutput NOEXPORT_SNAPSHOT`,
+			isReplication: true,
+			expected:      `CREATE_REPLICATION_SLOT "S" LOGICAL pgoutput`,
+		},
+		{
+			query:         `CREATE_REPLICATION_SLOT s TEMPORARY LOGICAL pgoutput`,
+			isReplication: true,
+			expectedErr:   `temporary replication slots are not supported`,
+		},
+		{
+			query:         `CREATE_REPLICATION_SLOT s PHYSICAL`,
+			isReplication: true,
+			expectedErr:   `physical replication is not supported`,
+		},
+		{
+			query:         `CREATE_REPLICATION_SLOT s`,
+			isReplication: true,
+			expectedErr:   `syntax error at end of input`,
+		},
+
+		{query: `DROP_REPLICATION_SLOT s`, isReplication: true, expected: `DROP_REPLICATION_SLOT s`},
+		{query: `DROP_REPLICATION_SLOT s WAIT`, isReplication: true, expected: `DROP_REPLICATION_SLOT s WAIT`},
+
+		{
+			query:         `START_REPLICATION SLOT s LOGICAL 0/0`,
+			isReplication: true,
+			expected:      `START_REPLICATION SLOT s LOGICAL 0/0`,
+		},
+		{
+			query:         `START_REPLICATION SLOT s LOGICAL 16B3748/1A2B3C4D (proto_version '1', publication_names '"P",q')`,
+			isReplication: true,
+			expected:      `START_REPLICATION SLOT s LOGICAL 16B3748/1A2B3C4D (proto_version '1', publication_names '"P",q')`,
+		},
+		{
+			query:         `START_REPLICATION SLOT s LOGICAL 0/0 (binary)`,
+			isReplication: true,
+			expected:      `START_REPLICATION SLOT s LOGICAL 0/0 (binary)`,
+		},
+		{
+			query:         `START_REPLICATION SLOT s LOGICAL 0/0 (proto_version '1'`,
+			isReplication: true,
+			expectedErr:   `syntax error: expected ")"`,
+		},
+		{
+			query:         `START_REPLICATION SLOT s LOGICAL foo`,
+			isReplication: true,
+			expectedErr:   `invalid LSN`,
+		},
+		{
+			query:         `START_REPLICATION SLOT s PHYSICAL 0/0`,
+			isReplication: true,
+			expectedErr:   `physical replication is not supported`,
+		},
+		{
+			query:         `START_REPLICATION 0/0`,
+			isReplication: true,
+			expectedErr:   `syntax error at or near "0/0"`,
+		},
+
+		{query: `BASE_BACKUP`, isReplication: true, expectedErr: `replication command BASE_BACKUP is not supported`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			stmt, isReplication, err := parseReplicationCommand(tc.query)
+			require.Equal(t, tc.isReplication, isReplication)
+			if tc.expectedErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			if !tc.isReplication {
+				require.Nil(t, stmt)
+				return
+			}
+			require.Equal(t, tc.expected, tree.AsString(stmt))
+		})
+	}
+}
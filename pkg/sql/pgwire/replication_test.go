@@ -0,0 +1,182 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package pgwire
+
+import (
+	"context"
+	"encoding/binary"
+	"net/url"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgproto3/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// TestReplicationSlotProtectedTimestamp checks that a replication slot
+// protects the changes which haven't been confirmed by its client from garbage
+// collection, and that the protection is advanced as the client confirms the
+// changes and released when the slot is dropped.
+func TestReplicationSlotProtectedTimestamp(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	sqlDB.Exec(t, `CREATE TABLE t (k INT PRIMARY KEY, v STRING)`)
+	sqlDB.Exec(t, `CREATE PUBLICATION p FOR TABLE t`)
+
+	pgURL, cleanupFunc := sqlutils.PGUrl(
+		t, s.ServingSQLAddr(), t.Name(), url.User(security.RootUser),
+	)
+	defer cleanupFunc()
+	pgURL.Path = "defaultdb"
+	q := pgURL.Query()
+	q.Add("replication", "database")
+	pgURL.RawQuery = q.Encode()
+	connect := func(t *testing.T) *pgconn.PgConn {
+		conn, err := pgconn.Connect(ctx, pgURL.String())
+		require.NoError(t, err)
+		return conn
+	}
+
+	conn := connect(t)
+	defer func() { _ = conn.Close(ctx) }()
+	_, err := conn.Exec(ctx, `CREATE_REPLICATION_SLOT s LOGICAL pgoutput`).ReadAll()
+	require.NoError(t, err)
+
+	const recordQuery = `
+SELECT r.ts FROM system.replication_slots AS s
+JOIN system.protected_ts_records AS r ON r.id = s.protected_timestamp_record
+WHERE s.name = 's' AND r.meta_type = 'replication_slot'`
+	var createdTS string
+	sqlDB.QueryRow(t, recordQuery).Scan(&createdTS)
+
+	startReplication := func(t *testing.T, conn *pgconn.PgConn) {
+		require.NoError(t, conn.SendBytes(ctx, (&pgproto3.Query{
+			String: `START_REPLICATION SLOT s LOGICAL 0/0 (proto_version '1', publication_names 'p')`,
+		}).Encode(nil)))
+		for {
+			msg, err := conn.ReceiveMessage(ctx)
+			require.NoError(t, err)
+			switch msg := msg.(type) {
+			case *pgproto3.CopyBothResponse:
+				return
+			case *pgproto3.ErrorResponse:
+				t.Fatalf("unexpected error: %s", msg.Message)
+			}
+		}
+	}
+
+	t.Run("advance", func(t *testing.T) {
+		startReplication(t, conn)
+		sqlDB.Exec(t, `INSERT INTO t VALUES (1, 'a')`)
+
+		// Wait for the commit of the transaction, and confirm that it was flushed.
+		var commitEnd uint64
+		for commitEnd == 0 {
+			msg, err := conn.ReceiveMessage(ctx)
+			require.NoError(t, err)
+			data, ok := msg.(*pgproto3.CopyData)
+			if !ok {
+				t.Fatalf("unexpected message %T", msg)
+			}
+			// An XLogData message has a 25 byte header, followed by a pgoutput
+			// message. The end LSN of a transaction follows the flags and the
+			// commit LSN of a pgoutput commit message.
+			if data.Data[0] == replicationMsgXLogData && data.Data[25] == 'C' {
+				commitEnd = binary.BigEndian.Uint64(data.Data[35:43])
+			}
+		}
+		update := make([]byte, 34)
+		update[0] = replicationMsgStandbyStatusUpdate
+		binary.BigEndian.PutUint64(update[1:], commitEnd)
+		binary.BigEndian.PutUint64(update[9:], commitEnd)
+		binary.BigEndian.PutUint64(update[17:], commitEnd)
+		require.NoError(t, conn.SendBytes(ctx, (&pgproto3.CopyData{Data: update}).Encode(nil)))
+		require.NoError(t, conn.SendBytes(ctx, (&pgproto3.CopyDone{}).Encode(nil)))
+
+		for done := false; !done; {
+			msg, err := conn.ReceiveMessage(ctx)
+			require.NoError(t, err)
+			switch msg := msg.(type) {
+			case *pgproto3.CommandComplete:
+				require.Equal(t, "START_STREAMING", string(msg.CommandTag))
+				done = true
+			case *pgproto3.ErrorResponse:
+				t.Fatalf("unexpected error: %s", msg.Message)
+			}
+		}
+
+		// The position of the slot and its protected timestamp are persisted
+		// once the stream ends.
+		testutils.SucceedsSoon(t, func() error {
+			var advanced bool
+			sqlDB.QueryRow(t,
+				`SELECT confirmed_flush_lsn = $1 FROM system.replication_slots WHERE name = 's'`,
+				int64(commitEnd),
+			).Scan(&advanced)
+			if !advanced {
+				return errors.New("the position of the slot was not persisted")
+			}
+			sqlDB.QueryRow(t,
+				`SELECT ts > $1::DECIMAL FROM system.protected_ts_records WHERE meta_type = 'replication_slot'`,
+				createdTS,
+			).Scan(&advanced)
+			if !advanced {
+				return errors.New("the protected timestamp of the slot was not advanced")
+			}
+			return nil
+		})
+	})
+
+	t.Run("error is reported once", func(t *testing.T) {
+		sqlDB.Exec(t, `SET CLUSTER SETTING sql.replication.feed_memory_limit = '1B'`)
+		defer sqlDB.Exec(t, `RESET CLUSTER SETTING sql.replication.feed_memory_limit`)
+
+		conn := connect(t)
+		defer func() { _ = conn.Close(ctx) }()
+		startReplication(t, conn)
+		sqlDB.Exec(t, `INSERT INTO t VALUES (2, 'b')`)
+
+		var errs []string
+		for {
+			msg, err := conn.ReceiveMessage(ctx)
+			if err != nil {
+				// The connection is closed after the error.
+				break
+			}
+			if msg, ok := msg.(*pgproto3.ErrorResponse); ok {
+				errs = append(errs, msg.Message)
+			}
+		}
+		require.Len(t, errs, 1)
+		require.Regexp(t, "memory budget exceeded", errs[0])
+	})
+
+	_, err = conn.Exec(ctx, `DROP_REPLICATION_SLOT s`).ReadAll()
+	require.NoError(t, err)
+	sqlDB.CheckQueryResults(t,
+		`SELECT count(*) FROM system.protected_ts_records WHERE meta_type = 'replication_slot'`,
+		[][]string{{"0"}},
+	)
+}
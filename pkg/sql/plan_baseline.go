@@ -0,0 +1,528 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/optbuilder"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/planbaseline"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+)
+
+// findPlanBaseline returns the accepted plan baseline of the fingerprint of
+// the statement in the planner, if any. The baseline of a statement also
+// applies to EXPLAIN and EXPLAIN ANALYZE of the statement.
+func (p *planner) findPlanBaseline() *planbaseline.Baseline {
+	r := p.execCfg.PlanBaselines
+	if r == nil || p.isInternalPlanner {
+		return nil
+	}
+	fingerprint := p.stmt.StmtNoConstants
+	switch t := p.stmt.AST.(type) {
+	case *tree.ParenSelect, *tree.Select, *tree.SelectClause, *tree.UnionClause, *tree.ValuesClause,
+		*tree.Insert, *tree.Update, *tree.Delete:
+	case *tree.Explain:
+		fingerprint = formatStatementHideConstants(t.Statement)
+	case *tree.ExplainAnalyze:
+		fingerprint = formatStatementHideConstants(t.Statement)
+	default:
+		return nil
+	}
+	return r.Find(fingerprint, p.CurrentDatabase())
+}
+
+// planBaselineCandidate is a plan of a statement, expressed as the hints which
+// make the optimizer choose it.
+type planBaselineCandidate struct {
+	// fingerprint is the fingerprint of the statement, without its hints.
+	fingerprint string
+	// query is the statement without its hints.
+	query string
+	// hints are the hints derived from the plan.
+	hints optbuilder.PlanHints
+	// targets are the tables and joins of the statement.
+	targets optbuilder.PlanHintTargets
+	plan    string
+	cost    float64
+}
+
+// planForBaseline plans the given query in the given database, applying the
+// given plan hints if they are not nil. It returns an error if the query is
+// not a statement to which plan baselines apply, or if it cannot be planned
+// with the hints.
+func (p *planner) planForBaseline(
+	ctx context.Context, query string, database string, hints *optbuilder.PlanHints,
+) (res planBaselineCandidate, err error) {
+	stmt, err := parser.ParseOne(query)
+	if err != nil {
+		return res, err
+	}
+	switch stmt.AST.(type) {
+	case *tree.ParenSelect, *tree.Select, *tree.SelectClause, *tree.UnionClause, *tree.ValuesClause,
+		*tree.Insert, *tree.Update, *tree.Delete:
+	default:
+		return res, pgerror.Newf(pgcode.InvalidParameterValue,
+			"plan baselines cannot be used for %s statements", stmt.AST.StatementTag())
+	}
+	if stmt.NumPlaceholders > 0 {
+		return res, pgerror.New(pgcode.InvalidParameterValue,
+			"plan baselines cannot be created from statements with placeholders")
+	}
+
+	ip, cleanup := NewInternalPlanner(
+		"plan-baseline",
+		p.txn,
+		p.User(),
+		&MemoryMetrics{},
+		p.ExecCfg(),
+		p.SessionData().SessionData,
+	)
+	defer cleanup()
+	localPlanner := ip.(*planner)
+	// The statement must resolve names like it does in the sessions it applies
+	// to, rather than in the system database of the internal planner.
+	localPlanner.SessionData().Database = database
+	localPlanner.SessionData().SearchPath = p.SessionData().SearchPath
+	localPlanner.semaCtx.SearchPath = p.SessionData().SearchPath
+
+	localPlanner.stmt = makeStatement(stmt, ClusterWideID{} /* queryID */)
+	opc := &localPlanner.optPlanningCtx
+	opc.init(localPlanner)
+	opc.catalog.reset()
+	opc.optimizer.Init(localPlanner.EvalContext(), &opc.catalog)
+
+	f := opc.optimizer.Factory()
+	f.FoldingControl().AllowStableFolds()
+	bld := optbuilder.New(
+		ctx, &localPlanner.semaCtx, localPlanner.EvalContext(), &opc.catalog, f, stmt.AST,
+	)
+	bld.PlanHints = hints
+	bld.RecordPlanHintTargets = true
+	if err := bld.Build(); err != nil {
+		return res, err
+	}
+	if _, err := opc.optimizer.Optimize(); err != nil {
+		return res, err
+	}
+	mem := f.Memo()
+
+	// Build the execution plan, which fails if the plan does not conform to the
+	// hints.
+	localPlanner.curPlan.init(&localPlanner.stmt, &localPlanner.instrumentation)
+	defer localPlanner.curPlan.close(ctx)
+	if err := opc.runExecBuilder(
+		&localPlanner.curPlan,
+		&localPlanner.stmt,
+		newExecFactory(localPlanner),
+		mem,
+		localPlanner.EvalContext(),
+		false, /* allowAutoCommit */
+	); err != nil {
+		return res, err
+	}
+
+	root := mem.RootExpr()
+	res.fingerprint = tree.AsStringWithFlags(stmt.AST, tree.FmtHideConstants|tree.FmtHideHints)
+	res.query = tree.AsStringWithFlags(stmt.AST, tree.FmtHideHints)
+	res.targets = bld.PlanHintTargets
+	res.hints = planHintsFromPlan(root, mem.Metadata(), &res.targets)
+	res.plan = memo.FormatExpr(root, memo.ExprFmtHideAll, mem, &opc.catalog)
+	res.cost = float64(root.(memo.RelExpr).Cost())
+	return res, nil
+}
+
+// planHintsFromPlan returns the hints which make the optimizer choose the
+// given plan of a statement with the given targets: the index used to access
+// each table, and the algorithm of each join which the plan executes with the
+// inputs written in the statement. Tables which are accessed through more than
+// one index are not hinted.
+func planHintsFromPlan(
+	root opt.Expr, md *opt.Metadata, targets *optbuilder.PlanHintTargets,
+) optbuilder.PlanHints {
+	ords := make(map[opt.TableID]int, len(targets.Tables))
+	for i := range targets.Tables {
+		ords[targets.Tables[i].ID] = i
+	}
+	tableSet := func(e opt.Expr) (s util.FastIntSet) {
+		forEachPlanTable(e, func(tabID opt.TableID, _ cat.IndexOrdinal) {
+			if ord, ok := ords[tabID]; ok {
+				s.Add(ord)
+			}
+		})
+		return s
+	}
+
+	// Find the index used to access each table.
+	const conflict = cat.IndexOrdinal(-1)
+	indexes := make(map[int]cat.IndexOrdinal, len(targets.Tables))
+	forEachPlanTable(root, func(tabID opt.TableID, idx cat.IndexOrdinal) {
+		ord, ok := ords[tabID]
+		if !ok {
+			return
+		}
+		if prev, ok := indexes[ord]; ok && prev != idx {
+			idx = conflict
+		}
+		indexes[ord] = idx
+	})
+
+	// Find the algorithm of each join whose inputs are those of a join of the
+	// statement.
+	joins := make([]string, len(targets.Joins))
+	var visit func(e opt.Expr)
+	visit = func(e opt.Expr) {
+		var hint string
+		var left, right util.FastIntSet
+		switch t := e.(type) {
+		case *memo.InnerJoinExpr, *memo.LeftJoinExpr, *memo.RightJoinExpr, *memo.FullJoinExpr:
+			hint, left, right = tree.AstHash, tableSet(e.Child(0)), tableSet(e.Child(1))
+		case *memo.MergeJoinExpr:
+			hint, left, right = tree.AstMerge, tableSet(t.Left), tableSet(t.Right)
+		case *memo.LookupJoinExpr:
+			hint, left = tree.AstLookup, tableSet(t.Input)
+			if ord, ok := ords[t.Table]; ok {
+				right.Add(ord)
+			}
+		case *memo.InvertedJoinExpr:
+			hint, left = tree.AstInverted, tableSet(t.Input)
+			if ord, ok := ords[t.Table]; ok {
+				right.Add(ord)
+			}
+		}
+		if hint != "" && !left.Empty() && !right.Empty() {
+			for i, j := range targets.Joins {
+				if j.LeftStart == j.RightStart || j.RightStart == j.RightEnd {
+					continue
+				}
+				var l, r util.FastIntSet
+				l.AddRange(j.LeftStart, j.RightStart-1)
+				r.AddRange(j.RightStart, j.RightEnd-1)
+				// Merge joins are symmetric, but the other algorithms are only hinted
+				// with the inputs in the order of the statement.
+				if (l.Equals(left) && r.Equals(right)) ||
+					(hint == tree.AstMerge && l.Equals(right) && r.Equals(left)) {
+					joins[i] = hint
+				}
+			}
+		}
+		for i, n := 0, e.ChildCount(); i < n; i++ {
+			visit(e.Child(i))
+		}
+	}
+	visit(root)
+
+	var hints optbuilder.PlanHints
+	for ord := range targets.Tables {
+		if idx, ok := indexes[ord]; ok && idx != conflict {
+			hints.Tables = append(hints.Tables, &optbuilder.TableHint{
+				TableID: targets.Tables[ord].TableID,
+				Index:   tree.UnrestrictedName(md.Table(targets.Tables[ord].ID).Index(idx).Name()),
+			})
+		} else {
+			hints.Tables = append(hints.Tables, nil)
+		}
+	}
+	for _, hint := range joins {
+		if hint != "" {
+			hints.Joins = joins
+			break
+		}
+	}
+	return hints
+}
+
+// forEachPlanTable calls fn for each table accessed by the given plan, with
+// the index used to access it, or -1 if the table is accessed through several
+// indexes at once.
+func forEachPlanTable(e opt.Expr, fn func(tabID opt.TableID, idx cat.IndexOrdinal)) {
+	switch t := e.(type) {
+	case *memo.ScanExpr:
+		fn(t.Table, t.Index)
+	case *memo.LookupJoinExpr:
+		fn(t.Table, t.Index)
+	case *memo.InvertedJoinExpr:
+		fn(t.Table, t.Index)
+	case *memo.ZigzagJoinExpr:
+		fn(t.LeftTable, -1)
+		fn(t.RightTable, -1)
+	}
+	for i, n := 0, e.ChildCount(); i < n; i++ {
+		forEachPlanTable(e.Child(i), fn)
+	}
+}
+
+// planHintsFromStatement returns the hints written in a statement with the
+// given targets.
+func planHintsFromStatement(targets *optbuilder.PlanHintTargets) (optbuilder.PlanHints, error) {
+	var hints optbuilder.PlanHints
+	var hinted bool
+	for _, t := range targets.Tables {
+		h, err := optbuilder.MakeTableHint(t.Flags)
+		if err != nil {
+			return optbuilder.PlanHints{}, err
+		}
+		if h != nil {
+			h.TableID = t.TableID
+		}
+		hints.Tables = append(hints.Tables, h)
+		hinted = hinted || h != nil
+	}
+	for _, j := range targets.Joins {
+		hints.Joins = append(hints.Joins, j.Hint)
+		hinted = hinted || j.Hint != ""
+	}
+	if !hinted {
+		return optbuilder.PlanHints{}, pgerror.New(pgcode.InvalidParameterValue,
+			"statement does not contain any index or join hints")
+	}
+	return hints, nil
+}
+
+func (p *planner) checkPlanBaselinesSupported(ctx context.Context, action string) error {
+	if !p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.StatementPlanBaselines) {
+		return pgerror.New(pgcode.FeatureNotSupported,
+			"plan baselines are not supported until version upgrade is finalized")
+	}
+	return p.RequireAdminRole(ctx, action)
+}
+
+// refreshPlanBaselinesOnCommit makes the changes to the plan baselines take
+// effect on this node as soon as the transaction commits. Other nodes pick
+// them up the next time they poll for plan baselines.
+func (p *planner) refreshPlanBaselinesOnCommit() {
+	if r := p.execCfg.PlanBaselines; r != nil {
+		p.txn.AddCommitTrigger(func(ctx context.Context) {
+			if err := r.Refresh(ctx); err != nil {
+				log.Warningf(ctx, "error loading plan baselines: %v", err)
+			}
+		})
+	}
+}
+
+// CapturePlanBaseline is part of the tree.EvalPlanner interface.
+func (p *planner) CapturePlanBaseline(
+	ctx context.Context, query string, enforce bool,
+) (int64, error) {
+	if err := p.checkPlanBaselinesSupported(ctx, "capture plan baselines"); err != nil {
+		return 0, err
+	}
+	c, err := p.planForBaseline(ctx, query, p.CurrentDatabase(), nil /* hints */)
+	if err != nil {
+		return 0, err
+	}
+	if len(c.hints.Joins) == 0 {
+		hinted := false
+		for _, h := range c.hints.Tables {
+			hinted = hinted || h != nil
+		}
+		if !hinted {
+			return 0, pgerror.New(pgcode.InvalidParameterValue,
+				"the plan of the statement cannot be expressed with index or join hints")
+		}
+	}
+	return p.insertAcceptedPlanBaseline(ctx, c, enforce)
+}
+
+// PinPlanBaseline is part of the tree.EvalPlanner interface.
+func (p *planner) PinPlanBaseline(ctx context.Context, query string, enforce bool) (int64, error) {
+	if err := p.checkPlanBaselinesSupported(ctx, "pin plan baselines"); err != nil {
+		return 0, err
+	}
+	c, err := p.planForBaseline(ctx, query, p.CurrentDatabase(), nil /* hints */)
+	if err != nil {
+		return 0, err
+	}
+	if c.hints, err = planHintsFromStatement(&c.targets); err != nil {
+		return 0, err
+	}
+	return p.insertAcceptedPlanBaseline(ctx, c, enforce)
+}
+
+// insertAcceptedPlanBaseline records the given plan as the accepted plan
+// baseline of its fingerprint in the current database, in place of the
+// baseline accepted previously.
+func (p *planner) insertAcceptedPlanBaseline(
+	ctx context.Context, c planBaselineCandidate, enforce bool,
+) (int64, error) {
+	hints, err := json.Marshal(&c.hints)
+	if err != nil {
+		return 0, err
+	}
+	ie := p.ExecCfg().InternalExecutor
+	if _, err := ie.ExecEx(
+		ctx, "reject-plan-baselines", p.txn,
+		sessiondata.InternalExecutorOverride{User: security.NodeUserName()},
+		`UPDATE system.statement_plan_baselines SET accepted = false
+      WHERE fingerprint = $1 AND database = $2 AND accepted`,
+		c.fingerprint, p.CurrentDatabase(),
+	); err != nil {
+		return 0, err
+	}
+	row, err := ie.QueryRowEx(
+		ctx, "insert-plan-baseline", p.txn,
+		sessiondata.InternalExecutorOverride{User: security.NodeUserName()},
+		`INSERT INTO system.statement_plan_baselines
+         (fingerprint, database, query, hints, plan, cost, accepted, enforced)
+  VALUES ($1, $2, $3, $4, $5, $6, true, $7)
+  RETURNING id`,
+		c.fingerprint, p.CurrentDatabase(), c.query, string(hints), c.plan, c.cost, enforce,
+	)
+	if err != nil {
+		return 0, err
+	}
+	p.refreshPlanBaselinesOnCommit()
+	return int64(tree.MustBeDInt(row[0])), nil
+}
+
+// EvolvePlanBaseline is part of the tree.EvalPlanner interface.
+func (p *planner) EvolvePlanBaseline(ctx context.Context, id int64) (int64, bool, error) {
+	if err := p.checkPlanBaselinesSupported(ctx, "evolve plan baselines"); err != nil {
+		return 0, false, err
+	}
+	ie := p.ExecCfg().InternalExecutor
+	row, err := ie.QueryRowEx(
+		ctx, "read-plan-baseline", p.txn,
+		sessiondata.InternalExecutorOverride{User: security.NodeUserName()},
+		`SELECT database, query, hints, cost FROM system.statement_plan_baselines WHERE id = $1`,
+		id,
+	)
+	if err != nil {
+		return 0, false, err
+	}
+	if row == nil {
+		return 0, false, pgerror.Newf(pgcode.UndefinedObject, "plan baseline %d does not exist", id)
+	}
+	database := string(tree.MustBeDString(row[0]))
+	query := string(tree.MustBeDString(row[1]))
+	var hints optbuilder.PlanHints
+	if err := json.Unmarshal([]byte(tree.MustBeDJSON(row[2]).JSON.String()), &hints); err != nil {
+		return 0, false, errors.Wrapf(err, "decoding hints of plan baseline %d", id)
+	}
+	cost := float64(tree.MustBeDFloat(row[3]))
+
+	// Re-estimate the cost of the plan of the baseline. The baseline may no
+	// longer be usable, for example if an index it names was dropped, in which
+	// case any other plan is an improvement.
+	usable := true
+	if cur, err := p.planForBaseline(ctx, query, database, &hints); err != nil {
+		log.VEventf(ctx, 1, "plan baseline %d cannot be planned: %v", id, err)
+		usable = false
+	} else {
+		cost = cur.cost
+		if _, err := ie.ExecEx(
+			ctx, "update-plan-baseline-cost", p.txn,
+			sessiondata.InternalExecutorOverride{User: security.NodeUserName()},
+			`UPDATE system.statement_plan_baselines SET cost = $2 WHERE id = $1`,
+			id, cost,
+		); err != nil {
+			return 0, false, err
+		}
+	}
+
+	// Plan the statement without hints, and record the plan as a candidate if
+	// it differs from the plan of the baseline and is estimated to be cheaper.
+	c, err := p.planForBaseline(ctx, query, database, nil /* hints */)
+	if err != nil {
+		return 0, false, err
+	}
+	candHints, err := json.Marshal(&c.hints)
+	if err != nil {
+		return 0, false, err
+	}
+	baseHints, err := json.Marshal(&hints)
+	if err != nil {
+		return 0, false, err
+	}
+	if string(candHints) == string(baseHints) || (usable && c.cost >= cost) {
+		return 0, false, nil
+	}
+	row, err = ie.QueryRowEx(
+		ctx, "update-plan-baseline-candidate", p.txn,
+		sessiondata.InternalExecutorOverride{User: security.NodeUserName()},
+		`UPDATE system.statement_plan_baselines SET plan = $4, cost = $5
+      WHERE fingerprint = $1 AND database = $2 AND hints = $3::JSONB AND NOT accepted
+  RETURNING id`,
+		c.fingerprint, database, string(candHints), c.plan, c.cost,
+	)
+	if err != nil {
+		return 0, false, err
+	}
+	if row == nil {
+		row, err = ie.QueryRowEx(
+			ctx, "insert-plan-baseline-candidate", p.txn,
+			sessiondata.InternalExecutorOverride{User: security.NodeUserName()},
+			`INSERT INTO system.statement_plan_baselines
+           (fingerprint, database, query, hints, plan, cost, accepted, enforced)
+    SELECT fingerprint, database, query, $2, $3, $4, false, enforced
+      FROM system.statement_plan_baselines
+     WHERE id = $1
+ RETURNING id`,
+			id, string(candHints), c.plan, c.cost,
+		)
+		if err != nil {
+			return 0, false, err
+		}
+	}
+	return int64(tree.MustBeDInt(row[0])), true, nil
+}
+
+// AcceptPlanBaseline is part of the tree.EvalPlanner interface.
+func (p *planner) AcceptPlanBaseline(ctx context.Context, id int64) (bool, error) {
+	if err := p.checkPlanBaselinesSupported(ctx, "accept plan baselines"); err != nil {
+		return false, err
+	}
+	rowsAffected, err := p.ExecCfg().InternalExecutor.ExecEx(
+		ctx, "accept-plan-baseline", p.txn,
+		sessiondata.InternalExecutorOverride{User: security.NodeUserName()},
+		`UPDATE system.statement_plan_baselines SET accepted = (id = $1)
+      WHERE (fingerprint, database) IN (
+              SELECT fingerprint, database FROM system.statement_plan_baselines WHERE id = $1
+            )`,
+		id,
+	)
+	if err != nil {
+		return false, err
+	}
+	p.refreshPlanBaselinesOnCommit()
+	return rowsAffected > 0, nil
+}
+
+// DropPlanBaseline is part of the tree.EvalPlanner interface.
+func (p *planner) DropPlanBaseline(ctx context.Context, id int64) (bool, error) {
+	if err := p.checkPlanBaselinesSupported(ctx, "drop plan baselines"); err != nil {
+		return false, err
+	}
+	rowsAffected, err := p.ExecCfg().InternalExecutor.ExecEx(
+		ctx, "drop-plan-baseline", p.txn,
+		sessiondata.InternalExecutorOverride{User: security.NodeUserName()},
+		`DELETE FROM system.statement_plan_baselines WHERE id = $1`,
+		id,
+	)
+	if err != nil {
+		return false, err
+	}
+	p.refreshPlanBaselinesOnCommit()
+	return rowsAffected > 0, nil
+}
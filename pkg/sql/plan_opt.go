@@ -29,6 +29,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/physicalplan"
+	"github.com/cockroachdb/cockroach/pkg/sql/planbaseline"
 	"github.com/cockroachdb/cockroach/pkg/sql/querycache"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondatapb"
@@ -57,7 +58,7 @@ func (p *planner) prepareUsingOptimizer(ctx context.Context) (planFlags, error)
 	case *tree.AlterIndex, *tree.AlterTable, *tree.AlterSequence,
 		*tree.Analyze,
 		*tree.BeginTransaction,
-		*tree.CommentOnColumn, *tree.CommentOnConstraint, *tree.CommentOnDatabase, *tree.CommentOnIndex, *tree.CommentOnTable, *tree.CommentOnSchema,
+		*tree.CommentOnColumn, *tree.CommentOnConstraint, *tree.CommentOnDatabase, *tree.CommentOnFunction, *tree.CommentOnIndex, *tree.CommentOnTable, *tree.CommentOnSchema,
 		*tree.CommitTransaction,
 		*tree.CopyFrom, *tree.CreateDatabase, *tree.CreateIndex, *tree.CreateView,
 		*tree.CreateSequence,
@@ -99,7 +100,7 @@ func (p *planner) prepareUsingOptimizer(ctx context.Context) (planFlags, error)
 			if !pm.TypeHints.Identical(p.semaCtx.Placeholders.TypeHints) {
 				opc.log(ctx, "query cache hit but type hints don't match")
 			} else {
-				isStale, err := cachedData.Memo.IsStale(ctx, p.EvalContext(), &opc.catalog)
+				isStale, err := opc.isMemoStale(ctx, cachedData.Memo)
 				if err != nil {
 					return 0, err
 				}
@@ -193,6 +194,25 @@ func (p *planner) makeOptimizerPlan(ctx context.Context) error {
 	opc := &p.optPlanningCtx
 	opc.reset()
 
+	err := p.makeOptimizerPlanInternal(ctx)
+	if err != nil && opc.baseline != nil && !opc.baseline.Enforced {
+		// The plan baseline of the statement is only preferred, so plan the
+		// statement without it rather than fail. The memo is not cached, so that
+		// the next execution of the statement tries the baseline again rather
+		// than replace the memo built without it.
+		log.VEventf(ctx, 1, "planning without plan baseline %d: %v", opc.baseline.ID, err)
+		p.curPlan.init(&p.stmt, &p.instrumentation)
+		opc.baseline = nil
+		opc.allowMemoReuse = false
+		opc.useCache = false
+		opc.optimizer.Init(p.EvalContext(), &opc.catalog)
+		err = p.makeOptimizerPlanInternal(ctx)
+	}
+	return err
+}
+
+func (p *planner) makeOptimizerPlanInternal(ctx context.Context) error {
+	opc := &p.optPlanningCtx
 	execMemo, err := opc.buildExecMemo(ctx)
 	if err != nil {
 		return err
@@ -290,6 +310,9 @@ type optPlanningCtx struct {
 	useCache bool
 
 	flags planFlags
+
+	// baseline is the plan baseline applied to the statement, if any.
+	baseline *planbaseline.Baseline
 }
 
 // init performs one-time initialization of the planning context; reset() must
@@ -332,6 +355,29 @@ func (opc *optPlanningCtx) reset() {
 		opc.allowMemoReuse = false
 		opc.useCache = false
 	}
+
+	// A memo built with the hints of a plan baseline is only reused for
+	// statements planned with the same baseline (see isMemoStale).
+	opc.baseline = p.findPlanBaseline()
+}
+
+// planHintsKey returns the key identifying the plan hints applied to the
+// statement, which must match the key of a memo for the memo to be reused.
+func (opc *optPlanningCtx) planHintsKey() string {
+	if opc.baseline == nil {
+		return ""
+	}
+	return opc.baseline.PlanHintsKey()
+}
+
+// isMemoStale returns whether a cached memo cannot be reused for the
+// statement, either because it was invalidated by schema or other changes, or
+// because it was built with other plan hints than those of the statement.
+func (opc *optPlanningCtx) isMemoStale(ctx context.Context, m *memo.Memo) (bool, error) {
+	if m.PlanHintsKey() != opc.planHintsKey() {
+		return true, nil
+	}
+	return m.IsStale(ctx, opc.p.EvalContext(), &opc.catalog)
 }
 
 func (opc *optPlanningCtx) log(ctx context.Context, msg string) {
@@ -379,6 +425,9 @@ func (opc *optPlanningCtx) buildReusableMemo(ctx context.Context) (_ *memo.Memo,
 	f := opc.optimizer.Factory()
 	bld := optbuilder.New(ctx, &p.semaCtx, p.EvalContext(), &opc.catalog, f, opc.p.stmt.AST)
 	bld.KeepPlaceholders = true
+	if opc.baseline != nil {
+		bld.PlanHints = &opc.baseline.Hints
+	}
 	if err := bld.Build(); err != nil {
 		return nil, err
 	}
@@ -425,7 +474,9 @@ func (opc *optPlanningCtx) buildReusableMemo(ctx context.Context) (_ *memo.Memo,
 	// Detach the prepared memo from the factory and transfer its ownership
 	// to the prepared statement. DetachMemo will re-initialize the optimizer
 	// to an empty memo.
-	return opc.optimizer.DetachMemo(), nil
+	m := opc.optimizer.DetachMemo()
+	m.SetPlanHintsKey(opc.planHintsKey())
+	return m, nil
 }
 
 // reuseMemo returns an optimized memo using a cached memo as a starting point.
@@ -471,7 +522,7 @@ func (opc *optPlanningCtx) buildExecMemo(ctx context.Context) (_ *memo.Memo, _ e
 
 		// If the prepared memo has been invalidated by schema or other changes,
 		// re-prepare it.
-		if isStale, err := prepared.Memo.IsStale(ctx, p.EvalContext(), &opc.catalog); err != nil {
+		if isStale, err := opc.isMemoStale(ctx, prepared.Memo); err != nil {
 			return nil, err
 		} else if isStale {
 			prepared.Memo, err = opc.buildReusableMemo(ctx)
@@ -489,7 +540,7 @@ func (opc *optPlanningCtx) buildExecMemo(ctx context.Context) (_ *memo.Memo, _ e
 		// Consult the query cache.
 		cachedData, ok := p.execCfg.QueryCache.Find(&p.queryCacheSession, opc.p.stmt.SQL)
 		if ok {
-			if isStale, err := cachedData.Memo.IsStale(ctx, p.EvalContext(), &opc.catalog); err != nil {
+			if isStale, err := opc.isMemoStale(ctx, cachedData.Memo); err != nil {
 				return nil, err
 			} else if isStale {
 				cachedData.Memo, err = opc.buildReusableMemo(ctx)
@@ -520,6 +571,9 @@ func (opc *optPlanningCtx) buildExecMemo(ctx context.Context) (_ *memo.Memo, _ e
 	f := opc.optimizer.Factory()
 	f.FoldingControl().AllowStableFolds()
 	bld := optbuilder.New(ctx, &p.semaCtx, p.EvalContext(), &opc.catalog, f, opc.p.stmt.AST)
+	if opc.baseline != nil {
+		bld.PlanHints = &opc.baseline.Hints
+	}
 	if err := bld.Build(); err != nil {
 		return nil, err
 	}
@@ -528,9 +582,11 @@ func (opc *optPlanningCtx) buildExecMemo(ctx context.Context) (_ *memo.Memo, _ e
 	// find potential index candidates in the memo.
 	_, isExplain := opc.p.stmt.AST.(*tree.Explain)
 	if isExplain && p.SessionData().IndexRecommendationsEnabled {
-		if err := opc.makeQueryIndexRecommendation(); err != nil {
+		indexRecommendations, err := opc.makeQueryIndexRecommendation()
+		if err != nil {
 			return nil, err
 		}
+		opc.p.instrumentation.indexRecommendations = indexrec.FormatRecommendations(indexRecommendations)
 	}
 
 	if _, isCanned := opc.p.stmt.AST.(*tree.CannedOptPlan); !isCanned {
@@ -546,6 +602,7 @@ func (opc *optPlanningCtx) buildExecMemo(ctx context.Context) (_ *memo.Memo, _ e
 	if opc.useCache && !bld.HadPlaceholders && !bld.DisableMemoReuse &&
 		!f.FoldingControl().PermittedStableFold() {
 		memo := opc.optimizer.DetachMemo()
+		memo.SetPlanHintsKey(opc.planHintsKey())
 		cachedData := querycache.CachedData{
 			SQL:  opc.p.stmt.SQL,
 			Memo: memo,
@@ -664,9 +721,9 @@ func (p *planner) DecodeGist(gist string) ([]string, error) {
 // makeQueryIndexRecommendation builds a statement and walks through it to find
 // potential index candidates. It then optimizes the statement with those
 // indexes hypothetically added to the table. An index recommendation for the
-// query is outputted based on which hypothetical indexes are helpful in the
+// query is returned based on which hypothetical indexes are helpful in the
 // optimal plan.
-func (opc *optPlanningCtx) makeQueryIndexRecommendation() error {
+func (opc *optPlanningCtx) makeQueryIndexRecommendation() ([]indexrec.Recommendation, error) {
 	// Save the normalized memo created by the optbuilder.
 	savedMemo := opc.optimizer.DetachMemo()
 
@@ -685,7 +742,7 @@ func (opc *optPlanningCtx) makeQueryIndexRecommendation() error {
 		return ruleName.IsNormalize()
 	})
 	if _, err := opc.optimizer.Optimize(); err != nil {
-		return err
+		return nil, err
 	}
 
 	// Walk through the fully normalized memo to determine index candidates and
@@ -703,11 +760,11 @@ func (opc *optPlanningCtx) makeQueryIndexRecommendation() error {
 	)
 	opc.optimizer.Memo().Metadata().UpdateTableMeta(hypTables)
 	if _, err := opc.optimizer.Optimize(); err != nil {
-		return err
+		return nil, err
 	}
 
 	indexRecommendations := indexrec.FindIndexRecommendationSet(f.Memo().RootExpr(), f.Metadata())
-	opc.p.instrumentation.indexRecommendations = indexRecommendations.Output()
+	recs := indexRecommendations.Recommendations()
 
 	// Re-initialize the optimizer (which also re-initializes the factory) and
 	// update the saved memo's metadata with the original table information.
@@ -720,5 +777,5 @@ func (opc *optPlanningCtx) makeQueryIndexRecommendation() error {
 		f.CopyWithoutAssigningPlaceholders,
 	)
 
-	return nil
+	return recs, nil
 }
@@ -378,6 +378,43 @@ SELECT cte.x, cte.y FROM cte LEFT JOIN cte as cte2 on cte.y = cte2.x`, j)
 			})
 		})
 
+		// Test that the memos built with the hints of a plan baseline are only
+		// reused while the baseline is accepted.
+		t.Run("plan-baseline", func(t *testing.T) {
+			t.Parallel() // SAFE FOR TESTING
+			h := makeQueryCacheTestHelper(t, 2 /* numConns */)
+			defer h.Stop()
+			r0, r1 := h.runners[0], h.runners[1]
+			r0.Exec(t, "CREATE INDEX b_idx ON t (b)")
+			h.ResetStats()
+			const query = "SELECT a FROM t WHERE b > 0"
+			r0.CheckQueryResults(t, query, [][]string{{"1"}})
+			h.AssertStats(t, 0 /* hits */, 1 /* misses */)
+			r1.CheckQueryResults(t, query, [][]string{{"1"}})
+			h.AssertStats(t, 1 /* hits */, 1 /* misses */)
+
+			var id int64
+			r0.QueryRow(t, "SELECT crdb_internal.pin_plan_baseline('SELECT a FROM t@t_pkey WHERE b > 0')").Scan(&id)
+			h.ResetStats()
+			r1.CheckQueryResults(t, query, [][]string{{"1"}})
+			h.AssertStats(t, 0 /* hits */, 1 /* misses */)
+			r0.CheckQueryResults(t, query, [][]string{{"1"}})
+			h.AssertStats(t, 1 /* hits */, 1 /* misses */)
+			// The cached memo was built for execution only, so it is rebuilt for
+			// PREPARE.
+			r0.Exec(t, "PREPARE a AS "+query)
+			h.AssertStats(t, 1 /* hits */, 2 /* misses */)
+
+			r0.Exec(t, "SELECT crdb_internal.drop_plan_baseline($1)", id)
+			h.ResetStats()
+			r1.CheckQueryResults(t, query, [][]string{{"1"}})
+			h.AssertStats(t, 0 /* hits */, 1 /* misses */)
+			r0.CheckQueryResults(t, query, [][]string{{"1"}})
+			h.AssertStats(t, 1 /* hits */, 1 /* misses */)
+			// The memo prepared with the hints of the baseline is rebuilt.
+			r0.CheckQueryResults(t, "EXECUTE a", [][]string{{"1"}})
+		})
+
 		// Test that a schema change triggers cache invalidation.
 		t.Run("schemachange-prepare", func(t *testing.T) {
 			t.Parallel() // SAFE FOR TESTING
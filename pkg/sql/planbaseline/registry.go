@@ -0,0 +1,206 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package planbaseline maintains the accepted plan baselines of statement
+// fingerprints, stored in system.statement_plan_baselines.
+//
+// A plan baseline is a set of index and join hints which the optimizer applies
+// to every statement with the fingerprint of the baseline, in order to keep it
+// on a known plan regardless of changes to the table statistics. An enforced
+// baseline is always applied; a baseline which is only preferred is ignored
+// when the statement cannot be planned with its hints, for example because an
+// index it names was dropped.
+package planbaseline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/optbuilder"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+// Enabled controls whether the optimizer applies plan baselines.
+var Enabled = settings.RegisterBoolSetting(
+	settings.TenantWritable,
+	"sql.plan_baselines.enabled",
+	"if set, the optimizer applies the accepted plan baselines of statement fingerprints",
+	true,
+).WithPublic()
+
+var pollingInterval = settings.RegisterDurationSetting(
+	settings.TenantWritable,
+	"sql.plan_baselines.poll_interval",
+	"rate at which the planbaseline.Registry polls for plan baselines, set to zero to disable",
+	10*time.Second,
+)
+
+// Baseline is the accepted plan baseline of a statement fingerprint.
+type Baseline struct {
+	ID int64
+	// Version is the time at which the baseline was last modified.
+	Version hlc.Timestamp
+	Hints   optbuilder.PlanHints
+	// Enforced is set if the statement must not be planned without the hints
+	// of the baseline.
+	Enforced bool
+}
+
+// PlanHintsKey returns a key identifying the hints of the baseline, with which
+// the memos built with them are cached.
+func (b *Baseline) PlanHintsKey() string {
+	return fmt.Sprintf("baseline %d@%s", b.ID, b.Version)
+}
+
+type baselineKey struct {
+	fingerprint string
+	database    string
+}
+
+// Registry caches the accepted plan baselines of system.statement_plan_baselines
+// so that they can be looked up by the optimizer for every statement.
+type Registry struct {
+	st *cluster.Settings
+	ie sqlutil.InternalExecutor
+
+	// loadMu serializes the reads of the system table, so that the baselines
+	// of a read never replace those of a later read.
+	loadMu syncutil.Mutex
+
+	mu struct {
+		syncutil.RWMutex
+		baselines map[baselineKey]*Baseline
+	}
+}
+
+// NewRegistry constructs a new Registry.
+func NewRegistry(ie sqlutil.InternalExecutor, st *cluster.Settings) *Registry {
+	return &Registry{st: st, ie: ie}
+}
+
+// Start will start the polling loop for the Registry.
+func (r *Registry) Start(ctx context.Context, stopper *stop.Stopper) {
+	ctx, _ = stopper.WithCancelOnQuiesce(ctx)
+	// NB: The only error that should occur here would be if the server were
+	// shutting down so let's swallow it.
+	_ = stopper.RunAsyncTask(ctx, "plan-baseline-poll", r.poll)
+}
+
+func (r *Registry) poll(ctx context.Context) {
+	if err := r.Refresh(ctx); err != nil && ctx.Err() == nil {
+		log.Warningf(ctx, "error loading plan baselines: %v", err)
+	}
+
+	var timer timeutil.Timer
+	pollIntervalChanged := make(chan struct{}, 1)
+	pollingInterval.SetOnChange(&r.st.SV, func(ctx context.Context) {
+		select {
+		case pollIntervalChanged <- struct{}{}:
+		default:
+		}
+	})
+	for {
+		if interval := pollingInterval.Get(&r.st.SV); interval > 0 {
+			timer.Reset(interval)
+		} else {
+			timer.Stop()
+		}
+		select {
+		case <-pollIntervalChanged:
+			continue
+		case <-timer.C:
+			timer.Read = true
+		case <-ctx.Done():
+			return
+		}
+		if err := r.Refresh(ctx); err != nil && ctx.Err() == nil {
+			log.Warningf(ctx, "error polling for plan baselines: %v", err)
+		}
+	}
+}
+
+// Refresh reads the accepted plan baselines from the system table. It is
+// called periodically, and after plan baselines are changed on this node so
+// that the changes take effect immediately.
+func (r *Registry) Refresh(ctx context.Context) (retErr error) {
+	if !r.st.Version.IsActive(ctx, clusterversion.StatementPlanBaselines) {
+		return nil
+	}
+	r.loadMu.Lock()
+	defer r.loadMu.Unlock()
+
+	it, err := r.ie.QueryIteratorEx(ctx, "plan-baseline-poll", nil, /* txn */
+		sessiondata.InternalExecutorOverride{User: security.NodeUserName()},
+		`SELECT id, fingerprint, database, hints, enforced, crdb_internal_mvcc_timestamp
+       FROM system.statement_plan_baselines
+      WHERE accepted`,
+	)
+	if err != nil {
+		return err
+	}
+	defer func() { retErr = errors.CombineErrors(retErr, it.Close()) }()
+
+	baselines := make(map[baselineKey]*Baseline)
+	var ok bool
+	for ok, err = it.Next(ctx); ok; ok, err = it.Next(ctx) {
+		row := it.Cur()
+		b := &Baseline{
+			ID:       int64(tree.MustBeDInt(row[0])),
+			Enforced: bool(tree.MustBeDBool(row[4])),
+		}
+		if err := json.Unmarshal([]byte(tree.MustBeDJSON(row[3]).JSON.String()), &b.Hints); err != nil {
+			return errors.Wrapf(err, "decoding hints of plan baseline %d", b.ID)
+		}
+		version := tree.MustBeDDecimal(row[5])
+		if b.Version, err = tree.DecimalToHLC(&version.Decimal); err != nil {
+			return errors.Wrapf(err, "decoding version of plan baseline %d", b.ID)
+		}
+		baselines[baselineKey{
+			fingerprint: string(tree.MustBeDString(row[1])),
+			database:    string(tree.MustBeDString(row[2])),
+		}] = b
+	}
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mu.baselines = baselines
+	return nil
+}
+
+// Find returns the accepted plan baseline of the given statement fingerprint
+// in the given database, if any.
+func (r *Registry) Find(fingerprint, database string) *Baseline {
+	if !Enabled.Get(&r.st.SV) {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if len(r.mu.baselines) == 0 {
+		return nil
+	}
+	return r.mu.baselines[baselineKey{fingerprint: fingerprint, database: database}]
+}
@@ -90,6 +90,10 @@ type extendedEvalContext struct {
 	// jobsCollection.
 	Jobs *jobsCollection
 
+	// DeferredChecks refers to deferredChecks in extraTxnState. The queued
+	// checks are run when the transaction commits.
+	DeferredChecks *deferredCheckCollection
+
 	// SchemaChangeJobRecords refers to schemaChangeJobsCache in extraTxnState of
 	// in sql.connExecutor. sql.connExecutor.createJobs() enqueues jobs with these
 	// records when transaction is committed.
@@ -20,11 +20,12 @@ func _() {
 	_ = x[ZONECONFIG-10]
 	_ = x[CONNECT-11]
 	_ = x[RULE-12]
+	_ = x[EXECUTE-13]
 }
 
-const _Kind_name = "ALLCREATEDROPGRANTSELECTINSERTDELETEUPDATEUSAGEZONECONFIGCONNECTRULE"
+const _Kind_name = "ALLCREATEDROPGRANTSELECTINSERTDELETEUPDATEUSAGEZONECONFIGCONNECTRULEEXECUTE"
 
-var _Kind_index = [...]uint8{0, 3, 9, 13, 18, 24, 30, 36, 42, 47, 57, 64, 68}
+var _Kind_index = [...]uint8{0, 3, 9, 13, 18, 24, 30, 36, 42, 47, 57, 64, 68, 75}
 
 func (i Kind) String() string {
 	i -= 1
@@ -43,6 +43,7 @@ const (
 	ZONECONFIG Kind = 10
 	CONNECT    Kind = 11
 	RULE       Kind = 12
+	EXECUTE    Kind = 13
 )
 
 // Privilege represents a privilege parsed from an Access Privilege Inquiry
@@ -70,17 +71,22 @@ const (
 	Table ObjectType = "table"
 	// Type represents a type object.
 	Type ObjectType = "type"
+	// Function represents a user-defined function object.
+	Function ObjectType = "function"
 )
 
 // Predefined sets of privileges.
 var (
-	AllPrivileges    = List{ALL, CONNECT, CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE, USAGE, ZONECONFIG}
+	AllPrivileges    = List{ALL, CONNECT, CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE, USAGE, ZONECONFIG, EXECUTE}
 	ReadData         = List{GRANT, SELECT}
 	ReadWriteData    = List{GRANT, SELECT, INSERT, DELETE, UPDATE}
 	DBPrivileges     = List{ALL, CONNECT, CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE, ZONECONFIG}
 	TablePrivileges  = List{ALL, CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE, ZONECONFIG}
 	SchemaPrivileges = List{ALL, GRANT, CREATE, USAGE}
 	TypePrivileges   = List{ALL, GRANT, USAGE}
+	// FunctionPrivileges are the privileges of user-defined functions. EXECUTE
+	// is required to call a function.
+	FunctionPrivileges = List{ALL, GRANT, EXECUTE}
 )
 
 // PGIncompatibleDBPrivileges represents the privileges CockroachDB
@@ -109,7 +115,7 @@ func (k Kind) IsSetIn(bits uint32) bool {
 
 // ByValue is just an array of privilege kinds sorted by value.
 var ByValue = [...]Kind{
-	ALL, CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE, USAGE, ZONECONFIG, CONNECT, RULE,
+	ALL, CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE, USAGE, ZONECONFIG, CONNECT, RULE, EXECUTE,
 }
 
 // ByName is a map of string -> kind value.
@@ -126,6 +132,7 @@ var ByName = map[string]Kind{
 	"ZONECONFIG": ZONECONFIG,
 	"USAGE":      USAGE,
 	"RULE":       RULE,
+	"EXECUTE":    EXECUTE,
 }
 
 // List is a list of privileges.
@@ -262,6 +269,8 @@ func GetValidPrivilegesForObject(objectType ObjectType) List {
 		return DBPrivileges
 	case Type:
 		return TypePrivileges
+	case Function:
+		return FunctionPrivileges
 	case Any:
 		return AllPrivileges
 	default:
@@ -278,10 +287,11 @@ var privToACL = map[Kind]string{
 	UPDATE:  "w",
 	USAGE:   "U",
 	CONNECT: "c",
+	EXECUTE: "X",
 }
 
-// orderedPrivs is the list of privileges sorted in alphanumeric order based on the ACL character -> CUacdrw
-var orderedPrivs = List{CREATE, USAGE, INSERT, CONNECT, DELETE, SELECT, UPDATE}
+// orderedPrivs is the list of privileges sorted in alphanumeric order based on the ACL character -> CUXacdrw
+var orderedPrivs = List{CREATE, USAGE, EXECUTE, INSERT, CONNECT, DELETE, SELECT, UPDATE}
 
 // ListToACL converts a list of privileges to a list of Postgres
 // ACL items.
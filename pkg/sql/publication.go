@@ -0,0 +1,196 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/resolver"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+)
+
+// checkPublicationsSupported returns an error if the cluster version does not
+// include the system tables storing publications and replication slots yet.
+func checkPublicationsSupported(ctx context.Context, p *planner) error {
+	if !p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.PublicationsAndReplicationSlots) {
+		return pgerror.New(pgcode.FeatureNotSupported,
+			"logical replication is not supported until version upgrade is finalized")
+	}
+	return nil
+}
+
+type createPublicationNode struct {
+	n        *tree.CreatePublication
+	dbDesc   catalog.DatabaseDescriptor
+	tableIDs []int64
+}
+
+// CreatePublication creates a publication in the current database.
+// Privileges: CREATE on the database and SELECT on the tables of the
+// publication.
+//   notes: postgres requires CREATE on the database and ownership of the
+//          tables.
+func (p *planner) CreatePublication(
+	ctx context.Context, n *tree.CreatePublication,
+) (planNode, error) {
+	if err := checkPublicationsSupported(ctx, p); err != nil {
+		return nil, err
+	}
+	dbDesc, err := p.Descriptors().GetImmutableDatabaseByName(ctx, p.txn,
+		p.CurrentDatabase(), tree.DatabaseLookupFlags{Required: true})
+	if err != nil {
+		return nil, err
+	}
+	if err := p.CheckPrivilege(ctx, dbDesc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+
+	tableIDs := make([]int64, 0, len(n.Tables))
+	for i := range n.Tables {
+		tn := &n.Tables[i]
+		_, tableDesc, err := resolver.ResolveExistingTableObject(ctx, p, tn,
+			tree.ObjectLookupFlagsWithRequiredTableKind(tree.ResolveRequireTableDesc))
+		if err != nil {
+			return nil, err
+		}
+		if tableDesc.GetParentID() != dbDesc.GetID() {
+			return nil, pgerror.Newf(pgcode.FeatureNotSupported,
+				"table %q is not in the current database %q", tn, dbDesc.GetName())
+		}
+		if tableDesc.IsVirtualTable() || tableDesc.IsTemporary() {
+			return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+				"table %q cannot be replicated", tn)
+		}
+		if err := checkReplicatedTable(tableDesc); err != nil {
+			return nil, err
+		}
+		if err := p.CheckPrivilege(ctx, tableDesc, privilege.SELECT); err != nil {
+			return nil, err
+		}
+		tableIDs = append(tableIDs, int64(tableDesc.GetID()))
+	}
+
+	return &createPublicationNode{n: n, dbDesc: dbDesc, tableIDs: tableIDs}, nil
+}
+
+func (n *createPublicationNode) startExec(params runParams) error {
+	ie := params.ExecCfg().InternalExecutor
+	row, err := ie.QueryRowEx(
+		params.ctx, "check-publication-exists", params.p.txn,
+		sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+		`SELECT 1 FROM system.publications WHERE database_id = $1 AND name = $2`,
+		n.dbDesc.GetID(), n.n.Name,
+	)
+	if err != nil {
+		return err
+	}
+	if row != nil {
+		return pgerror.Newf(pgcode.DuplicateObject,
+			"publication %q already exists", n.n.Name)
+	}
+
+	tableIDs := tree.NewDArray(types.Int)
+	for _, id := range n.tableIDs {
+		if err := tableIDs.Append(tree.NewDInt(tree.DInt(id))); err != nil {
+			return err
+		}
+	}
+	_, err = ie.ExecEx(
+		params.ctx, "create-publication", params.p.txn,
+		sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+		`INSERT INTO system.publications (database_id, name, owner, all_tables, table_ids)
+VALUES ($1, $2, $3, $4, $5)`,
+		n.dbDesc.GetID(), n.n.Name, params.p.User().Normalized(), n.n.AllTables, tableIDs,
+	)
+	return err
+}
+
+func (n *createPublicationNode) Next(runParams) (bool, error) { return false, nil }
+func (n *createPublicationNode) Values() tree.Datums          { return tree.Datums{} }
+func (n *createPublicationNode) Close(context.Context)        {}
+
+type dropPublicationNode struct {
+	n      *tree.DropPublication
+	dbDesc catalog.DatabaseDescriptor
+}
+
+// DropPublication drops publications of the current database.
+// Privileges: ownership of the publication or the admin role.
+//   notes: postgres requires ownership of the publication.
+func (p *planner) DropPublication(
+	ctx context.Context, n *tree.DropPublication,
+) (planNode, error) {
+	if err := checkPublicationsSupported(ctx, p); err != nil {
+		return nil, err
+	}
+	if n.DropBehavior == tree.DropCascade {
+		// Publications have no dependents, so CASCADE is the same as RESTRICT.
+		n.DropBehavior = tree.DropDefault
+	}
+	dbDesc, err := p.Descriptors().GetImmutableDatabaseByName(ctx, p.txn,
+		p.CurrentDatabase(), tree.DatabaseLookupFlags{Required: true})
+	if err != nil {
+		return nil, err
+	}
+	return &dropPublicationNode{n: n, dbDesc: dbDesc}, nil
+}
+
+func (n *dropPublicationNode) startExec(params runParams) error {
+	ie := params.ExecCfg().InternalExecutor
+	hasAdmin, err := params.p.HasAdminRole(params.ctx)
+	if err != nil {
+		return err
+	}
+	for _, name := range n.n.Names {
+		row, err := ie.QueryRowEx(
+			params.ctx, "get-publication-owner", params.p.txn,
+			sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+			`SELECT owner FROM system.publications WHERE database_id = $1 AND name = $2`,
+			n.dbDesc.GetID(), name,
+		)
+		if err != nil {
+			return err
+		}
+		if row == nil {
+			if n.n.IfExists {
+				continue
+			}
+			return pgerror.Newf(pgcode.UndefinedObject,
+				"publication %q does not exist", name)
+		}
+		owner := string(tree.MustBeDString(row[0]))
+		if !hasAdmin && owner != params.p.User().Normalized() {
+			return pgerror.Newf(pgcode.InsufficientPrivilege,
+				"must be owner of publication %s", name)
+		}
+		if _, err := ie.ExecEx(
+			params.ctx, "drop-publication", params.p.txn,
+			sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+			`DELETE FROM system.publications WHERE database_id = $1 AND name = $2`,
+			n.dbDesc.GetID(), name,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *dropPublicationNode) Next(runParams) (bool, error) { return false, nil }
+func (n *dropPublicationNode) Values() tree.Datums          { return tree.Datums{} }
+func (n *dropPublicationNode) Close(context.Context)        {}
@@ -89,6 +89,12 @@ func (n *refreshMaterializedViewNode) startExec(params runParams) error {
 		)
 	}
 
+	// If only a few rows of the tables the view depends on changed since the
+	// view was last refreshed, recompute only the rows of the view they affect.
+	if refreshed, err := n.maybeRefreshIncrementally(params); err != nil || refreshed {
+		return err
+	}
+
 	// Prepare the new set of indexes by cloning all existing indexes on the view.
 	newPrimaryIndex := n.desc.GetPrimaryIndex().IndexDescDeepCopy()
 	newIndexes := make([]descpb.IndexDescriptor, len(n.desc.PublicNonPrimaryIndexes()))
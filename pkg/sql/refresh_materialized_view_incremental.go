@@ -0,0 +1,861 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkv"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+)
+
+// incrementalRefreshMaxChangedRows controls whether REFRESH MATERIALIZED VIEW
+// may refresh a view incrementally, and bounds the amount of work done by an
+// incremental refresh.
+var incrementalRefreshMaxChangedRows = settings.RegisterIntSetting(
+	settings.TenantWritable,
+	"sql.materialized_view.incremental_refresh.max_changed_rows",
+	"if positive, REFRESH MATERIALIZED VIEW only recomputes the rows of a view "+
+		"affected by changes to the tables it depends on if at most this many rows "+
+		"of those tables changed since the last refresh; set to 0 to always "+
+		"recompute the entire view",
+	0,
+	settings.NonNegativeInt,
+).WithPublic()
+
+// incrementalRefreshBatchSize is the number of changed rows or of affected
+// view keys processed by each query run by an incremental refresh.
+const incrementalRefreshBatchSize = 100
+
+// incrementalViewQuery is the analysis of a materialized view query which can
+// be maintained incrementally. Such a query selects from tables, possibly
+// combined with inner joins, and either projects and filters their rows or
+// groups them by some of its output columns and computes count, sum, min and
+// max aggregates over the groups.
+//
+// The rows of the view are partitioned by the values of their key: the
+// grouping columns of an aggregating view, or all of the columns of any other
+// view. A change to a row of a base table can only affect the partitions to
+// which that row contributed before and after the change, so the view is
+// refreshed by replacing the rows of these partitions with the result of the
+// view query restricted to them.
+type incrementalViewQuery struct {
+	clause *tree.SelectClause
+	// keyExprs are the expressions of the view query computing the key of the
+	// rows of the view, and keyCols the columns of the view storing them.
+	keyExprs tree.Exprs
+	keyCols  []catalog.Column
+	// viewIndex is an index of the view on its key columns, used to find the
+	// rows of the view with some keys.
+	viewIndex catalog.Index
+	// outputOrdinals maps each public column of the view to the ordinal of the
+	// output column of the view query it stores, or to -1 for the hidden
+	// primary key column.
+	outputOrdinals []int
+	sources        []incrementalViewSource
+}
+
+// incrementalViewSource is a table in the FROM clause of an incremental view
+// query.
+type incrementalViewSource struct {
+	desc catalog.TableDescriptor
+	// name is the name by which the view query refers to the table.
+	name tree.TableName
+	// keyOrdinals are the ordinals of the key expressions which are columns of
+	// the table, at least one of which is the first column of an index.
+	keyOrdinals []int
+}
+
+// incrementalAggregates are the aggregate functions which may be used by an
+// incrementally maintained view.
+var incrementalAggregates = map[string]struct{}{
+	"count": {},
+	"sum":   {},
+	"min":   {},
+	"max":   {},
+}
+
+// maybeRefreshIncrementally refreshes the view in place, in the transaction
+// of the REFRESH statement, by recomputing only the rows of the view affected
+// by the changes to its base tables since the view was last refreshed. It
+// returns false without modifying the view if the view must be refreshed in
+// full instead.
+func (n *refreshMaterializedViewNode) maybeRefreshIncrementally(params runParams) (bool, error) {
+	ctx, p := params.ctx, params.p
+	maxChangedRows := incrementalRefreshMaxChangedRows.Get(&p.ExecCfg().Settings.SV)
+	if maxChangedRows == 0 || n.n.RefreshDataOption == tree.RefreshDataClear {
+		return false, nil
+	}
+	fullRefresh := func(reason string, args ...interface{}) (bool, error) {
+		log.VEventf(ctx, 2, "refreshing materialized view %q in full: %s",
+			n.desc.Name, fmt.Sprintf(reason, args...))
+		return false, nil
+	}
+	start, end := n.desc.MaterializedViewAsOfTime, p.Txn().ReadTimestamp()
+	if start.IsEmpty() {
+		return fullRefresh("the time of the last refresh is unknown")
+	}
+	q, reason, err := p.analyzeIncrementalViewQuery(ctx, n.desc)
+	if err != nil {
+		return false, err
+	}
+	if q == nil {
+		return fullRefresh("%s", reason)
+	}
+
+	if start.Less(end) {
+		// The changes to the rows of a source are read from its primary index,
+		// which does not reflect schema changes replacing the index, such as
+		// TRUNCATE. Any schema change to a source since the last refresh
+		// requires a full refresh.
+		for _, src := range q.sources {
+			prev, err := tableDescAsOf(ctx, p.ExecCfg().DB, p.ExecCfg().Codec, src.desc.GetID(), start)
+			if errors.HasType(err, (*roachpb.BatchTimestampBeforeGCError)(nil)) ||
+				sqlerrors.IsUndefinedRelationError(err) {
+				return fullRefresh("the schema of %q at the last refresh is unknown", src.desc.GetName())
+			} else if err != nil {
+				return false, err
+			}
+			if prev.GetPrimaryIndexID() != src.desc.GetPrimaryIndexID() ||
+				prev.GetVersion() != src.desc.GetVersion() {
+				return fullRefresh("the schema of %q changed since the last refresh", src.desc.GetName())
+			}
+		}
+
+		changed := make(map[descpb.ID][]tree.Datums)
+		var numChanged int64
+		for _, src := range q.sources {
+			if _, ok := changed[src.desc.GetID()]; ok {
+				continue
+			}
+			rows, ok, err := changedPrimaryKeys(
+				ctx, p.ExecCfg().DB, p.ExecCfg().Codec, src.desc, start, end, maxChangedRows-numChanged,
+			)
+			if errors.HasType(err, (*roachpb.BatchTimestampBeforeGCError)(nil)) {
+				return fullRefresh("the changes to %q since the last refresh were garbage collected",
+					src.desc.GetName())
+			} else if err != nil {
+				return false, err
+			}
+			if !ok {
+				return fullRefresh("more than %d rows changed since the last refresh", maxChangedRows)
+			}
+			changed[src.desc.GetID()] = rows
+			numChanged += int64(len(rows))
+		}
+
+		affected, err := q.affectedKeys(ctx, p, changed, start)
+		if err != nil {
+			return false, err
+		}
+		view := n.desc.ImmutableCopy().(catalog.TableDescriptor)
+		if err := q.refreshKeys(ctx, p, view, affected); err != nil {
+			return false, err
+		}
+	}
+
+	n.desc.MaterializedViewAsOfTime = end
+	return true, p.writeSchemaChange(
+		ctx, n.desc, descpb.InvalidMutationID, tree.AsStringWithFQNames(n.n, params.Ann()),
+	)
+}
+
+// tableDescAsOf returns the descriptor of the table as of the given timestamp.
+func tableDescAsOf(
+	ctx context.Context, db *kv.DB, codec keys.SQLCodec, id descpb.ID, ts hlc.Timestamp,
+) (catalog.TableDescriptor, error) {
+	var desc catalog.TableDescriptor
+	if err := db.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		if err := txn.SetFixedTimestamp(ctx, ts); err != nil {
+			return err
+		}
+		var err error
+		desc, err = catalogkv.MustGetTableDescByID(ctx, txn, codec, id)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return desc, nil
+}
+
+// analyzeIncrementalViewQuery returns the analysis of the query of the view,
+// or nil and the reason why the view cannot be refreshed incrementally.
+func (p *planner) analyzeIncrementalViewQuery(
+	ctx context.Context, view *tabledesc.Mutable,
+) (*incrementalViewQuery, string, error) {
+	if len(view.AllMutations()) > 0 {
+		return nil, "the view has pending schema changes", nil
+	}
+	if len(view.PartialIndexes()) > 0 {
+		return nil, "the view has partial indexes", nil
+	}
+	// The view stores the output columns of its query in its visible columns,
+	// and is keyed by a hidden row ID column.
+	q := &incrementalViewQuery{}
+	primary := view.GetPrimaryIndex()
+	if primary.NumKeyColumns() != 1 {
+		return nil, "the view has an unexpected primary key", nil
+	}
+	var numVisible int
+	for _, col := range view.PublicColumns() {
+		if !col.IsHidden() {
+			q.outputOrdinals = append(q.outputOrdinals, numVisible)
+			numVisible++
+		} else if col.GetID() == primary.GetKeyColumnID(0) && col.GetType().Family() == types.IntFamily {
+			q.outputOrdinals = append(q.outputOrdinals, -1)
+		} else {
+			return nil, "the view has an unexpected hidden column", nil
+		}
+	}
+
+	stmt, err := parser.ParseOne(view.ViewQuery)
+	if err != nil {
+		return nil, "", err
+	}
+	sel, ok := stmt.AST.(*tree.Select)
+	if !ok || sel.With != nil || sel.OrderBy != nil || sel.Limit != nil || sel.Locking != nil {
+		return nil, "the query is not a simple SELECT", nil
+	}
+	clause, ok := sel.Select.(*tree.SelectClause)
+	if !ok || clause.TableSelect || clause.DistinctOn != nil || clause.Window != nil ||
+		clause.From.AsOf.Expr != nil || len(clause.From.Tables) == 0 {
+		return nil, "the query is not a simple SELECT", nil
+	}
+	if len(clause.Exprs) != numVisible {
+		return nil, "the columns of the view do not match its query", nil
+	}
+	q.clause = clause
+
+	for _, t := range clause.From.Tables {
+		if reason, err := q.addSources(ctx, p, t); reason != "" || err != nil {
+			return nil, reason, err
+		}
+	}
+
+	// Aggregates may only be used in the output columns and in the HAVING
+	// clause of a grouping query.
+	grouped := len(clause.GroupBy) > 0
+	var numAggregates int
+	for _, e := range clause.Exprs {
+		if _, ok := e.Expr.(tree.UnqualifiedStar); ok {
+			return nil, "the query selects *", nil
+		}
+		if name, ok := e.Expr.(*tree.UnresolvedName); ok && name.Star {
+			return nil, "the query selects *", nil
+		}
+		n, reason := checkIncrementalViewExpr(e.Expr, grouped)
+		if reason != "" {
+			return nil, reason, nil
+		}
+		numAggregates += n
+	}
+	if clause.Having != nil {
+		if _, reason := checkIncrementalViewExpr(clause.Having.Expr, grouped); reason != "" {
+			return nil, reason, nil
+		}
+	}
+	if clause.Where != nil {
+		if _, reason := checkIncrementalViewExpr(clause.Where.Expr, false /* allowAggregates */); reason != "" {
+			return nil, reason, nil
+		}
+	}
+
+	visible := view.VisibleColumns()
+	if !grouped {
+		if numAggregates > 0 || clause.Having != nil {
+			return nil, "the query aggregates without GROUP BY", nil
+		}
+		for i, e := range clause.Exprs {
+			q.keyExprs = append(q.keyExprs, e.Expr)
+			q.keyCols = append(q.keyCols, visible[i])
+		}
+	} else {
+		// Every grouping column must be stored by the view, so that the rows of
+		// each group can be found in it.
+		for _, g := range clause.GroupBy {
+			name, ok := g.(*tree.UnresolvedName)
+			if !ok || name.Star {
+				return nil, "the query groups by an expression", nil
+			}
+			found := false
+			for i, e := range clause.Exprs {
+				if other, ok := e.Expr.(*tree.UnresolvedName); ok && tree.AsString(other) == tree.AsString(name) {
+					q.keyExprs = append(q.keyExprs, name)
+					q.keyCols = append(q.keyCols, visible[i])
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, fmt.Sprintf("the grouping column %s is not an output column", name), nil
+			}
+		}
+	}
+	if reason := q.findKeyIndexes(view); reason != "" {
+		return nil, reason, nil
+	}
+	return q, "", nil
+}
+
+// findKeyIndexes finds the indexes used to look up the rows of the keys
+// affected by a refresh: an index of the view on its key columns, and for each
+// source an index on one of its columns in the key. It returns the reason why
+// the view cannot be refreshed incrementally if one of them is missing, since
+// the lookups would scan the view or the source in full.
+func (q *incrementalViewQuery) findKeyIndexes(view catalog.TableDescriptor) string {
+	var keyColIDs catalog.TableColSet
+	for _, col := range q.keyCols {
+		keyColIDs.Add(col.GetID())
+	}
+	for _, idx := range view.ActiveIndexes() {
+		if idx.GetType() != descpb.IndexDescriptor_FORWARD || idx.NumKeyColumns() < keyColIDs.Len() {
+			continue
+		}
+		matches := true
+		for i := 0; i < keyColIDs.Len(); i++ {
+			matches = matches && keyColIDs.Contains(idx.GetKeyColumnID(i))
+		}
+		if matches {
+			q.viewIndex = idx
+			break
+		}
+	}
+	if q.viewIndex == nil {
+		return "the view has no index on its key columns"
+	}
+
+	for i := range q.sources {
+		src := &q.sources[i]
+		indexed := false
+		for ord, e := range q.keyExprs {
+			col := q.sourceColumn(i, e)
+			if col == nil {
+				continue
+			}
+			src.keyOrdinals = append(src.keyOrdinals, ord)
+			for _, idx := range src.desc.ActiveIndexes() {
+				if idx.GetType() == descpb.IndexDescriptor_FORWARD && !idx.IsPartial() &&
+					idx.GetKeyColumnID(0) == col.GetID() {
+					indexed = true
+				}
+			}
+		}
+		if !indexed {
+			return fmt.Sprintf("%q has no index on a key column of the view", src.desc.GetName())
+		}
+	}
+	return ""
+}
+
+// sourceColumn returns the column of the source with the given ordinal to
+// which the key expression refers, or nil if the expression is not a column
+// of this source.
+func (q *incrementalViewQuery) sourceColumn(ord int, e tree.Expr) catalog.Column {
+	name, ok := e.(*tree.UnresolvedName)
+	if !ok || name.Star {
+		return nil
+	}
+	var res catalog.Column
+	for i := range q.sources {
+		src := &q.sources[i]
+		if name.NumParts > 1 && name.Parts[1] != string(src.name.ObjectName) {
+			continue
+		}
+		col, err := src.desc.FindColumnWithName(tree.Name(name.Parts[0]))
+		if err != nil || !col.Public() {
+			continue
+		}
+		if i != ord {
+			// The column is ambiguous, or belongs to another source.
+			return nil
+		}
+		res = col
+	}
+	return res
+}
+
+// addSources adds the tables in the table expression to the sources of the
+// query, or returns the reason why the expression is not supported.
+func (q *incrementalViewQuery) addSources(
+	ctx context.Context, p *planner, t tree.TableExpr,
+) (string, error) {
+	switch t := t.(type) {
+	case *tree.ParenTableExpr:
+		return q.addSources(ctx, p, t.Expr)
+
+	case *tree.JoinTableExpr:
+		if t.JoinType != "" && t.JoinType != tree.AstInner && t.JoinType != tree.AstCross {
+			return "the query uses an outer join", nil
+		}
+		if on, ok := t.Cond.(*tree.OnJoinCond); ok {
+			if _, reason := checkIncrementalViewExpr(on.Expr, false /* allowAggregates */); reason != "" {
+				return reason, nil
+			}
+		}
+		if reason, err := q.addSources(ctx, p, t.Left); reason != "" || err != nil {
+			return reason, err
+		}
+		return q.addSources(ctx, p, t.Right)
+
+	case *tree.AliasedTableExpr:
+		tn, ok := t.Expr.(*tree.TableName)
+		if !ok || t.Ordinality || t.Lateral || len(t.As.Cols) > 0 {
+			return "the query selects from something other than a table", nil
+		}
+		desc, err := p.ResolveExistingObjectEx(
+			ctx, tn.ToUnresolvedObjectName(), true /* required */, tree.ResolveRequireTableDesc,
+		)
+		if err != nil {
+			return "", err
+		}
+		if !desc.IsPhysicalTable() || desc.IsSequence() {
+			return fmt.Sprintf("%q is not a table", desc.GetName()), nil
+		}
+		// The primary keys of the changed rows are decoded from the keys of the
+		// primary index, which is not possible for composite types.
+		primary := desc.GetPrimaryIndex()
+		for i := 0; i < primary.NumKeyColumns(); i++ {
+			col, err := desc.FindColumnWithID(primary.GetKeyColumnID(i))
+			if err != nil {
+				return "", err
+			}
+			if colinfo.CanHaveCompositeKeyEncoding(col.GetType()) {
+				return fmt.Sprintf("the primary key of %q has a composite type", desc.GetName()), nil
+			}
+		}
+		src := incrementalViewSource{desc: desc, name: *tn}
+		if t.As.Alias != "" {
+			src.name = tree.MakeUnqualifiedTableName(t.As.Alias)
+		}
+		q.sources = append(q.sources, src)
+		return "", nil
+
+	default:
+		return "the query selects from something other than a table", nil
+	}
+}
+
+// checkIncrementalViewExpr returns the number of aggregates in the expression
+// of an incremental view query, or the reason why the expression is not
+// supported. Functions other than the incremental aggregates are not
+// supported, since they could depend on the time of the refresh.
+func checkIncrementalViewExpr(expr tree.Expr, allowAggregates bool) (int, string) {
+	v := incrementalViewExprChecker{allowAggregates: allowAggregates}
+	tree.WalkExprConst(&v, expr)
+	return v.aggregates, v.reason
+}
+
+type incrementalViewExprChecker struct {
+	allowAggregates bool
+	aggregates      int
+	reason          string
+}
+
+var _ tree.Visitor = &incrementalViewExprChecker{}
+
+// VisitPre implements the tree.Visitor interface.
+func (v *incrementalViewExprChecker) VisitPre(expr tree.Expr) (bool, tree.Expr) {
+	if v.reason != "" {
+		return false, expr
+	}
+	switch t := expr.(type) {
+	case *tree.Subquery:
+		v.reason = "the query contains a subquery"
+	case *tree.Placeholder:
+		v.reason = "the query contains a placeholder"
+	case *tree.FuncExpr:
+		name, ok := t.Func.FunctionReference.(*tree.UnresolvedName)
+		if !ok || name.NumParts != 1 {
+			v.reason = "the query calls a function"
+			break
+		}
+		fn := strings.ToLower(name.Parts[0])
+		if _, ok := incrementalAggregates[fn]; !ok || !v.allowAggregates || t.WindowDef != nil {
+			v.reason = fmt.Sprintf("the query calls %s", fn)
+			break
+		}
+		v.aggregates++
+	}
+	return v.reason == "", expr
+}
+
+// VisitPost implements the tree.Visitor interface.
+func (v *incrementalViewExprChecker) VisitPost(expr tree.Expr) tree.Expr { return expr }
+
+// changedPrimaryKeysPageBytes is the target size of the pages of changes read
+// by changedPrimaryKeys.
+const changedPrimaryKeysPageBytes = 1 << 20
+
+// changedPrimaryKeys returns the primary keys of the rows of the table which
+// changed after start and up to end. The changes are found by export
+// requests, which read them with an incremental MVCC iterator and thus only
+// visit the data written in this interval. It returns false, without reading
+// the remaining changes, as soon as more than maxRows rows are found to have
+// changed.
+func changedPrimaryKeys(
+	ctx context.Context,
+	db *kv.DB,
+	codec keys.SQLCodec,
+	table catalog.TableDescriptor,
+	start, end hlc.Timestamp,
+	maxRows int64,
+) ([]tree.Datums, bool, error) {
+	primary := table.GetPrimaryIndex()
+	colTypes := make([]*types.T, primary.NumKeyColumns())
+	dirs := make([]descpb.IndexDescriptor_Direction, primary.NumKeyColumns())
+	for i := range colTypes {
+		col, err := table.FindColumnWithID(primary.GetKeyColumnID(i))
+		if err != nil {
+			return nil, false, err
+		}
+		colTypes[i] = col.GetType()
+		dirs[i] = primary.GetKeyColumnDirection(i)
+	}
+
+	// A row is stored in one key per column family, so several changed keys can
+	// belong to the same row, possibly returned in different pages.
+	var res []tree.Datums
+	var alloc tree.DatumAlloc
+	seen := make(map[string]struct{})
+	vals := make([]rowenc.EncDatum, len(colTypes))
+	decodeFile := func(sst []byte) error {
+		iter, err := storage.NewMemSSTIterator(sst, false /* verify */)
+		if err != nil {
+			return err
+		}
+		defer iter.Close()
+		for iter.SeekGE(storage.NilKey); ; iter.Next() {
+			if ok, err := iter.Valid(); err != nil || !ok {
+				return err
+			}
+			key := iter.UnsafeKey().Key
+			remaining, _, err := rowenc.DecodeIndexKey(codec, colTypes, vals, dirs, key)
+			if err != nil {
+				return err
+			}
+			rowKey := string(key[:len(key)-len(remaining)])
+			if _, ok := seen[rowKey]; ok {
+				continue
+			}
+			seen[rowKey] = struct{}{}
+			datums := make(tree.Datums, len(vals))
+			for i := range vals {
+				if err := vals[i].EnsureDecoded(colTypes[i], &alloc); err != nil {
+					return err
+				}
+				datums[i] = vals[i].Datum
+			}
+			res = append(res, datums)
+		}
+	}
+
+	// The changes are read in pages of at most changedPrimaryKeysPageBytes, so
+	// that no more than a page of changes beyond maxRows is ever read.
+	span := table.PrimaryIndexSpan(codec)
+	for {
+		req := &roachpb.ExportRequest{
+			RequestHeader:  roachpb.RequestHeaderFromSpan(span),
+			StartTime:      start,
+			MVCCFilter:     roachpb.MVCCFilter_Latest,
+			TargetFileSize: changedPrimaryKeysPageBytes,
+			ReturnSST:      true,
+		}
+		header := roachpb.Header{Timestamp: end, TargetBytes: changedPrimaryKeysPageBytes}
+		raw, pErr := kv.SendWrappedWith(ctx, db.NonTransactionalSender(), header, req)
+		if pErr != nil {
+			return nil, false, pErr.GoError()
+		}
+		resp := raw.(*roachpb.ExportResponse)
+		for _, file := range resp.Files {
+			if err := decodeFile(file.SST); err != nil {
+				return nil, false, err
+			}
+			if int64(len(res)) > maxRows {
+				return nil, false, nil
+			}
+		}
+		if resp.ResumeSpan == nil {
+			return res, true, nil
+		}
+		if !resp.ResumeSpan.Valid() {
+			return nil, false, errors.AssertionFailedf("invalid resume span: %s", resp.ResumeSpan)
+		}
+		span = *resp.ResumeSpan
+	}
+}
+
+// affectedKeys returns the keys of the rows of the view affected by changes to
+// the rows of its sources with the given primary keys: the keys of the rows
+// of the view to which these rows contributed before the changes, at start,
+// and after the changes, at the timestamp of the transaction.
+func (q *incrementalViewQuery) affectedKeys(
+	ctx context.Context, p *planner, changed map[descpb.ID][]tree.Datums, start hlc.Timestamp,
+) ([]tree.Datums, error) {
+	keyExprs := make(tree.SelectExprs, len(q.keyExprs))
+	for i, e := range q.keyExprs {
+		keyExprs[i] = tree.SelectExpr{Expr: e}
+	}
+
+	var res []tree.Datums
+	seen := make(map[string]struct{})
+	for _, src := range q.sources {
+		rows := changed[src.desc.GetID()]
+		for len(rows) > 0 {
+			batch := rows
+			if len(batch) > incrementalRefreshBatchSize {
+				batch = batch[:incrementalRefreshBatchSize]
+			}
+			rows = rows[len(batch):]
+
+			sel := *q.clause
+			sel.Exprs = keyExprs
+			sel.Distinct = true
+			sel.GroupBy, sel.Having = nil, nil
+			sel.Where = andWhere(sel.Where, src.primaryKeyPredicate(batch))
+			before := sel
+			before.From.AsOf = tree.AsOfClause{Expr: tree.NewStrVal(start.AsOfSystemTime())}
+
+			for _, query := range []struct {
+				txn *kv.Txn
+				sel *tree.SelectClause
+			}{{nil, &before}, {p.txn, &sel}} {
+				found, err := p.queryForIncrementalRefresh(ctx, query.txn, query.sel)
+				if err != nil {
+					return nil, err
+				}
+				for _, key := range found {
+					s := tree.AsStringWithFlags(&tree.DTuple{D: key}, tree.FmtParsable)
+					if _, ok := seen[s]; !ok {
+						seen[s] = struct{}{}
+						res = append(res, key)
+					}
+				}
+			}
+		}
+	}
+	return res, nil
+}
+
+// refreshKeys replaces the rows of the view with the given keys with the
+// result of the view query for these keys.
+func (q *incrementalViewQuery) refreshKeys(
+	ctx context.Context, p *planner, view catalog.TableDescriptor, affected []tree.Datums,
+) error {
+	internal := p.SessionData().Internal
+	sv := &p.ExecCfg().Settings.SV
+	metrics := p.ExecCfg().GetRowMetrics(internal)
+	cols := view.PublicColumns()
+	rd := row.MakeDeleter(p.ExecCfg().Codec, view, cols, sv, internal, metrics)
+	ri, err := row.MakeInserter(ctx, p.txn, p.ExecCfg().Codec, view, cols, p.alloc, sv, internal, metrics)
+	if err != nil {
+		return err
+	}
+	traceKV := p.ExtendedEvalContext().Tracing.KVTracingEnabled()
+	// The view has no partial indexes.
+	var pm row.PartialIndexUpdateHelper
+
+	viewExprs := make(tree.SelectExprs, len(cols))
+	for i, col := range cols {
+		viewExprs[i] = tree.SelectExpr{Expr: tree.NewUnresolvedName(col.GetName())}
+	}
+	viewKeyExprs := make(tree.Exprs, len(q.keyCols))
+	for i, col := range q.keyCols {
+		viewKeyExprs[i] = tree.NewUnresolvedName(col.GetName())
+	}
+	viewSource := &tree.AliasedTableExpr{
+		Expr:       &tree.TableRef{TableID: int64(view.GetID()), As: tree.AliasClause{Alias: "v"}},
+		IndexFlags: &tree.IndexFlags{IndexID: tree.IndexID(q.viewIndex.GetID())},
+	}
+
+	for len(affected) > 0 {
+		batch := affected
+		if len(batch) > incrementalRefreshBatchSize {
+			batch = batch[:incrementalRefreshBatchSize]
+		}
+		affected = affected[len(batch):]
+
+		b := p.txn.NewBatch()
+		oldRows, err := p.queryForIncrementalRefresh(ctx, p.txn, &tree.SelectClause{
+			Exprs: viewExprs,
+			From:  tree.From{Tables: tree.TableExprs{viewSource}},
+			Where: tree.NewWhere(tree.AstWhere, keyPredicate(viewKeyExprs, batch)),
+		})
+		if err != nil {
+			return err
+		}
+		for _, r := range oldRows {
+			if err := rd.DeleteRow(ctx, b, r, pm, traceKV); err != nil {
+				return err
+			}
+		}
+
+		// The rows of each source with the keys are found using its index on
+		// one of the key columns, which requires a predicate on its columns
+		// alone.
+		sel := *q.clause
+		sel.Where = andWhere(sel.Where, keyPredicate(q.keyExprs, batch))
+		for _, src := range q.sources {
+			if len(src.keyOrdinals) < len(q.keyExprs) {
+				sel.Where = andWhere(sel.Where, src.keyPredicate(q.keyExprs, batch))
+			}
+		}
+		newRows, err := p.queryForIncrementalRefresh(ctx, p.txn, &sel)
+		if err != nil {
+			return err
+		}
+		for _, r := range newRows {
+			values := make(tree.Datums, len(cols))
+			for i, ord := range q.outputOrdinals {
+				if ord < 0 {
+					values[i] = tree.NewDInt(builtins.GenerateUniqueInt(p.ExecCfg().NodeID.SQLInstanceID()))
+				} else {
+					values[i] = r[ord]
+				}
+			}
+			if err := ri.InsertRow(ctx, b, values, pm, false /* overwrite */, traceKV); err != nil {
+				return err
+			}
+		}
+
+		if err := p.txn.Run(ctx, b); err != nil {
+			return row.ConvertBatchError(ctx, view, b)
+		}
+	}
+	return nil
+}
+
+// queryForIncrementalRefresh runs a query of an incremental refresh.
+func (p *planner) queryForIncrementalRefresh(
+	ctx context.Context, txn *kv.Txn, sel *tree.SelectClause,
+) ([]tree.Datums, error) {
+	return p.ExecCfg().InternalExecutor.QueryBufferedEx(
+		ctx,
+		"refresh-materialized-view",
+		txn,
+		sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+		tree.AsStringWithFlags(sel, tree.FmtParsable),
+	)
+}
+
+// primaryKeyPredicate returns a predicate selecting the rows of the source
+// with the given primary keys.
+func (src *incrementalViewSource) primaryKeyPredicate(pks []tree.Datums) tree.Expr {
+	primary := src.desc.GetPrimaryIndex()
+	cols := make(tree.Exprs, primary.NumKeyColumns())
+	for i := range cols {
+		cols[i] = tree.NewColumnItem(&src.name, tree.Name(primary.GetKeyColumnName(i)))
+	}
+	vals := make(tree.Exprs, len(pks))
+	for i, pk := range pks {
+		if len(cols) == 1 {
+			vals[i] = pk[0]
+		} else {
+			vals[i] = &tree.Tuple{Exprs: datumsToExprs(pk)}
+		}
+	}
+	var left tree.Expr = &tree.Tuple{Exprs: cols}
+	if len(cols) == 1 {
+		left = cols[0]
+	}
+	return &tree.ComparisonExpr{
+		Operator: tree.MakeComparisonOperator(tree.In),
+		Left:     left,
+		Right:    &tree.Tuple{Exprs: vals},
+	}
+}
+
+// keyPredicate returns a predicate selecting the rows of the source with the
+// values of its columns in the given keys of the view.
+func (src *incrementalViewSource) keyPredicate(keyExprs tree.Exprs, keys []tree.Datums) tree.Expr {
+	exprs := make(tree.Exprs, len(src.keyOrdinals))
+	for i, ord := range src.keyOrdinals {
+		exprs[i] = keyExprs[ord]
+	}
+	projected := make([]tree.Datums, len(keys))
+	for i, key := range keys {
+		projected[i] = make(tree.Datums, len(src.keyOrdinals))
+		for j, ord := range src.keyOrdinals {
+			projected[i][j] = key[ord]
+		}
+	}
+	return keyPredicate(exprs, projected)
+}
+
+// keyPredicate returns a predicate selecting the rows for which the key
+// expressions evaluate to one of the given keys. Keys may contain NULLs, which
+// are matched with IS NOT DISTINCT FROM.
+func keyPredicate(keyExprs tree.Exprs, keys []tree.Datums) tree.Expr {
+	var res tree.Expr
+	for _, key := range keys {
+		var match tree.Expr
+		for i, e := range keyExprs {
+			var cmp tree.Expr = &tree.ComparisonExpr{
+				Operator: tree.MakeComparisonOperator(tree.IsNotDistinctFrom),
+				Left:     e,
+				Right:    key[i],
+			}
+			if match != nil {
+				cmp = &tree.AndExpr{Left: match, Right: cmp}
+			}
+			match = cmp
+		}
+		if match == nil {
+			match = tree.DBoolTrue
+		}
+		if res != nil {
+			match = &tree.OrExpr{Left: res, Right: match}
+		}
+		res = match
+	}
+	if res == nil {
+		return tree.DBoolFalse
+	}
+	return res
+}
+
+// andWhere returns a WHERE clause requiring both the existing clause, which
+// may be nil, and the predicate.
+func andWhere(where *tree.Where, pred tree.Expr) *tree.Where {
+	if where == nil {
+		return tree.NewWhere(tree.AstWhere, pred)
+	}
+	return tree.NewWhere(tree.AstWhere, &tree.AndExpr{Left: where.Expr, Right: pred})
+}
+
+func datumsToExprs(datums tree.Datums) tree.Exprs {
+	res := make(tree.Exprs, len(datums))
+	for i, d := range datums {
+		res[i] = d
+	}
+	return res
+}
@@ -169,6 +169,11 @@ func (n *renameTableNode) startExec(params runParams) error {
 
 	// Ensure tables cannot be moved cross-database.
 	if oldTn.Catalog() != newTn.Catalog() {
+		// Triggers reference their functions by name in the schema of the table.
+		if len(tableDesc.Triggers) > 0 {
+			return pgerror.Newf(pgcode.FeatureNotSupported,
+				"cannot change database of table %q which has triggers", tableDesc.Name)
+		}
 		// TODO(richardjcai): Remove this in 22.1. In 21.2, we allow moving tables
 		// from one database's public schema to another database's public schema
 		// as a special case. However after 22.1 all public schemas will be backed
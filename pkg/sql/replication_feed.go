@@ -0,0 +1,793 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+	"unsafe"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/rangefeed"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondatapb"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/lsn"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/cockroachdb/errors"
+)
+
+// ReplicationConn is the network connection over which a logical replication
+// stream is served. It is implemented by pgwire.
+type ReplicationConn interface {
+	// ServeReplication sends the transactions produced by the feed to the
+	// client, and reports the positions confirmed by the client back to the
+	// feed, until the client ends the stream. The values of the rows are
+	// formatted according to the given session settings.
+	//
+	// If an error is returned, it has already been sent to the client and the
+	// connection is closed, since the client may still be sending messages of
+	// the stream.
+	ServeReplication(
+		ctx context.Context,
+		feed *ReplicationFeed,
+		conv sessiondatapb.DataConversionConfig,
+		sessionLoc *time.Location,
+	) error
+}
+
+// ReplicationChangeKind is the kind of a row change.
+type ReplicationChangeKind int
+
+const (
+	// ReplicationInsert is the insertion of a new row.
+	ReplicationInsert ReplicationChangeKind = iota
+	// ReplicationUpdate is the update of an existing row.
+	ReplicationUpdate
+	// ReplicationDelete is the deletion of a row.
+	ReplicationDelete
+)
+
+// ReplicationChange is a change to a row of a published table.
+type ReplicationChange struct {
+	Table catalog.TableDescriptor
+	// SchemaName is the name of the schema of the table.
+	SchemaName string
+	Kind       ReplicationChangeKind
+	// Row contains the values of the public columns of the table after an
+	// insert or an update. For a delete, it only contains the values of the
+	// primary key columns, the other columns being NULL.
+	Row tree.Datums
+	// PrevRow contains the values of the row before an update or a delete, if
+	// they are known.
+	PrevRow tree.Datums
+}
+
+// ReplicationTxn groups the changes committed at the same timestamp. Since
+// LSNs are derived from the wall time of the commit timestamps, the
+// transactions committed within the same nanosecond share their LSNs.
+type ReplicationTxn struct {
+	// LSN is the LSN of the changes of the transaction. The LSN of its commit
+	// is LSN.CommitLSN().
+	LSN        lsn.LSN
+	CommitTime time.Time
+	Changes    []ReplicationChange
+}
+
+// replicationSlotPersistInterval is the minimum interval between two updates
+// of the position confirmed by the client of a replication slot.
+var replicationSlotPersistInterval = 5 * time.Second
+
+// replicationFeedMemoryLimit is the maximum amount of memory used by a
+// replication feed to buffer the changes which haven't been sent to the client.
+var replicationFeedMemoryLimit = settings.RegisterByteSizeSetting(
+	settings.TenantWritable,
+	"sql.replication.feed_memory_limit",
+	"maximum amount of memory used by a logical replication stream to buffer "+
+		"the changes which have not been sent to the client",
+	64<<20, /* 64 MiB */
+)
+
+// replicationEvent is a decoded change that has not been resolved yet.
+type replicationEvent struct {
+	key    roachpb.Key
+	ts     hlc.Timestamp
+	change ReplicationChange
+	// memSize is the memory accounted for the change.
+	memSize int64
+}
+
+const sizeOfReplicationEvent = int64(unsafe.Sizeof(replicationEvent{}))
+
+// replicationEventMemSize estimates the memory used by a buffered change.
+func replicationEventMemSize(ev *replicationEvent) int64 {
+	sz := sizeOfReplicationEvent + int64(cap(ev.key))
+	for _, d := range ev.change.Row {
+		sz += int64(d.Size())
+	}
+	for _, d := range ev.change.PrevRow {
+		sz += int64(d.Size())
+	}
+	return sz
+}
+
+type replicationFetcher struct {
+	version descpb.DescriptorVersion
+	rf      *row.Fetcher
+}
+
+// ReplicationFeed produces the changes to the tables of a set of publications
+// in commit order, using a rangefeed over the primary indexes of the tables.
+//
+// The set of tables is determined when the feed starts, so tables added to
+// the publications, or created in a database published with FOR ALL TABLES,
+// are only streamed by subsequent feeds. The protected timestamp record of the
+// replication slot is replaced when the feed starts to cover these tables, and
+// is advanced with the position confirmed by the client, so that the changes
+// which the client may still request are not garbage collected.
+type ReplicationFeed struct {
+	cfg       *ExecutorConfig
+	slot      string
+	startLSN  lsn.LSN
+	rangeFeed *rangefeed.RangeFeed
+
+	// memMonitor limits the memory used to buffer the changes, both pending and
+	// resolved, to replicationFeedMemoryLimit.
+	memMonitor *mon.BytesMonitor
+
+	// The fields below are only accessed by the callbacks of the rangefeed,
+	// which are never called concurrently.
+	schemaNames map[descpb.ID]string
+	fetchers    map[descpb.ID]replicationFetcher
+	alloc       tree.DatumAlloc
+	kvFetcher   row.SpanKVFetcher
+	pending     []replicationEvent
+
+	// notify is signaled when transactions become available or an error
+	// occurs.
+	notify chan struct{}
+	mu     struct {
+		syncutil.Mutex
+		// txns are the resolved transactions that haven't been returned by Next
+		// yet, and txnsMemSize the memory accounted for each of them.
+		txns        []ReplicationTxn
+		txnsMemSize []int64
+		// memAcc accounts for the changes in pending and txns. It is protected by
+		// the mutex, since pending changes are accounted by the rangefeed
+		// callbacks and released by Next.
+		memAcc mon.BoundAccount
+		// resolved is the highest LSN up to which all the changes are known.
+		resolved lsn.LSN
+		// sharedLSNs are the LSNs, in increasing order, of the resolved
+		// transactions that share their LSN with another transaction and
+		// whose commit hasn't been confirmed by the client.
+		sharedLSNs []lsn.LSN
+		err        error
+	}
+
+	// The fields below are only accessed by the goroutine serving the feed.
+	confirmed     lsn.LSN
+	persisted     lsn.LSN
+	lastPersisted time.Time
+	// ptsRecord is the ID of the protected timestamp record of the slot, or nil
+	// if the slot has none.
+	ptsRecord *uuid.UUID
+}
+
+// newReplicationFeed starts a feed for the replication slot named in the
+// START_REPLICATION command, on behalf of the user of the session.
+func newReplicationFeed(
+	ctx context.Context,
+	cfg *ExecutorConfig,
+	sd *sessiondata.SessionData,
+	stmt *tree.StartReplication,
+) (*ReplicationFeed, error) {
+	if !cfg.Settings.Version.IsActive(ctx, clusterversion.PublicationsAndReplicationSlots) {
+		return nil, pgerror.New(pgcode.FeatureNotSupported,
+			"logical replication is not supported until version upgrade is finalized")
+	}
+	pubNames, err := parsePGOutputOptions(stmt)
+	if err != nil {
+		return nil, err
+	}
+	f := &ReplicationFeed{
+		cfg:         cfg,
+		slot:        string(stmt.Slot),
+		schemaNames: make(map[descpb.ID]string),
+		fetchers:    make(map[descpb.ID]replicationFetcher),
+		notify:      make(chan struct{}, 1),
+	}
+
+	var spans []roachpb.Span
+	if err := cfg.DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		spans = nil
+		p, cleanup := newInternalPlanner(
+			"start-replication", txn, sd.User(), &MemoryMetrics{}, cfg, sd.SessionData,
+		)
+		defer cleanup()
+		if err := p.RequireAdminRole(ctx, "start replication"); err != nil {
+			return err
+		}
+		if sd.Database == "" {
+			return pgerror.New(pgcode.ObjectNotInPrerequisiteState,
+				"logical replication requires a database")
+		}
+		dbDesc, err := p.Descriptors().GetImmutableDatabaseByName(ctx, txn,
+			sd.Database, tree.DatabaseLookupFlags{Required: true})
+		if err != nil {
+			return err
+		}
+
+		slot, err := cfg.InternalExecutor.QueryRowEx(
+			ctx, "get-replication-slot", txn,
+			sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+			`SELECT database_id, confirmed_flush_lsn, protected_timestamp_record
+FROM system.replication_slots WHERE name = $1`,
+			f.slot,
+		)
+		if err != nil {
+			return err
+		}
+		if slot == nil {
+			return pgerror.Newf(pgcode.UndefinedObject,
+				"replication slot %q does not exist", f.slot)
+		}
+		if descpb.ID(tree.MustBeDInt(slot[0])) != dbDesc.GetID() {
+			return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+				"replication slot %q was not created in database %q", f.slot, sd.Database)
+		}
+		f.confirmed = lsn.LSN(tree.MustBeDInt(slot[1]))
+		f.persisted = f.confirmed
+		f.startLSN = stmt.StartLSN
+		if f.startLSN < f.confirmed {
+			f.startLSN = f.confirmed
+		}
+
+		tableIDs, allTables, err := loadPublications(ctx, cfg, txn, dbDesc.GetID(), pubNames)
+		if err != nil {
+			return err
+		}
+		schemaNames, err := p.Descriptors().GetSchemasForDatabase(ctx, txn, dbDesc.GetID())
+		if err != nil {
+			return err
+		}
+		tables, err := p.Descriptors().GetAllTableDescriptorsInDatabase(ctx, txn, dbDesc.GetID())
+		if err != nil {
+			return err
+		}
+		var streamedIDs descpb.IDs
+		for _, table := range tables {
+			if !allTables && !tableIDs[table.GetID()] {
+				continue
+			}
+			if !table.IsPhysicalTable() || table.IsSequence() || table.IsTemporary() ||
+				table.Dropped() || table.Offline() {
+				continue
+			}
+			if err := checkReplicatedTable(table); err != nil {
+				return err
+			}
+			f.schemaNames[table.GetParentSchemaID()] = schemaNames[table.GetParentSchemaID()]
+			spans = append(spans, table.PrimaryIndexSpan(cfg.Codec))
+			streamedIDs = append(streamedIDs, table.GetID())
+		}
+
+		// Replace the protected timestamp record of the slot with one covering
+		// the tables of the feed.
+		if err := releaseReplicationSlot(ctx, cfg, txn, slot[2]); err != nil {
+			return err
+		}
+		f.ptsRecord, err = protectReplicationSlot(
+			ctx, cfg, txn, f.slot, dbDesc.GetID(), streamedIDs, f.confirmed,
+		)
+		if err != nil {
+			return err
+		}
+		record := tree.DNull
+		if f.ptsRecord != nil {
+			record = tree.NewDUuid(tree.DUuid{UUID: *f.ptsRecord})
+		}
+		_, err = cfg.InternalExecutor.ExecEx(
+			ctx, "protect-replication-slot", txn,
+			sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+			`UPDATE system.replication_slots SET protected_timestamp_record = $2 WHERE name = $1`,
+			f.slot, record,
+		)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	f.mu.resolved = f.startLSN
+	f.memMonitor = mon.NewMonitorInheritWithLimit(
+		"replication-feed", replicationFeedMemoryLimit.Get(&cfg.Settings.SV), cfg.RootMemoryMonitor,
+	)
+	f.memMonitor.Start(ctx, cfg.RootMemoryMonitor, mon.BoundAccount{})
+	f.mu.memAcc = f.memMonitor.MakeBoundAccount()
+	if len(spans) == 0 {
+		// Nothing to watch: the feed never produces any transaction.
+		return f, nil
+	}
+	f.rangeFeed, err = cfg.RangeFeedFactory.RangeFeed(
+		ctx, "logical-replication-"+f.slot, spans, f.startLSN.ResumeTimestamp(),
+		f.onValue,
+		rangefeed.WithDiff(),
+		rangefeed.WithOnFrontierAdvance(f.onFrontierAdvance),
+	)
+	if err != nil {
+		f.mu.memAcc.Close(ctx)
+		f.memMonitor.Stop(ctx)
+		return nil, err
+	}
+	return f, nil
+}
+
+// parsePGOutputOptions validates the options of the pgoutput plugin passed to
+// START_REPLICATION, and returns the names of the publications to stream.
+func parsePGOutputOptions(stmt *tree.StartReplication) ([]string, error) {
+	var pubNames []string
+	for _, o := range stmt.Options {
+		switch o.Key {
+		case "proto_version":
+			if o.Value != "1" {
+				return nil, pgerror.Newf(pgcode.FeatureNotSupported,
+					"client sent proto_version=%s but we only support protocol 1", o.Value)
+			}
+		case "publication_names":
+			for _, name := range strings.Split(o.Value, ",") {
+				name = strings.TrimSpace(name)
+				if len(name) >= 2 && name[0] == '"' && name[len(name)-1] == '"' {
+					name = strings.Replace(name[1:len(name)-1], `""`, `"`, -1)
+				} else {
+					name = strings.ToLower(name)
+				}
+				if name == "" {
+					return nil, pgerror.New(pgcode.InvalidName, "invalid publication_names syntax")
+				}
+				pubNames = append(pubNames, name)
+			}
+		case "binary", "streaming":
+			if b, err := tree.ParseDBool(o.Value); err != nil || bool(*b) {
+				return nil, unimplemented.Newf("pgoutput."+string(o.Key),
+					"pgoutput option %s is not supported", o.Key)
+			}
+		case "messages":
+			// No logical decoding messages are ever produced.
+		default:
+			return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+				"unrecognized pgoutput option: %s", o.Key)
+		}
+	}
+	if _, ok := stmt.Option("proto_version"); !ok {
+		return nil, pgerror.New(pgcode.InvalidParameterValue, "proto_version option missing")
+	}
+	if pubNames == nil {
+		return nil, pgerror.New(pgcode.InvalidParameterValue, "publication_names parameter missing")
+	}
+	return pubNames, nil
+}
+
+// loadPublications returns the IDs of the tables of the given publications of
+// a database, and whether one of them publishes all the tables.
+func loadPublications(
+	ctx context.Context, cfg *ExecutorConfig, txn *kv.Txn, dbID descpb.ID, names []string,
+) (tableIDs map[descpb.ID]bool, allTables bool, _ error) {
+	rows, err := cfg.InternalExecutor.QueryBufferedEx(
+		ctx, "get-publications", txn,
+		sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+		`SELECT name, all_tables, table_ids FROM system.publications WHERE database_id = $1`,
+		dbID,
+	)
+	if err != nil {
+		return nil, false, err
+	}
+	tableIDs = make(map[descpb.ID]bool)
+	for _, name := range names {
+		found := false
+		for _, row := range rows {
+			if string(tree.MustBeDString(row[0])) != name {
+				continue
+			}
+			found = true
+			allTables = allTables || bool(tree.MustBeDBool(row[1]))
+			for _, id := range tree.MustBeDArray(row[2]).Array {
+				tableIDs[descpb.ID(tree.MustBeDInt(id))] = true
+			}
+		}
+		if !found {
+			return nil, false, pgerror.Newf(pgcode.UndefinedObject,
+				"publication %q does not exist", name)
+		}
+	}
+	return tableIDs, allTables, nil
+}
+
+// checkReplicatedTable returns an error if the changes to the table cannot be
+// decoded by the feed.
+func checkReplicatedTable(table catalog.TableDescriptor) error {
+	if table.NumFamilies() > 1 {
+		return unimplemented.NewWithIssuef(28344,
+			"table %q has multiple column families, which logical replication does not support",
+			table.GetName())
+	}
+	if table.ContainsUserDefinedTypes() {
+		return unimplemented.Newf("logical replication user-defined types",
+			"table %q has columns of user-defined types, which logical replication does not support",
+			table.GetName())
+	}
+	return nil
+}
+
+// StartLSN returns the position after which the feed streams changes: the
+// highest of the position requested by the client and of the position it last
+// confirmed.
+func (f *ReplicationFeed) StartLSN() lsn.LSN {
+	return f.startLSN
+}
+
+// Next blocks until the next transaction is resolved, and returns it.
+func (f *ReplicationFeed) Next(ctx context.Context) (ReplicationTxn, error) {
+	for {
+		f.mu.Lock()
+		if err := f.mu.err; err != nil {
+			f.mu.Unlock()
+			return ReplicationTxn{}, err
+		}
+		if len(f.mu.txns) > 0 {
+			txn := f.mu.txns[0]
+			f.mu.txns[0] = ReplicationTxn{}
+			f.mu.txns = f.mu.txns[1:]
+			// The changes of the transaction are only referenced by the caller
+			// from now on.
+			f.mu.memAcc.Shrink(ctx, f.mu.txnsMemSize[0])
+			f.mu.txnsMemSize = f.mu.txnsMemSize[1:]
+			f.mu.Unlock()
+			return txn, nil
+		}
+		f.mu.Unlock()
+
+		select {
+		case <-f.notify:
+		case <-ctx.Done():
+			return ReplicationTxn{}, ctx.Err()
+		}
+	}
+}
+
+// ResolvedLSN returns the highest LSN up to which all the transactions have
+// been returned by Next.
+func (f *ReplicationFeed) ResolvedLSN() lsn.LSN {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.mu.txns) > 0 {
+		return f.mu.txns[0].LSN - 1
+	}
+	return f.mu.resolved
+}
+
+// ConfirmFlush records that the client has durably stored the changes up to
+// the given LSN. The position of the replication slot, and the timestamp of
+// its protected timestamp record, are persisted periodically, so that a new
+// feed resumes after it.
+func (f *ReplicationFeed) ConfirmFlush(ctx context.Context, l lsn.LSN) error {
+	l = f.confirmableLSN(l)
+	if l <= f.confirmed {
+		return nil
+	}
+	f.confirmed = l
+	if timeutil.Since(f.lastPersisted) < replicationSlotPersistInterval {
+		return nil
+	}
+	return f.persist(ctx)
+}
+
+// confirmableLSN returns the highest LSN up to which the client is known to
+// have stored all the changes, given the LSN it confirmed. The commit of a
+// transaction which shares its LSN with other transactions does not tell
+// whether the client also stored the others, so the position only moves past
+// the LSN once the client confirms a later one. The client may then receive
+// some of these transactions again if it resumes from the position of the
+// slot.
+func (f *ReplicationFeed) confirmableLSN(l lsn.LSN) lsn.LSN {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for len(f.mu.sharedLSNs) > 0 && f.mu.sharedLSNs[0].CommitLSN() < l {
+		f.mu.sharedLSNs = f.mu.sharedLSNs[1:]
+	}
+	if len(f.mu.sharedLSNs) > 0 && f.mu.sharedLSNs[0].CommitLSN() == l {
+		// Confirm the commit of the previous wall time.
+		return f.mu.sharedLSNs[0] - 1
+	}
+	return l
+}
+
+func (f *ReplicationFeed) persist(ctx context.Context) error {
+	if f.confirmed == f.persisted {
+		return nil
+	}
+	if err := f.cfg.DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		rowsAffected, err := f.cfg.InternalExecutor.ExecEx(
+			ctx, "update-replication-slot", txn,
+			sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+			`UPDATE system.replication_slots SET confirmed_flush_lsn = $2
+WHERE name = $1 AND confirmed_flush_lsn < $2`,
+			f.slot, int64(f.confirmed),
+		)
+		if err != nil || rowsAffected == 0 || f.ptsRecord == nil {
+			// Another feed of the slot may have persisted a later position.
+			return err
+		}
+		return f.cfg.ProtectedTimestampProvider.UpdateTimestamp(
+			ctx, txn, *f.ptsRecord, f.confirmed.ResumeTimestamp(),
+		)
+	}); err != nil {
+		return err
+	}
+	f.persisted = f.confirmed
+	f.lastPersisted = timeutil.Now()
+	return nil
+}
+
+// Close stops the feed and persists the last position confirmed by the
+// client.
+func (f *ReplicationFeed) Close(ctx context.Context) {
+	if f.rangeFeed != nil {
+		f.rangeFeed.Close()
+	}
+	f.mu.Lock()
+	f.mu.memAcc.Close(ctx)
+	f.mu.Unlock()
+	f.memMonitor.Stop(ctx)
+	if err := f.persist(ctx); err != nil {
+		log.Warningf(ctx, "failed to persist the position of replication slot %q: %v", f.slot, err)
+	}
+}
+
+func (f *ReplicationFeed) setErr(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.mu.err == nil {
+		f.mu.err = err
+	}
+	f.signal()
+}
+
+func (f *ReplicationFeed) signal() {
+	select {
+	case f.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (f *ReplicationFeed) onValue(ctx context.Context, value *roachpb.RangeFeedValue) {
+	if f.isResolved(value.Value.Timestamp) {
+		// The rangefeed may emit a value again after it restarted, for instance
+		// following a range split, once its transaction has been resolved.
+		return
+	}
+	change, err := f.decodeChange(ctx, value)
+	if err != nil {
+		f.setErr(err)
+		return
+	}
+	f.bufferEvent(ctx, replicationEvent{key: value.Key, ts: value.Value.Timestamp, change: change})
+}
+
+// isResolved returns whether the transactions committed at the given timestamp
+// have already been resolved.
+func (f *ReplicationFeed) isResolved(ts hlc.Timestamp) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return lsn.FromTimestamp(ts).CommitLSN() <= f.mu.resolved
+}
+
+// bufferEvent adds a decoded change to the pending changes, until the frontier
+// of the rangefeed resolves its transaction.
+func (f *ReplicationFeed) bufferEvent(ctx context.Context, ev replicationEvent) {
+	ev.memSize = replicationEventMemSize(&ev)
+	f.mu.Lock()
+	err := f.mu.memAcc.Grow(ctx, ev.memSize)
+	f.mu.Unlock()
+	if err != nil {
+		// The changes cannot be dropped without losing them, so the stream is
+		// ended. The client can resume it after the last position it confirmed.
+		f.setErr(errors.WithHintf(err,
+			"the client may not be consuming the stream fast enough, or a transaction is too "+
+				"large; consider increasing the %s cluster setting", replicationFeedMemoryLimit.Key()))
+		return
+	}
+	f.pending = append(f.pending, ev)
+}
+
+func (f *ReplicationFeed) onFrontierAdvance(ctx context.Context, frontier hlc.Timestamp) {
+	// Changes with the wall time of the frontier may still be emitted at a
+	// higher logical timestamp, so only the changes with a lower wall time are
+	// resolved. This ensures that all the transactions with a given LSN are
+	// resolved together.
+	sort.SliceStable(f.pending, func(i, j int) bool {
+		if !f.pending[i].ts.EqOrdering(f.pending[j].ts) {
+			return f.pending[i].ts.Less(f.pending[j].ts)
+		}
+		return f.pending[i].key.Compare(f.pending[j].key) < 0
+	})
+	var txns []ReplicationTxn
+	var txnsMemSize []int64
+	var sharedLSNs []lsn.LSN
+	var dupMemSize int64
+	var txnTS hlc.Timestamp
+	n := 0
+	for ; n < len(f.pending) && f.pending[n].ts.WallTime < frontier.WallTime; n++ {
+		ev := f.pending[n]
+		if n > 0 && ev.ts.EqOrdering(f.pending[n-1].ts) && ev.key.Equal(f.pending[n-1].key) {
+			// The rangefeed emitted the value more than once.
+			dupMemSize += ev.memSize
+			continue
+		}
+		// The changes of a transaction share its commit timestamp.
+		if len(txns) == 0 || !txnTS.EqOrdering(ev.ts) {
+			l := lsn.FromTimestamp(ev.ts)
+			if len(txns) > 0 && txns[len(txns)-1].LSN == l &&
+				(len(sharedLSNs) == 0 || sharedLSNs[len(sharedLSNs)-1] != l) {
+				sharedLSNs = append(sharedLSNs, l)
+			}
+			txns = append(txns, ReplicationTxn{LSN: l, CommitTime: ev.ts.GoTime()})
+			txnsMemSize = append(txnsMemSize, 0)
+			txnTS = ev.ts
+		}
+		txns[len(txns)-1].Changes = append(txns[len(txns)-1].Changes, ev.change)
+		txnsMemSize[len(txns)-1] += ev.memSize
+	}
+	f.pending = append(f.pending[:0], f.pending[n:]...)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.mu.memAcc.Shrink(ctx, dupMemSize)
+	f.mu.txns = append(f.mu.txns, txns...)
+	f.mu.txnsMemSize = append(f.mu.txnsMemSize, txnsMemSize...)
+	f.mu.sharedLSNs = append(f.mu.sharedLSNs, sharedLSNs...)
+	if resolved := lsn.FromTimestamp(frontier) - 1; resolved > f.mu.resolved {
+		f.mu.resolved = resolved
+	}
+	f.signal()
+}
+
+// decodeChange decodes a change to a row of a table from the KV emitted by the
+// rangefeed.
+func (f *ReplicationFeed) decodeChange(
+	ctx context.Context, value *roachpb.RangeFeedValue,
+) (ReplicationChange, error) {
+	ts := value.Value.Timestamp
+	table, rf, err := f.fetcherAt(ctx, value.Key, ts)
+	if err != nil {
+		return ReplicationChange{}, err
+	}
+	datums, deleted, err := f.decodeRow(ctx, rf, roachpb.KeyValue{Key: value.Key, Value: value.Value})
+	if err != nil {
+		return ReplicationChange{}, err
+	}
+	change := ReplicationChange{
+		Table:      table,
+		SchemaName: f.schemaNames[table.GetParentSchemaID()],
+		Kind:       ReplicationInsert,
+		Row:        datums,
+	}
+
+	if value.PrevValue.IsPresent() {
+		// The previous value of the row is interpreted with the version of the
+		// table which was current when it was written. It is only reported if
+		// it has the same columns as the new value.
+		prevTable, prevRF, err := f.fetcherAt(ctx, value.Key, ts.Prev())
+		if err != nil {
+			return ReplicationChange{}, err
+		}
+		prevDatums, _, err := f.decodeRow(ctx, prevRF, roachpb.KeyValue{Key: value.Key, Value: value.PrevValue})
+		if err != nil {
+			return ReplicationChange{}, err
+		}
+		change.Kind = ReplicationUpdate
+		if prevTable.GetVersion() == table.GetVersion() {
+			change.PrevRow = prevDatums
+		}
+	}
+	if deleted {
+		change.Kind = ReplicationDelete
+	}
+	return change, nil
+}
+
+// fetcherAt returns the version of the table the key belongs to at the given
+// timestamp, and a row.Fetcher to decode its rows.
+func (f *ReplicationFeed) fetcherAt(
+	ctx context.Context, key roachpb.Key, ts hlc.Timestamp,
+) (catalog.TableDescriptor, *row.Fetcher, error) {
+	key, err := f.cfg.Codec.StripTenantPrefix(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	_, tableID, _, err := rowenc.DecodePartialTableIDIndexID(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	desc, err := f.cfg.LeaseManager.Acquire(ctx, ts, tableID)
+	if err != nil {
+		return nil, nil, err
+	}
+	table := desc.Underlying().(catalog.TableDescriptor)
+	// Immediately release the lease, since we only need it for the exact
+	// timestamp requested.
+	desc.Release(ctx)
+	if err := checkReplicatedTable(table); err != nil {
+		return nil, nil, err
+	}
+
+	if cached, ok := f.fetchers[tableID]; ok && cached.version == table.GetVersion() {
+		return table, cached.rf, nil
+	}
+	var rf row.Fetcher
+	if err := rf.Init(
+		ctx,
+		f.cfg.Codec,
+		false, /* reverse */
+		descpb.ScanLockingStrength_FOR_NONE,
+		descpb.ScanLockingWaitPolicy_BLOCK,
+		0, /* lockTimeout */
+		&f.alloc,
+		nil, /* memMonitor */
+		row.FetcherTableArgs{
+			Desc:    table,
+			Index:   table.GetPrimaryIndex(),
+			Columns: table.PublicColumns(),
+		},
+	); err != nil {
+		return nil, nil, err
+	}
+	// Necessary because virtual columns are not populated.
+	rf.IgnoreUnexpectedNulls = true
+	f.fetchers[tableID] = replicationFetcher{version: table.GetVersion(), rf: &rf}
+	return table, &rf, nil
+}
+
+// decodeRow decodes the row made of a single KV, and returns whether the KV
+// is a deletion.
+func (f *ReplicationFeed) decodeRow(
+	ctx context.Context, rf *row.Fetcher, kv roachpb.KeyValue,
+) (tree.Datums, bool, error) {
+	f.kvFetcher.KVs = append(f.kvFetcher.KVs[:0], kv)
+	if err := rf.StartScanFrom(ctx, &f.kvFetcher, false /* traceKV */); err != nil {
+		return nil, false, err
+	}
+	datums, err := rf.NextRowDecoded(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	if datums == nil {
+		return nil, false, errors.AssertionFailedf("unexpected empty datums")
+	}
+	return append(tree.Datums(nil), datums...), rf.RowIsDeleted(), nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/lsn"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/stretchr/testify/require"
+)
+
+// TestReplicationFeedResolveTransactions checks that the changes emitted by the
+// rangefeed are grouped by the commit timestamp of their transaction, and that
+// the changes which are emitted more than once are only reported once.
+func TestReplicationFeedResolveTransactions(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	m := mon.NewUnlimitedMonitor(ctx, "test", mon.MemoryResource,
+		nil /* curCount */, nil /* maxHist */, math.MaxInt64, cluster.MakeTestingClusterSettings())
+	defer m.Stop(ctx)
+	f := &ReplicationFeed{notify: make(chan struct{}, 1)}
+	f.mu.memAcc = m.MakeBoundAccount()
+	defer f.mu.memAcc.Close(ctx)
+
+	// onValue is the part of ReplicationFeed.onValue that follows the decoding
+	// of the change.
+	onValue := func(key string, ts hlc.Timestamp) {
+		if f.isResolved(ts) {
+			return
+		}
+		f.bufferEvent(ctx, replicationEvent{
+			key:    roachpb.Key(key),
+			ts:     ts,
+			change: ReplicationChange{Row: tree.Datums{tree.NewDString(key)}},
+		})
+	}
+	rows := func(txn ReplicationTxn) []string {
+		var res []string
+		for _, c := range txn.Changes {
+			res = append(res, string(tree.MustBeDString(c.Row[0])))
+		}
+		return res
+	}
+
+	// Two transactions committed within the same nanosecond, the second one
+	// having one of its changes emitted twice.
+	onValue("b", hlc.Timestamp{WallTime: 10, Logical: 1})
+	onValue("a", hlc.Timestamp{WallTime: 10})
+	onValue("a", hlc.Timestamp{WallTime: 10, Logical: 1})
+	onValue("b", hlc.Timestamp{WallTime: 10, Logical: 1})
+	onValue("c", hlc.Timestamp{WallTime: 20})
+	f.onFrontierAdvance(ctx, hlc.Timestamp{WallTime: 20})
+
+	first, err := f.Next(ctx)
+	require.NoError(t, err)
+	second, err := f.Next(ctx)
+	require.NoError(t, err)
+	l := lsn.FromTimestamp(hlc.Timestamp{WallTime: 10})
+	require.Equal(t, l, first.LSN)
+	require.Equal(t, []string{"a"}, rows(first))
+	require.Equal(t, l, second.LSN)
+	require.Equal(t, []string{"a", "b"}, rows(second))
+	require.Equal(t, lsn.FromTimestamp(hlc.Timestamp{WallTime: 20})-1, f.ResolvedLSN())
+
+	// Only the change after the frontier is still accounted for.
+	require.Len(t, f.pending, 1)
+	require.Equal(t, f.pending[0].memSize, f.mu.memAcc.Used())
+
+	// Changes emitted again after their transaction was resolved are dropped.
+	onValue("b", hlc.Timestamp{WallTime: 10, Logical: 1})
+	require.Len(t, f.pending, 1)
+
+	// The commit of a transaction which shares its LSN with another one only
+	// confirms the changes of the previous wall time.
+	require.Equal(t, l-1, f.confirmableLSN(l.CommitLSN()))
+	later := lsn.FromTimestamp(hlc.Timestamp{WallTime: 20}).CommitLSN()
+	require.Equal(t, later, f.confirmableLSN(later))
+	require.Equal(t, l.CommitLSN(), f.confirmableLSN(l.CommitLSN()))
+}
@@ -0,0 +1,291 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/protectedts"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/protectedts/ptpb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/lsn"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/cockroachdb/errors"
+)
+
+// PGOutputPlugin is the name of the only logical decoding output plugin
+// supported by replication slots.
+const PGOutputPlugin = "pgoutput"
+
+// validateReplicationSlotName checks that the name of a replication slot only
+// contains lower case letters, numbers and underscores, like postgres does.
+func validateReplicationSlotName(name string) error {
+	if name == "" {
+		return pgerror.New(pgcode.InvalidName, "replication slot name cannot be empty")
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z') && !(r >= '0' && r <= '9') && r != '_' {
+			return pgerror.Newf(pgcode.InvalidName,
+				"replication slot name %q contains invalid character", name)
+		}
+	}
+	return nil
+}
+
+// replicationSlotPTSMetaType is the meta type of the protected timestamp
+// records of replication slots. The meta of a record is the name of its slot.
+const replicationSlotPTSMetaType = "replication_slot"
+
+// protectReplicationSlot writes a protected timestamp record which prevents
+// the garbage collection of the changes to the given tables of a database
+// after the given position of a replication slot, and returns its ID. The
+// record also protects system.descriptor, since the changes are decoded with
+// the version of their table at the time they were committed.
+//
+// Returns a nil ID, without protecting anything, for secondary tenants, which
+// do not support protected timestamps yet.
+func protectReplicationSlot(
+	ctx context.Context,
+	cfg *ExecutorConfig,
+	txn *kv.Txn,
+	slot string,
+	dbID descpb.ID,
+	tableIDs descpb.IDs,
+	pos lsn.LSN,
+) (*uuid.UUID, error) {
+	if !cfg.Codec.ForSystemTenant() {
+		return nil, nil
+	}
+	spans := make([]roachpb.Span, 0, len(tableIDs)+1)
+	addTableSpan := func(id descpb.ID) {
+		prefix := cfg.Codec.TablePrefix(uint32(id))
+		spans = append(spans, roachpb.Span{Key: prefix, EndKey: prefix.PrefixEnd()})
+	}
+	for _, id := range tableIDs {
+		addTableSpan(id)
+	}
+	addTableSpan(keys.DescriptorTableID)
+	id := uuid.MakeV4()
+	rec := &ptpb.Record{
+		ID:              id.GetBytesMut(),
+		Timestamp:       pos.ResumeTimestamp(),
+		Mode:            ptpb.PROTECT_AFTER,
+		MetaType:        replicationSlotPTSMetaType,
+		Meta:            []byte(slot),
+		DeprecatedSpans: spans,
+		Target:          ptpb.MakeSchemaObjectsTarget(descpb.IDs{dbID, keys.DescriptorTableID}),
+	}
+	if err := cfg.ProtectedTimestampProvider.Protect(ctx, txn, rec); err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// releaseReplicationSlot releases the protected timestamp record of a
+// replication slot, as stored in system.replication_slots, if it has one.
+func releaseReplicationSlot(
+	ctx context.Context, cfg *ExecutorConfig, txn *kv.Txn, record tree.Datum,
+) error {
+	if record == tree.DNull {
+		return nil
+	}
+	err := cfg.ProtectedTimestampProvider.Release(ctx, txn, record.(*tree.DUuid).UUID)
+	if errors.Is(err, protectedts.ErrNotExists) {
+		// The record was released by an operator.
+		return nil
+	}
+	return err
+}
+
+var identifySystemColumns = colinfo.ResultColumns{
+	{Name: "systemid", Typ: types.String},
+	{Name: "timeline", Typ: types.Int4},
+	{Name: "xlogpos", Typ: types.String},
+	{Name: "dbname", Typ: types.String},
+}
+
+// IdentifySystem returns the identity of the cluster and the current position
+// in the stream of changes.
+// Privileges: None.
+func (p *planner) IdentifySystem(ctx context.Context, n *tree.IdentifySystem) (planNode, error) {
+	return &delayedNode{
+		name:    n.String(),
+		columns: identifySystemColumns,
+		constructor: func(ctx context.Context, p *planner) (planNode, error) {
+			dbName := tree.DNull
+			if db := p.CurrentDatabase(); db != "" {
+				dbName = tree.NewDString(db)
+			}
+			v := p.newContainerValuesNode(identifySystemColumns, 0)
+			if _, err := v.rows.AddRow(ctx, tree.Datums{
+				tree.NewDString(p.ExecCfg().ClusterID().String()),
+				tree.NewDInt(1),
+				tree.NewDString(lsn.FromTimestamp(p.ExecCfg().Clock.Now()).String()),
+				dbName,
+			}); err != nil {
+				v.rows.Close(ctx)
+				return nil, err
+			}
+			return v, nil
+		},
+	}, nil
+}
+
+var createReplicationSlotColumns = colinfo.ResultColumns{
+	{Name: "slot_name", Typ: types.String},
+	{Name: "consistent_point", Typ: types.String},
+	{Name: "snapshot_name", Typ: types.String},
+	{Name: "output_plugin", Typ: types.String},
+}
+
+// CreateReplicationSlot creates a logical replication slot for the current
+// database. The slot starts at the timestamp of the transaction creating it.
+// Privileges: admin.
+//   notes: postgres requires the REPLICATION attribute.
+func (p *planner) CreateReplicationSlot(
+	ctx context.Context, n *tree.CreateReplicationSlot,
+) (planNode, error) {
+	if err := checkPublicationsSupported(ctx, p); err != nil {
+		return nil, err
+	}
+	if err := p.RequireAdminRole(ctx, "create replication slots"); err != nil {
+		return nil, err
+	}
+	if err := validateReplicationSlotName(string(n.Name)); err != nil {
+		return nil, err
+	}
+	if n.Plugin != PGOutputPlugin {
+		return nil, pgerror.Newf(pgcode.UndefinedObject,
+			"output plugin %q is not supported", n.Plugin)
+	}
+	if p.CurrentDatabase() == "" {
+		return nil, pgerror.New(pgcode.ObjectNotInPrerequisiteState,
+			"logical replication slots require a database")
+	}
+
+	return &delayedNode{
+		name:    n.String(),
+		columns: createReplicationSlotColumns,
+		constructor: func(ctx context.Context, p *planner) (planNode, error) {
+			dbDesc, err := p.Descriptors().GetImmutableDatabaseByName(ctx, p.txn,
+				p.CurrentDatabase(), tree.DatabaseLookupFlags{Required: true})
+			if err != nil {
+				return nil, err
+			}
+			consistentPoint := lsn.FromTimestamp(p.txn.ReadTimestamp()).CommitLSN()
+			rowsAffected, err := p.ExecCfg().InternalExecutor.ExecEx(
+				ctx, "create-replication-slot", p.txn,
+				sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+				`INSERT INTO system.replication_slots (name, database_id, plugin, confirmed_flush_lsn)
+VALUES ($1, $2, $3, $4) ON CONFLICT (name) DO NOTHING`,
+				n.Name, dbDesc.GetID(), n.Plugin, int64(consistentPoint),
+			)
+			if err != nil {
+				return nil, err
+			}
+			if rowsAffected == 0 {
+				return nil, pgerror.Newf(pgcode.DuplicateObject,
+					"replication slot %q already exists", n.Name)
+			}
+
+			// Until the slot is used, the tables which will be streamed are not
+			// known, so all the tables of the database are protected.
+			tables, err := p.Descriptors().GetAllTableDescriptorsInDatabase(ctx, p.txn, dbDesc.GetID())
+			if err != nil {
+				return nil, err
+			}
+			var tableIDs descpb.IDs
+			for _, table := range tables {
+				if table.IsPhysicalTable() && !table.IsSequence() && !table.Dropped() {
+					tableIDs = append(tableIDs, table.GetID())
+				}
+			}
+			record, err := protectReplicationSlot(
+				ctx, p.ExecCfg(), p.txn, string(n.Name), dbDesc.GetID(), tableIDs, consistentPoint,
+			)
+			if err != nil {
+				return nil, err
+			}
+			if record != nil {
+				if _, err := p.ExecCfg().InternalExecutor.ExecEx(
+					ctx, "protect-replication-slot", p.txn,
+					sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+					`UPDATE system.replication_slots SET protected_timestamp_record = $2 WHERE name = $1`,
+					n.Name, tree.NewDUuid(tree.DUuid{UUID: *record}),
+				); err != nil {
+					return nil, err
+				}
+			}
+
+			v := p.newContainerValuesNode(createReplicationSlotColumns, 0)
+			if _, err := v.rows.AddRow(ctx, tree.Datums{
+				tree.NewDString(string(n.Name)),
+				tree.NewDString(consistentPoint.String()),
+				tree.DNull,
+				tree.NewDString(string(n.Plugin)),
+			}); err != nil {
+				v.rows.Close(ctx)
+				return nil, err
+			}
+			return v, nil
+		},
+	}, nil
+}
+
+type dropReplicationSlotNode struct {
+	n *tree.DropReplicationSlot
+}
+
+// DropReplicationSlot drops a replication slot.
+// Privileges: admin.
+//   notes: postgres requires the REPLICATION attribute.
+func (p *planner) DropReplicationSlot(
+	ctx context.Context, n *tree.DropReplicationSlot,
+) (planNode, error) {
+	if err := checkPublicationsSupported(ctx, p); err != nil {
+		return nil, err
+	}
+	if err := p.RequireAdminRole(ctx, "drop replication slots"); err != nil {
+		return nil, err
+	}
+	return &dropReplicationSlotNode{n: n}, nil
+}
+
+func (n *dropReplicationSlotNode) startExec(params runParams) error {
+	row, err := params.ExecCfg().InternalExecutor.QueryRowEx(
+		params.ctx, "drop-replication-slot", params.p.txn,
+		sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+		`DELETE FROM system.replication_slots WHERE name = $1 RETURNING protected_timestamp_record`,
+		n.n.Name,
+	)
+	if err != nil {
+		return err
+	}
+	if row == nil {
+		return pgerror.Newf(pgcode.UndefinedObject,
+			"replication slot %q does not exist", n.n.Name)
+	}
+	return releaseReplicationSlot(params.ctx, params.ExecCfg(), params.p.txn, row[0])
+}
+
+func (n *dropReplicationSlotNode) Next(runParams) (bool, error) { return false, nil }
+func (n *dropReplicationSlotNode) Values() tree.Datums          { return tree.Datums{} }
+func (n *dropReplicationSlotNode) Close(context.Context)        {}
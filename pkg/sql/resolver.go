@@ -582,6 +582,26 @@ func getDescriptorsFromTargetListForPrivilegeChange(
 		return descs, nil
 	}
 
+	if targets.Functions != nil {
+		if len(targets.Functions) == 0 {
+			return nil, errNoFunction
+		}
+		descs := make([]catalog.Descriptor, 0, len(targets.Functions))
+		for i := range targets.Functions {
+			fnObj := &targets.Functions[i]
+			descriptor, err := p.resolveMutableFunction(ctx, fnObj)
+			if err != nil {
+				return nil, err
+			}
+			if descriptor == nil {
+				return nil, pgerror.Newf(pgcode.UndefinedFunction,
+					"function %s does not exist", tree.AsString(fnObj))
+			}
+			descs = append(descs, descriptor)
+		}
+		return descs, nil
+	}
+
 	if targets.Schemas != nil {
 		if len(targets.Schemas) == 0 {
 			return nil, errNoSchema
@@ -18,6 +18,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/inverted"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/invertedexpr"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/invertedidx"
 	"github.com/cockroachdb/cockroach/pkg/sql/rowcontainer"
@@ -131,10 +132,10 @@ type invertedJoiner struct {
 
 	// State variables for each batch of input rows.
 	inputRows       rowenc.EncDatumRows
-	batchedExprEval batchedInvertedExprEvaluator
+	batchedExprEval inverted.BatchedExprEvaluator
 	// The row indexes that are the result of the inverted expression evaluation
 	// of the join. These will be further filtered using the onExpr.
-	joinedRowIdx [][]KeyIndex
+	joinedRowIdx [][]inverted.KeyIndex
 
 	// The container for the index rows retrieved from the index. For evaluating
 	// each inverted expression, which involved set unions and intersections, it
@@ -301,7 +302,7 @@ func newInvertedJoiner(
 	}
 	ij.canPreFilter = ij.datumsToInvertedExpr.CanPreFilter()
 	if ij.canPreFilter {
-		ij.batchedExprEval.filterer = ij.datumsToInvertedExpr
+		ij.batchedExprEval.Filterer = ij.datumsToInvertedExpr
 	}
 
 	// In general we need all the columns in the index to compute the set
@@ -368,7 +369,7 @@ func (ij *invertedJoiner) Next() (rowenc.EncDatumRow, *execinfrapb.ProducerMetad
 	// The join is implemented as follows:
 	// - Read the input rows in batches.
 	// - For each batch, map the rows to SpanExpressionProtos and initialize
-	//   a batchedInvertedExprEvaluator. Use that evaluator to generate spans
+	//   an inverted.BatchedExprEvaluator. Use that evaluator to generate spans
 	//   to read from the inverted index.
 	// - Retrieve the index rows and add the primary keys in these rows to the
 	//   row container, that de-duplicates, and pass the de-duplicated keys to
@@ -435,14 +436,14 @@ func (ij *invertedJoiner) readInput() (invertedJoinerState, *execinfrapb.Produce
 			// One of the input columns was NULL, resulting in a nil expression.
 			// The nil serves as a marker that will result in an empty set as the
 			// evaluation result.
-			ij.batchedExprEval.exprs = append(ij.batchedExprEval.exprs, nil)
+			ij.batchedExprEval.Exprs = append(ij.batchedExprEval.Exprs, nil)
 			if ij.canPreFilter {
-				ij.batchedExprEval.preFilterState = append(ij.batchedExprEval.preFilterState, nil)
+				ij.batchedExprEval.PreFilterState = append(ij.batchedExprEval.PreFilterState, nil)
 			}
 		} else {
-			ij.batchedExprEval.exprs = append(ij.batchedExprEval.exprs, expr)
+			ij.batchedExprEval.Exprs = append(ij.batchedExprEval.Exprs, expr)
 			if ij.canPreFilter {
-				ij.batchedExprEval.preFilterState = append(ij.batchedExprEval.preFilterState, preFilterState)
+				ij.batchedExprEval.PreFilterState = append(ij.batchedExprEval.PreFilterState, preFilterState)
 			}
 		}
 		if len(ij.prefixEqualityCols) > 0 {
@@ -450,7 +451,7 @@ func (ij *invertedJoiner) readInput() (invertedJoinerState, *execinfrapb.Produce
 				// One of the input columns was NULL, resulting in a nil expression.
 				// The join type will emit no row since the evaluation result will be
 				// an empty set, so don't bother creating a prefix key span.
-				ij.batchedExprEval.nonInvertedPrefixes = append(ij.batchedExprEval.nonInvertedPrefixes, roachpb.Key{})
+				ij.batchedExprEval.NonInvertedPrefixes = append(ij.batchedExprEval.NonInvertedPrefixes, roachpb.Key{})
 			} else {
 				for prefixIdx, colIdx := range ij.prefixEqualityCols {
 					ij.indexRow[prefixIdx] = row[colIdx]
@@ -471,7 +472,7 @@ func (ij *invertedJoiner) readInput() (invertedJoinerState, *execinfrapb.Produce
 					ij.MoveToDraining(err)
 					return ijStateUnknown, ij.DrainHelper()
 				}
-				ij.batchedExprEval.nonInvertedPrefixes = append(ij.batchedExprEval.nonInvertedPrefixes, prefixKey)
+				ij.batchedExprEval.NonInvertedPrefixes = append(ij.batchedExprEval.NonInvertedPrefixes, prefixKey)
 			}
 		}
 	}
@@ -484,7 +485,7 @@ func (ij *invertedJoiner) readInput() (invertedJoinerState, *execinfrapb.Produce
 	}
 	log.VEventf(ij.Ctx, 1, "read %d input rows", len(ij.inputRows))
 
-	spans, err := ij.batchedExprEval.init()
+	spans, err := ij.batchedExprEval.Init()
 	if err != nil {
 		ij.MoveToDraining(err)
 		return ijStateUnknown, ij.DrainHelper()
@@ -571,7 +572,7 @@ func (ij *invertedJoiner) performScan() (invertedJoinerState, *execinfrapb.Produ
 			// rowenc.appendEncDatumsToKey.
 			encFullVal = append(prefixKey, encInvertedVal...)
 		}
-		shouldAdd, err := ij.batchedExprEval.prepareAddIndexRow(encInvertedVal, encFullVal)
+		shouldAdd, err := ij.batchedExprEval.PrepareAddIndexRow(encInvertedVal, encFullVal)
 		if err != nil {
 			ij.MoveToDraining(err)
 			return ijStateUnknown, ij.DrainHelper()
@@ -582,13 +583,13 @@ func (ij *invertedJoiner) performScan() (invertedJoinerState, *execinfrapb.Produ
 				ij.MoveToDraining(err)
 				return ijStateUnknown, ij.DrainHelper()
 			}
-			if err = ij.batchedExprEval.addIndexRow(rowIdx); err != nil {
+			if err = ij.batchedExprEval.AddIndexRow(rowIdx); err != nil {
 				ij.MoveToDraining(err)
 				return ijStateUnknown, ij.DrainHelper()
 			}
 		}
 	}
-	ij.joinedRowIdx = ij.batchedExprEval.evaluate()
+	ij.joinedRowIdx = ij.batchedExprEval.Evaluate()
 	ij.indexRows.SetupForRead(ij.Ctx, ij.joinedRowIdx)
 	log.VEventf(ij.Ctx, 1, "done evaluating expressions")
 
@@ -610,7 +611,7 @@ func (ij *invertedJoiner) emitRow() (
 		log.VEventf(ij.Ctx, 1, "done emitting rows")
 		// Ready for another input batch. Reset state.
 		ij.inputRows = ij.inputRows[:0]
-		ij.batchedExprEval.reset()
+		ij.batchedExprEval.Reset()
 		ij.joinedRowIdx = nil
 		ij.emitCursor.outputRowIdx = 0
 		ij.emitCursor.inputRowIdx = 0
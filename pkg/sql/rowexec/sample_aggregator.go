@@ -101,9 +101,6 @@ func newSampleAggregator(
 		if s.GenerateHistogram && s.HistogramMaxBuckets == 0 {
 			return nil, errors.Errorf("histogram max buckets not specified")
 		}
-		if s.GenerateHistogram && len(s.Columns) != 1 {
-			return nil, errors.Errorf("histograms require one column")
-		}
 	}
 
 	ctx := flowCtx.EvalCtx.Ctx()
@@ -142,7 +139,9 @@ func newSampleAggregator(
 			numRows:  0,
 		}
 		if spec.Sketches[i].GenerateHistogram {
-			sampleCols.Add(int(spec.Sketches[i].Columns[0]))
+			for _, col := range spec.Sketches[i].Columns {
+				sampleCols.Add(int(col))
+			}
 		}
 	}
 
@@ -434,7 +433,25 @@ func (s *sampleAggregator) writeResults(ctx context.Context) error {
 	if err := s.FlowCtx.Cfg.DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
 		for _, si := range s.sketches {
 			var histogram *stats.HistogramData
-			if si.spec.GenerateHistogram && len(s.sr.Get()) != 0 {
+			if si.spec.GenerateHistogram && len(s.sr.Get()) != 0 && len(si.spec.Columns) > 1 {
+				colIdxs := make([]int, len(si.spec.Columns))
+				for i, c := range si.spec.Columns {
+					colIdxs[i] = int(c)
+				}
+				h, err := s.generateMultiColHistogram(
+					ctx,
+					s.EvalCtx,
+					&s.sr,
+					colIdxs,
+					si.numRows,
+					s.getDistinctCount(&si, false /* includeNulls */),
+					int(si.spec.HistogramMaxBuckets),
+				)
+				if err != nil {
+					return err
+				}
+				histogram = &h
+			} else if si.spec.GenerateHistogram && len(s.sr.Get()) != 0 {
 				colIdx := int(si.spec.Columns[0])
 				typ := s.inTypes[colIdx]
 
@@ -490,7 +507,17 @@ func (s *sampleAggregator) writeResults(ctx context.Context) error {
 			}
 
 			// Delete old stats that have been superseded.
-			if err := stats.DeleteOldStatsForColumns(
+			if s.spec.PartialPredicate != "" {
+				if err := stats.DeleteOldPartialStatsForColumns(
+					ctx,
+					s.FlowCtx.Cfg.Executor,
+					txn,
+					s.tableID,
+					columnIDs,
+				); err != nil {
+					return err
+				}
+			} else if err := stats.DeleteOldStatsForColumns(
 				ctx,
 				s.FlowCtx.Cfg.Executor,
 				txn,
@@ -513,7 +540,10 @@ func (s *sampleAggregator) writeResults(ctx context.Context) error {
 				s.getDistinctCount(&si, true /* includeNulls */),
 				si.numNulls,
 				s.getAvgSize(&si),
-				histogram); err != nil {
+				histogram,
+				s.spec.PartialPredicate,
+				s.spec.FullStatisticID,
+			); err != nil {
 				return err
 			}
 
@@ -590,6 +620,46 @@ func (s *sampleAggregator) generateHistogram(
 	return stats.EquiDepthHistogram(evalCtx, colType, values, numRows, distinctCount, maxBuckets)
 }
 
+// generateMultiColHistogram returns a histogram on a given set of columns from
+// a set of samples. The histogram is built on the concatenated key encodings of
+// the values of the columns (see stats.SampleReservoir.GetNonNullKeys), and
+// excludes the rows that have a NULL value on any of the columns.
+// numRows is the total number of rows from which values were sampled.
+func (s *sampleAggregator) generateMultiColHistogram(
+	ctx context.Context,
+	evalCtx *tree.EvalContext,
+	sr *stats.SampleReservoir,
+	colIdxs []int,
+	numRows int64,
+	distinctCount int64,
+	maxBuckets int,
+) (stats.HistogramData, error) {
+	prevCapacity := sr.Cap()
+	values, err := sr.GetNonNullKeys(ctx, &s.tempMemAcc, colIdxs)
+	if err != nil {
+		return stats.HistogramData{}, err
+	}
+	if sr.Cap() != prevCapacity {
+		log.Infof(
+			ctx, "histogram samples reduced from %d to %d due to excessive memory utilization",
+			prevCapacity, sr.Cap(),
+		)
+	}
+	if len(values) == 0 {
+		return stats.HistogramData{ColumnType: types.Bytes}, nil
+	}
+	// Estimate the number of rows without NULL values from the fraction of
+	// samples without NULL values. The sketch only counts the rows in which all
+	// the columns are NULL.
+	numNonNullRows := numRows * int64(len(values)) / int64(len(sr.Get()))
+	if distinctCount > numNonNullRows {
+		distinctCount = numNonNullRows
+	}
+	return stats.EquiDepthHistogram(
+		evalCtx, types.Bytes, values, numNonNullRows, distinctCount, maxBuckets,
+	)
+}
+
 var _ execinfra.DoesNotUseTxn = &sampleAggregator{}
 
 // DoesNotUseTxn implements the DoesNotUseTxn interface.
@@ -0,0 +1,258 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package scheduledsql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/scheduledjobs/schedulebase"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/errors"
+	pbtypes "github.com/gogo/protobuf/types"
+)
+
+const scheduleSQLOp = "CREATE SCHEDULE FOR SQL"
+
+// scheduledSQLHeader is the header for "CREATE SCHEDULE FOR SQL..." statements
+// results.
+var scheduledSQLHeader = colinfo.ResultColumns{
+	{Name: "schedule_id", Typ: types.Int},
+	{Name: "label", Typ: types.String},
+	{Name: "status", Typ: types.String},
+	{Name: "first_run", Typ: types.TimestampTZ},
+	{Name: "schedule", Typ: types.String},
+	{Name: "statement", Typ: types.String},
+}
+
+// scheduledSQLEval is a helper struct containing the evaluated components of
+// a CREATE SCHEDULE FOR SQL statement.
+type scheduledSQLEval struct {
+	*tree.ScheduledSQL
+
+	scheduleLabel func() (string, error)
+	recurrence    func() (string, error)
+	statement     func() (string, error)
+	scheduleOpts  func() (map[string]string, error)
+}
+
+func makeScheduledSQLEval(
+	ctx context.Context, p sql.PlanHookState, schedule *tree.ScheduledSQL,
+) (*scheduledSQLEval, error) {
+	eval := &scheduledSQLEval{ScheduledSQL: schedule}
+	var err error
+
+	if schedule.ScheduleLabelSpec.Label != nil {
+		eval.scheduleLabel, err = p.TypeAsString(ctx, schedule.ScheduleLabelSpec.Label, scheduleSQLOp)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if schedule.Recurrence == nil {
+		// Sanity check: recurrence must be specified.
+		return nil, errors.New("RECURRING clause required")
+	}
+	eval.recurrence, err = p.TypeAsString(ctx, schedule.Recurrence, scheduleSQLOp)
+	if err != nil {
+		return nil, err
+	}
+
+	eval.statement, err = p.TypeAsString(ctx, schedule.Statement, scheduleSQLOp)
+	if err != nil {
+		return nil, err
+	}
+
+	eval.scheduleOpts, err = p.TypeAsStringOpts(
+		ctx, schedule.ScheduleOptions, schedulebase.CommonScheduleOptionExpectValues)
+	if err != nil {
+		return nil, err
+	}
+	return eval, nil
+}
+
+// validateScheduledStatement checks that stmt is a single SQL statement which
+// can be executed by a schedule.
+func validateScheduledStatement(stmt string) error {
+	stmts, err := parser.Parse(stmt)
+	if err != nil {
+		return errors.Wrap(err, "parsing scheduled statement")
+	}
+	if len(stmts) != 1 {
+		return pgerror.Newf(pgcode.InvalidParameterValue,
+			"%s requires exactly one statement, found %d", scheduleSQLOp, len(stmts))
+	}
+	switch stmts[0].AST.(type) {
+	case *tree.BeginTransaction, *tree.CommitTransaction, *tree.RollbackTransaction,
+		*tree.Savepoint, *tree.ReleaseSavepoint, *tree.RollbackToSavepoint:
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"transaction control statements cannot be scheduled")
+	}
+	return nil
+}
+
+// doCreateSQLSchedule is a plan hook implementation responsible for creating
+// a SQL statement schedule.
+func doCreateSQLSchedule(
+	ctx context.Context, p sql.PlanHookState, eval *scheduledSQLEval, resultsCh chan<- tree.Datums,
+) error {
+	if err := p.RequireAdminRole(ctx, scheduleSQLOp); err != nil {
+		return err
+	}
+
+	if !p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.ScheduledSQL) {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"%s requires all nodes to be upgraded to %s",
+			scheduleSQLOp, clusterversion.ByKey(clusterversion.ScheduledSQL))
+	}
+
+	if eval.ScheduleLabelSpec.IfNotExists && eval.scheduleLabel != nil {
+		scheduleLabel, err := eval.scheduleLabel()
+		if err != nil {
+			return err
+		}
+
+		exists, err := schedulebase.CheckScheduleAlreadyExists(ctx, p, scheduleLabel)
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			p.BufferClientNotice(ctx,
+				pgnotice.Newf("schedule %q already exists, skipping", scheduleLabel),
+			)
+			return nil
+		}
+	}
+
+	env := schedulebase.JobSchedulerEnv(p.ExecCfg())
+
+	recurrence, err := schedulebase.ComputeScheduleRecurrence(env.Now(), eval.recurrence)
+	if err != nil {
+		return err
+	}
+
+	statement, err := eval.statement()
+	if err != nil {
+		return err
+	}
+	if err := validateScheduledStatement(statement); err != nil {
+		return err
+	}
+
+	var scheduleLabel string
+	if eval.scheduleLabel != nil {
+		label, err := eval.scheduleLabel()
+		if err != nil {
+			return err
+		}
+		scheduleLabel = label
+	} else {
+		scheduleLabel = fmt.Sprintf("SQL %d", env.Now().Unix())
+	}
+
+	scheduleOptions, err := eval.scheduleOpts()
+	if err != nil {
+		return err
+	}
+
+	evalCtx := &p.ExtendedEvalContext().EvalContext
+	firstRun, err := schedulebase.ScheduleFirstRun(evalCtx, scheduleOptions)
+	if err != nil {
+		return err
+	}
+
+	details, err := schedulebase.MakeScheduleDetails(scheduleOptions)
+	if err != nil {
+		return err
+	}
+
+	sj := jobs.NewScheduledJob(env)
+	sj.SetScheduleLabel(scheduleLabel)
+	sj.SetOwner(p.User())
+	if err := sj.SetSchedule(recurrence.Cron); err != nil {
+		return err
+	}
+	sj.SetScheduleDetails(details)
+	if firstRun != nil {
+		sj.SetNextRun(*firstRun)
+	}
+
+	args := &jobspb.ScheduledSQLExecutionArgs{
+		Statement: statement,
+		Database:  p.CurrentDatabase(),
+	}
+	any, err := pbtypes.MarshalAny(args)
+	if err != nil {
+		return err
+	}
+	sj.SetExecutionDetails(
+		tree.ScheduledSQLExecutor.InternalName(), jobspb.ExecutionArguments{Args: any},
+	)
+
+	if err := sj.Create(ctx, p.ExecCfg().InternalExecutor, p.ExtendedEvalContext().Txn); err != nil {
+		return err
+	}
+
+	nextRun, err := tree.MakeDTimestampTZ(sj.NextRun(), time.Microsecond)
+	if err != nil {
+		return err
+	}
+	resultsCh <- tree.Datums{
+		tree.NewDInt(tree.DInt(sj.ScheduleID())),
+		tree.NewDString(sj.ScheduleLabel()),
+		tree.NewDString("ACTIVE"),
+		nextRun,
+		tree.NewDString(sj.ScheduleExpr()),
+		tree.NewDString(statement),
+	}
+	telemetry.Count("scheduled-sql.create.success")
+	return nil
+}
+
+func createSQLScheduleHook(
+	ctx context.Context, stmt tree.Statement, p sql.PlanHookState,
+) (sql.PlanHookRowFn, colinfo.ResultColumns, []sql.PlanNode, bool, error) {
+	schedule, ok := stmt.(*tree.ScheduledSQL)
+	if !ok {
+		return nil, nil, nil, false, nil
+	}
+
+	eval, err := makeScheduledSQLEval(ctx, p, schedule)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
+		if err := doCreateSQLSchedule(ctx, p, eval, resultsCh); err != nil {
+			telemetry.Count("scheduled-sql.create.failed")
+			return err
+		}
+		return nil
+	}
+	return fn, scheduledSQLHeader, nil, false, nil
+}
+
+func init() {
+	sql.AddPlanHook(createSQLScheduleHook)
+}
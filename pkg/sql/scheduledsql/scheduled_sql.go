@@ -0,0 +1,214 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package scheduledsql implements schedules which periodically execute a SQL
+// statement, created with CREATE SCHEDULE FOR SQL. Each execution of such a
+// schedule runs as a SCHEDULED SQL job, so that its outcome is recorded in
+// system.jobs.
+package scheduledsql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/scheduledjobs"
+	"github.com/cockroachdb/cockroach/pkg/scheduledjobs/schedulebase"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+	"github.com/cockroachdb/errors"
+	pbtypes "github.com/gogo/protobuf/types"
+)
+
+type scheduledSQLMetrics struct {
+	*jobs.ExecutorMetrics
+}
+
+var _ metric.Struct = &scheduledSQLMetrics{}
+
+// MetricStruct implements metric.Struct interface.
+func (m *scheduledSQLMetrics) MetricStruct() {}
+
+// scheduledSQLExecutor is executed by the scheduled job subsystem to launch
+// a SCHEDULED SQL job, which runs the statement of the schedule.
+type scheduledSQLExecutor struct {
+	metrics scheduledSQLMetrics
+}
+
+var _ jobs.ScheduledJobExecutor = &scheduledSQLExecutor{}
+
+// ExecuteJob implements the jobs.ScheduledJobExecutor interface.
+func (e *scheduledSQLExecutor) ExecuteJob(
+	ctx context.Context,
+	cfg *scheduledjobs.JobExecutionConfig,
+	env scheduledjobs.JobSchedulerEnv,
+	sj *jobs.ScheduledJob,
+	txn *kv.Txn,
+) error {
+	if err := e.createScheduledSQLJob(ctx, cfg, sj, txn); err != nil {
+		e.metrics.NumFailed.Inc(1)
+		return err
+	}
+	e.metrics.NumStarted.Inc(1)
+	return nil
+}
+
+func (e *scheduledSQLExecutor) createScheduledSQLJob(
+	ctx context.Context, cfg *scheduledjobs.JobExecutionConfig, sj *jobs.ScheduledJob, txn *kv.Txn,
+) error {
+	args, err := extractExecutionArgs(sj)
+	if err != nil {
+		return err
+	}
+
+	p, cleanup := cfg.PlanHookMaker("invoke-scheduled-sql", txn, sj.Owner())
+	defer cleanup()
+	registry := p.(sql.PlanHookState).ExecCfg().JobRegistry
+
+	redacted := redactStatement(args.Statement)
+	record := jobs.Record{
+		Description: fmt.Sprintf("scheduled SQL %d: %s", sj.ScheduleID(), redacted),
+		Statements:  []string{redacted},
+		Username:    sj.Owner(),
+		Details: jobspb.ScheduledSQLDetails{
+			Statement: args.Statement,
+			Database:  args.Database,
+		},
+		Progress: jobspb.ScheduledSQLProgress{},
+		CreatedBy: &jobs.CreatedByInfo{
+			Name: jobs.CreatedByScheduledJobs,
+			ID:   sj.ScheduleID(),
+		},
+	}
+	jobID := registry.MakeJobID()
+	if _, err := registry.CreateAdoptableJobWithTxn(ctx, record, jobID, txn); err != nil {
+		return err
+	}
+	log.Infof(ctx, "scheduled SQL %d started job %d", sj.ScheduleID(), jobID)
+	return nil
+}
+
+// NotifyJobTermination implements the jobs.ScheduledJobExecutor interface.
+func (e *scheduledSQLExecutor) NotifyJobTermination(
+	ctx context.Context,
+	jobID jobspb.JobID,
+	jobStatus jobs.Status,
+	details jobspb.Details,
+	env scheduledjobs.JobSchedulerEnv,
+	sj *jobs.ScheduledJob,
+	ex sqlutil.InternalExecutor,
+	txn *kv.Txn,
+) error {
+	if jobStatus == jobs.StatusSucceeded {
+		e.metrics.NumSucceeded.Inc(1)
+		sj.SetScheduleStatus(string(jobStatus))
+		return nil
+	}
+
+	e.metrics.NumFailed.Inc(1)
+	jobs.DefaultHandleFailedRun(sj, "scheduled SQL job %d failed with status %s", jobID, jobStatus)
+	return nil
+}
+
+// Metrics implements the jobs.ScheduledJobExecutor interface.
+func (e *scheduledSQLExecutor) Metrics() metric.Struct {
+	return &e.metrics
+}
+
+// GetCreateScheduleStatement implements the jobs.ScheduledJobExecutor interface.
+func (e *scheduledSQLExecutor) GetCreateScheduleStatement(
+	ctx context.Context,
+	env scheduledjobs.JobSchedulerEnv,
+	txn *kv.Txn,
+	sj *jobs.ScheduledJob,
+	ex sqlutil.InternalExecutor,
+) (string, error) {
+	args, err := extractExecutionArgs(sj)
+	if err != nil {
+		return "", err
+	}
+
+	firstRunTime := sj.ScheduledRunTime()
+	if firstRunTime.IsZero() {
+		firstRunTime = env.Now()
+	}
+	firstRun, err := tree.MakeDTimestampTZ(firstRunTime, time.Microsecond)
+	if err != nil {
+		return "", err
+	}
+
+	scheduleOptions, err := schedulebase.MakeScheduleOptions(firstRun, sj.ScheduleDetails())
+	if err != nil {
+		return "", err
+	}
+
+	node := &tree.ScheduledSQL{
+		ScheduleLabelSpec: tree.ScheduleLabelSpec{
+			IfNotExists: false, Label: tree.NewDString(sj.ScheduleLabel())},
+		Recurrence:      tree.NewDString(sj.ScheduleExpr()),
+		Statement:       tree.NewDString(args.Statement),
+		ScheduleOptions: scheduleOptions,
+	}
+	return tree.AsString(node), nil
+}
+
+// redactStatement returns the scheduled statement with its constants hidden,
+// since they may contain sensitive data such as the credentials of a storage
+// URI. If the statement cannot be parsed, it is omitted.
+func redactStatement(stmt string) string {
+	parsed, err := parser.ParseOne(stmt)
+	if err != nil {
+		return "<redacted>"
+	}
+	return tree.AsStringWithFlags(parsed.AST, tree.FmtHideConstants)
+}
+
+func extractExecutionArgs(sj *jobs.ScheduledJob) (*jobspb.ScheduledSQLExecutionArgs, error) {
+	args := &jobspb.ScheduledSQLExecutionArgs{}
+	if err := pbtypes.UnmarshalAny(sj.ExecutionArgs().Args, args); err != nil {
+		return nil, errors.Wrap(err, "un-marshaling args")
+	}
+	return args, nil
+}
+
+func init() {
+	jobs.RegisterConstructor(jobspb.TypeScheduledSQL, func(job *jobs.Job, settings *cluster.Settings) jobs.Resumer {
+		details := job.Details().(jobspb.ScheduledSQLDetails)
+		return &schedulebase.StatementJobResumer{
+			Job:       job,
+			OpName:    "scheduled-sql",
+			Statement: details.Statement,
+			Database:  details.Database,
+			Started: func(progress jobspb.Progress) bool {
+				return progress.GetScheduledSQL().Started
+			},
+			StartedProgress: jobspb.ScheduledSQLProgress{Started: true},
+		}
+	})
+
+	jobs.RegisterScheduledJobExecutorFactory(
+		tree.ScheduledSQLExecutor.InternalName(),
+		func() (jobs.ScheduledJobExecutor, error) {
+			m := jobs.MakeExecutorMetrics(tree.ScheduledSQLExecutor.UserName())
+			return &scheduledSQLExecutor{
+				metrics: scheduledSQLMetrics{
+					ExecutorMetrics: &m,
+				},
+			}, nil
+		})
+}
@@ -0,0 +1,244 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package scheduledsql_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobstest"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/scheduledjobs"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/server"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type testHelper struct {
+	server           serverutils.TestServerInterface
+	env              *jobstest.JobSchedulerTestEnv
+	cfg              *scheduledjobs.JobExecutionConfig
+	sqlDB            *sqlutils.SQLRunner
+	executeSchedules func() error
+}
+
+// newTestHelper starts a server whose job scheduler is driven by the test
+// through jobstest.JobSchedulerTestEnv.
+func newTestHelper(t *testing.T) (*testHelper, func()) {
+	th := &testHelper{
+		env: jobstest.NewJobSchedulerTestEnv(
+			jobstest.UseSystemTables, timeutil.Now(), tree.ScheduledSQLExecutor),
+	}
+
+	knobs := &jobs.TestingKnobs{
+		JobSchedulerEnv: th.env,
+		TakeOverJobsScheduling: func(fn func(ctx context.Context, maxSchedules int64, txn *kv.Txn) error) {
+			th.executeSchedules = func() error {
+				defer th.server.JobRegistry().(*jobs.Registry).TestingNudgeAdoptionQueue()
+				return th.cfg.DB.Txn(context.Background(), func(ctx context.Context, txn *kv.Txn) error {
+					// maxSchedules = 0 means there's no limit.
+					return fn(ctx, 0 /* maxSchedules */, txn)
+				})
+			}
+		},
+		CaptureJobExecutionConfig: func(config *scheduledjobs.JobExecutionConfig) {
+			th.cfg = config
+		},
+	}
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{
+		Knobs: base.TestingKnobs{JobsTestingKnobs: knobs},
+	})
+	require.NotNil(t, th.cfg)
+	th.sqlDB = sqlutils.MakeSQLRunner(db)
+	th.server = s
+
+	return th, func() {
+		s.Stopper().Stop(context.Background())
+	}
+}
+
+func (h *testHelper) createSchedule(t *testing.T, query string) int64 {
+	t.Helper()
+	var id int64
+	var unusedStr string
+	var unusedTS *time.Time
+	h.sqlDB.QueryRow(t, query).Scan(&id, &unusedStr, &unusedStr, &unusedTS, &unusedStr, &unusedStr)
+	return id
+}
+
+func (h *testHelper) loadSchedule(t *testing.T, id int64) *jobs.ScheduledJob {
+	t.Helper()
+	sj, err := jobs.LoadScheduledJob(context.Background(), h.env, id, h.cfg.InternalExecutor, nil)
+	require.NoError(t, err)
+	return sj
+}
+
+// fireSchedule advances the time past the next run of the schedule and runs
+// the job scheduler.
+func (h *testHelper) fireSchedule(t *testing.T, id int64) {
+	t.Helper()
+	h.env.SetTime(h.loadSchedule(t, id).NextRun().Add(time.Second))
+	require.NoError(t, h.executeSchedules())
+}
+
+// waitForJob waits for the job to reach the specified status.
+func (h *testHelper) waitForJob(t *testing.T, jobID jobspb.JobID, status jobs.Status) {
+	t.Helper()
+	testutils.SucceedsSoon(t, func() error {
+		h.server.JobRegistry().(*jobs.Registry).TestingNudgeAdoptionQueue()
+		var actual string
+		h.sqlDB.QueryRow(t, `SELECT status FROM system.jobs WHERE id = $1`, jobID).Scan(&actual)
+		if jobs.Status(actual) != status {
+			return errors.Newf("job %d is %s, expected %s", jobID, actual, status)
+		}
+		return nil
+	})
+}
+
+// scheduledJob returns the ID of the job started by the schedule.
+func (h *testHelper) scheduledJob(t *testing.T, id int64) jobspb.JobID {
+	t.Helper()
+	var jobID jobspb.JobID
+	h.sqlDB.QueryRow(t,
+		`SELECT id FROM system.jobs WHERE created_by_type = $1 AND created_by_id = $2`,
+		jobs.CreatedByScheduledJobs, id).Scan(&jobID)
+	return jobID
+}
+
+func TestScheduledSQL(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	th, cleanup := newTestHelper(t)
+	defer cleanup()
+
+	th.sqlDB.Exec(t, `
+CREATE DATABASE d;
+CREATE TABLE d.log (id INT PRIMARY KEY DEFAULT unique_rowid(), username STRING);
+CREATE USER schedule_owner;
+GRANT admin TO schedule_owner;
+`)
+
+	t.Run("execute as owner", func(t *testing.T) {
+		th.sqlDB.Exec(t, `USE d`)
+		defer th.sqlDB.Exec(t, `USE defaultdb`)
+		id := th.createSchedule(t,
+			`CREATE SCHEDULE FOR SQL 'INSERT INTO log (username) VALUES (current_user())' RECURRING '@hourly'`)
+		defer th.sqlDB.Exec(t, `DROP SCHEDULE $1`, id)
+		th.sqlDB.Exec(t, `UPDATE system.scheduled_jobs SET owner = 'schedule_owner' WHERE schedule_id = $1`, id)
+
+		th.fireSchedule(t, id)
+		jobID := th.scheduledJob(t, id)
+		th.waitForJob(t, jobID, jobs.StatusSucceeded)
+
+		// The statement ran in the database of the session which created the
+		// schedule, as the owner of the schedule.
+		th.sqlDB.CheckQueryResults(t, `SELECT username FROM d.log`, [][]string{{"schedule_owner"}})
+		require.Equal(t, [][]string{{"SCHEDULED SQL", "schedule_owner"}}, th.sqlDB.QueryStr(t,
+			`SELECT job_type, user_name FROM [SHOW JOBS] WHERE job_id = $1`, jobID))
+		sj := th.loadSchedule(t, id)
+		require.False(t, sj.IsPaused())
+		require.Equal(t, string(jobs.StatusSucceeded), sj.ScheduleStatus())
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		id := th.createSchedule(t,
+			`CREATE SCHEDULE FOR SQL 'INSERT INTO d.missing VALUES (1)' RECURRING '@hourly'
+WITH SCHEDULE OPTIONS on_execution_failure = 'pause'`)
+		defer th.sqlDB.Exec(t, `DROP SCHEDULE $1`, id)
+
+		th.fireSchedule(t, id)
+		jobID := th.scheduledJob(t, id)
+		th.waitForJob(t, jobID, jobs.StatusFailed)
+		var jobErr string
+		th.sqlDB.QueryRow(t, `SELECT error FROM [SHOW JOBS] WHERE job_id = $1`, jobID).Scan(&jobErr)
+		require.Regexp(t, `relation "d.missing" does not exist`, jobErr)
+
+		// The constants of the statement are hidden from the description of
+		// the job.
+		var description string
+		th.sqlDB.QueryRow(t, `SELECT description FROM [SHOW JOBS] WHERE job_id = $1`, jobID).Scan(&description)
+		require.Equal(t, fmt.Sprintf("scheduled SQL %d: INSERT INTO d.missing VALUES (_)", id), description)
+
+		// The failure of the job is recorded in the state of the schedule.
+		testutils.SucceedsSoon(t, func() error {
+			if sj := th.loadSchedule(t, id); !sj.IsPaused() {
+				return errors.Newf("schedule %d is not paused: %s", id, sj.ScheduleStatus())
+			}
+			return nil
+		})
+		require.Equal(t,
+			fmt.Sprintf("schedule paused: scheduled SQL job %d failed with status failed", jobID),
+			th.loadSchedule(t, id).ScheduleStatus())
+	})
+
+	t.Run("resumed after start", func(t *testing.T) {
+		// A job which is resumed after it started executing its statement, e.g.
+		// after the node executing it restarted, fails instead of executing the
+		// statement a second time.
+		registry := th.server.JobRegistry().(*jobs.Registry)
+		jobID := registry.MakeJobID()
+		require.NoError(t, th.cfg.DB.Txn(context.Background(), func(ctx context.Context, txn *kv.Txn) error {
+			_, err := registry.CreateAdoptableJobWithTxn(ctx, jobs.Record{
+				Description: "interrupted",
+				Username:    security.RootUserName(),
+				Details: jobspb.ScheduledSQLDetails{
+					Statement: `INSERT INTO log (username) VALUES ('interrupted')`,
+					Database:  "d",
+				},
+				Progress: jobspb.ScheduledSQLProgress{Started: true},
+			}, jobID, txn)
+			return err
+		}))
+		th.waitForJob(t, jobID, jobs.StatusFailed)
+		th.sqlDB.CheckQueryResults(t,
+			`SELECT count(*) FROM d.log WHERE username = 'interrupted'`, [][]string{{"0"}})
+	})
+}
+
+// TestScheduledSQLMixedVersion checks that SQL schedules cannot be created
+// until all nodes can run their jobs.
+func TestScheduledSQLMixedVersion(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{
+		Knobs: base.TestingKnobs{
+			Server: &server.TestingKnobs{
+				DisableAutomaticVersionUpgrade: 1,
+				BinaryVersionOverride:          clusterversion.ByKey(clusterversion.ScheduledSQL - 1),
+			},
+		},
+	})
+	defer s.Stopper().Stop(context.Background())
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	const create = `CREATE SCHEDULE FOR SQL 'SELECT 1' RECURRING '@hourly'`
+	sqlDB.ExpectErr(t, `CREATE SCHEDULE FOR SQL requires all nodes to be upgraded`, create)
+	sqlDB.Exec(t, `SET CLUSTER SETTING version = $1`,
+		clusterversion.ByKey(clusterversion.ScheduledSQL).String())
+	sqlDB.Exec(t, create)
+}
@@ -625,7 +625,7 @@ func (sc *SchemaChanger) exec(ctx context.Context) error {
 		}
 		// Some descriptors should be deleted if they are in the DROP state.
 		switch desc.(type) {
-		case catalog.SchemaDescriptor, catalog.DatabaseDescriptor:
+		case catalog.SchemaDescriptor, catalog.DatabaseDescriptor, catalog.FunctionDescriptor:
 			if desc.Dropped() {
 				if err := sc.execCfg.DB.Del(ctx, catalogkeys.MakeDescMetadataKey(sc.execCfg.Codec, desc.GetID())); err != nil {
 					return err
@@ -1153,6 +1153,11 @@ func (sc *SchemaChanger) done(ctx context.Context) error {
 				// If we are mutation is in the ADD state, then start GC jobs for the
 				// existing indexes on the table.
 				if m.Adding() {
+					if refresh.ShouldBackfill() {
+						scTable.MaterializedViewAsOfTime = refresh.AsOf()
+					} else {
+						scTable.MaterializedViewAsOfTime = hlc.Timestamp{}
+					}
 					desc := fmt.Sprintf("REFRESH MATERIALIZED VIEW %q cleanup", scTable.Name)
 					for _, idx := range scTable.ActiveIndexes() {
 						if err := sc.createIndexGCJob(ctx, idx.GetID(), txn, desc); err != nil {
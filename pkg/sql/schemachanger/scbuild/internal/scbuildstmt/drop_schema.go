@@ -15,6 +15,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scerrors"
 	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/errors"
@@ -53,10 +54,15 @@ func dropSchema(
 			descsThatNeedElements.Add(id)
 		}
 	}
-	if behavior != tree.DropCascade && !dropIDs.Empty() {
+	if behavior != tree.DropCascade && (!dropIDs.Empty() || len(sc.GetFunctions()) > 0) {
 		panic(pgerror.Newf(pgcode.DependentObjectsStillExist,
 			"schema %q is not empty and CASCADE was not specified", sc.GetName()))
 	}
+	// The descriptors of user-defined functions have no elements, so they are
+	// dropped by the legacy schema changer.
+	if len(sc.GetFunctions()) > 0 {
+		panic(scerrors.NotImplementedErrorf(nil, "dropping schema with user-defined functions"))
+	}
 	{
 		c := b.WithNewSourceElementID()
 		for _, id := range descsThatNeedElements.Ordered() {
@@ -235,6 +235,28 @@ func mustBeDIntInTenantRange(e tree.Expr) (tree.DInt, error) {
 	return tenID, nil
 }
 
+// makePlanBaselineOverload returns an overload of the builtins which create
+// plan baselines, which return the ID of the new baseline.
+func makePlanBaselineOverload(
+	argTypes tree.ArgTypes,
+	fn func(evalCtx *tree.EvalContext, args tree.Datums) (int64, error),
+	info string,
+) tree.Overload {
+	return tree.Overload{
+		Types:      argTypes,
+		ReturnType: tree.FixedReturnType(types.Int),
+		Fn: func(evalCtx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+			id, err := fn(evalCtx, args)
+			if err != nil {
+				return nil, err
+			}
+			return tree.NewDInt(tree.DInt(id)), nil
+		},
+		Info:       info,
+		Volatility: tree.VolatilityVolatile,
+	}
+}
+
 // builtins contains the built-in functions indexed by name.
 //
 // For use in other packages, see AllBuiltinNames and GetBuiltinProperties().
@@ -6231,6 +6253,121 @@ table's zone configuration this will return NULL.`,
 		},
 	),
 
+	"crdb_internal.capture_plan_baseline": makeBuiltin(
+		tree.FunctionProperties{
+			Category: categorySystemInfo,
+		},
+		makePlanBaselineOverload(
+			tree.ArgTypes{{"query", types.String}},
+			func(evalCtx *tree.EvalContext, args tree.Datums) (int64, error) {
+				query := string(tree.MustBeDString(args[0]))
+				return evalCtx.Planner.CapturePlanBaseline(evalCtx.Ctx(), query, false /* enforce */)
+			},
+			`Records the plan which the optimizer currently chooses for the given query as `+
+				`the accepted plan baseline of its fingerprint, and returns the ID of the baseline.`,
+		),
+		makePlanBaselineOverload(
+			tree.ArgTypes{{"query", types.String}, {"enforce", types.Bool}},
+			func(evalCtx *tree.EvalContext, args tree.Datums) (int64, error) {
+				query := string(tree.MustBeDString(args[0]))
+				enforce := bool(tree.MustBeDBool(args[1]))
+				return evalCtx.Planner.CapturePlanBaseline(evalCtx.Ctx(), query, enforce)
+			},
+			`Records the plan which the optimizer currently chooses for the given query as `+
+				`the accepted plan baseline of its fingerprint, and returns the ID of the baseline. `+
+				`If enforce is set, statements fail rather than being planned without the baseline.`,
+		),
+	),
+
+	"crdb_internal.pin_plan_baseline": makeBuiltin(
+		tree.FunctionProperties{
+			Category: categorySystemInfo,
+		},
+		makePlanBaselineOverload(
+			tree.ArgTypes{{"query", types.String}},
+			func(evalCtx *tree.EvalContext, args tree.Datums) (int64, error) {
+				query := string(tree.MustBeDString(args[0]))
+				return evalCtx.Planner.PinPlanBaseline(evalCtx.Ctx(), query, false /* enforce */)
+			},
+			`Records the index and join hints of the given query as the accepted plan `+
+				`baseline of its fingerprint, and returns the ID of the baseline.`,
+		),
+		makePlanBaselineOverload(
+			tree.ArgTypes{{"query", types.String}, {"enforce", types.Bool}},
+			func(evalCtx *tree.EvalContext, args tree.Datums) (int64, error) {
+				query := string(tree.MustBeDString(args[0]))
+				enforce := bool(tree.MustBeDBool(args[1]))
+				return evalCtx.Planner.PinPlanBaseline(evalCtx.Ctx(), query, enforce)
+			},
+			`Records the index and join hints of the given query as the accepted plan `+
+				`baseline of its fingerprint, and returns the ID of the baseline. If enforce `+
+				`is set, statements fail rather than being planned without the baseline.`,
+		),
+	),
+
+	"crdb_internal.evolve_plan_baseline": makeBuiltin(
+		tree.FunctionProperties{
+			Category: categorySystemInfo,
+		},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"id", types.Int}},
+			ReturnType: tree.FixedReturnType(types.Int),
+			Fn: func(evalCtx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				id := int64(tree.MustBeDInt(args[0]))
+				candidateID, ok, err := evalCtx.Planner.EvolvePlanBaseline(evalCtx.Ctx(), id)
+				if err != nil || !ok {
+					return tree.DNull, err
+				}
+				return tree.NewDInt(tree.DInt(candidateID)), nil
+			},
+			Info: `Plans the statement of the given plan baseline without hints, and records ` +
+				`the plan as a candidate baseline if it is estimated to be cheaper than the ` +
+				`plan of the baseline. Returns the ID of the candidate, or NULL if there is none.`,
+			Volatility: tree.VolatilityVolatile,
+		},
+	),
+
+	"crdb_internal.accept_plan_baseline": makeBuiltin(
+		tree.FunctionProperties{
+			Category: categorySystemInfo,
+		},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"id", types.Int}},
+			ReturnType: tree.FixedReturnType(types.Bool),
+			Fn: func(evalCtx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				id := int64(tree.MustBeDInt(args[0]))
+				ok, err := evalCtx.Planner.AcceptPlanBaseline(evalCtx.Ctx(), id)
+				if err != nil {
+					return nil, err
+				}
+				return tree.MakeDBool(tree.DBool(ok)), nil
+			},
+			Info: `Makes the given plan baseline the accepted baseline of its fingerprint. ` +
+				`Returns false if the baseline does not exist.`,
+			Volatility: tree.VolatilityVolatile,
+		},
+	),
+
+	"crdb_internal.drop_plan_baseline": makeBuiltin(
+		tree.FunctionProperties{
+			Category: categorySystemInfo,
+		},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"id", types.Int}},
+			ReturnType: tree.FixedReturnType(types.Bool),
+			Fn: func(evalCtx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				id := int64(tree.MustBeDInt(args[0]))
+				ok, err := evalCtx.Planner.DropPlanBaseline(evalCtx.Ctx(), id)
+				if err != nil {
+					return nil, err
+				}
+				return tree.MakeDBool(tree.DBool(ok)), nil
+			},
+			Info:       `Deletes the given plan baseline. Returns false if the baseline does not exist.`,
+			Volatility: tree.VolatilityVolatile,
+		},
+	),
+
 	"crdb_internal.serialize_session": makeBuiltin(
 		tree.FunctionProperties{
 			Category: categorySystemInfo,
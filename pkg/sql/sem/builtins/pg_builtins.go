@@ -2278,6 +2278,8 @@ func getCatalogOidForComments(catalogName string) (id int, ok bool) {
 		return catconstants.PgCatalogDescriptionTableID, true
 	case "pg_constraint":
 		return catconstants.PgCatalogConstraintTableID, true
+	case "pg_proc":
+		return catconstants.PgCatalogProcTableID, true
 	default:
 		// We currently only support comments on pg_class objects
 		// (columns, tables) in this context.
@@ -156,6 +156,11 @@ type Restore struct {
 	// ... FROM 'from' IN 'subdir'...`. Alternatively, restore_planning.go will set
 	// it for the query `RESTORE ... FROM 'from' IN LATEST...`
 	Subdir Expr
+
+	// AsTableName is set by the parser when the SQL query is of the form
+	// `RESTORE TABLE t AS new_t FROM ...`, in which case Targets contains
+	// exactly the one table t, which is restored under the name new_t.
+	AsTableName *UnresolvedObjectName
 }
 
 var _ Statement = &Restore{}
@@ -167,6 +172,11 @@ func (node *Restore) Format(ctx *FmtCtx) {
 		ctx.FormatNode(&node.Targets)
 		ctx.WriteString(" ")
 	}
+	if node.AsTableName != nil {
+		ctx.WriteString("AS ")
+		ctx.FormatNode(node.AsTableName)
+		ctx.WriteString(" ")
+	}
 	ctx.WriteString("FROM ")
 	if node.Subdir != nil {
 		ctx.FormatNode(node.Subdir)
@@ -15,12 +15,20 @@ type CreateChangefeed struct {
 	Targets TargetList
 	SinkURI Expr
 	Options KVOptions
+	// Select is set for changefeeds created with a CDC query (CREATE
+	// CHANGEFEED ... AS SELECT). Its FROM clause contains the single table
+	// that Targets refers to.
+	Select *SelectClause
 }
 
 var _ Statement = &CreateChangefeed{}
 
 // Format implements the NodeFormatter interface.
 func (node *CreateChangefeed) Format(ctx *FmtCtx) {
+	if node.Select != nil {
+		node.formatWithSelect(ctx)
+		return
+	}
 	if node.SinkURI != nil {
 		ctx.WriteString("CREATE ")
 	} else {
@@ -39,3 +47,19 @@ func (node *CreateChangefeed) Format(ctx *FmtCtx) {
 		ctx.FormatNode(&node.Options)
 	}
 }
+
+// formatWithSelect formats a changefeed created with a CDC query. The targets
+// are not formatted separately since they are implied by the FROM clause.
+func (node *CreateChangefeed) formatWithSelect(ctx *FmtCtx) {
+	ctx.WriteString("CREATE CHANGEFEED")
+	if node.SinkURI != nil {
+		ctx.WriteString(" INTO ")
+		ctx.FormatNode(node.SinkURI)
+	}
+	if node.Options != nil {
+		ctx.WriteString(" WITH ")
+		ctx.FormatNode(&node.Options)
+	}
+	ctx.WriteString(" AS ")
+	ctx.FormatNode(node.Select)
+}
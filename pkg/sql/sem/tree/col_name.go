@@ -11,6 +11,8 @@
 package tree
 
 import (
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 )
@@ -67,6 +69,12 @@ func ComputeColNameInternal(sp sessiondata.SearchPath, target Expr) (int, string
 	case *FuncExpr:
 		fd, err := e.Func.Resolve(sp)
 		if err != nil {
+			// The name may be the name of a user-defined function, which is
+			// resolved later.
+			if n, ok := e.Func.FunctionReference.(*UnresolvedName); ok &&
+				pgerror.GetPGCode(err) == pgcode.UndefinedFunction {
+				return 2, n.Parts[0], nil
+			}
 			return 0, "", err
 		}
 		return 2, fd.Name, nil
@@ -147,6 +155,9 @@ func ComputeColNameInternal(sp sessiondata.SearchPath, target Expr) (int, string
 	case *CoalesceExpr:
 		return 2, "coalesce", nil
 
+	case *GroupingExpr:
+		return 2, "grouping", nil
+
 		// CockroachDB-specific nodes follow.
 	case *IfErrExpr:
 		if e.Else == nil {
@@ -445,6 +445,7 @@ type ColumnTableDef struct {
 		ConstraintName Name
 		Actions        ReferenceActions
 		Match          CompositeKeyMatchMethod
+		Deferrable     ConstraintDeferrability
 	}
 	Computed struct {
 		Computed bool
@@ -626,6 +627,7 @@ func NewColumnTableDef(
 			d.References.ConstraintName = c.Name
 			d.References.Actions = t.Actions
 			d.References.Match = t.Match
+			d.References.Deferrable = t.Deferrable
 		case *ColumnComputedDef:
 			if d.GeneratedIdentity.IsGeneratedAsIdentity {
 				return nil, pgerror.Newf(pgcode.Syntax,
@@ -779,6 +781,10 @@ func (node *ColumnTableDef) Format(ctx *FmtCtx) {
 			ctx.WriteString(node.References.Match.String())
 		}
 		ctx.FormatNode(&node.References.Actions)
+		if node.References.Deferrable != ConstraintNotDeferrable {
+			ctx.WriteByte(' ')
+			ctx.WriteString(node.References.Deferrable.String())
+		}
 	}
 	if node.IsComputed() {
 		ctx.WriteString(" AS (")
@@ -905,10 +911,11 @@ type ColumnCheckConstraint struct {
 
 // ColumnFKConstraint represents a FK-constaint on a column.
 type ColumnFKConstraint struct {
-	Table   TableName
-	Col     Name // empty-string means use PK
-	Actions ReferenceActions
-	Match   CompositeKeyMatchMethod
+	Table      TableName
+	Col        Name // empty-string means use PK
+	Actions    ReferenceActions
+	Match      CompositeKeyMatchMethod
+	Deferrable ConstraintDeferrability
 }
 
 // ColumnComputedDef represents the description of a computed column.
@@ -998,6 +1005,7 @@ type UniqueConstraintTableDef struct {
 	IndexTableDef
 	PrimaryKey   bool
 	WithoutIndex bool
+	Deferrable   ConstraintDeferrability
 	IfNotExists  bool
 }
 
@@ -1043,6 +1051,10 @@ func (node *UniqueConstraintTableDef) Format(ctx *FmtCtx) {
 	if node.PartitionByIndex != nil {
 		ctx.FormatNode(node.PartitionByIndex)
 	}
+	if node.Deferrable != ConstraintNotDeferrable {
+		ctx.WriteByte(' ')
+		ctx.WriteString(node.Deferrable.String())
+	}
 	if node.Predicate != nil {
 		ctx.WriteString(" WHERE ")
 		ctx.FormatNode(node.Predicate)
@@ -1116,6 +1128,28 @@ func (c CompositeKeyMatchMethod) String() string {
 	return compositeKeyMatchMethodName[c]
 }
 
+// ConstraintDeferrability specifies whether the checking of a constraint can
+// be deferred until the end of the transaction and, if so, whether it is
+// deferred by default. See SET CONSTRAINTS.
+type ConstraintDeferrability int
+
+// The values for ConstraintDeferrability.
+const (
+	ConstraintNotDeferrable ConstraintDeferrability = iota
+	ConstraintInitiallyImmediate
+	ConstraintInitiallyDeferred
+)
+
+var constraintDeferrabilityName = [...]string{
+	ConstraintNotDeferrable:      "NOT DEFERRABLE",
+	ConstraintInitiallyImmediate: "DEFERRABLE",
+	ConstraintInitiallyDeferred:  "DEFERRABLE INITIALLY DEFERRED",
+}
+
+func (c ConstraintDeferrability) String() string {
+	return constraintDeferrabilityName[c]
+}
+
 // ForeignKeyConstraintTableDef represents a FOREIGN KEY constraint in the AST.
 type ForeignKeyConstraintTableDef struct {
 	Name        Name
@@ -1124,6 +1158,7 @@ type ForeignKeyConstraintTableDef struct {
 	ToCols      NameList
 	Actions     ReferenceActions
 	Match       CompositeKeyMatchMethod
+	Deferrable  ConstraintDeferrability
 	IfNotExists bool
 }
 
@@ -1155,6 +1190,11 @@ func (node *ForeignKeyConstraintTableDef) Format(ctx *FmtCtx) {
 	}
 
 	ctx.FormatNode(&node.Actions)
+
+	if node.Deferrable != ConstraintNotDeferrable {
+		ctx.WriteByte(' ')
+		ctx.WriteString(node.Deferrable.String())
+	}
 }
 
 // SetName implements the ConstraintTableDef interface.
@@ -1544,12 +1584,13 @@ func (node *CreateTable) HoistConstraints() {
 					targetCol = append(targetCol, col.References.Col)
 				}
 				node.Defs = append(node.Defs, &ForeignKeyConstraintTableDef{
-					Table:    *col.References.Table,
-					FromCols: NameList{col.Name},
-					ToCols:   targetCol,
-					Name:     col.References.ConstraintName,
-					Actions:  col.References.Actions,
-					Match:    col.References.Match,
+					Table:      *col.References.Table,
+					FromCols:   NameList{col.Name},
+					ToCols:     targetCol,
+					Name:       col.References.ConstraintName,
+					Actions:    col.References.Actions,
+					Match:      col.References.Match,
+					Deferrable: col.References.Deferrable,
 				})
 				col.References.Table = nil
 			}
@@ -2016,17 +2057,27 @@ type CreateStatsOptions struct {
 	// Note that the timestamp will be moved up during the operation if it gets
 	// too old (in order to avoid problems with TTL expiration).
 	AsOf AsOfClause
+
+	// UsingExtremes creates partial statistics that only scan the values of the
+	// columns that are below the lowest bound or above the highest bound of
+	// the histograms of the existing statistics.
+	UsingExtremes bool
 }
 
 // Empty returns true if no options were provided.
 func (o *CreateStatsOptions) Empty() bool {
-	return o.Throttling == 0 && o.AsOf.Expr == nil
+	return o.Throttling == 0 && o.AsOf.Expr == nil && !o.UsingExtremes
 }
 
 // Format implements the NodeFormatter interface.
 func (o *CreateStatsOptions) Format(ctx *FmtCtx) {
 	sep := ""
+	if o.UsingExtremes {
+		ctx.WriteString("USING EXTREMES")
+		sep = " "
+	}
 	if o.Throttling != 0 {
+		ctx.WriteString(sep)
 		ctx.WriteString("THROTTLING ")
 		// TODO(knz): Remove all this with ctx.FormatNode()
 		// if/when throttling supports full expressions.
@@ -2062,6 +2113,12 @@ func (o *CreateStatsOptions) CombineWith(other *CreateStatsOptions) error {
 		}
 		o.AsOf = other.AsOf
 	}
+	if other.UsingExtremes {
+		if o.UsingExtremes {
+			return errors.New("USING EXTREMES specified multiple times")
+		}
+		o.UsingExtremes = true
+	}
 	return nil
 }
 
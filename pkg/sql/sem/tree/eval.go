@@ -3243,6 +3243,29 @@ type EvalPlanner interface {
 	// DecodeGist exposes gist functionality to the builtin functions.
 	DecodeGist(gist string) ([]string, error)
 
+	// CapturePlanBaseline records the plan which the optimizer currently
+	// chooses for the given query as the accepted plan baseline of its
+	// fingerprint, and returns the ID of the baseline.
+	CapturePlanBaseline(ctx context.Context, query string, enforce bool) (int64, error)
+
+	// PinPlanBaseline records the index and join hints of the given query as
+	// the accepted plan baseline of its fingerprint, and returns the ID of the
+	// baseline.
+	PinPlanBaseline(ctx context.Context, query string, enforce bool) (int64, error)
+
+	// EvolvePlanBaseline plans the statement of the given plan baseline without
+	// hints, and records the plan as an unaccepted candidate baseline if it is
+	// estimated to be better. It returns the ID of the candidate, if any.
+	EvolvePlanBaseline(ctx context.Context, id int64) (candidateID int64, ok bool, err error)
+
+	// AcceptPlanBaseline makes the given plan baseline the accepted baseline of
+	// its fingerprint. It returns false if the baseline does not exist.
+	AcceptPlanBaseline(ctx context.Context, id int64) (bool, error)
+
+	// DropPlanBaseline deletes the given plan baseline. It returns false if the
+	// baseline does not exist.
+	DropPlanBaseline(ctx context.Context, id int64) (bool, error)
+
 	// CreateSessionRevivalToken creates a token that can be used to log in
 	// as the current user, in bytes form.
 	CreateSessionRevivalToken() (*DBytes, error)
@@ -3538,6 +3561,9 @@ type EvalContext struct {
 	// TxnReadOnly specifies if the current transaction is read-only.
 	TxnReadOnly bool
 	TxnImplicit bool
+	// TxnConstraintsMode is the checking mode of deferrable constraints set
+	// by SET CONSTRAINTS in the current transaction.
+	TxnConstraintsMode ConstraintsMode
 
 	Settings    *cluster.Settings
 	ClusterID   uuid.UUID
@@ -4298,6 +4324,11 @@ func ensureExpectedType(exp *types.T, d Datum) error {
 	return nil
 }
 
+// Eval implements the TypedExpr interface.
+func (expr *GroupingExpr) Eval(ctx *EvalContext) (Datum, error) {
+	return nil, errors.AssertionFailedf("unhandled type %T", expr)
+}
+
 // Eval implements the TypedExpr interface.
 func (expr *IfErrExpr) Eval(ctx *EvalContext) (Datum, error) {
 	cond, evalErr := expr.Cond.(TypedExpr).Eval(ctx)
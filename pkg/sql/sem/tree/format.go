@@ -163,6 +163,11 @@ const (
 	// - Show columns up to 15 characters.
 	// - Show condition up to 15 characters.
 	FmtSummary
+
+	// FmtHideHints instructs the pretty-printer to omit index hints and join
+	// hints, so that a statement written with hints is formatted like the
+	// same statement without them.
+	FmtHideHints
 )
 
 // PasswordSubstitution is the string that replaces
@@ -90,6 +90,11 @@ type Overload struct {
 	// statement which will be executed as a common table expression in the query.
 	SQLFn func(*EvalContext, Datums) (string, error)
 
+	// UDF is set for overloads of user-defined functions. Their body is
+	// inlined by the optimizer, so none of the implementation functions above
+	// are set.
+	UDF *UDFDefinition
+
 	// counter, if non-nil, should be incremented upon successful
 	// type check of expressions using this overload.
 	counter telemetry.Counter
@@ -0,0 +1,116 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tree
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/lexbase"
+	"github.com/cockroachdb/cockroach/pkg/util/lsn"
+)
+
+// The statements in this file are the commands of the streaming replication
+// protocol. They are only accepted on connections established in replication
+// mode, and are not parsed by the SQL parser.
+
+// IdentifySystem represents an IDENTIFY_SYSTEM replication command.
+type IdentifySystem struct{}
+
+var _ Statement = &IdentifySystem{}
+
+// Format implements the NodeFormatter interface.
+func (node *IdentifySystem) Format(ctx *FmtCtx) {
+	ctx.WriteString("IDENTIFY_SYSTEM")
+}
+
+// CreateReplicationSlot represents a CREATE_REPLICATION_SLOT replication
+// command. Only logical slots can be created.
+type CreateReplicationSlot struct {
+	Name Name
+	// Plugin is the name of the output plugin used to decode the changes.
+	Plugin Name
+}
+
+var _ Statement = &CreateReplicationSlot{}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateReplicationSlot) Format(ctx *FmtCtx) {
+	ctx.WriteString("CREATE_REPLICATION_SLOT ")
+	ctx.FormatNode(&node.Name)
+	ctx.WriteString(" LOGICAL ")
+	ctx.FormatNode(&node.Plugin)
+}
+
+// DropReplicationSlot represents a DROP_REPLICATION_SLOT replication command.
+type DropReplicationSlot struct {
+	Name Name
+	Wait bool
+}
+
+var _ Statement = &DropReplicationSlot{}
+
+// Format implements the NodeFormatter interface.
+func (node *DropReplicationSlot) Format(ctx *FmtCtx) {
+	ctx.WriteString("DROP_REPLICATION_SLOT ")
+	ctx.FormatNode(&node.Name)
+	if node.Wait {
+		ctx.WriteString(" WAIT")
+	}
+}
+
+// ReplicationOption is an option of the output plugin passed to
+// START_REPLICATION.
+type ReplicationOption struct {
+	Key Name
+	// Value is empty if the option was given without a value.
+	Value string
+}
+
+// StartReplication represents a START_REPLICATION replication command. Only
+// logical replication is supported.
+type StartReplication struct {
+	Slot     Name
+	StartLSN lsn.LSN
+	Options  []ReplicationOption
+}
+
+var _ Statement = &StartReplication{}
+
+// Format implements the NodeFormatter interface.
+func (node *StartReplication) Format(ctx *FmtCtx) {
+	ctx.WriteString("START_REPLICATION SLOT ")
+	ctx.FormatNode(&node.Slot)
+	ctx.WriteString(" LOGICAL ")
+	ctx.WriteString(node.StartLSN.String())
+	if len(node.Options) > 0 {
+		ctx.WriteString(" (")
+		for i := range node.Options {
+			if i > 0 {
+				ctx.WriteString(", ")
+			}
+			ctx.FormatNode(&node.Options[i].Key)
+			if node.Options[i].Value != "" {
+				ctx.WriteByte(' ')
+				lexbase.EncodeSQLString(&ctx.Buffer, node.Options[i].Value)
+			}
+		}
+		ctx.WriteByte(')')
+	}
+}
+
+// Option returns the value of the option with the given key, and whether the
+// option was specified.
+func (node *StartReplication) Option(key string) (string, bool) {
+	for _, o := range node.Options {
+		if string(o.Key) == key {
+			return o.Value, true
+		}
+	}
+	return "", false
+}
@@ -91,3 +91,96 @@ func (node ScheduledBackup) Coverage() DescriptorCoverage {
 	}
 	return RequestedDescriptors
 }
+
+// ScheduledChangefeed represents a scheduled changefeed job. Each execution
+// of the schedule runs a changefeed that exports the initial scan of its
+// targets and then completes.
+type ScheduledChangefeed struct {
+	ScheduleLabelSpec ScheduleLabelSpec
+	Recurrence        Expr
+	Changefeed        *CreateChangefeed
+	ScheduleOptions   KVOptions
+}
+
+var _ Statement = &ScheduledChangefeed{}
+
+// Format implements the NodeFormatter interface.
+func (node *ScheduledChangefeed) Format(ctx *FmtCtx) {
+	formatScheduleLabel(ctx, &node.ScheduleLabelSpec)
+
+	ctx.WriteString(" FOR CHANGEFEED ")
+	ctx.FormatNode(&node.Changefeed.Targets)
+	ctx.WriteString(" INTO ")
+	ctx.FormatNode(node.Changefeed.SinkURI)
+	if node.Changefeed.Options != nil {
+		ctx.WriteString(" WITH ")
+		ctx.FormatNode(&node.Changefeed.Options)
+	}
+
+	formatScheduleRecurrence(ctx, node.Recurrence, node.ScheduleOptions)
+}
+
+// ScheduledExport represents a scheduled EXPORT statement.
+type ScheduledExport struct {
+	ScheduleLabelSpec ScheduleLabelSpec
+	Recurrence        Expr
+	Export            *Export
+	ScheduleOptions   KVOptions
+}
+
+var _ Statement = &ScheduledExport{}
+
+// Format implements the NodeFormatter interface.
+func (node *ScheduledExport) Format(ctx *FmtCtx) {
+	formatScheduleLabel(ctx, &node.ScheduleLabelSpec)
+	ctx.WriteString(" FOR ")
+	ctx.FormatNode(node.Export)
+	formatScheduleRecurrence(ctx, node.Recurrence, node.ScheduleOptions)
+}
+
+// ScheduledSQL represents a schedule which periodically executes a SQL
+// statement.
+type ScheduledSQL struct {
+	ScheduleLabelSpec ScheduleLabelSpec
+	Recurrence        Expr
+	Statement         Expr
+	ScheduleOptions   KVOptions
+}
+
+var _ Statement = &ScheduledSQL{}
+
+// Format implements the NodeFormatter interface.
+func (node *ScheduledSQL) Format(ctx *FmtCtx) {
+	formatScheduleLabel(ctx, &node.ScheduleLabelSpec)
+	ctx.WriteString(" FOR SQL ")
+	ctx.FormatNode(node.Statement)
+	formatScheduleRecurrence(ctx, node.Recurrence, node.ScheduleOptions)
+}
+
+// formatScheduleLabel formats the CREATE SCHEDULE prefix shared by scheduled
+// statements.
+func formatScheduleLabel(ctx *FmtCtx, spec *ScheduleLabelSpec) {
+	ctx.WriteString("CREATE SCHEDULE")
+	if spec.IfNotExists {
+		ctx.WriteString(" IF NOT EXISTS")
+	}
+	if spec.Label != nil {
+		ctx.WriteString(" ")
+		ctx.FormatNode(spec.Label)
+	}
+}
+
+// formatScheduleRecurrence formats the RECURRING and WITH SCHEDULE OPTIONS
+// clauses shared by scheduled statements.
+func formatScheduleRecurrence(ctx *FmtCtx, recurrence Expr, opts KVOptions) {
+	ctx.WriteString(" RECURRING ")
+	if recurrence == nil {
+		ctx.WriteString("NEVER")
+	} else {
+		ctx.FormatNode(recurrence)
+	}
+	if opts != nil {
+		ctx.WriteString(" WITH SCHEDULE OPTIONS ")
+		ctx.FormatNode(&opts)
+	}
+}
@@ -519,7 +519,7 @@ func (node *AliasedTableExpr) Format(ctx *FmtCtx) {
 		ctx.WriteString("LATERAL ")
 	}
 	ctx.FormatNode(node.Expr)
-	if node.IndexFlags != nil {
+	if node.IndexFlags != nil && !ctx.HasFlags(FmtHideHints) {
 		ctx.FormatNode(node.IndexFlags)
 	}
 	if node.Ordinality {
@@ -588,7 +588,7 @@ func (node *JoinTableExpr) Format(ctx *FmtCtx) {
 		if node.JoinType != "" {
 			ctx.WriteString(node.JoinType)
 			ctx.WriteByte(' ')
-			if node.Hint != "" {
+			if node.Hint != "" && !ctx.HasFlags(FmtHideHints) {
 				ctx.WriteString(node.Hint)
 				ctx.WriteByte(' ')
 			}
@@ -600,7 +600,7 @@ func (node *JoinTableExpr) Format(ctx *FmtCtx) {
 		if node.JoinType != "" {
 			ctx.WriteString(node.JoinType)
 			ctx.WriteByte(' ')
-			if node.Hint != "" {
+			if node.Hint != "" && !ctx.HasFlags(FmtHideHints) {
 				ctx.WriteString(node.Hint)
 				ctx.WriteByte(' ')
 			}
@@ -696,6 +696,43 @@ func (node *GroupBy) Format(ctx *FmtCtx) {
 	}
 }
 
+// GroupingSetsType represents the kind of a GroupingSets element.
+type GroupingSetsType int
+
+const (
+	// GroupingSetsExplicit represents GROUPING SETS (...).
+	GroupingSetsExplicit GroupingSetsType = iota
+	// GroupingSetsRollup represents ROLLUP (...).
+	GroupingSetsRollup
+	// GroupingSetsCube represents CUBE (...).
+	GroupingSetsCube
+)
+
+var groupingSetsTypeName = [...]string{
+	GroupingSetsExplicit: "GROUPING SETS ",
+	GroupingSetsRollup:   "ROLLUP",
+	GroupingSetsCube:     "CUBE",
+}
+
+// GroupingSets represents a GROUPING SETS, ROLLUP or CUBE element of a GROUP
+// BY clause. For GROUPING SETS, each of the Exprs is itself a grouping
+// element. For ROLLUP and CUBE, each of the Exprs is either an expression or
+// a Tuple of expressions which are treated as a unit.
+type GroupingSets struct {
+	Type  GroupingSetsType
+	Exprs Exprs
+}
+
+var _ Expr = &GroupingSets{}
+
+// Format implements the NodeFormatter interface.
+func (node *GroupingSets) Format(ctx *FmtCtx) {
+	ctx.WriteString(groupingSetsTypeName[node.Type])
+	ctx.WriteByte('(')
+	ctx.FormatNode(&node.Exprs)
+	ctx.WriteByte(')')
+}
+
 // DistinctOn represents a DISTINCT ON clause.
 type DistinctOn []Expr
 
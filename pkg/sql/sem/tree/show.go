@@ -89,8 +89,12 @@ const (
 
 // ShowBackup represents a SHOW BACKUP statement.
 type ShowBackup struct {
-	Path                 Expr
-	InCollection         Expr
+	Path         Expr
+	InCollection Expr
+	// LocalityPaths are the URIs of the locality-specific locations of a
+	// locality-aware backup, or of the collection it is in. They follow Path,
+	// or InCollection if set, in parentheses.
+	LocalityPaths        Exprs
 	Details              BackupDetails
 	ShouldIncludeSchemas bool
 	Options              KVOptions
@@ -112,10 +116,23 @@ func (node *ShowBackup) Format(ctx *FmtCtx) {
 	if node.ShouldIncludeSchemas {
 		ctx.WriteString("SCHEMAS ")
 	}
-	ctx.FormatNode(node.Path)
+	formatWithLocalityPaths := func(path Expr) {
+		if len(node.LocalityPaths) == 0 {
+			ctx.FormatNode(path)
+			return
+		}
+		ctx.WriteByte('(')
+		ctx.FormatNode(path)
+		ctx.WriteString(", ")
+		ctx.FormatNode(&node.LocalityPaths)
+		ctx.WriteByte(')')
+	}
 	if node.InCollection != nil {
+		ctx.FormatNode(node.Path)
 		ctx.WriteString(" IN ")
-		ctx.FormatNode(node.InCollection)
+		formatWithLocalityPaths(node.InCollection)
+	} else {
+		formatWithLocalityPaths(node.Path)
 	}
 	if len(node.Options) > 0 {
 		ctx.WriteString(" WITH ")
@@ -712,6 +729,9 @@ func (node *ShowFingerprints) Format(ctx *FmtCtx) {
 type ShowTableStats struct {
 	Table     *UnresolvedObjectName
 	UsingJSON bool
+	// WithForecast also shows the statistics forecasted from the history of
+	// statistics collected on the table.
+	WithForecast bool
 }
 
 // Format implements the NodeFormatter interface.
@@ -722,6 +742,9 @@ func (node *ShowTableStats) Format(ctx *FmtCtx) {
 	}
 	ctx.WriteString("FOR TABLE ")
 	ctx.FormatNode(node.Table)
+	if node.WithForecast {
+		ctx.WriteString(" WITH FORECAST")
+	}
 }
 
 // ShowHistogram represents a SHOW HISTOGRAM statement.
@@ -775,12 +798,27 @@ const (
 	// ScheduledSQLStatsCompactionExecutor is an executor responsible for the
 	// execution of the scheduled SQL Stats compaction.
 	ScheduledSQLStatsCompactionExecutor
+
+	// ScheduledChangefeedExecutor is an executor responsible for the execution
+	// of the scheduled changefeeds.
+	ScheduledChangefeedExecutor
+
+	// ScheduledExportExecutor is an executor responsible for the execution of
+	// the scheduled exports.
+	ScheduledExportExecutor
+
+	// ScheduledSQLExecutor is an executor responsible for the execution of
+	// the scheduled SQL statements.
+	ScheduledSQLExecutor
 )
 
 var scheduleExecutorInternalNames = map[ScheduledJobExecutorType]string{
 	InvalidExecutor:                     "unknown-executor",
 	ScheduledBackupExecutor:             "scheduled-backup-executor",
 	ScheduledSQLStatsCompactionExecutor: "scheduled-sql-stats-compaction-executor",
+	ScheduledChangefeedExecutor:         "scheduled-changefeed-executor",
+	ScheduledExportExecutor:             "scheduled-export-executor",
+	ScheduledSQLExecutor:                "scheduled-sql-executor",
 }
 
 // InternalName returns an internal executor name.
@@ -796,6 +834,12 @@ func (t ScheduledJobExecutorType) UserName() string {
 		return "BACKUP"
 	case ScheduledSQLStatsCompactionExecutor:
 		return "SQL STATISTICS"
+	case ScheduledChangefeedExecutor:
+		return "CHANGEFEED"
+	case ScheduledExportExecutor:
+		return "EXPORT"
+	case ScheduledSQLExecutor:
+		return "SQL"
 	}
 	return "unsupported-executor"
 }
@@ -25,17 +25,20 @@ type IsolationLevel int
 const (
 	UnspecifiedIsolation IsolationLevel = iota
 	SerializableIsolation
+	ReadCommittedIsolation
 )
 
 var isolationLevelNames = [...]string{
-	UnspecifiedIsolation:  "UNSPECIFIED",
-	SerializableIsolation: "SERIALIZABLE",
+	UnspecifiedIsolation:   "UNSPECIFIED",
+	SerializableIsolation:  "SERIALIZABLE",
+	ReadCommittedIsolation: "READ COMMITTED",
 }
 
 // IsolationLevelMap is a map from string isolation level name to isolation
 // level, in the lowercase format that set isolation_level supports.
 var IsolationLevelMap = map[string]IsolationLevel{
-	"serializable": SerializableIsolation,
+	"serializable":   SerializableIsolation,
+	"read committed": ReadCommittedIsolation,
 }
 
 func (i IsolationLevel) String() string {
@@ -130,6 +133,32 @@ func (d DeferrableMode) String() string {
 	return deferrableModeNames[d]
 }
 
+// ConstraintsMode holds the constraint checking mode set for a transaction
+// with SET CONSTRAINTS.
+type ConstraintsMode int
+
+// ConstraintsMode values.
+const (
+	// UnspecifiedConstraintsMode checks each deferrable constraint according
+	// to its INITIALLY DEFERRED or INITIALLY IMMEDIATE attribute.
+	UnspecifiedConstraintsMode ConstraintsMode = iota
+	ConstraintsDeferred
+	ConstraintsImmediate
+)
+
+var constraintsModeNames = [...]string{
+	UnspecifiedConstraintsMode: "UNSPECIFIED",
+	ConstraintsDeferred:        "DEFERRED",
+	ConstraintsImmediate:       "IMMEDIATE",
+}
+
+func (m ConstraintsMode) String() string {
+	if m < 0 || m > ConstraintsMode(len(constraintsModeNames)-1) {
+		return fmt.Sprintf("ConstraintsMode(%d)", m)
+	}
+	return constraintsModeNames[m]
+}
+
 // TransactionModes holds the transaction modes for a transaction.
 type TransactionModes struct {
 	Isolation     IsolationLevel
@@ -0,0 +1,258 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tree
+
+import (
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/lexbase"
+)
+
+// UDFDefinition is the definition of a user-defined SQL function overload. It
+// is set on the Overload of functions resolved from a function descriptor; the
+// optimizer inlines Body in place of the function call.
+type UDFDefinition struct {
+	// Body is the SQL text of the function body: a single SELECT statement,
+	// or an INSERT statement for trigger functions.
+	Body string
+	// ArgNames are the names of the arguments, in order. Unnamed arguments
+	// have an empty name and can only be referenced as $1, $2, etc.
+	ArgNames []string
+	// CalledOnNullInput is false for STRICT functions, which return NULL
+	// without evaluating the body when any argument is NULL.
+	CalledOnNullInput bool
+	// Trigger is true for trigger functions, which have no arguments and can
+	// only be executed by triggers. Their body is a SELECT or INSERT statement.
+	Trigger bool
+	// FunctionID and FunctionVersion identify the version of the function
+	// descriptor the overload was resolved from.
+	FunctionID      uint32
+	FunctionVersion uint64
+}
+
+// NewUDFFunctionDefinition returns the definition of a user-defined function
+// with the given overloads, which must all have a UDF definition. Unlike
+// builtins, the arguments of user-defined functions are not folded to NULL by
+// type checking, and calls are not counted in telemetry since their names are
+// not public.
+func NewUDFFunctionDefinition(name string, overloads []Overload) *FunctionDefinition {
+	def := &FunctionDefinition{
+		Name:       name,
+		Definition: make([]overloadImpl, len(overloads)),
+		FunctionProperties: FunctionProperties{
+			NullableArgs: true,
+		},
+	}
+	for i := range overloads {
+		def.Definition[i] = &overloads[i]
+	}
+	return def
+}
+
+// FuncArg is an argument of a function in a CREATE or DROP FUNCTION
+// statement.
+type FuncArg struct {
+	Name Name
+	Type ResolvableTypeReference
+}
+
+// Format implements the NodeFormatter interface.
+func (node *FuncArg) Format(ctx *FmtCtx) {
+	if node.Name != "" {
+		ctx.FormatNode(&node.Name)
+		ctx.WriteByte(' ')
+	}
+	ctx.FormatTypeReference(node.Type)
+}
+
+// FuncArgs is a list of function arguments.
+type FuncArgs []FuncArg
+
+// Format implements the NodeFormatter interface.
+func (node *FuncArgs) Format(ctx *FmtCtx) {
+	for i := range *node {
+		if i > 0 {
+			ctx.WriteString(", ")
+		}
+		ctx.FormatNode(&(*node)[i])
+	}
+}
+
+// FunctionOption is an option of a CREATE FUNCTION statement.
+type FunctionOption interface {
+	NodeFormatter
+	functionOption()
+}
+
+func (FunctionNullInputBehavior) functionOption() {}
+func (FunctionVolatility) functionOption()        {}
+func (FunctionLanguage) functionOption()          {}
+func (FunctionBodyStr) functionOption()           {}
+
+// FunctionNullInputBehavior is the CALLED ON NULL INPUT, RETURNS NULL ON NULL
+// INPUT or STRICT option of a function.
+type FunctionNullInputBehavior int
+
+// FunctionNullInputBehavior values.
+const (
+	FunctionCalledOnNullInput FunctionNullInputBehavior = iota
+	FunctionReturnsNullOnNullInput
+	FunctionStrict
+)
+
+// Format implements the NodeFormatter interface.
+func (node FunctionNullInputBehavior) Format(ctx *FmtCtx) {
+	switch node {
+	case FunctionCalledOnNullInput:
+		ctx.WriteString("CALLED ON NULL INPUT")
+	case FunctionReturnsNullOnNullInput:
+		ctx.WriteString("RETURNS NULL ON NULL INPUT")
+	case FunctionStrict:
+		ctx.WriteString("STRICT")
+	}
+}
+
+// FunctionVolatility is the IMMUTABLE, STABLE or VOLATILE option of a
+// function.
+type FunctionVolatility struct {
+	Volatility Volatility
+}
+
+// Format implements the NodeFormatter interface.
+func (node FunctionVolatility) Format(ctx *FmtCtx) {
+	ctx.WriteString(strings.ToUpper(node.Volatility.String()))
+}
+
+// FunctionLanguage is the LANGUAGE option of a function.
+type FunctionLanguage string
+
+// FunctionLangSQL is the only language supported for user-defined functions.
+const FunctionLangSQL FunctionLanguage = "sql"
+
+// Format implements the NodeFormatter interface.
+func (node FunctionLanguage) Format(ctx *FmtCtx) {
+	ctx.WriteString("LANGUAGE ")
+	if node == FunctionLangSQL {
+		ctx.WriteString("SQL")
+		return
+	}
+	lexbase.EncodeRestrictedSQLIdent(&ctx.Buffer, string(node), ctx.flags.EncodeFlags())
+}
+
+// FunctionBodyStr is the AS option of a function, which holds the function
+// body.
+type FunctionBodyStr string
+
+// Format implements the NodeFormatter interface.
+func (node FunctionBodyStr) Format(ctx *FmtCtx) {
+	ctx.WriteString("AS ")
+	if ctx.flags.HasFlags(FmtHideConstants) {
+		ctx.WriteString("'_'")
+	} else {
+		lexbase.EncodeSQLStringWithFlags(&ctx.Buffer, string(node), ctx.flags.EncodeFlags())
+	}
+}
+
+// FunctionOptions is a list of function options, in the order in which they
+// were specified.
+type FunctionOptions []FunctionOption
+
+// Format implements the NodeFormatter interface.
+func (node *FunctionOptions) Format(ctx *FmtCtx) {
+	for i, opt := range *node {
+		if i > 0 {
+			ctx.WriteByte(' ')
+		}
+		ctx.FormatNode(opt)
+	}
+}
+
+// CreateFunction represents a CREATE FUNCTION statement.
+type CreateFunction struct {
+	Name       *UnresolvedObjectName
+	Replace    bool
+	Args       FuncArgs
+	ReturnType ResolvableTypeReference
+	Options    FunctionOptions
+}
+
+var _ Statement = &CreateFunction{}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateFunction) Format(ctx *FmtCtx) {
+	ctx.WriteString("CREATE ")
+	if node.Replace {
+		ctx.WriteString("OR REPLACE ")
+	}
+	ctx.WriteString("FUNCTION ")
+	ctx.FormatNode(node.Name)
+	ctx.WriteByte('(')
+	ctx.FormatNode(&node.Args)
+	ctx.WriteString(") RETURNS ")
+	ctx.FormatTypeReference(node.ReturnType)
+	if len(node.Options) > 0 {
+		ctx.WriteByte(' ')
+		ctx.FormatNode(&node.Options)
+	}
+}
+
+// FuncObj identifies a function in a DROP FUNCTION statement. Args is nil if
+// no argument list was given, in which case the name must identify a single
+// function.
+type FuncObj struct {
+	Name *UnresolvedObjectName
+	Args FuncArgs
+}
+
+// Format implements the NodeFormatter interface.
+func (node *FuncObj) Format(ctx *FmtCtx) {
+	ctx.FormatNode(node.Name)
+	if node.Args != nil {
+		ctx.WriteByte('(')
+		ctx.FormatNode(&node.Args)
+		ctx.WriteByte(')')
+	}
+}
+
+// FuncObjs is a list of functions in a DROP FUNCTION statement.
+type FuncObjs []FuncObj
+
+// Format implements the NodeFormatter interface.
+func (node *FuncObjs) Format(ctx *FmtCtx) {
+	for i := range *node {
+		if i > 0 {
+			ctx.WriteString(", ")
+		}
+		ctx.FormatNode(&(*node)[i])
+	}
+}
+
+// DropFunction represents a DROP FUNCTION statement.
+type DropFunction struct {
+	Functions    FuncObjs
+	IfExists     bool
+	DropBehavior DropBehavior
+}
+
+var _ Statement = &DropFunction{}
+
+// Format implements the NodeFormatter interface.
+func (node *DropFunction) Format(ctx *FmtCtx) {
+	ctx.WriteString("DROP FUNCTION ")
+	if node.IfExists {
+		ctx.WriteString("IF EXISTS ")
+	}
+	ctx.FormatNode(&node.Functions)
+	if node.DropBehavior != DropDefault {
+		ctx.WriteByte(' ')
+		ctx.WriteString(node.DropBehavior.String())
+	}
+}
@@ -340,6 +340,44 @@ func (expr *IfExpr) Walk(v Visitor) Expr {
 	return expr
 }
 
+// copyNode makes a copy of this Expr without recursing in any child Exprs.
+func (expr *GroupingExpr) copyNode() *GroupingExpr {
+	exprCopy := *expr
+	return &exprCopy
+}
+
+// Walk implements the Expr interface.
+func (expr *GroupingExpr) Walk(v Visitor) Expr {
+	ret := expr
+	exprs, changed := walkExprSlice(v, expr.Exprs)
+	if changed {
+		if ret == expr {
+			ret = expr.copyNode()
+		}
+		ret.Exprs = exprs
+	}
+	return ret
+}
+
+// copyNode makes a copy of this Expr without recursing in any child Exprs.
+func (expr *GroupingSets) copyNode() *GroupingSets {
+	exprCopy := *expr
+	return &exprCopy
+}
+
+// Walk implements the Expr interface.
+func (expr *GroupingSets) Walk(v Visitor) Expr {
+	ret := expr
+	exprs, changed := walkExprSlice(v, expr.Exprs)
+	if changed {
+		if ret == expr {
+			ret = expr.copyNode()
+		}
+		ret.Exprs = exprs
+	}
+	return ret
+}
+
 // Walk implements the Expr interface.
 func (expr *IfErrExpr) Walk(v Visitor) Expr {
 	c, changedC := WalkExpr(v, expr.Cond)
@@ -0,0 +1,50 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+type setConstraintsNode struct {
+	mode tree.ConstraintsMode
+}
+
+// SetConstraints sets the checking mode of deferrable constraints for the
+// current transaction.
+func (p *planner) SetConstraints(ctx context.Context, n *tree.SetConstraints) (planNode, error) {
+	return &setConstraintsNode{mode: n.Mode}, nil
+}
+
+func (n *setConstraintsNode) startExec(params runParams) error {
+	if params.extendedEvalCtx.TxnImplicit {
+		// Postgres also only warns in this case.
+		params.p.BufferClientNotice(
+			params.ctx,
+			pgnotice.NewWithSeverityf("WARNING", "SET CONSTRAINTS can only be used in transaction blocks"),
+		)
+		return nil
+	}
+	params.extendedEvalCtx.DeferredChecks.mode = n.mode
+	if n.mode == tree.ConstraintsImmediate {
+		// Switching to IMMEDIATE validates any checks that were deferred so far
+		// in the transaction.
+		return params.p.runDeferredChecks(params.ctx)
+	}
+	return nil
+}
+
+func (n *setConstraintsNode) Next(params runParams) (bool, error) { return false, nil }
+func (n *setConstraintsNode) Values() tree.Datums                 { return nil }
+func (n *setConstraintsNode) Close(ctx context.Context)           {}
@@ -358,6 +358,11 @@ func showForeignKeyConstraint(
 		buf.WriteString(" ON UPDATE ")
 		buf.WriteString(fk.OnUpdate.String())
 	}
+	if fk.InitiallyDeferred {
+		buf.WriteString(" DEFERRABLE INITIALLY DEFERRED")
+	} else if fk.Deferrable {
+		buf.WriteString(" DEFERRABLE")
+	}
 	if fk.Validity != descpb.ConstraintValidity_Validated {
 		buf.WriteString(" NOT VALID")
 	}
@@ -598,6 +603,11 @@ func showConstraintClause(
 		}
 		f.WriteString(strings.Join(colNames, ", "))
 		f.WriteString(")")
+		if c.InitiallyDeferred {
+			f.WriteString(" DEFERRABLE INITIALLY DEFERRED")
+		} else if c.Deferrable {
+			f.WriteString(" DEFERRABLE")
+		}
 		if c.IsPartial() {
 			f.WriteString(" WHERE ")
 			pred, err := schemaexpr.FormatExprForDisplay(ctx, desc, c.Predicate, semaCtx, sessionData, tree.FmtParsable)
@@ -14,6 +14,7 @@ import (
 	"context"
 	encjson "encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
@@ -50,6 +51,19 @@ var showTableStatsColumnsAvgSizeVer = colinfo.ResultColumns{
 	{Name: "histogram_id", Typ: types.Int},
 }
 
+var showTableStatsColumnsPartialStatsVer = colinfo.ResultColumns{
+	{Name: "statistics_name", Typ: types.String},
+	{Name: "column_names", Typ: types.StringArray},
+	{Name: "created", Typ: types.Timestamp},
+	{Name: "row_count", Typ: types.Int},
+	{Name: "distinct_count", Typ: types.Int},
+	{Name: "null_count", Typ: types.Int},
+	{Name: "avg_size", Typ: types.Int},
+	{Name: "histogram_id", Typ: types.Int},
+	{Name: "partial_predicate", Typ: types.String},
+	{Name: "full_histogram_id", Typ: types.Int},
+}
+
 var showTableStatsJSONColumns = colinfo.ResultColumns{
 	{Name: "statistics", Typ: types.Jsonb},
 }
@@ -67,9 +81,13 @@ func (p *planner) ShowTableStats(ctx context.Context, n *tree.ShowTableStats) (p
 		return nil, err
 	}
 	avgSizeColVerActive := p.ExtendedEvalContext().ExecCfg.Settings.Version.IsActive(ctx, clusterversion.AlterSystemTableStatisticsAddAvgSizeCol)
-	columns := showTableStatsColumnsAvgSizeVer
+	partialStatsVerActive := avgSizeColVerActive &&
+		p.ExtendedEvalContext().ExecCfg.Settings.Version.IsActive(ctx, clusterversion.AlterSystemTableStatisticsAddPartialPredicateAndID)
+	columns := showTableStatsColumnsPartialStatsVer
 	if !avgSizeColVerActive {
 		columns = showTableStatsColumns
+	} else if !partialStatsVerActive {
+		columns = showTableStatsColumnsAvgSizeVer
 	}
 	if n.UsingJSON {
 		columns = showTableStatsJSONColumns
@@ -85,11 +103,16 @@ func (p *planner) ShowTableStats(ctx context.Context, n *tree.ShowTableStats) (p
 			//    "handle" which can be used with SHOW HISTOGRAM.
 			// TODO(yuzefovich): refactor the code to use the iterator API
 			// (currently it is not possible due to a panic-catcher below).
-			var avgSize string
+			var avgSize, partialStatsCols string
 			if avgSizeColVerActive {
 				avgSize = `
 					"avgSize",`
 			}
+			if partialStatsVerActive {
+				partialStatsCols = `,
+					"partialPredicate",
+					"fullStatisticID"`
+			}
 			stmt := fmt.Sprintf(`SELECT "statisticID",
 																				 name,
 																				 "columnIDs",
@@ -98,10 +121,10 @@ func (p *planner) ShowTableStats(ctx context.Context, n *tree.ShowTableStats) (p
 																				 "distinctCount",
 																				 "nullCount",
 																				 %s
-																				 histogram
+																				 histogram%s
 																	FROM system.table_statistics
 																	WHERE "tableID" = $1
-																	ORDER BY "createdAt"`, avgSize)
+																	ORDER BY "createdAt"`, avgSize, partialStatsCols)
 			rows, err := p.ExtendedEvalContext().ExecCfg.InternalExecutor.QueryBuffered(
 				ctx,
 				"read-table-stats",
@@ -123,6 +146,8 @@ func (p *planner) ShowTableStats(ctx context.Context, n *tree.ShowTableStats) (p
 				nullCountIdx
 				avgSizeIdx
 				histogramIdx
+				partialPredicateIdx
+				fullStatisticIDIdx
 				numCols
 			)
 
@@ -130,7 +155,48 @@ func (p *planner) ShowTableStats(ctx context.Context, n *tree.ShowTableStats) (p
 			nCols := numCols
 			if !avgSizeColVerActive {
 				histIdx = histogramIdx - 1
-				nCols = numCols - 1
+				nCols = numCols - 3
+			} else if !partialStatsVerActive {
+				nCols = numCols - 2
+			}
+
+			if n.WithForecast {
+				// Forecasts are not stored in system.table_statistics, so they are
+				// added to the rows read from it, in the same format.
+				tableStats, err := p.ExtendedEvalContext().ExecCfg.TableStatsCache.GetTableStats(ctx, desc)
+				if err != nil {
+					return nil, err
+				}
+				observed := make([]*stats.TableStatistic, 0, len(tableStats))
+				for _, stat := range tableStats {
+					if !stat.IsForecast() && !stat.IsMerged() {
+						observed = append(observed, stat)
+					}
+				}
+				for _, forecast := range stats.ForecastTableStatistics(ctx, observed) {
+					r := make(tree.Datums, nCols)
+					for j := range r {
+						r[j] = tree.DNull
+					}
+					r[nameIdx] = tree.NewDString(forecast.Name)
+					colIDs := tree.NewDArray(types.Int)
+					for _, colID := range forecast.ColumnIDs {
+						if err := colIDs.Append(tree.NewDInt(tree.DInt(colID))); err != nil {
+							return nil, err
+						}
+					}
+					r[columnIDsIdx] = colIDs
+					if r[createdAtIdx], err = tree.MakeDTimestamp(forecast.CreatedAt, time.Microsecond); err != nil {
+						return nil, err
+					}
+					r[rowCountIdx] = tree.NewDInt(tree.DInt(forecast.RowCount))
+					r[distinctCountIdx] = tree.NewDInt(tree.DInt(forecast.DistinctCount))
+					r[nullCountIdx] = tree.NewDInt(tree.DInt(forecast.NullCount))
+					if avgSizeColVerActive {
+						r[avgSizeIdx] = tree.NewDInt(tree.DInt(forecast.AvgSize))
+					}
+					rows = append(rows, r)
+				}
 			}
 
 			// Guard against crashes in the code below (e.g. #56356).
@@ -152,8 +218,15 @@ func (p *planner) ShowTableStats(ctx context.Context, n *tree.ShowTableStats) (p
 
 			v := p.newContainerValuesNode(columns, 0)
 			if n.UsingJSON {
-				result := make([]stats.JSONStatistic, len(rows))
-				for i, r := range rows {
+				result := make([]stats.JSONStatistic, 0, len(rows))
+				for _, r := range rows {
+					// Partial statistics cannot be injected back into the table, so
+					// they are not included.
+					if partialStatsVerActive && r[partialPredicateIdx] != tree.DNull {
+						continue
+					}
+					result = append(result, stats.JSONStatistic{})
+					i := len(result) - 1
 					result[i].CreatedAt = tree.AsStringWithFlags(r[createdAtIdx], tree.FmtBareStrings)
 					result[i].RowCount = (uint64)(*r[rowCountIdx].(*tree.DInt))
 					result[i].DistinctCount = (uint64)(*r[distinctCountIdx].(*tree.DInt))
@@ -210,7 +283,20 @@ func (p *planner) ShowTableStats(ctx context.Context, n *tree.ShowTableStats) (p
 				}
 
 				var res tree.Datums
-				if avgSizeColVerActive {
+				if partialStatsVerActive {
+					res = tree.Datums{
+						r[nameIdx],
+						colNames,
+						r[createdAtIdx],
+						r[rowCountIdx],
+						r[distinctCountIdx],
+						r[nullCountIdx],
+						r[avgSizeIdx],
+						histogramID,
+						r[partialPredicateIdx],
+						r[fullStatisticIDIdx],
+					}
+				} else if avgSizeColVerActive {
 					res = tree.Datums{
 						r[nameIdx],
 						colNames,
@@ -156,6 +156,12 @@ func NewUndefinedTypeError(name tree.NodeFormatter) error {
 	return pgerror.Newf(pgcode.UndefinedObject, "type %q does not exist", tree.ErrString(name))
 }
 
+// NewUndefinedFunctionError creates an error that represents a missing
+// user-defined function.
+func NewUndefinedFunctionError(name string) error {
+	return pgerror.Newf(pgcode.UndefinedFunction, "function %s does not exist", name)
+}
+
 // NewUndefinedRelationError creates an error that represents a missing database table or view.
 func NewUndefinedRelationError(name tree.NodeFormatter) error {
 	return pgerror.Newf(pgcode.UndefinedTable,
@@ -196,6 +202,8 @@ func MakeObjectAlreadyExistsError(collidingObject *descpb.Descriptor, name strin
 		return NewDatabaseAlreadyExistsError(name)
 	case *descpb.Descriptor_Schema:
 		return NewSchemaAlreadyExistsError(name)
+	case *descpb.Descriptor_Function:
+		return pgerror.Newf(pgcode.DuplicateFunction, "function %s already exists", name)
 	default:
 		return errors.AssertionFailedf("unknown type %T exists with name %v", collidingObject.Union, name)
 	}
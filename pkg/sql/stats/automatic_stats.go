@@ -17,6 +17,7 @@ import (
 	"math/rand"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
@@ -105,6 +106,48 @@ var AutomaticStatisticsMinStaleRows = func() *settings.IntSetting {
 	return s
 }()
 
+// AutomaticPartialStatisticsClusterMode controls the cluster setting for
+// enabling automatic collection of partial statistics on the extreme values of
+// indexed columns.
+var AutomaticPartialStatisticsClusterMode = settings.RegisterBoolSetting(
+	settings.TenantWritable,
+	"sql.stats.automatic_partial_collection.enabled",
+	"automatic partial statistics collection mode",
+	false,
+).WithPublic()
+
+// AutomaticPartialStatisticsFractionStaleRows controls the cluster setting for
+// the target fraction of rows in a table that should be stale before partial
+// statistics on that table are refreshed, in addition to the constant value
+// AutomaticPartialStatisticsMinStaleRows.
+var AutomaticPartialStatisticsFractionStaleRows = func() *settings.FloatSetting {
+	s := settings.RegisterFloatSetting(
+		settings.TenantWritable,
+		"sql.stats.automatic_partial_collection.fraction_stale_rows",
+		"target fraction of stale rows per table that will trigger a partial statistics refresh",
+		0.05,
+		settings.NonNegativeFloat,
+	)
+	s.SetVisibility(settings.Public)
+	return s
+}()
+
+// AutomaticPartialStatisticsMinStaleRows controls the cluster setting for the
+// target number of rows that should be updated before the partial statistics
+// on a table are refreshed, in addition to the fraction
+// AutomaticPartialStatisticsFractionStaleRows.
+var AutomaticPartialStatisticsMinStaleRows = func() *settings.IntSetting {
+	s := settings.RegisterIntSetting(
+		settings.TenantWritable,
+		"sql.stats.automatic_partial_collection.min_stale_rows",
+		"target minimum number of stale rows per table that will trigger a partial statistics refresh",
+		100,
+		settings.NonNegativeInt,
+	)
+	s.SetVisibility(settings.Public)
+	return s
+}()
+
 // DefaultRefreshInterval is the frequency at which the Refresher will check if
 // the stats for each table should be refreshed. It is mutable for testing.
 // NB: Updates to this value after Refresher.Start has been called will not
@@ -181,6 +224,15 @@ const (
 // AS OF SYSTEM TIME ‘-30s’ to minimize performance impact on running
 // transactions.
 //
+// If sql.stats.automatic_partial_collection.enabled is true and no full
+// refresh is triggered, the Refresher uses the same statistical approach (with
+// a smaller target fraction of stale rows) to decide whether to refresh the
+// partial statistics on the table instead, with CREATE STATISTICS ... USING
+// EXTREMES. Partial statistics only scan the values of indexed columns that
+// are outside the bounds of their histograms, so they are much cheaper to
+// collect, and they keep the statistics accurate for values that keep growing,
+// such as timestamps and sequence numbers.
+//
 // To avoid adding latency to SQL mutation operations, the Refresher is run
 // in one separate background thread per Server. SQL mutation operations signal
 // to the Refresher thread by calling NotifyMutation, which sends mutation
@@ -456,7 +508,9 @@ func (r *Refresher) maybeRefreshStats(
 	targetRows := int64(rowCount*AutomaticStatisticsFractionStaleRows.Get(&r.st.SV)) +
 		AutomaticStatisticsMinStaleRows.Get(&r.st.SV)
 	if !mustRefresh && rowsAffected < math.MaxInt32 && r.randGen.randInt(targetRows) >= rowsAffected {
-		// No refresh is happening this time.
+		// No full refresh is happening this time, but the partial statistics
+		// might be refreshed.
+		r.maybeRefreshPartialStats(ctx, tableID, rowCount, rowsAffected, asOf)
 		return
 	}
 
@@ -507,6 +561,52 @@ func (r *Refresher) refreshStats(ctx context.Context, tableID descpb.ID, asOf ti
 	return err
 }
 
+// maybeRefreshPartialStats refreshes the partial statistics on the given table
+// with a probability based on the number of rows affected, like the full
+// refresh in maybeRefreshStats. It does nothing if automatic partial
+// statistics collection is disabled.
+func (r *Refresher) maybeRefreshPartialStats(
+	ctx context.Context, tableID descpb.ID, rowCount float64, rowsAffected int64, asOf time.Duration,
+) {
+	if !AutomaticPartialStatisticsClusterMode.Get(&r.st.SV) || rowCount == 0 ||
+		!r.st.Version.IsActive(ctx, clusterversion.AlterSystemTableStatisticsAddPartialPredicateAndID) {
+		return
+	}
+	targetRows := int64(rowCount*AutomaticPartialStatisticsFractionStaleRows.Get(&r.st.SV)) +
+		AutomaticPartialStatisticsMinStaleRows.Get(&r.st.SV)
+	if r.randGen.randInt(targetRows) >= rowsAffected {
+		return
+	}
+
+	if err := r.refreshPartialStats(ctx, tableID, asOf); err != nil {
+		// Unlike full refreshes, partial refreshes are not rescheduled when
+		// another stats job is running: the next mutations on the table will
+		// trigger another one.
+		if !errors.Is(err, ConcurrentCreateStatsError) {
+			log.Warningf(ctx, "failed to create partial statistics on table %d: %v", tableID, err)
+		}
+	}
+}
+
+func (r *Refresher) refreshPartialStats(
+	ctx context.Context, tableID descpb.ID, asOf time.Duration,
+) error {
+	// Create partial statistics for all default columns on the given table.
+	_ /* rows */, err := r.ex.Exec(
+		ctx,
+		"create-partial-stats",
+		nil, /* txn */
+		fmt.Sprintf(
+			"CREATE STATISTICS %s FROM [%d] WITH OPTIONS USING EXTREMES THROTTLING %g AS OF SYSTEM TIME '-%s'",
+			jobspb.AutoPartialStatsName,
+			tableID,
+			AutomaticStatisticsMaxIdleTime.Get(&r.st.SV),
+			asOf.String(),
+		),
+	)
+	return err
+}
+
 // mostRecentAutomaticStat finds the most recent automatic statistic
 // (identified by the name AutoStatsName).
 func mostRecentAutomaticStat(tableStats []*TableStatistic) *TableStatistic {
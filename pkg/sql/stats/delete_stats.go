@@ -33,7 +33,8 @@ const (
 // DeleteOldStatsForColumns deletes old statistics from the
 // system.table_statistics table. For the given tableID and columnIDs,
 // DeleteOldStatsForColumns keeps the most recent keepCount automatic
-// statistics and deletes all the others.
+// statistics and deletes all the others, including all partial statistics
+// (which extend older full statistics).
 func DeleteOldStatsForColumns(
 	ctx context.Context,
 	executor sqlutil.InternalExecutor,
@@ -71,3 +72,32 @@ func DeleteOldStatsForColumns(
 	)
 	return err
 }
+
+// DeleteOldPartialStatsForColumns deletes all the partial statistics for the
+// given tableID and columnIDs from the system.table_statistics table. It is
+// called before inserting a new partial statistic, which supersedes them.
+func DeleteOldPartialStatsForColumns(
+	ctx context.Context,
+	executor sqlutil.InternalExecutor,
+	txn *kv.Txn,
+	tableID descpb.ID,
+	columnIDs []descpb.ColumnID,
+) error {
+	columnIDsVal := tree.NewDArray(types.Int)
+	for _, c := range columnIDs {
+		if err := columnIDsVal.Append(tree.NewDInt(tree.DInt(int(c)))); err != nil {
+			return err
+		}
+	}
+
+	_, err := executor.Exec(
+		ctx, "delete-partial-statistics", txn,
+		`DELETE FROM system.table_statistics
+               WHERE "tableID" = $1
+               AND "columnIDs" = $2
+               AND "partialPredicate" IS NOT NULL`,
+		tableID,
+		columnIDsVal,
+	)
+	return err
+}
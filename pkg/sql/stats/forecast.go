@@ -0,0 +1,478 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package stats
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc/keyside"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil/pgdate"
+	"github.com/cockroachdb/errors"
+)
+
+// UseStatisticsForecasts controls whether the optimizer uses statistics
+// forecasted from the history of statistics collected on each table.
+var UseStatisticsForecasts = settings.RegisterBoolSetting(
+	settings.TenantWritable,
+	"sql.stats.forecasts.enabled",
+	"when true, the optimizer uses table statistics forecasted from the history of collected statistics",
+	false,
+).WithPublic()
+
+const (
+	// minObservationsForForecast is the minimum number of full statistics on a
+	// set of columns needed to forecast statistics on those columns.
+	minObservationsForForecast = 3
+
+	// minGoodnessOfFit is the minimum coefficient of determination (R²) of the
+	// linear regression of the row counts needed to use a forecast. Tables with
+	// row counts that do not change linearly over time are not forecast.
+	minGoodnessOfFit = 0.95
+
+	// maxForecastHistogramBuckets is the maximum number of buckets of a
+	// forecasted histogram.
+	maxForecastHistogramBuckets = 200
+)
+
+// ForecastTableStatistics forecasts the statistics on each set of columns that
+// has at least minObservationsForForecast full statistics in stats. stats must
+// be ordered by CreatedAt, newest to oldest. Each forecast is reported as
+// created at the time of the newest statistic plus the average time between
+// the statistics on the same columns, which is the expected time of the next
+// refresh.
+//
+// Sets of columns for which no forecast can be made are skipped.
+func ForecastTableStatistics(ctx context.Context, stats []*TableStatistic) []*TableStatistic {
+	// Group the full statistics by set of columns, preserving their order.
+	observedByCols := make(map[string][]*TableStatistic)
+	var keys []string
+	for _, stat := range stats {
+		if stat.IsPartial() || stat.IsMerged() || stat.IsForecast() {
+			continue
+		}
+		key := columnSetKey(stat)
+		if _, ok := observedByCols[key]; !ok {
+			keys = append(keys, key)
+		}
+		observedByCols[key] = append(observedByCols[key], stat)
+	}
+
+	var forecasts []*TableStatistic
+	for _, key := range keys {
+		forecast, err := forecastColumnStatistics(observedByCols[key])
+		if err != nil {
+			log.VEventf(ctx, 2, "could not forecast statistics on columns %s: %v", key, err)
+			continue
+		}
+		forecasts = append(forecasts, forecast)
+	}
+	return forecasts
+}
+
+// forecastColumnStatistics forecasts the statistics on a set of columns from
+// the given full statistics on those columns, ordered newest to oldest. The
+// counts are forecast by a linear regression over time. The histogram is
+// forecast by a linear regression of each of its quantiles if the values of
+// the column can be converted to numbers, and otherwise the newest histogram
+// is scaled to the forecasted counts.
+func forecastColumnStatistics(observed []*TableStatistic) (*TableStatistic, error) {
+	n := len(observed)
+	if n < minObservationsForForecast {
+		return nil, errors.Newf(
+			"not enough observations: %d (need at least %d)", n, minObservationsForForecast,
+		)
+	}
+	latest := observed[0]
+	interval := latest.CreatedAt.Sub(observed[n-1].CreatedAt) / time.Duration(n-1)
+	if interval <= 0 {
+		return nil, errors.New("the observations were not made at increasing times")
+	}
+	forecastAt := latest.CreatedAt.Add(interval)
+
+	// The regressions are made relative to the time of the forecast, so that
+	// the forecasted values are the intercepts.
+	xs := make([]float64, n)
+	for i := range observed {
+		xs[i] = observed[i].CreatedAt.Sub(forecastAt).Seconds()
+	}
+	ys := make([]float64, n)
+	predict := func(val func(stat *TableStatistic) float64) (float64, float64) {
+		for i := range observed {
+			ys[i] = val(observed[i])
+		}
+		return linearRegression(xs, ys)
+	}
+
+	rowCount, r2 := predict(func(stat *TableStatistic) float64 { return float64(stat.RowCount) })
+	if r2 < minGoodnessOfFit {
+		return nil, errors.Newf("row counts do not follow a linear trend (R² = %.3f)", r2)
+	}
+	// Values that do not follow a linear trend are kept at their newest
+	// observation.
+	predictOrLatest := func(val func(stat *TableStatistic) float64) float64 {
+		if pred, r2 := predict(val); r2 >= minGoodnessOfFit {
+			return pred
+		}
+		return val(latest)
+	}
+	nullCount := predictOrLatest(func(stat *TableStatistic) float64 { return float64(stat.NullCount) })
+	distinctCount := predictOrLatest(func(stat *TableStatistic) float64 { return float64(stat.DistinctCount) })
+	avgSize := predictOrLatest(func(stat *TableStatistic) float64 { return float64(stat.AvgSize) })
+
+	// Make the forecasted counts consistent with each other. The distinct count
+	// includes NULL as a distinct value.
+	rowCount = math.Max(math.Round(rowCount), 0)
+	nullCount = math.Min(math.Max(math.Round(nullCount), 0), rowCount)
+	maxDistinctCount := rowCount - nullCount
+	if nullCount > 0 {
+		maxDistinctCount++
+	}
+	distinctCount = math.Min(math.Max(math.Round(distinctCount), 0), maxDistinctCount)
+	if distinctCount == 0 && rowCount > 0 {
+		distinctCount = 1
+	}
+	avgSize = math.Max(math.Round(avgSize), 0)
+
+	forecast := &TableStatistic{TableStatisticProto: TableStatisticProto{
+		TableID:       latest.TableID,
+		Name:          jobspb.ForecastStatsName,
+		ColumnIDs:     latest.ColumnIDs,
+		CreatedAt:     forecastAt,
+		RowCount:      uint64(rowCount),
+		DistinctCount: uint64(distinctCount),
+		NullCount:     uint64(nullCount),
+		AvgSize:       uint64(avgSize),
+	}}
+
+	if latest.HistogramData != nil && len(latest.HistogramData.Buckets) > 0 {
+		histData, err := forecastHistogram(observed, xs, forecast)
+		if err != nil {
+			// Fall back to the shape of the newest histogram.
+			histData = scaleHistogram(latest, forecast)
+		}
+		forecast.HistogramData = histData
+		if err := forecast.decodeHistogram(); err != nil {
+			return nil, err
+		}
+	}
+	return forecast, nil
+}
+
+// forecastHistogram forecasts the histogram of a single column by forecasting
+// the values of evenly spaced quantiles of its distribution. Each quantile is
+// predicted with a linear regression of the values of the same quantile in the
+// observed histograms. The buckets of the forecasted histogram are filled
+// using the counts of the forecast statistic.
+func forecastHistogram(
+	observed []*TableStatistic, xs []float64, forecast *TableStatistic,
+) (*HistogramData, error) {
+	latest := observed[0]
+	colType := latest.HistogramData.ColumnType
+	if len(latest.ColumnIDs) != 1 || !canConvertToFloat(colType) {
+		return nil, errors.New("the histogram cannot be forecast by quantiles")
+	}
+
+	numQuantiles := len(latest.HistogramData.Buckets)
+	if numQuantiles > maxForecastHistogramBuckets {
+		numQuantiles = maxForecastHistogramBuckets
+	}
+	if numQuantiles < 2 {
+		numQuantiles = 2
+	}
+	// quantileVals[i][k] is the value of quantile k/(numQuantiles-1) of the
+	// i-th observed histogram.
+	quantileVals := make([][]float64, len(observed))
+	for i, stat := range observed {
+		if stat.HistogramData == nil || len(stat.HistogramData.Buckets) == 0 ||
+			!stat.HistogramData.ColumnType.Equivalent(colType) {
+			return nil, errors.New("not all the observations have a histogram")
+		}
+		vals, err := histogramQuantiles(stat.HistogramData, numQuantiles)
+		if err != nil {
+			return nil, err
+		}
+		quantileVals[i] = vals
+	}
+
+	ys := make([]float64, len(observed))
+	predicted := make([]float64, numQuantiles)
+	for k := range predicted {
+		for i := range observed {
+			ys[i] = quantileVals[i][k]
+		}
+		predicted[k], _ = linearRegression(xs, ys)
+		// The quantile function must be non-decreasing.
+		if k > 0 && predicted[k] < predicted[k-1] {
+			predicted[k] = predicted[k-1]
+		}
+	}
+
+	nonNullRows := float64(forecast.RowCount - forecast.NullCount)
+	nonNullDistinct := float64(forecast.DistinctCount)
+	if forecast.NullCount > 0 {
+		nonNullDistinct--
+	}
+	rowsPerValue := 1.0
+	if nonNullDistinct > 0 {
+		rowsPerValue = math.Max(nonNullRows/nonNullDistinct, 1)
+	}
+	rowsPerBucket := nonNullRows / float64(numQuantiles-1)
+
+	// The lowest quantile is the upper bound of a first bucket that only holds
+	// the rows equal to it. Each following bucket holds the rows between two
+	// consecutive quantiles. Quantiles that round to the same value are merged
+	// into a single bucket.
+	var buckets []HistogramData_Bucket
+	var numEq float64
+	var prevKey []byte
+	for k, val := range predicted {
+		datum, err := datumFromFloat(colType, val)
+		if err != nil {
+			return nil, err
+		}
+		key, err := keyside.Encode(nil, datum, encoding.Ascending)
+		if err != nil {
+			return nil, err
+		}
+		rows := rowsPerBucket
+		if k == 0 {
+			rows = math.Min(rowsPerValue, rowsPerBucket)
+		} else if k == 1 {
+			rows -= math.Min(rowsPerValue, rowsPerBucket)
+		}
+		if prevKey != nil && string(key) == string(prevKey) {
+			numEq += rows
+			buckets[len(buckets)-1].NumEq = int64(math.Round(numEq))
+			continue
+		}
+		numEq = math.Min(rowsPerValue, rows)
+		numRange := rows - numEq
+		buckets = append(buckets, HistogramData_Bucket{
+			NumEq:         int64(math.Round(numEq)),
+			NumRange:      int64(math.Round(numRange)),
+			DistinctRange: numRange / rowsPerValue,
+			UpperBound:    key,
+		})
+		prevKey = key
+	}
+
+	return &HistogramData{
+		ColumnType: colType,
+		Buckets:    buckets,
+		Version:    histVersion,
+	}, nil
+}
+
+// scaleHistogram returns a copy of the histogram of the given statistic with
+// the counts scaled to the counts of the forecast statistic.
+func scaleHistogram(latest, forecast *TableStatistic) *HistogramData {
+	rowScale, distinctScale := 1.0, 1.0
+	if latest.RowCount > latest.NullCount {
+		rowScale = float64(forecast.RowCount-forecast.NullCount) /
+			float64(latest.RowCount-latest.NullCount)
+	}
+	if latest.DistinctCount > 0 {
+		distinctScale = float64(forecast.DistinctCount) / float64(latest.DistinctCount)
+	}
+	buckets := make([]HistogramData_Bucket, len(latest.HistogramData.Buckets))
+	for i, b := range latest.HistogramData.Buckets {
+		numRange := math.Round(float64(b.NumRange) * rowScale)
+		buckets[i] = HistogramData_Bucket{
+			NumEq:         int64(math.Round(float64(b.NumEq) * rowScale)),
+			NumRange:      int64(numRange),
+			DistinctRange: math.Min(b.DistinctRange*distinctScale, numRange),
+			UpperBound:    b.UpperBound,
+		}
+	}
+	return &HistogramData{
+		ColumnType: latest.HistogramData.ColumnType,
+		Buckets:    buckets,
+		Version:    latest.HistogramData.Version,
+	}
+}
+
+// histogramQuantiles returns the values of numQuantiles evenly spaced
+// quantiles of the distribution described by the given histogram, from the
+// minimum to the maximum value. The values in the range of each bucket are
+// assumed to be uniformly distributed.
+func histogramQuantiles(h *HistogramData, numQuantiles int) ([]float64, error) {
+	// Build the points of the piecewise linear quantile function, as pairs of
+	// cumulative fraction of the rows and value.
+	var total float64
+	for _, b := range h.Buckets {
+		total += float64(b.NumRange + b.NumEq)
+	}
+	if total <= 0 {
+		return nil, errors.New("the histogram is empty")
+	}
+	var a tree.DatumAlloc
+	fracs := make([]float64, 0, 2*len(h.Buckets)+1)
+	vals := make([]float64, 0, 2*len(h.Buckets)+1)
+	var cum float64
+	for i, b := range h.Buckets {
+		datum, _, err := keyside.Decode(&a, h.ColumnType, b.UpperBound, encoding.Ascending)
+		if err != nil {
+			return nil, err
+		}
+		val, err := datumToFloat(datum)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			// The values below the first upper bound are not known, so the
+			// range of the first bucket is treated as equal to it.
+			fracs = append(fracs, 0)
+			vals = append(vals, val)
+			cum += float64(b.NumRange)
+		} else {
+			cum += float64(b.NumRange)
+			fracs = append(fracs, cum/total)
+			vals = append(vals, val)
+		}
+		cum += float64(b.NumEq)
+		fracs = append(fracs, cum/total)
+		vals = append(vals, val)
+	}
+
+	quantiles := make([]float64, numQuantiles)
+	j := 0
+	for k := range quantiles {
+		q := float64(k) / float64(numQuantiles-1)
+		for j < len(fracs)-2 && fracs[j+1] < q {
+			j++
+		}
+		lo, hi := fracs[j], fracs[j+1]
+		if hi <= lo {
+			quantiles[k] = vals[j+1]
+			continue
+		}
+		t := math.Min(math.Max((q-lo)/(hi-lo), 0), 1)
+		quantiles[k] = vals[j] + t*(vals[j+1]-vals[j])
+	}
+	return quantiles, nil
+}
+
+// linearRegression fits a line to the points (xs[i], ys[i]) using the least
+// squares method, and returns its value at x = 0 along with the coefficient of
+// determination (R²) of the fit. If the ys are all equal, the fit is perfect.
+func linearRegression(xs, ys []float64) (intercept, r2 float64) {
+	n := float64(len(xs))
+	var sumX, sumY float64
+	for i := range xs {
+		sumX += xs[i]
+		sumY += ys[i]
+	}
+	meanX, meanY := sumX/n, sumY/n
+	var sxx, sxy, syy float64
+	for i := range xs {
+		dx, dy := xs[i]-meanX, ys[i]-meanY
+		sxx += dx * dx
+		sxy += dx * dy
+		syy += dy * dy
+	}
+	if sxx == 0 {
+		return meanY, 0
+	}
+	slope := sxy / sxx
+	intercept = meanY - slope*meanX
+	if syy == 0 {
+		return intercept, 1
+	}
+	return intercept, sxy * sxy / (sxx * syy)
+}
+
+// canConvertToFloat returns true if the values of the given type can be
+// converted to and from float64 with datumToFloat and datumFromFloat.
+func canConvertToFloat(typ *types.T) bool {
+	switch typ.Family() {
+	case types.IntFamily, types.FloatFamily, types.DecimalFamily, types.DateFamily,
+		types.TimestampFamily, types.TimestampTZFamily:
+		return true
+	}
+	return false
+}
+
+// datumToFloat converts a datum of a type for which canConvertToFloat returns
+// true to a float64. Dates are converted to days and timestamps to
+// microseconds since the Unix epoch.
+func datumToFloat(d tree.Datum) (float64, error) {
+	switch t := d.(type) {
+	case *tree.DInt:
+		return float64(*t), nil
+	case *tree.DFloat:
+		return float64(*t), nil
+	case *tree.DDecimal:
+		return t.Float64()
+	case *tree.DDate:
+		if !t.IsFinite() {
+			return 0, errors.New("cannot convert an infinite date")
+		}
+		return float64(t.UnixEpochDays()), nil
+	case *tree.DTimestamp:
+		return float64(t.UnixMicro()), nil
+	case *tree.DTimestampTZ:
+		return float64(t.UnixMicro()), nil
+	}
+	return 0, errors.Newf("cannot convert %s to a float", d.ResolvedType())
+}
+
+// datumFromFloat is the inverse of datumToFloat.
+func datumFromFloat(typ *types.T, val float64) (tree.Datum, error) {
+	if math.IsNaN(val) || math.IsInf(val, 0) {
+		return nil, errors.New("cannot convert a non-finite value")
+	}
+	switch typ.Family() {
+	case types.IntFamily:
+		if val >= math.MaxInt64 || val <= math.MinInt64 {
+			return nil, errors.New("value out of range")
+		}
+		return tree.NewDInt(tree.DInt(math.Round(val))), nil
+	case types.FloatFamily:
+		return tree.NewDFloat(tree.DFloat(val)), nil
+	case types.DecimalFamily:
+		var d tree.DDecimal
+		if _, err := d.SetFloat64(val); err != nil {
+			return nil, err
+		}
+		return &d, nil
+	case types.DateFamily:
+		date, err := pgdate.MakeDateFromUnixEpoch(int64(math.Round(val)))
+		if err != nil {
+			return nil, err
+		}
+		return tree.NewDDate(date), nil
+	case types.TimestampFamily:
+		if val >= math.MaxInt64 || val <= math.MinInt64 {
+			return nil, errors.New("value out of range")
+		}
+		return tree.MakeDTimestamp(timeFromMicros(val), time.Microsecond)
+	case types.TimestampTZFamily:
+		if val >= math.MaxInt64 || val <= math.MinInt64 {
+			return nil, errors.New("value out of range")
+		}
+		return tree.MakeDTimestampTZ(timeFromMicros(val), time.Microsecond)
+	}
+	return nil, errors.Newf("cannot convert a float to %s", typ)
+}
+
+// timeFromMicros returns the time that is the given number of microseconds
+// after the Unix epoch.
+func timeFromMicros(micros float64) time.Time {
+	return time.UnixMicro(int64(math.Round(micros))).UTC()
+}
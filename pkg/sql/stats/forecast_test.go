@@ -0,0 +1,104 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package stats
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestForecastTableStatistics(t *testing.T) {
+	ctx := context.Background()
+	t0 := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	// makeObservation returns a statistic on a column with the values
+	// 0..rowCount-1, collected hours after t0.
+	makeObservation := func(id uint64, hours int, rowCount uint64) *TableStatistic {
+		stat := makeTestStat(t, id, t0.Add(time.Duration(hours)*time.Hour), rowCount, rowCount, 0, []testBucket{
+			{upper: 0, numEq: 1},
+			{
+				upper:         int64(rowCount) - 1,
+				numEq:         1,
+				numRange:      int64(rowCount) - 2,
+				distinctRange: float64(rowCount) - 2,
+			},
+		})
+		stat.Name = "__auto__"
+		return stat
+	}
+
+	observed := []*TableStatistic{
+		makeObservation(3, 2, 300),
+		makeObservation(2, 1, 200),
+		makeObservation(1, 0, 100),
+	}
+	forecasts := ForecastTableStatistics(ctx, observed)
+	if len(forecasts) != 1 {
+		t.Fatalf("expected 1 forecast, got %d", len(forecasts))
+	}
+	f := forecasts[0]
+	if !f.IsForecast() {
+		t.Fatalf("expected a forecast, got %q", f.Name)
+	}
+	if expected := t0.Add(3 * time.Hour); !f.CreatedAt.Equal(expected) {
+		t.Errorf("expected the forecast at %s, got %s", expected, f.CreatedAt)
+	}
+	if f.RowCount != 400 || f.DistinctCount != 400 || f.NullCount != 0 {
+		t.Errorf("unexpected counts: rows=%d distinct=%d nulls=%d", f.RowCount, f.DistinctCount, f.NullCount)
+	}
+	if len(f.Histogram) != 2 {
+		t.Fatalf("expected 2 buckets, got %d", len(f.Histogram))
+	}
+	if s := f.Histogram[0].UpperBound.String(); s != "0" {
+		t.Errorf("expected the lowest upper bound to be 0, got %s", s)
+	}
+	if s := f.Histogram[1].UpperBound.String(); s != "399" {
+		t.Errorf("expected the highest upper bound to be 399, got %s", s)
+	}
+	var total float64
+	for _, b := range f.Histogram {
+		total += b.NumEq + b.NumRange
+	}
+	if total != 400 {
+		t.Errorf("expected the histogram to hold 400 rows, got %v", total)
+	}
+
+	// Two observations are not enough for a forecast.
+	if forecasts := ForecastTableStatistics(ctx, observed[:2]); len(forecasts) != 0 {
+		t.Errorf("expected no forecasts, got %d", len(forecasts))
+	}
+
+	// Row counts that do not follow a linear trend are not forecast.
+	observed[1].RowCount = 1000
+	if forecasts := ForecastTableStatistics(ctx, observed); len(forecasts) != 0 {
+		t.Errorf("expected no forecasts, got %d", len(forecasts))
+	}
+}
+
+func TestLinearRegression(t *testing.T) {
+	testCases := []struct {
+		xs, ys    []float64
+		intercept float64
+		r2        float64
+	}{
+		{xs: []float64{-3, -2, -1}, ys: []float64{1, 2, 3}, intercept: 4, r2: 1},
+		{xs: []float64{-3, -2, -1}, ys: []float64{5, 5, 5}, intercept: 5, r2: 1},
+		{xs: []float64{-3, -2, -1}, ys: []float64{1, 3, 1}, intercept: 5.0 / 3, r2: 0},
+	}
+	for i, tc := range testCases {
+		intercept, r2 := linearRegression(tc.xs, tc.ys)
+		if math.Abs(intercept-tc.intercept) > 1e-9 || math.Abs(r2-tc.r2) > 1e-9 {
+			t.Errorf("test case %d: expected (%v, %v), got (%v, %v)", i, tc.intercept, tc.r2, intercept, r2)
+		}
+	}
+}
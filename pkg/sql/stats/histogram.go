@@ -32,6 +32,15 @@ var HistogramClusterMode = settings.RegisterBoolSetting(
 	true,
 ).WithPublic()
 
+// MultiColumnHistogramClusterMode controls the cluster setting for enabling
+// histogram collection on multi-column statistics.
+var MultiColumnHistogramClusterMode = settings.RegisterBoolSetting(
+	settings.TenantWritable,
+	"sql.stats.multi_column_histogram_collection.enabled",
+	"multi-column histogram collection mode",
+	false,
+).WithPublic()
+
 // HistogramVersion identifies histogram versions.
 type HistogramVersion uint32
 
@@ -0,0 +1,156 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package stats
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+)
+
+// IsPartial returns true if the statistic was collected on a subset of the
+// rows of the table, such as with CREATE STATISTICS ... USING EXTREMES.
+func (tabStat *TableStatistic) IsPartial() bool {
+	return tabStat.PartialPredicate != ""
+}
+
+// IsMerged returns true if the statistic is the result of merging a partial
+// statistic into a full statistic.
+func (tabStat *TableStatistic) IsMerged() bool {
+	return tabStat.Name == jobspb.MergedStatsName
+}
+
+// IsForecast returns true if the statistic is a forecast.
+func (tabStat *TableStatistic) IsForecast() bool {
+	return tabStat.Name == jobspb.ForecastStatsName
+}
+
+// MergedStatistics returns the statistics that result from merging each of the
+// partial statistics in stats into the full statistic that it extends. stats
+// must be ordered by CreatedAt, newest to oldest.
+//
+// For each set of columns, only the newest partial statistic is merged, and
+// only if it extends the newest full statistic on the same columns. Partial
+// statistics that cannot be merged are ignored.
+func MergedStatistics(ctx context.Context, stats []*TableStatistic) []*TableStatistic {
+	// Find the newest full statistic and the newest partial statistic for each
+	// set of columns.
+	fullStats := make(map[string]*TableStatistic)
+	partialStats := make(map[string]*TableStatistic)
+	var keys []string
+	for _, stat := range stats {
+		if stat.IsMerged() || stat.IsForecast() {
+			continue
+		}
+		key := columnSetKey(stat)
+		if stat.IsPartial() {
+			if _, ok := partialStats[key]; !ok {
+				partialStats[key] = stat
+				keys = append(keys, key)
+			}
+			continue
+		}
+		if _, ok := fullStats[key]; !ok {
+			fullStats[key] = stat
+		}
+	}
+
+	var merged []*TableStatistic
+	for _, key := range keys {
+		partial, full := partialStats[key], fullStats[key]
+		if full == nil || partial.FullStatisticID != full.StatisticID ||
+			!partial.CreatedAt.After(full.CreatedAt) {
+			continue
+		}
+		stat, err := mergePartialStatistic(full, partial)
+		if err != nil {
+			log.VEventf(ctx, 2, "could not merge partial statistic %d: %v", partial.StatisticID, err)
+			continue
+		}
+		merged = append(merged, stat)
+	}
+	return merged
+}
+
+// mergePartialStatistic merges the given partial statistic, which was
+// collected on the rows outside the bounds of the histogram of the given full
+// statistic, into the full statistic. The merged statistic is reported as
+// created at the time of the partial statistic.
+func mergePartialStatistic(full, partial *TableStatistic) (*TableStatistic, error) {
+	if len(full.ColumnIDs) != 1 {
+		return nil, errors.New("only single-column partial statistics can be merged")
+	}
+	if full.HistogramData == nil || len(full.HistogramData.Buckets) == 0 {
+		return nil, errors.New("the full statistic does not have a histogram")
+	}
+	fullBuckets := full.HistogramData.Buckets
+	lo := fullBuckets[0].UpperBound
+	hi := fullBuckets[len(fullBuckets)-1].UpperBound
+
+	// The upper bounds of the buckets are key encodings in ascending order, so
+	// they can be compared as bytes. The partial statistic only contains values
+	// below the lowest bound and above the highest bound of the full histogram.
+	var partialBuckets []HistogramData_Bucket
+	if partial.HistogramData != nil {
+		partialBuckets = partial.HistogramData.Buckets
+	}
+	buckets := make([]HistogramData_Bucket, 0, len(fullBuckets)+len(partialBuckets))
+	for _, b := range partialBuckets {
+		if bytes.Compare(b.UpperBound, lo) < 0 {
+			buckets = append(buckets, b)
+		}
+	}
+	buckets = append(buckets, fullBuckets...)
+	for _, b := range partialBuckets {
+		if bytes.Compare(b.UpperBound, hi) > 0 {
+			buckets = append(buckets, b)
+		}
+	}
+
+	merged := &TableStatistic{TableStatisticProto: TableStatisticProto{
+		TableID:       full.TableID,
+		StatisticID:   full.StatisticID,
+		Name:          jobspb.MergedStatsName,
+		ColumnIDs:     full.ColumnIDs,
+		CreatedAt:     partial.CreatedAt,
+		RowCount:      full.RowCount + partial.RowCount,
+		DistinctCount: full.DistinctCount + partial.DistinctCount,
+		// The partial statistic does not scan the NULL values.
+		NullCount: full.NullCount,
+		HistogramData: &HistogramData{
+			ColumnType: full.HistogramData.ColumnType,
+			Buckets:    buckets,
+			Version:    full.HistogramData.Version,
+		},
+	}}
+	if merged.RowCount > 0 {
+		merged.AvgSize = (full.AvgSize*full.RowCount + partial.AvgSize*partial.RowCount) /
+			merged.RowCount
+	}
+	if err := merged.decodeHistogram(); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+// columnSetKey returns a key that identifies the set of columns of the given
+// statistic.
+func columnSetKey(stat *TableStatistic) string {
+	var cols util.FastIntSet
+	for _, c := range stat.ColumnIDs {
+		cols.Add(int(c))
+	}
+	return cols.String()
+}
@@ -0,0 +1,130 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package stats
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc/keyside"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+)
+
+// testBucket describes a histogram bucket on an INT column.
+type testBucket struct {
+	upper         int64
+	numEq         int64
+	numRange      int64
+	distinctRange float64
+}
+
+func makeTestStat(
+	t *testing.T,
+	id uint64,
+	createdAt time.Time,
+	rowCount, distinctCount, nullCount uint64,
+	buckets []testBucket,
+) *TableStatistic {
+	stat := &TableStatistic{TableStatisticProto: TableStatisticProto{
+		TableID:       100,
+		StatisticID:   id,
+		ColumnIDs:     []descpb.ColumnID{1},
+		CreatedAt:     createdAt,
+		RowCount:      rowCount,
+		DistinctCount: distinctCount,
+		NullCount:     nullCount,
+	}}
+	if buckets == nil {
+		return stat
+	}
+	stat.HistogramData = &HistogramData{ColumnType: types.Int, Version: histVersion}
+	for _, b := range buckets {
+		key, err := keyside.Encode(nil, tree.NewDInt(tree.DInt(b.upper)), encoding.Ascending)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stat.HistogramData.Buckets = append(stat.HistogramData.Buckets, HistogramData_Bucket{
+			NumEq:         b.numEq,
+			NumRange:      b.numRange,
+			DistinctRange: b.distinctRange,
+			UpperBound:    key,
+		})
+	}
+	if err := stat.decodeHistogram(); err != nil {
+		t.Fatal(err)
+	}
+	return stat
+}
+
+func TestMergedStatistics(t *testing.T) {
+	ctx := context.Background()
+	t0 := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	full := makeTestStat(t, 1, t0, 105, 11, 5, []testBucket{
+		{upper: 10, numEq: 10},
+		{upper: 20, numEq: 10, numRange: 80, distinctRange: 8},
+	})
+	partial := makeTestStat(t, 2, t0.Add(time.Hour), 30, 3, 0, []testBucket{
+		{upper: 5, numEq: 10},
+		{upper: 25, numEq: 10},
+		{upper: 30, numEq: 10},
+	})
+	partial.Name = "__auto_partial__"
+	partial.PartialPredicate = "(a < 10) OR (a > 20)"
+	partial.FullStatisticID = full.StatisticID
+
+	merged := MergedStatistics(ctx, []*TableStatistic{partial, full})
+	if len(merged) != 1 {
+		t.Fatalf("expected 1 merged statistic, got %d", len(merged))
+	}
+	m := merged[0]
+	if !m.IsMerged() || m.IsPartial() {
+		t.Fatalf("expected a merged statistic, got %q", m.Name)
+	}
+	if !m.CreatedAt.Equal(partial.CreatedAt) {
+		t.Errorf("expected the merged statistic to be created at %s, got %s", partial.CreatedAt, m.CreatedAt)
+	}
+	if m.RowCount != 135 || m.DistinctCount != 14 || m.NullCount != 5 {
+		t.Errorf("unexpected counts: rows=%d distinct=%d nulls=%d", m.RowCount, m.DistinctCount, m.NullCount)
+	}
+
+	// The NULL bucket of the full statistic comes first, followed by the
+	// buckets below and above the bounds of the full histogram.
+	expected := []string{"NULL", "5", "10", "20", "25", "30"}
+	if len(m.Histogram) != len(expected) {
+		t.Fatalf("expected %d buckets, got %d", len(expected), len(m.Histogram))
+	}
+	for i, b := range m.Histogram {
+		if s := b.UpperBound.String(); s != expected[i] {
+			t.Errorf("bucket %d: expected upper bound %s, got %s", i, expected[i], s)
+		}
+	}
+
+	// A partial statistic that extends an older full statistic is not merged.
+	newer := makeTestStat(t, 3, t0.Add(30*time.Minute), 110, 11, 5, []testBucket{
+		{upper: 10, numEq: 10},
+		{upper: 20, numEq: 10, numRange: 85, distinctRange: 8},
+	})
+	if merged := MergedStatistics(ctx, []*TableStatistic{partial, newer, full}); len(merged) != 0 {
+		t.Errorf("expected no merged statistics, got %d", len(merged))
+	}
+
+	// A partial statistic that is older than the full statistic it extends is
+	// not merged either.
+	partial.CreatedAt = t0.Add(-time.Hour)
+	if merged := MergedStatistics(ctx, []*TableStatistic{full, partial}); len(merged) != 0 {
+		t.Errorf("expected no merged statistics, got %d", len(merged))
+	}
+}
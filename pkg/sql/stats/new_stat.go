@@ -21,10 +21,16 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/errors"
 )
 
 // InsertNewStats inserts a slice of statistics at the current time into the
 // system table.
+//
+// The inserted statistics get new statistic IDs, so the FullStatisticID of a
+// partial statistic is remapped to the new ID of the full statistic that it
+// extends. Partial statistics whose full statistic is not in the slice, or
+// which cannot be stored at the current cluster version, are skipped.
 func InsertNewStats(
 	ctx context.Context,
 	settings *cluster.Settings,
@@ -32,30 +38,53 @@ func InsertNewStats(
 	txn *kv.Txn,
 	tableStats []*TableStatisticProto,
 ) error {
-	var err error
-	for _, statistic := range tableStats {
-		err = InsertNewStat(
-			ctx,
-			settings,
-			executor,
-			txn,
-			statistic.TableID,
-			statistic.Name,
-			statistic.ColumnIDs,
-			int64(statistic.RowCount),
-			int64(statistic.DistinctCount),
-			int64(statistic.NullCount),
-			int64(statistic.AvgSize),
-			statistic.HistogramData,
-		)
-		if err != nil {
-			return err
+	newIDs := make(map[uint64]uint64, len(tableStats))
+	// Insert the full statistics before the partial ones, so that the new IDs
+	// of the full statistics are known.
+	for _, partial := range []bool{false, true} {
+		if partial && !settings.Version.IsActive(
+			ctx, clusterversion.AlterSystemTableStatisticsAddPartialPredicateAndID,
+		) {
+			break
+		}
+		for _, statistic := range tableStats {
+			if (statistic.PartialPredicate != "") != partial {
+				continue
+			}
+			var fullStatisticID uint64
+			if partial {
+				var ok bool
+				if fullStatisticID, ok = newIDs[statistic.FullStatisticID]; !ok {
+					continue
+				}
+			}
+			statisticID, err := insertNewStat(
+				ctx,
+				settings,
+				executor,
+				txn,
+				statistic.TableID,
+				statistic.Name,
+				statistic.ColumnIDs,
+				int64(statistic.RowCount),
+				int64(statistic.DistinctCount),
+				int64(statistic.NullCount),
+				int64(statistic.AvgSize),
+				statistic.HistogramData,
+				statistic.PartialPredicate,
+				fullStatisticID,
+			)
+			if err != nil {
+				return err
+			}
+			newIDs[statistic.StatisticID] = statisticID
 		}
 	}
 	return nil
 }
 
-// InsertNewStat inserts a new statistic in the system table.
+// InsertNewStat inserts a new statistic in the system table. partialPredicate
+// and fullStatisticID are only set for partial statistics.
 //
 // The stats cache will automatically update asynchronously (as well as the
 // stats caches on all other nodes).
@@ -69,24 +98,54 @@ func InsertNewStat(
 	columnIDs []descpb.ColumnID,
 	rowCount, distinctCount, nullCount, avgSize int64,
 	h *HistogramData,
+	partialPredicate string,
+	fullStatisticID uint64,
 ) error {
+	_, err := insertNewStat(
+		ctx, settings, executor, txn, tableID, name, columnIDs,
+		rowCount, distinctCount, nullCount, avgSize, h, partialPredicate, fullStatisticID,
+	)
+	return err
+}
+
+// insertNewStat is like InsertNewStat, but it also returns the ID of the new
+// statistic. The ID is only returned once the cluster version supports partial
+// statistics, since it is only needed to refer to the statistic from a partial
+// statistic; otherwise it is zero.
+func insertNewStat(
+	ctx context.Context,
+	settings *cluster.Settings,
+	executor sqlutil.InternalExecutor,
+	txn *kv.Txn,
+	tableID descpb.ID,
+	name string,
+	columnIDs []descpb.ColumnID,
+	rowCount, distinctCount, nullCount, avgSize int64,
+	h *HistogramData,
+	partialPredicate string,
+	fullStatisticID uint64,
+) (statisticID uint64, _ error) {
 	// We must pass a nil interface{} if we want to insert a NULL.
-	var nameVal, histogramVal interface{}
+	var nameVal, histogramVal, partialPredicateVal, fullStatisticIDVal interface{}
 	if name != "" {
 		nameVal = name
 	}
+	if partialPredicate != "" {
+		partialPredicateVal = partialPredicate
+		fullStatisticIDVal = int64(fullStatisticID)
+	}
 	if h != nil {
 		var err error
 		histogramVal, err = protoutil.Marshal(h)
 		if err != nil {
-			return err
+			return 0, err
 		}
 	}
 
 	columnIDsVal := tree.NewDArray(types.Int)
 	for _, c := range columnIDs {
 		if err := columnIDsVal.Append(tree.NewDInt(tree.DInt(int(c)))); err != nil {
-			return err
+			return 0, err
 		}
 	}
 	if !settings.Version.IsActive(ctx, clusterversion.AlterSystemTableStatisticsAddAvgSizeCol) {
@@ -109,11 +168,17 @@ func InsertNewStat(
 			nullCount,
 			histogramVal,
 		)
-		return err
+		return 0, err
 	}
-	_, err := executor.Exec(
-		ctx, "insert-statistic", txn,
-		`INSERT INTO system.table_statistics (
+	if !settings.Version.IsActive(ctx, clusterversion.AlterSystemTableStatisticsAddPartialPredicateAndID) {
+		if partialPredicate != "" {
+			return 0, errors.AssertionFailedf(
+				"partial statistics are not supported until the cluster version is upgraded",
+			)
+		}
+		_, err := executor.Exec(
+			ctx, "insert-statistic", txn,
+			`INSERT INTO system.table_statistics (
 					"tableID",
 					"name",
 					"columnIDs",
@@ -123,6 +188,32 @@ func InsertNewStat(
 					"avgSize",
 					histogram
 				) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+			tableID,
+			nameVal,
+			columnIDsVal,
+			rowCount,
+			distinctCount,
+			nullCount,
+			avgSize,
+			histogramVal,
+		)
+		return 0, err
+	}
+	row, err := executor.QueryRow(
+		ctx, "insert-statistic", txn,
+		`INSERT INTO system.table_statistics (
+					"tableID",
+					"name",
+					"columnIDs",
+					"rowCount",
+					"distinctCount",
+					"nullCount",
+					"avgSize",
+					histogram,
+					"partialPredicate",
+					"fullStatisticID"
+				) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+				RETURNING "statisticID"`,
 		tableID,
 		nameVal,
 		columnIDsVal,
@@ -131,6 +222,11 @@ func InsertNewStat(
 		nullCount,
 		avgSize,
 		histogramVal,
+		partialPredicateVal,
+		fullStatisticIDVal,
 	)
-	return err
+	if err != nil {
+		return 0, err
+	}
+	return uint64(tree.MustBeDInt(row[0])), nil
 }
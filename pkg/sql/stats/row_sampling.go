@@ -16,10 +16,12 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/sql/memsize"
 	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc/keyside"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/mon"
 	"github.com/cockroachdb/errors"
 )
@@ -239,6 +241,56 @@ func (sr *SampleReservoir) GetNonNullDatums(
 	return
 }
 
+// GetNonNullKeys returns the concatenated ascending key encodings of the values
+// of the specified columns, for the samples in which none of the columns is
+// null. The keys sort in the same order as the tuples of values, so they can
+// be used to build a histogram on multiple columns. The capacity of the
+// reservoir (K) will shrink if we hit a memory limit while building this
+// return slice. If the capacity goes below minNumSamples, GetNonNullKeys will
+// return an error.
+func (sr *SampleReservoir) GetNonNullKeys(
+	ctx context.Context, memAcc *mon.BoundAccount, colIdxs []int,
+) (values tree.Datums, err error) {
+	err = sr.retryMaybeResize(ctx, func() error {
+		// Account for the memory we'll use copying the samples into values. The
+		// memory used by the keys is accounted for below.
+		if memAcc != nil {
+			if err := memAcc.Grow(ctx, memsize.DatumOverhead*int64(len(sr.samples))); err != nil {
+				return err
+			}
+		}
+		values = make(tree.Datums, 0, len(sr.samples))
+	SamplesLoop:
+		for _, sample := range sr.samples {
+			var key []byte
+			for _, colIdx := range colIdxs {
+				ed := &sample.Row[colIdx]
+				if ed.Datum == nil {
+					values = nil
+					return errors.AssertionFailedf("value in column %d not decoded", colIdx)
+				}
+				if ed.IsNull() {
+					continue SamplesLoop
+				}
+				var err error
+				if key, err = keyside.Encode(key, ed.Datum, encoding.Ascending); err != nil {
+					values = nil
+					return err
+				}
+			}
+			if memAcc != nil {
+				if err := memAcc.Grow(ctx, int64(len(key))); err != nil {
+					values = nil
+					return err
+				}
+			}
+			values = append(values, tree.NewDBytes(tree.DBytes(key)))
+		}
+		return nil
+	})
+	return
+}
+
 func (sr *SampleReservoir) copyRow(
 	ctx context.Context, evalCtx *tree.EvalContext, dst, src rowenc.EncDatumRow,
 ) error {
@@ -13,6 +13,7 @@ package stats
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 
 	"github.com/cockroachdb/cockroach/pkg/clusterversion"
@@ -133,6 +134,13 @@ func NewTableStatisticsCache(
 		ShouldEvict: func(s int, key, value interface{}) bool { return s > cacheSize },
 	})
 
+	// Forecasts are added to the statistics when they are read from the system
+	// table, so the cached statistics are discarded when forecasts are enabled
+	// or disabled.
+	UseStatisticsForecasts.SetOnChange(&settings.SV, func(ctx context.Context) {
+		tableStatsCache.clear()
+	})
+
 	// Set up a range feed to watch for updates to system.table_statistics.
 
 	statsTablePrefix := codec.TablePrefix(keys.TableStatisticsTableID)
@@ -210,6 +218,10 @@ func decodeTableStatisticsKV(
 // silently ignores any statistics that can't be decoded (e.g. because
 // user-defined types don't exit).
 //
+// Partial statistics are not returned; instead, they are merged into the full
+// statistics that they extend. Forecasted statistics are returned if
+// sql.stats.forecasts.enabled is true.
+//
 // The statistics are ordered by their CreatedAt time (newest-to-oldest).
 func (sc *TableStatisticsCache) GetTableStats(
 	ctx context.Context, table catalog.TableDescriptor,
@@ -220,6 +232,20 @@ func (sc *TableStatisticsCache) GetTableStats(
 	return sc.getTableStatsFromCache(ctx, table.GetID())
 }
 
+// GetStoredTableStats is like GetTableStats, but it reads the statistics
+// directly from system.table_statistics and returns them as they are stored:
+// partial statistics are not merged into the full statistics that they extend,
+// and forecasts are not added. It is used to back up the statistics of a
+// table.
+func (sc *TableStatisticsCache) GetStoredTableStats(
+	ctx context.Context, table catalog.TableDescriptor,
+) ([]*TableStatistic, error) {
+	if !hasStatistics(table) {
+		return nil, nil
+	}
+	return sc.getStoredTableStatsFromDB(ctx, table.GetID())
+}
+
 // hasStatistics returns true if the table can have statistics collected for it.
 func hasStatistics(table catalog.TableDescriptor) bool {
 	if catalog.IsSystemDescriptor(table) {
@@ -417,6 +443,13 @@ func (sc *TableStatisticsCache) refreshTableStats(
 	}()
 }
 
+// clear evicts the cached statistics for all the tables.
+func (sc *TableStatisticsCache) clear() {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.mu.cache.Clear()
+}
+
 // InvalidateTableStats invalidates the cached statistics for the given table ID.
 func (sc *TableStatisticsCache) InvalidateTableStats(ctx context.Context, tableID descpb.ID) {
 	log.VEventf(ctx, 1, "evicting statistics for table %d", tableID)
@@ -436,13 +469,15 @@ const (
 	nullCountIndex
 	avgSizeIndex
 	histogramIndex
+	partialPredicateIndex
+	fullStatisticIDIndex
 	statsLen
 )
 
 // parseStats converts the given datums to a TableStatistic object. It might
 // need to run a query to get user defined type metadata.
 func (sc *TableStatisticsCache) parseStats(
-	ctx context.Context, datums tree.Datums, avgSizeColVerActive bool,
+	ctx context.Context, datums tree.Datums, avgSizeColVerActive, partialStatsVerActive bool,
 ) (*TableStatistic, error) {
 	if datums == nil || datums.Len() == 0 {
 		return nil, nil
@@ -452,7 +487,9 @@ func (sc *TableStatisticsCache) parseStats(
 	numStats := statsLen
 	if !avgSizeColVerActive {
 		hgIndex = histogramIndex - 1
-		numStats = statsLen - 1
+		numStats = statsLen - 3
+	} else if !partialStatsVerActive {
+		numStats = statsLen - 2
 	}
 
 	// Validate the input length.
@@ -492,6 +529,26 @@ func (sc *TableStatisticsCache) parseStats(
 			},
 		)
 	}
+	if avgSizeColVerActive && partialStatsVerActive {
+		expectedTypes = append(expectedTypes,
+			struct {
+				fieldName    string
+				fieldIndex   int
+				expectedType *types.T
+				nullable     bool
+			}{
+				"partialPredicate", partialPredicateIndex, types.String, true,
+			},
+			struct {
+				fieldName    string
+				fieldIndex   int
+				expectedType *types.T
+				nullable     bool
+			}{
+				"fullStatisticID", fullStatisticIDIndex, types.Int, true,
+			},
+		)
+	}
 	for _, v := range expectedTypes {
 		if !datums[v.fieldIndex].ResolvedType().Equivalent(v.expectedType) &&
 			(!v.nullable || datums[v.fieldIndex].ResolvedType().Family() != types.UnknownFamily) {
@@ -514,6 +571,12 @@ func (sc *TableStatisticsCache) parseStats(
 	if avgSizeColVerActive {
 		res.AvgSize = (uint64)(*datums[avgSizeIndex].(*tree.DInt))
 	}
+	if avgSizeColVerActive && partialStatsVerActive && datums[partialPredicateIndex] != tree.DNull {
+		res.PartialPredicate = string(*datums[partialPredicateIndex].(*tree.DString))
+		if datums[fullStatisticIDIndex] != tree.DNull {
+			res.FullStatisticID = (uint64)(*datums[fullStatisticIDIndex].(*tree.DInt))
+		}
+	}
 	columnIDs := datums[columnIDsIndex].(*tree.DArray)
 	res.ColumnIDs = make([]descpb.ColumnID, len(columnIDs.Array))
 	for i, d := range columnIDs.Array {
@@ -557,58 +620,87 @@ func (sc *TableStatisticsCache) parseStats(
 			}
 		}
 
-		var offset int
-		if res.NullCount > 0 {
-			// A bucket for NULL is not persisted, but we create a fake one to
-			// make histograms easier to work with. The length of res.Histogram
-			// is therefore 1 greater than the length of the histogram data
-			// buckets.
-			res.Histogram = make([]cat.HistogramBucket, len(res.HistogramData.Buckets)+1)
-			res.Histogram[0] = cat.HistogramBucket{
-				NumEq:         float64(res.NullCount),
-				NumRange:      0,
-				DistinctRange: 0,
-				UpperBound:    tree.DNull,
-			}
-			offset = 1
-		} else {
-			res.Histogram = make([]cat.HistogramBucket, len(res.HistogramData.Buckets))
-			offset = 0
+		if err := res.decodeHistogram(); err != nil {
+			return nil, err
 		}
+	}
 
-		// Decode the histogram data so that it's usable by the opt catalog.
-		var a tree.DatumAlloc
-		for i := offset; i < len(res.Histogram); i++ {
-			bucket := &res.HistogramData.Buckets[i-offset]
-			datum, _, err := keyside.Decode(&a, res.HistogramData.ColumnType, bucket.UpperBound, encoding.Ascending)
-			if err != nil {
-				return nil, err
-			}
-			res.Histogram[i] = cat.HistogramBucket{
-				NumEq:         float64(bucket.NumEq),
-				NumRange:      float64(bucket.NumRange),
-				DistinctRange: bucket.DistinctRange,
-				UpperBound:    datum,
-			}
+	return res, nil
+}
+
+// decodeHistogram decodes the buckets of the histogram data into the
+// Histogram field, so that they are usable by the opt catalog. The column type
+// of the histogram data must already be hydrated.
+func (tabStat *TableStatistic) decodeHistogram() error {
+	var offset int
+	if tabStat.NullCount > 0 {
+		// A bucket for NULL is not persisted, but we create a fake one to
+		// make histograms easier to work with. The length of tabStat.Histogram
+		// is therefore 1 greater than the length of the histogram data
+		// buckets.
+		tabStat.Histogram = make([]cat.HistogramBucket, len(tabStat.HistogramData.Buckets)+1)
+		tabStat.Histogram[0] = cat.HistogramBucket{
+			NumEq:         float64(tabStat.NullCount),
+			NumRange:      0,
+			DistinctRange: 0,
+			UpperBound:    tree.DNull,
 		}
+		offset = 1
+	} else {
+		tabStat.Histogram = make([]cat.HistogramBucket, len(tabStat.HistogramData.Buckets))
+		offset = 0
 	}
 
-	return res, nil
+	// Decode the histogram data so that it's usable by the opt catalog.
+	var a tree.DatumAlloc
+	for i := offset; i < len(tabStat.Histogram); i++ {
+		bucket := &tabStat.HistogramData.Buckets[i-offset]
+		datum, _, err := keyside.Decode(&a, tabStat.HistogramData.ColumnType, bucket.UpperBound, encoding.Ascending)
+		if err != nil {
+			return err
+		}
+		tabStat.Histogram[i] = cat.HistogramBucket{
+			NumEq:         float64(bucket.NumEq),
+			NumRange:      float64(bucket.NumRange),
+			DistinctRange: bucket.DistinctRange,
+			UpperBound:    datum,
+		}
+	}
+	return nil
 }
 
 // getTableStatsFromDB retrieves the statistics in system.table_statistics
-// for the given table ID.
+// for the given table ID, merged and forecasted like the statistics returned by
+// GetTableStats.
+func (sc *TableStatisticsCache) getTableStatsFromDB(
+	ctx context.Context, tableID descpb.ID,
+) ([]*TableStatistic, error) {
+	statsList, err := sc.getStoredTableStatsFromDB(ctx, tableID)
+	if err != nil {
+		return nil, err
+	}
+	return sc.mergeAndForecastStats(ctx, statsList), nil
+}
+
+// getStoredTableStatsFromDB retrieves the statistics in
+// system.table_statistics for the given table ID, as they are stored.
 //
 // It ignores any statistics that cannot be decoded (e.g. because a user-defined
 // type that doesn't exist) and returns the rest (with no error).
-func (sc *TableStatisticsCache) getTableStatsFromDB(
+func (sc *TableStatisticsCache) getStoredTableStatsFromDB(
 	ctx context.Context, tableID descpb.ID,
 ) ([]*TableStatistic, error) {
 	avgSizeColVerActive := sc.Settings.Version.IsActive(ctx, clusterversion.AlterSystemTableStatisticsAddAvgSizeCol)
-	var avgSize string
+	partialStatsVerActive := sc.Settings.Version.IsActive(ctx, clusterversion.AlterSystemTableStatisticsAddPartialPredicateAndID)
+	var avgSize, partialStatsCols string
 	if avgSizeColVerActive {
 		avgSize = `
 					"avgSize",`
+		if partialStatsVerActive {
+			partialStatsCols = `,
+	"partialPredicate",
+	"fullStatisticID"`
+		}
 	}
 	getTableStatisticsStmt := fmt.Sprintf(`
 SELECT
@@ -621,11 +713,11 @@ SELECT
 	"distinctCount",
 	"nullCount",
 	%s
-	histogram
+	histogram%s
 FROM system.table_statistics
 WHERE "tableID" = $1
 ORDER BY "createdAt" DESC
-`, avgSize)
+`, avgSize, partialStatsCols)
 
 	it, err := sc.SQLExecutor.QueryIterator(
 		ctx, "get-table-statistics", nil /* txn */, getTableStatisticsStmt, tableID,
@@ -637,7 +729,7 @@ ORDER BY "createdAt" DESC
 	var statsList []*TableStatistic
 	var ok bool
 	for ok, err = it.Next(ctx); ok; ok, err = it.Next(ctx) {
-		stats, err := sc.parseStats(ctx, it.Cur(), avgSizeColVerActive)
+		stats, err := sc.parseStats(ctx, it.Cur(), avgSizeColVerActive, partialStatsVerActive)
 		if err != nil {
 			log.Warningf(ctx, "could not decode statistic for table %d: %v", tableID, err)
 			continue
@@ -650,3 +742,29 @@ ORDER BY "createdAt" DESC
 
 	return statsList, nil
 }
+
+// mergeAndForecastStats replaces the partial statistics in the given list
+// with the result of merging them into the full statistics that they extend,
+// and adds the forecasted statistics if forecasts are enabled. The statistics
+// are returned ordered by their CreatedAt time (newest-to-oldest).
+func (sc *TableStatisticsCache) mergeAndForecastStats(
+	ctx context.Context, statsList []*TableStatistic,
+) []*TableStatistic {
+	merged := MergedStatistics(ctx, statsList)
+	var forecasts []*TableStatistic
+	if UseStatisticsForecasts.Get(&sc.Settings.SV) {
+		forecasts = ForecastTableStatistics(ctx, statsList)
+	}
+	res := make([]*TableStatistic, 0, len(statsList)+len(merged)+len(forecasts))
+	for _, stat := range statsList {
+		if !stat.IsPartial() {
+			res = append(res, stat)
+		}
+	}
+	res = append(res, merged...)
+	res = append(res, forecasts...)
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].CreatedAt.After(res[j].CreatedAt)
+	})
+	return res
+}
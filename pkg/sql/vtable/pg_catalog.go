@@ -1010,8 +1010,9 @@ CREATE TABLE pg_catalog.pg_timezone_names (
 	utc_offset INTERVAL
 )`
 
-// PgCatalogPublicationTables is an empty table created by pg_catalog_test
-// and is currently unimplemented.
+// PgCatalogPublicationTables describes the schema of the
+// pg_catalog.pg_publication_tables table.
+// https://www.postgresql.org/docs/13/view-pg-publication-tables.html
 const PgCatalogPublicationTables = `
 CREATE TABLE pg_catalog.pg_publication_tables (
 	pubname NAME,
@@ -1067,8 +1068,9 @@ CREATE TABLE pg_catalog.pg_shadow (
 	useconfig STRING[]
 )`
 
-// PgCatalogPublication is an empty table created by pg_catalog_test
-// and is currently unimplemented.
+// PgCatalogPublication describes the schema of the pg_catalog.pg_publication
+// table.
+// https://www.postgresql.org/docs/13/catalog-pg-publication.html
 const PgCatalogPublication = `
 CREATE TABLE pg_catalog.pg_publication (
 	pubupdate BOOL,
@@ -1306,7 +1308,9 @@ CREATE TABLE pg_catalog.pg_replication_origin_status (
 	local_id OID
 )`
 
-//PgCatalogReplicationSlots is an empty table in the pg_catalog that is not implemented yet
+// PgCatalogReplicationSlots describes the schema of the
+// pg_catalog.pg_replication_slots table.
+// https://www.postgresql.org/docs/13/view-pg-replication-slots.html
 const PgCatalogReplicationSlots = `
 CREATE TABLE pg_catalog.pg_replication_slots (
 	safe_wal_size INT,
@@ -281,6 +281,9 @@ func (v *planVisitor) visitInternal(plan planNode, name string) {
 	case *errorIfRowsNode:
 		n.plan = v.visit(n.plan)
 
+	case *deferredCheckNode:
+		n.plan = v.visit(n.plan)
+
 	case *scanBufferNode:
 
 	case *bufferNode:
@@ -357,6 +360,7 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&commentOnColumnNode{}):            "comment on column",
 	reflect.TypeOf(&commentOnConstraintNode{}):        "comment on constraint",
 	reflect.TypeOf(&commentOnDatabaseNode{}):          "comment on database",
+	reflect.TypeOf(&commentOnFunctionNode{}):          "comment on function",
 	reflect.TypeOf(&commentOnIndexNode{}):             "comment on index",
 	reflect.TypeOf(&commentOnTableNode{}):             "comment on table",
 	reflect.TypeOf(&commentOnSchemaNode{}):            "comment on schema",
@@ -364,7 +368,9 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&controlSchedulesNode{}):           "control schedules",
 	reflect.TypeOf(&createDatabaseNode{}):             "create database",
 	reflect.TypeOf(&createExtensionNode{}):            "create extension",
+	reflect.TypeOf(&createFunctionNode{}):             "create function",
 	reflect.TypeOf(&createIndexNode{}):                "create index",
+	reflect.TypeOf(&createPublicationNode{}):          "create publication",
 	reflect.TypeOf(&createSequenceNode{}):             "create sequence",
 	reflect.TypeOf(&createSchemaNode{}):               "create schema",
 	reflect.TypeOf(&createStatsNode{}):                "create statistics",
@@ -373,11 +379,15 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&CreateRoleNode{}):                 "create user/role",
 	reflect.TypeOf(&createViewNode{}):                 "create view",
 	reflect.TypeOf(&delayedNode{}):                    "virtual table",
+	reflect.TypeOf(&deferredCheckNode{}):              "deferred check",
 	reflect.TypeOf(&deleteNode{}):                     "delete",
 	reflect.TypeOf(&deleteRangeNode{}):                "delete range",
 	reflect.TypeOf(&distinctNode{}):                   "distinct",
 	reflect.TypeOf(&dropDatabaseNode{}):               "drop database",
+	reflect.TypeOf(&dropFunctionNode{}):               "drop function",
 	reflect.TypeOf(&dropIndexNode{}):                  "drop index",
+	reflect.TypeOf(&dropPublicationNode{}):            "drop publication",
+	reflect.TypeOf(&dropReplicationSlotNode{}):        "drop replication slot",
 	reflect.TypeOf(&dropSequenceNode{}):               "drop sequence",
 	reflect.TypeOf(&dropSchemaNode{}):                 "drop schema",
 	reflect.TypeOf(&dropTableNode{}):                  "drop table",
@@ -427,6 +437,7 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&sequenceSelectNode{}):             "sequence select",
 	reflect.TypeOf(&serializeNode{}):                  "run",
 	reflect.TypeOf(&setClusterSettingNode{}):          "set cluster setting",
+	reflect.TypeOf(&setConstraintsNode{}):             "set constraints",
 	reflect.TypeOf(&setVarNode{}):                     "set",
 	reflect.TypeOf(&setZoneConfigNode{}):              "configure zone",
 	reflect.TypeOf(&showFingerprintsNode{}):           "show fingerprints",
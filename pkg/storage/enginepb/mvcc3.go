@@ -51,3 +51,19 @@ func (op *MVCCLogicalOp) MustSetValue(value interface{}) {
 		panic(errors.AssertionFailedf("%T excludes %T", op, value))
 	}
 }
+
+// SafeValue implements the redact.SafeValue interface.
+func (IsolationLevel) SafeValue() {}
+
+// ToleratesWriteSkew returns whether transactions running at the isolation
+// level may commit at a timestamp above their read timestamp without first
+// refreshing their reads.
+func (l IsolationLevel) ToleratesWriteSkew() bool {
+	return l == ReadCommitted
+}
+
+// PerStatementReadSnapshot returns whether transactions running at the
+// isolation level establish a new read snapshot for each statement.
+func (l IsolationLevel) PerStatementReadSnapshot() bool {
+	return l == ReadCommitted
+}
@@ -99,8 +99,12 @@ func InjectDescriptors(
 				return errors.Wrapf(err, "failed to inject descriptor %d", id)
 			}
 		}
-		// Inject the namespace entries.
+		// Inject the namespace entries. Functions are resolved through the
+		// schema descriptor and have no namespace entry.
 		for _, d := range others {
+			if _, isFunction := d.Union.(*descpb.Descriptor_Function); isFunction {
+				continue
+			}
 			id, _, name, _, _, err := descpb.GetDescriptorMetadata(d)
 			if err != nil {
 				return err
@@ -141,6 +145,8 @@ func resetVersionAndModificationTime(d *descpb.Descriptor) {
 		d.Database.Version = 1
 	case *descpb.Descriptor_Schema:
 		d.Schema.Version = 1
+	case *descpb.Descriptor_Function:
+		d.Function.Version = 1
 	case *descpb.Descriptor_Type:
 		d.Type.Version = 1
 	case *descpb.Descriptor_Table:
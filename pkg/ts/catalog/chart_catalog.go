@@ -2614,6 +2614,45 @@ var charts = []sectionDescription{
 			},
 		},
 	},
+	{
+		Organization: [][]string{{Jobs, "Schedules", "Changefeed"}},
+		Charts: []chartDescription{
+			{
+				Title: "Counts",
+				Metrics: []string{
+					"schedules.CHANGEFEED.started",
+					"schedules.CHANGEFEED.succeeded",
+					"schedules.CHANGEFEED.failed",
+				},
+			},
+		},
+	},
+	{
+		Organization: [][]string{{Jobs, "Schedules", "Export"}},
+		Charts: []chartDescription{
+			{
+				Title: "Counts",
+				Metrics: []string{
+					"schedules.EXPORT.started",
+					"schedules.EXPORT.succeeded",
+					"schedules.EXPORT.failed",
+				},
+			},
+		},
+	},
+	{
+		Organization: [][]string{{Jobs, "Schedules", "SQL"}},
+		Charts: []chartDescription{
+			{
+				Title: "Counts",
+				Metrics: []string{
+					"schedules.SQL.started",
+					"schedules.SQL.succeeded",
+					"schedules.SQL.failed",
+				},
+			},
+		},
+	},
 	{
 		Organization: [][]string{{Jobs, "Schedules", "SQL Stats"}},
 		Charts: []chartDescription{
@@ -2648,6 +2687,10 @@ var charts = []sectionDescription{
 					"jobs.auto_span_config_reconciliation.currently_running",
 					"jobs.auto_sql_stats_compaction.currently_running",
 					"jobs.stream_replication.currently_running",
+					"jobs.scheduled_export.currently_running",
+					"jobs.scheduled_sql.currently_running",
+					"jobs.backup_compaction.currently_running",
+					"jobs.auto_index_recommendations.currently_running",
 				},
 			},
 			{
@@ -2824,6 +2867,50 @@ var charts = []sectionDescription{
 					"jobs.auto_sql_stats_compaction.resume_retry_error",
 				},
 			},
+			{
+				Title: "Scheduled Export",
+				Metrics: []string{
+					"jobs.scheduled_export.fail_or_cancel_completed",
+					"jobs.scheduled_export.fail_or_cancel_failed",
+					"jobs.scheduled_export.fail_or_cancel_retry_error",
+					"jobs.scheduled_export.resume_completed",
+					"jobs.scheduled_export.resume_failed",
+					"jobs.scheduled_export.resume_retry_error",
+				},
+			},
+			{
+				Title: "Scheduled SQL",
+				Metrics: []string{
+					"jobs.scheduled_sql.fail_or_cancel_completed",
+					"jobs.scheduled_sql.fail_or_cancel_failed",
+					"jobs.scheduled_sql.fail_or_cancel_retry_error",
+					"jobs.scheduled_sql.resume_completed",
+					"jobs.scheduled_sql.resume_failed",
+					"jobs.scheduled_sql.resume_retry_error",
+				},
+			},
+			{
+				Title: "Backup Compaction",
+				Metrics: []string{
+					"jobs.backup_compaction.fail_or_cancel_completed",
+					"jobs.backup_compaction.fail_or_cancel_failed",
+					"jobs.backup_compaction.fail_or_cancel_retry_error",
+					"jobs.backup_compaction.resume_completed",
+					"jobs.backup_compaction.resume_failed",
+					"jobs.backup_compaction.resume_retry_error",
+				},
+			},
+			{
+				Title: "Auto Index Recommendations",
+				Metrics: []string{
+					"jobs.auto_index_recommendations.fail_or_cancel_completed",
+					"jobs.auto_index_recommendations.fail_or_cancel_failed",
+					"jobs.auto_index_recommendations.fail_or_cancel_retry_error",
+					"jobs.auto_index_recommendations.resume_completed",
+					"jobs.auto_index_recommendations.resume_failed",
+					"jobs.auto_index_recommendations.resume_retry_error",
+				},
+			},
 		},
 	},
 	{
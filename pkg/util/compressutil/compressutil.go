@@ -0,0 +1,155 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package compressutil implements the compression codecs shared by the
+// features reading or writing files in external storage, such as IMPORT,
+// EXPORT and changefeeds.
+package compressutil
+
+import (
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Codec is a compression codec.
+type Codec int
+
+const (
+	// None means the data is not compressed.
+	None Codec = iota
+	// Gzip is the gzip codec.
+	Gzip
+	// Bzip is the bzip2 codec. It is only supported for reading.
+	Bzip
+	// Snappy is the framed snappy codec.
+	Snappy
+	// Zstd is the zstandard codec.
+	Zstd
+)
+
+var codecNames = map[Codec]string{
+	None:   "none",
+	Gzip:   "gzip",
+	Bzip:   "bzip",
+	Snappy: "snappy",
+	Zstd:   "zstd",
+}
+
+var codecExtensions = map[Codec]string{
+	Gzip:   ".gz",
+	Bzip:   ".bz2",
+	Snappy: ".sz",
+	Zstd:   ".zst",
+}
+
+// String implements the fmt.Stringer interface.
+func (c Codec) String() string {
+	if name, ok := codecNames[c]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// Extension returns the file extension, including the leading dot, of files
+// compressed with the codec. It is empty for None.
+func (c Codec) Extension() string {
+	return codecExtensions[c]
+}
+
+// CodecFromName returns the codec with the given name, ignoring case. "bzip2"
+// is accepted as an alias of "bzip".
+func CodecFromName(name string) (Codec, error) {
+	if strings.EqualFold(name, "bzip2") {
+		return Bzip, nil
+	}
+	for c, n := range codecNames {
+		if strings.EqualFold(name, n) {
+			return c, nil
+		}
+	}
+	return None, errors.Errorf("unsupported compression codec %q", name)
+}
+
+// CodecFromFileName guesses the codec of a file from its extension. Files
+// with an unknown extension are assumed not to be compressed.
+func CodecFromFileName(name string) Codec {
+	switch {
+	case strings.HasSuffix(name, ".gz"):
+		return Gzip
+	case strings.HasSuffix(name, ".bz2") || strings.HasSuffix(name, ".bz"):
+		return Bzip
+	case strings.HasSuffix(name, ".sz") || strings.HasSuffix(name, ".snappy"):
+		return Snappy
+	case strings.HasSuffix(name, ".zst") || strings.HasSuffix(name, ".zstd"):
+		return Zstd
+	default:
+		return None
+	}
+}
+
+// Writer is a compressing writer. Close must be called to flush the
+// remaining data and write the footer of the compressed stream, if any.
+type Writer interface {
+	io.WriteCloser
+	// Flush writes any pending data to the underlying writer.
+	Flush() error
+	// Reset discards the state of the writer and makes it write to w, as if it
+	// had just been created.
+	Reset(w io.Writer)
+}
+
+// NewWriter returns a Writer which compresses the data written to it with the
+// given codec and writes it to w.
+func NewWriter(c Codec, w io.Writer) (Writer, error) {
+	switch c {
+	case Gzip:
+		return gzip.NewWriter(w), nil
+	case Snappy:
+		return snappy.NewBufferedWriter(w), nil
+	case Zstd:
+		e, err := zstd.NewWriter(w)
+		if err != nil {
+			return nil, err
+		}
+		return e, nil
+	default:
+		return nil, errors.Errorf("compressing with %s is not supported", c)
+	}
+}
+
+// NewReader returns a reader which decompresses the data read from r with the
+// given codec. None returns the data of r as is.
+func NewReader(c Codec, r io.Reader) (io.ReadCloser, error) {
+	switch c {
+	case None:
+		return ioutil.NopCloser(r), nil
+	case Gzip:
+		return gzip.NewReader(r)
+	case Bzip:
+		return ioutil.NopCloser(bzip2.NewReader(r)), nil
+	case Snappy:
+		return ioutil.NopCloser(snappy.NewReader(r)), nil
+	case Zstd:
+		d, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	default:
+		return nil, errors.Errorf("decompressing %s is not supported", c)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package compressutil
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundTrip(t *testing.T) {
+	data := strings.Repeat("hello, world\n", 1000)
+	for _, c := range []Codec{Gzip, Snappy, Zstd} {
+		t.Run(c.String(), func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewWriter(c, &buf)
+			require.NoError(t, err)
+			_, err = w.Write([]byte(data))
+			require.NoError(t, err)
+			require.NoError(t, w.Close())
+			require.Less(t, buf.Len(), len(data))
+			require.Equal(t, c, CodecFromFileName("data.csv"+c.Extension()))
+
+			r, err := NewReader(c, &buf)
+			require.NoError(t, err)
+			decompressed, err := ioutil.ReadAll(r)
+			require.NoError(t, err)
+			require.NoError(t, r.Close())
+			require.Equal(t, data, string(decompressed))
+
+			// A reset writer starts a new stream.
+			var other bytes.Buffer
+			w.Reset(&other)
+			_, err = w.Write([]byte(data))
+			require.NoError(t, err)
+			require.NoError(t, w.Close())
+			r, err = NewReader(c, &other)
+			require.NoError(t, err)
+			decompressed, err = ioutil.ReadAll(r)
+			require.NoError(t, err)
+			require.Equal(t, data, string(decompressed))
+		})
+	}
+}
+
+func TestCodecFromName(t *testing.T) {
+	for name, expected := range map[string]Codec{
+		"gzip":   Gzip,
+		"GZIP":   Gzip,
+		"bzip2":  Bzip,
+		"snappy": Snappy,
+		"Zstd":   Zstd,
+		"none":   None,
+	} {
+		c, err := CodecFromName(name)
+		require.NoError(t, err)
+		require.Equal(t, expected, c)
+	}
+	_, err := CodecFromName("lzma")
+	require.EqualError(t, err, `unsupported compression codec "lzma"`)
+
+	_, err = NewWriter(Bzip, &bytes.Buffer{})
+	require.EqualError(t, err, `compressing with bzip is not supported`)
+}
+
+func TestCodecFromFileName(t *testing.T) {
+	for name, expected := range map[string]Codec{
+		"data.csv":         None,
+		"data.csv.gz":      Gzip,
+		"data.csv.bz":      Bzip,
+		"data.csv.bz2":     Bzip,
+		"data.ndjson.sz":   Snappy,
+		"data.csv.snappy":  Snappy,
+		"data.csv.zst":     Zstd,
+		"data.ndjson.zstd": Zstd,
+	} {
+		require.Equal(t, expected, CodecFromFileName(name), name)
+	}
+}
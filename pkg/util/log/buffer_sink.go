@@ -171,7 +171,7 @@ func (bs *bufferSink) accumulator(ctx context.Context) {
 	}
 }
 
-// flusher concatenates bundled messages and sends them to the child sink.
+// flusher sends bundled messages to the child sink.
 //
 // TODO(knz): How does this interact with the flusher logic in log_flush.go?
 // See: https://github.com/cockroachdb/cockroach/issues/72458
@@ -185,24 +185,9 @@ func (bs *bufferSink) accumulator(ctx context.Context) {
 func (bs *bufferSink) flusher(ctx context.Context) {
 	for b := range bs.flushCh {
 		if len(b.messages) > 0 {
-			// Append all the messages in the first buffer.
-			buf := b.messages[0].b
-			buf.Grow(b.byteLen - len(buf.Bytes()))
-			for i, m := range b.messages {
-				if i == 0 {
-					// First buffer skips putBuffer --
-					// we're still using it and it's a weird size
-					// for reuse.
-					continue
-				}
-				buf.WriteByte('\n')
-				buf.Write(m.b.Bytes())
-				putBuffer(m.b)
-			}
 			forceSync := b.errorCh != nil
 			// Send the accumulated messages to the child sink.
-			err := bs.child.output(buf.Bytes(),
-				sinkOutputOptions{extraFlush: true, forceSync: forceSync})
+			err := bs.flushBundle(b, sinkOutputOptions{extraFlush: true, forceSync: forceSync})
 			if forceSync {
 				b.errorCh <- err
 			} else if err != nil && bs.errCallback != nil {
@@ -224,6 +209,39 @@ func (bs *bufferSink) flusher(ctx context.Context) {
 	}
 }
 
+// flushBundle sends the messages of a non-empty bundle to the child
+// sink. If the child sink is a batchSink, the messages are passed to it
+// separately; otherwise, they are concatenated.
+func (bs *bufferSink) flushBundle(b bufferSinkBundle, opts sinkOutputOptions) error {
+	if child, ok := bs.child.(batchSink); ok {
+		entries := make([]batchEntry, len(b.messages))
+		for i, m := range b.messages {
+			entries[i] = batchEntry{b: m.b.Bytes(), entry: m.entry}
+		}
+		err := child.outputBatch(entries, opts)
+		for _, m := range b.messages {
+			putBuffer(m.b)
+		}
+		return err
+	}
+
+	// Append all the messages in the first buffer.
+	buf := b.messages[0].b
+	buf.Grow(b.byteLen - len(buf.Bytes()))
+	for i, m := range b.messages {
+		if i == 0 {
+			// First buffer skips putBuffer --
+			// we're still using it and it's a weird size
+			// for reuse.
+			continue
+		}
+		buf.WriteByte('\n')
+		buf.Write(m.b.Bytes())
+		putBuffer(m.b)
+	}
+	return bs.child.output(buf.Bytes(), opts)
+}
+
 // bufferSinkMessage holds an individual log message sent from output to accumulate.
 type bufferSinkMessage struct {
 	b *buffer
@@ -234,6 +252,8 @@ type bufferSinkMessage struct {
 	// of child sink writes.
 	// The caller will block expecting a (possibly nil) error to return synchronously.
 	errorCh chan<- error
+	// entry describes the log entry, for use by batchSink children.
+	entry entryInfo
 }
 
 // bufferSinkBundle is the accumulated state; the unit sent from the accumulator to the flusher.
@@ -301,10 +321,10 @@ func (bs *bufferSink) output(b []byte, opts sinkOutputOptions) error {
 	}
 	if opts.forceSync {
 		errorCh := make(chan error)
-		bs.messageCh <- bufferSinkMessage{buf, opts.extraFlush, errorCh}
+		bs.messageCh <- bufferSinkMessage{buf, opts.extraFlush, errorCh, opts.entry}
 		return <-errorCh
 	}
-	bs.messageCh <- bufferSinkMessage{buf, opts.extraFlush, nil}
+	bs.messageCh <- bufferSinkMessage{buf, opts.extraFlush, nil, opts.entry}
 	return nil
 }
 
@@ -98,7 +98,10 @@ func (l *loggerT) reportErrorEverywhereLocked(ctx context.Context, err error) {
 		sink := s.sink
 		if logpb.Severity_ERROR >= s.threshold.get(entry.ch) && sink.active() {
 			buf := s.formatter.formatEntry(entry)
-			_ = sink.output(buf.Bytes(), sinkOutputOptions{ignoreErrors: true})
+			_ = sink.output(buf.Bytes(), sinkOutputOptions{
+				ignoreErrors: true,
+				entry:        entryInfo{sev: entry.sev, ch: entry.ch, ts: entry.ts},
+			})
 			putBuffer(buf)
 		}
 	}
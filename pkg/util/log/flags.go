@@ -323,6 +323,32 @@ func ApplyConfig(config logconfig.Config) (resFn func(), err error) {
 		attachSinkInfo(httpSinkInfo, &fc.Channels)
 	}
 
+	// Create the syslog sinks.
+	for _, fc := range config.Sinks.SyslogServers {
+		if fc.Filter == severity.NONE {
+			continue
+		}
+		syslogSinkInfo, err := newSyslogSinkInfo(*fc)
+		if err != nil {
+			return nil, err
+		}
+		attachBufferWrapper(secLoggersCtx, syslogSinkInfo, fc.CommonSinkConfig)
+		attachSinkInfo(syslogSinkInfo, &fc.Channels)
+	}
+
+	// Create the OTLP sinks.
+	for _, fc := range config.Sinks.OTLPServers {
+		if fc.Filter == severity.NONE {
+			continue
+		}
+		otlpSinkInfo, err := newOTLPSinkInfo(*fc)
+		if err != nil {
+			return nil, err
+		}
+		attachBufferWrapper(secLoggersCtx, otlpSinkInfo, fc.CommonSinkConfig)
+		attachSinkInfo(otlpSinkInfo, &fc.Channels)
+	}
+
 	// Prepend the interceptor sink to all channels.
 	// We prepend it because we want the interceptors
 	// to see every event before they make their way to disk/network.
@@ -392,6 +418,48 @@ func newHTTPSinkInfo(c logconfig.HTTPSinkConfig) (*sinkInfo, error) {
 	return info, nil
 }
 
+func newSyslogSinkInfo(c logconfig.SyslogSinkConfig) (*sinkInfo, error) {
+	info := &sinkInfo{}
+	if err := info.applyConfig(c.CommonSinkConfig); err != nil {
+		return nil, err
+	}
+	info.applyFilters(c.Channels)
+	opts := syslogSinkOptions{
+		network:   *c.Net,
+		tls:       *c.TLS,
+		unsafeTLS: *c.UnsafeTLS,
+		facility:  c.Facility.Code(),
+		appName:   *c.AppName,
+		timeout:   *c.Timeout,
+	}
+	if c.CACert != nil {
+		opts.caCert = *c.CACert
+	}
+	syslogSink, err := newSyslogSink(*c.Address, opts)
+	if err != nil {
+		return nil, err
+	}
+	info.sink = syslogSink
+	return info, nil
+}
+
+func newOTLPSinkInfo(c logconfig.OTLPSinkConfig) (*sinkInfo, error) {
+	info := &sinkInfo{}
+	if err := info.applyConfig(c.CommonSinkConfig); err != nil {
+		return nil, err
+	}
+	info.applyFilters(c.Channels)
+	otlpSink, err := newOTLPSink(*c.Address, otlpSinkOptions{
+		unsafeTLS: *c.UnsafeTLS,
+		timeout:   *c.Timeout,
+	})
+	if err != nil {
+		return nil, err
+	}
+	info.sink = otlpSink
+	return info, nil
+}
+
 // applyFilters applies the channel filters to a sinkInfo.
 func (l *sinkInfo) applyFilters(chs logconfig.ChannelFilters) {
 	for ch, threshold := range chs.ChannelFilters {
@@ -41,6 +41,14 @@ const DefaultFluentFormat = `json-fluent-compact`
 // when not specified in a configuration.
 const DefaultHTTPFormat = `json-compact`
 
+// DefaultSyslogFormat is the entry format for syslog sinks
+// when not specified in a configuration.
+const DefaultSyslogFormat = `json-compact`
+
+// DefaultOTLPFormat is the entry format for OTLP sinks
+// when not specified in a configuration.
+const DefaultOTLPFormat = `json-compact`
+
 // DefaultConfig returns a suitable default configuration when logging
 // is meant to primarily go to files.
 func DefaultConfig() (c Config) {
@@ -113,6 +121,16 @@ type Config struct {
 	// configuration value.
 	HTTPDefaults HTTPDefaults `yaml:"http-defaults,omitempty"`
 
+	// SyslogDefaults represents the default configuration for syslog sinks,
+	// inherited when a specific syslog sink config does not provide a
+	// configuration value.
+	SyslogDefaults SyslogDefaults `yaml:"syslog-defaults,omitempty"`
+
+	// OTLPDefaults represents the default configuration for OTLP sinks,
+	// inherited when a specific OTLP sink config does not provide a
+	// configuration value.
+	OTLPDefaults OTLPDefaults `yaml:"otlp-defaults,omitempty"`
+
 	// Sinks represents the sink configurations.
 	Sinks SinkConfig `yaml:",omitempty"`
 
@@ -224,6 +242,10 @@ type SinkConfig struct {
 	FluentServers map[string]*FluentSinkConfig `yaml:"fluent-servers,omitempty"`
 	// HTTPServers represents the list of configured http sinks.
 	HTTPServers map[string]*HTTPSinkConfig `yaml:"http-servers,omitempty"`
+	// SyslogServers represents the list of configured syslog sinks.
+	SyslogServers map[string]*SyslogSinkConfig `yaml:"syslog-servers,omitempty"`
+	// OTLPServers represents the list of configured OTLP sinks.
+	OTLPServers map[string]*OTLPSinkConfig `yaml:"otlp-servers,omitempty"`
 	// Stderr represents the configuration for the stderr sink.
 	Stderr StderrSinkConfig `yaml:",omitempty"`
 }
@@ -533,6 +555,155 @@ type HTTPSinkConfig struct {
 	sinkName string
 }
 
+// SyslogDefaults represents the configuration defaults for syslog sinks.
+type SyslogDefaults struct {
+	// Address is the network address of the syslog server. The
+	// host/address and port parts are separated with a colon. IPv6
+	// numeric addresses should be included within square brackets,
+	// e.g.: [::1]:514.
+	Address *string `yaml:",omitempty"`
+
+	// Net is the protocol for the syslog server. Can be "tcp", "udp",
+	// "tcp4", etc. Defaults to "tcp".
+	Net *string `yaml:",omitempty"`
+
+	// TLS enables TLS on the connection to the syslog server. Only
+	// supported with TCP. Defaults to false.
+	TLS *bool `yaml:"tls,omitempty"`
+
+	// CACert is the path to a PEM-encoded CA certificate used to verify
+	// the certificate of the syslog server when TLS is enabled. If
+	// unset, the system's root CAs are used.
+	CACert *string `yaml:"ca-cert,omitempty"`
+
+	// UnsafeTLS enables certificate authentication to be bypassed.
+	// Defaults to false.
+	UnsafeTLS *bool `yaml:"unsafe-tls,omitempty"`
+
+	// Facility is the syslog facility reported for the log events,
+	// e.g. "user", "daemon", "auth", "local0". Defaults to "user".
+	Facility *SyslogFacility `yaml:",omitempty"`
+
+	// AppName is the APP-NAME field reported for the log events.
+	// Defaults to "cockroach".
+	AppName *string `yaml:"app-name,omitempty"`
+
+	// Timeout is the timeout for connecting to the syslog server and
+	// for writing to it. Defaults to 5s.
+	Timeout *time.Duration `yaml:",omitempty"`
+
+	CommonSinkConfig `yaml:",inline"`
+}
+
+// SyslogSinkConfig represents the configuration for one syslog sink.
+//
+// User-facing documentation follows.
+// TITLE: Output to syslog servers.
+//
+// This sink type causes logging data to be sent over the network to
+// a syslog server, as messages in the
+// [RFC 5424](https://datatracker.ietf.org/doc/html/rfc5424) format.
+//
+// Each log event is sent as a separate syslog message. The severity of
+// the message is derived from the severity of the log event, and the
+// logging channel is reported as the MSGID field. Over TCP, messages
+// are framed using octet counting as described in
+// [RFC 6587](https://datatracker.ietf.org/doc/html/rfc6587). TLS can be
+// enabled with `tls: true`, in which case the server certificate is
+// verified using the system's root CAs or the CA certificate
+// configured with `ca-cert`.
+//
+// The configuration key under the `sinks` key in the YAML
+// configuration is `syslog-servers`. Example configuration:
+//
+//      sinks:
+//         syslog-servers:
+//            security:
+//               channels: [SENSITIVE_ACCESS, USER_ADMIN, PRIVILEGES]
+//               address: syslog.example.com:6514
+//               tls: true
+//               facility: auth
+//
+// Every new server sink configured automatically inherits the configuration set in the `syslog-defaults` section.
+//
+// The default output format for syslog sinks is
+// `json-compact`. [Other supported formats.](log-formats.html)
+//
+// {{site.data.alerts.callout_info}}
+// Run `cockroach debug check-log-config` to verify the effect of defaults inheritance.
+// {{site.data.alerts.end}}
+//
+type SyslogSinkConfig struct {
+	// Channels is the list of logging channels that use this sink.
+	Channels ChannelFilters `yaml:",omitempty,flow"`
+
+	SyslogDefaults `yaml:",inline"`
+
+	// sinkName is populated during validation.
+	sinkName string
+}
+
+// OTLPDefaults represents the configuration defaults for OTLP sinks.
+type OTLPDefaults struct {
+	// Address is the URL of the OTLP/HTTP logs endpoint of the
+	// collector, e.g. http://127.0.0.1:4318/v1/logs.
+	Address *string `yaml:",omitempty"`
+
+	// UnsafeTLS enables certificate authentication to be bypassed.
+	// Defaults to false.
+	UnsafeTLS *bool `yaml:"unsafe-tls,omitempty"`
+
+	// Timeout is the timeout of each export request.
+	// Defaults to 0 for no timeout.
+	Timeout *time.Duration `yaml:",omitempty"`
+
+	CommonSinkConfig `yaml:",inline"`
+}
+
+// OTLPSinkConfig represents the configuration for one OTLP sink.
+//
+// User-facing documentation follows.
+// TITLE: Output to OpenTelemetry collectors.
+//
+// This sink type causes logging data to be sent over the network to
+// a collector that supports the
+// [OpenTelemetry protocol](https://opentelemetry.io/docs/reference/specification/protocol/otlp/)
+// (OTLP) over HTTP.
+//
+// Each log event is exported as an OTLP log record. The formatted
+// event is reported as the body of the record, its severity as the
+// record's severity and the logging channel as the `channel`
+// attribute. When buffering is enabled, the events accumulated in the
+// buffer are exported in a single request.
+//
+// The configuration key under the `sinks` key in the YAML
+// configuration is `otlp-servers`. Example configuration:
+//
+//      sinks:
+//         otlp-servers:
+//            collector:
+//               channels: all
+//               address: http://127.0.0.1:4318/v1/logs
+//
+// Every new server sink configured automatically inherits the configuration set in the `otlp-defaults` section.
+//
+// The default output format for OTLP sinks is
+// `json-compact`. [Other supported formats.](log-formats.html)
+//
+// {{site.data.alerts.callout_info}}
+// Run `cockroach debug check-log-config` to verify the effect of defaults inheritance.
+// {{site.data.alerts.end}}
+//
+type OTLPSinkConfig struct {
+	// Channels is the list of logging channels that use this sink.
+	Channels ChannelFilters `yaml:",omitempty,flow"`
+
+	OTLPDefaults `yaml:",inline"`
+
+	// sinkName is populated during validation.
+	sinkName string
+}
+
 // IterateDirectories calls the provided fn on every directory linked to
 // by the configuration.
 func (c *Config) IterateDirectories(fn func(d string) error) error {
@@ -1107,6 +1278,56 @@ func (hsm *HTTPSinkMethod) UnmarshalYAML(fn func(interface{}) error) error {
 	return unmarshalYAMLConstrainedString(hsm, fn)
 }
 
+// SyslogFacility is a string restricted to the facility names
+// defined by RFC 5424.
+type SyslogFacility string
+
+var _ constrainedString = (*SyslogFacility)(nil)
+
+// syslogFacilities lists the supported facility names, in the order of
+// their numeric codes.
+var syslogFacilities = []string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+	"uucp", "cron", "authpriv", "ftp", "ntp", "audit", "alert", "clock",
+	"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+}
+
+// Accept implements the constrainedString interface.
+func (f *SyslogFacility) Accept(s string) {
+	*f = SyslogFacility(s)
+}
+
+// Canonicalize implements the constrainedString interface.
+func (SyslogFacility) Canonicalize(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+// AllowedSet implements the constrainedString interface.
+func (SyslogFacility) AllowedSet() []string {
+	return syslogFacilities
+}
+
+// Code returns the numeric code of the facility, as used in the PRI
+// part of syslog messages.
+func (f SyslogFacility) Code() int {
+	for i, name := range syslogFacilities {
+		if string(f) == name {
+			return i
+		}
+	}
+	return 1 // user
+}
+
+// MarshalYAML implements yaml.Marshaler interface.
+func (f SyslogFacility) MarshalYAML() (interface{}, error) {
+	return string(f), nil
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+func (f *SyslogFacility) UnmarshalYAML(fn func(interface{}) error) error {
+	return unmarshalYAMLConstrainedString(f, fn)
+}
+
 // constrainedString is an interface to make it easy to unmarshal
 // a string constrained to a small set of accepted values.
 type constrainedString interface {
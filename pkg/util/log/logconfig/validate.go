@@ -79,14 +79,36 @@ func (c *Config) Validate(defaultLogDir *string) (resErr error) {
 		Method:            func() *HTTPSinkMethod { m := HTTPSinkMethod(http.MethodPost); return &m }(),
 		Timeout:           &zeroDuration,
 	}
+	baseSyslogDefaults := SyslogDefaults{
+		CommonSinkConfig: CommonSinkConfig{
+			Format: func() *string { s := DefaultSyslogFormat; return &s }(),
+		},
+		Net:       func() *string { s := "tcp"; return &s }(),
+		TLS:       &bf,
+		UnsafeTLS: &bf,
+		Facility:  func() *SyslogFacility { f := SyslogFacility("user"); return &f }(),
+		AppName:   func() *string { s := "cockroach"; return &s }(),
+		Timeout:   func() *time.Duration { d := 5 * time.Second; return &d }(),
+	}
+	baseOTLPDefaults := OTLPDefaults{
+		CommonSinkConfig: CommonSinkConfig{
+			Format: func() *string { s := DefaultOTLPFormat; return &s }(),
+		},
+		UnsafeTLS: &bf,
+		Timeout:   &zeroDuration,
+	}
 
 	propagateCommonDefaults(&baseFileDefaults.CommonSinkConfig, baseCommonSinkConfig)
 	propagateCommonDefaults(&baseFluentDefaults.CommonSinkConfig, baseCommonSinkConfig)
 	propagateCommonDefaults(&baseHTTPDefaults.CommonSinkConfig, baseCommonSinkConfig)
+	propagateCommonDefaults(&baseSyslogDefaults.CommonSinkConfig, baseCommonSinkConfig)
+	propagateCommonDefaults(&baseOTLPDefaults.CommonSinkConfig, baseCommonSinkConfig)
 
 	propagateFileDefaults(&c.FileDefaults, baseFileDefaults)
 	propagateFluentDefaults(&c.FluentDefaults, baseFluentDefaults)
 	propagateHTTPDefaults(&c.HTTPDefaults, baseHTTPDefaults)
+	propagateSyslogDefaults(&c.SyslogDefaults, baseSyslogDefaults)
+	propagateOTLPDefaults(&c.OTLPDefaults, baseOTLPDefaults)
 
 	// Normalize the directory.
 	if err := normalizeDir(&c.FileDefaults.Dir); err != nil {
@@ -128,6 +150,28 @@ func (c *Config) Validate(defaultLogDir *string) (resErr error) {
 		}
 	}
 
+	for sinkName, fc := range c.Sinks.SyslogServers {
+		if fc == nil {
+			fc = &SyslogSinkConfig{Channels: SelectChannels()}
+			c.Sinks.SyslogServers[sinkName] = fc
+		}
+		fc.sinkName = sinkName
+		if err := c.validateSyslogSinkConfig(fc); err != nil {
+			fmt.Fprintf(&errBuf, "syslog server %q: %v\n", sinkName, err)
+		}
+	}
+
+	for sinkName, fc := range c.Sinks.OTLPServers {
+		if fc == nil {
+			fc = &OTLPSinkConfig{Channels: SelectChannels()}
+			c.Sinks.OTLPServers[sinkName] = fc
+		}
+		fc.sinkName = sinkName
+		if err := c.validateOTLPSinkConfig(fc); err != nil {
+			fmt.Fprintf(&errBuf, "otlp server %q: %v\n", sinkName, err)
+		}
+	}
+
 	// Defaults for stderr.
 	if c.Sinks.Stderr.Filter == logpb.Severity_UNKNOWN {
 		c.Sinks.Stderr.Filter = logpb.Severity_NONE
@@ -202,6 +246,32 @@ func (c *Config) Validate(defaultLogDir *string) (resErr error) {
 		}
 	}
 
+	for sinkName, fc := range c.Sinks.SyslogServers {
+		if len(fc.Channels.Filters) == 0 {
+			fmt.Fprintf(&errBuf, "syslog server %q: no channel selected\n", sinkName)
+			continue
+		}
+		// Propagate the sink-wide default filter to all channels that don't
+		// have a filter yet.
+		if err := fc.Channels.Validate(fc.Filter); err != nil {
+			fmt.Fprintf(&errBuf, "syslog server %q: %v\n", sinkName, err)
+			continue
+		}
+	}
+
+	for sinkName, fc := range c.Sinks.OTLPServers {
+		if len(fc.Channels.Filters) == 0 {
+			fmt.Fprintf(&errBuf, "otlp server %q: no channel selected\n", sinkName)
+			continue
+		}
+		// Propagate the sink-wide default filter to all channels that don't
+		// have a filter yet.
+		if err := fc.Channels.Validate(fc.Filter); err != nil {
+			fmt.Fprintf(&errBuf, "otlp server %q: %v\n", sinkName, err)
+			continue
+		}
+	}
+
 	// If capture-stray-errors was enabled, then perform some additional
 	// validation on it.
 	if c.CaptureFd2.Enable {
@@ -307,6 +377,22 @@ func (c *Config) Validate(defaultLogDir *string) (resErr error) {
 		}
 	}
 
+	// Elide all the syslog sinks where all channels have
+	// severity set to NONE.
+	for serverName, fc := range c.Sinks.SyslogServers {
+		if fc.Channels.noChannelsSelected() {
+			delete(c.Sinks.SyslogServers, serverName)
+		}
+	}
+
+	// Elide all the OTLP sinks where all channels have
+	// severity set to NONE.
+	for serverName, fc := range c.Sinks.OTLPServers {
+		if fc.Channels.noChannelsSelected() {
+			delete(c.Sinks.OTLPServers, serverName)
+		}
+	}
+
 	return nil
 }
 
@@ -397,6 +483,54 @@ func (c *Config) validateHTTPSinkConfig(hsc *HTTPSinkConfig) error {
 	return nil
 }
 
+func (c *Config) validateSyslogSinkConfig(sc *SyslogSinkConfig) error {
+	propagateSyslogDefaults(&sc.SyslogDefaults, c.SyslogDefaults)
+	if sc.Address == nil || len(strings.TrimSpace(*sc.Address)) == 0 {
+		return errors.New("address cannot be empty")
+	}
+	net := strings.ToLower(strings.TrimSpace(*sc.Net))
+	switch net {
+	case "tcp", "tcp4", "tcp6":
+	case "udp", "udp4", "udp6":
+		if *sc.TLS {
+			return errors.Newf("TLS is not supported with protocol %q", net)
+		}
+	case "":
+		net = "tcp"
+	default:
+		return errors.Newf("unknown protocol: %q", net)
+	}
+	sc.Net = &net
+	if len(*sc.AppName) == 0 {
+		return errors.New("app-name cannot be empty")
+	}
+
+	// Apply the auditable flag if set.
+	if *sc.Auditable {
+		bt := true
+		sc.Criticality = &bt
+	}
+	sc.Auditable = nil
+
+	return nil
+}
+
+func (c *Config) validateOTLPSinkConfig(oc *OTLPSinkConfig) error {
+	propagateOTLPDefaults(&oc.OTLPDefaults, c.OTLPDefaults)
+	if oc.Address == nil || len(*oc.Address) == 0 {
+		return errors.New("address cannot be empty")
+	}
+
+	// Apply the auditable flag if set.
+	if *oc.Auditable {
+		bt := true
+		oc.Criticality = &bt
+	}
+	oc.Auditable = nil
+
+	return nil
+}
+
 func normalizeDir(dir **string) error {
 	if *dir == nil {
 		return nil
@@ -431,6 +565,14 @@ func propagateHTTPDefaults(target *HTTPDefaults, source HTTPDefaults) {
 	propagateDefaults(target, source)
 }
 
+func propagateSyslogDefaults(target *SyslogDefaults, source SyslogDefaults) {
+	propagateDefaults(target, source)
+}
+
+func propagateOTLPDefaults(target *OTLPDefaults, source OTLPDefaults) {
+	propagateDefaults(target, source)
+}
+
 // propagateDefaults takes (target *T, source T) where T is a struct
 // and sets zero-valued exported fields in target to the values
 // from source (recursively for struct-valued fields).
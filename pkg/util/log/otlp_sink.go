@@ -0,0 +1,176 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"bytes"
+	"crypto/tls"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/cli/exit"
+	"github.com/cockroachdb/cockroach/pkg/util/log/severity"
+	"github.com/cockroachdb/errors"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// otlpInstrumentationLibrary is the name of the instrumentation library
+// reported in the exported log records.
+const otlpInstrumentationLibrary = "github.com/cockroachdb/cockroach/pkg/util/log"
+
+type otlpSinkOptions struct {
+	unsafeTLS bool
+	timeout   time.Duration
+}
+
+func newOTLPSink(url string, opt otlpSinkOptions) (*otlpSink, error) {
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return nil, errors.AssertionFailedf("http.DefaultTransport is not a http.Transport: %T", http.DefaultTransport)
+	}
+	transport = transport.Clone()
+	if opt.unsafeTLS {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	return &otlpSink{
+		client: http.Client{
+			Transport: transport,
+			Timeout:   opt.timeout,
+		},
+		address: url,
+		resource: &resourcepb.Resource{
+			Attributes: []*commonpb.KeyValue{
+				otlpStringAttribute("service.name", "cockroach"),
+				otlpStringAttribute("host.name", fullHostName),
+				otlpStringAttribute("process.pid", strconv.Itoa(fileNameConstants.pid)),
+			},
+		},
+	}, nil
+}
+
+// otlpSink exports log entries to a collector using the OpenTelemetry
+// protocol (OTLP) over HTTP, with protobuf encoding.
+type otlpSink struct {
+	client  http.Client
+	address string
+	// resource describes the process emitting the log records.
+	resource *resourcepb.Resource
+}
+
+// output implements the logSink interface.
+func (s *otlpSink) output(b []byte, opts sinkOutputOptions) error {
+	return s.outputBatch([]batchEntry{{b: b, entry: opts.entry}}, opts)
+}
+
+// outputBatch implements the batchSink interface.
+//
+// All the entries are exported in a single request.
+func (s *otlpSink) outputBatch(entries []batchEntry, _ sinkOutputOptions) error {
+	records := make([]*logspb.LogRecord, len(entries))
+	for i, e := range entries {
+		records[i] = makeOTLPLogRecord(e)
+	}
+	resourceLogs, err := proto.Marshal(&logspb.ResourceLogs{
+		Resource: s.resource,
+		InstrumentationLibraryLogs: []*logspb.InstrumentationLibraryLogs{{
+			InstrumentationLibrary: &commonpb.InstrumentationLibrary{
+				Name: otlpInstrumentationLibrary,
+			},
+			Logs: records,
+		}},
+	})
+	if err != nil {
+		return err
+	}
+	// The body is an ExportLogsServiceRequest, which only contains the
+	// repeated resource_logs field (field number 1). We encode it by
+	// hand, as the generated package for the collector service depends
+	// on gRPC.
+	body := protowire.AppendTag(nil, 1, protowire.BytesType)
+	body = protowire.AppendBytes(body, resourceLogs)
+
+	resp, err := s.client.Post(s.address, "application/x-protobuf", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close() // don't care about content
+	if resp.StatusCode >= 400 {
+		return HTTPLogError{
+			StatusCode: resp.StatusCode,
+			Address:    s.address}
+	}
+	return nil
+}
+
+// makeOTLPLogRecord converts a formatted log entry to an OTLP log record.
+func makeOTLPLogRecord(e batchEntry) *logspb.LogRecord {
+	r := &logspb.LogRecord{
+		Body: &commonpb.AnyValue{
+			Value: &commonpb.AnyValue_StringValue{
+				StringValue: string(bytes.TrimSuffix(e.b, []byte{'\n'})),
+			},
+		},
+	}
+	if e.entry.ts != 0 {
+		r.TimeUnixNano = uint64(e.entry.ts)
+		r.SeverityNumber = otlpSeverity(e.entry.sev)
+		r.SeverityText = e.entry.sev.String()
+		r.Attributes = []*commonpb.KeyValue{
+			otlpStringAttribute("channel", e.entry.ch.String()),
+		}
+	}
+	return r
+}
+
+// otlpSeverity maps a log severity to an OTLP severity number.
+func otlpSeverity(sev Severity) logspb.SeverityNumber {
+	switch sev {
+	case severity.INFO:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_INFO
+	case severity.WARNING:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_WARN
+	case severity.ERROR:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_ERROR
+	case severity.FATAL:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_FATAL
+	default:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_UNSPECIFIED
+	}
+}
+
+func otlpStringAttribute(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{
+		Key:   key,
+		Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}},
+	}
+}
+
+// active returns true if this sink is currently active.
+func (*otlpSink) active() bool {
+	return true
+}
+
+// attachHints attaches some hints about the location of the message
+// to the stack message.
+func (*otlpSink) attachHints(stacks []byte) []byte {
+	return stacks
+}
+
+// exitCode returns the exit code to use if the logger decides
+// to terminate because of an error in output().
+func (*otlpSink) exitCode() exit.Code {
+	return exit.LoggingNetCollectorUnavailable()
+}
@@ -0,0 +1,124 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log/channel"
+	"github.com/cockroachdb/cockroach/pkg/util/log/logconfig"
+	"github.com/stretchr/testify/require"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// TestOTLPSink verifies that buffered log events are exported to an OTLP
+// collector in a single request, one record per event.
+func TestOTLPSink(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	sc := ScopeWithoutShowLogs(t)
+	defer sc.Close(t)
+
+	requests := make(chan *logspb.ResourceLogs, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		// Decode the ExportLogsServiceRequest, which has a single
+		// resource_logs field.
+		num, typ, n := protowire.ConsumeTag(body)
+		if num != 1 || typ != protowire.BytesType {
+			t.Errorf("unexpected field %d of type %d", num, typ)
+			return
+		}
+		b, m := protowire.ConsumeBytes(body[n:])
+		if m < 0 {
+			t.Error(protowire.ParseError(m))
+			return
+		}
+		rl := &logspb.ResourceLogs{}
+		if err := proto.Unmarshal(b, rl); err != nil {
+			t.Error(err)
+			return
+		}
+		requests <- rl
+	}))
+	defer srv.Close()
+
+	// Set up a logging configuration with the server we've just set up
+	// as target for the OPS channel. The buffering makes the two events
+	// below be exported together.
+	address := srv.URL + "/v1/logs"
+	staleness := time.Second
+	cfg := logconfig.DefaultConfig()
+	cfg.Sinks.OTLPServers = map[string]*logconfig.OTLPSinkConfig{
+		"ops": {
+			OTLPDefaults: logconfig.OTLPDefaults{
+				Address: &address,
+				CommonSinkConfig: logconfig.CommonSinkConfig{
+					Buffering: logconfig.CommonBufferSinkConfigWrapper{
+						CommonBufferSinkConfig: logconfig.CommonBufferSinkConfig{
+							MaxStaleness: &staleness,
+						},
+					},
+				},
+			},
+			Channels: logconfig.SelectChannels(channel.OPS)},
+	}
+	// Derive a full config using the same directory as the
+	// TestLogScope.
+	require.NoError(t, cfg.Validate(&sc.logDir))
+
+	// Apply the configuration.
+	TestingResetActive()
+	cleanup, err := ApplyConfig(cfg)
+	require.NoError(t, err)
+	defer cleanup()
+
+	// Send two log events on the OPS channel.
+	Ops.Infof(context.Background(), "hello")
+	Ops.Errorf(context.Background(), "world")
+
+	var rl *logspb.ResourceLogs
+	select {
+	case <-time.After(10 * time.Second):
+		t.Fatal("timeout")
+	case rl = <-requests:
+	}
+
+	require.Len(t, rl.InstrumentationLibraryLogs, 1)
+	records := rl.InstrumentationLibraryLogs[0].Logs
+	require.Len(t, records, 2)
+	for i, exp := range []struct {
+		sev     logspb.SeverityNumber
+		message string
+	}{
+		{logspb.SeverityNumber_SEVERITY_NUMBER_INFO, `"message":"hello"`},
+		{logspb.SeverityNumber_SEVERITY_NUMBER_ERROR, `"message":"world"`},
+	} {
+		r := records[i]
+		require.Equal(t, exp.sev, r.SeverityNumber)
+		require.NotZero(t, r.TimeUnixNano)
+		require.Contains(t, r.Body.GetStringValue(), exp.message)
+		require.Len(t, r.Attributes, 1)
+		require.Equal(t, "channel", r.Attributes[0].Key)
+		require.Equal(t, "OPS", r.Attributes[0].Value.GetStringValue())
+	}
+}
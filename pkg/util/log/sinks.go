@@ -23,6 +23,20 @@ type sinkOutputOptions struct {
 	// forceSync forces synchronous operation of this output operation.
 	// That is, it will block until the output has been handled.
 	forceSync bool
+	// entry describes the log entry being output, for sinks whose wire
+	// protocol reports it out of band. It is left empty when the output
+	// does not correspond to a single log entry.
+	entry entryInfo
+}
+
+// entryInfo describes a log entry passed to a logSink.
+type entryInfo struct {
+	// sev is the severity of the entry.
+	sev Severity
+	// ch is the channel of the entry.
+	ch Channel
+	// ts is the timestamp of the entry, in nanoseconds since the epoch.
+	ts int64
 }
 
 // logSink abstracts the destination of logging events, after all
@@ -60,8 +74,29 @@ type logSink interface {
 	// emergencyOutput([]byte)
 }
 
+// batchSink is implemented by sinks whose wire protocol delimits
+// individual log entries. When such a sink is wrapped by a bufferSink,
+// the accumulated entries are passed to outputBatch separately instead
+// of being concatenated into a single output call.
+type batchSink interface {
+	logSink
+
+	// outputBatch emits multiple formatted entries to this sink. The
+	// entry field of opts is ignored; the per-entry information is
+	// provided by the batchEntry structs instead.
+	outputBatch(entries []batchEntry, opts sinkOutputOptions) error
+}
+
+// batchEntry is one of the entries passed to batchSink.outputBatch.
+type batchEntry struct {
+	b     []byte
+	entry entryInfo
+}
+
 var _ logSink = (*stderrSink)(nil)
 var _ logSink = (*fileSink)(nil)
 var _ logSink = (*fluentSink)(nil)
 var _ logSink = (*httpSink)(nil)
 var _ logSink = (*bufferSink)(nil)
+var _ batchSink = (*syslogSink)(nil)
+var _ batchSink = (*otlpSink)(nil)
@@ -0,0 +1,283 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/cli/exit"
+	"github.com/cockroachdb/cockroach/pkg/util/log/severity"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+type syslogSinkOptions struct {
+	network   string
+	tls       bool
+	caCert    string
+	unsafeTLS bool
+	facility  int
+	appName   string
+	timeout   time.Duration
+}
+
+// syslogSink represents a syslog server, to which log entries are sent
+// as RFC 5424 messages.
+type syslogSink struct {
+	// The network address of the syslog server.
+	network string
+	addr    string
+
+	// tlsConfig is the TLS configuration used to connect to the server,
+	// or nil if TLS is disabled.
+	tlsConfig *tls.Config
+
+	facility int
+	timeout  time.Duration
+
+	// header is the part of the message header that is the same for
+	// every message: HOSTNAME, APP-NAME and PROCID, followed by a space.
+	header string
+
+	// The sink can be shared by the loggers of multiple channels, which
+	// do not synchronize with each other.
+	mu struct {
+		syncutil.Mutex
+		// good indicates that the connection can be used.
+		good bool
+		conn net.Conn
+	}
+}
+
+func newSyslogSink(addr string, opt syslogSinkOptions) (*syslogSink, error) {
+	s := &syslogSink{
+		network:  opt.network,
+		addr:     addr,
+		facility: opt.facility,
+		timeout:  opt.timeout,
+		header: fmt.Sprintf("%s %s %d ",
+			syslogHeaderField(fullHostName, 255),
+			syslogHeaderField(opt.appName, 48),
+			fileNameConstants.pid),
+	}
+	if opt.tls {
+		s.tlsConfig = &tls.Config{InsecureSkipVerify: opt.unsafeTLS}
+		if opt.caCert != "" {
+			pem, err := ioutil.ReadFile(opt.caCert)
+			if err != nil {
+				return nil, errors.Wrap(err, "reading syslog CA certificate")
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, errors.Newf("no certificate found in %q", opt.caCert)
+			}
+			s.tlsConfig.RootCAs = pool
+		}
+	}
+	return s, nil
+}
+
+func (s *syslogSink) String() string {
+	return fmt.Sprintf("syslog:%s://%s", s.network, s.addr)
+}
+
+// active implements the logSink interface.
+func (s *syslogSink) active() bool { return true }
+
+// attachHints implements the logSink interface.
+func (s *syslogSink) attachHints(stacks []byte) []byte {
+	return stacks
+}
+
+// exitCode implements the logSink interface.
+func (s *syslogSink) exitCode() exit.Code {
+	return exit.LoggingNetCollectorUnavailable()
+}
+
+// output implements the logSink interface.
+func (s *syslogSink) output(b []byte, opts sinkOutputOptions) error {
+	return s.outputBatch([]batchEntry{{b: b, entry: opts.entry}}, opts)
+}
+
+// outputBatch implements the batchSink interface.
+func (s *syslogSink) outputBatch(entries []batchEntry, _ sinkOutputOptions) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.isStream() {
+		// Datagram protocols carry a single message per packet.
+		for _, e := range entries {
+			if err := s.writeWithRetryLocked(s.formatMessage(e)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// Stream protocols use octet counting to delimit messages (RFC
+	// 6587), so the whole batch can be sent in a single write.
+	var buf bytes.Buffer
+	for _, e := range entries {
+		msg := s.formatMessage(e)
+		buf.WriteString(strconv.Itoa(len(msg)))
+		buf.WriteByte(' ')
+		buf.Write(msg)
+	}
+	return s.writeWithRetryLocked(buf.Bytes())
+}
+
+// isStream returns true if the sink uses a stream-oriented protocol.
+func (s *syslogSink) isStream() bool {
+	return strings.HasPrefix(s.network, "tcp")
+}
+
+// formatMessage formats a log entry as an RFC 5424 syslog message, of
+// the form "<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID SD MSG". The
+// MSGID is the logging channel, the structured data (SD) is always
+// empty and the formatted entry is the MSG.
+func (s *syslogSink) formatMessage(e batchEntry) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<%d>1 ", s.facility*8+syslogSeverity(e.entry.sev))
+	if e.entry.ts != 0 {
+		buf.WriteString(timeutil.Unix(0, e.entry.ts).UTC().Format("2006-01-02T15:04:05.000000Z07:00"))
+		buf.WriteByte(' ')
+	} else {
+		buf.WriteString("- ")
+	}
+	buf.WriteString(s.header)
+	if e.entry.ts != 0 {
+		buf.WriteString(e.entry.ch.String())
+	} else {
+		buf.WriteByte('-')
+	}
+	buf.WriteString(" - ")
+	buf.Write(bytes.TrimSuffix(e.b, []byte{'\n'}))
+	return buf.Bytes()
+}
+
+// syslogSeverity maps a log severity to a syslog severity.
+func syslogSeverity(sev Severity) int {
+	switch sev {
+	case severity.INFO:
+		return 6 // informational
+	case severity.WARNING:
+		return 4 // warning
+	case severity.ERROR:
+		return 3 // error
+	case severity.FATAL:
+		return 2 // critical
+	default:
+		return 5 // notice
+	}
+}
+
+// syslogHeaderField sanitizes a header field of a syslog message,
+// which must consist of at most maxLen printable US-ASCII characters.
+func syslogHeaderField(s string, maxLen int) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return '_'
+		}
+		return r
+	}, s)
+	if len(s) == 0 {
+		return "-"
+	}
+	if len(s) > maxLen {
+		s = s[:maxLen]
+	}
+	return s
+}
+
+// writeWithRetryLocked writes to the server, reconnecting and retrying
+// once if the first write fails. The write is not retried if part of b
+// was already sent, since the server would then receive the start of
+// the message twice.
+func (s *syslogSink) writeWithRetryLocked(b []byte) error {
+	n, err := s.tryWriteLocked(b)
+	if err == nil || n > 0 {
+		return err
+	}
+	if err := s.ensureConnLocked(); err != nil {
+		return err
+	}
+	_, err = s.tryWriteLocked(b)
+	return err
+}
+
+func (s *syslogSink) closeLocked() {
+	s.mu.good = false
+	if s.mu.conn != nil {
+		if err := s.mu.conn.Close(); err != nil {
+			fmt.Fprintf(OrigStderr, "%s: error closing connection: %v\n", s, err)
+		}
+		s.mu.conn = nil
+	}
+}
+
+func (s *syslogSink) ensureConnLocked() error {
+	if s.mu.good {
+		return nil
+	}
+	s.closeLocked()
+	var err error
+	dialer := &net.Dialer{Timeout: s.timeout}
+	if s.tlsConfig != nil {
+		s.mu.conn, err = tls.DialWithDialer(dialer, s.network, s.addr, s.tlsConfig)
+	} else {
+		s.mu.conn, err = dialer.Dial(s.network, s.addr)
+	}
+	if err != nil {
+		fmt.Fprintf(OrigStderr, "%s: error dialing syslog server: %v\n", s, err)
+		return err
+	}
+	fmt.Fprintf(OrigStderr, "%s: connection to syslog server resumed\n", s)
+	s.mu.good = true
+	return nil
+}
+
+// tryWriteLocked writes b to the server and returns the number of bytes
+// written. A short write is reported as io.ErrShortWrite. The connection
+// is marked bad on any error, as the server may have received a partial
+// message.
+func (s *syslogSink) tryWriteLocked(b []byte) (int, error) {
+	if !s.mu.good {
+		return 0, errNoConn
+	}
+	if s.timeout > 0 {
+		if err := s.mu.conn.SetWriteDeadline(timeutil.Now().Add(s.timeout)); err != nil {
+			// An error here is suggestive of a bug in the Go runtime.
+			fmt.Fprintf(OrigStderr, "%s: set write deadline error: %v\n", s, err)
+			s.mu.good = false
+			return 0, err
+		}
+	}
+	n, err := s.mu.conn.Write(b)
+	if err == nil && n < len(b) {
+		err = io.ErrShortWrite
+	}
+	if err != nil {
+		fmt.Fprintf(OrigStderr, "%s: logging error (%d/%d bytes written): %v\n",
+			s, n, len(b), err)
+		s.mu.good = false
+	}
+	return n, err
+}
@@ -0,0 +1,216 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log/channel"
+	"github.com/cockroachdb/cockroach/pkg/util/log/logconfig"
+	"github.com/cockroachdb/cockroach/pkg/util/log/severity"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSyslogSink verifies that log events are sent to a syslog server
+// as RFC 5424 messages.
+func TestSyslogSink(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	sc := ScopeWithoutShowLogs(t)
+	defer sc.Close(t)
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, conn.Close()) }()
+
+	// Set up a logging configuration with the server we've just set up
+	// as target for the OPS channel.
+	address := conn.LocalAddr().String()
+	network := "udp"
+	facility := logconfig.SyslogFacility("local0")
+	cfg := logconfig.DefaultConfig()
+	cfg.Sinks.SyslogServers = map[string]*logconfig.SyslogSinkConfig{
+		"ops": {
+			SyslogDefaults: logconfig.SyslogDefaults{
+				Address:  &address,
+				Net:      &network,
+				Facility: &facility,
+			},
+			Channels: logconfig.SelectChannels(channel.OPS)},
+	}
+	// Derive a full config using the same directory as the
+	// TestLogScope.
+	require.NoError(t, cfg.Validate(&sc.logDir))
+
+	// Apply the configuration.
+	TestingResetActive()
+	cleanup, err := ApplyConfig(cfg)
+	require.NoError(t, err)
+	defer cleanup()
+
+	// Send a log event on the OPS channel.
+	Ops.Warningf(context.Background(), "hello world")
+
+	// Check that the event was indeed sent via the syslog sink.
+	buf := make([]byte, 65536)
+	require.NoError(t, conn.SetReadDeadline(timeutil.Now().Add(5*time.Second)))
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	msg := string(buf[:n])
+	t.Log(msg)
+
+	// local0 (16) * 8 + warning (4) = 132.
+	re := regexp.MustCompile(
+		`^<132>1 \d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{6}Z \S+ cockroach \d+ OPS - \{.*"message":"hello world".*\}$`)
+	require.Regexp(t, re, msg)
+}
+
+// TestSyslogSinkFraming verifies that batches of entries sent over a
+// stream protocol are framed using octet counting.
+func TestSyslogSinkFraming(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, err := newSyslogSink("unused", syslogSinkOptions{
+		network:  "tcp",
+		facility: 1, // user
+		appName:  "test app",
+	})
+	require.NoError(t, err)
+
+	client, server := net.Pipe()
+	received := make(chan []byte)
+	go func() {
+		b, err := ioutil.ReadAll(server)
+		if err != nil {
+			t.Error(err)
+		}
+		received <- b
+	}()
+	s.mu.conn = client
+	s.mu.good = true
+
+	ts := time.Date(2022, 1, 2, 3, 4, 5, 6000, time.UTC).UnixNano()
+	require.NoError(t, s.outputBatch([]batchEntry{
+		{b: []byte("first\n"), entry: entryInfo{sev: severity.INFO, ch: channel.HEALTH, ts: ts}},
+		{b: []byte("second"), entry: entryInfo{sev: severity.ERROR, ch: channel.SESSIONS, ts: ts}},
+	}, sinkOutputOptions{}))
+	require.NoError(t, client.Close())
+
+	header := fmt.Sprintf("%s test_app %d ", syslogHeaderField(fullHostName, 255), fileNameConstants.pid)
+	first := "<14>1 2022-01-02T03:04:05.000006Z " + header + "HEALTH - first"
+	second := "<11>1 2022-01-02T03:04:05.000006Z " + header + "SESSIONS - second"
+	expected := fmt.Sprintf("%d %s%d %s", len(first), first, len(second), second)
+	require.Equal(t, expected, string(<-received))
+}
+
+// limitedConn is a net.Conn which writes at most limit bytes per call,
+// without reporting an error for the bytes it drops.
+type limitedConn struct {
+	net.Conn
+	limit  int
+	writes int
+}
+
+func (c *limitedConn) Write(b []byte) (int, error) {
+	c.writes++
+	if len(b) > c.limit {
+		b = b[:c.limit]
+	}
+	if len(b) == 0 {
+		return 0, nil
+	}
+	return c.Conn.Write(b)
+}
+
+// TestSyslogSinkShortWrite verifies that short writes are reported as
+// errors, and that a write is only retried on a new connection if
+// nothing was sent on the previous one.
+func TestSyslogSinkShortWrite(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, listener.Close()) }()
+
+	s, err := newSyslogSink(listener.Addr().String(), syslogSinkOptions{network: "tcp"})
+	require.NoError(t, err)
+	defer func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.closeLocked()
+	}()
+
+	// setConn installs a connection which writes at most limit bytes per
+	// call, and returns it with a channel receiving what the server side of
+	// the connection read.
+	setConn := func(limit int) (*limitedConn, chan []byte) {
+		client, server := net.Pipe()
+		received := make(chan []byte, 1)
+		go func() {
+			b, err := ioutil.ReadAll(server)
+			if err != nil {
+				t.Error(err)
+			}
+			received <- b
+		}()
+		conn := &limitedConn{Conn: client, limit: limit}
+		s.mu.conn = conn
+		s.mu.good = true
+		return conn, received
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t.Run("partial", func(t *testing.T) {
+		conn, received := setConn(3)
+		err := s.writeWithRetryLocked([]byte("hello"))
+		require.True(t, errors.Is(err, io.ErrShortWrite), "%v", err)
+		// The rest of the message is not sent on a new connection.
+		require.Equal(t, 1, conn.writes)
+		require.False(t, s.mu.good)
+		require.NoError(t, conn.Close())
+		require.Equal(t, "hel", string(<-received))
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		conn, _ := setConn(0)
+		accepted := make(chan []byte, 1)
+		go func() {
+			c, err := listener.Accept()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer c.Close()
+			b := make([]byte, len("hello"))
+			if _, err := io.ReadFull(c, b); err != nil {
+				t.Error(err)
+			}
+			accepted <- b
+		}()
+		// Nothing was sent on the first connection, so the message is sent
+		// again on a new one.
+		require.NoError(t, s.writeWithRetryLocked([]byte("hello")))
+		require.Equal(t, 1, conn.writes)
+		require.True(t, s.mu.good)
+		require.Equal(t, "hello", string(<-accepted))
+	})
+}
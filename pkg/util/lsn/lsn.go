@@ -0,0 +1,78 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package lsn implements the log sequence numbers used by the logical
+// replication protocol of PostgreSQL to identify positions in the stream of
+// changes.
+package lsn
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/errors"
+)
+
+// LSN is a log sequence number. Since CockroachDB doesn't have a write-ahead
+// log shared by all the changes, an LSN is derived from the MVCC timestamp of
+// the changes. Each wall time, in nanoseconds, has two LSNs: the LSN of the
+// changes committed at this wall time, and the LSN of their commit, which is
+// the next one.
+type LSN uint64
+
+// FromTimestamp returns the LSN of the changes committed at the given
+// timestamp. All the changes with the same wall time share an LSN.
+func FromTimestamp(ts hlc.Timestamp) LSN {
+	return LSN(ts.WallTime) * 2
+}
+
+// CommitLSN returns the LSN of the commit of the changes with the LSN l. It is
+// larger than l, and smaller than the LSN of the changes committed at any
+// later wall time.
+func (l LSN) CommitLSN() LSN {
+	return l | 1
+}
+
+// ResumeTimestamp returns the timestamp after which to look for the changes
+// that follow the LSN, that is the highest timestamp whose changes are
+// committed at or before the LSN.
+func (l LSN) ResumeTimestamp() hlc.Timestamp {
+	var wallTime int64
+	if l > 0 {
+		wallTime = int64((l - 1) / 2)
+	}
+	return hlc.Timestamp{WallTime: wallTime, Logical: math.MaxInt32}
+}
+
+// String implements the fmt.Stringer interface. The LSN is formatted as two
+// hexadecimal numbers, the high and the low 32 bits, separated by a slash.
+func (l LSN) String() string {
+	return fmt.Sprintf("%X/%X", uint32(l>>32), uint32(l))
+}
+
+// Parse parses an LSN in the format produced by String.
+func Parse(s string) (LSN, error) {
+	parts := strings.Split(s, "/")
+	if len(parts) != 2 {
+		return 0, errors.Errorf("invalid LSN %q", s)
+	}
+	hi, err := strconv.ParseUint(parts[0], 16, 32)
+	if err != nil {
+		return 0, errors.Errorf("invalid LSN %q", s)
+	}
+	lo, err := strconv.ParseUint(parts[1], 16, 32)
+	if err != nil {
+		return 0, errors.Errorf("invalid LSN %q", s)
+	}
+	return LSN(hi<<32 | lo), nil
+}
@@ -0,0 +1,54 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package lsn
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLSN(t *testing.T) {
+	for _, tc := range []struct {
+		lsn LSN
+		str string
+	}{
+		{0, "0/0"},
+		{1, "0/1"},
+		{0x16B3748, "0/16B3748"},
+		{0x16D2E5CE7A5C1F00, "16D2E5CE/7A5C1F00"},
+	} {
+		require.Equal(t, tc.str, tc.lsn.String())
+		parsed, err := Parse(tc.str)
+		require.NoError(t, err)
+		require.Equal(t, tc.lsn, parsed)
+	}
+
+	for _, s := range []string{"", "0", "0/", "/0", "G/0", "0/1/2", "100000000/0"} {
+		_, err := Parse(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestResumeTimestamp(t *testing.T) {
+	ts := hlc.Timestamp{WallTime: 1640995200000000000, Logical: 3}
+	l := FromTimestamp(ts)
+	commit := l.CommitLSN()
+	require.Less(t, uint64(l), uint64(commit))
+	require.Less(t, uint64(commit), uint64(FromTimestamp(hlc.Timestamp{WallTime: ts.WallTime + 1})))
+
+	// The changes are only skipped when resuming after their commit.
+	require.True(t, l.ResumeTimestamp().Less(ts))
+	require.True(t, ts.Less(commit.ResumeTimestamp()))
+	require.True(t, commit.ResumeTimestamp().Less(hlc.Timestamp{WallTime: ts.WallTime + 1}))
+	require.Equal(t, l, FromTimestamp(commit.ResumeTimestamp()))
+}
@@ -71,12 +71,24 @@ func (cs *childSet) init(labels []string) {
 
 func (cs *childSet) Each(
 	labels []*io_prometheus_client.LabelPair, f func(metric *io_prometheus_client.Metric),
+) {
+	cs.each(labels, func(cm childMetric, childLabels []*io_prometheus_client.LabelPair) {
+		pm := cm.ToPrometheusMetric()
+		pm.Label = childLabels
+		f(pm)
+	})
+}
+
+// each calls the passed function with each of the children and their labels,
+// which are the parent's labels augmented with the label values of the child.
+func (cs *childSet) each(
+	labels []*io_prometheus_client.LabelPair,
+	f func(cm childMetric, childLabels []*io_prometheus_client.LabelPair),
 ) {
 	cs.mu.Lock()
 	defer cs.mu.Unlock()
 	cs.mu.tree.Ascend(func(item btree.Item) (wantMore bool) {
 		cm := item.(childMetric)
-		pm := cm.ToPrometheusMetric()
 		childLabels := make([]*io_prometheus_client.LabelPair, 0, len(labels)+len(cs.labels))
 		childLabels = append(childLabels, labels...)
 		lvs := cm.labelValues()
@@ -86,8 +98,7 @@ func (cs *childSet) Each(
 				Value: &lvs[i],
 			})
 		}
-		pm.Label = childLabels
-		f(pm)
+		f(cm, childLabels)
 		return true
 	})
 }
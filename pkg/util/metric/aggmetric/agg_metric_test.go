@@ -13,6 +13,7 @@ package aggmetric_test
 import (
 	"bufio"
 	"bytes"
+	"fmt"
 	"sort"
 	"strings"
 	"testing"
@@ -22,6 +23,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/metric"
 	"github.com/cockroachdb/cockroach/pkg/util/metric/aggmetric"
+	"github.com/codahale/hdrhistogram"
 	prometheusgo "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/require"
 )
@@ -185,6 +187,25 @@ histo_gram_sum{tenant_id="3"} 0`,
 	})
 }
 
+func TestAggHistogramEachSnapshot(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	h := aggmetric.NewHistogram(metric.Metadata{
+		Name: "histo_gram",
+	}, base.DefaultHistogramWindowInterval(), 100, 1, "tenant_id")
+	h.AddChild(roachpb.MakeTenantID(2).String()).RecordValue(10)
+	h.AddChild(roachpb.MakeTenantID(3).String()).RecordValue(90)
+	require.Equal(t, int64(2), h.Snapshot().TotalCount())
+
+	var children []string
+	h.EachSnapshot(nil, func(labels []*prometheusgo.LabelPair, snapshot *hdrhistogram.Histogram) {
+		require.Len(t, labels, 1)
+		children = append(children, fmt.Sprintf("%s=%s: %d",
+			labels[0].GetName(), labels[0].GetValue(), snapshot.Max()))
+	})
+	require.Equal(t, []string{"tenant_id=2: 10", "tenant_id=3: 91"}, children)
+}
+
 func TestAggMetricBuilder(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
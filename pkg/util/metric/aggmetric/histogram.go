@@ -31,6 +31,7 @@ type AggHistogram struct {
 var _ metric.Iterable = (*AggHistogram)(nil)
 var _ metric.PrometheusIterable = (*AggHistogram)(nil)
 var _ metric.PrometheusExportable = (*AggHistogram)(nil)
+var _ metric.HistogramSnapshotIterable = (*AggHistogram)(nil)
 
 // NewHistogram constructs a new AggHistogram.
 func NewHistogram(
@@ -90,6 +91,22 @@ func (a *AggHistogram) Windowed() (*hdrhistogram.Histogram, time.Duration) {
 	return a.h.Windowed()
 }
 
+// Snapshot returns a copy of the cumulative histogram data of all the
+// children.
+func (a *AggHistogram) Snapshot() *hdrhistogram.Histogram {
+	return a.h.Snapshot()
+}
+
+// EachSnapshot is part of the metric.HistogramSnapshotIterable interface.
+func (a *AggHistogram) EachSnapshot(
+	labels []*io_prometheus_client.LabelPair,
+	f func(labels []*io_prometheus_client.LabelPair, snapshot *hdrhistogram.Histogram),
+) {
+	a.each(labels, func(cm childMetric, childLabels []*io_prometheus_client.LabelPair) {
+		f(childLabels, cm.(*Histogram).h.Snapshot())
+	})
+}
+
 // AddChild adds a Counter to this AggCounter. This method panics if a Counter
 // already exists for this set of labelVals.
 func (a *AggHistogram) AddChild(labelVals ...string) *Histogram {
@@ -0,0 +1,401 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package metric
+
+import (
+	"context"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+	"github.com/codahale/hdrhistogram"
+	prometheusgo "github.com/prometheus/client_model/go"
+	collectorpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc"
+)
+
+var errNoOTLPEndpoint = errors.New("external.otlp.metrics.endpoint is not set")
+
+const (
+	// otlpInstrumentationLibrary is the name of the instrumentation library
+	// reported in the exported metrics.
+	otlpInstrumentationLibrary = "github.com/cockroachdb/cockroach/pkg/util/metric"
+
+	// otlpMaxHistogramScale is the scale at which histograms are exported
+	// if their values fit in otlpMaxHistogramBuckets buckets. At this
+	// scale, each power of two is split into 16 buckets, which is about
+	// the precision of the latency histograms.
+	otlpMaxHistogramScale = 4
+	// otlpMaxHistogramBuckets is the maximum number of buckets of an
+	// exported histogram. Histograms with a wider range of values are
+	// exported at a lower scale.
+	otlpMaxHistogramBuckets = 160
+)
+
+// OTLPExporter scrapes registries for metrics and pushes them to an
+// OpenTelemetry collector using the OTLP/gRPC protocol. Counters are
+// exported as cumulative sums, gauges as gauges and histograms as
+// exponential histograms.
+// It is NOT thread-safe.
+//
+//	oe := MakeOTLPExporter()
+//	oe.ScrapeRegistry(nodeRegistry, includeChildMetrics)
+//	oe.ScrapeRegistry(storeOneRegistry, includeChildMetrics)
+//	...
+//	oe.Push(ctx, endpoint, resourceAttrs)
+type OTLPExporter struct {
+	// startTime is the start of the time interval over which the
+	// cumulative metrics are reported.
+	startTime time.Time
+	metrics   []*metricspb.Metric
+
+	// conn is the connection to the collector at endpoint, if any.
+	endpoint string
+	conn     *grpc.ClientConn
+}
+
+// HistogramSnapshotter is implemented by the histograms which expose the
+// cumulative HDR histogram of their samples. The OTLP exporter converts it
+// to an exponential histogram.
+type HistogramSnapshotter interface {
+	Snapshot() *hdrhistogram.Histogram
+}
+
+// HistogramSnapshotIterable is an extension of HistogramSnapshotter to
+// indicate that this histogram is comprised of children histograms, like
+// PrometheusIterable.
+type HistogramSnapshotIterable interface {
+	HistogramSnapshotter
+
+	// EachSnapshot takes a slice of label pairs associated with the parent
+	// histogram and calls the passed function with the labels and the
+	// cumulative HDR histogram of each of the children.
+	EachSnapshot(
+		[]*prometheusgo.LabelPair, func([]*prometheusgo.LabelPair, *hdrhistogram.Histogram),
+	)
+}
+
+// MakeOTLPExporter returns an initialized OTLP exporter.
+func MakeOTLPExporter() OTLPExporter {
+	return OTLPExporter{startTime: timeutil.Now()}
+}
+
+// ScrapeRegistry scrapes all metrics contained in the registry, holding on
+// only to the scraped data until the next call to Push.
+func (oe *OTLPExporter) ScrapeRegistry(registry *Registry, includeChildMetrics bool) {
+	labels := registry.getLabels()
+	startTime := uint64(oe.startTime.UnixNano())
+	now := uint64(timeutil.Now().UnixNano())
+	registry.Each(func(_ string, v interface{}) {
+		prom, ok := v.(PrometheusExportable)
+		if !ok {
+			return
+		}
+		m := &metricspb.Metric{
+			Name:        prom.GetName(),
+			Description: prom.GetHelp(),
+		}
+		if iterable, ok := v.(Iterable); ok {
+			m.Unit = otlpUnit(iterable.GetUnit())
+		}
+		if hist, ok := v.(HistogramSnapshotter); ok {
+			pointLabels := append(labels[:len(labels):len(labels)], prom.GetLabels()...)
+			eh := &metricspb.ExponentialHistogram{
+				AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+				DataPoints: []*metricspb.ExponentialHistogramDataPoint{
+					makeOTLPExponentialHistogramDataPoint(pointLabels, hist.Snapshot(), startTime, now),
+				},
+			}
+			if histIter, ok := v.(HistogramSnapshotIterable); ok && includeChildMetrics {
+				histIter.EachSnapshot(pointLabels, func(
+					childLabels []*prometheusgo.LabelPair, snapshot *hdrhistogram.Histogram,
+				) {
+					eh.DataPoints = append(eh.DataPoints,
+						makeOTLPExponentialHistogramDataPoint(childLabels, snapshot, startTime, now))
+				})
+			}
+			m.Data = &metricspb.Metric_ExponentialHistogram{ExponentialHistogram: eh}
+			oe.metrics = append(oe.metrics, m)
+			return
+		}
+		points := []*prometheusgo.Metric{prom.ToPrometheusMetric()}
+		points[0].Label = append(labels, prom.GetLabels()...)
+		if promIter, ok := v.(PrometheusIterable); ok && includeChildMetrics {
+			promIter.Each(points[0].Label, func(child *prometheusgo.Metric) {
+				points = append(points, child)
+			})
+		}
+		if setOTLPData(m, prom.GetType(), points, startTime, now) {
+			oe.metrics = append(oe.metrics, m)
+		}
+	})
+}
+
+// Push sends the metrics scraped since the last call to Push to the
+// collector at the given host:port. The resource attributes describe the
+// node in addition to the default service.name and host.name ones, which
+// they override.
+func (oe *OTLPExporter) Push(
+	ctx context.Context, endpoint string, resourceAttrs map[string]string,
+) error {
+	// Regardless of whether the export fails, clear metrics. Only the
+	// latest values are pushed, like for Graphite.
+	defer func() { oe.metrics = nil }()
+	if endpoint == "" {
+		return errNoOTLPEndpoint
+	}
+	if err := oe.ensureConn(ctx, endpoint); err != nil {
+		return err
+	}
+	resource, err := makeOTLPResource(resourceAttrs)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	_, err = collectorpb.NewMetricsServiceClient(oe.conn).Export(ctx, &collectorpb.ExportMetricsServiceRequest{
+		ResourceMetrics: []*metricspb.ResourceMetrics{{
+			Resource: resource,
+			InstrumentationLibraryMetrics: []*metricspb.InstrumentationLibraryMetrics{{
+				InstrumentationLibrary: &commonpb.InstrumentationLibrary{
+					Name: otlpInstrumentationLibrary,
+				},
+				Metrics: oe.metrics,
+			}},
+		}},
+	})
+	return err
+}
+
+// Close closes the connection to the collector, if any.
+func (oe *OTLPExporter) Close() error {
+	if oe.conn == nil {
+		return nil
+	}
+	err := oe.conn.Close()
+	oe.endpoint, oe.conn = "", nil
+	return err
+}
+
+// ensureConn sets up a connection to the collector at endpoint, closing
+// the connection to the previous endpoint if it changed.
+func (oe *OTLPExporter) ensureConn(ctx context.Context, endpoint string) error {
+	if oe.conn != nil && oe.endpoint == endpoint {
+		return nil
+	}
+	if err := oe.Close(); err != nil {
+		return err
+	}
+	// Only insecure connections to the collector are supported for now,
+	// like for the trace collector.
+	//lint:ignore SA1019 grpc.WithInsecure is deprecated
+	conn, err := grpc.DialContext(ctx, endpoint, grpc.WithInsecure())
+	if err != nil {
+		return err
+	}
+	oe.endpoint, oe.conn = endpoint, conn
+	return nil
+}
+
+// ParseOTLPResourceAttributes parses a comma-separated list of key=value
+// pairs, in the format of the OTEL_RESOURCE_ATTRIBUTES environment
+// variable.
+func ParseOTLPResourceAttributes(s string) (map[string]string, error) {
+	attrs := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		eq := strings.IndexByte(pair, '=')
+		if eq <= 0 {
+			return nil, errors.Newf("invalid resource attribute %q, expected key=value", pair)
+		}
+		attrs[strings.TrimSpace(pair[:eq])] = strings.TrimSpace(pair[eq+1:])
+	}
+	return attrs, nil
+}
+
+func makeOTLPResource(attrs map[string]string) (*resourcepb.Resource, error) {
+	h, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+	all := map[string]string{
+		"service.name": "cockroach",
+		"host.name":    h,
+	}
+	for k, v := range attrs {
+		all[k] = v
+	}
+	keys := make([]string, 0, len(all))
+	for k := range all {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	resource := &resourcepb.Resource{Attributes: make([]*commonpb.KeyValue, len(keys))}
+	for i, k := range keys {
+		resource.Attributes[i] = otlpStringAttribute(k, all[k])
+	}
+	return resource, nil
+}
+
+// setOTLPData fills in the data of m from the given Prometheus data
+// points of the given type. It returns false if the type cannot be
+// exported. Histograms are exported from their HDR histograms instead,
+// see makeOTLPExponentialHistogramDataPoint.
+func setOTLPData(
+	m *metricspb.Metric,
+	typ *prometheusgo.MetricType,
+	points []*prometheusgo.Metric,
+	startTime, now uint64,
+) bool {
+	switch *typ {
+	case prometheusgo.MetricType_COUNTER:
+		sum := &metricspb.Sum{
+			AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+			IsMonotonic:            true,
+		}
+		for _, p := range points {
+			dp := makeOTLPNumberDataPoint(p, p.GetCounter().GetValue(), now)
+			dp.StartTimeUnixNano = startTime
+			sum.DataPoints = append(sum.DataPoints, dp)
+		}
+		m.Data = &metricspb.Metric_Sum{Sum: sum}
+	case prometheusgo.MetricType_GAUGE:
+		gauge := &metricspb.Gauge{}
+		for _, p := range points {
+			gauge.DataPoints = append(gauge.DataPoints, makeOTLPNumberDataPoint(p, p.GetGauge().GetValue(), now))
+		}
+		m.Data = &metricspb.Metric_Gauge{Gauge: gauge}
+	default:
+		return false
+	}
+	return true
+}
+
+func makeOTLPNumberDataPoint(
+	p *prometheusgo.Metric, value float64, now uint64,
+) *metricspb.NumberDataPoint {
+	return &metricspb.NumberDataPoint{
+		Attributes:   otlpAttributes(p.Label),
+		TimeUnixNano: now,
+		Value:        &metricspb.NumberDataPoint_AsDouble{AsDouble: value},
+	}
+}
+
+// makeOTLPExponentialHistogramDataPoint converts the given HDR histogram
+// to an exponential histogram data point.
+//
+// The samples of each HDR bucket are assigned to the exponential bucket
+// that contains the highest value equivalent to the HDR bucket. The
+// exponential buckets are (base^index, base^(index+1)], with
+// base = 2^(2^-scale); the scale is reduced until all the values fit in
+// otlpMaxHistogramBuckets buckets.
+func makeOTLPExponentialHistogramDataPoint(
+	labels []*prometheusgo.LabelPair, snapshot *hdrhistogram.Histogram, startTime, now uint64,
+) *metricspb.ExponentialHistogramDataPoint {
+	dp := &metricspb.ExponentialHistogramDataPoint{
+		Attributes:        otlpAttributes(labels),
+		StartTimeUnixNano: startTime,
+		TimeUnixNano:      now,
+		Count:             uint64(snapshot.TotalCount()),
+		// The HDR histogram doesn't keep track of the sum of the samples,
+		// so we approximate it from their mean.
+		Sum: snapshot.Mean() * float64(snapshot.TotalCount()),
+	}
+	var indexes []int32
+	var counts []uint64
+	for _, bar := range snapshot.Distribution() {
+		if bar.Count == 0 {
+			continue
+		}
+		if bar.To <= 0 {
+			dp.ZeroCount += uint64(bar.Count)
+			continue
+		}
+		indexes = append(indexes, otlpBucketIndex(float64(bar.To), otlpMaxHistogramScale))
+		counts = append(counts, uint64(bar.Count))
+	}
+
+	// The HDR buckets are sorted by value, so the indexes are sorted too.
+	scale := int32(otlpMaxHistogramScale)
+	for len(indexes) > 0 && indexes[len(indexes)-1]-indexes[0] >= otlpMaxHistogramBuckets {
+		// Halving the resolution merges pairs of adjacent buckets.
+		scale--
+		for i := range indexes {
+			indexes[i] >>= 1
+		}
+	}
+	dp.Scale = scale
+	if len(indexes) == 0 {
+		return dp
+	}
+	dp.Positive = &metricspb.ExponentialHistogramDataPoint_Buckets{Offset: indexes[0]}
+	for i, idx := range indexes {
+		pos := int(idx - indexes[0])
+		for len(dp.Positive.BucketCounts) <= pos {
+			dp.Positive.BucketCounts = append(dp.Positive.BucketCounts, 0)
+		}
+		dp.Positive.BucketCounts[pos] += counts[i]
+	}
+	return dp
+}
+
+// otlpBucketIndex returns the index of the exponential bucket containing
+// the positive value v at the given scale.
+func otlpBucketIndex(v float64, scale int) int32 {
+	return int32(math.Ceil(math.Log2(v)*math.Ldexp(1, scale))) - 1
+}
+
+func otlpAttributes(labels []*prometheusgo.LabelPair) []*commonpb.KeyValue {
+	if len(labels) == 0 {
+		return nil
+	}
+	attrs := make([]*commonpb.KeyValue, len(labels))
+	for i, l := range labels {
+		attrs[i] = otlpStringAttribute(l.GetName(), l.GetValue())
+	}
+	return attrs
+}
+
+func otlpStringAttribute(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{
+		Key:   key,
+		Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}},
+	}
+}
+
+// otlpUnit returns the UCUM unit for the given unit, as recommended by
+// the OpenTelemetry specification.
+func otlpUnit(unit Unit) string {
+	switch unit {
+	case Unit_BYTES:
+		return "By"
+	case Unit_COUNT:
+		return "1"
+	case Unit_NANOSECONDS, Unit_TIMESTAMP_NS:
+		return "ns"
+	case Unit_SECONDS, Unit_TIMESTAMP_SEC:
+		return "s"
+	case Unit_PERCENT:
+		return "%"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package metric
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	collectorpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/grpc"
+)
+
+type testMetricsCollector struct {
+	collectorpb.UnimplementedMetricsServiceServer
+	requests chan *collectorpb.ExportMetricsServiceRequest
+}
+
+// Export implements the collectorpb.MetricsServiceServer interface.
+func (c *testMetricsCollector) Export(
+	_ context.Context, req *collectorpb.ExportMetricsServiceRequest,
+) (*collectorpb.ExportMetricsServiceResponse, error) {
+	c.requests <- req
+	return &collectorpb.ExportMetricsServiceResponse{}, nil
+}
+
+func TestOTLPExporter(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	collector := &testMetricsCollector{
+		requests: make(chan *collectorpb.ExportMetricsServiceRequest, 1),
+	}
+	srv := grpc.NewServer()
+	collectorpb.RegisterMetricsServiceServer(srv, collector)
+	go func() { _ = srv.Serve(ln) }()
+	defer srv.Stop()
+
+	r1, r2 := NewRegistry(), NewRegistry()
+	r2.AddLabel("store", "2")
+
+	c := NewCounter(Metadata{Name: "one.counter", Unit: Unit_COUNT})
+	c.Inc(3)
+	r1.AddMetric(c)
+	g := NewGauge(Metadata{Name: "two.gauge", Unit: Unit_BYTES})
+	g.Update(7)
+	r2.AddMetric(g)
+	h := NewHistogram(Metadata{Name: "one.histogram", Unit: Unit_NANOSECONDS}, time.Minute, 1000, 1)
+	for _, v := range []int64{0, 1, 2, 4, 100} {
+		h.RecordValue(v)
+	}
+	r1.AddMetric(h)
+
+	oe := MakeOTLPExporter()
+	defer func() { require.NoError(t, oe.Close()) }()
+	const includeChildMetrics = false
+	oe.ScrapeRegistry(r1, includeChildMetrics)
+	oe.ScrapeRegistry(r2, includeChildMetrics)
+	require.NoError(t, oe.Push(context.Background(), ln.Addr().String(), map[string]string{
+		"service.name": "crdb",
+		"region":       "us-east1",
+	}))
+	require.Empty(t, oe.metrics)
+
+	var req *collectorpb.ExportMetricsServiceRequest
+	select {
+	case <-time.After(10 * time.Second):
+		t.Fatal("timeout")
+	case req = <-collector.requests:
+	}
+	require.Len(t, req.ResourceMetrics, 1)
+	resourceAttrs := map[string]string{}
+	for _, kv := range req.ResourceMetrics[0].Resource.Attributes {
+		resourceAttrs[kv.Key] = kv.Value.GetStringValue()
+	}
+	require.Equal(t, "crdb", resourceAttrs["service.name"])
+	require.Equal(t, "us-east1", resourceAttrs["region"])
+	require.NotEmpty(t, resourceAttrs["host.name"])
+
+	require.Len(t, req.ResourceMetrics[0].InstrumentationLibraryMetrics, 1)
+	metrics := req.ResourceMetrics[0].InstrumentationLibraryMetrics[0].Metrics
+	require.Len(t, metrics, 3)
+	byName := map[string]*metricspb.Metric{}
+	for _, m := range metrics {
+		byName[m.Name] = m
+	}
+
+	counter := byName["one.counter"]
+	require.Equal(t, "1", counter.Unit)
+	sum := counter.GetSum()
+	require.True(t, sum.IsMonotonic)
+	require.Equal(t, metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE, sum.AggregationTemporality)
+	require.Len(t, sum.DataPoints, 1)
+	require.Equal(t, 3.0, sum.DataPoints[0].GetAsDouble())
+	require.NotZero(t, sum.DataPoints[0].StartTimeUnixNano)
+
+	gauge := byName["two.gauge"]
+	require.Equal(t, "By", gauge.Unit)
+	require.Len(t, gauge.GetGauge().DataPoints, 1)
+	dp := gauge.GetGauge().DataPoints[0]
+	require.Equal(t, 7.0, dp.GetAsDouble())
+	require.Len(t, dp.Attributes, 1)
+	require.Equal(t, "store", dp.Attributes[0].Key)
+	require.Equal(t, "2", dp.Attributes[0].Value.GetStringValue())
+
+	hist := byName["one.histogram"]
+	require.Equal(t, "ns", hist.Unit)
+	eh := hist.GetExponentialHistogram()
+	require.NotNil(t, eh)
+	require.Equal(t, metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE, eh.AggregationTemporality)
+	require.Len(t, eh.DataPoints, 1)
+	hdp := eh.DataPoints[0]
+	require.NotZero(t, hdp.StartTimeUnixNano)
+	require.Equal(t, uint64(5), hdp.Count)
+	// The sum is approximated from the median value equivalent to 100,
+	// which is 102.
+	require.InDelta(t, 109, hdp.Sum, 1e-9)
+	require.Equal(t, int32(4), hdp.Scale)
+	require.Equal(t, uint64(1), hdp.ZeroCount)
+	require.Equal(t, int32(-1), hdp.Positive.Offset)
+	// The values 1, 2, 4 are exact powers of two, which are the upper
+	// bounds of the buckets with indexes -1, 15 and 31. The highest value
+	// equivalent to 100 is 103, in the bucket with index 106.
+	require.Equal(t, map[int]uint64{0: 1, 16: 1, 32: 1, 107: 1}, nonEmptyBuckets(hdp.Positive))
+}
+
+func TestOTLPExporterHistogramScale(t *testing.T) {
+	h := NewHistogram(Metadata{Name: "histogram"}, time.Minute, 1000000, 1)
+	h.RecordValue(1)
+	h.RecordValue(1000000)
+	dp := makeOTLPExponentialHistogramDataPoint(nil, h.Snapshot(), 0, 0)
+	// At scale 4, the values are 320 buckets apart. Halving the
+	// resolution twice makes them fit in 160 buckets.
+	require.Equal(t, int32(2), dp.Scale)
+	require.Equal(t, int32(-1), dp.Positive.Offset)
+	require.Len(t, nonEmptyBuckets(dp.Positive), 2)
+
+	dp = makeOTLPExponentialHistogramDataPoint(nil, NewHistogram(Metadata{}, time.Minute, 10, 1).Snapshot(), 0, 0)
+	require.Zero(t, dp.Count)
+	require.Nil(t, dp.Positive)
+}
+
+func TestParseOTLPResourceAttributes(t *testing.T) {
+	attrs, err := ParseOTLPResourceAttributes(" region=us-east1, deployment.environment = prod,,")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"region":                 "us-east1",
+		"deployment.environment": "prod",
+	}, attrs)
+
+	_, err = ParseOTLPResourceAttributes("region")
+	require.EqualError(t, err, `invalid resource attribute "region", expected key=value`)
+}
+
+// nonEmptyBuckets maps the positions of the non-empty buckets, relative to
+// their offset, to their counts.
+func nonEmptyBuckets(b *metricspb.ExponentialHistogramDataPoint_Buckets) map[int]uint64 {
+	buckets := map[int]uint64{}
+	for i, c := range b.BucketCounts {
+		if c > 0 {
+			buckets[i] = c
+		}
+	}
+	return buckets
+}